@@ -258,6 +258,7 @@ func main() {
 	var testWorkflowOutputRepository testworkflow.OutputRepository
 	var configRepository configrepository.Repository
 	var triggerLeaseBackend triggers.LeaseBackend
+	var scheduledExecutionStore, scheduledContainerExecutionStore, scheduledGRPCExecutionStore client.ScheduledExecutionStore
 	var artifactStorage domainstorage.ArtifactsStorage
 	var storageClient domainstorage.Client
 	if mode == common.ModeAgent {
@@ -279,6 +280,9 @@ func main() {
 		testWorkflowResultsRepository = testworkflow.NewMongoRepository(db, cfg.APIMongoAllowDiskUse)
 		configRepository = configrepository.NewMongoRepository(db)
 		triggerLeaseBackend = triggers.NewMongoLeaseBackend(db)
+		scheduledExecutionStore = client.NewMongoScheduledExecutionStore(db, "job")
+		scheduledContainerExecutionStore = client.NewMongoScheduledExecutionStore(db, "container")
+		scheduledGRPCExecutionStore = client.NewMongoScheduledExecutionStore(db, "grpc")
 		minioClient := newStorageClient(cfg)
 		if err = minioClient.Connect(); err != nil {
 			ui.ExitOnError("Connecting to minio", err)
@@ -466,6 +470,24 @@ func main() {
 		ui.ExitOnError("Creating executor client", err)
 	}
 
+	watchOptions := client.WatchOptions{
+		Interval:    cfg.TestkubeWatchInterval,
+		MaxInterval: cfg.TestkubeWatchMaxInterval,
+		Multiplier:  cfg.TestkubeWatchMultiplier,
+		Jitter:      cfg.TestkubeWatchJitter,
+		UseWatch:    cfg.TestkubeWatchUseWatch,
+	}
+	if err = watchOptions.Validate(); err != nil {
+		ui.ExitOnError("Validating watch options", err)
+	}
+	executor.WithWatchOptions(watchOptions)
+
+	priorityClasses, err := client.ParsePriorityClasses(cfg.TestkubePriorityClasses)
+	if err != nil {
+		ui.ExitOnError("Parsing execution priority classes", err)
+	}
+	executor.WithPriorityClasses(priorityClasses)
+
 	containerTemplates, err := parser.ParseContainerTemplates(cfg)
 	if err != nil {
 		ui.ExitOnError("Creating container job templates", err)
@@ -510,11 +532,18 @@ func main() {
 	if err != nil {
 		ui.ExitOnError("Creating container executor", err)
 	}
+	containerExecutor.WithWatchOptions(watchOptions)
+
+	// executions with run after time or delay are queued until their start time
+	delayedExecutor := client.NewDelayedExecutor(executor, resultsRepository, nil).
+		WithStore(scheduledExecutionStore)
+	delayedContainerExecutor := client.NewDelayedExecutor(containerExecutor, resultsRepository, nil).
+		WithStore(scheduledContainerExecutionStore)
 
 	sched := scheduler.NewScheduler(
 		metrics,
-		executor,
-		containerExecutor,
+		delayedExecutor,
+		delayedContainerExecutor,
 		resultsRepository,
 		testResultsRepository,
 		executorsClient,
@@ -535,6 +564,23 @@ func main() {
 		cfg.TestkubeProTLSSecret,
 		cfg.TestkubeProRunnerCustomCASecret,
 	)
+	grpcExecutor := client.NewGRPCExecutor(log.DefaultLogger, client.GRPCOptions{
+		Insecure:        !cfg.RunnerGRPCSecure,
+		SkipVerify:      cfg.RunnerGRPCSkipVerify,
+		CertFile:        cfg.RunnerGRPCCertFile,
+		KeyFile:         cfg.RunnerGRPCKeyFile,
+		CAFile:          cfg.RunnerGRPCCAFile,
+		MaxResponseSize: cfg.RunnerGRPCMaxResponseSize,
+		MaxOutputSize:   cfg.RunnerGRPCMaxOutputSize,
+		RequestTimeout:  cfg.RunnerGRPCRequestTimeout,
+		Retry: client.GRPCRetryOptions{
+			MaxAttempts: cfg.RunnerGRPCRetryMaxAttempts,
+			MaxElapsed:  cfg.RunnerGRPCRetryMaxElapsed,
+		},
+	}, resultsRepository, eventsEmitter).WithMetrics(metrics)
+	delayedGRPCExecutor := client.NewDelayedExecutor(grpcExecutor, resultsRepository, nil).
+		WithStore(scheduledGRPCExecutionStore)
+	sched.WithExecutor(client.ExecutorTypeGRPC, delayedGRPCExecutor)
 	if mode == common.ModeAgent {
 		sched.WithSubscriptionChecker(subscriptionChecker)
 	}
@@ -559,8 +605,8 @@ func main() {
 		configMapConfig,
 		clusterId,
 		eventsEmitter,
-		executor,
-		containerExecutor,
+		delayedExecutor,
+		delayedContainerExecutor,
 		metrics,
 		sched,
 		slackLoader,
@@ -624,6 +670,15 @@ func main() {
 	}
 
 	api.InitEvents()
+
+	// executions scheduled before restart are started at their time again, every replica restores them
+	// and the one claiming the execution from the store starts it
+	for _, delayed := range []*client.DelayedExecutor{delayedExecutor, delayedContainerExecutor, delayedGRPCExecutor} {
+		if err := delayed.Restore(ctx); err != nil {
+			log.DefaultLogger.Errorw("restoring scheduled executions", "error", err)
+		}
+	}
+
 	if !cfg.DisableTestTriggers {
 		triggerService := triggers.NewService(
 			sched,
@@ -637,13 +692,15 @@ func main() {
 			log.DefaultLogger,
 			configMapConfig,
 			executorsClient,
-			executor,
+			delayedExecutor,
 			eventBus,
 			metrics,
 			triggers.WithHostnameIdentifier(),
 			triggers.WithTestkubeNamespace(cfg.TestkubeNamespace),
 			triggers.WithWatcherNamespaces(cfg.TestkubeWatcherNamespaces),
 			triggers.WithDisableSecretCreation(cfg.DisableSecretCreation),
+			triggers.WithQueueDepth(cfg.TestTriggerQueueDepth),
+			triggers.WithPriorityClasses(priorityClasses),
 		)
 		log.DefaultLogger.Info("starting trigger service")
 		triggerService.Run(ctx)
@@ -50,6 +50,10 @@ func ExecutionRenderer(client client.Client, ui *ui.UI, obj interface{}) error {
 		ui.Warn("Args:             ", execution.Args...)
 	}
 
+	if len(execution.FieldOrigins) > 0 {
+		ui.Warn("Field origins:    ", testkube.MapToString(execution.FieldOrigins))
+	}
+
 	if execution.Content != nil && execution.Content.Repository != nil {
 		ui.Warn("Repository parameters:")
 		ui.Warn("  Branch:         ", execution.Content.Repository.Branch)
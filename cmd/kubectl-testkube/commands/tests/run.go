@@ -78,6 +78,7 @@ func NewRunTestCmd() *cobra.Command {
 		slavePodTemplate                   string
 		slavePodTemplateReference          string
 		executionNamespace                 string
+		dryRun                             bool
 	)
 
 	cmd := &cobra.Command{
@@ -254,6 +255,17 @@ func NewRunTestCmd() *cobra.Command {
 					os.Exit(1)
 				}
 
+				if dryRun {
+					if len(variablesFile) > 0 || len(copyFiles) > 0 {
+						ui.Warn("Files are not uploaded in dry run mode, the rendered execution doesn't reference them")
+					}
+
+					result, err := client.DryRunTest(testName, name, options)
+					ui.ExitOnError("rendering test execution "+namespacedName, err)
+					fmt.Print(result.Yaml)
+					return
+				}
+
 				var timeout time.Duration
 				if uploadTimeout != "" {
 					timeout, err = time.ParseDuration(uploadTimeout)
@@ -288,6 +300,10 @@ func NewRunTestCmd() *cobra.Command {
 					executions = append(executions, execution)
 				}
 			case len(selectors) != 0:
+				if dryRun {
+					ui.Failf("Dry run is supported only for a single test, pass Test name")
+				}
+
 				selector := strings.Join(selectors, ",")
 				executions, err = client.ExecuteTests(selector, concurrencyLevel, options)
 				ui.ExitOnError("starting test executions "+selector, err)
@@ -412,6 +428,7 @@ func NewRunTestCmd() *cobra.Command {
 	cmd.Flags().StringVar(&slavePodTemplate, "slave-pod-template", "", "slave pod template file path for extensions to slave pod template")
 	cmd.Flags().StringVar(&slavePodTemplateReference, "slave-pod-template-reference", "", "reference to slave pod template to use for the test")
 	cmd.Flags().StringVar(&executionNamespace, "execution-namespace", "", "namespace for test execution (Pro edition only)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the job the test execution would create, or the request it would send to the runner, without starting it")
 
 	return cmd
 }
@@ -113,6 +113,16 @@ var testWorkflowTemplateDeletesCount = promauto.NewCounterVec(prometheus.Counter
 	Help: "The total number of test workflow template deleted events",
 }, []string{"result"})
 
+var runnerRetriesCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "testkube_runner_retries_count",
+	Help: "The total number of retried runner calls",
+}, []string{"method", "reason"})
+
+var runnerRetriesExhaustedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "testkube_runner_retries_exhausted_count",
+	Help: "The total number of runner calls failed after all retries",
+}, []string{"method"})
+
 func NewMetrics() Metrics {
 	return Metrics{
 		TestExecutionsCount:           testExecutionsCount,
@@ -135,6 +145,8 @@ func NewMetrics() Metrics {
 		TestWorkflowTemplateCreations: testWorkflowTemplateCreationCount,
 		TestWorkflowTemplateUpdates:   testWorkflowTemplateUpdatesCount,
 		TestWorkflowTemplateDeletes:   testWorkflowTemplateDeletesCount,
+		RunnerRetries:                 runnerRetriesCount,
+		RunnerRetriesExhausted:        runnerRetriesExhaustedCount,
 	}
 }
 
@@ -159,6 +171,8 @@ type Metrics struct {
 	TestWorkflowTemplateCreations *prometheus.CounterVec
 	TestWorkflowTemplateUpdates   *prometheus.CounterVec
 	TestWorkflowTemplateDeletes   *prometheus.CounterVec
+	RunnerRetries                 *prometheus.CounterVec
+	RunnerRetriesExhausted        *prometheus.CounterVec
 }
 
 func (m Metrics) IncAndObserveExecuteTest(execution testkube.Execution, dashboardURI string) {
@@ -405,3 +419,16 @@ func (m Metrics) IncDeleteTestWorkflowTemplate(err error) {
 		"result": result,
 	}).Inc()
 }
+
+func (m Metrics) IncRunnerRetry(method, reason string) {
+	m.RunnerRetries.With(map[string]string{
+		"method": method,
+		"reason": reason,
+	}).Inc()
+}
+
+func (m Metrics) IncRunnerRetryExhausted(method string) {
+	m.RunnerRetriesExhausted.With(map[string]string{
+		"method": method,
+	}).Inc()
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
 	"github.com/kubeshop/testkube/pkg/executor/client"
 	"github.com/kubeshop/testkube/pkg/executor/output"
+	testsmapper "github.com/kubeshop/testkube/pkg/mapper/tests"
 	"github.com/kubeshop/testkube/pkg/scheduler"
 	"github.com/kubeshop/testkube/pkg/storage"
 	"github.com/kubeshop/testkube/pkg/storage/minio"
@@ -118,6 +119,50 @@ func (s *TestkubeAPI) ExecuteTestsHandler() fiber.Handler {
 	}
 }
 
+// DryRunTestHandler renders test execution without starting it, validation is the same as for the real run
+func (s *TestkubeAPI) DryRunTestHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		errPrefix := fmt.Sprintf("failed to dry run test %s", id)
+
+		var request testkube.ExecutionRequest
+		err := c.BodyParser(&request)
+		if err != nil {
+			return s.Error(c, http.StatusBadRequest, fmt.Errorf("%s: test request body invalid: %w", errPrefix, err))
+		}
+
+		if request.Args != nil {
+			request.Args, err = testkube.PrepareExecutorArgs(request.Args)
+			if err != nil {
+				return s.Error(c, http.StatusBadRequest, fmt.Errorf("%s: could not prepare executor args: %w", errPrefix, err))
+			}
+		}
+
+		test, err := s.TestsClient.Get(id)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return s.Error(c, http.StatusNotFound, fmt.Errorf("%s: client found no test: %w", errPrefix, err))
+			}
+			return s.Error(c, http.StatusBadGateway, fmt.Errorf("%s: can't get test: %w", errPrefix, err))
+		}
+
+		result, err := s.scheduler.DryRunTest(c.Context(), testsmapper.MapTestCRToAPI(*test), request)
+		if err == client.ErrDryRunNotSupported {
+			return s.Error(c, http.StatusNotImplemented, fmt.Errorf("%s: %w", errPrefix, err))
+		}
+		if err != nil {
+			return s.Error(c, http.StatusBadRequest, fmt.Errorf("%s: %w", errPrefix, err))
+		}
+
+		manifest, err := result.YAML()
+		if err != nil {
+			return s.Error(c, http.StatusInternalServerError, fmt.Errorf("%s: can't render manifest: %w", errPrefix, err))
+		}
+
+		return c.JSON(testkube.ExecutionDryRunResult{Yaml: manifest})
+	}
+}
+
 // ListExecutionsHandler returns array of available test executions
 func (s *TestkubeAPI) ListExecutionsHandler() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -401,7 +446,11 @@ func (s *TestkubeAPI) AbortExecutionHandler() fiber.Handler {
 			return s.Error(c, http.StatusInternalServerError, fmt.Errorf("%s: could not get test %v", errPrefix, err))
 		}
 
-		res, err := s.Executor.Abort(ctx, &execution)
+		res, err := s.Executor.Abort(client.WithAbortedBy(ctx, "api"), &execution)
+		if client.IsAlreadyFinished(err) {
+			// abort of finished execution is a no-op, the terminal result is returned
+			return c.JSON(res)
+		}
 		if err != nil {
 			return s.Error(c, http.StatusInternalServerError, fmt.Errorf("%s: could not abort execution: %v", errPrefix, err))
 		}
@@ -293,7 +293,8 @@ func (r MockExecutionResultsRepository) Count(ctx context.Context, filter result
 }
 
 type MockExecutor struct {
-	LogsFn func(id string) (chan output.Output, error)
+	LogsFn         func(id string) (chan output.Output, error)
+	CapabilitiesFn func() executorclient.Capabilities
 }
 
 func (e MockExecutor) Execute(ctx context.Context, execution *testkube.Execution, options executorclient.ExecuteOptions) (*testkube.ExecutionResult, error) {
@@ -311,6 +312,13 @@ func (e MockExecutor) Logs(ctx context.Context, id, namespace string) (chan outp
 	return e.LogsFn(id)
 }
 
+func (e MockExecutor) Capabilities() executorclient.Capabilities {
+	if e.CapabilitiesFn == nil {
+		return executorclient.Capabilities{}
+	}
+	return e.CapabilitiesFn()
+}
+
 func getMockExecutorClient() *executorsclientv1.ExecutorsClient {
 	scheme := runtime.NewScheme()
 	executorv1.AddToScheme(scheme)
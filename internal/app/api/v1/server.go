@@ -344,6 +344,7 @@ func (s *TestkubeAPI) InitRoutes() {
 	tests.Get("/:id/metrics", s.TestMetricsHandler())
 
 	tests.Post("/:id/executions", s.ExecuteTestsHandler())
+	tests.Post("/:id/executions/dry-run", s.DryRunTestHandler())
 
 	tests.Get("/:id/executions", s.ListExecutionsHandler())
 	tests.Get("/:id/executions/:executionID", s.GetExecutionHandler())
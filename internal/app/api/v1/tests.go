@@ -534,7 +534,11 @@ func (s TestkubeAPI) AbortTestHandler() fiber.Handler {
 
 		var results []testkube.ExecutionResult
 		for _, execution := range executions {
-			res, errAbort := s.Executor.Abort(ctx, &execution)
+			res, errAbort := s.Executor.Abort(client.WithAbortedBy(ctx, "api"), &execution)
+			if client.IsAlreadyFinished(errAbort) {
+				results = append(results, *res)
+				continue
+			}
 			if errAbort != nil {
 				s.Log.Errorw("aborting execution failed", "execution", execution, "error", errAbort)
 				err = errAbort
@@ -34,6 +34,12 @@ func (s *TestkubeAPI) CreateTestTriggerHandler() fiber.Handler {
 			if err := decoder.Decode(&testTrigger); err != nil {
 				return s.Error(c, http.StatusBadRequest, fmt.Errorf("%s: could not parse yaml request: %w", errPrefix, err))
 			}
+
+			request := testtriggersmapper.MapTestTriggerCRDToTestTriggerUpsertRequest(testTrigger)
+			if err := validateTestTriggerUpsertRequest(&request); err != nil {
+				return s.Error(c, http.StatusBadRequest, fmt.Errorf("%s: invalid test trigger: %w", errPrefix, err))
+			}
+			testTrigger.Spec = testtriggersmapper.MapTestTriggerUpsertRequestToTestTriggerCRD(request).Spec
 		} else {
 			var request testkube.TestTriggerUpsertRequest
 			err := c.BodyParser(&request)
@@ -41,6 +47,10 @@ func (s *TestkubeAPI) CreateTestTriggerHandler() fiber.Handler {
 				return s.Error(c, http.StatusBadRequest, fmt.Errorf("%s: could not parse json request: %w", errPrefix, err))
 			}
 
+			if err = validateTestTriggerUpsertRequest(&request); err != nil {
+				return s.Error(c, http.StatusBadRequest, fmt.Errorf("%s: invalid test trigger: %w", errPrefix, err))
+			}
+
 			testTrigger = testtriggersmapper.MapTestTriggerUpsertRequestToTestTriggerCRD(request)
 			// default namespace if not defined in upsert request
 			if testTrigger.Namespace == "" {
@@ -95,6 +105,10 @@ func (s *TestkubeAPI) UpdateTestTriggerHandler() fiber.Handler {
 			}
 		}
 
+		if err := validateTestTriggerUpsertRequest(&request); err != nil {
+			return s.Error(c, http.StatusBadRequest, fmt.Errorf("%s %s: invalid test trigger: %w", errPrefix, request.Name, err))
+		}
+
 		namespace := s.Namespace
 		if request.Namespace != "" {
 			namespace = request.Namespace
@@ -137,6 +151,12 @@ func (s *TestkubeAPI) BulkUpdateTestTriggersHandler() fiber.Handler {
 			return s.Error(c, http.StatusBadRequest, fmt.Errorf("%s: could not parse request: %w", errPrefix, err))
 		}
 
+		for i := range request {
+			if err = validateTestTriggerUpsertRequest(&request[i]); err != nil {
+				return s.Error(c, http.StatusBadRequest, fmt.Errorf("%s: invalid test trigger %s: %w", errPrefix, request[i].Name, err))
+			}
+		}
+
 		namespaces := make(map[string]struct{}, 0)
 		for _, upsertRequest := range request {
 			namespace := s.Namespace
@@ -313,6 +333,63 @@ func (s *TestkubeAPI) GetTestTriggerKeyMapHandler() fiber.Handler {
 	}
 }
 
+// validateTestTriggerUpsertRequest rejects the values not supported by test triggers,
+// the accepted ones are normalized, so i.e. "Forbid" concurrency policy is stored as "forbid"
+func validateTestTriggerUpsertRequest(request *testkube.TestTriggerUpsertRequest) error {
+	if request.Resource != nil {
+		resource, err := testkube.ParseTestTriggerResources(string(*request.Resource))
+		if err != nil {
+			return err
+		}
+		request.Resource = &resource
+
+		event, err := parseTestTriggerEvent(resource, request.Event)
+		if err != nil {
+			return err
+		}
+		request.Event = event
+	}
+
+	if request.Action != nil {
+		action, err := testkube.ParseTestTriggerActions(string(*request.Action))
+		if err != nil {
+			return err
+		}
+		request.Action = &action
+	}
+
+	if request.Execution != nil {
+		execution, err := testkube.ParseTestTriggerExecutions(string(*request.Execution))
+		if err != nil {
+			return err
+		}
+		request.Execution = &execution
+	}
+
+	// concurrency policy is optional
+	if request.ConcurrencyPolicy != nil && *request.ConcurrencyPolicy != "" {
+		policy, err := testkube.ParseTestTriggerConcurrencyPolicies(string(*request.ConcurrencyPolicy))
+		if err != nil {
+			return err
+		}
+		request.ConcurrencyPolicy = &policy
+	}
+
+	return nil
+}
+
+// parseTestTriggerEvent finds the event supported by the resource, case-insensitive and with the whitespace trimmed
+func parseTestTriggerEvent(resource testkube.TestTriggerResources, source string) (string, error) {
+	events := triggers.NewKeyMap().Events[string(resource)]
+	value := strings.TrimSpace(source)
+	for _, event := range events {
+		if strings.EqualFold(event, value) {
+			return event, nil
+		}
+	}
+	return "", fmt.Errorf("invalid test trigger event %q for resource %s, allowed values: %s", source, resource, strings.Join(events, ", "))
+}
+
 // generateTestTriggerName function generates a trigger name from the TestTrigger spec
 // function also takes care of name collisions, not exceeding k8s max object name (63 characters) and not ending with a hyphen '-'
 func generateTestTriggerName(t *testtriggersv1.TestTrigger) string {
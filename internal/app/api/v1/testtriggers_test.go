@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/log"
+	"github.com/kubeshop/testkube/pkg/server"
+)
+
+func TestTestkubeAPI_CreateTestTriggerHandlerInvalid(t *testing.T) {
+	app := fiber.New()
+
+	s := &TestkubeAPI{
+		HTTPServer: server.HTTPServer{
+			Mux: app,
+			Log: log.DefaultLogger,
+		},
+	}
+
+	app.Post("/triggers", s.CreateTestTriggerHandler())
+
+	tests := map[string]string{
+		"concurrency policy": `{"resource": "deployment", "event": "modified", "action": "run", "execution": "test", "concurrencyPolicy": "replase"}`,
+		"resource":           `{"resource": "secret", "event": "modified", "action": "run", "execution": "test"}`,
+		"event":              `{"resource": "pod", "event": "deployment-image-update", "action": "run", "execution": "test"}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "http://localhost/triggers", strings.NewReader(body))
+			req.Header.Set("Content-Type", mediaTypeJSON)
+			resp, err := app.Test(req, -1)
+
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
+
+func TestValidateTestTriggerUpsertRequest(t *testing.T) {
+	resource := testkube.TestTriggerResources(" Deployment")
+	action := testkube.TestTriggerActions("RUN")
+	execution := testkube.TestTriggerExecutions("testsuite")
+	policy := testkube.TestTriggerConcurrencyPolicies("Forbid")
+	request := testkube.TestTriggerUpsertRequest{
+		Resource:          &resource,
+		Event:             "Deployment-Image-Update",
+		Action:            &action,
+		Execution:         &execution,
+		ConcurrencyPolicy: &policy,
+	}
+
+	assert.NoError(t, validateTestTriggerUpsertRequest(&request))
+	assert.Equal(t, testkube.DEPLOYMENT_TestTriggerResources, *request.Resource)
+	assert.Equal(t, "deployment-image-update", request.Event)
+	assert.Equal(t, testkube.RUN_TestTriggerActions, *request.Action)
+	assert.Equal(t, testkube.TESTSUITE_TestTriggerExecutions, *request.Execution)
+	assert.Equal(t, testkube.FORBID_TestTriggerConcurrencyPolicies, *request.ConcurrencyPolicy)
+
+	request.Event = "updated"
+	assert.ErrorContains(t, validateTestTriggerUpsertRequest(&request), `invalid test trigger event "updated" for resource deployment`)
+}
@@ -47,6 +47,7 @@ type Config struct {
 	JobServiceAccountName                       string        `envconfig:"JOB_SERVICE_ACCOUNT_NAME" default:""`
 	JobTemplateFile                             string        `envconfig:"JOB_TEMPLATE_FILE" default:""`
 	DisableTestTriggers                         bool          `envconfig:"DISABLE_TEST_TRIGGERS" default:"false"`
+	TestTriggerQueueDepth                       int           `envconfig:"TESTKUBE_TRIGGER_QUEUE_DEPTH" default:"10"`
 	TestkubeDefaultExecutors                    string        `envconfig:"TESTKUBE_DEFAULT_EXECUTORS" default:""`
 	TestkubeEnabledExecutors                    string        `envconfig:"TESTKUBE_ENABLED_EXECUTORS" default:""`
 	TestkubeTemplateJob                         string        `envconfig:"TESTKUBE_TEMPLATE_JOB" default:""`
@@ -103,6 +104,22 @@ type Config struct {
 	LogServerCAFile                 string        `envconfig:"LOG_SERVER_CA_FILE" default:""`
 	DisableSecretCreation           bool          `envconfig:"DISABLE_SECRET_CREATION" default:"false"`
 	TestkubeExecutionNamespaces     string        `envconfig:"TESTKUBE_EXECUTION_NAMESPACES" default:""`
+	TestkubeWatchInterval           time.Duration `envconfig:"TESTKUBE_WATCH_INTERVAL" default:"1s"`
+	TestkubeWatchMaxInterval        time.Duration `envconfig:"TESTKUBE_WATCH_MAX_INTERVAL" default:"1s"`
+	TestkubeWatchMultiplier         float64       `envconfig:"TESTKUBE_WATCH_MULTIPLIER" default:"1"`
+	TestkubeWatchJitter             float64       `envconfig:"TESTKUBE_WATCH_JITTER" default:"0"`
+	TestkubeWatchUseWatch           bool          `envconfig:"TESTKUBE_WATCH_USE_WATCH" default:"false"`
+	TestkubePriorityClasses         string        `envconfig:"TESTKUBE_EXECUTION_PRIORITY_CLASSES" default:""`
+	RunnerGRPCSecure                bool          `envconfig:"RUNNER_GRPC_SECURE" default:"false"`
+	RunnerGRPCSkipVerify            bool          `envconfig:"RUNNER_GRPC_SKIP_VERIFY" default:"false"`
+	RunnerGRPCCertFile              string        `envconfig:"RUNNER_GRPC_CERT_FILE" default:""`
+	RunnerGRPCKeyFile               string        `envconfig:"RUNNER_GRPC_KEY_FILE" default:""`
+	RunnerGRPCCAFile                string        `envconfig:"RUNNER_GRPC_CA_FILE" default:""`
+	RunnerGRPCMaxResponseSize       int           `envconfig:"RUNNER_GRPC_MAX_RESPONSE_SIZE" default:"4194304"`
+	RunnerGRPCMaxOutputSize         int           `envconfig:"RUNNER_GRPC_MAX_OUTPUT_SIZE" default:"8388608"`
+	RunnerGRPCRequestTimeout        time.Duration `envconfig:"RUNNER_GRPC_REQUEST_TIMEOUT" default:"30s"`
+	RunnerGRPCRetryMaxAttempts      int           `envconfig:"RUNNER_GRPC_RETRY_MAX_ATTEMPTS" default:"5"`
+	RunnerGRPCRetryMaxElapsed       time.Duration `envconfig:"RUNNER_GRPC_RETRY_MAX_ELAPSED" default:"2m"`
 
 	// DEPRECATED: Use TestkubeProAPIKey instead
 	TestkubeCloudAPIKey string `envconfig:"TESTKUBE_CLOUD_API_KEY" default:""`
@@ -23,6 +23,7 @@ func NewProxyAPIClient(client kubernetes.Interface, config APIConfig) APIClient
 			NewProxyClient[testkube.Artifact](client, config),
 			NewProxyClient[testkube.ServerInfo](client, config),
 			NewProxyClient[testkube.DebugInfo](client, config),
+			NewProxyClient[testkube.ExecutionDryRunResult](client, config),
 		),
 		TestSuiteClient: NewTestSuiteClient(
 			NewProxyClient[testkube.TestSuite](client, config),
@@ -61,6 +62,7 @@ func NewDirectAPIClient(httpClient *http.Client, sseClient *http.Client, apiURI,
 			NewDirectClient[testkube.Artifact](httpClient, apiURI, apiPathPrefix),
 			NewDirectClient[testkube.ServerInfo](httpClient, apiURI, apiPathPrefix),
 			NewDirectClient[testkube.DebugInfo](httpClient, apiURI, apiPathPrefix),
+			NewDirectClient[testkube.ExecutionDryRunResult](httpClient, apiURI, apiPathPrefix),
 		),
 		TestSuiteClient: NewTestSuiteClient(
 			NewDirectClient[testkube.TestSuite](httpClient, apiURI, apiPathPrefix),
@@ -99,6 +101,7 @@ func NewCloudAPIClient(httpClient *http.Client, sseClient *http.Client, apiURI,
 			NewCloudClient[testkube.Artifact](httpClient, apiURI, apiPathPrefix),
 			NewCloudClient[testkube.ServerInfo](httpClient, apiURI, apiPathPrefix),
 			NewCloudClient[testkube.DebugInfo](httpClient, apiURI, apiPathPrefix),
+			NewCloudClient[testkube.ExecutionDryRunResult](httpClient, apiURI, apiPathPrefix),
 		),
 		TestSuiteClient: NewTestSuiteClient(
 			NewCloudClient[testkube.TestSuite](httpClient, apiURI, apiPathPrefix),
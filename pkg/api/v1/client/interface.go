@@ -38,6 +38,7 @@ type TestAPI interface {
 	ListTestWithExecutionSummaries(selector string) (tests testkube.TestWithExecutionSummaries, err error)
 	ExecuteTest(id, executionName string, options ExecuteTestOptions) (executions testkube.Execution, err error)
 	ExecuteTests(selector string, concurrencyLevel int, options ExecuteTestOptions) (executions []testkube.Execution, err error)
+	DryRunTest(id, executionName string, options ExecuteTestOptions) (result testkube.ExecutionDryRunResult, err error)
 	Logs(id string) (logs chan output.Output, err error)
 	LogsV2(id string) (logs chan events.Log, err error)
 }
@@ -278,7 +279,8 @@ type Gettable interface {
 // Executable is an interface of executable objects
 type Executable interface {
 	testkube.Execution | testkube.TestSuiteExecution | testkube.TestWorkflowExecution |
-		testkube.ExecutionsResult | testkube.TestSuiteExecutionsResult | testkube.TestWorkflowExecutionsResult
+		testkube.ExecutionsResult | testkube.TestSuiteExecutionsResult | testkube.TestWorkflowExecutionsResult |
+		testkube.ExecutionDryRunResult
 }
 
 // All is an interface of all objects
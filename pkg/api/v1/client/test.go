@@ -22,6 +22,7 @@ func NewTestClient(
 	artifactTransport Transport[testkube.Artifact],
 	serverInfoTransport Transport[testkube.ServerInfo],
 	debugInfoTransport Transport[testkube.DebugInfo],
+	executionDryRunTransport Transport[testkube.ExecutionDryRunResult],
 ) TestClient {
 	return TestClient{
 		testTransport:                     testTransport,
@@ -32,6 +33,7 @@ func NewTestClient(
 		artifactTransport:                 artifactTransport,
 		serverInfoTransport:               serverInfoTransport,
 		debugInfoTransport:                debugInfoTransport,
+		executionDryRunTransport:          executionDryRunTransport,
 	}
 }
 
@@ -45,6 +47,7 @@ type TestClient struct {
 	artifactTransport                 Transport[testkube.Artifact]
 	serverInfoTransport               Transport[testkube.ServerInfo]
 	debugInfoTransport                Transport[testkube.DebugInfo]
+	executionDryRunTransport          Transport[testkube.ExecutionDryRunResult]
 }
 
 // GetTest returns single test by id
@@ -136,7 +139,28 @@ func (c TestClient) GetExecution(executionID string) (execution testkube.Executi
 // execution is started asynchronously client can check later for results
 func (c TestClient) ExecuteTest(id, executionName string, options ExecuteTestOptions) (execution testkube.Execution, err error) {
 	uri := c.executionTransport.GetURI("/tests/%s/executions", id)
-	request := testkube.ExecutionRequest{
+	body, err := json.Marshal(newTestExecutionRequest(executionName, options))
+	if err != nil {
+		return execution, err
+	}
+
+	return c.executionTransport.Execute(http.MethodPost, uri, body, nil)
+}
+
+// DryRunTest renders test execution without starting it, the execution is validated the same way as by ExecuteTest
+func (c TestClient) DryRunTest(id, executionName string, options ExecuteTestOptions) (result testkube.ExecutionDryRunResult, err error) {
+	uri := c.executionDryRunTransport.GetURI("/tests/%s/executions/dry-run", id)
+	body, err := json.Marshal(newTestExecutionRequest(executionName, options))
+	if err != nil {
+		return result, err
+	}
+
+	return c.executionDryRunTransport.Execute(http.MethodPost, uri, body, nil)
+}
+
+// newTestExecutionRequest builds the request of the single test execution
+func newTestExecutionRequest(executionName string, options ExecuteTestOptions) testkube.ExecutionRequest {
+	return testkube.ExecutionRequest{
 		Name:                               executionName,
 		IsVariablesFileUploaded:            options.IsVariablesFileUploaded,
 		VariablesFile:                      options.ExecutionVariablesFileContent,
@@ -172,13 +196,6 @@ func (c TestClient) ExecuteTest(id, executionName string, options ExecuteTestOpt
 		SlavePodRequest:                    options.SlavePodRequest,
 		ExecutionNamespace:                 options.ExecutionNamespace,
 	}
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return execution, err
-	}
-
-	return c.executionTransport.Execute(http.MethodPost, uri, body, nil)
 }
 
 // ExecuteTests starts test executions, reads data and returns IDs
@@ -18,4 +18,6 @@ type Artifact struct {
 	// execution name that produced the artifact
 	ExecutionName string `json:"executionName,omitempty"`
 	Status        string `json:"status,omitempty"`
+	// sha256 checksum of the artifact file
+	Checksum string `json:"checksum,omitempty"`
 }
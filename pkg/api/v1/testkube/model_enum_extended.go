@@ -0,0 +1,49 @@
+package testkube
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// parseEnum finds the allowed value matching the source, case-insensitive and with the whitespace trimmed
+func parseEnum[T ~string](name, source string, allowed []T) (T, error) {
+	value := strings.TrimSpace(source)
+	for _, v := range allowed {
+		if strings.EqualFold(string(v), value) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid %s %q, allowed values: %s", name, source, joinEnum(allowed))
+}
+
+// isValidEnum checks if the value is exactly one of the allowed values
+func isValidEnum[T ~string](value T, allowed []T) bool {
+	for _, v := range allowed {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// unmarshalEnum decodes JSON string into the allowed value, see parseEnum;
+// the empty value is kept, so the optional fields not set i.e. in CRD are still decoded
+func unmarshalEnum[T ~string](name string, data []byte, allowed []T) (T, error) {
+	var source string
+	if err := json.Unmarshal(data, &source); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if strings.TrimSpace(source) == "" {
+		return "", nil
+	}
+	return parseEnum(name, source, allowed)
+}
+
+func joinEnum[T ~string](values []T) string {
+	result := make([]string, len(values))
+	for i := range values {
+		result[i] = string(values[i])
+	}
+	return strings.Join(result, ", ")
+}
@@ -0,0 +1,74 @@
+package testkube
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// enumValue is implemented by the validated enum types
+type enumValue interface {
+	~string
+	IsValid() bool
+}
+
+// testEnum checks parsing, validation and JSON round-trip of the enum type, so the next enum needs a single call
+func testEnum[T enumValue](t *testing.T, allowed []T, parse func(string) (T, error)) {
+	t.Helper()
+
+	for _, value := range allowed {
+		assert.True(t, value.IsValid(), value)
+		assert.False(t, T(strings.ToUpper(string(value))).IsValid(), value)
+
+		parsed, err := parse(" " + strings.ToUpper(string(value)) + "\n")
+		assert.NoError(t, err, value)
+		assert.Equal(t, value, parsed)
+
+		data, err := json.Marshal(value)
+		assert.NoError(t, err, value)
+		var decoded T
+		assert.NoError(t, json.Unmarshal(data, &decoded), value)
+		assert.Equal(t, value, decoded)
+	}
+
+	_, err := parse("")
+	assert.Error(t, err)
+	_, err = parse("unknown")
+	assert.ErrorContains(t, err, `"unknown", allowed values: `+joinEnum(allowed))
+	assert.False(t, T("unknown").IsValid())
+
+	var decoded T
+	assert.ErrorContains(t, json.Unmarshal([]byte(`"unknown"`), &decoded), "allowed values: "+joinEnum(allowed))
+	assert.Error(t, json.Unmarshal([]byte(`1`), &decoded))
+	assert.NoError(t, json.Unmarshal([]byte(`""`), &decoded))
+	assert.Equal(t, T(""), decoded)
+}
+
+func TestTestTriggerConcurrencyPolicies(t *testing.T) {
+	testEnum(t, AllTestTriggerConcurrencyPolicies, ParseTestTriggerConcurrencyPolicies)
+
+	var request TestTriggerUpsertRequest
+	err := json.Unmarshal([]byte(`{"name": "trigger", "concurrencyPolicy": "replase"}`), &request)
+	assert.EqualError(t, err, `invalid test trigger concurrency policy "replase", allowed values: allow, forbid, replace`)
+
+	// the test trigger CRD rejects queue, so it's rejected before the trigger is created
+	err = json.Unmarshal([]byte(`{"name": "trigger", "concurrencyPolicy": "queue"}`), &request)
+	assert.EqualError(t, err, `invalid test trigger concurrency policy "queue", allowed values: allow, forbid, replace`)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"name": "trigger", "concurrencyPolicy": "Forbid"}`), &request))
+	assert.Equal(t, FORBID_TestTriggerConcurrencyPolicies, *request.ConcurrencyPolicy)
+}
+
+func TestTestTriggerActions(t *testing.T) {
+	testEnum(t, AllTestTriggerActions, ParseTestTriggerActions)
+}
+
+func TestTestTriggerResources(t *testing.T) {
+	testEnum(t, AllTestTriggerResources, ParseTestTriggerResources)
+}
+
+func TestTestTriggerExecutions(t *testing.T) {
+	testEnum(t, AllTestTriggerExecutions, ParseTestTriggerExecutions)
+}
@@ -84,4 +84,8 @@ type Execution struct {
 	SlavePodRequest           *PodRequest `json:"slavePodRequest,omitempty"`
 	// namespace for test execution (Pro edition only)
 	ExecutionNamespace string `json:"executionNamespace,omitempty"`
+	// request fields resolved from expressions, mapped to their resolved values
+	ResolvedExpressions map[string]string `json:"resolvedExpressions,omitempty"`
+	// request fields mapped to where their effective value came from: request, test or merged
+	FieldOrigins map[string]string `json:"fieldOrigins,omitempty"`
 }
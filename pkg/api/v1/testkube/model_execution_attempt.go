@@ -0,0 +1,21 @@
+/*
+ * Testkube API
+ *
+ * Testkube provides a Kubernetes-native framework for test definition, execution and results
+ *
+ * API version: 1.0.0
+ * Contact: testkube@kubeshop.io
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package testkube
+
+// result of single attempt of retried execution
+type ExecutionAttempt struct {
+	// attempt number, starting from 1
+	Number int32 `json:"number"`
+	// id of the execution storing the attempt
+	ExecutionId string           `json:"executionId"`
+	Status      *ExecutionStatus `json:"status"`
+	// error message when attempt failed
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
@@ -0,0 +1,16 @@
+/*
+ * Testkube API
+ *
+ * Testkube provides a Kubernetes-native framework for test definition, execution and results
+ *
+ * API version: 1.0.0
+ * Contact: testkube@kubeshop.io
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package testkube
+
+// what the test execution would create or send, nothing of it is created
+type ExecutionDryRunResult struct {
+	// multi document YAML of the Kubernetes objects the execution would create, or the request it would send to the runner
+	Yaml string `json:"yaml"`
+}
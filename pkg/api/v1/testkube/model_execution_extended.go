@@ -178,6 +178,14 @@ func (e Execution) IsQueued() bool {
 	return *e.ExecutionResult.Status == QUEUED_ExecutionStatus
 }
 
+func (e Execution) IsPaused() bool {
+	if e.ExecutionResult == nil {
+		return true
+	}
+
+	return *e.ExecutionResult.Status == PAUSED_ExecutionStatus
+}
+
 func (e Execution) IsCanceled() bool {
 	if e.ExecutionResult == nil {
 		return true
@@ -9,6 +9,10 @@
  */
 package testkube
 
+import (
+	"time"
+)
+
 // test execution request body
 type ExecutionRequest struct {
 	// execution id
@@ -104,4 +108,22 @@ type ExecutionRequest struct {
 	SlavePodRequest           *PodRequest `json:"slavePodRequest,omitempty"`
 	// namespace for test execution (Pro edition only)
 	ExecutionNamespace string `json:"executionNamespace,omitempty"`
+	// earliest time the execution is started at
+	RunAfter time.Time `json:"runAfter,omitempty"`
+	// duration the execution start is postponed by, e.g. 1h30m
+	Delay string `json:"delay,omitempty"`
+	// execution priority, queued executions with higher priority start first
+	Priority int32 `json:"priority,omitempty"`
+	// priority class of the execution pod, it has to be allowed by the API server configuration
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// node selector merged into the executor job template node selector, execution values win for the same key
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// service account of the execution pod, it overrides the executor service account of the execution namespace
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// working directory of the execution container, relative path is resolved against the repository directory
+	WorkingDir string `json:"workingDir,omitempty"`
+	// format of the execution container output parsed into the execution result, empty is the native runner protocol
+	OutputFormat string `json:"outputFormat,omitempty"`
+	// sharding of the execution into parallel pods
+	Sharding *ExecutionSharding `json:"sharding,omitempty"`
 }
@@ -0,0 +1,116 @@
+package testkube
+
+import (
+	"maps"
+	"slices"
+)
+
+// GetDeepCopy gives a copy of ExecutionRequest with new pointers, maps and slices
+func (r *ExecutionRequest) GetDeepCopy() *ExecutionRequest {
+	if r == nil {
+		return nil
+	}
+
+	result := *r
+	result.ExecutionLabels = maps.Clone(r.ExecutionLabels)
+	result.Variables = copyVariables(r.Variables)
+	result.Command = slices.Clone(r.Command)
+	result.Args = slices.Clone(r.Args)
+	result.ImagePullSecrets = slices.Clone(r.ImagePullSecrets)
+	result.Envs = maps.Clone(r.Envs)
+	result.SecretEnvs = maps.Clone(r.SecretEnvs)
+	result.Uploads = slices.Clone(r.Uploads)
+	result.EnvConfigMaps = copyEnvReferences(r.EnvConfigMaps)
+	result.EnvSecrets = copyEnvReferences(r.EnvSecrets)
+	result.DownloadArtifactExecutionIDs = slices.Clone(r.DownloadArtifactExecutionIDs)
+	result.DownloadArtifactTestNames = slices.Clone(r.DownloadArtifactTestNames)
+	result.NodeSelector = maps.Clone(r.NodeSelector)
+
+	if r.ArtifactRequest != nil {
+		artifactRequest := *r.ArtifactRequest
+		artifactRequest.Dirs = slices.Clone(r.ArtifactRequest.Dirs)
+		artifactRequest.Masks = slices.Clone(r.ArtifactRequest.Masks)
+		result.ArtifactRequest = &artifactRequest
+	}
+
+	if r.ContentRequest != nil {
+		contentRequest := *r.ContentRequest
+		if r.ContentRequest.Repository != nil {
+			repository := *r.ContentRequest.Repository
+			contentRequest.Repository = &repository
+		}
+		result.ContentRequest = &contentRequest
+	}
+
+	if r.Sharding != nil {
+		sharding := *r.Sharding
+		sharding.Envs = maps.Clone(r.Sharding.Envs)
+		result.Sharding = &sharding
+	}
+
+	if r.RunningContext != nil {
+		runningContext := *r.RunningContext
+		result.RunningContext = &runningContext
+	}
+
+	if r.SlavePodRequest != nil {
+		slavePodRequest := *r.SlavePodRequest
+		if r.SlavePodRequest.Resources != nil {
+			resources := *r.SlavePodRequest.Resources
+			if resources.Requests != nil {
+				requests := *resources.Requests
+				resources.Requests = &requests
+			}
+			if resources.Limits != nil {
+				limits := *resources.Limits
+				resources.Limits = &limits
+			}
+			slavePodRequest.Resources = &resources
+		}
+		result.SlavePodRequest = &slavePodRequest
+	}
+
+	return &result
+}
+
+func copyVariables(variables map[string]Variable) map[string]Variable {
+	if variables == nil {
+		return nil
+	}
+
+	result := make(map[string]Variable, len(variables))
+	for name, variable := range variables {
+		if variable.Type_ != nil {
+			variableType := *variable.Type_
+			variable.Type_ = &variableType
+		}
+		if variable.SecretRef != nil {
+			secretRef := *variable.SecretRef
+			variable.SecretRef = &secretRef
+		}
+		if variable.ConfigMapRef != nil {
+			configMapRef := *variable.ConfigMapRef
+			variable.ConfigMapRef = &configMapRef
+		}
+		result[name] = variable
+	}
+
+	return result
+}
+
+func copyEnvReferences(references []EnvReference) []EnvReference {
+	if references == nil {
+		return nil
+	}
+
+	result := make([]EnvReference, len(references))
+	for i, reference := range references {
+		if reference.Reference != nil {
+			localReference := *reference.Reference
+			reference.Reference = &localReference
+		}
+		result[i] = reference
+	}
+
+	return result
+}
@@ -9,6 +9,10 @@
  */
 package testkube
 
+import (
+	"time"
+)
+
 // execution result returned from executor
 type ExecutionResult struct {
 	Status *ExecutionStatus `json:"status"`
@@ -16,9 +20,21 @@ type ExecutionResult struct {
 	Output string `json:"output,omitempty"`
 	// output type depends of reporter used in particular tool
 	OutputType string `json:"outputType,omitempty"`
+	// set when the output exceeded the executor output size limit and the rest of it was dropped
+	OutputTruncated bool `json:"outputTruncated,omitempty"`
+	// size of the whole output in bytes when the output was truncated
+	OutputSize int64 `json:"outputSize,omitempty"`
 	// error message when status is error, separate to output as output can be partial in case of error
 	ErrorMessage string `json:"errorMessage,omitempty"`
 	// execution steps (for collection of requests)
 	Steps   []ExecutionStepResult   `json:"steps,omitempty"`
 	Reports *ExecutionResultReports `json:"reports,omitempty"`
+	// artifacts stored for the execution
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	// time when queued execution is scheduled to start
+	ScheduledTime time.Time `json:"scheduledTime,omitempty"`
+	// results of all attempts when the execution was retried, in order
+	Attempts []ExecutionAttempt `json:"attempts,omitempty"`
+	// results of the parallel jobs when the execution was split into shards, in shard order
+	Shards []ExecutionShardResult `json:"shards,omitempty"`
 }
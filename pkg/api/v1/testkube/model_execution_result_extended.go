@@ -1,11 +1,24 @@
 package testkube
 
+import (
+	"slices"
+	"time"
+)
+
 func NewRunningExecutionResult() *ExecutionResult {
 	return &ExecutionResult{
 		Status: StatusPtr(RUNNING_ExecutionStatus),
 	}
 }
 
+// NewScheduledExecutionResult creates queued execution result for execution starting at given time
+func NewScheduledExecutionResult(at time.Time) *ExecutionResult {
+	return &ExecutionResult{
+		Status:        StatusPtr(QUEUED_ExecutionStatus),
+		ScheduledTime: at,
+	}
+}
+
 // NewPendingExecutionResult DEPRECATED since testkube@1.0.0
 func NewPendingExecutionResult() ExecutionResult {
 	return ExecutionResult{
@@ -68,6 +81,10 @@ func (e *ExecutionResult) IsTimeout() bool {
 	return *e.Status == TIMEOUT_ExecutionStatus
 }
 
+func (e *ExecutionResult) IsPaused() bool {
+	return *e.Status == PAUSED_ExecutionStatus
+}
+
 func (e *ExecutionResult) Err(err error) *ExecutionResult {
 	e.Status = ExecutionStatusFailed
 	e.ErrorMessage = err.Error()
@@ -127,6 +144,9 @@ func (e *ExecutionResult) GetDeepCopy() *ExecutionResult {
 		ErrorMessage: e.ErrorMessage,
 		Steps:        e.Steps,
 		Reports:      reports,
+		Artifacts:    slices.Clone(e.Artifacts),
+		Attempts:     slices.Clone(e.Attempts),
+		Shards:       slices.Clone(e.Shards),
 	}
 	return &result
 }
@@ -0,0 +1,25 @@
+/*
+ * Testkube API
+ *
+ * Testkube provides a Kubernetes-native framework for test definition, execution and results
+ *
+ * API version: 1.0.0
+ * Contact: testkube@kubeshop.io
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package testkube
+
+// result of single shard of the execution split into parallel jobs
+type ExecutionShardResult struct {
+	// shard index, starting from 0
+	Index int32 `json:"index"`
+	// id of the shard, it's the name of the shard job
+	ExecutionId string           `json:"executionId"`
+	Status      *ExecutionStatus `json:"status"`
+	// error message when shard failed
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// shard duration
+	Duration string `json:"duration,omitempty"`
+	// RAW shard output
+	Output string `json:"output,omitempty"`
+}
@@ -0,0 +1,20 @@
+/*
+ * Testkube API
+ *
+ * Testkube provides a Kubernetes-native framework for test definition, execution and results
+ *
+ * API version: 1.0.0
+ * Contact: testkube@kubeshop.io
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package testkube
+
+// execution sharding, it splits the execution into parallel jobs
+type ExecutionSharding struct {
+	// number of parallel jobs
+	Count int32 `json:"count"`
+	// additional environment variables of the shard jobs, the values are templates, i.e. \"{{ .Number }}/{{ .Total }}\"
+	Envs map[string]string `json:"envs,omitempty"`
+	// how shard results are merged, steps by default
+	Merge string `json:"merge,omitempty"`
+}
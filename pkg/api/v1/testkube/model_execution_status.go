@@ -20,4 +20,5 @@ const (
 	ABORTED_ExecutionStatus ExecutionStatus = "aborted"
 	TIMEOUT_ExecutionStatus ExecutionStatus = "timeout"
 	SKIPPED_ExecutionStatus ExecutionStatus = "skipped"
+	PAUSED_ExecutionStatus  ExecutionStatus = "paused"
 )
@@ -16,6 +16,7 @@ var (
 	ExecutionStatusRunning = StatusPtr(RUNNING_ExecutionStatus)
 	ExecutionStatusAborted = StatusPtr(ABORTED_ExecutionStatus)
 	ExecutionStatusTimeout = StatusPtr(TIMEOUT_ExecutionStatus)
+	ExecutionStatusPaused  = StatusPtr(PAUSED_ExecutionStatus)
 )
 
 // ExecutionStatuses is an array of ExecutionStatus
@@ -40,6 +41,7 @@ func ParseExecutionStatusList(source, separator string) (statusList ExecutionSta
 		RUNNING_ExecutionStatus: {},
 		ABORTED_ExecutionStatus: {},
 		TIMEOUT_ExecutionStatus: {},
+		PAUSED_ExecutionStatus:  {},
 	}
 
 	if source == "" {
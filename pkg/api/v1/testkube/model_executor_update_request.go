@@ -15,7 +15,7 @@ type ExecutorUpdateRequest struct {
 	Namespace *string `json:"namespace,omitempty"`
 	// object name
 	Name *string `json:"name"`
-	// ExecutorType one of \"rest\" for rest openapi based executors or \"job\" which will be default runners for testkube soon
+	// ExecutorType one of \"job\" for executors running the runner binary in Kubernetes job, which is the default, \"container\" for running the image with the command and args directly, with the test content mounted, or \"grpc\" for long-lived runners reachable over gRPC
 	ExecutorType *string `json:"executorType,omitempty"`
 	// Image for kube-job
 	Image  *string     `json:"image,omitempty"`
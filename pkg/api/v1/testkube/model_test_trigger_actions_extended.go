@@ -0,0 +1,26 @@
+package testkube
+
+// AllTestTriggerActions lists the supported values of TestTriggerActions
+var AllTestTriggerActions = []TestTriggerActions{
+	RUN_TestTriggerActions,
+}
+
+// ParseTestTriggerActions returns the test trigger action matching the source, case-insensitive and with the whitespace trimmed
+func ParseTestTriggerActions(source string) (TestTriggerActions, error) {
+	return parseEnum("test trigger action", source, AllTestTriggerActions)
+}
+
+// IsValid checks if the test trigger action is one of the supported values
+func (v TestTriggerActions) IsValid() bool {
+	return isValidEnum(v, AllTestTriggerActions)
+}
+
+// UnmarshalJSON rejects the unsupported test trigger action, listing the allowed values in the error
+func (v *TestTriggerActions) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum("test trigger action", data, AllTestTriggerActions)
+	if err != nil {
+		return err
+	}
+	*v = value
+	return nil
+}
@@ -0,0 +1,29 @@
+package testkube
+
+// AllTestTriggerConcurrencyPolicies lists the supported values of TestTriggerConcurrencyPolicies,
+// they match the values accepted by the test trigger CRD
+var AllTestTriggerConcurrencyPolicies = []TestTriggerConcurrencyPolicies{
+	ALLOW_TestTriggerConcurrencyPolicies,
+	FORBID_TestTriggerConcurrencyPolicies,
+	REPLACE_TestTriggerConcurrencyPolicies,
+}
+
+// ParseTestTriggerConcurrencyPolicies returns the test trigger concurrency policy matching the source, case-insensitive and with the whitespace trimmed
+func ParseTestTriggerConcurrencyPolicies(source string) (TestTriggerConcurrencyPolicies, error) {
+	return parseEnum("test trigger concurrency policy", source, AllTestTriggerConcurrencyPolicies)
+}
+
+// IsValid checks if the test trigger concurrency policy is one of the supported values
+func (v TestTriggerConcurrencyPolicies) IsValid() bool {
+	return isValidEnum(v, AllTestTriggerConcurrencyPolicies)
+}
+
+// UnmarshalJSON rejects the unsupported test trigger concurrency policy, listing the allowed values in the error
+func (v *TestTriggerConcurrencyPolicies) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum("test trigger concurrency policy", data, AllTestTriggerConcurrencyPolicies)
+	if err != nil {
+		return err
+	}
+	*v = value
+	return nil
+}
@@ -0,0 +1,27 @@
+package testkube
+
+// AllTestTriggerExecutions lists the supported values of TestTriggerExecutions
+var AllTestTriggerExecutions = []TestTriggerExecutions{
+	TEST_TestTriggerExecutions,
+	TESTSUITE_TestTriggerExecutions,
+}
+
+// ParseTestTriggerExecutions returns the test trigger execution matching the source, case-insensitive and with the whitespace trimmed
+func ParseTestTriggerExecutions(source string) (TestTriggerExecutions, error) {
+	return parseEnum("test trigger execution", source, AllTestTriggerExecutions)
+}
+
+// IsValid checks if the test trigger execution is one of the supported values
+func (v TestTriggerExecutions) IsValid() bool {
+	return isValidEnum(v, AllTestTriggerExecutions)
+}
+
+// UnmarshalJSON rejects the unsupported test trigger execution, listing the allowed values in the error
+func (v *TestTriggerExecutions) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum("test trigger execution", data, AllTestTriggerExecutions)
+	if err != nil {
+		return err
+	}
+	*v = value
+	return nil
+}
@@ -0,0 +1,33 @@
+package testkube
+
+// AllTestTriggerResources lists the supported values of TestTriggerResources
+var AllTestTriggerResources = []TestTriggerResources{
+	POD_TestTriggerResources,
+	DEPLOYMENT_TestTriggerResources,
+	STATEFULSET_TestTriggerResources,
+	DAEMONSET_TestTriggerResources,
+	SERVICE_TestTriggerResources,
+	INGRESS_TestTriggerResources,
+	EVENT_TestTriggerResources,
+	CONFIGMAP_TestTriggerResources,
+}
+
+// ParseTestTriggerResources returns the test trigger resource matching the source, case-insensitive and with the whitespace trimmed
+func ParseTestTriggerResources(source string) (TestTriggerResources, error) {
+	return parseEnum("test trigger resource", source, AllTestTriggerResources)
+}
+
+// IsValid checks if the test trigger resource is one of the supported values
+func (v TestTriggerResources) IsValid() bool {
+	return isValidEnum(v, AllTestTriggerResources)
+}
+
+// UnmarshalJSON rejects the unsupported test trigger resource, listing the allowed values in the error
+func (v *TestTriggerResources) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum("test trigger resource", data, AllTestTriggerResources)
+	if err != nil {
+		return err
+	}
+	*v = value
+	return nil
+}
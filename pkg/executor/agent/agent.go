@@ -98,6 +98,11 @@ func Run(ctx context.Context, r runner.Runner, args []string) {
 	}
 
 	if r.GetType().IsMain() {
+		if err = CollectArtifacts(ctx, params, e, &result); err != nil {
+			output.PrintError(os.Stderr, err)
+			os.Exit(1)
+		}
+
 		output.PrintEvent("test execution finished", e.Id)
 		output.PrintResult(result)
 	}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/envs"
+	"github.com/kubeshop/testkube/pkg/executor/output"
+	"github.com/kubeshop/testkube/pkg/executor/scraper"
+	"github.com/kubeshop/testkube/pkg/executor/scraper/factory"
+	"github.com/kubeshop/testkube/pkg/ui"
+)
+
+// CollectArtifacts stores files requested by RUNNER_ARTIFACT_COLLECTION and records them on the execution result,
+// patterns matching no files and files over the size limit are only logged
+func CollectArtifacts(ctx context.Context, params envs.Params, execution testkube.Execution, result *testkube.ExecutionResult) error {
+	if params.ArtifactCollection == "" {
+		return nil
+	}
+
+	var request scraper.ArtifactRequest
+	if err := json.Unmarshal([]byte(params.ArtifactCollection), &request); err != nil {
+		return errors.Wrap(err, "error decoding artifact collection")
+	}
+
+	if !request.ShouldCollect(result) {
+		output.PrintLogf("%s Skipping artifacts collection for passed execution", ui.IconCheckMark)
+		return nil
+	}
+
+	uploaderType := factory.MinIOUploader
+	if params.ProMode {
+		uploaderType = factory.CloudUploader
+	}
+
+	uploader, err := factory.GetUploader(ctx, params, uploaderType)
+	if err != nil {
+		return errors.Wrap(err, "error creating artifacts uploader")
+	}
+	defer uploader.Close()
+
+	collection, err := scraper.CollectArtifacts(ctx, os.DirFS("/"), params.WorkingDir, request,
+		func(ctx context.Context, object *scraper.Object) error {
+			return uploader.Upload(ctx, object, execution)
+		})
+	if collection != nil {
+		for _, warning := range collection.Warnings {
+			output.PrintLogf("%s %s", ui.IconWarning, warning)
+		}
+		result.Artifacts = append(result.Artifacts, collection.Artifacts...)
+	}
+	if err != nil {
+		return errors.Wrap(err, "error collecting artifacts")
+	}
+
+	output.PrintLogf("%s Collected %d artifacts", ui.IconCheckMark, len(collection.Artifacts))
+	return nil
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// DefaultAbortedBy is recorded when abort request doesn't say who aborted the execution
+const DefaultAbortedBy = "system"
+
+type abortedByKey struct{}
+
+type timedOutKey struct{}
+
+// WithAbortedBy returns context carrying who requests execution abort
+func WithAbortedBy(ctx context.Context, by string) context.Context {
+	return context.WithValue(ctx, abortedByKey{}, by)
+}
+
+// AbortedBy returns who requests execution abort, DefaultAbortedBy when context doesn't carry it
+func AbortedBy(ctx context.Context) string {
+	if by, ok := ctx.Value(abortedByKey{}).(string); ok && by != "" {
+		return by
+	}
+
+	return DefaultAbortedBy
+}
+
+// WithTimedOut returns context of abort requested because execution timeout passed,
+// executors record such aborted execution as timed out
+func WithTimedOut(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timedOutKey{}, timeout)
+}
+
+// NewTimedOutExecutionResult creates timed out execution result
+func NewTimedOutExecutionResult(timeout time.Duration) *testkube.ExecutionResult {
+	return &testkube.ExecutionResult{
+		Status:       testkube.ExecutionStatusTimeout,
+		ErrorMessage: fmt.Sprintf("execution timed out after %s", timeout),
+	}
+}
+
+// NewAbortedExecutionResult creates aborted execution result recording who and when aborted the execution,
+// the result is timed out when abort was requested because execution timeout passed
+func NewAbortedExecutionResult(ctx context.Context, at time.Time) *testkube.ExecutionResult {
+	if timeout, ok := ctx.Value(timedOutKey{}).(time.Duration); ok {
+		return NewTimedOutExecutionResult(timeout)
+	}
+
+	return &testkube.ExecutionResult{
+		Status:       testkube.ExecutionStatusAborted,
+		ErrorMessage: fmt.Sprintf("execution aborted by %s at %s", AbortedBy(ctx), at.UTC().Format(time.RFC3339)),
+	}
+}
+
+// FinishedResult returns execution result when execution already reached terminal status
+func FinishedResult(execution testkube.Execution) (*testkube.ExecutionResult, bool) {
+	if execution.ExecutionResult == nil || execution.ExecutionResult.Status == nil || !execution.ExecutionResult.IsCompleted() {
+		return nil, false
+	}
+
+	return execution.ExecutionResult, true
+}
+
+// AbortRegistry tracks contexts of executions watched by executor, so abort can stop watching promptly
+type AbortRegistry struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+// NewAbortRegistry creates new abort registry
+func NewAbortRegistry() *AbortRegistry {
+	return &AbortRegistry{cancels: make(map[string]context.CancelFunc)}
+}
+
+// Watch returns context cancelled when execution is aborted, release has to be called when watching is finished
+func (r *AbortRegistry) Watch(ctx context.Context, id string) (watchCtx context.Context, release func()) {
+	watchCtx, cancel := context.WithCancel(ctx)
+
+	r.mu.Lock()
+	r.cancels[id] = cancel
+	r.mu.Unlock()
+
+	return watchCtx, func() {
+		r.mu.Lock()
+		delete(r.cancels, id)
+		r.mu.Unlock()
+		cancel()
+	}
+}
+
+// Cancel stops watching of the execution, returns false when execution isn't watched
+func (r *AbortRegistry) Cancel(id string) bool {
+	r.mu.Lock()
+	cancel, ok := r.cancels[id]
+	delete(r.cancels, id)
+	r.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+
+	return ok
+}
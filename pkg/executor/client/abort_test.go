@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/repository/result"
+)
+
+func TestAbortedBy(t *testing.T) {
+	assert.Equal(t, DefaultAbortedBy, AbortedBy(context.Background()))
+	assert.Equal(t, "api", AbortedBy(WithAbortedBy(context.Background(), "api")))
+}
+
+func TestNewAbortedExecutionResult(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	result := NewAbortedExecutionResult(WithAbortedBy(context.Background(), "testtrigger deploy"), at)
+
+	assert.True(t, result.IsAborted())
+	assert.Equal(t, "execution aborted by testtrigger deploy at 2024-01-02T03:04:05Z", result.ErrorMessage)
+}
+
+func TestNewAbortedExecutionResult_TimedOut(t *testing.T) {
+	result := NewAbortedExecutionResult(WithTimedOut(context.Background(), time.Minute), time.Now())
+
+	assert.True(t, result.IsTimeout())
+	assert.Equal(t, "execution timed out after 1m0s", result.ErrorMessage)
+}
+
+func TestFinishedResult(t *testing.T) {
+	_, ok := FinishedResult(testkube.Execution{})
+	assert.False(t, ok)
+
+	_, ok = FinishedResult(testkube.Execution{ExecutionResult: testkube.NewRunningExecutionResult()})
+	assert.False(t, ok)
+
+	for _, status := range []*testkube.ExecutionStatus{testkube.ExecutionStatusPassed, testkube.ExecutionStatusFailed,
+		testkube.ExecutionStatusAborted, testkube.ExecutionStatusTimeout} {
+		finished := &testkube.ExecutionResult{Status: status}
+		result, ok := FinishedResult(testkube.Execution{ExecutionResult: finished})
+		assert.True(t, ok)
+		assert.Same(t, finished, result)
+	}
+}
+
+func TestAbortRegistry(t *testing.T) {
+	registry := NewAbortRegistry()
+
+	ctx, release := registry.Watch(context.Background(), "exec-1")
+	assert.True(t, registry.Cancel("exec-1"))
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.False(t, registry.Cancel("exec-1"))
+	release()
+
+	ctx, release = registry.Watch(context.Background(), "exec-2")
+	release()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.False(t, registry.Cancel("exec-2"))
+}
+
+func newAbortTestJobExecutor(repository result.Repository) *JobExecutor {
+	return &JobExecutor{
+		Repository: repository,
+		Log:        zap.NewNop().Sugar(),
+		ClientSet: fake.NewSimpleClientset(&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "exec-1", Namespace: "default"},
+		}),
+		aborts: NewAbortRegistry(),
+	}
+}
+
+func TestJobExecutor_AbortFinishedExecution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	executor := newAbortTestJobExecutor(result.NewMockRepository(ctrl))
+	finished := &testkube.ExecutionResult{Status: testkube.ExecutionStatusFailed}
+
+	res, err := executor.Abort(context.Background(), &testkube.Execution{Id: "exec-1", TestNamespace: "default", ExecutionResult: finished})
+
+	assert.ErrorIs(t, err, ErrAlreadyFinished)
+	assert.EqualError(t, err, "execution exec-1 already finished with status failed")
+	assert.Same(t, finished, res)
+	_, err = executor.ClientSet.BatchV1().Jobs("default").Get(context.Background(), "exec-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestJobExecutor_AbortRaceWithCompletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// execution finishes naturally while its job is being deleted
+	repository := result.NewMockRepository(ctrl)
+	gomock.InOrder(
+		repository.EXPECT().Get(gomock.Any(), "exec-1").
+			Return(testkube.Execution{Id: "exec-1", ExecutionResult: testkube.NewRunningExecutionResult()}, nil),
+		repository.EXPECT().Get(gomock.Any(), "exec-1").
+			Return(testkube.Execution{Id: "exec-1", ExecutionResult: &testkube.ExecutionResult{Status: testkube.ExecutionStatusPassed}}, nil),
+	)
+	executor := newAbortTestJobExecutor(repository)
+
+	res, err := executor.Abort(context.Background(), &testkube.Execution{Id: "exec-1", TestNamespace: "default", ExecutionResult: testkube.NewRunningExecutionResult()})
+
+	assert.ErrorIs(t, err, ErrAlreadyFinished)
+	assert.True(t, res.IsPassed())
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+// NewArtifactsMachine creates expressions machine exposing artifacts recorded in the execution result,
+// i.e. artifacts.names, artifacts.totalSize and artifacts.matching(patterns...), storage is not read
+func NewArtifactsMachine(result *testkube.ExecutionResult) expressionstcl.Machine {
+	var artifacts []testkube.Artifact
+	if result != nil {
+		artifacts = result.Artifacts
+	}
+
+	names := make([]string, len(artifacts))
+	var totalSize int64
+	for i, artifact := range artifacts {
+		names[i] = artifact.Name
+		totalSize += int64(artifact.Size)
+	}
+
+	return expressionstcl.NewMachine().
+		Register("artifacts.names", names).
+		Register("artifacts.totalSize", totalSize).
+		RegisterFunction("artifacts.matching", func(values ...expressionstcl.StaticValue) (interface{}, bool, error) {
+			v, err := matchArtifacts(names, values...)
+			return v, true, err
+		})
+}
+
+// matchArtifacts returns artifact names matching the glob patterns like the glob() function,
+// patterns prefixed with "!" exclude the matching names
+func matchArtifacts(names []string, values ...expressionstcl.StaticValue) ([]string, error) {
+	if len(values) == 0 {
+		return nil, errors.New("artifacts.matching() function takes at least one argument")
+	}
+
+	var patterns, ignorePatterns []string
+	for _, value := range values {
+		if !value.IsString() {
+			return nil, fmt.Errorf("artifacts.matching() function expects string arguments, provided: %v", value.String())
+		}
+
+		pattern, _ := value.StringValue()
+		ignore := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("artifacts.matching() function: invalid pattern %s", pattern)
+		}
+
+		if ignore {
+			ignorePatterns = append(ignorePatterns, pattern)
+		} else {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	if len(patterns) == 0 {
+		return nil, errors.New("artifacts.matching() function needs at least one matching pattern")
+	}
+
+	result := make([]string, 0)
+	for _, name := range names {
+		if matchesAnyPattern(patterns, name) && !matchesAnyPattern(ignorePatterns, name) {
+			result = append(result, name)
+		}
+	}
+
+	return result, nil
+}
+
+func matchesAnyPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := doublestar.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+func artifactsResult() *testkube.ExecutionResult {
+	return &testkube.ExecutionResult{
+		Artifacts: []testkube.Artifact{
+			{Name: "report.html", Size: 2048},
+			{Name: "screenshots/login.png", Size: 1000},
+			{Name: "screenshots/failed/cart.png", Size: 3000},
+			{Name: "videos/login.mp4", Size: 1 << 20},
+		},
+	}
+}
+
+func TestArtifactsMachine(t *testing.T) {
+	machine := NewArtifactsMachine(artifactsResult())
+
+	tests := map[string]interface{}{
+		`artifacts.names`:                                 []string{"report.html", "screenshots/login.png", "screenshots/failed/cart.png", "videos/login.mp4"},
+		`artifacts.totalSize`:                             int64(2048 + 1000 + 3000 + 1<<20),
+		`artifacts.matching("**/*.png")`:                  []string{"screenshots/login.png", "screenshots/failed/cart.png"},
+		`artifacts.matching("screenshots/*.png")`:         []string{"screenshots/login.png"},
+		`artifacts.matching("**/*.png", "!**/failed/**")`: []string{"screenshots/login.png"},
+		`artifacts.matching("*.html", "videos/*")`:        []string{"report.html", "videos/login.mp4"},
+		`len(artifacts.matching("**/*.zip")) > 0`:         false,
+	}
+	for expr, expected := range tests {
+		v, err := expressionstcl.EvalString(expr, nil, expressionstcl.WithMachines(machine))
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+}
+
+func TestArtifactsMachineEmpty(t *testing.T) {
+	machine := NewArtifactsMachine(nil)
+
+	v, err := expressionstcl.EvalString(`len(artifacts.names) == 0 && artifacts.totalSize == 0`, nil, expressionstcl.WithMachines(machine))
+	assert.NoError(t, err)
+	assert.Equal(t, true, v)
+	v, err = expressionstcl.EvalString(`artifacts.matching("**")`, nil, expressionstcl.WithMachines(machine))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, v)
+}
+
+func TestArtifactsMachineMatchingErrors(t *testing.T) {
+	machine := NewArtifactsMachine(artifactsResult())
+
+	for expr, message := range map[string]string{
+		`artifacts.matching()`:         "takes at least one argument",
+		`artifacts.matching("!*.png")`: "needs at least one matching pattern",
+		`artifacts.matching("[a")`:     "invalid pattern [a",
+		`artifacts.matching(1)`:        "expects string arguments",
+	} {
+		_, err := expressionstcl.EvalString(expr, nil, expressionstcl.WithMachines(machine))
+		assert.ErrorContains(t, err, message, expr)
+	}
+}
+
+func TestEvaluateOnResultArtifacts(t *testing.T) {
+	execution := testkube.Execution{ExecutionResult: artifactsResult()}
+
+	v, err := EvaluateOnResult(`len(artifacts.matching("**/*.png")) > 0`, execution)
+	assert.NoError(t, err)
+	assert.Equal(t, true, v)
+}
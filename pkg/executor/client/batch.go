@@ -0,0 +1,345 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+const (
+	// BatchIDLabel links executions started together in a batch
+	BatchIDLabel = "testkube.io/batch-id"
+	// BatchIndexLabel is a position of execution in the batch
+	BatchIndexLabel = "testkube.io/batch-index"
+)
+
+// ErrBatchItemSkipped is reported for batch items which weren't started because batch failed fast
+var ErrBatchItemSkipped = errors.New("batch item skipped after another item failed")
+
+// BatchPolicy defines how overall batch result is computed from item results
+type BatchPolicy string
+
+const (
+	// BatchPolicyAllMustPass passes the batch only when all items passed
+	BatchPolicyAllMustPass BatchPolicy = "all"
+	// BatchPolicyThreshold passes the batch when ratio of passed items reaches the threshold
+	BatchPolicyThreshold BatchPolicy = "threshold"
+)
+
+// BatchOptions defines how batch executions are started and evaluated
+type BatchOptions struct {
+	// ID is used as BatchIDLabel value, generated when empty
+	ID string
+	// Parallelism is a maximum number of concurrent submissions, zero submits all items at once,
+	// items with higher priority are submitted first
+	Parallelism int
+	// Policy computes overall batch result, defaults to BatchPolicyAllMustPass
+	Policy BatchPolicy
+	// Threshold is a minimal ratio of passed items, from 0 to 1, used by BatchPolicyThreshold
+	Threshold float64
+	// FailFast aborts remaining executions once any item fails
+	FailFast bool
+	// ConcurrencyKey identifies batches which ConcurrencyPolicy applies to, e.g. trigger name
+	ConcurrencyKey string
+	// ConcurrencyPolicy is applied to the whole batch, when another batch with the same key is running:
+	// forbid rejects the batch, queue holds it until the other batch finishes, and replace aborts all executions
+	// of the other batch before starting any of this one; allow or empty runs batches concurrently
+	ConcurrencyPolicy testkube.TestTriggerConcurrencyPolicies
+	// CostBudget refuses to start the batch when its estimated cost exceeds the budget, see EstimateCost
+	CostBudget *CostBudget
+	// ForceOverBudget starts the batch even when its estimated cost exceeds the budget
+	ForceOverBudget bool
+}
+
+// Validate checks if batch options are valid
+func (o BatchOptions) Validate() error {
+	if o.Parallelism < 0 {
+		return errors.New("batch parallelism can't be negative")
+	}
+
+	switch o.Policy {
+	case "", BatchPolicyAllMustPass:
+	case BatchPolicyThreshold:
+		if o.Threshold <= 0 || o.Threshold > 1 {
+			return fmt.Errorf("batch threshold should be greater than 0 and at most 1, %v provided", o.Threshold)
+		}
+	default:
+		return fmt.Errorf("unknown batch policy %s", o.Policy)
+	}
+
+	return validateBatchConcurrency(o.ConcurrencyKey, o.ConcurrencyPolicy)
+}
+
+// BatchItemResult is an outcome of a single batch execution
+type BatchItemResult struct {
+	// Index is a position of the item in the batch
+	Index int
+	// ExecutionID is empty when execution wasn't started
+	ExecutionID string
+	// Result is the last known execution result
+	Result *testkube.ExecutionResult
+	// Err is set when execution couldn't be started or watched
+	Err error
+}
+
+// IsPassed checks if batch item execution passed
+func (r BatchItemResult) IsPassed() bool {
+	return r.Err == nil && r.Result != nil && r.Result.Status != nil && r.Result.IsPassed()
+}
+
+// BatchResult aggregates results of batch executions
+type BatchResult struct {
+	// ID is a batch ID set as BatchIDLabel on all executions
+	ID string
+	// Items are item results in the order of passed execute options
+	Items []BatchItemResult
+	// PassedCount is a number of passed items
+	PassedCount int
+	// Passed is an overall result computed by batch policy
+	Passed bool
+	// Decision tells how the concurrency policy was applied, it's empty for batches without concurrency key
+	Decision BatchConcurrencyDecision
+	// ReplacedBatchIDs are batches aborted before this one started
+	ReplacedBatchIDs []string
+	// ReplaceErrors are failures of aborting executions of replaced batches, the batch is started anyway,
+	// so these executions may still be running
+	ReplaceErrors []error
+	// ReplacedBy is a batch which replaced this one before it finished
+	ReplacedBy string
+	// CostEstimate is the estimated cost of the batch, it's set only when the batch has cost budget
+	CostEstimate *CostEstimate
+}
+
+// ExecuteBatch starts executions for all execute options and waits for their terminal state,
+// failures of particular items are reported in their item results, returned error is set only for invalid input,
+// or when the estimated cost exceeds the budget
+func (r *ExecutionRunner) ExecuteBatch(ctx context.Context, items []ExecuteOptions, options BatchOptions) (*BatchResult, error) {
+	if len(items) == 0 {
+		return nil, errors.New("batch should have at least one item")
+	}
+
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	var estimate *CostEstimate
+	if options.CostBudget != nil {
+		var err error
+		if estimate, err = r.checkCostBudget(ctx, items, options); err != nil {
+			return nil, err
+		}
+	}
+
+	batchID := options.ID
+	if batchID == "" {
+		batchID = primitive.NewObjectID().Hex()
+	}
+
+	parallelism := options.Parallelism
+	if parallelism == 0 || parallelism > len(items) {
+		parallelism = len(items)
+	}
+
+	// batch context is cancelled to stop submitting and waiting when batch fails fast or it's replaced
+	batchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batch := &BatchResult{ID: batchID, CostEstimate: estimate}
+	var active *activeBatch
+	if options.ConcurrencyKey != "" {
+		active = newActiveBatch(batchID, cancel)
+		decision, replaced, abortErrs, err := r.concurrency.acquire(ctx, options.ConcurrencyKey, options.ConcurrencyPolicy, active)
+		batch.Decision, batch.ReplacedBatchIDs, batch.ReplaceErrors = decision, replaced, abortErrs
+		if decision == BatchRejected || err != nil {
+			if err == nil {
+				err = ErrBatchForbidden
+			}
+			batch.Items = make([]BatchItemResult, len(items))
+			for i := range items {
+				batch.Items[i] = BatchItemResult{Index: i, Err: err}
+			}
+			return batch, nil
+		}
+
+		defer func() {
+			r.concurrency.release(options.ConcurrencyKey, active)
+			close(active.done)
+		}()
+	}
+	replacedBy := func() string {
+		if active == nil {
+			return ""
+		}
+		return active.replacedByID()
+	}
+	var failOnce sync.Once
+	failed := false
+	fail := func() {
+		if options.FailFast {
+			failOnce.Do(func() {
+				failed = true
+				cancel()
+			})
+		}
+	}
+
+	results := make([]BatchItemResult, len(items))
+	executions := make([]*testkube.Execution, len(items))
+	queue := NewExecutionQueue(r.priorityClasses)
+	for i := range items {
+		results[i] = BatchItemResult{Index: i}
+		queue.Push(items[i])
+	}
+
+	// items are submitted in priority order, slot is taken before starting the submission to keep the order
+	slots := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for item, ok := queue.Pop(); ok; item, ok = queue.Pop() {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(index int, options ExecuteOptions) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if batchCtx.Err() != nil {
+				results[index].Err = ErrBatchItemSkipped
+				if replacedBy() != "" {
+					results[index].Err = ErrBatchReplaced
+				}
+				if ctx.Err() != nil {
+					results[index].Err = ctx.Err()
+				}
+				return
+			}
+
+			execution, itemOptions := newBatchExecution(batchID, index, options)
+			_, err := r.ExecuteAsync(batchCtx, execution, itemOptions)
+			results[index].Result = execution.ExecutionResult
+			if err != nil {
+				results[index].Err = fmt.Errorf("starting batch item %d: %w", index, err)
+				fail()
+				return
+			}
+
+			executions[index] = execution
+			results[index].ExecutionID = execution.Id
+		}(item.Sequence, item.Options)
+	}
+	wg.Wait()
+
+	// all items are submitted before watching, so failing fast aborts every started execution
+	for i, execution := range executions {
+		if execution == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(index int, execution *testkube.Execution) {
+			defer wg.Done()
+
+			result, err := r.wait(batchCtx, execution, execution.Id)
+			results[index].Result = result
+			var detached *ExecutionDetachedError
+			if err != nil && !(errors.As(err, &detached) && ctx.Err() == nil) {
+				results[index].Err = fmt.Errorf("watching batch item %d: %w", index, err)
+			}
+
+			if !results[index].IsPassed() && batchCtx.Err() == nil {
+				fail()
+			}
+		}(i, execution)
+	}
+	wg.Wait()
+
+	batch.ReplacedBy = replacedBy()
+	if failed || batch.ReplacedBy != "" {
+		abortedBy := batchID
+		if batch.ReplacedBy != "" {
+			abortedBy = batch.ReplacedBy
+		}
+		errs := r.abortUnfinished(WithAbortedBy(ctx, "batch "+abortedBy), executions, results)
+		if active != nil {
+			active.abortErrs = errs
+		}
+	}
+	if batch.ReplacedBy != "" {
+		for i := range results {
+			if results[i].Err == nil && !results[i].IsPassed() {
+				results[i].Err = ErrBatchReplaced
+			}
+		}
+	}
+
+	batch.Items = results
+	for _, result := range results {
+		if result.IsPassed() {
+			batch.PassedCount++
+		}
+	}
+
+	switch options.Policy {
+	case BatchPolicyThreshold:
+		batch.Passed = float64(batch.PassedCount)/float64(len(results)) >= options.Threshold
+	default:
+		batch.Passed = batch.PassedCount == len(results)
+	}
+
+	return batch, nil
+}
+
+// abortUnfinished aborts started batch executions which haven't reached terminal state, failures are returned too
+func (r *ExecutionRunner) abortUnfinished(ctx context.Context, executions []*testkube.Execution, results []BatchItemResult) (errs []error) {
+	for i, execution := range executions {
+		if execution == nil || results[i].Err != nil {
+			continue
+		}
+
+		if _, ok := FinishedResult(*execution); ok {
+			continue
+		}
+
+		result, err := r.executor.Abort(ctx, execution)
+		r.cache.Invalidate(execution.Id)
+		if IsAlreadyFinished(err) {
+			// execution finished while the batch was being aborted, its terminal result is kept
+			results[i].Result = result
+			continue
+		}
+		if err != nil {
+			results[i].Err = fmt.Errorf("aborting batch item %d: %w", i, err)
+			errs = append(errs, fmt.Errorf("aborting execution %s: %w", execution.Id, err))
+			continue
+		}
+
+		results[i].Result = result
+	}
+
+	return errs
+}
+
+func newBatchExecution(batchID string, index int, options ExecuteOptions) (*testkube.Execution, ExecuteOptions) {
+	id := options.ID
+	if id == "" {
+		id = primitive.NewObjectID().Hex()
+	}
+
+	labels := make(map[string]string, len(options.Labels)+2)
+	for key, value := range options.Labels {
+		labels[key] = value
+	}
+	labels[BatchIDLabel] = batchID
+	labels[BatchIndexLabel] = strconv.Itoa(index)
+
+	options.ID = id
+	options.Labels = labels
+	execution := testkube.NewExecutionWithID(id, options.TestSpec.Type_, options.TestName)
+	execution.TestNamespace = options.Namespace
+	execution.Labels = labels
+	return execution, options
+}
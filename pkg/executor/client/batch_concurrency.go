@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// BatchConcurrencyQueue holds the batch until all batches with the same concurrency key finish,
+// it's supported by batch scheduling only, on top of the test trigger concurrency policies
+const BatchConcurrencyQueue testkube.TestTriggerConcurrencyPolicies = "queue"
+
+var (
+	// ErrBatchForbidden is reported for all items of a batch rejected by the forbid concurrency policy
+	ErrBatchForbidden = errors.New("batch rejected, another batch with the same concurrency key is running")
+	// ErrBatchReplaced is reported for items of a batch which was replaced before they finished
+	ErrBatchReplaced = errors.New("batch replaced by another batch with the same concurrency key")
+)
+
+// BatchConcurrencyDecision tells how the concurrency policy was applied to the batch
+type BatchConcurrencyDecision string
+
+const (
+	// BatchStarted is a batch started right away
+	BatchStarted BatchConcurrencyDecision = "started"
+	// BatchRejected is a batch which wasn't started, as another batch with the same key was running
+	BatchRejected BatchConcurrencyDecision = "rejected"
+	// BatchQueued is a batch started after waiting for previous batches with the same key
+	BatchQueued BatchConcurrencyDecision = "queued"
+	// BatchReplaced is a batch started after aborting previous batches with the same key
+	BatchReplaced BatchConcurrencyDecision = "replaced"
+)
+
+func validateBatchConcurrency(key string, policy testkube.TestTriggerConcurrencyPolicies) error {
+	switch policy {
+	case "", testkube.ALLOW_TestTriggerConcurrencyPolicies:
+		return nil
+	case testkube.FORBID_TestTriggerConcurrencyPolicies, testkube.REPLACE_TestTriggerConcurrencyPolicies, BatchConcurrencyQueue:
+		if key == "" {
+			return fmt.Errorf("batch concurrency policy %s requires concurrency key", policy)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown batch concurrency policy %s", policy)
+}
+
+// activeBatch is a batch holding the concurrency key
+type activeBatch struct {
+	id     string
+	cancel context.CancelFunc
+	// done is closed once the batch finished, and replaced batch aborted its executions
+	done chan struct{}
+	// abortErrs are failures of aborting replaced batch executions, set before done is closed
+	abortErrs []error
+
+	mu         sync.Mutex
+	replacedBy string
+}
+
+func newActiveBatch(id string, cancel context.CancelFunc) *activeBatch {
+	return &activeBatch{id: id, cancel: cancel, done: make(chan struct{})}
+}
+
+func (b *activeBatch) replace(id string) {
+	b.mu.Lock()
+	b.replacedBy = id
+	b.mu.Unlock()
+	b.cancel()
+}
+
+func (b *activeBatch) replacedByID() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.replacedBy
+}
+
+// queuedBatch is a batch waiting for the concurrency key, ready is closed when it's moved to active batches
+type queuedBatch struct {
+	batch *activeBatch
+	ready chan struct{}
+}
+
+// batchConcurrency tracks batches by concurrency key, queued batches are started in FIFO order
+type batchConcurrency struct {
+	mu     sync.Mutex
+	active map[string][]*activeBatch
+	queued map[string][]*queuedBatch
+}
+
+func newBatchConcurrency() *batchConcurrency {
+	return &batchConcurrency{
+		active: make(map[string][]*activeBatch),
+		queued: make(map[string][]*queuedBatch),
+	}
+}
+
+// acquire applies the policy, the batch holds the key afterwards unless it's rejected or error is returned;
+// replaced batch IDs and failures of aborting their executions are returned for the replace policy
+func (c *batchConcurrency) acquire(ctx context.Context, key string, policy testkube.TestTriggerConcurrencyPolicies,
+	batch *activeBatch) (decision BatchConcurrencyDecision, replaced []string, abortErrs []error, err error) {
+	c.mu.Lock()
+	busy := len(c.active[key]) != 0 || len(c.queued[key]) != 0
+	switch policy {
+	case testkube.FORBID_TestTriggerConcurrencyPolicies:
+		if busy {
+			c.mu.Unlock()
+			return BatchRejected, nil, nil, nil
+		}
+	case BatchConcurrencyQueue:
+		if busy {
+			queued := &queuedBatch{batch: batch, ready: make(chan struct{})}
+			c.queued[key] = append(c.queued[key], queued)
+			c.mu.Unlock()
+			return BatchQueued, nil, nil, c.wait(ctx, key, queued)
+		}
+	case testkube.REPLACE_TestTriggerConcurrencyPolicies:
+		previous := c.active[key]
+		c.active[key] = []*activeBatch{batch}
+		c.mu.Unlock()
+		if len(previous) == 0 {
+			return BatchStarted, nil, nil, nil
+		}
+		abortErrs, err = c.replace(ctx, key, batch, previous)
+		for _, b := range previous {
+			replaced = append(replaced, b.id)
+		}
+		return BatchReplaced, replaced, abortErrs, err
+	}
+
+	c.active[key] = append(c.active[key], batch)
+	c.mu.Unlock()
+	return BatchStarted, nil, nil, nil
+}
+
+// wait blocks until the queued batch holds the key, the batch leaves the queue when context is done
+func (c *batchConcurrency) wait(ctx context.Context, key string, queued *queuedBatch) error {
+	select {
+	case <-queued.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	for i, q := range c.queued[key] {
+		if q == queued {
+			c.queued[key] = append(c.queued[key][:i:i], c.queued[key][i+1:]...)
+			c.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+	c.mu.Unlock()
+
+	// the key was handed over concurrently, so it has to be passed on
+	c.release(key, queued.batch)
+	return ctx.Err()
+}
+
+// replace cancels previous batches and waits until they abort their executions,
+// the key stays with the new batch when some executions couldn't be aborted, so it doesn't wait forever
+func (c *batchConcurrency) replace(ctx context.Context, key string, batch *activeBatch, previous []*activeBatch) ([]error, error) {
+	for _, b := range previous {
+		b.replace(batch.id)
+	}
+
+	var abortErrs []error
+	for _, b := range previous {
+		select {
+		case <-b.done:
+			abortErrs = append(abortErrs, b.abortErrs...)
+		case <-ctx.Done():
+			c.release(key, batch)
+			return abortErrs, ctx.Err()
+		}
+	}
+
+	return abortErrs, nil
+}
+
+// release removes the batch from the key holders, next queued batch takes the key when it's free
+func (c *batchConcurrency) release(key string, batch *activeBatch) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	active := c.active[key]
+	for i, b := range active {
+		if b == batch {
+			active = append(active[:i:i], active[i+1:]...)
+			break
+		}
+	}
+
+	if len(active) == 0 && len(c.queued[key]) != 0 {
+		next := c.queued[key][0]
+		c.queued[key] = c.queued[key][1:]
+		active = append(active, next.batch)
+		close(next.ready)
+	}
+
+	if len(active) == 0 {
+		delete(c.active, key)
+	} else {
+		c.active[key] = active
+	}
+
+	if len(c.queued[key]) == 0 {
+		delete(c.queued, key)
+	}
+}
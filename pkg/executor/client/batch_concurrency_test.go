@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// orderingExecutor records the order of starting and aborting executions
+type orderingExecutor struct {
+	*FakeExecutor
+
+	mu     sync.Mutex
+	events []string
+}
+
+func (e *orderingExecutor) Execute(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	e.record("execute " + options.TestName)
+	return e.FakeExecutor.Execute(ctx, execution, options)
+}
+
+func (e *orderingExecutor) Abort(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
+	result, err := e.FakeExecutor.Abort(ctx, execution)
+	if err == nil {
+		e.record("abort " + execution.TestName)
+	}
+	return result, err
+}
+
+func (e *orderingExecutor) record(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *orderingExecutor) Events() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.events...)
+}
+
+// newConcurrencyTestRunner polls with real clock, as batches are running concurrently until they are cancelled
+func newConcurrencyTestRunner(executor Executor, executions ExecutionGetter) *ExecutionRunner {
+	return NewExecutionRunner(executor, executions, NewWatcher(zap.NewNop().Sugar(), WatchOptions{
+		Interval:    time.Millisecond,
+		MaxInterval: 10 * time.Millisecond,
+		Multiplier:  2,
+	}, NewRealClock()))
+}
+
+func startBatch(t *testing.T, ctx context.Context, runner *ExecutionRunner, items []ExecuteOptions, options BatchOptions) <-chan *BatchResult {
+	result := make(chan *BatchResult, 1)
+	go func() {
+		batch, err := runner.ExecuteBatch(ctx, items, options)
+		assert.NoError(t, err)
+		result <- batch
+	}()
+	return result
+}
+
+func waitStarted(t *testing.T, fake *FakeExecutor, count int) {
+	require.Eventually(t, func() bool {
+		return len(fake.ExecuteOptions()) == count
+	}, time.Second, time.Millisecond)
+}
+
+func TestBatchOptions_ValidateConcurrency(t *testing.T) {
+	assert.NoError(t, BatchOptions{ConcurrencyPolicy: testkube.ALLOW_TestTriggerConcurrencyPolicies}.Validate())
+	assert.NoError(t, BatchOptions{ConcurrencyKey: "trigger", ConcurrencyPolicy: BatchConcurrencyQueue}.Validate())
+	assert.Error(t, BatchOptions{ConcurrencyPolicy: testkube.FORBID_TestTriggerConcurrencyPolicies}.Validate())
+	assert.Error(t, BatchOptions{ConcurrencyKey: "trigger", ConcurrencyPolicy: "skip"}.Validate())
+}
+
+func TestExecutionRunner_ExecuteBatchConcurrencyForbid(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("running").WithStatuses(testkube.RUNNING_ExecutionStatus)
+	fake.ExpectExecution("next")
+	runner := newConcurrencyTestRunner(fake, fake)
+	options := BatchOptions{ConcurrencyKey: "trigger", ConcurrencyPolicy: testkube.FORBID_TestTriggerConcurrencyPolicies}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := startBatch(t, ctx, runner, []ExecuteOptions{{TestName: "running"}}, options)
+	waitStarted(t, fake, 1)
+
+	// whole batch is rejected, none of its items is started
+	batch, err := runner.ExecuteBatch(context.Background(), []ExecuteOptions{{TestName: "next"}, {TestName: "next"}}, options)
+	require.NoError(t, err)
+	assert.Equal(t, BatchRejected, batch.Decision)
+	assert.False(t, batch.Passed)
+	require.Len(t, batch.Items, 2)
+	for _, item := range batch.Items {
+		assert.ErrorIs(t, item.Err, ErrBatchForbidden)
+		assert.Empty(t, item.ExecutionID)
+	}
+	assert.Len(t, fake.ExecuteOptions(), 1)
+
+	cancel()
+	assert.Equal(t, BatchStarted, (<-first).Decision)
+
+	batch, err = runner.ExecuteBatch(context.Background(), []ExecuteOptions{{TestName: "next"}}, options)
+	require.NoError(t, err)
+	assert.Equal(t, BatchStarted, batch.Decision)
+	assert.True(t, batch.Passed)
+}
+
+func TestExecutionRunner_ExecuteBatchConcurrencyQueue(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("running").WithStatuses(testkube.RUNNING_ExecutionStatus)
+	fake.ExpectExecution("queued-1")
+	fake.ExpectExecution("queued-2")
+	runner := newConcurrencyTestRunner(fake, fake)
+	options := BatchOptions{ConcurrencyKey: "trigger", ConcurrencyPolicy: BatchConcurrencyQueue}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := startBatch(t, ctx, runner, []ExecuteOptions{{TestName: "running"}}, options)
+	waitStarted(t, fake, 1)
+
+	second := startBatch(t, context.Background(), runner, []ExecuteOptions{{TestName: "queued-1"}, {TestName: "queued-2"}}, options)
+	require.Never(t, func() bool {
+		return len(fake.ExecuteOptions()) > 1
+	}, 50*time.Millisecond, time.Millisecond)
+
+	cancel()
+	assert.Equal(t, BatchStarted, (<-first).Decision)
+	batch := <-second
+	assert.Equal(t, BatchQueued, batch.Decision)
+	assert.True(t, batch.Passed)
+}
+
+func TestExecutionRunner_ExecuteBatchConcurrencyQueueCancelled(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("running").WithStatuses(testkube.RUNNING_ExecutionStatus)
+	fake.ExpectExecution("next")
+	runner := newConcurrencyTestRunner(fake, fake)
+	options := BatchOptions{ConcurrencyKey: "trigger", ConcurrencyPolicy: BatchConcurrencyQueue}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := startBatch(t, ctx, runner, []ExecuteOptions{{TestName: "running"}}, options)
+	waitStarted(t, fake, 1)
+
+	queuedCtx, queuedCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer queuedCancel()
+	batch, err := runner.ExecuteBatch(queuedCtx, []ExecuteOptions{{TestName: "next"}}, options)
+	require.NoError(t, err)
+	assert.Equal(t, BatchQueued, batch.Decision)
+	assert.ErrorIs(t, batch.Items[0].Err, context.DeadlineExceeded)
+	assert.Len(t, fake.ExecuteOptions(), 1)
+
+	// cancelled batch left the queue, so the key is free once the running batch finishes
+	cancel()
+	<-first
+	batch, err = runner.ExecuteBatch(context.Background(), []ExecuteOptions{{TestName: "next"}}, options)
+	require.NoError(t, err)
+	assert.Equal(t, BatchStarted, batch.Decision)
+	assert.True(t, batch.Passed)
+}
+
+func TestExecutionRunner_ExecuteBatchConcurrencyReplace(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("old-1").WithStatuses(testkube.RUNNING_ExecutionStatus)
+	fake.ExpectExecution("old-2").WithStatuses(testkube.RUNNING_ExecutionStatus)
+	fake.ExpectExecution("new-1")
+	fake.ExpectExecution("new-2")
+	executor := &orderingExecutor{FakeExecutor: fake}
+	runner := newConcurrencyTestRunner(executor, fake)
+	options := BatchOptions{ConcurrencyKey: "trigger", ConcurrencyPolicy: testkube.REPLACE_TestTriggerConcurrencyPolicies}
+
+	options.ID = "old"
+	first := startBatch(t, context.Background(), runner, []ExecuteOptions{{TestName: "old-1"}, {TestName: "old-2"}}, options)
+	waitStarted(t, fake, 2)
+
+	options.ID = "new"
+	batch, err := runner.ExecuteBatch(context.Background(), []ExecuteOptions{{TestName: "new-1"}, {TestName: "new-2"}}, options)
+	require.NoError(t, err)
+	assert.Equal(t, BatchReplaced, batch.Decision)
+	assert.Equal(t, []string{"old"}, batch.ReplacedBatchIDs)
+	assert.Empty(t, batch.ReplaceErrors)
+	assert.True(t, batch.Passed)
+
+	replaced := <-first
+	assert.Equal(t, "new", replaced.ReplacedBy)
+	assert.False(t, replaced.Passed)
+	for _, item := range replaced.Items {
+		assert.ErrorIs(t, item.Err, ErrBatchReplaced)
+		assert.Equal(t, testkube.ABORTED_ExecutionStatus, *item.Result.Status)
+	}
+
+	// all executions of the old batch are aborted before any of the new one is started
+	events := executor.Events()
+	require.Len(t, events, 6)
+	assert.ElementsMatch(t, []string{"abort old-1", "abort old-2"}, events[2:4])
+	assert.ElementsMatch(t, []string{"execute new-1", "execute new-2"}, events[4:])
+}
+
+func TestExecutionRunner_ExecuteBatchConcurrencyReplaceAbortFailure(t *testing.T) {
+	abortErr := errors.New("pod not found")
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("old-1").WithStatuses(testkube.RUNNING_ExecutionStatus).WithAbortError(abortErr)
+	fake.ExpectExecution("old-2").WithStatuses(testkube.RUNNING_ExecutionStatus)
+	fake.ExpectExecution("new")
+	fake.ExpectExecution("next")
+	runner := newConcurrencyTestRunner(fake, fake)
+	options := BatchOptions{ConcurrencyKey: "trigger", ConcurrencyPolicy: testkube.REPLACE_TestTriggerConcurrencyPolicies}
+
+	first := startBatch(t, context.Background(), runner, []ExecuteOptions{{TestName: "old-1"}, {TestName: "old-2"}}, options)
+	waitStarted(t, fake, 2)
+
+	// the new batch is started anyway, the execution which couldn't be aborted is reported
+	batch, err := runner.ExecuteBatch(context.Background(), []ExecuteOptions{{TestName: "new"}}, options)
+	require.NoError(t, err)
+	assert.Equal(t, BatchReplaced, batch.Decision)
+	require.Len(t, batch.ReplaceErrors, 1)
+	assert.ErrorIs(t, batch.ReplaceErrors[0], abortErr)
+	assert.True(t, batch.Passed)
+
+	replaced := <-first
+	assert.ErrorIs(t, replaced.Items[0].Err, abortErr)
+	assert.ErrorIs(t, replaced.Items[1].Err, ErrBatchReplaced)
+	assert.Len(t, fake.Aborted(), 2)
+
+	// the key isn't held by any of the batches anymore
+	options.ConcurrencyPolicy = testkube.FORBID_TestTriggerConcurrencyPolicies
+	batch, err = runner.ExecuteBatch(context.Background(), []ExecuteOptions{{TestName: "next"}}, options)
+	require.NoError(t, err)
+	assert.Equal(t, BatchStarted, batch.Decision)
+}
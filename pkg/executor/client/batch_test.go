@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+func TestBatchOptions_Validate(t *testing.T) {
+	assert.NoError(t, BatchOptions{}.Validate())
+	assert.NoError(t, BatchOptions{Policy: BatchPolicyThreshold, Threshold: 0.5}.Validate())
+	assert.Error(t, BatchOptions{Parallelism: -1}.Validate())
+	assert.Error(t, BatchOptions{Policy: BatchPolicyThreshold}.Validate())
+	assert.Error(t, BatchOptions{Policy: BatchPolicyThreshold, Threshold: 1.5}.Validate())
+	assert.Error(t, BatchOptions{Policy: "majority"}.Validate())
+}
+
+func TestExecutionRunner_ExecuteBatch(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("shard-1").WithStatuses(testkube.RUNNING_ExecutionStatus, testkube.PASSED_ExecutionStatus)
+	fake.ExpectExecution("shard-2").WithStatuses(testkube.PASSED_ExecutionStatus)
+
+	batch, err := newTestExecutionRunner(fake, fake, &recordingClock{}).ExecuteBatch(context.Background(), []ExecuteOptions{
+		{TestName: "shard-1", Labels: map[string]string{"suite": "e2e"}},
+		{TestName: "shard-2"},
+	}, BatchOptions{ID: "batch-1", Parallelism: 1})
+
+	require.NoError(t, err)
+	assert.True(t, batch.Passed)
+	assert.Equal(t, 2, batch.PassedCount)
+	require.Len(t, batch.Items, 2)
+	for i, item := range batch.Items {
+		assert.Equal(t, i, item.Index)
+		assert.NotEmpty(t, item.ExecutionID)
+		assert.True(t, item.IsPassed())
+	}
+
+	options := fake.ExecuteOptions()
+	require.Len(t, options, 2)
+	assert.Equal(t, map[string]string{"suite": "e2e", BatchIDLabel: "batch-1", BatchIndexLabel: "0"}, options[0].Labels)
+	assert.Equal(t, map[string]string{BatchIDLabel: "batch-1", BatchIndexLabel: "1"}, options[1].Labels)
+}
+
+func TestExecutionRunner_ExecuteBatchPartialFailure(t *testing.T) {
+	startErr := errors.New("executor not found")
+	items := []ExecuteOptions{{TestName: "shard-1"}, {TestName: "shard-2"}, {TestName: "shard-3"}}
+	run := func(t *testing.T, options BatchOptions) *BatchResult {
+		fake := NewFakeExecutor(t)
+		fake.ExpectExecution("shard-1")
+		fake.ExpectExecution("shard-2").WithError(startErr)
+		fake.ExpectExecution("shard-3").WithStatuses(testkube.RUNNING_ExecutionStatus, testkube.PASSED_ExecutionStatus)
+
+		batch, err := newTestExecutionRunner(fake, fake, &recordingClock{}).ExecuteBatch(context.Background(), items, options)
+		require.NoError(t, err)
+		return batch
+	}
+
+	batch := run(t, BatchOptions{})
+	assert.False(t, batch.Passed)
+	assert.Equal(t, 2, batch.PassedCount)
+	assert.True(t, batch.Items[0].IsPassed())
+	assert.ErrorIs(t, batch.Items[1].Err, startErr)
+	assert.Empty(t, batch.Items[1].ExecutionID)
+	assert.True(t, batch.Items[2].IsPassed())
+
+	batch = run(t, BatchOptions{Policy: BatchPolicyThreshold, Threshold: 0.6})
+	assert.True(t, batch.Passed)
+}
+
+func TestExecutionRunner_ExecuteBatchFailFastAborts(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("fast").WithStatuses(testkube.FAILED_ExecutionStatus)
+	fake.ExpectExecution("slow").WithStatuses(testkube.RUNNING_ExecutionStatus)
+
+	batch, err := newTestExecutionRunner(fake, fake, &recordingClock{}).ExecuteBatch(context.Background(),
+		[]ExecuteOptions{{TestName: "fast"}, {TestName: "slow"}}, BatchOptions{ID: "batch-1", FailFast: true})
+
+	require.NoError(t, err)
+	assert.False(t, batch.Passed)
+	assert.Equal(t, testkube.ExecutionStatusFailed, batch.Items[0].Result.Status)
+	assert.NoError(t, batch.Items[1].Err)
+	assert.Equal(t, testkube.ExecutionStatusAborted, batch.Items[1].Result.Status)
+	assert.Contains(t, batch.Items[1].Result.ErrorMessage, "aborted by batch batch-1")
+	assert.Equal(t, []string{batch.Items[1].ExecutionID}, fake.Aborted())
+}
+
+func TestExecutionRunner_AbortUnfinishedKeepsFinishedResult(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the execution passes while the batch is being aborted
+	passed := &testkube.ExecutionResult{Status: testkube.ExecutionStatusPassed}
+	mockExecutor := NewMockExecutor(ctrl)
+	mockExecutor.EXPECT().Abort(gomock.Any(), gomock.Any()).
+		Return(passed, &AlreadyFinishedError{ExecutionID: "exec-1", Result: passed})
+
+	results := []BatchItemResult{{ExecutionID: "exec-1", Result: testkube.NewRunningExecutionResult()}}
+	errs := newTestExecutionRunner(mockExecutor, nil, &recordingClock{}).abortUnfinished(context.Background(),
+		[]*testkube.Execution{{Id: "exec-1", ExecutionResult: testkube.NewRunningExecutionResult()}}, results)
+
+	assert.Empty(t, errs)
+	assert.NoError(t, results[0].Err)
+	assert.True(t, results[0].IsPassed())
+}
+
+func TestExecutionRunner_ExecuteBatchFailFastSkips(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// with a single submission slot only the first submitted item is started
+	startErr := errors.New("job creation failed")
+	mockExecutor := NewMockExecutor(ctrl)
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, startErr).Times(1)
+
+	batch, err := newTestExecutionRunner(mockExecutor, &completingExecutions{}, &recordingClock{}).ExecuteBatch(context.Background(),
+		[]ExecuteOptions{{TestName: "shard"}, {TestName: "shard"}, {TestName: "shard"}}, BatchOptions{Parallelism: 1, FailFast: true})
+
+	require.NoError(t, err)
+	var started, skipped int
+	for _, item := range batch.Items {
+		if errors.Is(item.Err, ErrBatchItemSkipped) {
+			skipped++
+		} else if errors.Is(item.Err, startErr) {
+			started++
+		}
+	}
+	assert.Equal(t, 1, started)
+	assert.Equal(t, 2, skipped)
+	assert.False(t, batch.Passed)
+}
+
+func TestExecutionRunner_ExecuteBatchChunkedShards(t *testing.T) {
+	// shards come from the expression engine, e.g. from a test workflow matrix
+	shards, err := expressionstcl.MustCompile(`chunk(["a.spec.js","b.spec.js","c.spec.js","d.spec.js","e.spec.js"], 2)`).Static().SliceValue()
+	require.NoError(t, err)
+
+	fake := NewFakeExecutor(t)
+	items := make([]ExecuteOptions, len(shards))
+	for i, shard := range shards {
+		name := fmt.Sprintf("cypress-shard-%d", i)
+		fake.ExpectExecution(name)
+		items[i] = ExecuteOptions{TestName: name}
+		for _, file := range shard.([]interface{}) {
+			items[i].Request.Args = append(items[i].Request.Args, file.(string))
+		}
+	}
+
+	batch, err := newTestExecutionRunner(fake, fake, &recordingClock{}).ExecuteBatch(context.Background(), items, BatchOptions{Parallelism: 2})
+
+	require.NoError(t, err)
+	assert.True(t, batch.Passed)
+	assert.Len(t, batch.Items, 3)
+	var args [][]string
+	for _, options := range fake.ExecuteOptions() {
+		args = append(args, options.Request.Args)
+	}
+	assert.Equal(t, [][]string{{"a.spec.js", "b.spec.js"}, {"c.spec.js", "d.spec.js"}, {"e.spec.js"}}, args)
+}
+
+func TestExecutionRunner_ExecuteBatchMatrix(t *testing.T) {
+	combinations, err := expressionstcl.MustCompile(`matrix({"browser": ["chrome", "firefox"], "viewport": ["mobile", "desktop"]}, [{"browser": "firefox", "viewport": "mobile"}])`).Static().SliceValue()
+	require.NoError(t, err)
+
+	fake := NewFakeExecutor(t)
+	items := make([]ExecuteOptions, len(combinations))
+	for i, combination := range combinations {
+		values := combination.(map[string]interface{})
+		name := fmt.Sprintf("e2e-%s-%s", values["browser"], values["viewport"])
+		fake.ExpectExecution(name)
+		items[i] = ExecuteOptions{TestName: name}
+	}
+
+	batch, err := newTestExecutionRunner(fake, fake, &recordingClock{}).ExecuteBatch(context.Background(), items, BatchOptions{Parallelism: 1})
+
+	require.NoError(t, err)
+	assert.True(t, batch.Passed)
+	var names []string
+	for _, options := range fake.ExecuteOptions() {
+		names = append(names, options.TestName)
+	}
+	assert.Equal(t, []string{"e2e-chrome-mobile", "e2e-chrome-desktop", "e2e-firefox-desktop"}, names)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Capabilities describe which execute options are honored by the executor, options for unsupported
+// capabilities are ignored by the executor
+type Capabilities struct {
+	// SupportsAbort is set when running executions can be aborted
+	SupportsAbort bool
+	// SupportsLogStream is set when logs can be followed line by line while execution is running, see LogFollower
+	SupportsLogStream bool
+	// SupportsArtifacts is set when files matching the artifact request option are collected as execution artifacts
+	SupportsArtifacts bool
+	// SupportsResourceOverrides is set when execution container requests and limits can be set
+	SupportsResourceOverrides bool
+	// SupportsScheduling is set when node selector, tolerations and affinity can be set
+	SupportsScheduling bool
+	// SupportsServiceAccount is set when execution service account can be overridden
+	SupportsServiceAccount bool
+	// SupportsPriority is set when execution pod priority class can be set
+	SupportsPriority bool
+	// SupportsCommandOverride is set when command, args and working directory can be overridden
+	SupportsCommandOverride bool
+	// SupportsServices is set when service containers can be started alongside the test container
+	SupportsServices bool
+	// SupportsEnvs is set when environment variables, secret and config map references can be passed to the execution
+	SupportsEnvs bool
+	// SupportsOutputFormats is set when output not following the native runner protocol is parsed, see ResultParser
+	SupportsOutputFormats bool
+	// SupportsSharding is set when the execution can be split into parallel jobs, see Sharding
+	SupportsSharding bool
+	// MaxTimeout is a maximum execution timeout supported, zero means no limit
+	MaxTimeout time.Duration
+}
+
+// FullCapabilities are capabilities of executor supporting all execute options
+var FullCapabilities = Capabilities{
+	SupportsAbort:             true,
+	SupportsLogStream:         true,
+	SupportsArtifacts:         true,
+	SupportsResourceOverrides: true,
+	SupportsScheduling:        true,
+	SupportsServiceAccount:    true,
+	SupportsPriority:          true,
+	SupportsCommandOverride:   true,
+	SupportsServices:          true,
+	SupportsEnvs:              true,
+	SupportsOutputFormats:     true,
+	SupportsSharding:          true,
+}
+
+// CapabilitiesStrictness selects how options targeting unsupported capabilities are reported
+type CapabilitiesStrictness string
+
+const (
+	// CapabilitiesWarn reports unsupported options as warnings, options are still valid
+	CapabilitiesWarn CapabilitiesStrictness = "warn"
+	// CapabilitiesStrict reports unsupported options as validation errors
+	CapabilitiesStrict CapabilitiesStrictness = "strict"
+)
+
+// ErrUnsupportedOption is returned for options which are ignored by the executor
+var ErrUnsupportedOption = errors.New("option is not supported by the executor")
+
+// ValidateCapabilities checks that set options are supported by the executor, all unsupported options are returned together
+func (o ExecuteOptions) ValidateCapabilities(capabilities Capabilities) error {
+	var errs []error
+	unsupported := func(supported bool, set bool, option string) {
+		if set && !supported {
+			errs = append(errs, fmt.Errorf("%s: %w", option, ErrUnsupportedOption))
+		}
+	}
+
+	unsupported(capabilities.SupportsArtifacts, o.ArtifactRequest != nil, "artifact request")
+	unsupported(capabilities.SupportsResourceOverrides, o.Resources != nil, "resources")
+	unsupported(capabilities.SupportsScheduling, len(o.NodeSelector) != 0, "node selector")
+	unsupported(capabilities.SupportsScheduling, len(o.Tolerations) != 0, "tolerations")
+	unsupported(capabilities.SupportsScheduling, o.Affinity != nil, "affinity")
+	unsupported(capabilities.SupportsServiceAccount, o.ServiceAccountName != "", "service account name")
+	unsupported(capabilities.SupportsPriority, o.Priority != nil, "priority")
+	unsupported(capabilities.SupportsCommandOverride, len(o.Command) != 0, "command")
+	unsupported(capabilities.SupportsCommandOverride, len(o.Args) != 0, "args")
+	unsupported(capabilities.SupportsCommandOverride, o.WorkingDir != "", "working dir")
+	unsupported(capabilities.SupportsServices, len(o.Services) != 0, "services")
+	unsupported(capabilities.SupportsEnvs, len(o.Envs) != 0, "envs")
+	unsupported(capabilities.SupportsEnvs, len(o.SecretEnvs) != 0, "secret envs")
+	unsupported(capabilities.SupportsEnvs, len(o.ConfigMapEnvs) != 0, "config map envs")
+	unsupported(capabilities.SupportsOutputFormats, o.OutputFormat != OutputFormatNative, "output format")
+	unsupported(capabilities.SupportsSharding, o.Sharding.IsSharded(), "sharding")
+
+	if capabilities.MaxTimeout > 0 && o.Timeout > capabilities.MaxTimeout {
+		errs = append(errs, fmt.Errorf("timeout %s exceeds executor maximum %s: %w", o.Timeout, capabilities.MaxTimeout, ErrUnsupportedOption))
+	}
+
+	return errors.Join(errs...)
+}
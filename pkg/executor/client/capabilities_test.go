@@ -0,0 +1,113 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/scraper"
+)
+
+func TestExecutorCapabilities_LogStream(t *testing.T) {
+	executors := map[string]Executor{
+		"job":  &JobExecutor{},
+		"grpc": NewGRPCExecutor(zap.NewNop().Sugar(), GRPCOptions{}, nil, nil),
+		"fake": NewFakeExecutor(t),
+	}
+
+	for name, executor := range executors {
+		_, follower := executor.(LogFollower)
+		assert.Equal(t, follower, executor.Capabilities().SupportsLogStream, name)
+		assert.True(t, executor.Capabilities().SupportsAbort, name)
+	}
+}
+
+func TestGRPCExecutor_CapabilitiesMatchRequest(t *testing.T) {
+	capabilities := NewGRPCExecutor(zap.NewNop().Sugar(), GRPCOptions{}, nil, nil).Capabilities()
+	execution := testkube.Execution{Id: "exec-1"}
+
+	plain := grpcExecuteOptions(false)
+	ignored := grpcExecuteOptions(false)
+	ignored.Resources = &Resources{Limits: ResourceList{CPU: "1"}}
+	ignored.NodeSelector = map[string]string{"pool": "devices"}
+	ignored.Tolerations = []corev1.Toleration{{Key: "devices", Operator: corev1.TolerationOpExists}}
+	ignored.ServiceAccountName = "runner"
+	ignored.Priority = &Priority{Value: 10}
+	ignored.ArtifactRequest = &scraper.ArtifactRequest{Patterns: []string{"reports/**"}}
+
+	assert.Equal(t, NewRunnerExecuteRequest(execution, plain), NewRunnerExecuteRequest(execution, ignored))
+	assert.EqualError(t, ignored.ValidateCapabilities(capabilities), "artifact request: option is not supported by the executor\n"+
+		"resources: option is not supported by the executor\n"+
+		"node selector: option is not supported by the executor\n"+
+		"tolerations: option is not supported by the executor\n"+
+		"service account name: option is not supported by the executor\n"+
+		"priority: option is not supported by the executor")
+
+	honored := grpcExecuteOptions(false)
+	honored.Command = []string{"farm-debug"}
+	honored.WorkingDir = "e2e"
+
+	assert.NotEqual(t, NewRunnerExecuteRequest(execution, plain), NewRunnerExecuteRequest(execution, honored))
+	assert.NoError(t, honored.ValidateCapabilities(capabilities))
+}
+
+func TestExecuteOptions_ValidateCapabilities(t *testing.T) {
+	options := ExecuteOptions{Timeout: 2 * time.Hour, Args: []string{"-v"}}
+
+	assert.NoError(t, options.ValidateCapabilities(FullCapabilities))
+
+	err := options.ValidateCapabilities(Capabilities{MaxTimeout: time.Hour})
+	assert.True(t, errors.Is(err, ErrUnsupportedOption))
+	assert.EqualError(t, err, "args: option is not supported by the executor\n"+
+		"timeout 2h0m0s exceeds executor maximum 1h0m0s: option is not supported by the executor")
+}
+
+func TestExecuteOptionsBuilder_Capabilities(t *testing.T) {
+	grpcCapabilities := NewGRPCExecutor(zap.NewNop().Sugar(), GRPCOptions{}, nil, nil).Capabilities()
+
+	t.Run("warn", func(t *testing.T) {
+		builder := validBuilder().
+			WithNodeSelector(map[string]string{"pool": "devices"}).
+			WithCapabilities(grpcCapabilities, CapabilitiesWarn)
+		options, err := builder.Build()
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"pool": "devices"}, options.NodeSelector)
+		assert.EqualError(t, builder.Warnings(), "node selector: option is not supported by the executor")
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		builder := validBuilder().
+			WithID("").
+			WithNodeSelector(map[string]string{"pool": "devices"}).
+			WithCapabilities(grpcCapabilities, CapabilitiesStrict)
+		_, err := builder.Build()
+
+		assert.EqualError(t, err, "execution id is required\n"+
+			"node selector: option is not supported by the executor")
+		assert.NoError(t, builder.Warnings())
+	})
+
+	t.Run("supported", func(t *testing.T) {
+		builder := validBuilder().
+			WithNodeSelector(map[string]string{"pool": "devices"}).
+			WithCapabilities(FullCapabilities, CapabilitiesStrict)
+		_, err := builder.Build()
+
+		assert.NoError(t, err)
+		assert.NoError(t, builder.Warnings())
+	})
+}
+
+func TestFakeExecutor_Capabilities(t *testing.T) {
+	executor := NewFakeExecutor(t)
+	assert.Equal(t, FullCapabilities, executor.Capabilities())
+
+	executor.WithCapabilities(Capabilities{SupportsAbort: true})
+	assert.Equal(t, Capabilities{SupportsAbort: true}, executor.Capabilities())
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+const (
+	// ChainIDLabel links executions started by the same chain
+	ChainIDLabel = "testkube.io/chain-id"
+	// ChainPreviousLabel is set on the next execution of the chain to ID of the execution it's conditioned on
+	ChainPreviousLabel = "testkube.io/chain-previous"
+
+	// ChainConditionField is the key of condition text in resolved expressions of the next execution
+	ChainConditionField = "chain.condition"
+	// ChainConditionValueField is the key of resolved condition value in resolved expressions of the next execution
+	ChainConditionValueField = "chain.conditionValue"
+)
+
+// ChainConditionError is returned when the chain condition can't be evaluated or it's not a boolean,
+// it's distinguishable from the condition resolved to false, which is not an error
+type ChainConditionError struct {
+	Condition string
+	Err       error
+}
+
+func (e *ChainConditionError) Error() string {
+	return fmt.Sprintf("evaluating chain condition %q: %v", e.Condition, e.Err)
+}
+
+func (e *ChainConditionError) Unwrap() error {
+	return e.Err
+}
+
+// ChainDecision tells if the next execution of the chain was started
+type ChainDecision string
+
+const (
+	// ChainNextStarted is the decision when the condition resolved to true
+	ChainNextStarted ChainDecision = "started"
+	// ChainNextSkipped is the decision when the condition resolved to false
+	ChainNextSkipped ChainDecision = "skipped"
+	// ChainConditionFailed is the decision when the condition couldn't be evaluated
+	ChainConditionFailed ChainDecision = "conditionFailed"
+	// ChainFirstUnfinished is the decision when the first execution couldn't be started or watched until terminal state
+	ChainFirstUnfinished ChainDecision = "firstUnfinished"
+)
+
+// ChainResult is an outcome of the chained executions, it's a skip record when the next execution wasn't started
+type ChainResult struct {
+	// ID is set as ChainIDLabel on both executions
+	ID string
+	// First is the execution the condition is evaluated on
+	First *testkube.Execution
+	// Condition is the expression evaluated over the first execution result
+	Condition string
+	// ConditionValue is the resolved condition, it's nil when the condition wasn't evaluated
+	ConditionValue interface{}
+	Decision       ChainDecision
+	// Next is the started next execution, it's nil when the next execution was skipped
+	Next *testkube.Execution
+}
+
+// ChainExecutions runs the first execution until it reaches terminal state, evaluates the condition over its result,
+// see EvaluateOnResult, and starts the next execution only when the condition is true, without waiting for it;
+// the returned result records the decision also when the next execution wasn't started
+func (r *ExecutionRunner) ChainExecutions(ctx context.Context, first ExecuteOptions, condition string, next ExecuteOptions) (*ChainResult, error) {
+	if _, err := expressionstcl.Compile(condition); err != nil {
+		return nil, &ChainConditionError{Condition: condition, Err: err}
+	}
+
+	chain := &ChainResult{ID: primitive.NewObjectID().Hex(), Condition: condition, Decision: ChainFirstUnfinished}
+	execution, options := newChainExecution(chain.ID, "", first)
+	chain.First = execution
+	if _, err := r.ExecuteSync(ctx, execution, options); err != nil {
+		return chain, fmt.Errorf("running first chain execution: %w", err)
+	}
+
+	value, err := EvaluateOnResult(condition, *execution)
+	if err == nil {
+		if _, ok := value.(bool); !ok {
+			err = fmt.Errorf("condition should resolve to boolean, %v provided", value)
+		}
+	}
+	if err != nil {
+		chain.Decision = ChainConditionFailed
+		return chain, &ChainConditionError{Condition: condition, Err: err}
+	}
+
+	chain.ConditionValue = value
+	if !value.(bool) {
+		chain.Decision = ChainNextSkipped
+		return chain, nil
+	}
+
+	execution, options = newChainExecution(chain.ID, chain.First.Id, next)
+	execution.ResolvedExpressions = map[string]string{
+		ChainConditionField:      condition,
+		ChainConditionValueField: fmt.Sprint(value),
+	}
+	chain.Next = execution
+	if _, err = r.ExecuteAsync(ctx, execution, options); err != nil {
+		return chain, fmt.Errorf("starting next chain execution: %w", err)
+	}
+
+	chain.Decision = ChainNextStarted
+	return chain, nil
+}
+
+// IsConditionError checks if the chain failed because of the condition, and not the executions
+func IsConditionError(err error) bool {
+	var conditionErr *ChainConditionError
+	return errors.As(err, &conditionErr)
+}
+
+func newChainExecution(chainID, previousID string, options ExecuteOptions) (*testkube.Execution, ExecuteOptions) {
+	id := options.ID
+	if id == "" {
+		id = primitive.NewObjectID().Hex()
+	}
+
+	labels := make(map[string]string, len(options.Labels)+2)
+	for key, value := range options.Labels {
+		labels[key] = value
+	}
+	labels[ChainIDLabel] = chainID
+	if previousID != "" {
+		labels[ChainPreviousLabel] = previousID
+	}
+
+	options.ID = id
+	options.Labels = labels
+	execution := testkube.NewExecutionWithID(id, options.TestSpec.Type_, options.TestName)
+	execution.TestNamespace = options.Namespace
+	execution.Labels = labels
+	return execution, options
+}
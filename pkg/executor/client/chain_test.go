@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func TestExecutionRunner_ChainExecutions(t *testing.T) {
+	tests := map[string]struct {
+		status    testkube.ExecutionStatus
+		condition string
+		decision  ChainDecision
+	}{
+		"passed started":      {status: testkube.PASSED_ExecutionStatus, condition: `result.status == "passed"`, decision: ChainNextStarted},
+		"failed skipped":      {status: testkube.FAILED_ExecutionStatus, condition: `result.status == "passed"`, decision: ChainNextSkipped},
+		"timeout skipped":     {status: testkube.TIMEOUT_ExecutionStatus, condition: `result.status == "passed"`, decision: ChainNextSkipped},
+		"timeout started":     {status: testkube.TIMEOUT_ExecutionStatus, condition: `result.status == "timeout"`, decision: ChainNextStarted},
+		"failed on condition": {status: testkube.FAILED_ExecutionStatus, condition: `result.status != "passed"`, decision: ChainNextStarted},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := NewFakeExecutor(t)
+			fake.ExpectExecution("smoke").WithStatuses(testkube.RUNNING_ExecutionStatus, tt.status)
+			if tt.decision == ChainNextStarted {
+				fake.ExpectExecution("full")
+			}
+
+			chain, err := newTestExecutionRunner(fake, fake, &recordingClock{}).ChainExecutions(context.Background(),
+				ExecuteOptions{TestName: "smoke", Labels: map[string]string{"suite": "e2e"}}, tt.condition, ExecuteOptions{TestName: "full"})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.decision, chain.Decision)
+			assert.Equal(t, tt.condition, chain.Condition)
+			assert.Equal(t, tt.decision == ChainNextStarted, chain.ConditionValue)
+			assert.Equal(t, tt.status, *chain.First.ExecutionResult.Status)
+			assert.Equal(t, map[string]string{"suite": "e2e", ChainIDLabel: chain.ID}, chain.First.Labels)
+			if tt.decision != ChainNextStarted {
+				assert.Nil(t, chain.Next)
+				assert.Len(t, fake.ExecuteOptions(), 1)
+				return
+			}
+
+			require.NotNil(t, chain.Next)
+			assert.Equal(t, map[string]string{ChainIDLabel: chain.ID, ChainPreviousLabel: chain.First.Id}, chain.Next.Labels)
+			assert.Equal(t, map[string]string{ChainConditionField: tt.condition, ChainConditionValueField: "true"}, chain.Next.ResolvedExpressions)
+			options := fake.ExecuteOptions()
+			require.Len(t, options, 2)
+			assert.Equal(t, chain.Next.Labels, options[1].Labels)
+			assert.False(t, options[1].Sync)
+		})
+	}
+}
+
+func TestExecutionRunner_ChainExecutionsConditionError(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("smoke")
+
+	chain, err := newTestExecutionRunner(fake, fake, &recordingClock{}).ChainExecutions(context.Background(),
+		ExecuteOptions{TestName: "smoke"}, `result.status`, ExecuteOptions{TestName: "full"})
+
+	assert.True(t, IsConditionError(err))
+	assert.ErrorContains(t, err, "condition should resolve to boolean")
+	assert.Equal(t, ChainConditionFailed, chain.Decision)
+	assert.Nil(t, chain.ConditionValue)
+	assert.Nil(t, chain.Next)
+}
+
+func TestExecutionRunner_ChainExecutionsInvalidCondition(t *testing.T) {
+	fake := NewFakeExecutor(t)
+
+	chain, err := newTestExecutionRunner(fake, fake, &recordingClock{}).ChainExecutions(context.Background(),
+		ExecuteOptions{TestName: "smoke"}, `result.status ==`, ExecuteOptions{TestName: "full"})
+
+	assert.True(t, IsConditionError(err))
+	assert.Nil(t, chain)
+	assert.Empty(t, fake.ExecuteOptions())
+}
+
+func TestExecutionRunner_ChainExecutionsFirstStartError(t *testing.T) {
+	startErr := errors.New("executor not found")
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("smoke").WithError(startErr)
+
+	chain, err := newTestExecutionRunner(fake, fake, &recordingClock{}).ChainExecutions(context.Background(),
+		ExecuteOptions{TestName: "smoke"}, `true`, ExecuteOptions{TestName: "full"})
+
+	assert.ErrorIs(t, err, startErr)
+	assert.False(t, IsConditionError(err))
+	assert.Equal(t, ChainFirstUnfinished, chain.Decision)
+	assert.Nil(t, chain.Next)
+}
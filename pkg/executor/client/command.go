@@ -0,0 +1,55 @@
+package client
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// CommandAndArgs applies execution command and args overrides on top of the defaults,
+// command replaces the default one when set and args are appended or replace the defaults according to args mode,
+// returned slices are always new, so callers can modify them
+func (o ExecuteOptions) CommandAndArgs(command, args []string) ([]string, []string) {
+	if len(o.Command) != 0 {
+		command = o.Command
+	}
+
+	switch o.ArgsMode {
+	case testkube.ArgsModeTypeOverride, testkube.ArgsModeTypeReplace:
+		args = o.Args
+	default:
+		args = append(append([]string(nil), args...), o.Args...)
+	}
+
+	return append([]string(nil), command...), append([]string(nil), args...)
+}
+
+// ResolveWorkingDir applies execution working directory override, relative override is resolved against repository directory
+func (o ExecuteOptions) ResolveWorkingDir(workingDir, repositoryDir string) string {
+	if o.WorkingDir == "" {
+		return workingDir
+	}
+
+	if filepath.IsAbs(o.WorkingDir) {
+		return o.WorkingDir
+	}
+
+	return filepath.Join(repositoryDir, o.WorkingDir)
+}
+
+func (o ExecuteOptions) validateCommand() (errs []error) {
+	switch o.ArgsMode {
+	case "", testkube.ArgsModeTypeAppend, testkube.ArgsModeTypeOverride, testkube.ArgsModeTypeReplace:
+	default:
+		errs = append(errs, fmt.Errorf("unknown args mode %s", o.ArgsMode))
+	}
+
+	for i, part := range o.Command {
+		if part == "" {
+			errs = append(errs, fmt.Errorf("command part %d can't be empty", i))
+		}
+	}
+
+	return errs
+}
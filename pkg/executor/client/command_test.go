@@ -0,0 +1,73 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func TestExecuteOptions_CommandAndArgs(t *testing.T) {
+	defaultCommand := []string{"npx", "cypress", "run"}
+	defaultArgs := []string{"--browser", "chrome"}
+
+	tests := map[string]struct {
+		options     ExecuteOptions
+		wantCommand []string
+		wantArgs    []string
+	}{
+		"defaults": {
+			wantCommand: defaultCommand,
+			wantArgs:    defaultArgs,
+		},
+		"append args": {
+			options:     ExecuteOptions{Args: []string{"--grep", "smoke"}},
+			wantCommand: defaultCommand,
+			wantArgs:    []string{"--browser", "chrome", "--grep", "smoke"},
+		},
+		"override args": {
+			options:     ExecuteOptions{Args: []string{"--grep", "smoke"}, ArgsMode: testkube.ArgsModeTypeOverride},
+			wantCommand: defaultCommand,
+			wantArgs:    []string{"--grep", "smoke"},
+		},
+		"replace args with nothing": {
+			options:     ExecuteOptions{ArgsMode: testkube.ArgsModeTypeReplace},
+			wantCommand: defaultCommand,
+			wantArgs:    nil,
+		},
+		"command override": {
+			options:     ExecuteOptions{Command: []string{"npm", "test"}},
+			wantCommand: []string{"npm", "test"},
+			wantArgs:    defaultArgs,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			command, args := tt.options.CommandAndArgs(defaultCommand, defaultArgs)
+
+			assert.Equal(t, tt.wantCommand, command)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestExecuteOptions_CommandAndArgsDoesNotAlias(t *testing.T) {
+	defaultArgs := make([]string, 1, 4)
+	defaultArgs[0] = "--verbose"
+	options := ExecuteOptions{Command: []string{"k6"}, Args: []string{"--quiet"}}
+
+	command, args := options.CommandAndArgs(nil, defaultArgs)
+	command[0] = `"k6"`
+	args[0] = `"--verbose"`
+
+	assert.Equal(t, []string{"k6"}, options.Command)
+	assert.Equal(t, []string{"--verbose"}, defaultArgs)
+}
+
+func TestExecuteOptions_ResolveWorkingDir(t *testing.T) {
+	assert.Equal(t, "/data/repo/tests", ExecuteOptions{}.ResolveWorkingDir("/data/repo/tests", "/data/repo"))
+	assert.Equal(t, "/data/repo/e2e/smoke", ExecuteOptions{WorkingDir: "e2e/smoke"}.ResolveWorkingDir("/data/repo/tests", "/data/repo"))
+	assert.Equal(t, "/tmp", ExecuteOptions{WorkingDir: "/tmp"}.ResolveWorkingDir("/data/repo/tests", "/data/repo"))
+}
@@ -2,7 +2,10 @@ package client
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"time"
 
 	"go.uber.org/zap"
@@ -14,6 +17,7 @@ import (
 	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
 	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
 	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/scraper"
 	"github.com/kubeshop/testkube/pkg/featureflags"
 	"github.com/kubeshop/testkube/pkg/utils"
 )
@@ -40,6 +44,213 @@ type ExecuteOptions struct {
 	AgentAPITLSSecret    string
 	ImagePullSecretNames []string
 	Features             featureflags.FeatureFlags
+	// Timeout is a maximum duration of the execution, zero means no limit
+	Timeout time.Duration
+	// Resources are execution container requests and limits
+	Resources *Resources
+	// RetryPolicy defines how failed executions are retried
+	RetryPolicy *RetryPolicy
+	// NodeSelector is merged into the executor job template node selector, execution values win for the same key
+	NodeSelector map[string]string
+	// Tolerations are merged into the executor job template tolerations, replacing ones with the same key and effect,
+	// they are set by the executor client callers only, execution request has no tolerations
+	Tolerations []corev1.Toleration
+	// Affinity replaces the executor job template affinity when set, like Tolerations it's not a part of execution request
+	Affinity *corev1.Affinity
+	// ServiceAccountName overrides the executor service account of the execution namespace when set
+	ServiceAccountName string
+	// Command replaces the resolved container command when set, the execution request command and args are resolved
+	// into Request by the scheduler, so Command, Args and ArgsMode are set by the executor client callers only
+	Command []string
+	// Args are added to the resolved container args according to ArgsMode
+	Args []string
+	// ArgsMode selects if Args are appended to the resolved args or replace them, defaults to append
+	ArgsMode testkube.ArgsModeType
+	// WorkingDir replaces the container working directory when set, relative path is resolved against repository directory
+	WorkingDir string
+	// ArtifactRequest defines files collected as execution artifacts after the test run
+	ArtifactRequest *scraper.ArtifactRequest
+	// Priority orders queued executions and sets execution pod priority class
+	Priority *Priority
+	// RunAfter is the earliest time the execution is started at
+	RunAfter time.Time
+	// Delay postpones execution start by the duration, when RunAfter is set too the later time wins
+	Delay time.Duration
+	// IdempotencyKey deduplicates submissions, execution with the same key is returned instead of starting new one
+	IdempotencyKey string
+	// Services are auxiliary containers started before the test container and stopped after it finished,
+	// they are set by the executor client callers only, execution request has no services
+	Services []Service
+	// Envs are execution container environment variables, they win over the secret and config map ones;
+	// Envs, SecretEnvs and ConfigMapEnvs are set by the executor client callers only, execution request
+	// passes them as variables, env config maps and env secrets in Request
+	Envs map[string]string
+	// SecretEnvs expose secret keys, or whole secrets, as execution container environment variables
+	SecretEnvs []SecretRef
+	// ConfigMapEnvs expose config map keys, or whole config maps, as execution container environment variables
+	ConfigMapEnvs []ConfigMapRef
+	// Annotations are set on the job, its pod template and the persistent volume claim created for the execution
+	Annotations map[string]string
+	// OutputFormat selects the parser of the execution container output, empty format is the native runner protocol
+	OutputFormat OutputFormat
+	// Sharding splits the execution into parallel jobs, their results are merged into the execution result
+	Sharding *Sharding
+	// FieldOrigins tell where the effective values of the Request fields came from, see MergeExecutionRequest
+	FieldOrigins []FieldOrigin
+}
+
+// NewExecuteOptions creates execute options with initialized maps, so values can be added directly
+func NewExecuteOptions() ExecuteOptions {
+	return ExecuteOptions{
+		Labels:       make(map[string]string),
+		Annotations:  make(map[string]string),
+		NodeSelector: make(map[string]string),
+		Envs:         make(map[string]string),
+	}
+}
+
+var (
+	// ErrNegativeTimeout is returned when execute options have negative timeout
+	ErrNegativeTimeout = errors.New("execution timeout can't be negative")
+	// ErrNegativeDelay is returned when execute options have negative delay
+	ErrNegativeDelay = errors.New("execution delay can't be negative")
+)
+
+// Validate checks if execute options are valid, all found problems are returned together
+func (o ExecuteOptions) Validate() error {
+	var errs []error
+	if o.ID == "" {
+		errs = append(errs, errors.New("execution id is required"))
+	}
+
+	if o.TestName == "" {
+		errs = append(errs, errors.New("test name is required"))
+	}
+
+	if o.Timeout < 0 {
+		errs = append(errs, ErrNegativeTimeout)
+	}
+
+	if o.Delay < 0 {
+		errs = append(errs, ErrNegativeDelay)
+	}
+
+	errs = append(errs, o.validateExecutor()...)
+	errs = append(errs, o.validateContent()...)
+	errs = append(errs, o.validateCommand()...)
+	errs = append(errs, o.validateServices()...)
+	errs = append(errs, o.validateEnvs()...)
+	errs = append(errs, o.validateMetadata()...)
+
+	if o.Resources != nil {
+		if err := o.Resources.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if o.RetryPolicy != nil {
+		if err := o.RetryPolicy.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, ValidateTolerations(o.Tolerations)...)
+
+	if o.ArtifactRequest != nil {
+		errs = append(errs, o.ArtifactRequest.Validate()...)
+	}
+
+	if o.Priority != nil {
+		if err := o.Priority.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if o.Sharding != nil {
+		if err := o.Sharding.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (o ExecuteOptions) validateExecutor() (errs []error) {
+	switch o.ExecutorSpec.ExecutorType {
+	case "", executorv1.ExecutorTypeJob:
+	case executorv1.ExecutorTypeContainer:
+		if o.ExecutorSpec.Image == "" && o.Request.Image == "" &&
+			(o.TestSpec.ExecutionRequest == nil || o.TestSpec.ExecutionRequest.Image == "") {
+			errs = append(errs, fmt.Errorf("container executor %s requires image", o.ExecutorName))
+		}
+	case ExecutorTypeGRPC:
+		if o.ExecutorSpec.URI == "" {
+			errs = append(errs, fmt.Errorf("grpc executor %s requires uri", o.ExecutorName))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown executor type %s", o.ExecutorSpec.ExecutorType))
+	}
+
+	if o.TestSpec.Type_ != "" && len(o.ExecutorSpec.Types) != 0 && !slices.Contains(o.ExecutorSpec.Types, o.TestSpec.Type_) {
+		errs = append(errs, fmt.Errorf("executor %s doesn't support test type %s", o.ExecutorName, o.TestSpec.Type_))
+	}
+
+	return errs
+}
+
+func (o ExecuteOptions) validateContent() (errs []error) {
+	content := o.TestSpec.Content
+	if o.Request.ContentRequest != nil && o.Request.ContentRequest.Repository != nil &&
+		(content == nil || content.Repository == nil) {
+		errs = append(errs, errors.New("repository content request requires test with git content"))
+	}
+
+	if content == nil {
+		return errs
+	}
+
+	if content.Type_ != "" && len(o.ExecutorSpec.ContentTypes) != 0 &&
+		!slices.Contains(o.ExecutorSpec.ContentTypes, executorv1.ScriptContentType(content.Type_)) {
+		errs = append(errs, fmt.Errorf("executor %s doesn't support content type %s", o.ExecutorName, content.Type_))
+	}
+
+	sources := 0
+	for _, isSet := range []bool{content.Data != "", content.Uri != "", content.Repository != nil} {
+		if isSet {
+			sources++
+		}
+	}
+
+	if sources > 1 {
+		errs = append(errs, errors.New("test content data, uri and repository are mutually exclusive"))
+	}
+
+	return errs
+}
+
+// StartAt returns time when the execution should start, the later of RunAfter and now increased by Delay
+func (o ExecuteOptions) StartAt(now time.Time) time.Time {
+	at := now.Add(o.Delay)
+	if o.RunAfter.After(at) {
+		return o.RunAfter
+	}
+
+	return at
+}
+
+// ActiveDeadlineSeconds returns job active deadline, taking the stricter of request deadline and timeout
+func (o ExecuteOptions) ActiveDeadlineSeconds() int64 {
+	deadline := o.Request.ActiveDeadlineSeconds
+	if o.Timeout <= 0 {
+		return deadline
+	}
+
+	timeout := int64(math.Ceil(o.Timeout.Seconds()))
+	if deadline <= 0 || timeout < deadline {
+		return timeout
+	}
+
+	return deadline
 }
 
 type PVCOptions struct {
@@ -48,6 +259,8 @@ type PVCOptions struct {
 	PvcTemplate           string
 	PvcTemplateExtensions string
 	ArtifactRequest       *testkube.ArtifactRequest
+	Labels                map[string]string
+	Annotations           map[string]string
 }
 
 // NewPersistentVolumeClaimSpec is a method to create new persistent volume claim spec
@@ -86,5 +299,7 @@ func NewPersistentVolumeClaimSpec(log *zap.SugaredLogger, options PVCOptions) (*
 		return nil, fmt.Errorf("decoding pvc spec error: %w", err)
 	}
 
+	ApplyMetadata(&pvc.ObjectMeta, options.Labels, options.Annotations)
+
 	return &pvc, nil
 }
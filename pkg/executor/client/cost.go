@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// DefaultEstimatedDuration is used for tests without finished executions in the history
+	DefaultEstimatedDuration = 5 * time.Minute
+	// costHistorySample is a number of latest executions used for the average duration
+	costHistorySample = 10
+
+	gibibyte = 1 << 30
+)
+
+// ErrCostBudgetExceeded is returned by ExecuteBatch when the estimated cost of the batch exceeds its budget
+var ErrCostBudgetExceeded = errors.New("estimated batch cost exceeds the budget")
+
+// CostEstimate is an estimated consumption of the executions, resources are taken from requests, or limits
+// when there are no requests, so the items without both are not included in CPU and memory totals
+type CostEstimate struct {
+	Executions int
+	// CPUSeconds is a sum of CPU cores multiplied by estimated duration in seconds
+	CPUSeconds float64
+	// MemoryGiBSeconds is a sum of memory in GiB multiplied by estimated duration in seconds
+	MemoryGiBSeconds float64
+	// WallClock is an estimated time to finish all executions with the given parallelism,
+	// assuming the executions are started in order as soon as there is a free slot
+	WallClock time.Duration
+	// ByExecutorType counts the executions by test type, or executor name when the type is not set
+	ByExecutorType map[string]int
+	// FromHistory is a number of executions with duration estimated from the history, others use the default duration
+	FromHistory int
+	// WithoutCPU and WithoutMemory are numbers of executions without known resource quantity
+	WithoutCPU    int
+	WithoutMemory int
+}
+
+// CostBudget limits the estimated cost of the batch, zero values are not limited
+type CostBudget struct {
+	CPUSeconds       float64
+	MemoryGiBSeconds float64
+	WallClock        time.Duration
+}
+
+// Exceeded lists the budget limits exceeded by the estimate
+func (b CostBudget) Exceeded(estimate CostEstimate) []string {
+	var exceeded []string
+	if b.CPUSeconds > 0 && estimate.CPUSeconds > b.CPUSeconds {
+		exceeded = append(exceeded, fmt.Sprintf("cpu %.0fs > %.0fs", estimate.CPUSeconds, b.CPUSeconds))
+	}
+	if b.MemoryGiBSeconds > 0 && estimate.MemoryGiBSeconds > b.MemoryGiBSeconds {
+		exceeded = append(exceeded, fmt.Sprintf("memory %.0fGiB*s > %.0fGiB*s", estimate.MemoryGiBSeconds, b.MemoryGiBSeconds))
+	}
+	if b.WallClock > 0 && estimate.WallClock > b.WallClock {
+		exceeded = append(exceeded, fmt.Sprintf("wall clock %s > %s", estimate.WallClock, b.WallClock))
+	}
+	return exceeded
+}
+
+// WithCostEstimation sets the history used for the average durations of tests, and duration used for tests without it;
+// nil history or zero duration are using defaults
+func (r *ExecutionRunner) WithCostEstimation(history ExecutionHistory, defaultDuration time.Duration) *ExecutionRunner {
+	r.history = history
+	r.defaultDuration = defaultDuration
+	return r
+}
+
+// EstimateCost estimates consumption of the executions run with given parallelism, zero runs all at once
+func (r *ExecutionRunner) EstimateCost(ctx context.Context, items []ExecuteOptions, parallelism int) (*CostEstimate, error) {
+	if parallelism < 0 {
+		return nil, errors.New("parallelism can't be negative")
+	}
+
+	estimate := &CostEstimate{Executions: len(items), ByExecutorType: make(map[string]int)}
+	durations := make([]time.Duration, len(items))
+	averages := make(map[string]time.Duration)
+	for i, item := range items {
+		duration, ok := averages[item.TestName]
+		if !ok {
+			var err error
+			if duration, err = r.averageDuration(ctx, item.TestName); err != nil {
+				return nil, err
+			}
+			averages[item.TestName] = duration
+		}
+		if duration > 0 {
+			estimate.FromHistory++
+		} else {
+			duration = r.defaultDuration
+			if duration <= 0 {
+				duration = DefaultEstimatedDuration
+			}
+		}
+		durations[i] = duration
+
+		executorType := item.TestSpec.Type_
+		if executorType == "" {
+			executorType = item.ExecutorName
+		}
+		estimate.ByExecutorType[executorType]++
+
+		cpu, memory := itemResources(item.Resources)
+		if cpu < 0 {
+			estimate.WithoutCPU++
+		} else {
+			estimate.CPUSeconds += cpu * duration.Seconds()
+		}
+		if memory < 0 {
+			estimate.WithoutMemory++
+		} else {
+			estimate.MemoryGiBSeconds += memory / gibibyte * duration.Seconds()
+		}
+	}
+
+	estimate.WallClock = wallClock(durations, parallelism)
+	return estimate, nil
+}
+
+// averageDuration returns average duration of the latest finished executions of the test, or zero without history
+func (r *ExecutionRunner) averageDuration(ctx context.Context, testName string) (time.Duration, error) {
+	if r.history == nil || testName == "" {
+		return 0, nil
+	}
+
+	executions, err := r.history.ListExecutions(ctx, testName, time.Time{}, costHistorySample)
+	if err != nil {
+		return 0, fmt.Errorf("listing executions of %s test: %w", testName, err)
+	}
+
+	var total float64
+	count := 0
+	for _, execution := range executions {
+		if seconds := resultDuration(execution).Seconds(); isFinishedExecution(execution) && seconds > 0 {
+			total += seconds
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return time.Duration(total / float64(count) * float64(time.Second)), nil
+}
+
+// itemResources returns CPU cores and memory bytes requested by the execution, or limited when there are no requests,
+// negative values are unknown, e.g. not set or still containing expressions
+func itemResources(resources *Resources) (cpu float64, memory float64) {
+	if resources == nil {
+		return -1, -1
+	}
+	return quantityValue(resources.Requests.CPU, resources.Limits.CPU), quantityValue(resources.Requests.Memory, resources.Limits.Memory)
+}
+
+func quantityValue(values ...string) float64 {
+	for _, value := range values {
+		if value == "" || isExpression(value) {
+			continue
+		}
+		if quantity, err := resource.ParseQuantity(value); err == nil {
+			return quantity.AsApproximateFloat64()
+		}
+	}
+	return -1
+}
+
+// wallClock simulates running the executions in order, each one on the slot which gets free first
+func wallClock(durations []time.Duration, parallelism int) time.Duration {
+	if parallelism == 0 || parallelism > len(durations) {
+		parallelism = len(durations)
+	}
+	if parallelism == 0 {
+		return 0
+	}
+
+	slots := make([]time.Duration, parallelism)
+	for _, duration := range durations {
+		sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+		slots[0] += duration
+	}
+
+	var total time.Duration
+	for _, slot := range slots {
+		total = max(total, slot)
+	}
+	return total
+}
+
+// checkCostBudget refuses the batch when its estimated cost exceeds the budget
+func (r *ExecutionRunner) checkCostBudget(ctx context.Context, items []ExecuteOptions, options BatchOptions) (*CostEstimate, error) {
+	estimate, err := r.EstimateCost(ctx, items, options.Parallelism)
+	if err != nil {
+		return nil, fmt.Errorf("estimating batch cost: %w", err)
+	}
+	if exceeded := options.CostBudget.Exceeded(*estimate); len(exceeded) > 0 && !options.ForceOverBudget {
+		return estimate, fmt.Errorf("%w: %s", ErrCostBudgetExceeded, strings.Join(exceeded, ", "))
+	}
+	return estimate, nil
+}
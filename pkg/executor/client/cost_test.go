@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func newCostHistory() *memoryHistory {
+	now := time.Now()
+	history := &memoryHistory{}
+	history.Add("api", testkube.PASSED_ExecutionStatus, now.Add(-2*time.Hour), time.Minute)
+	history.Add("api", testkube.FAILED_ExecutionStatus, now.Add(-time.Hour), 2*time.Minute)
+	// running execution doesn't have final duration yet
+	history.Add("api", testkube.RUNNING_ExecutionStatus, now, 0)
+	return history
+}
+
+func costTestItems() []ExecuteOptions {
+	return []ExecuteOptions{
+		{TestName: "api", TestSpec: testsv3.TestSpec{Type_: "k6/script"}, Resources: &Resources{Requests: ResourceList{CPU: "500m", Memory: "1Gi"}}},
+		{TestName: "api", TestSpec: testsv3.TestSpec{Type_: "k6/script"}, Resources: &Resources{Requests: ResourceList{CPU: "1"}}},
+		{TestName: "web", ExecutorName: "curl", Resources: &Resources{Limits: ResourceList{CPU: "2", Memory: "512Mi"}}},
+		{TestName: "web", ExecutorName: "curl"},
+	}
+}
+
+func TestExecutionRunner_EstimateCost(t *testing.T) {
+	history := newCostHistory()
+	runner := newTestExecutionRunner(NewFakeExecutor(t), nil, &recordingClock{}).WithCostEstimation(history, 30*time.Second)
+
+	estimate, err := runner.EstimateCost(context.Background(), costTestItems(), 2)
+	require.NoError(t, err)
+
+	// api takes 90s on average, web has no history, so it takes the default 30s
+	assert.Equal(t, &CostEstimate{
+		Executions:       4,
+		CPUSeconds:       0.5*90 + 1*90 + 2*30,
+		MemoryGiBSeconds: 1*90 + 0.5*30,
+		WallClock:        120 * time.Second,
+		ByExecutorType:   map[string]int{"k6/script": 2, "curl": 2},
+		FromHistory:      2,
+		WithoutCPU:       1,
+		WithoutMemory:    2,
+	}, estimate)
+
+	// history is read once per test
+	assert.Equal(t, 2, history.Queries())
+	assert.Equal(t, []int{costHistorySample, costHistorySample}, history.limits)
+}
+
+func TestExecutionRunner_EstimateCostWallClock(t *testing.T) {
+	runner := newTestExecutionRunner(NewFakeExecutor(t), nil, &recordingClock{}).WithCostEstimation(newCostHistory(), 30*time.Second)
+
+	tests := map[int]time.Duration{
+		0:  90 * time.Second,
+		1:  240 * time.Second,
+		2:  120 * time.Second,
+		3:  90 * time.Second,
+		10: 90 * time.Second,
+	}
+	for parallelism, expected := range tests {
+		estimate, err := runner.EstimateCost(context.Background(), costTestItems(), parallelism)
+		require.NoError(t, err)
+		assert.Equal(t, expected, estimate.WallClock, "parallelism %d", parallelism)
+	}
+
+	_, err := runner.EstimateCost(context.Background(), costTestItems(), -1)
+	assert.Error(t, err)
+}
+
+func TestExecutionRunner_EstimateCostDefaults(t *testing.T) {
+	runner := newTestExecutionRunner(NewFakeExecutor(t), nil, &recordingClock{})
+
+	estimate, err := runner.EstimateCost(context.Background(), []ExecuteOptions{
+		{TestName: "api", Resources: &Resources{Requests: ResourceList{CPU: "{{ 1 + 1 }}", Memory: "invalid"}, Limits: ResourceList{Memory: "2Gi"}}},
+	}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultEstimatedDuration, estimate.WallClock)
+	assert.Equal(t, 0, estimate.FromHistory)
+	// expressions are not resolved yet, so limits are used when they are known
+	assert.Equal(t, 1, estimate.WithoutCPU)
+	assert.Equal(t, 2*DefaultEstimatedDuration.Seconds(), estimate.MemoryGiBSeconds)
+
+	history := newCostHistory()
+	history.err = errors.New("store unavailable")
+	_, err = runner.WithCostEstimation(history, 0).EstimateCost(context.Background(), costTestItems(), 0)
+	assert.ErrorContains(t, err, "listing executions of api test: store unavailable")
+}
+
+func TestExecutionRunner_ExecuteBatchCostBudget(t *testing.T) {
+	items := []ExecuteOptions{
+		{TestName: "api", Resources: &Resources{Requests: ResourceList{CPU: "1"}}},
+		{TestName: "api", Resources: &Resources{Requests: ResourceList{CPU: "1"}}},
+	}
+
+	fake := NewFakeExecutor(t)
+	runner := newTestExecutionRunner(fake, fake, &recordingClock{}).WithCostEstimation(newCostHistory(), 0)
+	_, err := runner.ExecuteBatch(context.Background(), items, BatchOptions{
+		Parallelism: 1,
+		CostBudget:  &CostBudget{CPUSeconds: 100, WallClock: 2 * time.Minute},
+	})
+	assert.ErrorIs(t, err, ErrCostBudgetExceeded)
+	assert.EqualError(t, err, "estimated batch cost exceeds the budget: cpu 180s > 100s, wall clock 3m0s > 2m0s")
+	assert.Empty(t, fake.ExecuteOptions())
+
+	fake = NewFakeExecutor(t)
+	fake.ExpectExecution("api").WithStatuses(testkube.PASSED_ExecutionStatus)
+	fake.ExpectExecution("api").WithStatuses(testkube.PASSED_ExecutionStatus)
+	runner = newTestExecutionRunner(fake, fake, &recordingClock{}).WithCostEstimation(newCostHistory(), 0)
+	batch, err := runner.ExecuteBatch(context.Background(), items, BatchOptions{
+		Parallelism:     1,
+		CostBudget:      &CostBudget{CPUSeconds: 100},
+		ForceOverBudget: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, batch.Passed)
+	require.NotNil(t, batch.CostEstimate)
+	assert.Equal(t, 180.0, batch.CostEstimate.CPUSeconds)
+	assert.Len(t, fake.ExecuteOptions(), 2)
+}
+
+func TestCostBudget_Exceeded(t *testing.T) {
+	estimate := CostEstimate{CPUSeconds: 100, MemoryGiBSeconds: 50, WallClock: time.Minute}
+	assert.Empty(t, CostBudget{}.Exceeded(estimate))
+	assert.Empty(t, CostBudget{CPUSeconds: 100, MemoryGiBSeconds: 50, WallClock: time.Minute}.Exceeded(estimate))
+	assert.Equal(t, []string{"memory 50GiB*s > 10GiB*s"}, CostBudget{CPUSeconds: 200, MemoryGiBSeconds: 10}.Exceeded(estimate))
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// deadlineExceededReason is the reason of job condition and pod status set when active deadline passed
+const deadlineExceededReason = "DeadlineExceeded"
+
+// Clock abstracts time for delays, backoffs and expirations
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// NewRealClock returns clock based on system time
+func NewRealClock() Clock {
+	return realClock{}
+}
+
+// DeadlineWatcher enforces execution timeout for executors which don't enforce it themselves,
+// like gRPC runners and container executor jobs rendered from templates without active deadline
+type DeadlineWatcher struct {
+	executor Executor
+	clock    Clock
+}
+
+// NewDeadlineWatcher creates new deadline watcher for the executor
+func NewDeadlineWatcher(executor Executor, clock Clock) *DeadlineWatcher {
+	if clock == nil {
+		clock = NewRealClock()
+	}
+
+	return &DeadlineWatcher{
+		executor: executor,
+		clock:    clock,
+	}
+}
+
+// Watch blocks until the execution deadline passes or context is done, context is done when execution finishes;
+// when deadline passes the execution is aborted and marked as timed out
+func (w *DeadlineWatcher) Watch(ctx context.Context, execution *testkube.Execution, timeout time.Duration) (*testkube.ExecutionResult, error) {
+	if timeout < 0 {
+		return nil, ErrNegativeTimeout
+	}
+
+	if timeout == 0 {
+		return nil, nil
+	}
+
+	start := execution.StartTime
+	if start.IsZero() {
+		start = w.clock.Now()
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, nil
+	case <-w.clock.After(start.Add(timeout).Sub(w.clock.Now())):
+	}
+
+	// abort isn't interrupted when the execution finishes meanwhile
+	result, err := w.executor.Abort(WithTimedOut(context.WithoutCancel(ctx), timeout), execution)
+	if errors.Is(err, ErrAlreadyFinished) {
+		// execution finished in time, it isn't timed out
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("aborting timed out execution %s: %w", execution.Id, err)
+	}
+
+	if result == nil {
+		result = &testkube.ExecutionResult{}
+	}
+
+	result.Timeout()
+	result.ErrorMessage = fmt.Sprintf("execution timed out after %s", timeout)
+	execution.ExecutionResult = result
+
+	return result, nil
+}
+
+// JobDeadlineExceeded checks if the job failed because its active deadline passed, not because the test failed
+func JobDeadlineExceeded(job batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue && condition.Reason == deadlineExceededReason {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PodDeadlineExceeded checks if the pod was terminated because its active deadline passed
+func PodDeadlineExceeded(pod corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == deadlineExceededReason
+}
+
+// ApplyActiveDeadline sets the job active deadline, the stricter of template and execution deadline wins,
+// zero deadline keeps the template one, so executions without timeout stay unbounded
+func ApplyActiveDeadline(job *batchv1.Job, seconds int64) {
+	if seconds <= 0 {
+		return
+	}
+
+	if job.Spec.ActiveDeadlineSeconds == nil || *job.Spec.ActiveDeadlineSeconds <= 0 || *job.Spec.ActiveDeadlineSeconds > seconds {
+		job.Spec.ActiveDeadlineSeconds = &seconds
+	}
+}
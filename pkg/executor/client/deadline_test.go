@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	added   chan struct{}
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, added: make(chan struct{}, 100)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+	} else {
+		c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	}
+	c.added <- struct{}{}
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if !w.at.After(c.now) {
+			w.ch <- c.now
+		} else {
+			waiters = append(waiters, w)
+		}
+	}
+	c.waiters = waiters
+}
+
+func TestExecuteOptionsValidate(t *testing.T) {
+	assert.NoError(t, ExecuteOptions{ID: "exec", TestName: "test"}.Validate())
+	assert.NoError(t, ExecuteOptions{ID: "exec", TestName: "test", Timeout: time.Minute}.Validate())
+	assert.ErrorIs(t, ExecuteOptions{ID: "exec", TestName: "test", Timeout: -time.Second}.Validate(), ErrNegativeTimeout)
+}
+
+func TestExecuteOptionsActiveDeadlineSeconds(t *testing.T) {
+	assert.Equal(t, int64(0), ExecuteOptions{}.ActiveDeadlineSeconds())
+	assert.Equal(t, int64(90), ExecuteOptions{Timeout: 90 * time.Second}.ActiveDeadlineSeconds())
+	assert.Equal(t, int64(2), ExecuteOptions{Timeout: 1500 * time.Millisecond}.ActiveDeadlineSeconds())
+	assert.Equal(t, int64(30), ExecuteOptions{
+		Timeout: time.Minute,
+		Request: testkube.ExecutionRequest{ActiveDeadlineSeconds: 30},
+	}.ActiveDeadlineSeconds())
+	assert.Equal(t, int64(60), ExecuteOptions{
+		Timeout: time.Minute,
+		Request: testkube.ExecutionRequest{ActiveDeadlineSeconds: 120},
+	}.ActiveDeadlineSeconds())
+}
+
+func TestDeadlineWatcher_Timeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	execution := &testkube.Execution{Id: "exec-1", StartTime: clock.Now()}
+	mockExecutor := NewMockExecutor(ctrl)
+	mockExecutor.EXPECT().Abort(gomock.Any(), execution).
+		DoAndReturn(func(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
+			// executors record the abort requested by the watcher as timeout
+			assert.True(t, NewAbortedExecutionResult(ctx, clock.Now()).IsTimeout())
+			return testkube.NewRunningExecutionResult(), nil
+		})
+
+	done := make(chan *testkube.ExecutionResult)
+	go func() {
+		result, err := NewDeadlineWatcher(mockExecutor, clock).Watch(context.Background(), execution, time.Minute)
+		assert.NoError(t, err)
+		done <- result
+	}()
+
+	<-clock.added
+	clock.Advance(59 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("execution aborted before deadline")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(time.Second)
+	result := <-done
+	assert.True(t, result.IsTimeout())
+	assert.Equal(t, "execution timed out after 1m0s", result.ErrorMessage)
+	assert.Equal(t, result, execution.ExecutionResult)
+}
+
+func TestDeadlineWatcher_ContextDone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clock := newFakeClock(time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := NewDeadlineWatcher(NewMockExecutor(ctrl), clock).Watch(ctx, &testkube.Execution{StartTime: clock.Now()}, time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestDeadlineWatcher_Unlimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	watcher := NewDeadlineWatcher(NewMockExecutor(ctrl), newFakeClock(time.Now()))
+
+	result, err := watcher.Watch(context.Background(), &testkube.Execution{}, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	_, err = watcher.Watch(context.Background(), &testkube.Execution{}, -time.Second)
+	assert.ErrorIs(t, err, ErrNegativeTimeout)
+}
+
+func TestJobDeadlineExceeded(t *testing.T) {
+	failed := batchv1.Job{Status: batchv1.JobStatus{Failed: 1, Conditions: []batchv1.JobCondition{
+		{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"},
+	}}}
+	assert.False(t, JobDeadlineExceeded(failed))
+
+	timedOut := batchv1.Job{Status: batchv1.JobStatus{Failed: 1, Conditions: []batchv1.JobCondition{
+		{Type: batchv1.JobFailureTarget, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded"},
+		{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded"},
+	}}}
+	assert.True(t, JobDeadlineExceeded(timedOut))
+	assert.False(t, JobDeadlineExceeded(batchv1.Job{}))
+}
+
+func TestPodDeadlineExceeded(t *testing.T) {
+	assert.True(t, PodDeadlineExceeded(corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "DeadlineExceeded"}}))
+	assert.False(t, PodDeadlineExceeded(corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"}}))
+	assert.False(t, PodDeadlineExceeded(corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}}))
+}
+
+func TestApplyActiveDeadline(t *testing.T) {
+	seconds := func(v int64) *int64 { return &v }
+
+	job := batchv1.Job{}
+	ApplyActiveDeadline(&job, 0)
+	assert.Nil(t, job.Spec.ActiveDeadlineSeconds)
+
+	ApplyActiveDeadline(&job, 60)
+	assert.Equal(t, seconds(60), job.Spec.ActiveDeadlineSeconds)
+
+	job.Spec.ActiveDeadlineSeconds = seconds(30)
+	ApplyActiveDeadline(&job, 60)
+	assert.Equal(t, seconds(30), job.Spec.ActiveDeadlineSeconds)
+
+	job.Spec.ActiveDeadlineSeconds = seconds(120)
+	ApplyActiveDeadline(&job, 60)
+	assert.Equal(t, seconds(60), job.Spec.ActiveDeadlineSeconds)
+}
@@ -0,0 +1,254 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// DefaultScheduleSkew is a tolerated clock difference between execution requester and executor client,
+// executions scheduled to start within it are started immediately
+const DefaultScheduleSkew = 5 * time.Second
+
+// ErrScheduledExecutionClaimed is returned when scheduled execution was already removed from the store when its time came,
+// it was started by another API replica or aborted
+var ErrScheduledExecutionClaimed = errors.New("scheduled execution was claimed already")
+
+// ScheduledExecution is an execution waiting for its start time, options RunAfter holds the start time
+type ScheduledExecution struct {
+	Execution testkube.Execution `json:"execution"`
+	Options   ExecuteOptions     `json:"options"`
+}
+
+// ScheduledExecutionStore persists scheduled executions, so they are started after client restart
+type ScheduledExecutionStore interface {
+	// Save stores scheduled execution
+	Save(ctx context.Context, execution ScheduledExecution) error
+	// Delete removes scheduled execution, it's called when execution is aborted
+	Delete(ctx context.Context, id string) error
+	// Claim atomically removes scheduled execution before it starts, it returns false when the execution
+	// isn't stored anymore, so only one of the API replicas sharing the store starts it
+	Claim(ctx context.Context, id string) (bool, error)
+	// List returns all stored scheduled executions
+	List(ctx context.Context) ([]ScheduledExecution, error)
+}
+
+// DelayedExecutor postpones executions with RunAfter or Delay execute options, the execution is recorded
+// as queued with its scheduled time and started by the wrapped executor when the time comes
+type DelayedExecutor struct {
+	Executor
+	recorder ExecutionRecorder
+	clock    Clock
+	store    ScheduledExecutionStore
+	skew     time.Duration
+
+	mu      sync.Mutex
+	pending map[string]context.CancelFunc
+}
+
+// NewDelayedExecutor creates new delayed executor, scheduled executions are kept in memory until store is set
+func NewDelayedExecutor(executor Executor, recorder ExecutionRecorder, clock Clock) *DelayedExecutor {
+	if clock == nil {
+		clock = NewRealClock()
+	}
+
+	return &DelayedExecutor{
+		Executor: executor,
+		recorder: recorder,
+		clock:    clock,
+		skew:     DefaultScheduleSkew,
+		pending:  make(map[string]context.CancelFunc),
+	}
+}
+
+// WithStore sets store persisting scheduled executions, use Restore to start stored executions
+func (e *DelayedExecutor) WithStore(store ScheduledExecutionStore) *DelayedExecutor {
+	e.store = store
+	return e
+}
+
+// WithSkew sets tolerated clock difference, executions scheduled to start within it are started immediately
+func (e *DelayedExecutor) WithSkew(skew time.Duration) *DelayedExecutor {
+	e.skew = skew
+	return e
+}
+
+// Execute starts execution immediately or schedules it when it should start later,
+// scheduled execution gets queued result with the scheduled time
+func (e *DelayedExecutor) Execute(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	now := e.clock.Now()
+	at := options.StartAt(now)
+	if options.Delay < 0 || at.Sub(now) <= e.skew {
+		return e.Executor.Execute(ctx, execution, options)
+	}
+
+	options.RunAfter = at
+	options.Delay = 0
+	result := testkube.NewScheduledExecutionResult(at)
+	execution.ExecutionResult = result
+
+	scheduled := ScheduledExecution{Execution: *execution, Options: options}
+	if e.store != nil {
+		if err := e.store.Save(ctx, scheduled); err != nil {
+			err = fmt.Errorf("storing scheduled execution %s: %w", execution.Id, err)
+			return result.Err(err), err
+		}
+	}
+
+	if options.Sync {
+		return e.run(e.register(ctx, execution.Id), execution, options)
+	}
+
+	e.start(ctx, scheduled)
+	return result, nil
+}
+
+// DryRun renders execution with the wrapped executor, start time doesn't affect dry run
+func (e *DelayedExecutor) DryRun(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*DryRunResult, error) {
+	return DryRun(ctx, e.Executor, execution, options)
+}
+
+// Abort aborts execution, scheduled execution which didn't start yet is removed and recorded as aborted
+func (e *DelayedExecutor) Abort(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
+	e.mu.Lock()
+	cancel, ok := e.pending[execution.Id]
+	delete(e.pending, execution.Id)
+	e.mu.Unlock()
+
+	if !ok {
+		return e.Executor.Abort(ctx, execution)
+	}
+
+	cancel()
+	if e.store != nil {
+		if err := e.store.Delete(ctx, execution.Id); err != nil {
+			return nil, fmt.Errorf("removing scheduled execution %s: %w", execution.Id, err)
+		}
+	}
+
+	result := NewAbortedExecutionResult(ctx, e.clock.Now())
+	if execution.ExecutionResult != nil {
+		result.ScheduledTime = execution.ExecutionResult.ScheduledTime
+	}
+
+	execution.ExecutionResult = result
+	if err := e.recorder.UpdateResult(ctx, execution.Id, *execution); err != nil {
+		return result, fmt.Errorf("updating aborted execution %s: %w", execution.Id, err)
+	}
+
+	return result, nil
+}
+
+// Restore schedules executions from the store, executions which start time already passed are started immediately;
+// it's safe to restore the same store on every API replica, as the execution is claimed before it's started
+func (e *DelayedExecutor) Restore(ctx context.Context) error {
+	if e.store == nil {
+		return nil
+	}
+
+	executions, err := e.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("listing scheduled executions: %w", err)
+	}
+
+	for _, scheduled := range executions {
+		scheduled.Options.Sync = false
+		e.start(ctx, scheduled)
+	}
+
+	return nil
+}
+
+// Scheduled returns number of executions waiting for their start time
+func (e *DelayedExecutor) Scheduled() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return len(e.pending)
+}
+
+// start waits for scheduled execution in background, not bound to the request context
+func (e *DelayedExecutor) start(ctx context.Context, scheduled ScheduledExecution) {
+	runCtx := e.register(context.WithoutCancel(ctx), scheduled.Execution.Id)
+	go func() {
+		execution := scheduled.Execution
+		result, err := e.run(runCtx, &execution, scheduled.Options)
+		if err == nil || runCtx.Err() != nil || errors.Is(err, ErrScheduledExecutionClaimed) {
+			return
+		}
+
+		// nobody waits for the result, so failure to start has to be recorded here
+		if result == nil {
+			result = testkube.NewRunningExecutionResult()
+		}
+
+		execution.ExecutionResult = result.Err(err)
+		_ = e.recorder.UpdateResult(context.WithoutCancel(ctx), execution.Id, execution)
+	}()
+}
+
+// register makes scheduled execution abortable, returned context is cancelled on abort
+func (e *DelayedExecutor) register(ctx context.Context, id string) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+
+	e.mu.Lock()
+	e.pending[id] = cancel
+	e.mu.Unlock()
+
+	return ctx
+}
+
+// release removes execution from pending ones, returns false when it was aborted already
+func (e *DelayedExecutor) release(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_, ok := e.pending[id]
+	delete(e.pending, id)
+	return ok
+}
+
+// run waits until execution start time and starts it with the wrapped executor
+func (e *DelayedExecutor) run(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	if !e.wait(ctx, options.RunAfter) {
+		e.release(execution.Id)
+		return execution.ExecutionResult, ctx.Err()
+	}
+
+	if !e.release(execution.Id) {
+		return execution.ExecutionResult, context.Canceled
+	}
+
+	if e.store != nil {
+		claimed, err := e.store.Claim(ctx, execution.Id)
+		if err != nil {
+			return execution.ExecutionResult, fmt.Errorf("claiming scheduled execution %s: %w", execution.Id, err)
+		}
+		if !claimed {
+			return execution.ExecutionResult, ErrScheduledExecutionClaimed
+		}
+	}
+
+	return e.Executor.Execute(ctx, execution, options)
+}
+
+// wait blocks until the start time is reached, it rechecks the clock when woken up too early,
+// e.g. when system clock was adjusted, returns false when context is done first
+func (e *DelayedExecutor) wait(ctx context.Context, at time.Time) bool {
+	for {
+		remaining := at.Sub(e.clock.Now())
+		if remaining <= e.skew {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-e.clock.After(remaining):
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const CollectionScheduledExecutions = "scheduledexecutions"
+
+var _ ScheduledExecutionStore = (*MongoScheduledExecutionStore)(nil)
+
+// scheduledExecutionDocument keeps scheduled execution encoded as JSON, as execute options hold
+// kubernetes types which have no BSON mapping
+type scheduledExecutionDocument struct {
+	ID       string    `bson:"_id"`
+	Executor string    `bson:"executor"`
+	RunAfter time.Time `bson:"runAfter"`
+	Data     string    `bson:"data"`
+}
+
+// MongoScheduledExecutionStore persists scheduled executions in Mongo, executions are stored per executor,
+// so each delayed executor restores only its own ones
+type MongoScheduledExecutionStore struct {
+	coll     *mongo.Collection
+	executor string
+}
+
+// NewMongoScheduledExecutionStore creates scheduled execution store for the executor name
+func NewMongoScheduledExecutionStore(db *mongo.Database, executor string) *MongoScheduledExecutionStore {
+	return &MongoScheduledExecutionStore{
+		coll:     db.Collection(CollectionScheduledExecutions),
+		executor: executor,
+	}
+}
+
+// Save stores scheduled execution, replacing the stored one with the same id
+func (s *MongoScheduledExecutionStore) Save(ctx context.Context, execution ScheduledExecution) error {
+	data, err := json.Marshal(execution)
+	if err != nil {
+		return fmt.Errorf("encoding scheduled execution: %w", err)
+	}
+
+	document := scheduledExecutionDocument{
+		ID:       execution.Execution.Id,
+		Executor: s.executor,
+		RunAfter: execution.Options.RunAfter,
+		Data:     string(data),
+	}
+	_, err = s.coll.ReplaceOne(ctx, bson.M{"_id": document.ID}, document, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Delete removes scheduled execution, removing missing one is not an error
+func (s *MongoScheduledExecutionStore) Delete(ctx context.Context, id string) error {
+	_, err := s.coll.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// Claim removes scheduled execution of the executor, the document is removed by one caller only,
+// so it's claimed by one API replica when all of them restored it
+func (s *MongoScheduledExecutionStore) Claim(ctx context.Context, id string) (bool, error) {
+	result, err := s.coll.DeleteOne(ctx, bson.M{"_id": id, "executor": s.executor})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount == 1, nil
+}
+
+// List returns scheduled executions of the executor ordered by their start time
+func (s *MongoScheduledExecutionStore) List(ctx context.Context) ([]ScheduledExecution, error) {
+	cursor, err := s.coll.Find(ctx, bson.M{"executor": s.executor}, options.Find().SetSort(bson.D{{Key: "runAfter", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []scheduledExecutionDocument
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	executions := make([]ScheduledExecution, 0, len(documents))
+	for _, document := range documents {
+		var execution ScheduledExecution
+		if err = json.Unmarshal([]byte(document.Data), &execution); err != nil {
+			return nil, fmt.Errorf("decoding scheduled execution %s: %w", document.ID, err)
+		}
+		executions = append(executions, execution)
+	}
+
+	return executions, nil
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/repository/storage"
+	"github.com/kubeshop/testkube/pkg/utils/test"
+)
+
+func TestMongoScheduledExecutionStore_Integration(t *testing.T) {
+	test.IntegrationTest(t)
+
+	assert := require.New(t)
+	ctx := context.Background()
+
+	db, err := storage.GetMongoDatabase("mongodb://localhost:27017", "testkube-test", storage.TypeMongoDB, false, nil)
+	assert.NoError(err)
+	assert.NoError(db.Collection(CollectionScheduledExecutions).Drop(ctx))
+
+	jobStore := NewMongoScheduledExecutionStore(db, "job")
+	containerStore := NewMongoScheduledExecutionStore(db, "container")
+
+	at := time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)
+	later := ScheduledExecution{
+		Execution: testkube.Execution{Id: "later", TestName: "test"},
+		Options:   ExecuteOptions{ID: "later", TestName: "test", RunAfter: at.Add(time.Hour), Envs: map[string]string{"KEY": "value"}},
+	}
+	sooner := ScheduledExecution{
+		Execution: testkube.Execution{Id: "sooner", TestName: "test"},
+		Options:   ExecuteOptions{ID: "sooner", TestName: "test", RunAfter: at},
+	}
+	assert.NoError(jobStore.Save(ctx, later))
+	assert.NoError(jobStore.Save(ctx, sooner))
+	assert.NoError(containerStore.Save(ctx, ScheduledExecution{Execution: testkube.Execution{Id: "container"}}))
+
+	executions, err := jobStore.List(ctx)
+	assert.NoError(err)
+	assert.Len(executions, 2)
+	assert.Equal("sooner", executions[0].Execution.Id)
+	assert.Equal("later", executions[1].Execution.Id)
+	assert.True(at.Add(time.Hour).Equal(executions[1].Options.RunAfter))
+	assert.Equal("value", executions[1].Options.Envs["KEY"])
+
+	claimed, err := jobStore.Claim(ctx, "sooner")
+	assert.NoError(err)
+	assert.True(claimed)
+	claimed, err = NewMongoScheduledExecutionStore(db, "job").Claim(ctx, "sooner")
+	assert.NoError(err)
+	assert.False(claimed)
+	claimed, err = jobStore.Claim(ctx, "container")
+	assert.NoError(err)
+	assert.False(claimed)
+	assert.NoError(jobStore.Delete(ctx, "missing"))
+
+	executions, err = jobStore.List(ctx)
+	assert.NoError(err)
+	assert.Len(executions, 1)
+	assert.Equal("later", executions[0].Execution.Id)
+
+	executions, err = containerStore.List(ctx)
+	assert.NoError(err)
+	assert.Len(executions, 1)
+}
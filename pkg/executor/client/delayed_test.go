@@ -0,0 +1,266 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+type memoryScheduledStore struct {
+	mu         sync.Mutex
+	executions map[string]ScheduledExecution
+}
+
+func newMemoryScheduledStore(executions ...ScheduledExecution) *memoryScheduledStore {
+	store := &memoryScheduledStore{executions: map[string]ScheduledExecution{}}
+	for _, execution := range executions {
+		store.executions[execution.Execution.Id] = execution
+	}
+	return store
+}
+
+func (s *memoryScheduledStore) Save(ctx context.Context, execution ScheduledExecution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executions[execution.Execution.Id] = execution
+	return nil
+}
+
+func (s *memoryScheduledStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.executions, id)
+	return nil
+}
+
+func (s *memoryScheduledStore) Claim(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.executions[id]
+	delete(s.executions, id)
+	return ok, nil
+}
+
+func (s *memoryScheduledStore) List(ctx context.Context) ([]ScheduledExecution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var executions []ScheduledExecution
+	for _, execution := range s.executions {
+		executions = append(executions, execution)
+	}
+	sort.Slice(executions, func(i, j int) bool { return executions[i].Execution.Id < executions[j].Execution.Id })
+	return executions, nil
+}
+
+func (s *memoryScheduledStore) ids() []string {
+	executions, _ := s.List(context.Background())
+	var ids []string
+	for _, execution := range executions {
+		ids = append(ids, execution.Execution.Id)
+	}
+	return ids
+}
+
+// expectStarts returns channel receiving IDs of executions started by the mock executor
+func expectStarts(mockExecutor *MockExecutor, times int) <-chan string {
+	started := make(chan string, times)
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Times(times).
+		DoAndReturn(func(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+			started <- execution.Id
+			return testkube.NewRunningExecutionResult(), nil
+		})
+	return started
+}
+
+func assertNotStarted(t *testing.T, started <-chan string) {
+	select {
+	case id := <-started:
+		t.Fatalf("execution %s started before its time", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func assertStarted(t *testing.T, started <-chan string, id string) {
+	select {
+	case startedID := <-started:
+		assert.Equal(t, id, startedID)
+	case <-time.After(time.Second):
+		t.Fatalf("execution %s wasn't started", id)
+	}
+}
+
+func TestExecuteOptionsStartAt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now, ExecuteOptions{}.StartAt(now))
+	assert.Equal(t, now.Add(time.Hour), ExecuteOptions{Delay: time.Hour}.StartAt(now))
+	assert.Equal(t, now.Add(2*time.Hour), ExecuteOptions{RunAfter: now.Add(2 * time.Hour), Delay: time.Hour}.StartAt(now))
+	assert.Equal(t, now.Add(time.Hour), ExecuteOptions{RunAfter: now.Add(-time.Hour), Delay: time.Hour}.StartAt(now))
+}
+
+func TestDelayedExecutor_Fires(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	started := expectStarts(mockExecutor, 1)
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := newMemoryScheduledStore()
+	executor := NewDelayedExecutor(mockExecutor, newFakeRecorder(), clock).WithStore(store)
+
+	execution := &testkube.Execution{Id: "exec-1"}
+	result, err := executor.Execute(context.Background(), execution, ExecuteOptions{ID: "exec-1", Delay: time.Minute})
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsQueued())
+	assert.Equal(t, clock.Now().Add(time.Minute), result.ScheduledTime)
+	assert.Same(t, result, execution.ExecutionResult)
+	assert.Equal(t, []string{"exec-1"}, store.ids())
+
+	<-clock.added
+	clock.Advance(59 * time.Second)
+	assertNotStarted(t, started)
+	assert.Equal(t, 1, executor.Scheduled())
+
+	clock.Advance(time.Second)
+	assertStarted(t, started, "exec-1")
+	assert.Equal(t, 0, executor.Scheduled())
+	assert.Empty(t, store.ids())
+}
+
+func TestDelayedExecutor_AbortBeforeFiring(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	started := expectStarts(mockExecutor, 0)
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := newMemoryScheduledStore()
+	recorder := newFakeRecorder()
+	executor := NewDelayedExecutor(mockExecutor, recorder, clock).WithStore(store)
+
+	runAfter := clock.Now().Add(time.Hour)
+	execution := &testkube.Execution{Id: "exec-1"}
+	_, err := executor.Execute(context.Background(), execution, ExecuteOptions{ID: "exec-1", RunAfter: runAfter})
+	require.NoError(t, err)
+	<-clock.added
+
+	result, err := executor.Abort(WithAbortedBy(context.Background(), "user"), execution)
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsAborted())
+	assert.Equal(t, "execution aborted by user at 2024-01-01T00:00:00Z", result.ErrorMessage)
+	assert.Equal(t, runAfter, result.ScheduledTime)
+	assert.True(t, recorder.executions["exec-1"].ExecutionResult.IsAborted())
+	assert.Empty(t, store.ids())
+	assert.Equal(t, 0, executor.Scheduled())
+
+	clock.Advance(2 * time.Hour)
+	assertNotStarted(t, started)
+}
+
+func TestDelayedExecutor_AbortStartedExecution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	execution := &testkube.Execution{Id: "exec-1"}
+	mockExecutor.EXPECT().Abort(gomock.Any(), execution).Return(testkube.NewRunningExecutionResult(), nil)
+
+	_, err := NewDelayedExecutor(mockExecutor, newFakeRecorder(), newFakeClock(time.Now())).Abort(context.Background(), execution)
+	assert.NoError(t, err)
+}
+
+func TestDelayedExecutor_ClockSkew(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		runAfter  time.Time
+		scheduled bool
+	}{
+		"requester clock behind": {runAfter: now.Add(-3 * time.Second)},
+		"requester clock ahead":  {runAfter: now.Add(3 * time.Second)},
+		"beyond tolerated skew":  {runAfter: now.Add(6 * time.Second), scheduled: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockExecutor := NewMockExecutor(ctrl)
+			clock := newFakeClock(now)
+			executor := NewDelayedExecutor(mockExecutor, newFakeRecorder(), clock)
+			if !tt.scheduled {
+				mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(testkube.NewRunningExecutionResult(), nil)
+			}
+
+			result, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, ExecuteOptions{ID: "exec-1", RunAfter: tt.runAfter})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.scheduled, result.IsQueued())
+			if tt.scheduled {
+				_, err = executor.Abort(context.Background(), &testkube.Execution{Id: "exec-1"})
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDelayedExecutor_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	started := expectStarts(mockExecutor, 2)
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := newMemoryScheduledStore(
+		ScheduledExecution{Execution: testkube.Execution{Id: "missed"}, Options: ExecuteOptions{RunAfter: clock.Now().Add(-time.Hour)}},
+		ScheduledExecution{Execution: testkube.Execution{Id: "upcoming"}, Options: ExecuteOptions{RunAfter: clock.Now().Add(time.Hour), Sync: true}},
+	)
+	executor := NewDelayedExecutor(mockExecutor, newFakeRecorder(), clock).WithStore(store)
+
+	require.NoError(t, executor.Restore(context.Background()))
+
+	assertStarted(t, started, "missed")
+	<-clock.added
+	assert.Equal(t, 1, executor.Scheduled())
+	assert.Equal(t, []string{"upcoming"}, store.ids())
+
+	clock.Advance(time.Hour)
+	assertStarted(t, started, "upcoming")
+	assert.Empty(t, store.ids())
+}
+
+func TestDelayedExecutor_RestoreOnReplicas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	started := expectStarts(mockExecutor, 1)
+	recorder := newFakeRecorder()
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := newMemoryScheduledStore(
+		ScheduledExecution{Execution: testkube.Execution{Id: "upcoming"}, Options: ExecuteOptions{RunAfter: clock.Now().Add(time.Hour)}},
+	)
+	first := NewDelayedExecutor(mockExecutor, recorder, clock).WithStore(store)
+	second := NewDelayedExecutor(mockExecutor, recorder, clock).WithStore(store)
+
+	require.NoError(t, first.Restore(context.Background()))
+	require.NoError(t, second.Restore(context.Background()))
+	<-clock.added
+	<-clock.added
+
+	clock.Advance(time.Hour)
+	assertStarted(t, started, "upcoming")
+	assertNotStarted(t, started)
+	assert.Empty(t, store.ids())
+	assert.Equal(t, 0, first.Scheduled()+second.Scheduled())
+}
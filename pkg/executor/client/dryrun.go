@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// ErrDryRunNotSupported is returned when executor can't render execution without starting it
+var ErrDryRunNotSupported = errors.New("executor doesn't support dry run")
+
+// DryRunResult describes what execution would create or send, nothing of it is created
+type DryRunResult struct {
+	// Objects are Kubernetes objects the execution would create, in creation order
+	Objects []runtime.Object
+	// Request is a payload the execution would send to a remote runner
+	Request any
+}
+
+// YAML renders objects and request as multi document YAML for human inspection
+func (r DryRunResult) YAML() (string, error) {
+	documents := make([]any, 0, len(r.Objects)+1)
+	for _, object := range r.Objects {
+		documents = append(documents, object)
+	}
+
+	if r.Request != nil {
+		documents = append(documents, r.Request)
+	}
+
+	rendered := make([]string, len(documents))
+	for i, document := range documents {
+		data, err := json.Marshal(document)
+		if err != nil {
+			return "", fmt.Errorf("encoding dry run document %d: %w", i, err)
+		}
+
+		// JSON is valid YAML, decoding it to map slice keeps API field names and their order
+		var values yaml.MapSlice
+		if err = yaml.Unmarshal(data, &values); err != nil {
+			return "", fmt.Errorf("converting dry run document %d to yaml: %w", i, err)
+		}
+
+		out, err := yaml.Marshal(values)
+		if err != nil {
+			return "", fmt.Errorf("rendering dry run document %d: %w", i, err)
+		}
+
+		rendered[i] = string(out)
+	}
+
+	return strings.Join(rendered, "---\n"), nil
+}
+
+// DryRunner renders execution without starting it, performing the same validation as Execute
+type DryRunner interface {
+	DryRun(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*DryRunResult, error)
+}
+
+// DryRun renders execution with executor supporting dry run, ErrDryRunNotSupported is returned otherwise
+func DryRun(ctx context.Context, executor Executor, execution *testkube.Execution, options ExecuteOptions) (*DryRunResult, error) {
+	runner, ok := executor.(DryRunner)
+	if !ok {
+		return nil, ErrDryRunNotSupported
+	}
+
+	return runner.DryRun(ctx, execution, options)
+}
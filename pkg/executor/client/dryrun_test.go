@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/client/runnerapi"
+)
+
+func TestDryRunResult_YAML(t *testing.T) {
+	result := DryRunResult{
+		Objects: []runtime.Object{
+			&corev1.PersistentVolumeClaim{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+				ObjectMeta: metav1.ObjectMeta{Name: "exec-1-pvc"},
+			},
+			&batchv1.Job{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+				ObjectMeta: metav1.ObjectMeta{Name: "exec-1", Namespace: "tests"},
+			},
+		},
+		Request: &runnerapi.ExecuteRequest{ExecutionID: "exec-1", TestName: "test"},
+	}
+
+	manifest, err := result.YAML()
+
+	require.NoError(t, err)
+	documents := strings.Split(manifest, "---\n")
+	require.Len(t, documents, 3)
+	assert.Contains(t, documents[0], "kind: PersistentVolumeClaim\n")
+	assert.Contains(t, documents[0], "  name: exec-1-pvc\n")
+	assert.Contains(t, documents[1], "kind: Job\n")
+	assert.Contains(t, documents[1], "apiVersion: batch/v1\n")
+	assert.Contains(t, documents[1], "  namespace: tests\n")
+	assert.Equal(t, "executionId: exec-1\ntestName: test\n", documents[2])
+}
+
+func TestDryRun_NotSupported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, err := DryRun(context.Background(), NewMockExecutor(ctrl), &testkube.Execution{Id: "exec-1"}, ExecuteOptions{})
+	assert.ErrorIs(t, err, ErrDryRunNotSupported)
+}
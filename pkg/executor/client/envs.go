@@ -0,0 +1,148 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// SecretRef exposes a secret to the execution, the key is exposed as environment variable with the same name,
+// the whole secret is exposed when the key is empty
+type SecretRef struct {
+	// Name is the name of the secret in the execution namespace
+	Name string `json:"name"`
+	// Key is the secret key exposed as the environment variable
+	Key string `json:"key,omitempty"`
+}
+
+// ConfigMapRef exposes a config map to the execution, the key is exposed as environment variable with the same name,
+// the whole config map is exposed when the key is empty
+type ConfigMapRef struct {
+	// Name is the name of the config map in the execution namespace
+	Name string `json:"name"`
+	// Key is the config map key exposed as the environment variable
+	Key string `json:"key,omitempty"`
+}
+
+// EnvVars returns environment variables of the execution container, explicit envs sorted by name go first,
+// then the secret keys and the config map keys in order; the variable which is already defined is skipped,
+// so explicit envs win over the secret keys, and the secret keys win over the config map keys
+func EnvVars(envs map[string]string, secretEnvs []SecretRef, configMapEnvs []ConfigMapRef) []corev1.EnvVar {
+	var result []corev1.EnvVar
+	defined := make(map[string]struct{}, len(envs))
+
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		defined[name] = struct{}{}
+		result = append(result, corev1.EnvVar{Name: name, Value: envs[name]})
+	}
+
+	for _, ref := range secretEnvs {
+		if _, ok := defined[ref.Key]; ok || ref.Key == "" {
+			continue
+		}
+		defined[ref.Key] = struct{}{}
+		result = append(result, corev1.EnvVar{Name: ref.Key, ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: ref.Name}, Key: ref.Key},
+		}})
+	}
+
+	for _, ref := range configMapEnvs {
+		if _, ok := defined[ref.Key]; ok || ref.Key == "" {
+			continue
+		}
+		defined[ref.Key] = struct{}{}
+		result = append(result, corev1.EnvVar{Name: ref.Key, ValueFrom: &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: ref.Name}, Key: ref.Key},
+		}})
+	}
+
+	return result
+}
+
+// EnvFromSources returns whole secrets and config maps exposed to the execution container,
+// Kubernetes gives precedence to the variables defined explicitly, see EnvVars
+func EnvFromSources(secretEnvs []SecretRef, configMapEnvs []ConfigMapRef) []corev1.EnvFromSource {
+	var result []corev1.EnvFromSource
+	for _, ref := range configMapEnvs {
+		if ref.Key == "" {
+			result = append(result, corev1.EnvFromSource{ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: ref.Name},
+			}})
+		}
+	}
+
+	// secrets go last, so they win over config maps for the same key, as the later source takes precedence
+	for _, ref := range secretEnvs {
+		if ref.Key == "" {
+			result = append(result, corev1.EnvFromSource{SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: ref.Name},
+			}})
+		}
+	}
+
+	return result
+}
+
+// ApplyEnvs adds execution environment variables to containers of the pod spec rendered from the executor job template,
+// they are added after the template ones, so they win for the same name
+func ApplyEnvs(spec *corev1.PodSpec, envs map[string]string, secretEnvs []SecretRef, configMapEnvs []ConfigMapRef) {
+	vars := EnvVars(envs, secretEnvs, configMapEnvs)
+	sources := EnvFromSources(secretEnvs, configMapEnvs)
+	if len(vars) == 0 && len(sources) == 0 {
+		return
+	}
+
+	for i := range spec.InitContainers {
+		spec.InitContainers[i].Env = append(spec.InitContainers[i].Env, vars...)
+		spec.InitContainers[i].EnvFrom = append(spec.InitContainers[i].EnvFrom, sources...)
+	}
+
+	for i := range spec.Containers {
+		spec.Containers[i].Env = append(spec.Containers[i].Env, vars...)
+		spec.Containers[i].EnvFrom = append(spec.Containers[i].EnvFrom, sources...)
+	}
+}
+
+func (o ExecuteOptions) validateEnvs() (errs []error) {
+	names := make([]string, 0, len(o.Envs))
+	for name := range o.Envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if problems := validation.IsEnvVarName(name); len(problems) != 0 {
+			errs = append(errs, fmt.Errorf("env %q name is invalid: %s", name, problems[0]))
+		}
+	}
+
+	for i, ref := range o.SecretEnvs {
+		if ref.Name == "" {
+			errs = append(errs, fmt.Errorf("secret env %d name is required", i))
+		}
+		if ref.Key != "" {
+			if problems := validation.IsEnvVarName(ref.Key); len(problems) != 0 {
+				errs = append(errs, fmt.Errorf("secret env %d key %q is invalid: %s", i, ref.Key, problems[0]))
+			}
+		}
+	}
+
+	for i, ref := range o.ConfigMapEnvs {
+		if ref.Name == "" {
+			errs = append(errs, fmt.Errorf("config map env %d name is required", i))
+		}
+		if ref.Key != "" {
+			if problems := validation.IsEnvVarName(ref.Key); len(problems) != 0 {
+				errs = append(errs, fmt.Errorf("config map env %d key %q is invalid: %s", i, ref.Key, problems[0]))
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func secretKeyEnv(name, secret string) corev1.EnvVar {
+	return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secret}, Key: name},
+	}}
+}
+
+func configMapKeyEnv(name, configMap string) corev1.EnvVar {
+	return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: configMap}, Key: name},
+	}}
+}
+
+func TestEnvVars_Collisions(t *testing.T) {
+	envs := map[string]string{"TOKEN": "explicit", "REGION": "eu"}
+	secrets := []SecretRef{{Name: "api", Key: "TOKEN"}, {Name: "db", Key: "PASSWORD"}, {Name: "other", Key: "PASSWORD"}, {Name: "all"}}
+	configMaps := []ConfigMapRef{{Name: "settings", Key: "REGION"}, {Name: "settings", Key: "PASSWORD"}, {Name: "settings", Key: "LEVEL"}}
+
+	expected := []corev1.EnvVar{
+		{Name: "REGION", Value: "eu"},
+		{Name: "TOKEN", Value: "explicit"},
+		secretKeyEnv("PASSWORD", "db"),
+		configMapKeyEnv("LEVEL", "settings"),
+	}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, expected, EnvVars(envs, secrets, configMaps))
+	}
+}
+
+func TestApplyEnvs(t *testing.T) {
+	spec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "exec-init"}},
+		Containers:     []corev1.Container{{Name: "exec", Env: []corev1.EnvVar{{Name: "RUNNER_CLUSTERID", Value: "cluster"}}}},
+	}
+
+	ApplyEnvs(&spec, map[string]string{"DEBUG": "1"}, []SecretRef{{Name: "credentials"}}, []ConfigMapRef{{Name: "settings"}})
+
+	sources := []corev1.EnvFromSource{
+		{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}}},
+		{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}}},
+	}
+	assert.Equal(t, []corev1.Container{{Name: "exec-init", Env: []corev1.EnvVar{{Name: "DEBUG", Value: "1"}}, EnvFrom: sources}}, spec.InitContainers)
+	assert.Equal(t, []corev1.Container{{
+		Name:    "exec",
+		Env:     []corev1.EnvVar{{Name: "RUNNER_CLUSTERID", Value: "cluster"}, {Name: "DEBUG", Value: "1"}},
+		EnvFrom: sources,
+	}}, spec.Containers)
+
+	untouched := corev1.PodSpec{Containers: []corev1.Container{{Name: "exec"}}}
+	ApplyEnvs(&untouched, nil, nil, nil)
+	assert.Equal(t, corev1.PodSpec{Containers: []corev1.Container{{Name: "exec"}}}, untouched)
+}
+
+func TestNewJobSpec_ExecutionEnvs(t *testing.T) {
+	options := NewExecuteOptions()
+	options.Envs["API_TOKEN"] = "explicit"
+	options.Envs["REGION"] = "eu"
+	options.SecretEnvs = []SecretRef{{Name: "api", Key: "API_TOKEN"}, {Name: "api", Key: "API_USER"}}
+	options.ConfigMapEnvs = []ConfigMapRef{{Name: "settings"}}
+
+	jobOptions := NewJobOptionsFromExecutionOptions(options)
+	jobOptions.Name = "exec"
+	jobOptions.JobTemplate = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: "{{ .Name }}"
+spec:
+  template:
+    spec:
+      containers:
+        - name: "{{ .Name }}"
+          image: executor
+`
+	job, err := NewJobSpec(zap.NewNop().Sugar(), jobOptions)
+	require.NoError(t, err)
+	require.Len(t, job.Spec.Template.Spec.Containers, 1)
+
+	container := job.Spec.Template.Spec.Containers[0]
+	envs := map[string]corev1.EnvVar{}
+	for _, env := range container.Env {
+		envs[env.Name] = env
+	}
+	assert.Equal(t, corev1.EnvVar{Name: "API_TOKEN", Value: "explicit"}, envs["API_TOKEN"])
+	assert.Equal(t, corev1.EnvVar{Name: "REGION", Value: "eu"}, envs["REGION"])
+	assert.Equal(t, secretKeyEnv("API_USER", "api"), envs["API_USER"])
+	assert.Equal(t, []corev1.EnvFromSource{
+		{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}}},
+	}, container.EnvFrom)
+}
+
+func TestExecuteOptions_ValidateEnvs(t *testing.T) {
+	options := ExecuteOptions{
+		Envs:          map[string]string{"VALID": "1", "1=2": "invalid"},
+		SecretEnvs:    []SecretRef{{Name: "api", Key: "TOKEN"}, {Key: "PASSWORD"}, {Name: "db", Key: "a=b"}},
+		ConfigMapEnvs: []ConfigMapRef{{Name: "settings"}, {}},
+	}
+
+	errs := options.validateEnvs()
+	require.Len(t, errs, 4)
+	assert.ErrorContains(t, errs[0], `env "1=2" name is invalid`)
+	assert.EqualError(t, errs[1], "secret env 1 name is required")
+	assert.ErrorContains(t, errs[2], `secret env 2 key "a=b" is invalid`)
+	assert.EqualError(t, errs[3], "config map env 1 name is required")
+
+	assert.Empty(t, NewExecuteOptions().validateEnvs())
+	assert.EqualError(t, ExecuteOptions{SecretEnvs: []SecretRef{{Name: "api"}}}.ValidateCapabilities(Capabilities{}),
+		"secret envs: option is not supported by the executor")
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// ExecutionEvent is a transition of execution status observed by WatchExecution
+type ExecutionEvent struct {
+	// ExecutionID is the watched execution ID
+	ExecutionID string
+	// OldStatus is empty for the first observed status
+	OldStatus testkube.ExecutionStatus
+	// NewStatus is the status the execution transitioned to
+	NewStatus testkube.ExecutionStatus
+	// Time is when the transition was observed
+	Time time.Time
+	// Result is the execution result observed with the new status
+	Result *testkube.ExecutionResult
+	// Err is set on the last event when execution state couldn't be read, statuses are empty then
+	Err error
+
+	// execution is the whole execution observed with the new status, i.e. to evaluate conditions over it
+	execution testkube.Execution
+}
+
+// IsTerminal checks if the event transitioned the execution to terminal status
+func (e ExecutionEvent) IsTerminal() bool {
+	return statusRank(e.NewStatus) == terminalStatusRank
+}
+
+const terminalStatusRank = 2
+
+// statusRank orders statuses by execution lifecycle, statuses which are not part of it have negative rank
+func statusRank(status testkube.ExecutionStatus) int {
+	switch status {
+	case testkube.QUEUED_ExecutionStatus:
+		return 0
+	case testkube.RUNNING_ExecutionStatus:
+		return 1
+	case testkube.PASSED_ExecutionStatus, testkube.FAILED_ExecutionStatus,
+		testkube.ABORTED_ExecutionStatus, testkube.TIMEOUT_ExecutionStatus:
+		return terminalStatusRank
+	}
+
+	return -1
+}
+
+// WatchExecution polls execution and emits an event once per status transition, repeated observations of the same
+// status and observations going back in the lifecycle, e.g. from a stale read, are skipped,
+// the channel is closed after terminal event, failed state read or when context is done
+func (r *ExecutionRunner) WatchExecution(ctx context.Context, id string) (<-chan ExecutionEvent, error) {
+	if id == "" {
+		return nil, errors.New("execution id is required")
+	}
+
+	events := make(chan ExecutionEvent)
+	go func() {
+		defer close(events)
+
+		send := func(event ExecutionEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var lastStatus testkube.ExecutionStatus
+		err := r.watcher.Poll(ctx, func(ctx context.Context) (done, changed bool, err error) {
+			current, err := r.executions.Get(ctx, id)
+			if err != nil {
+				return false, false, err
+			}
+
+			status := currentStatus(current)
+			if statusRank(status) <= statusRank(lastStatus) {
+				return false, false, nil
+			}
+
+			event := ExecutionEvent{
+				ExecutionID: id,
+				OldStatus:   lastStatus,
+				NewStatus:   status,
+				Time:        r.watcher.clock.Now(),
+				Result:      current.ExecutionResult,
+				execution:   current,
+			}
+			lastStatus = status
+			r.cache.Invalidate(id)
+			if !send(event) {
+				return true, true, nil
+			}
+
+			return event.IsTerminal(), true, nil
+		})
+		if err != nil && ctx.Err() == nil {
+			send(ExecutionEvent{ExecutionID: id, Time: r.watcher.clock.Now(), Err: err})
+		}
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// observedStatuses returns scripted status observations, the last one is repeated when they run out
+type observedStatuses struct {
+	mu           sync.Mutex
+	observations []testkube.ExecutionStatus
+	err          error
+	polls        int
+}
+
+func (s *observedStatuses) Get(ctx context.Context, id string) (testkube.Execution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.polls >= len(s.observations) {
+		if s.err != nil {
+			return testkube.Execution{}, s.err
+		}
+		s.polls = len(s.observations) - 1
+	}
+
+	status := s.observations[s.polls]
+	s.polls++
+	return testkube.Execution{Id: id, ExecutionResult: &testkube.ExecutionResult{Status: &status}}, nil
+}
+
+func collectEvents(events <-chan ExecutionEvent) []ExecutionEvent {
+	var collected []ExecutionEvent
+	for event := range events {
+		collected = append(collected, event)
+	}
+	return collected
+}
+
+func transitions(events []ExecutionEvent) [][2]testkube.ExecutionStatus {
+	result := make([][2]testkube.ExecutionStatus, len(events))
+	for i, event := range events {
+		result[i] = [2]testkube.ExecutionStatus{event.OldStatus, event.NewStatus}
+	}
+	return result
+}
+
+func TestExecutionRunner_WatchExecution(t *testing.T) {
+	tests := map[string]struct {
+		observations []testkube.ExecutionStatus
+		expected     [][2]testkube.ExecutionStatus
+	}{
+		"full lifecycle": {
+			observations: []testkube.ExecutionStatus{"queued", "running", "passed"},
+			expected:     [][2]testkube.ExecutionStatus{{"", "queued"}, {"queued", "running"}, {"running", "passed"}},
+		},
+		"repeated observations": {
+			observations: []testkube.ExecutionStatus{"queued", "queued", "running", "running", "running", "failed"},
+			expected:     [][2]testkube.ExecutionStatus{{"", "queued"}, {"queued", "running"}, {"running", "failed"}},
+		},
+		"out of order observations": {
+			observations: []testkube.ExecutionStatus{"running", "queued", "running", "queued", "timeout", "running"},
+			expected:     [][2]testkube.ExecutionStatus{{"", "running"}, {"running", "timeout"}},
+		},
+		"missed running": {
+			observations: []testkube.ExecutionStatus{"", "queued", "aborted"},
+			expected:     [][2]testkube.ExecutionStatus{{"", "queued"}, {"queued", "aborted"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			statuses := &observedStatuses{observations: tt.observations}
+
+			events, err := newTestExecutionRunner(nil, statuses, &recordingClock{}).WatchExecution(context.Background(), "exec-1")
+			require.NoError(t, err)
+
+			collected := collectEvents(events)
+			assert.Equal(t, tt.expected, transitions(collected))
+			for _, event := range collected {
+				assert.Equal(t, "exec-1", event.ExecutionID)
+				assert.Equal(t, event.NewStatus, *event.Result.Status)
+			}
+			assert.True(t, collected[len(collected)-1].IsTerminal())
+		})
+	}
+}
+
+func TestExecutionRunner_WatchExecutionContextDone(t *testing.T) {
+	statuses := &observedStatuses{observations: []testkube.ExecutionStatus{"running"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := newFakeClock(time.Now())
+
+	events, err := newTestExecutionRunner(nil, statuses, clock).WatchExecution(ctx, "exec-1")
+	require.NoError(t, err)
+
+	<-clock.added
+	clock.Advance(time.Second)
+	event := <-events
+	assert.Equal(t, testkube.RUNNING_ExecutionStatus, event.NewStatus)
+
+	cancel()
+	assert.Empty(t, collectEvents(events))
+}
+
+func TestExecutionRunner_WatchExecutionGetError(t *testing.T) {
+	getErr := errors.New("database unavailable")
+	statuses := &observedStatuses{observations: []testkube.ExecutionStatus{"queued"}, err: getErr}
+
+	events, err := newTestExecutionRunner(nil, statuses, &recordingClock{}).WatchExecution(context.Background(), "exec-1")
+	require.NoError(t, err)
+
+	collected := collectEvents(events)
+	require.Len(t, collected, 2)
+	assert.Equal(t, testkube.QUEUED_ExecutionStatus, collected[0].NewStatus)
+	assert.ErrorIs(t, collected[1].Err, getErr)
+	assert.False(t, collected[1].IsTerminal())
+}
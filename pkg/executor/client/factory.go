@@ -0,0 +1,34 @@
+package client
+
+import (
+	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
+)
+
+// ExecutorFactory selects executor client by executor type
+type ExecutorFactory struct {
+	defaultExecutor Executor
+	executors       map[executorv1.ExecutorType]Executor
+}
+
+// NewExecutorFactory creates new executor factory, default executor is used for job and unknown executor types
+func NewExecutorFactory(defaultExecutor Executor) *ExecutorFactory {
+	return &ExecutorFactory{
+		defaultExecutor: defaultExecutor,
+		executors:       map[executorv1.ExecutorType]Executor{executorv1.ExecutorTypeJob: defaultExecutor},
+	}
+}
+
+// Register registers executor client for executor type
+func (f *ExecutorFactory) Register(executorType executorv1.ExecutorType, executor Executor) *ExecutorFactory {
+	f.executors[executorType] = executor
+	return f
+}
+
+// Get returns executor client for executor spec
+func (f *ExecutorFactory) Get(spec executorv1.ExecutorSpec) Executor {
+	if executor, ok := f.executors[spec.ExecutorType]; ok && executor != nil {
+		return executor
+	}
+
+	return f.defaultExecutor
+}
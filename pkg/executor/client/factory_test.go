@@ -0,0 +1,24 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
+)
+
+func TestExecutorFactory_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jobExecutor := NewMockExecutor(ctrl)
+	containerExecutor := NewMockExecutor(ctrl)
+	factory := NewExecutorFactory(jobExecutor).Register(executorv1.ExecutorTypeContainer, containerExecutor)
+
+	assert.Same(t, jobExecutor, factory.Get(executorv1.ExecutorSpec{}))
+	assert.Same(t, jobExecutor, factory.Get(executorv1.ExecutorSpec{ExecutorType: executorv1.ExecutorTypeJob}))
+	assert.Same(t, containerExecutor, factory.Get(executorv1.ExecutorSpec{ExecutorType: executorv1.ExecutorTypeContainer}))
+	assert.Same(t, jobExecutor, factory.Get(executorv1.ExecutorSpec{ExecutorType: "unknown"}))
+}
@@ -0,0 +1,298 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/output"
+)
+
+// FakeTestingT is a subset of testing.T used by FakeExecutor to report failures
+type FakeTestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}
+
+// FakeExecution scripts a single execution run by FakeExecutor
+type FakeExecution struct {
+	testName string
+	statuses []testkube.ExecutionStatus
+	logs     []string
+	err      error
+	result   testkube.ExecutionResult
+	options  *ExecuteOptions
+	polls    int
+	aborted  *testkube.ExecutionResult
+	abortErr error
+}
+
+// WithStatuses sets statuses reported by consecutive status polls, the last one is reported afterwards
+func (e *FakeExecution) WithStatuses(statuses ...testkube.ExecutionStatus) *FakeExecution {
+	e.statuses = statuses
+	return e
+}
+
+// WithLogs sets log lines returned for the execution
+func (e *FakeExecution) WithLogs(lines ...string) *FakeExecution {
+	e.logs = lines
+	return e
+}
+
+// WithError makes the execution fail to start
+func (e *FakeExecution) WithError(err error) *FakeExecution {
+	e.err = err
+	return e
+}
+
+// WithAbortError makes aborting the execution fail, the execution keeps running
+func (e *FakeExecution) WithAbortError(err error) *FakeExecution {
+	e.abortErr = err
+	return e
+}
+
+// WithResult sets output and error message of the final result
+func (e *FakeExecution) WithResult(output, errorMessage string) *FakeExecution {
+	e.result.Output = output
+	e.result.ErrorMessage = errorMessage
+	return e
+}
+
+// status returns execution result after given number of polls
+func (e *FakeExecution) status(polls int) *testkube.ExecutionResult {
+	if e.aborted != nil {
+		return e.aborted
+	}
+
+	status := testkube.PASSED_ExecutionStatus
+	if len(e.statuses) != 0 {
+		status = e.statuses[min(polls, len(e.statuses)-1)]
+	}
+
+	result := e.result
+	result.Status = testkube.StatusPtr(status)
+	if !result.IsCompleted() {
+		result.Output = ""
+		result.ErrorMessage = ""
+	}
+
+	return &result
+}
+
+func (e *FakeExecution) finalStatus() *testkube.ExecutionResult {
+	return e.status(len(e.statuses))
+}
+
+// FakeExecutor is an in-memory executor for tests, executions are scripted by test name,
+// executions without expectation and expectations which weren't executed are reported as test failures
+type FakeExecutor struct {
+	t FakeTestingT
+
+	mu           sync.Mutex
+	expectations []*FakeExecution
+	executions   map[string]*FakeExecution
+	aborted      []string
+	capabilities *Capabilities
+}
+
+// NewFakeExecutor creates new fake executor, expectations are asserted when the test finishes
+func NewFakeExecutor(t FakeTestingT) *FakeExecutor {
+	f := &FakeExecutor{
+		t:          t,
+		executions: make(map[string]*FakeExecution),
+	}
+	t.Cleanup(f.AssertExpectations)
+
+	return f
+}
+
+// ExpectExecution expects execution of the test, returned execution can be scripted
+func (f *FakeExecutor) ExpectExecution(testName string) *FakeExecution {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	execution := &FakeExecution{testName: testName}
+	f.expectations = append(f.expectations, execution)
+	return execution
+}
+
+// Execute starts scripted execution, in sync mode final status is returned immediately
+func (f *FakeExecutor) Execute(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var scripted *FakeExecution
+	for _, expectation := range f.expectations {
+		if expectation.options == nil && expectation.testName == options.TestName {
+			scripted = expectation
+			break
+		}
+	}
+
+	if scripted == nil {
+		f.t.Helper()
+		f.t.Errorf("fake executor: unexpected execution of test %s", options.TestName)
+		err := fmt.Errorf("unexpected execution of test %s", options.TestName)
+		return testkube.NewRunningExecutionResult().Err(err), err
+	}
+
+	scripted.options = &options
+	if scripted.err != nil {
+		execution.ExecutionResult = testkube.NewRunningExecutionResult().Err(scripted.err)
+		return execution.ExecutionResult, scripted.err
+	}
+
+	f.executions[execution.Id] = scripted
+	if options.Sync {
+		execution.ExecutionResult = scripted.finalStatus()
+	} else {
+		execution.ExecutionResult = testkube.NewRunningExecutionResult()
+	}
+
+	return execution.ExecutionResult, nil
+}
+
+// Get returns execution with current scripted status, each call advances the status
+func (f *FakeExecutor) Get(ctx context.Context, id string) (testkube.Execution, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	scripted, ok := f.executions[id]
+	if !ok {
+		return testkube.Execution{}, fmt.Errorf("execution %s not found", id)
+	}
+
+	result := scripted.status(scripted.polls)
+	scripted.polls++
+	return testkube.Execution{Id: id, TestName: scripted.testName, ExecutionResult: result}, nil
+}
+
+// Abort aborts running execution, finished execution keeps its terminal status
+func (f *FakeExecutor) Abort(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.aborted = append(f.aborted, execution.Id)
+	scripted, ok := f.executions[execution.Id]
+	if !ok {
+		// execution was started elsewhere, e.g. loaded from repository, it's treated as running
+		execution.ExecutionResult = NewAbortedExecutionResult(ctx, time.Now())
+		return execution.ExecutionResult, nil
+	}
+
+	if scripted.abortErr != nil {
+		return nil, scripted.abortErr
+	}
+
+	if result := scripted.status(max(scripted.polls-1, 0)); result.IsCompleted() {
+		return result, &AlreadyFinishedError{ExecutionID: execution.Id, Result: result}
+	}
+
+	scripted.aborted = NewAbortedExecutionResult(ctx, time.Now())
+	execution.ExecutionResult = scripted.aborted
+	return scripted.aborted, nil
+}
+
+// Capabilities returns capabilities set with WithCapabilities, fake executor accepts all options by default
+func (f *FakeExecutor) Capabilities() Capabilities {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.capabilities == nil {
+		return FullCapabilities
+	}
+
+	return *f.capabilities
+}
+
+// WithCapabilities sets capabilities reported by the fake executor
+func (f *FakeExecutor) WithCapabilities(capabilities Capabilities) *FakeExecutor {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.capabilities = &capabilities
+	return f
+}
+
+// Logs returns scripted log lines
+func (f *FakeExecutor) Logs(ctx context.Context, id, namespace string) (chan output.Output, error) {
+	lines, err := f.scriptedLogs(id)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make(chan output.Output, len(lines))
+	for _, line := range lines {
+		logs <- output.NewOutputLine([]byte(line))
+	}
+	close(logs)
+
+	return logs, nil
+}
+
+// FollowLogs returns scripted log lines
+func (f *FakeExecutor) FollowLogs(ctx context.Context, id, namespace string) (<-chan LogLine, error) {
+	lines, err := f.scriptedLogs(id)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make(chan LogLine, len(lines))
+	for _, line := range lines {
+		logs <- LogLine{Time: time.Now(), Stream: LogStreamStdout, Source: "fake", Content: line}
+	}
+	close(logs)
+
+	return logs, nil
+}
+
+// ExecuteOptions returns options of started executions in order of expectations
+func (f *FakeExecutor) ExecuteOptions() []ExecuteOptions {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var options []ExecuteOptions
+	for _, expectation := range f.expectations {
+		if expectation.options != nil {
+			options = append(options, *expectation.options)
+		}
+	}
+
+	return options
+}
+
+// Aborted returns IDs of aborted executions
+func (f *FakeExecutor) Aborted() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.aborted...)
+}
+
+// AssertExpectations reports expected executions which weren't started
+func (f *FakeExecutor) AssertExpectations() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.t.Helper()
+	for _, expectation := range f.expectations {
+		if expectation.options == nil {
+			f.t.Errorf("fake executor: expected execution of test %s wasn't started", expectation.testName)
+		}
+	}
+}
+
+func (f *FakeExecutor) scriptedLogs(id string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	scripted, ok := f.executions[id]
+	if !ok {
+		return nil, fmt.Errorf("execution %s not found", id)
+	}
+
+	return scripted.logs, nil
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+var (
+	_ Executor        = (*FakeExecutor)(nil)
+	_ ExecutionGetter = (*FakeExecutor)(nil)
+	_ LogFollower     = (*FakeExecutor)(nil)
+)
+
+// recordingT records failures reported by the fake executor
+type recordingT struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *recordingT) finish() {
+	for _, f := range t.cleanups {
+		f()
+	}
+}
+
+func TestFakeExecutor_ExecuteSync(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("k6-test").
+		WithStatuses(testkube.QUEUED_ExecutionStatus, testkube.RUNNING_ExecutionStatus, testkube.FAILED_ExecutionStatus).
+		WithResult("1 check failed", "test failed")
+	execution := &testkube.Execution{Id: "exec-1"}
+
+	result, err := newTestExecutionRunner(fake, fake, &recordingClock{}).
+		ExecuteSync(context.Background(), execution, ExecuteOptions{TestName: "k6-test", Timeout: time.Minute})
+
+	require.NoError(t, err)
+	assert.Equal(t, testkube.ExecutionStatusFailed, result.Status)
+	assert.Equal(t, "1 check failed", result.Output)
+	assert.Equal(t, "test failed", result.ErrorMessage)
+	assert.Equal(t, []ExecuteOptions{{TestName: "k6-test", Timeout: time.Minute}}, fake.ExecuteOptions())
+}
+
+func TestFakeExecutor_StatusTransitions(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("curl-test").WithStatuses(testkube.RUNNING_ExecutionStatus, testkube.PASSED_ExecutionStatus)
+
+	result, err := fake.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, ExecuteOptions{TestName: "curl-test"})
+	require.NoError(t, err)
+	assert.True(t, result.IsRunning())
+
+	var statuses []testkube.ExecutionStatus
+	for i := 0; i < 3; i++ {
+		execution, err := fake.Get(context.Background(), "exec-1")
+		require.NoError(t, err)
+		statuses = append(statuses, *execution.ExecutionResult.Status)
+	}
+
+	assert.Equal(t, []testkube.ExecutionStatus{
+		testkube.RUNNING_ExecutionStatus,
+		testkube.PASSED_ExecutionStatus,
+		testkube.PASSED_ExecutionStatus,
+	}, statuses)
+}
+
+func TestFakeExecutor_Abort(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("curl-test").WithStatuses(testkube.RUNNING_ExecutionStatus)
+	execution := &testkube.Execution{Id: "exec-1"}
+	_, err := fake.Execute(context.Background(), execution, ExecuteOptions{TestName: "curl-test"})
+	require.NoError(t, err)
+
+	result, err := fake.Abort(WithAbortedBy(context.Background(), "api"), execution)
+
+	require.NoError(t, err)
+	assert.Equal(t, testkube.ExecutionStatusAborted, result.Status)
+	assert.Contains(t, result.ErrorMessage, "aborted by api")
+	assert.Equal(t, []string{"exec-1"}, fake.Aborted())
+
+	current, err := fake.Get(context.Background(), "exec-1")
+	require.NoError(t, err)
+	assert.Equal(t, testkube.ExecutionStatusAborted, current.ExecutionResult.Status)
+}
+
+func TestFakeExecutor_Logs(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("curl-test").WithLogs("starting", "done")
+	_, err := fake.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, ExecuteOptions{TestName: "curl-test"})
+	require.NoError(t, err)
+
+	logs, err := fake.Logs(context.Background(), "exec-1", "default")
+	require.NoError(t, err)
+	var lines []string
+	for line := range logs {
+		lines = append(lines, line.Content)
+	}
+	assert.Equal(t, []string{"starting", "done"}, lines)
+
+	followed, err := fake.FollowLogs(context.Background(), "exec-1", "default")
+	require.NoError(t, err)
+	lines = nil
+	for line := range followed {
+		lines = append(lines, line.Content)
+	}
+	assert.Equal(t, []string{"starting", "done"}, lines)
+}
+
+func TestFakeExecutor_StartError(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	startErr := errors.New("no executor for test type")
+	fake.ExpectExecution("curl-test").WithError(startErr)
+
+	_, err := fake.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, ExecuteOptions{TestName: "curl-test"})
+
+	assert.ErrorIs(t, err, startErr)
+}
+
+func TestFakeExecutor_Expectations(t *testing.T) {
+	recorder := &recordingT{}
+	fake := NewFakeExecutor(recorder)
+	fake.ExpectExecution("curl-test")
+
+	_, err := fake.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, ExecuteOptions{TestName: "postman-test"})
+	assert.Error(t, err)
+	recorder.finish()
+
+	assert.Equal(t, []string{
+		"fake executor: unexpected execution of test postman-test",
+		"fake executor: expected execution of test curl-test wasn't started",
+	}, recorder.errors)
+}
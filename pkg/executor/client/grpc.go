@@ -0,0 +1,672 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/client/runnerapi"
+	"github.com/kubeshop/testkube/pkg/executor/output"
+)
+
+// ExecutorTypeGRPC is an executor type of long-lived runner daemons reachable over gRPC,
+// runner address is taken from executor spec URI
+const ExecutorTypeGRPC executorv1.ExecutorType = "grpc"
+
+const (
+	// defaultWatchReconnectAttempts is a number of tries to resume interrupted watch stream
+	defaultWatchReconnectAttempts = 3
+	// defaultWatchReconnectDelay is a delay between watch stream reconnect tries
+	defaultWatchReconnectDelay = time.Second
+)
+
+// ErrRunnerUnavailable is returned when runner can't be reached, it's an infrastructure error and can be retried
+var ErrRunnerUnavailable = errors.New("runner unavailable")
+
+// GRPCOptions are connection options of gRPC executor
+type GRPCOptions struct {
+	// Insecure disables transport security
+	Insecure bool
+	// SkipVerify disables runner certificate verification
+	SkipVerify bool
+	// CertFile and KeyFile are client certificate used for mTLS
+	CertFile string
+	KeyFile  string
+	// CAFile is a certificate authority used to verify runner certificate
+	CAFile string
+	// DialOptions are additional dial options
+	DialOptions []grpc.DialOption
+	// WatchReconnectAttempts is a number of tries to resume interrupted watch stream
+	WatchReconnectAttempts int
+	// WatchReconnectDelay is a delay between watch stream reconnect tries
+	WatchReconnectDelay time.Duration
+	// MaxResponseSize is a maximum size of single runner response in bytes, bigger responses fail
+	// with ErrResponseTooLarge, 4 MiB by default
+	MaxResponseSize int
+	// MaxOutputSize is a maximum size of execution output in bytes, the rest of the output is dropped
+	// and the execution result is marked as truncated, 8 MiB by default
+	MaxOutputSize int
+	// RequestTimeout limits single Execute and Abort call to the runner, 30 seconds by default;
+	// watch stream lasts until the execution finishes, so it's limited by the execution timeout instead
+	RequestTimeout time.Duration
+	// Retry configures retries of throttled or unavailable runner calls, Execute is retried
+	// only when the execution has an idempotency key
+	Retry GRPCRetryOptions
+}
+
+// ExecutionResultUpdater stores results of asynchronous executions
+type ExecutionResultUpdater interface {
+	// UpdateResult updates result in execution
+	UpdateResult(ctx context.Context, id string, execution testkube.Execution) error
+}
+
+// EventNotifier notifies about finished executions
+type EventNotifier interface {
+	Notify(event testkube.Event)
+}
+
+// GRPCExecutor runs executions on long-lived runner daemons over gRPC
+type GRPCExecutor struct {
+	log      *zap.SugaredLogger
+	options  GRPCOptions
+	updater  ExecutionResultUpdater
+	notifier EventNotifier
+	clock    Clock
+	metrics  RunnerRetryMetrics
+
+	mu          sync.Mutex
+	connections map[string]*grpc.ClientConn
+	addresses   map[string]string
+}
+
+// NewGRPCExecutor creates new gRPC executor, updater and notifier are used for asynchronous executions and can be nil
+func NewGRPCExecutor(log *zap.SugaredLogger, options GRPCOptions, updater ExecutionResultUpdater, notifier EventNotifier) *GRPCExecutor {
+	if options.WatchReconnectAttempts == 0 {
+		options.WatchReconnectAttempts = defaultWatchReconnectAttempts
+	}
+
+	if options.WatchReconnectDelay == 0 {
+		options.WatchReconnectDelay = defaultWatchReconnectDelay
+	}
+
+	if options.MaxResponseSize == 0 {
+		options.MaxResponseSize = defaultMaxResponseSize
+	}
+
+	if options.MaxOutputSize == 0 {
+		options.MaxOutputSize = defaultMaxOutputSize
+	}
+
+	if options.RequestTimeout == 0 {
+		options.RequestTimeout = defaultRequestTimeout
+	}
+
+	if options.Retry.MaxAttempts == 0 {
+		options.Retry.MaxAttempts = defaultRetryMaxAttempts
+	}
+
+	if options.Retry.MaxElapsed == 0 {
+		options.Retry.MaxElapsed = defaultRetryMaxElapsed
+	}
+
+	if options.Retry.Backoff.Interval == 0 {
+		options.Retry.Backoff = DefaultGRPCRetryBackoff()
+	}
+
+	return &GRPCExecutor{
+		log:         log,
+		options:     options,
+		updater:     updater,
+		notifier:    notifier,
+		clock:       NewRealClock(),
+		connections: make(map[string]*grpc.ClientConn),
+		addresses:   make(map[string]string),
+	}
+}
+
+// Execute starts execution on the runner, in sync mode it waits until runner reports final status
+func (e *GRPCExecutor) Execute(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (result *testkube.ExecutionResult, err error) {
+	result = testkube.NewRunningExecutionResult()
+	execution.ExecutionResult = result
+	options = options.Normalize()
+
+	if err = options.Validate(); err != nil {
+		return result.Err(err), err
+	}
+
+	if err = ValidateSecretNamespaces(*execution, options); err != nil {
+		return result.Err(err), err
+	}
+
+	address := options.ExecutorSpec.URI
+	runner, err := e.client(address)
+	if err != nil {
+		return result.Err(err), err
+	}
+
+	// runner may start the execution even when its response is lost, so without idempotency key
+	// the request is sent only once
+	var response *runnerapi.ExecuteResponse
+	err = e.retry(ctx, "Execute", options.IdempotencyKey != "", func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		response, err = runner.Execute(ctx, NewRunnerExecuteRequest(*execution, options), opts...)
+		return err
+	})
+	if err != nil {
+		err = runnerError(address, err)
+		return result.Err(err), err
+	}
+
+	if !response.Accepted {
+		err = fmt.Errorf("runner %s rejected execution: %s", address, response.Message)
+		return result.Err(err), err
+	}
+
+	e.mu.Lock()
+	e.addresses[execution.Id] = address
+	e.mu.Unlock()
+
+	if options.Sync {
+		defer e.forget(execution.Id)
+		stopDeadline := e.watchDeadline(ctx, *execution, options.Timeout)
+		result, err = e.watch(ctx, runner, address, execution.Id, nil)
+		result = stopDeadline(result)
+		execution.ExecutionResult = result
+		if err != nil {
+			return result.Err(err), err
+		}
+
+		return result, nil
+	}
+
+	go e.watchAsync(*execution, runner, address, options.Timeout)
+
+	return result, nil
+}
+
+// DryRun validates execution and returns request which would be sent to the runner, nothing is sent
+func (e *GRPCExecutor) DryRun(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*DryRunResult, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateSecretNamespaces(*execution, options); err != nil {
+		return nil, err
+	}
+
+	return &DryRunResult{Request: NewRunnerExecuteRequest(*execution, options)}, nil
+}
+
+// Abort aborts execution on the runner it was started on,
+// already finished execution is left untouched, its terminal result is returned with AlreadyFinishedError
+func (e *GRPCExecutor) Abort(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
+	if result, ok := FinishedResult(*execution); ok {
+		return result, &AlreadyFinishedError{ExecutionID: execution.Id, Result: result}
+	}
+
+	address, ok := e.address(execution.Id)
+	if !ok {
+		return nil, fmt.Errorf("execution %s is not running on any known runner", execution.Id)
+	}
+
+	runner, err := e.client(address)
+	if err != nil {
+		return nil, err
+	}
+
+	err = e.retry(ctx, "Abort", true, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := runner.Abort(ctx, &runnerapi.AbortRequest{ExecutionID: execution.Id}, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, runnerError(address, err)
+	}
+
+	result := NewAbortedExecutionResult(ctx, time.Now())
+	execution.ExecutionResult = result
+	return result, nil
+}
+
+// Capabilities returns execute options supported by gRPC executor, the runner request carries
+// only command, args, working directory and timeout, so Kubernetes specific options are not supported
+func (e *GRPCExecutor) Capabilities() Capabilities {
+	return Capabilities{
+		SupportsAbort:           true,
+		SupportsLogStream:       true,
+		SupportsCommandOverride: true,
+	}
+}
+
+// Logs streams execution output reported by the runner
+func (e *GRPCExecutor) Logs(ctx context.Context, id, namespace string) (logs chan output.Output, err error) {
+	address, ok := e.address(id)
+	if !ok {
+		return nil, fmt.Errorf("execution %s is not running on any known runner", id)
+	}
+
+	runner, err := e.client(address)
+	if err != nil {
+		return nil, err
+	}
+
+	logs = make(chan output.Output)
+	go func() {
+		defer close(logs)
+
+		result, err := e.watch(ctx, runner, address, id, func(update *runnerapi.StatusUpdate) {
+			if update.Output != "" {
+				logs <- output.NewOutputLine([]byte(update.Output))
+			}
+		})
+		if err != nil {
+			logs <- output.NewOutputError(err)
+			return
+		}
+
+		logs <- output.NewOutputResult(*result)
+	}()
+
+	return logs, nil
+}
+
+// FollowLogs streams execution output reported by the runner as log lines,
+// lines are dropped when consumer can't keep up
+func (e *GRPCExecutor) FollowLogs(ctx context.Context, id, namespace string) (<-chan LogLine, error) {
+	address, ok := e.address(id)
+	if !ok {
+		return nil, fmt.Errorf("execution %s is not running on any known runner", id)
+	}
+
+	runner, err := e.client(address)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := NewLogLineBuffer(DefaultLogLineBufferSize)
+	go func() {
+		defer buffer.Close()
+
+		_, err := e.watch(ctx, runner, address, id, func(update *runnerapi.StatusUpdate) {
+			for _, line := range runnerLogLines(address, update) {
+				buffer.Send(line)
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			e.log.Errorw("following runner logs error", "executionID", id, "runner", address, "error", err)
+		}
+
+		if dropped := buffer.Dropped(); dropped > 0 {
+			e.log.Warnw("log lines dropped, consumer too slow", "executionID", id, "dropped", dropped)
+		}
+	}()
+
+	return buffer.Lines(), nil
+}
+
+// Close closes all runner connections
+func (e *GRPCExecutor) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	for address, conn := range e.connections {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(e.connections, address)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (e *GRPCExecutor) watchAsync(execution testkube.Execution, runner runnerapi.RunnerClient, address string, timeout time.Duration) {
+	ctx := context.Background()
+	l := e.log.With("executionID", execution.Id, "runner", address)
+	stopDeadline := e.watchDeadline(ctx, execution, timeout)
+	result, err := e.watch(ctx, runner, address, execution.Id, nil)
+	result = stopDeadline(result)
+	e.forget(execution.Id)
+	if err != nil {
+		l.Errorw("watching runner execution error", "error", err)
+		result = result.Err(err)
+	}
+
+	execution.ExecutionResult = result
+	execution.Stop()
+
+	if e.updater != nil {
+		if err = e.updater.UpdateResult(ctx, execution.Id, execution); err != nil {
+			l.Errorw("update execution result error", "error", err)
+		}
+	}
+
+	if e.notifier == nil {
+		return
+	}
+
+	switch {
+	case result.IsAborted():
+		e.notifier.Notify(testkube.NewEventEndTestAborted(&execution))
+	case result.IsTimeout():
+		e.notifier.Notify(testkube.NewEventEndTestTimeout(&execution))
+	case result.IsFailed():
+		e.notifier.Notify(testkube.NewEventEndTestFailed(&execution))
+	default:
+		e.notifier.Notify(testkube.NewEventEndTestSuccess(&execution))
+	}
+}
+
+// watchDeadline aborts the execution on the runner when its timeout passes, as the runner isn't required
+// to enforce the forwarded timeout itself; returned function stops watching the deadline when the execution
+// is done, and marks the final result as timed out when the execution was aborted because of the deadline
+func (e *GRPCExecutor) watchDeadline(ctx context.Context, execution testkube.Execution, timeout time.Duration) func(result *testkube.ExecutionResult) *testkube.ExecutionResult {
+	ctx, cancel := context.WithCancel(ctx)
+	timedOut := make(chan *testkube.ExecutionResult, 1)
+	go func() {
+		result, err := NewDeadlineWatcher(e, e.clock).Watch(ctx, &execution, timeout)
+		if err != nil {
+			e.log.Errorw("aborting timed out execution error", "executionID", execution.Id, "error", err)
+		}
+		timedOut <- result
+	}()
+
+	return func(result *testkube.ExecutionResult) *testkube.ExecutionResult {
+		cancel()
+		deadline := <-timedOut
+		if deadline == nil || !deadline.IsTimeout() || (result.IsCompleted() && !result.IsAborted()) {
+			return result
+		}
+
+		result.Timeout()
+		result.ErrorMessage = deadline.ErrorMessage
+		return result
+	}
+}
+
+// watch follows runner status updates until execution is done, interrupted stream is resumed,
+// returned result always contains output collected so far
+func (e *GRPCExecutor) watch(ctx context.Context, runner runnerapi.RunnerClient, address, id string, onUpdate func(update *runnerapi.StatusUpdate)) (*testkube.ExecutionResult, error) {
+	result := testkube.NewRunningExecutionResult()
+	attempts := 0
+
+	for {
+		var trailer metadata.MD
+		err := e.watchStream(ctx, runner, id, result, onUpdate, grpc.Trailer(&trailer))
+		if err == nil {
+			return result, nil
+		}
+
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		// watching is read only, so throttled stream is resumed like the interrupted one
+		if !isStreamInterruption(err) && !isRetryable(err) {
+			return result, fmt.Errorf("watching execution %s on runner %s: %w", id, address, responseError(err))
+		}
+
+		attempts++
+		if attempts > e.options.WatchReconnectAttempts {
+			if e.metrics != nil {
+				e.metrics.IncRunnerRetryExhausted("Watch")
+			}
+			return result, runnerError(address, err)
+		}
+
+		delay, ok := retryAfter(trailer, time.Now())
+		if !ok {
+			delay = e.options.WatchReconnectDelay
+		}
+
+		if e.metrics != nil {
+			e.metrics.IncRunnerRetry("Watch", status.Code(err).String())
+		}
+		e.log.Warnw("runner watch stream interrupted, reconnecting", "executionID", id, "runner", address, "attempt", attempts, "delay", delay, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// watchStream reads single watch stream, nil error means execution is done
+func (e *GRPCExecutor) watchStream(ctx context.Context, runner runnerapi.RunnerClient, id string, result *testkube.ExecutionResult, onUpdate func(update *runnerapi.StatusUpdate), opts ...grpc.CallOption) error {
+	stream, err := runner.Watch(ctx, &runnerapi.WatchRequest{ExecutionID: id}, opts...)
+	if err != nil {
+		return err
+	}
+
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return err
+		}
+
+		applyStatusUpdate(result, update, e.options.MaxOutputSize)
+		if onUpdate != nil {
+			onUpdate(update)
+		}
+
+		if update.Done {
+			return nil
+		}
+	}
+}
+
+func (e *GRPCExecutor) client(address string) (runnerapi.RunnerClient, error) {
+	if address == "" {
+		return nil, errors.New("runner address is required")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if conn, ok := e.connections[address]; ok {
+		return runnerapi.NewRunnerClient(conn), nil
+	}
+
+	creds, err := e.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	dialOptions := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(e.options.MaxResponseSize)),
+	}, e.options.DialOptions...)
+	conn, err := grpc.Dial(address, dialOptions...)
+	if err != nil {
+		return nil, runnerError(address, err)
+	}
+
+	e.connections[address] = conn
+	return runnerapi.NewRunnerClient(conn), nil
+}
+
+func (e *GRPCExecutor) transportCredentials() (credentials.TransportCredentials, error) {
+	if e.options.Insecure {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := e.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// tlsConfig builds runner connection TLS config, the client certificate is sent even when runner certificate
+// verification is skipped, so mTLS runners accept the connection
+func (e *GRPCExecutor) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: e.options.SkipVerify}
+	if e.options.CertFile != "" && e.options.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(e.options.CertFile, e.options.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if e.options.CAFile != "" {
+		caCertificate, err := os.ReadFile(e.options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caCertificate) {
+			return nil, fmt.Errorf("failed to add runner CA's certificate")
+		}
+
+		tlsConfig.RootCAs = certPool
+	}
+
+	return tlsConfig, nil
+}
+
+func (e *GRPCExecutor) address(id string) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	address, ok := e.addresses[id]
+	return address, ok
+}
+
+func (e *GRPCExecutor) forget(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.addresses, id)
+}
+
+// NewRunnerExecuteRequest translates execute options into runner execute request
+func NewRunnerExecuteRequest(execution testkube.Execution, options ExecuteOptions) *runnerapi.ExecuteRequest {
+	request := &runnerapi.ExecuteRequest{
+		ExecutionID:    execution.Id,
+		ExecutionName:  execution.Name,
+		Number:         execution.Number,
+		TestName:       options.TestName,
+		TestNamespace:  execution.TestNamespace,
+		TestType:       options.TestSpec.Type_,
+		Image:          options.ExecutorSpec.Image,
+		Command:        options.ExecutorSpec.Command,
+		Args:           execution.Args,
+		Envs:           execution.Envs,
+		Labels:         options.Labels,
+		TimeoutSeconds: options.ActiveDeadlineSeconds(),
+		IdempotencyKey: options.IdempotencyKey,
+	}
+
+	if options.Request.Image != "" {
+		request.Image = options.Request.Image
+	}
+
+	if len(options.Request.Command) != 0 {
+		request.Command = options.Request.Command
+	}
+
+	request.Command, request.Args = options.CommandAndArgs(request.Command, request.Args)
+	request.WorkingDir = options.WorkingDir
+
+	if content := execution.Content; content != nil {
+		request.Content = &runnerapi.Content{
+			Type: content.Type_,
+			Data: content.Data,
+			URI:  content.Uri,
+		}
+
+		if repository := content.Repository; repository != nil {
+			request.Content.Repository = repository.Uri
+			request.Content.Branch = repository.Branch
+			request.Content.Commit = repository.Commit
+			request.Content.Path = repository.Path
+		}
+	}
+
+	return request
+}
+
+// runnerLogLines splits status update output into log lines
+func runnerLogLines(address string, update *runnerapi.StatusUpdate) []LogLine {
+	if update.Output == "" {
+		return nil
+	}
+
+	stream := LogStream(update.Stream)
+	if stream == "" {
+		stream = LogStreamStdout
+	}
+
+	at := update.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	var lines []LogLine
+	for _, content := range strings.Split(strings.TrimSuffix(update.Output, "\n"), "\n") {
+		lines = append(lines, LogLine{Time: at, Stream: stream, Source: address, Content: content})
+	}
+
+	return lines
+}
+
+// applyStatusUpdate merges runner status update into execution result, output is truncated at the maximum output size
+func applyStatusUpdate(result *testkube.ExecutionResult, update *runnerapi.StatusUpdate, maxOutputSize int) {
+	appendOutput(result, update.Output, maxOutputSize)
+	if update.ErrorMessage != "" {
+		result.ErrorMessage = update.ErrorMessage
+	}
+
+	switch status := testkube.ExecutionStatus(update.Status); status {
+	case testkube.QUEUED_ExecutionStatus, testkube.RUNNING_ExecutionStatus, testkube.PASSED_ExecutionStatus,
+		testkube.FAILED_ExecutionStatus, testkube.ABORTED_ExecutionStatus, testkube.TIMEOUT_ExecutionStatus:
+		result.Status = testkube.StatusPtr(status)
+	}
+}
+
+// isStreamInterruption checks if watch stream was broken by connection problems and can be resumed
+func isStreamInterruption(err error) bool {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted, codes.Internal:
+		return true
+	}
+
+	return false
+}
+
+// runnerError wraps connection errors with ErrRunnerUnavailable, so they are distinguished from test failures
+func runnerError(address string, err error) error {
+	if _, ok := status.FromError(err); !ok || status.Code(err) == codes.Unavailable || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %s: %w", ErrRunnerUnavailable, address, err)
+	}
+
+	return fmt.Errorf("runner %s: %w", address, responseError(err))
+}
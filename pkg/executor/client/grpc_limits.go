@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+const (
+	// defaultMaxResponseSize is a maximum size of single runner response, it's the gRPC default
+	defaultMaxResponseSize = 4 << 20
+	// defaultMaxOutputSize is a maximum size of execution output collected from runner status updates
+	defaultMaxOutputSize = 8 << 20
+	// defaultRequestTimeout limits single Execute and Abort call to the runner
+	defaultRequestTimeout = 30 * time.Second
+	// outputTruncationMarker is appended to the output cut at the maximum output size
+	outputTruncationMarker = "\n[output truncated]\n"
+)
+
+// ErrResponseTooLarge is returned when runner response exceeds the maximum response size,
+// the response is rejected before it's read into memory
+var ErrResponseTooLarge = errors.New("runner response too large")
+
+// requestContext limits single runner call with the request timeout, the execution timeout is enforced separately
+func (e *GRPCExecutor) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, e.options.RequestTimeout)
+}
+
+// responseError wraps error of the response rejected by the gRPC client because of its size with ErrResponseTooLarge
+func responseError(err error) error {
+	if s, ok := status.FromError(err); ok && s.Code() == codes.ResourceExhausted && strings.Contains(s.Message(), "larger than max") {
+		return fmt.Errorf("%w: %w", ErrResponseTooLarge, err)
+	}
+
+	return err
+}
+
+// appendOutput appends runner output to the execution result, output beyond the limit is dropped
+// and the result records it was truncated together with the size of the whole output
+func appendOutput(result *testkube.ExecutionResult, output string, limit int) {
+	if output == "" {
+		return
+	}
+
+	if result.OutputTruncated {
+		result.OutputSize += int64(len(output))
+		return
+	}
+
+	if len(result.Output)+len(output) <= limit {
+		result.Output += output
+		return
+	}
+
+	result.OutputTruncated = true
+	result.OutputSize = int64(len(result.Output) + len(output))
+
+	// cut at rune boundary, so truncated output stays valid UTF-8
+	kept := max(limit-len(result.Output), 0)
+	for kept > 0 && !utf8.RuneStart(output[kept]) {
+		kept--
+	}
+
+	result.Output += output[:kept] + outputTruncationMarker
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/client/runnerapi"
+)
+
+func TestAppendOutput(t *testing.T) {
+	tests := map[string]struct {
+		outputs   []string
+		output    string
+		truncated bool
+		size      int64
+	}{
+		"within limit": {
+			outputs: []string{"01234", "56789"},
+			output:  "0123456789",
+		},
+		"over limit": {
+			outputs:   []string{"01234", "56789abc"},
+			output:    "0123456789" + outputTruncationMarker,
+			truncated: true,
+			size:      13,
+		},
+		"after truncation": {
+			outputs:   []string{"0123456789abc", "def", ""},
+			output:    "0123456789" + outputTruncationMarker,
+			truncated: true,
+			size:      16,
+		},
+		"rune boundary": {
+			outputs:   []string{"012345678ż"},
+			output:    "012345678" + outputTruncationMarker,
+			truncated: true,
+			size:      11,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := testkube.NewRunningExecutionResult()
+			for _, output := range tt.outputs {
+				appendOutput(result, output, 10)
+			}
+
+			assert.Equal(t, tt.output, result.Output)
+			assert.Equal(t, tt.truncated, result.OutputTruncated)
+			assert.Equal(t, tt.size, result.OutputSize)
+		})
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	tooLarge := status.Error(codes.ResourceExhausted, "grpc: received message larger than max (100 vs. 10)")
+	assert.ErrorIs(t, responseError(tooLarge), ErrResponseTooLarge)
+	assert.ErrorIs(t, runnerError("bufnet", tooLarge), ErrResponseTooLarge)
+
+	throttled := status.Error(codes.ResourceExhausted, "too many executions")
+	assert.NotErrorIs(t, responseError(throttled), ErrResponseTooLarge)
+}
+
+func TestGRPCExecutor_OutputTruncated(t *testing.T) {
+	runner := &testRunner{updates: []*runnerapi.StatusUpdate{
+		{ExecutionID: "exec-1", Status: "running", Output: "starting\n"},
+		{ExecutionID: "exec-1", Status: "passed", Output: "done\n", Done: true},
+	}}
+	executor := newTestGRPCExecutor(startTestRunner(t, runner), nil)
+	executor.options.MaxOutputSize = 10
+	defer executor.Close()
+
+	result, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, grpcExecuteOptions(true))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsPassed())
+	assert.Equal(t, "starting\nd"+outputTruncationMarker, result.Output)
+	assert.True(t, result.OutputTruncated)
+	assert.Equal(t, int64(14), result.OutputSize)
+}
+
+func TestGRPCExecutor_ResponseTooLarge(t *testing.T) {
+	runner := &testRunner{updates: []*runnerapi.StatusUpdate{
+		{ExecutionID: "exec-1", Status: "passed", Output: strings.Repeat("x", 64*1024), Done: true},
+	}}
+	executor := newTestGRPCExecutor(startTestRunner(t, runner), nil)
+	executor.options.MaxResponseSize = 1024
+	defer executor.Close()
+
+	result, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, grpcExecuteOptions(true))
+
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+	assert.True(t, result.IsFailed())
+	assert.Empty(t, result.Output)
+}
+
+func TestGRPCExecutor_RequestTimeout(t *testing.T) {
+	runner := &testRunner{delay: time.Minute}
+	executor := newTestGRPCExecutor(startTestRunner(t, runner), nil)
+	executor.options.RequestTimeout = 50 * time.Millisecond
+	defer executor.Close()
+
+	start := time.Now()
+	result, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, grpcExecuteOptions(true))
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.True(t, result.IsFailed())
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Empty(t, runner.requests)
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultRetryMaxAttempts is a maximum number of tries of single runner call
+	defaultRetryMaxAttempts = 5
+	// defaultRetryMaxElapsed is a maximum time spent by single runner call including delays between tries
+	defaultRetryMaxElapsed = 2 * time.Minute
+	// retryAfterMetadata is a metadata key the runner sets to ask for a delay before the next try,
+	// in seconds or as HTTP date
+	retryAfterMetadata = "retry-after"
+)
+
+// GRPCRetryOptions configure retries of runner calls which the runner throttled or couldn't handle
+type GRPCRetryOptions struct {
+	// MaxAttempts is a maximum number of tries of single call including the first one, 1 disables retries
+	MaxAttempts int
+	// MaxElapsed is a maximum time spent by single call including delays between tries
+	MaxElapsed time.Duration
+	// Backoff computes delays between tries when the runner doesn't send retry-after metadata
+	Backoff WatchOptions
+}
+
+// DefaultGRPCRetryBackoff returns exponential backoff with jitter starting at half a second
+func DefaultGRPCRetryBackoff() WatchOptions {
+	return WatchOptions{
+		Interval:    500 * time.Millisecond,
+		MaxInterval: 30 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+	}
+}
+
+// RunnerRetryMetrics observes retries of runner calls
+type RunnerRetryMetrics interface {
+	// IncRunnerRetry counts retried runner call, reason is gRPC code of the failed try
+	IncRunnerRetry(method, reason string)
+	// IncRunnerRetryExhausted counts runner call which still failed when its retry budget was spent
+	IncRunnerRetryExhausted(method string)
+}
+
+// WithMetrics sets metrics observing retries of runner calls
+func (e *GRPCExecutor) WithMetrics(metrics RunnerRetryMetrics) *GRPCExecutor {
+	e.metrics = metrics
+	return e
+}
+
+// retry calls the runner until the call succeeds, fails with error which can't be retried, or the retry budget
+// is spent; throttled or unavailable call is tried again after the delay the runner asks for in retry-after
+// metadata, or after exponential backoff with jitter; idempotent is false for calls which are tried only once
+func (e *GRPCExecutor) retry(ctx context.Context, method string, idempotent bool, call func(ctx context.Context, opts ...grpc.CallOption) error) error {
+	start := e.clock.Now()
+	backoff := NewWatchBackoff(e.options.Retry.Backoff, nil)
+	for attempt := 1; ; attempt++ {
+		var header, trailer metadata.MD
+		requestCtx, cancel := e.requestContext(ctx)
+		err := call(requestCtx, grpc.Header(&header), grpc.Trailer(&trailer))
+		cancel()
+		if err == nil || !idempotent || !isRetryable(err) {
+			return err
+		}
+
+		delay, ok := retryAfter(metadata.Join(header, trailer), e.clock.Now())
+		if !ok {
+			delay = backoff.Next(false)
+		}
+
+		if attempt >= e.options.Retry.MaxAttempts || e.clock.Now().Add(delay).Sub(start) > e.options.Retry.MaxElapsed {
+			if e.metrics != nil {
+				e.metrics.IncRunnerRetryExhausted(method)
+			}
+			return err
+		}
+
+		if e.metrics != nil {
+			e.metrics.IncRunnerRetry(method, status.Code(err).String())
+		}
+		e.log.Warnw("runner call failed, retrying", "method", method, "attempt", attempt, "delay", delay, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-e.clock.After(delay):
+		}
+	}
+}
+
+// isRetryable checks if the runner throttled the call or was unavailable, so the call can be tried again
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable:
+		return true
+	case codes.ResourceExhausted:
+		// response over the size limit would be rejected again
+		return !errors.Is(responseError(err), ErrResponseTooLarge)
+	}
+
+	return false
+}
+
+// retryAfter reads the delay requested by the runner, retry-after is either seconds or HTTP date
+func retryAfter(md metadata.MD, now time.Time) (time.Duration, bool) {
+	values := md.Get(retryAfterMetadata)
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	value := strings.TrimSpace(values[0])
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/client/runnerapi"
+)
+
+type fakeRetryMetrics struct {
+	mu        sync.Mutex
+	retries   []string
+	exhausted []string
+}
+
+func (m *fakeRetryMetrics) IncRunnerRetry(method, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries = append(m.retries, method+"/"+reason)
+}
+
+func (m *fakeRetryMetrics) IncRunnerRetryExhausted(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exhausted = append(m.exhausted, method)
+}
+
+func newTestRetryExecutor(t *testing.T, runner *testRunner) (*GRPCExecutor, *fakeClock, *fakeRetryMetrics) {
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	metrics := &fakeRetryMetrics{}
+	executor := newTestGRPCExecutor(startTestRunner(t, runner), nil).WithMetrics(metrics)
+	executor.clock = clock
+	executor.options.Retry.Backoff.Jitter = 0
+	t.Cleanup(func() { _ = executor.Close() })
+
+	return executor, clock, metrics
+}
+
+func idempotentExecuteOptions() ExecuteOptions {
+	options := grpcExecuteOptions(true)
+	options.IdempotencyKey = "delivery-1"
+	return options
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		value string
+		delay time.Duration
+		ok    bool
+	}{
+		"seconds":   {value: "3", delay: 3 * time.Second, ok: true},
+		"http date": {value: now.Add(10 * time.Second).Format(http.TimeFormat), delay: 10 * time.Second, ok: true},
+		"past date": {value: now.Add(-time.Minute).Format(http.TimeFormat), delay: 0, ok: true},
+		"negative":  {value: "-1"},
+		"invalid":   {value: "soon"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			delay, ok := retryAfter(metadata.Pairs(retryAfterMetadata, tt.value), now)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.delay, delay)
+		})
+	}
+
+	_, ok := retryAfter(metadata.MD{}, now)
+	assert.False(t, ok)
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, isRetryable(status.Error(codes.Unavailable, "connection refused")))
+	assert.True(t, isRetryable(status.Error(codes.ResourceExhausted, "too many executions")))
+	assert.False(t, isRetryable(status.Error(codes.ResourceExhausted, "grpc: received message larger than max (100 vs. 10)")))
+	assert.False(t, isRetryable(status.Error(codes.InvalidArgument, "unknown test type")))
+	assert.False(t, isRetryable(context.Canceled))
+}
+
+func TestGRPCExecutor_RetriesThrottledExecute(t *testing.T) {
+	runner := &testRunner{throttle: 2, retry: "5", updates: []*runnerapi.StatusUpdate{
+		{ExecutionID: "exec-1", Status: "passed", Done: true},
+	}}
+	executor, clock, metrics := newTestRetryExecutor(t, runner)
+
+	type response struct {
+		result *testkube.ExecutionResult
+		err    error
+	}
+	done := make(chan response, 1)
+	go func() {
+		result, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, idempotentExecuteOptions())
+		done <- response{result: result, err: err}
+	}()
+
+	for i := 0; i < 2; i++ {
+		<-clock.added
+		clock.Advance(4 * time.Second)
+		select {
+		case <-done:
+			t.Fatal("retry should wait for the delay requested by the runner")
+		case <-time.After(50 * time.Millisecond):
+		}
+		clock.Advance(time.Second)
+	}
+
+	r := <-done
+	require.NoError(t, r.err)
+	assert.True(t, r.result.IsPassed())
+	assert.Equal(t, 3, runner.calls)
+	assert.Len(t, runner.requests, 1)
+	assert.Equal(t, "delivery-1", runner.requests[0].IdempotencyKey)
+	assert.Equal(t, []string{"Execute/ResourceExhausted", "Execute/ResourceExhausted"}, metrics.retries)
+	assert.Empty(t, metrics.exhausted)
+}
+
+func TestGRPCExecutor_ExecuteWithoutIdempotencyKeyIsNotRetried(t *testing.T) {
+	runner := &testRunner{throttle: 1}
+	executor, _, metrics := newTestRetryExecutor(t, runner)
+
+	result, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, grpcExecuteOptions(true))
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, 1, runner.calls)
+	assert.Empty(t, metrics.retries)
+}
+
+func TestGRPCExecutor_RetryBudget(t *testing.T) {
+	t.Run("attempts", func(t *testing.T) {
+		runner := &testRunner{throttle: 10}
+		executor, clock, metrics := newTestRetryExecutor(t, runner)
+		executor.options.Retry.MaxAttempts = 3
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, idempotentExecuteOptions())
+			done <- err
+		}()
+
+		// exponential backoff without retry-after
+		for _, delay := range []time.Duration{500 * time.Millisecond, time.Second} {
+			<-clock.added
+			clock.Advance(delay)
+		}
+
+		err := <-done
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, 3, runner.calls)
+		assert.Len(t, metrics.retries, 2)
+		assert.Equal(t, []string{"Execute"}, metrics.exhausted)
+	})
+
+	t.Run("elapsed", func(t *testing.T) {
+		runner := &testRunner{throttle: 10, retry: "60"}
+		executor, _, metrics := newTestRetryExecutor(t, runner)
+		executor.options.Retry.MaxElapsed = 30 * time.Second
+
+		_, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, idempotentExecuteOptions())
+
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, 1, runner.calls)
+		assert.Empty(t, metrics.retries)
+		assert.Equal(t, []string{"Execute"}, metrics.exhausted)
+	})
+}
+
+func TestGRPCExecutor_RetryCancelled(t *testing.T) {
+	runner := &testRunner{throttle: 10, retry: "60"}
+	executor, clock, _ := newTestRetryExecutor(t, runner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := executor.Execute(ctx, &testkube.Execution{Id: "exec-1"}, idempotentExecuteOptions())
+		done <- err
+	}()
+
+	<-clock.added
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.Equal(t, codes.Canceled, status.Code(err))
+		assert.Equal(t, 1, runner.calls)
+	case <-time.After(5 * time.Second):
+		t.Fatal("retry didn't stop on context cancellation")
+	}
+}
@@ -0,0 +1,401 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/client/runnerapi"
+)
+
+type testRunner struct {
+	runnerapi.UnimplementedRunnerServer
+
+	mu        sync.Mutex
+	requests  []*runnerapi.ExecuteRequest
+	updates   []*runnerapi.StatusUpdate
+	sent      int
+	interrupt bool
+	block     bool
+	delay     time.Duration
+	calls     int
+	throttle  int
+	retry     string
+	aborts    int
+	aborted   chan struct{}
+	abortOnce sync.Once
+}
+
+func (r *testRunner) Execute(ctx context.Context, request *runnerapi.ExecuteRequest) (*runnerapi.ExecuteResponse, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(r.delay):
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls++
+	if r.throttle > 0 {
+		r.throttle--
+		if r.retry != "" {
+			_ = grpc.SetTrailer(ctx, metadata.Pairs(retryAfterMetadata, r.retry))
+		}
+		return nil, status.Error(codes.ResourceExhausted, "too many executions")
+	}
+
+	r.requests = append(r.requests, request)
+	return &runnerapi.ExecuteResponse{Accepted: true}, nil
+}
+
+func (r *testRunner) Watch(request *runnerapi.WatchRequest, stream runnerapi.Runner_WatchServer) error {
+	for {
+		r.mu.Lock()
+		if r.sent == len(r.updates) {
+			r.mu.Unlock()
+			break
+		}
+		update := r.updates[r.sent]
+		r.sent++
+		interrupt := r.interrupt
+		r.interrupt = false
+		r.mu.Unlock()
+
+		if err := stream.Send(update); err != nil {
+			return err
+		}
+
+		if interrupt {
+			return status.Error(codes.Unavailable, "runner restarting")
+		}
+	}
+
+	if !r.block {
+		return nil
+	}
+
+	select {
+	case <-stream.Context().Done():
+		return nil
+	case <-r.aborted:
+		return stream.Send(&runnerapi.StatusUpdate{ExecutionID: request.ExecutionID, Status: "aborted", Done: true})
+	}
+}
+
+func (r *testRunner) Abort(ctx context.Context, request *runnerapi.AbortRequest) (*runnerapi.AbortResponse, error) {
+	r.mu.Lock()
+	r.aborts++
+	r.mu.Unlock()
+
+	r.abortOnce.Do(func() {
+		close(r.aborted)
+	})
+	return &runnerapi.AbortResponse{Status: "aborted"}, nil
+}
+
+type fakeResultUpdater struct {
+	results chan testkube.Execution
+}
+
+func (u *fakeResultUpdater) UpdateResult(ctx context.Context, id string, execution testkube.Execution) error {
+	u.results <- execution
+	return nil
+}
+
+func startTestRunner(t *testing.T, runner *testRunner) *bufconn.Listener {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	runnerapi.RegisterRunnerServer(server, runner)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return listener
+}
+
+func newTestGRPCExecutor(listener *bufconn.Listener, updater ExecutionResultUpdater) *GRPCExecutor {
+	return NewGRPCExecutor(zap.NewNop().Sugar(), GRPCOptions{
+		Insecure: true,
+		DialOptions: []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		})},
+		WatchReconnectAttempts: 1,
+		WatchReconnectDelay:    time.Millisecond,
+	}, updater, nil)
+}
+
+func grpcExecuteOptions(sync bool) ExecuteOptions {
+	return ExecuteOptions{
+		ID:           "exec-1",
+		TestName:     "test",
+		ExecutorName: "device-farm",
+		ExecutorSpec: executorv1.ExecutorSpec{ExecutorType: ExecutorTypeGRPC, URI: "bufnet", Image: "farm"},
+		Sync:         sync,
+		Timeout:      time.Minute,
+	}
+}
+
+func TestGRPCExecutor_Execute(t *testing.T) {
+	runner := &testRunner{updates: []*runnerapi.StatusUpdate{
+		{ExecutionID: "exec-1", Status: "running", Output: "starting\n"},
+		{ExecutionID: "exec-1", Status: "passed", Output: "done\n", Done: true},
+	}}
+	executor := newTestGRPCExecutor(startTestRunner(t, runner), nil)
+	defer executor.Close()
+
+	execution := &testkube.Execution{Id: "exec-1", Args: []string{"--device", "pixel"}}
+	result, err := executor.Execute(context.Background(), execution, grpcExecuteOptions(true))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsPassed())
+	assert.Equal(t, "starting\ndone\n", result.Output)
+	assert.Equal(t, result, execution.ExecutionResult)
+	require.Len(t, runner.requests, 1)
+	assert.Equal(t, "exec-1", runner.requests[0].ExecutionID)
+	assert.Equal(t, "farm", runner.requests[0].Image)
+	assert.Equal(t, []string{"--device", "pixel"}, runner.requests[0].Args)
+	assert.Equal(t, int64(60), runner.requests[0].TimeoutSeconds)
+}
+
+func TestGRPCExecutor_DryRunMatchesSentRequest(t *testing.T) {
+	runner := &testRunner{updates: []*runnerapi.StatusUpdate{
+		{ExecutionID: "exec-1", Status: "passed", Done: true},
+	}}
+	executor := newTestGRPCExecutor(startTestRunner(t, runner), nil)
+	defer executor.Close()
+
+	execution := testkube.Execution{Id: "exec-1", Args: []string{"--device", "pixel"}}
+	dryRun, err := DryRun(context.Background(), NewDelayedExecutor(executor, nil, nil), &execution, grpcExecuteOptions(true))
+	require.NoError(t, err)
+	assert.Empty(t, runner.requests)
+
+	_, err = executor.Execute(context.Background(), &execution, grpcExecuteOptions(true))
+	require.NoError(t, err)
+	require.Len(t, runner.requests, 1)
+	assert.Equal(t, runner.requests[0], dryRun.Request)
+	assert.Empty(t, dryRun.Objects)
+
+	_, err = executor.DryRun(context.Background(), &execution, ExecuteOptions{TestName: "test"})
+	assert.EqualError(t, err, "execution id is required")
+}
+
+func TestNewRunnerExecuteRequest_CommandOverrides(t *testing.T) {
+	options := grpcExecuteOptions(false)
+	options.ExecutorSpec.Command = []string{"farm-run"}
+	options.Command = []string{"farm-debug"}
+	options.Args = []string{"--grep", `name with "quotes"`}
+	options.WorkingDir = "e2e/smoke"
+
+	request := NewRunnerExecuteRequest(testkube.Execution{Id: "exec-1", Args: []string{"--device", "pixel"}}, options)
+
+	assert.Equal(t, []string{"farm-debug"}, request.Command)
+	assert.Equal(t, []string{"--device", "pixel", "--grep", `name with "quotes"`}, request.Args)
+	assert.Equal(t, "e2e/smoke", request.WorkingDir)
+}
+
+func TestGRPCExecutor_StreamInterruption(t *testing.T) {
+	runner := &testRunner{interrupt: true, updates: []*runnerapi.StatusUpdate{
+		{ExecutionID: "exec-1", Status: "running", Output: "starting\n"},
+		{ExecutionID: "exec-1", Status: "failed", Output: "assertion failed\n", ErrorMessage: "1 check failed", Done: true},
+	}}
+	executor := newTestGRPCExecutor(startTestRunner(t, runner), nil)
+	defer executor.Close()
+
+	result, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, grpcExecuteOptions(true))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, "starting\nassertion failed\n", result.Output)
+	assert.Equal(t, "1 check failed", result.ErrorMessage)
+	assert.False(t, IsInfrastructureFailure(result, err))
+}
+
+func TestGRPCExecutor_StreamLost(t *testing.T) {
+	runner := &testRunner{interrupt: true, updates: []*runnerapi.StatusUpdate{
+		{ExecutionID: "exec-1", Status: "running", Output: "starting\n"},
+	}}
+	executor := newTestGRPCExecutor(startTestRunner(t, runner), nil)
+	defer executor.Close()
+
+	result, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, grpcExecuteOptions(true))
+
+	assert.ErrorIs(t, err, ErrRunnerUnavailable)
+	assert.Equal(t, "starting\n", result.Output)
+	assert.True(t, IsInfrastructureFailure(result, err))
+}
+
+func TestGRPCExecutor_RunnerUnavailable(t *testing.T) {
+	listener := bufconn.Listen(1024)
+	listener.Close()
+	executor := newTestGRPCExecutor(listener, nil)
+	defer executor.Close()
+
+	result, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, grpcExecuteOptions(true))
+
+	assert.ErrorIs(t, err, ErrRunnerUnavailable)
+	assert.True(t, result.IsFailed())
+}
+
+func TestGRPCExecutor_Abort(t *testing.T) {
+	runner := &testRunner{block: true, aborted: make(chan struct{}), updates: []*runnerapi.StatusUpdate{
+		{ExecutionID: "exec-1", Status: "running", Output: "starting\n"},
+	}}
+	updater := &fakeResultUpdater{results: make(chan testkube.Execution, 1)}
+	executor := newTestGRPCExecutor(startTestRunner(t, runner), updater)
+	defer executor.Close()
+
+	execution := &testkube.Execution{Id: "exec-1"}
+	result, err := executor.Execute(context.Background(), execution, grpcExecuteOptions(false))
+	require.NoError(t, err)
+	assert.True(t, result.IsRunning())
+
+	result, err = executor.Abort(WithAbortedBy(context.Background(), "api"), execution)
+	require.NoError(t, err)
+	assert.True(t, result.IsAborted())
+	assert.Contains(t, result.ErrorMessage, "execution aborted by api at ")
+
+	select {
+	case updated := <-updater.results:
+		assert.True(t, updated.ExecutionResult.IsAborted())
+		assert.Equal(t, "starting\n", updated.ExecutionResult.Output)
+	case <-time.After(5 * time.Second):
+		t.Fatal("execution result wasn't updated after abort")
+	}
+
+	result, err = executor.Abort(context.Background(), execution)
+	assert.ErrorIs(t, err, ErrAlreadyFinished)
+	assert.True(t, result.IsAborted())
+	assert.Equal(t, 1, runner.aborts)
+
+	_, err = executor.Abort(context.Background(), &testkube.Execution{Id: "exec-2"})
+	assert.EqualError(t, err, "execution exec-2 is not running on any known runner")
+}
+
+func TestGRPCExecutor_Timeout(t *testing.T) {
+	for name, sync := range map[string]bool{"sync": true, "async": false} {
+		t.Run(name, func(t *testing.T) {
+			runner := &testRunner{block: true, aborted: make(chan struct{}), updates: []*runnerapi.StatusUpdate{
+				{ExecutionID: "exec-1", Status: "running", Output: "starting\n"},
+			}}
+			updater := &fakeResultUpdater{results: make(chan testkube.Execution, 1)}
+			clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			executor := newTestGRPCExecutor(startTestRunner(t, runner), updater)
+			executor.clock = clock
+			defer executor.Close()
+
+			results := make(chan *testkube.ExecutionResult, 1)
+			go func() {
+				result, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1", StartTime: clock.Now()}, grpcExecuteOptions(sync))
+				assert.NoError(t, err)
+				results <- result
+			}()
+
+			<-clock.added
+			clock.Advance(59 * time.Second)
+			if !sync {
+				assert.True(t, (<-results).IsRunning())
+			}
+			select {
+			case <-runner.aborted:
+				t.Fatal("execution aborted before deadline")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			clock.Advance(time.Second)
+			var result *testkube.ExecutionResult
+			if sync {
+				result = <-results
+			} else {
+				result = (<-updater.results).ExecutionResult
+			}
+			assert.True(t, result.IsTimeout())
+			assert.Equal(t, "execution timed out after 1m0s", result.ErrorMessage)
+			assert.Equal(t, "starting\n", result.Output)
+			assert.Equal(t, 1, runner.aborts)
+		})
+	}
+}
+
+func TestGRPCExecutor_FollowLogs(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	runner := &testRunner{updates: []*runnerapi.StatusUpdate{
+		{ExecutionID: "exec-1", Status: "running", Output: "starting\n", Timestamp: at},
+		{ExecutionID: "exec-1", Status: "running", Output: "retrying\nconnection refused\n", Stream: "stderr", Timestamp: at.Add(time.Second)},
+		{ExecutionID: "exec-1", Status: "passed", Done: true},
+	}}
+	executor := newTestGRPCExecutor(startTestRunner(t, runner), nil)
+	defer executor.Close()
+	executor.addresses["exec-1"] = "bufnet"
+
+	logs, err := executor.FollowLogs(context.Background(), "exec-1", "")
+	require.NoError(t, err)
+
+	var lines []LogLine
+	for line := range logs {
+		lines = append(lines, line)
+	}
+
+	assert.Equal(t, []LogLine{
+		{Time: at, Stream: LogStreamStdout, Source: "bufnet", Content: "starting"},
+		{Time: at.Add(time.Second), Stream: LogStreamStderr, Source: "bufnet", Content: "retrying"},
+		{Time: at.Add(time.Second), Stream: LogStreamStderr, Source: "bufnet", Content: "connection refused"},
+	}, lines)
+}
+
+// writeClientCertificate writes self-signed client certificate and its key, and returns their paths
+func writeClientCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "testkube"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestGRPCExecutor_TLSConfigSkipVerifySendsClientCertificate(t *testing.T) {
+	certFile, keyFile := writeClientCertificate(t)
+	e := &GRPCExecutor{options: GRPCOptions{SkipVerify: true, CertFile: certFile, KeyFile: keyFile}}
+
+	config, err := e.tlsConfig()
+
+	require.NoError(t, err)
+	assert.True(t, config.InsecureSkipVerify)
+	assert.Len(t, config.Certificates, 1)
+}
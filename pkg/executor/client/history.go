@@ -0,0 +1,241 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+// DefaultHistoryLimit is a default maximum number of executions fetched by history machine
+const DefaultHistoryLimit = 100
+
+// ExecutionHistory lists the latest executions of the test started since given time, newest first
+type ExecutionHistory interface {
+	ListExecutions(ctx context.Context, testName string, since time.Time, limit int) ([]testkube.Execution, error)
+}
+
+// HistoryMachine is expressions machine exposing execution history of the test, i.e. history.lastStatus,
+// history.count, history.statusesLast(n), history.passRateLast(n) and history.medianDurationSince(duration);
+// the history is queried only when the expression references it, and the results are kept for the machine lifetime,
+// so the machine should be created for each resolution
+type HistoryMachine struct {
+	ctx      context.Context
+	history  ExecutionHistory
+	testName string
+	clock    Clock
+	limit    int
+
+	mu      sync.Mutex
+	fetched map[time.Duration][]testkube.Execution
+}
+
+// NewHistoryMachine creates history machine for the test
+func NewHistoryMachine(ctx context.Context, history ExecutionHistory, testName string, clock Clock) *HistoryMachine {
+	return &HistoryMachine{
+		ctx:      ctx,
+		history:  history,
+		testName: testName,
+		clock:    clock,
+		limit:    DefaultHistoryLimit,
+		fetched:  make(map[time.Duration][]testkube.Execution),
+	}
+}
+
+// WithLimit sets maximum number of executions fetched by single query, accessors asking for more fail
+func (m *HistoryMachine) WithLimit(limit int) *HistoryMachine {
+	if limit > 0 {
+		m.limit = limit
+	}
+	return m
+}
+
+// Get resolves history.lastStatus and history.count
+func (m *HistoryMachine) Get(name string) (expressionstcl.Expression, bool, error) {
+	switch name {
+	case "history.lastStatus":
+		executions, err := m.executions(0)
+		if err != nil {
+			return nil, true, err
+		}
+		status := ""
+		if len(executions) > 0 {
+			status = executionStatus(executions[0])
+		}
+		return expressionstcl.NewValue(status), true, nil
+	case "history.count":
+		executions, err := m.executions(0)
+		if err != nil {
+			return nil, true, err
+		}
+		return expressionstcl.NewValue(len(executions)), true, nil
+	}
+	return nil, false, nil
+}
+
+// Call resolves history.statusesLast(n), history.passRateLast(n) and history.medianDurationSince(duration)
+func (m *HistoryMachine) Call(name string, args ...expressionstcl.StaticValue) (expressionstcl.Expression, bool, error) {
+	var value interface{}
+	var err error
+	switch name {
+	case "history.statusesLast":
+		value, err = m.statusesLast(args...)
+	case "history.passRateLast":
+		value, err = m.passRateLast(args...)
+	case "history.medianDurationSince":
+		value, err = m.medianDurationSince(args...)
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, fmt.Errorf("%s() function: %w", name, err)
+	}
+	return expressionstcl.NewValue(value), true, nil
+}
+
+// executions fetches the latest executions started within the window, zero window means no time bound
+func (m *HistoryMachine) executions(window time.Duration) ([]testkube.Execution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if executions, ok := m.fetched[window]; ok {
+		return executions, nil
+	}
+
+	var since time.Time
+	if window > 0 {
+		since = m.clock.Now().Add(-window)
+	}
+	executions, err := m.history.ListExecutions(m.ctx, m.testName, since, m.limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing executions of %s test: %w", m.testName, err)
+	}
+	if len(executions) > m.limit {
+		executions = executions[:m.limit]
+	}
+	sort.SliceStable(executions, func(i, j int) bool {
+		return executions[i].StartTime.After(executions[j].StartTime)
+	})
+
+	m.fetched[window] = executions
+	return executions, nil
+}
+
+// finishedLast returns up to n latest finished executions, running and queued ones are ignored
+func (m *HistoryMachine) finishedLast(args ...expressionstcl.StaticValue) ([]testkube.Execution, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expects 1 argument, %d provided", len(args))
+	}
+	n, err := args[0].IntValue()
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("argument should be a positive integer, %s provided", args[0].String())
+	}
+	if int(n) > m.limit {
+		return nil, fmt.Errorf("%d exceeds the history limit of %d", n, m.limit)
+	}
+
+	executions, err := m.executions(0)
+	if err != nil {
+		return nil, err
+	}
+	finished := make([]testkube.Execution, 0, n)
+	for _, execution := range executions {
+		if len(finished) == int(n) {
+			break
+		}
+		if isFinishedExecution(execution) {
+			finished = append(finished, execution)
+		}
+	}
+	return finished, nil
+}
+
+func (m *HistoryMachine) statusesLast(args ...expressionstcl.StaticValue) ([]string, error) {
+	executions, err := m.finishedLast(args...)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]string, len(executions))
+	for i := range executions {
+		statuses[i] = executionStatus(executions[i])
+	}
+	return statuses, nil
+}
+
+// passRateLast returns fraction of passed executions, it's 0 when there are none
+func (m *HistoryMachine) passRateLast(args ...expressionstcl.StaticValue) (float64, error) {
+	executions, err := m.finishedLast(args...)
+	if err != nil || len(executions) == 0 {
+		return 0, err
+	}
+	passed := 0
+	for i := range executions {
+		if executionStatus(executions[i]) == string(testkube.PASSED_ExecutionStatus) {
+			passed++
+		}
+	}
+	return float64(passed) / float64(len(executions)), nil
+}
+
+// medianDurationSince returns median duration in seconds of finished executions within the window,
+// it's 0 when there are none
+func (m *HistoryMachine) medianDurationSince(args ...expressionstcl.StaticValue) (float64, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("expects 1 argument, %d provided", len(args))
+	}
+	str, err := args[0].StringValue()
+	if err != nil {
+		return 0, fmt.Errorf("argument should be a duration: %w", err)
+	}
+	window, err := time.ParseDuration(str)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("argument should be a positive duration, %s provided", args[0].String())
+	}
+
+	executions, err := m.executions(window)
+	if err != nil {
+		return 0, err
+	}
+	durations := make([]float64, 0, len(executions))
+	for _, execution := range executions {
+		if isFinishedExecution(execution) {
+			durations = append(durations, resultDuration(execution).Seconds())
+		}
+	}
+	if len(durations) == 0 {
+		return 0, nil
+	}
+	sort.Float64s(durations)
+	middle := len(durations) / 2
+	if len(durations)%2 == 0 {
+		return (durations[middle-1] + durations[middle]) / 2, nil
+	}
+	return durations[middle], nil
+}
+
+func executionStatus(execution testkube.Execution) string {
+	if execution.ExecutionResult == nil || execution.ExecutionResult.Status == nil {
+		return ""
+	}
+	return string(*execution.ExecutionResult.Status)
+}
+
+func isFinishedExecution(execution testkube.Execution) bool {
+	return statusRank(testkube.ExecutionStatus(executionStatus(execution))) == terminalStatusRank
+}
+
+// EvaluateCondition evaluates boolean condition, e.g. whether the triggered test should run,
+// optional machines like HistoryMachine are consulted before the execution metadata
+func EvaluateCondition(expr string, execution testkube.Execution, machines ...expressionstcl.Machine) (bool, error) {
+	machines = append(machines, NewExecutionMachine(execution))
+	value, err := expressionstcl.EvalExpression(strings.TrimSpace(expr), machines...)
+	if err != nil {
+		return false, err
+	}
+	return value.BoolValue()
+}
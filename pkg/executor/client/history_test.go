@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// memoryHistory keeps executions in memory and records the queries
+type memoryHistory struct {
+	mu         sync.Mutex
+	executions []testkube.Execution
+	queries    []time.Time
+	limits     []int
+	err        error
+}
+
+func (h *memoryHistory) Add(testName string, status testkube.ExecutionStatus, start time.Time, duration time.Duration) {
+	h.executions = append(h.executions, testkube.Execution{
+		TestName:        testName,
+		StartTime:       start,
+		EndTime:         start.Add(duration),
+		DurationMs:      int32(duration.Milliseconds()),
+		ExecutionResult: &testkube.ExecutionResult{Status: testkube.StatusPtr(status)},
+	})
+}
+
+func (h *memoryHistory) ListExecutions(ctx context.Context, testName string, since time.Time, limit int) ([]testkube.Execution, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.queries = append(h.queries, since)
+	h.limits = append(h.limits, limit)
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	// executions are added oldest first
+	result := make([]testkube.Execution, 0)
+	for i := len(h.executions) - 1; i >= 0 && len(result) < limit; i-- {
+		if h.executions[i].TestName == testName && !h.executions[i].StartTime.Before(since) {
+			result = append(result, h.executions[i])
+		}
+	}
+	return result, nil
+}
+
+func (h *memoryHistory) Queries() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.queries)
+}
+
+func newTestHistory(now time.Time) *memoryHistory {
+	history := &memoryHistory{}
+	history.Add("other", testkube.FAILED_ExecutionStatus, now.Add(-240*time.Hour), time.Second)
+	history.Add("test", testkube.FAILED_ExecutionStatus, now.Add(-200*time.Hour), 100*time.Second)
+	history.Add("test", testkube.PASSED_ExecutionStatus, now.Add(-100*time.Hour), 10*time.Second)
+	history.Add("test", testkube.FAILED_ExecutionStatus, now.Add(-50*time.Hour), 20*time.Second)
+	history.Add("test", testkube.PASSED_ExecutionStatus, now.Add(-10*time.Hour), 12*time.Second)
+	history.Add("test", testkube.PASSED_ExecutionStatus, now.Add(-5*time.Hour), 14*time.Second)
+	history.Add("test", testkube.RUNNING_ExecutionStatus, now.Add(-time.Minute), 0)
+	return history
+}
+
+func TestHistoryMachine_Accessors(t *testing.T) {
+	now := time.Now()
+	history := newTestHistory(now)
+	machine := NewHistoryMachine(context.Background(), history, "test", newFakeClock(now))
+	execution := testkube.Execution{TestName: "test"}
+
+	tests := map[string]bool{
+		`history.lastStatus == "running"`:                                   true,
+		`history.count == 6`:                                                true,
+		`history.statusesLast(3) == ["passed", "passed", "failed"]`:         true,
+		`history.passRateLast(2) == 1`:                                      true,
+		`history.passRateLast(4) == 0.75`:                                   true,
+		`history.passRateLast(10) == 0.6`:                                   true,
+		`history.medianDurationSince("168h") == 13`:                         true,
+		`history.medianDurationSince("72h") == 14`:                          true,
+		`history.medianDurationSince("1m") == 0`:                            true,
+		`history.medianDurationSince("168h") > 1.2 * 10`:                    true,
+		`len(history.statusesLast(3)) == 3 && history.passRateLast(3) == 1`: false,
+	}
+	for expr, expected := range tests {
+		result, err := EvaluateCondition(expr, execution, machine)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, result, expr)
+	}
+
+	// the results are cached per window for the machine lifetime
+	assert.Equal(t, 4, history.Queries())
+	assert.ElementsMatch(t, []time.Time{{}, now.Add(-168 * time.Hour), now.Add(-72 * time.Hour), now.Add(-time.Minute)}, history.queries)
+}
+
+func TestHistoryMachine_Lazy(t *testing.T) {
+	history := newTestHistory(time.Now())
+	machine := NewHistoryMachine(context.Background(), history, "test", newFakeClock(time.Now()))
+
+	result, err := EvaluateCondition(`test.name == "test"`, testkube.Execution{TestName: "test"}, machine)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Equal(t, 0, history.Queries())
+
+	// the history is not needed when the condition is decided before
+	result, err = EvaluateCondition(`false && history.lastStatus == "passed"`, testkube.Execution{}, machine)
+	require.NoError(t, err)
+	assert.False(t, result)
+	assert.Equal(t, 0, history.Queries())
+}
+
+func TestHistoryMachine_Limit(t *testing.T) {
+	history := newTestHistory(time.Now())
+	machine := NewHistoryMachine(context.Background(), history, "test", newFakeClock(time.Now())).WithLimit(3)
+
+	result, err := EvaluateCondition(`history.count == 3 && history.statusesLast(3) == ["passed", "passed"]`, testkube.Execution{}, machine)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Equal(t, []int{3}, history.limits)
+
+	_, err = EvaluateCondition(`history.passRateLast(4) == 1`, testkube.Execution{}, machine)
+	assert.ErrorContains(t, err, "history.passRateLast() function: 4 exceeds the history limit of 3")
+}
+
+func TestHistoryMachine_Errors(t *testing.T) {
+	history := newTestHistory(time.Now())
+	history.err = errors.New("store unavailable")
+	machine := NewHistoryMachine(context.Background(), history, "test", newFakeClock(time.Now()))
+
+	_, err := EvaluateCondition(`history.lastStatus == "passed"`, testkube.Execution{}, machine)
+	assert.ErrorContains(t, err, "listing executions of test test: store unavailable")
+
+	tests := map[string]string{
+		`history.passRateLast()`:                "expects 1 argument, 0 provided",
+		`history.passRateLast(0)`:               "argument should be a positive integer, 0 provided",
+		`history.medianDurationSince("a week")`: `argument should be a positive duration, "a week" provided`,
+		`history.medianDurationSince("-1h")`:    `argument should be a positive duration, "-1h" provided`,
+	}
+	for expr, message := range tests {
+		_, err = EvaluateCondition(expr+` == 1`, testkube.Execution{}, machine)
+		assert.ErrorContains(t, err, message, expr)
+	}
+	assert.Equal(t, 1, history.Queries())
+}
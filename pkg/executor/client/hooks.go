@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// BeforeExecuteHook is called before execution is launched, it may change execute options,
+// returned error prevents the launch and fails the execution
+type BeforeExecuteHook func(ctx context.Context, options *ExecuteOptions) error
+
+// AfterExecuteHook is called once execution reaches terminal state, including abort and timeout,
+// returned error is logged only and doesn't change recorded execution status
+type AfterExecuteHook func(ctx context.Context, options ExecuteOptions, result testkube.ExecutionResult) error
+
+// HookedExecutor calls registered hooks around executions, hooks run in registration order
+// and their panics are recovered, so they can't break execution watching
+type HookedExecutor struct {
+	Executor
+	executions ExecutionGetter
+	watcher    *Watcher
+	log        *zap.SugaredLogger
+
+	mu     sync.Mutex
+	before []BeforeExecuteHook
+	after  []AfterExecuteHook
+	// running keeps options of launched executions until after hooks are called for them
+	running map[string]ExecuteOptions
+}
+
+// NewHookedExecutor creates new hooked executor, executions are polled by watcher to find out when they finish
+func NewHookedExecutor(log *zap.SugaredLogger, executor Executor, executions ExecutionGetter, watcher *Watcher) *HookedExecutor {
+	return &HookedExecutor{
+		Executor:   executor,
+		executions: executions,
+		watcher:    watcher,
+		log:        log,
+		running:    make(map[string]ExecuteOptions),
+	}
+}
+
+// BeforeExecute registers hook called before execution is launched
+func (e *HookedExecutor) BeforeExecute(hook BeforeExecuteHook) *HookedExecutor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.before = append(e.before, hook)
+	return e
+}
+
+// AfterExecute registers hook called when execution reaches terminal state
+func (e *HookedExecutor) AfterExecute(hook AfterExecuteHook) *HookedExecutor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.after = append(e.after, hook)
+	return e
+}
+
+// Execute calls before hooks and launches execution, after hooks are called when it finishes
+func (e *HookedExecutor) Execute(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	e.mu.Lock()
+	before := e.before
+	e.mu.Unlock()
+
+	for i, hook := range before {
+		if err := e.callBefore(ctx, hook, &options); err != nil {
+			err = fmt.Errorf("before execute hook %d: %w", i, err)
+			result := testkube.NewRunningExecutionResult().Err(err)
+			execution.ExecutionResult = result
+			return result, err
+		}
+	}
+
+	e.mu.Lock()
+	e.running[execution.Id] = options
+	e.mu.Unlock()
+
+	result, err := e.Executor.Execute(ctx, execution, options)
+	if err != nil {
+		failed := testkube.NewRunningExecutionResult()
+		if result != nil {
+			failed = result.GetDeepCopy()
+		}
+
+		e.finish(ctx, execution.Id, *failed.Err(err))
+		return result, err
+	}
+
+	if result != nil && result.IsCompleted() {
+		e.finish(ctx, execution.Id, *result)
+		return result, nil
+	}
+
+	go e.watch(context.WithoutCancel(ctx), execution.Id)
+	return result, nil
+}
+
+// DryRun renders execution with the wrapped executor, hooks aren't called as nothing is launched
+func (e *HookedExecutor) DryRun(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*DryRunResult, error) {
+	return DryRun(ctx, e.Executor, execution, options)
+}
+
+// Abort aborts execution and calls after hooks when the execution is aborted, or it has finished already
+func (e *HookedExecutor) Abort(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
+	result, err := e.Executor.Abort(ctx, execution)
+	if (err == nil || IsAlreadyFinished(err)) && result != nil && result.IsCompleted() {
+		e.finish(ctx, execution.Id, *result)
+	}
+
+	return result, err
+}
+
+// watch polls execution until it reaches terminal state or after hooks were called by abort
+func (e *HookedExecutor) watch(ctx context.Context, id string) {
+	err := e.watcher.Poll(ctx, func(ctx context.Context) (done, changed bool, err error) {
+		if !e.isRunning(id) {
+			return true, false, nil
+		}
+
+		current, err := e.executions.Get(ctx, id)
+		if err != nil {
+			e.log.Warnw("getting execution for after execute hooks", "executionId", id, "error", err)
+			return false, false, nil
+		}
+
+		if result, ok := FinishedResult(current); ok {
+			e.finish(ctx, id, *result)
+			return true, true, nil
+		}
+
+		return false, false, nil
+	})
+	if err != nil {
+		e.log.Errorw("watching execution for after execute hooks", "executionId", id, "error", err)
+	}
+}
+
+func (e *HookedExecutor) isRunning(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_, ok := e.running[id]
+	return ok
+}
+
+// finish calls after hooks once per execution
+func (e *HookedExecutor) finish(ctx context.Context, id string, result testkube.ExecutionResult) {
+	e.mu.Lock()
+	options, ok := e.running[id]
+	delete(e.running, id)
+	after := e.after
+	e.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	for i, hook := range after {
+		if err := e.callAfter(ctx, hook, options, result); err != nil {
+			e.log.Errorw("after execute hook failed", "executionId", id, "hook", i, "error", err)
+		}
+	}
+}
+
+func (e *HookedExecutor) callBefore(ctx context.Context, hook BeforeExecuteHook, options *ExecuteOptions) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return hook(ctx, options)
+}
+
+func (e *HookedExecutor) callAfter(ctx context.Context, hook AfterExecuteHook, options ExecuteOptions, result testkube.ExecutionResult) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return hook(ctx, options, result)
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func newTestHookedExecutor(fake *FakeExecutor) *HookedExecutor {
+	return NewHookedExecutor(zap.NewNop().Sugar(), fake, fake, NewWatcher(zap.NewNop().Sugar(), DefaultWatchOptions(), &recordingClock{}))
+}
+
+// recordAfter registers after hook sending results of finished executions to returned channel
+func recordAfter(executor *HookedExecutor) <-chan testkube.ExecutionResult {
+	results := make(chan testkube.ExecutionResult, 10)
+	executor.AfterExecute(func(ctx context.Context, options ExecuteOptions, result testkube.ExecutionResult) error {
+		results <- result
+		return nil
+	})
+	return results
+}
+
+func receiveResult(t *testing.T, results <-chan testkube.ExecutionResult) testkube.ExecutionResult {
+	select {
+	case result := <-results:
+		return result
+	case <-time.After(time.Second):
+		t.Fatal("after execute hook wasn't called")
+		return testkube.ExecutionResult{}
+	}
+}
+
+func TestHookedExecutor_BeforeExecuteMutatesOptions(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("test")
+	var order []string
+
+	_, err := newTestHookedExecutor(fake).
+		BeforeExecute(func(ctx context.Context, options *ExecuteOptions) error {
+			order = append(order, "allocate")
+			options.Labels = map[string]string{"tenant": "tenant-1"}
+			return nil
+		}).
+		BeforeExecute(func(ctx context.Context, options *ExecuteOptions) error {
+			order = append(order, "annotate")
+			options.Labels["annotated"] = options.Labels["tenant"]
+			return nil
+		}).
+		Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, ExecuteOptions{TestName: "test", Sync: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"allocate", "annotate"}, order)
+	assert.Equal(t, map[string]string{"tenant": "tenant-1", "annotated": "tenant-1"}, fake.ExecuteOptions()[0].Labels)
+}
+
+func TestHookedExecutor_BeforeExecuteErrorAbortsLaunch(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	executor := newTestHookedExecutor(fake)
+	results := recordAfter(executor)
+	secondCalled := false
+
+	execution := &testkube.Execution{Id: "exec-1"}
+	result, err := executor.
+		BeforeExecute(func(ctx context.Context, options *ExecuteOptions) error {
+			return errors.New("no tenant available")
+		}).
+		BeforeExecute(func(ctx context.Context, options *ExecuteOptions) error {
+			secondCalled = true
+			return nil
+		}).
+		Execute(context.Background(), execution, ExecuteOptions{TestName: "test"})
+
+	assert.EqualError(t, err, "before execute hook 0: no tenant available")
+	assert.True(t, result.IsFailed())
+	assert.Same(t, result, execution.ExecutionResult)
+	assert.False(t, secondCalled)
+	assert.Empty(t, fake.ExecuteOptions())
+	assert.Empty(t, results)
+}
+
+func TestHookedExecutor_BeforeExecutePanic(t *testing.T) {
+	fake := NewFakeExecutor(t)
+
+	_, err := newTestHookedExecutor(fake).
+		BeforeExecute(func(ctx context.Context, options *ExecuteOptions) error {
+			panic("tenant pool is nil")
+		}).
+		Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, ExecuteOptions{TestName: "test"})
+
+	assert.EqualError(t, err, "before execute hook 0: panic: tenant pool is nil")
+}
+
+func TestHookedExecutor_AfterExecuteTerminalPaths(t *testing.T) {
+	running := testkube.RUNNING_ExecutionStatus
+	tests := map[string]struct {
+		script func(execution *FakeExecution)
+		sync   bool
+		abort  bool
+		status testkube.ExecutionStatus
+	}{
+		"passed": {
+			script: func(execution *FakeExecution) { execution.WithStatuses(running, testkube.PASSED_ExecutionStatus) },
+			status: testkube.PASSED_ExecutionStatus,
+		},
+		"failed": {
+			script: func(execution *FakeExecution) { execution.WithStatuses(running, testkube.FAILED_ExecutionStatus) },
+			status: testkube.FAILED_ExecutionStatus,
+		},
+		"timeout": {
+			script: func(execution *FakeExecution) { execution.WithStatuses(running, testkube.TIMEOUT_ExecutionStatus) },
+			status: testkube.TIMEOUT_ExecutionStatus,
+		},
+		"sync": {
+			script: func(execution *FakeExecution) { execution.WithStatuses(testkube.PASSED_ExecutionStatus) },
+			sync:   true,
+			status: testkube.PASSED_ExecutionStatus,
+		},
+		"launch error": {
+			script: func(execution *FakeExecution) { execution.WithError(errors.New("pod can't be created")) },
+			status: testkube.FAILED_ExecutionStatus,
+		},
+		"abort": {
+			script: func(execution *FakeExecution) { execution.WithStatuses(running) },
+			abort:  true,
+			status: testkube.ABORTED_ExecutionStatus,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := NewFakeExecutor(t)
+			tt.script(fake.ExpectExecution("test"))
+			executor := newTestHookedExecutor(fake)
+			results := recordAfter(executor)
+
+			execution := &testkube.Execution{Id: "exec-1"}
+			_, _ = executor.Execute(context.Background(), execution, ExecuteOptions{TestName: "test", Sync: tt.sync})
+			if tt.abort {
+				_, err := executor.Abort(context.Background(), execution)
+				require.NoError(t, err)
+			}
+
+			result := receiveResult(t, results)
+			assert.Equal(t, tt.status, *result.Status)
+			assert.Empty(t, results, "after execute hooks should be called once")
+		})
+	}
+}
+
+func TestHookedExecutor_AfterExecuteFailuresDontAffectResult(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("test")
+	executor := newTestHookedExecutor(fake).
+		AfterExecute(func(ctx context.Context, options ExecuteOptions, result testkube.ExecutionResult) error {
+			panic("release failed")
+		}).
+		AfterExecute(func(ctx context.Context, options ExecuteOptions, result testkube.ExecutionResult) error {
+			return errors.New("annotation failed")
+		})
+	results := recordAfter(executor)
+
+	result, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1"}, ExecuteOptions{TestName: "test", Sync: true})
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsPassed())
+	assert.Equal(t, testkube.PASSED_ExecutionStatus, *receiveResult(t, results).Status)
+}
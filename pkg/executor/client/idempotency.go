@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+const (
+	// DefaultIdempotencyWindow is how long finished execution deduplicates submissions with the same key
+	DefaultIdempotencyWindow = 10 * time.Minute
+	// maxIdempotencyClaimAttempts limits retries when expired claim is taken over concurrently
+	maxIdempotencyClaimAttempts = 3
+)
+
+// IdempotencyStore records which execution holds an idempotency key, all methods have to be atomic,
+// so concurrent submissions with the same key can't both claim it
+type IdempotencyStore interface {
+	// Claim records execution for the key unless the key is held already, returns execution holding the key
+	Claim(ctx context.Context, key, executionID string) (holder string, claimed bool, err error)
+	// Replace moves the key from previous to new execution, returns false when previous execution doesn't hold it anymore
+	Replace(ctx context.Context, key, previousID, executionID string) (bool, error)
+	// Release removes the key when it's held by the execution
+	Release(ctx context.Context, key, executionID string) error
+}
+
+// MemoryIdempotencyStore keeps idempotency keys in memory
+type MemoryIdempotencyStore struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+// NewMemoryIdempotencyStore creates new in-memory idempotency store
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{keys: make(map[string]string)}
+}
+
+// Claim records execution for the key unless the key is held already
+func (s *MemoryIdempotencyStore) Claim(ctx context.Context, key, executionID string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if holder, ok := s.keys[key]; ok {
+		return holder, false, nil
+	}
+
+	s.keys[key] = executionID
+	return executionID, true, nil
+}
+
+// Replace moves the key from previous to new execution
+func (s *MemoryIdempotencyStore) Replace(ctx context.Context, key, previousID, executionID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys[key] != previousID {
+		return false, nil
+	}
+
+	s.keys[key] = executionID
+	return true, nil
+}
+
+// Release removes the key when it's held by the execution
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key, executionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys[key] == executionID {
+		delete(s.keys, key)
+	}
+
+	return nil
+}
+
+// IdempotentResult is a result of idempotent submission
+type IdempotentResult struct {
+	// Execution is the started execution or the existing one holding the key
+	Execution *testkube.Execution
+	// Result is the execution result
+	Result *testkube.ExecutionResult
+	// Deduplicated is true when existing execution was returned instead of starting new one
+	Deduplicated bool
+}
+
+// IdempotentExecutor deduplicates executions with the same execute options idempotency key,
+// running execution or execution finished within the window is returned instead of starting new one
+type IdempotentExecutor struct {
+	Executor
+	executions ExecutionGetter
+	store      IdempotencyStore
+	clock      Clock
+	window     time.Duration
+}
+
+// NewIdempotentExecutor creates new idempotent executor, executions are used to look up execution holding the key
+func NewIdempotentExecutor(executor Executor, executions ExecutionGetter, store IdempotencyStore, clock Clock) *IdempotentExecutor {
+	if clock == nil {
+		clock = NewRealClock()
+	}
+
+	return &IdempotentExecutor{
+		Executor:   executor,
+		executions: executions,
+		store:      store,
+		clock:      clock,
+		window:     DefaultIdempotencyWindow,
+	}
+}
+
+// WithWindow sets how long finished execution deduplicates submissions with the same key
+func (e *IdempotentExecutor) WithWindow(window time.Duration) *IdempotentExecutor {
+	e.window = window
+	return e
+}
+
+// Execute starts execution or fills the execution with existing one holding the same idempotency key
+func (e *IdempotentExecutor) Execute(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	submitted, err := e.Submit(ctx, execution, options)
+	if submitted == nil {
+		result := testkube.NewRunningExecutionResult().Err(err)
+		execution.ExecutionResult = result
+		return result, err
+	}
+
+	if submitted.Deduplicated {
+		*execution = *submitted.Execution
+	}
+
+	return submitted.Result, err
+}
+
+// DryRun renders execution with the wrapped executor, idempotency key isn't claimed
+func (e *IdempotentExecutor) DryRun(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*DryRunResult, error) {
+	return DryRun(ctx, e.Executor, execution, options)
+}
+
+// Submit starts execution unless other execution holds the same idempotency key, result tells which one happened
+func (e *IdempotentExecutor) Submit(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*IdempotentResult, error) {
+	key := options.IdempotencyKey
+	if key == "" {
+		return e.start(ctx, execution, options)
+	}
+
+	for attempt := 0; attempt < maxIdempotencyClaimAttempts; attempt++ {
+		holder, claimed, err := e.store.Claim(ctx, key, execution.Id)
+		if err != nil {
+			return nil, fmt.Errorf("claiming idempotency key %s: %w", key, err)
+		}
+
+		if claimed || holder == execution.Id {
+			return e.startClaimed(ctx, execution, options)
+		}
+
+		existing, err := e.executions.Get(ctx, holder)
+		if err != nil {
+			return nil, fmt.Errorf("getting execution %s holding idempotency key %s: %w", holder, key, err)
+		}
+
+		if !e.expired(existing) {
+			return &IdempotentResult{Execution: &existing, Result: existing.ExecutionResult, Deduplicated: true}, nil
+		}
+
+		replaced, err := e.store.Replace(ctx, key, holder, execution.Id)
+		if err != nil {
+			return nil, fmt.Errorf("replacing idempotency key %s: %w", key, err)
+		}
+
+		if replaced {
+			return e.startClaimed(ctx, execution, options)
+		}
+	}
+
+	return nil, fmt.Errorf("idempotency key %s is contended, try again later", key)
+}
+
+// expired checks if execution finished before the deduplication window
+func (e *IdempotentExecutor) expired(execution testkube.Execution) bool {
+	if _, ok := FinishedResult(execution); !ok || execution.EndTime.IsZero() {
+		return false
+	}
+
+	return e.clock.Now().Sub(execution.EndTime) > e.window
+}
+
+// startClaimed starts execution holding the key, the key is released when execution fails to start
+func (e *IdempotentExecutor) startClaimed(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*IdempotentResult, error) {
+	submitted, err := e.start(ctx, execution, options)
+	if err != nil {
+		if rErr := e.store.Release(ctx, options.IdempotencyKey, execution.Id); rErr != nil {
+			return submitted, fmt.Errorf("%w, releasing idempotency key: %v", err, rErr)
+		}
+	}
+
+	return submitted, err
+}
+
+func (e *IdempotentExecutor) start(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*IdempotentResult, error) {
+	result, err := e.Executor.Execute(ctx, execution, options)
+	return &IdempotentResult{Execution: execution, Result: result}, err
+}
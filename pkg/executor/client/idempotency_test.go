@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// storedExecutions is an execution getter returning executions stored by test
+type storedExecutions struct {
+	mu         sync.Mutex
+	executions map[string]testkube.Execution
+}
+
+func (s *storedExecutions) Get(ctx context.Context, id string) (testkube.Execution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	execution, ok := s.executions[id]
+	if !ok {
+		return testkube.Execution{}, fmt.Errorf("execution %s not found", id)
+	}
+	return execution, nil
+}
+
+func (s *storedExecutions) store(execution testkube.Execution) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executions[execution.Id] = execution
+}
+
+// expectStoringExecute expects executions which are stored as running when started
+func expectStoringExecute(mockExecutor *MockExecutor, executions *storedExecutions, times int) {
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Times(times).
+		DoAndReturn(func(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+			execution.ExecutionResult = testkube.NewRunningExecutionResult()
+			executions.store(*execution)
+			return execution.ExecutionResult, nil
+		})
+}
+
+func TestIdempotentExecutor_ConcurrentSubmissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	executions := &storedExecutions{executions: map[string]testkube.Execution{}}
+	expectStoringExecute(mockExecutor, executions, 1)
+	executor := NewIdempotentExecutor(mockExecutor, executions, NewMemoryIdempotencyStore(), nil)
+
+	const submissions = 20
+	results := make([]*IdempotentResult, submissions)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < submissions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			execution := &testkube.Execution{Id: fmt.Sprintf("exec-%d", i)}
+			var err error
+			results[i], err = executor.Submit(context.Background(), execution, ExecuteOptions{TestName: "test", IdempotencyKey: "delivery-1"})
+			assert.NoError(t, err)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	var created []string
+	for _, result := range results {
+		if !result.Deduplicated {
+			created = append(created, result.Execution.Id)
+		}
+	}
+	require.Len(t, created, 1)
+	for _, result := range results {
+		assert.Equal(t, created[0], result.Execution.Id)
+		assert.True(t, result.Result.IsRunning())
+	}
+}
+
+func TestIdempotentExecutor_Execute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	executions := &storedExecutions{executions: map[string]testkube.Execution{}}
+	expectStoringExecute(mockExecutor, executions, 1)
+	executor := NewIdempotentExecutor(mockExecutor, executions, NewMemoryIdempotencyStore(), nil)
+	options := ExecuteOptions{TestName: "test", IdempotencyKey: "delivery-1"}
+
+	_, err := executor.Execute(context.Background(), &testkube.Execution{Id: "exec-1", TestName: "test"}, options)
+	require.NoError(t, err)
+
+	redelivery := &testkube.Execution{Id: "exec-2", TestName: "test"}
+	result, err := executor.Execute(context.Background(), redelivery, options)
+
+	require.NoError(t, err)
+	assert.True(t, result.IsRunning())
+	assert.Equal(t, "exec-1", redelivery.Id)
+}
+
+func TestIdempotentExecutor_Window(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	executions := &storedExecutions{executions: map[string]testkube.Execution{}}
+	expectStoringExecute(mockExecutor, executions, 2)
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	executor := NewIdempotentExecutor(mockExecutor, executions, NewMemoryIdempotencyStore(), clock).WithWindow(time.Minute)
+	options := ExecuteOptions{TestName: "test", IdempotencyKey: "delivery-1"}
+
+	first, err := executor.Submit(context.Background(), &testkube.Execution{Id: "exec-1"}, options)
+	require.NoError(t, err)
+	assert.False(t, first.Deduplicated)
+
+	finished := *first.Execution
+	finished.ExecutionResult = &testkube.ExecutionResult{Status: testkube.ExecutionStatusPassed}
+	finished.EndTime = clock.Now()
+	executions.store(finished)
+
+	clock.Advance(time.Minute)
+	recent, err := executor.Submit(context.Background(), &testkube.Execution{Id: "exec-2"}, options)
+	require.NoError(t, err)
+	assert.True(t, recent.Deduplicated)
+	assert.Equal(t, "exec-1", recent.Execution.Id)
+	assert.True(t, recent.Result.IsPassed())
+
+	clock.Advance(time.Second)
+	expired, err := executor.Submit(context.Background(), &testkube.Execution{Id: "exec-3"}, options)
+	require.NoError(t, err)
+	assert.False(t, expired.Deduplicated)
+	assert.Equal(t, "exec-3", expired.Execution.Id)
+
+	again, err := executor.Submit(context.Background(), &testkube.Execution{Id: "exec-4"}, options)
+	require.NoError(t, err)
+	assert.True(t, again.Deduplicated)
+	assert.Equal(t, "exec-3", again.Execution.Id)
+}
+
+func TestIdempotentExecutor_FailedStartReleasesKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	executions := &storedExecutions{executions: map[string]testkube.Execution{}}
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(testkube.NewRunningExecutionResult().Err(errors.New("namespace not found")), errors.New("namespace not found"))
+	expectStoringExecute(mockExecutor, executions, 1)
+	executor := NewIdempotentExecutor(mockExecutor, executions, NewMemoryIdempotencyStore(), nil)
+	options := ExecuteOptions{TestName: "test", IdempotencyKey: "delivery-1"}
+
+	failed, err := executor.Submit(context.Background(), &testkube.Execution{Id: "exec-1"}, options)
+	assert.EqualError(t, err, "namespace not found")
+	assert.True(t, failed.Result.IsFailed())
+
+	retried, err := executor.Submit(context.Background(), &testkube.Execution{Id: "exec-2"}, options)
+	require.NoError(t, err)
+	assert.False(t, retried.Deduplicated)
+}
+
+func TestIdempotentExecutor_WithoutKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	executions := &storedExecutions{executions: map[string]testkube.Execution{}}
+	expectStoringExecute(mockExecutor, executions, 2)
+	executor := NewIdempotentExecutor(mockExecutor, executions, NewMemoryIdempotencyStore(), nil)
+
+	for _, id := range []string{"exec-1", "exec-2"} {
+		result, err := executor.Submit(context.Background(), &testkube.Execution{Id: id}, ExecuteOptions{TestName: "test"})
+		require.NoError(t, err)
+		assert.False(t, result.Deduplicated)
+	}
+}
@@ -15,7 +15,8 @@ type ResultEvent struct {
 	Error  error
 }
 
-// Executor abstraction to implement new executors
+// Executor abstraction to implement new executors, tests can use generated MockExecutor
+// or FakeExecutor which scripts status transitions and logs in memory
 //
 //go:generate mockgen -destination=./mock_executor.go -package=client "github.com/kubeshop/testkube/pkg/executor/client" Executor
 type Executor interface {
@@ -23,10 +24,15 @@ type Executor interface {
 	// execution is started asynchronously client can check later for results
 	Execute(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (result *testkube.ExecutionResult, err error)
 
-	// Abort aborts pending execution, do nothing when there is no pending execution
+	// Abort aborts pending execution, do nothing when there is no pending execution;
+	// already finished execution returns its terminal result with AlreadyFinishedError
 	Abort(ctx context.Context, execution *testkube.Execution) (result *testkube.ExecutionResult, err error)
 
+	// Logs returns execution logs
 	Logs(ctx context.Context, id, namespace string) (logs chan output.Output, err error)
+
+	// Capabilities returns execute options supported by the executor
+	Capabilities() Capabilities
 }
 
 // HTTPClient interface for getting REST based requests
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -26,7 +27,6 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/kustomize/kyaml/yaml/merge2"
@@ -43,6 +43,7 @@ import (
 	"github.com/kubeshop/testkube/pkg/executor/agent"
 	"github.com/kubeshop/testkube/pkg/executor/env"
 	"github.com/kubeshop/testkube/pkg/executor/output"
+	"github.com/kubeshop/testkube/pkg/executor/scraper"
 	"github.com/kubeshop/testkube/pkg/log"
 	logsclient "github.com/kubeshop/testkube/pkg/logs/client"
 	"github.com/kubeshop/testkube/pkg/logs/events"
@@ -66,12 +67,7 @@ const (
 	// SecretSource is a source secret
 	SecretSource = "source-secrets"
 
-	pollTimeout  = 24 * time.Hour
-	pollInterval = 200 * time.Millisecond
-	// pollJobStatus is interval for checking if job timeout occurred
-	pollJobStatus = 1 * time.Second
-	// timeoutIndicator is string that is added to job logs when timeout occurs
-	timeoutIndicator = "DeadlineExceeded"
+	pollTimeout = 24 * time.Hour
 )
 
 // NewJobExecutor creates new job executor
@@ -123,6 +119,9 @@ func NewJobExecutor(
 		debug:                debug,
 		logsStream:           logsStream,
 		features:             features,
+		watchOptions:         DefaultWatchOptions(),
+		aborts:               NewAbortRegistry(),
+		resultParsers:        DefaultResultParsers(),
 	}, nil
 }
 
@@ -154,6 +153,10 @@ type JobExecutor struct {
 	debug                bool
 	logsStream           logsclient.Stream
 	features             featureflags.FeatureFlags
+	watchOptions         WatchOptions
+	aborts               *AbortRegistry
+	priorityClasses      PriorityClasses
+	resultParsers        ResultParsers
 }
 
 type JobOptions struct {
@@ -181,9 +184,12 @@ type JobOptions struct {
 	EnvConfigMaps         []testkube.EnvReference
 	EnvSecrets            []testkube.EnvReference
 	Labels                map[string]string
+	Annotations           map[string]string
 	Registry              string
 	ClusterID             string
 	ArtifactRequest       *testkube.ArtifactRequest
+	ArtifactCollection    *scraper.ArtifactRequest
+	Priority              *Priority
 	WorkingDir            string
 	ExecutionNumber       int32
 	ContextType           string
@@ -196,6 +202,19 @@ type JobOptions struct {
 	Features              featureflags.FeatureFlags
 	PvcTemplate           string
 	PvcTemplateExtensions string
+	Resources             *Resources
+	NodeSelector          map[string]string
+	Tolerations           []corev1.Toleration
+	Affinity              *corev1.Affinity
+	Services              []Service
+	ExecutionEnvs         map[string]string
+	SecretRefs            []SecretRef
+	ConfigMapRefs         []ConfigMapRef
+}
+
+// Capabilities returns execute options supported by job executor, it supports all of them
+func (c *JobExecutor) Capabilities() Capabilities {
+	return FullCapabilities
 }
 
 // Logs returns job logs stream channel using kubernetes api
@@ -232,6 +251,20 @@ func (c *JobExecutor) Execute(ctx context.Context, execution *testkube.Execution
 	result = testkube.NewRunningExecutionResult()
 	execution.ExecutionResult = result
 
+	normalized := options.Normalize()
+	if fields := normalized.Diff(options); len(fields) > 0 && c.Log != nil {
+		c.Log.Debugw("normalized execute options", "executionId", execution.Id, "fields", fields)
+	}
+	options = normalized
+
+	if options, err = c.prepare(ctx, *execution, options); err != nil {
+		return result.Err(err), err
+	}
+
+	if options.Sharding.IsSharded() {
+		return c.executeSharded(ctx, execution, options)
+	}
+
 	err = c.CreateJob(ctx, *execution, options)
 	if err != nil {
 		if cErr := c.cleanPVCVolume(ctx, execution); cErr != nil {
@@ -265,12 +298,14 @@ func (c *JobExecutor) Execute(ctx context.Context, execution *testkube.Execution
 		if pod.Status.Phase != corev1.PodRunning && pod.Labels["job-name"] == execution.Id {
 			// for sync block and complete
 			if options.Sync {
-				return c.updateResultsFromPod(ctx, pod, l, execution, options.Request.NegativeTest)
+				return c.updateResultsFromPod(ctx, pod, l, execution, options)
 			}
 
-			// for async start goroutine and return in progress job
+			// for async start goroutine and return in progress job, abort stops watching the pod
+			watchCtx, release := c.aborts.Watch(ctx, execution.Id)
 			go func(pod corev1.Pod) {
-				_, err := c.updateResultsFromPod(ctx, pod, l, execution, options.Request.NegativeTest)
+				defer release()
+				_, err := c.updateResultsFromPod(watchCtx, pod, l, execution, options)
 				if err != nil {
 					l.Errorw("update results from jobs pod error", "error", err)
 				}
@@ -286,82 +321,193 @@ func (c *JobExecutor) Execute(ctx context.Context, execution *testkube.Execution
 }
 
 func (c *JobExecutor) MonitorJobForTimeout(ctx context.Context, jobName, namespace string) {
-	ticker := time.NewTicker(pollJobStatus)
 	l := c.Log.With("jobName", jobName)
-	for {
-		select {
-		case <-ctx.Done():
-			l.Infow("context done, stopping job timeout monitor")
+	if c.watchOptions.UseWatch {
+		if c.watchJobForTimeout(ctx, l, jobName, namespace) {
 			return
-		case <-ticker.C:
-			jobs, err := c.ClientSet.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + jobName})
-			if err != nil {
-				l.Errorw("could not get jobs", "error", err)
-				return
-			}
-			if jobs == nil || len(jobs.Items) == 0 {
-				return
-			}
+		}
+		l.Infow("job watch broken, polling job state")
+	}
 
-			job := jobs.Items[0]
+	var lastActive int32
+	err := NewWatcher(l, c.watchOptions, nil).Poll(ctx, func(ctx context.Context) (done, changed bool, err error) {
+		jobs, err := c.ClientSet.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + jobName})
+		if err != nil {
+			l.Errorw("could not get jobs", "error", err)
+			return true, false, nil
+		}
+		if jobs == nil || len(jobs.Items) == 0 {
+			return true, false, nil
+		}
 
-			if job.Status.Succeeded > 0 {
-				l.Debugw("job succeeded", "status", "succeded")
-				return
-			}
+		job := jobs.Items[0]
+		if c.checkJobForTimeout(ctx, l, job) {
+			return true, false, nil
+		}
 
-			if job.Status.Failed > 0 {
-				l.Debugw("job failed")
-				if len(job.Status.Conditions) > 0 {
-					for _, condition := range job.Status.Conditions {
-						l.Infow("job timeout", "condition.reason", condition.Reason)
-						if condition.Reason == timeoutIndicator {
-							c.Timeout(ctx, jobName)
-						}
-					}
-				}
-				return
-			}
+		changed = job.Status.Active != lastActive
+		lastActive = job.Status.Active
+		return false, changed, nil
+	})
+	if err != nil {
+		l.Infow("context done, stopping job timeout monitor")
+	}
+}
 
-			if job.Status.Active > 0 {
-				continue
-			}
+// checkJobForTimeout times out the job which exceeded its deadline, it reports if the job is finished
+func (c *JobExecutor) checkJobForTimeout(ctx context.Context, l *zap.SugaredLogger, job batchv1.Job) bool {
+	if job.Status.Succeeded > 0 {
+		l.Debugw("job succeeded", "status", "succeded")
+		return true
+	}
+
+	if JobDeadlineExceeded(job) {
+		l.Infow("job timeout", "activeDeadlineSeconds", job.Spec.ActiveDeadlineSeconds)
+		c.Timeout(ctx, job.Name)
+		return true
+	}
+
+	if job.Status.Failed > 0 {
+		l.Debugw("job failed")
+		return true
+	}
+
+	return false
+}
+
+// WithWatchOptions sets how often job state is polled
+func (c *JobExecutor) WithWatchOptions(options WatchOptions) *JobExecutor {
+	c.watchOptions = options
+	return c
+}
+
+// podWatcher waits for pods with the watch options intervals, unset options fall back to the default ones
+func (c *JobExecutor) podWatcher() *Watcher {
+	options := c.watchOptions
+	if options.Interval <= 0 {
+		options = DefaultWatchOptions()
+	}
+	return NewWatcher(c.Log, options, nil)
+}
+
+// WithPriorityClasses sets priority classes allowed for executions
+func (c *JobExecutor) WithPriorityClasses(classes PriorityClasses) *JobExecutor {
+	c.priorityClasses = classes
+	return c
+}
+
+// WithResultParsers sets parsers of execution output by output format, they replace the built-in ones
+func (c *JobExecutor) WithResultParsers(parsers ResultParsers) *JobExecutor {
+	c.resultParsers = parsers
+	return c
+}
+
+// DryRun validates execution and renders job and persistent volume claim it would create, nothing is created
+func (c *JobExecutor) DryRun(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*DryRunResult, error) {
+	options, err := c.prepare(ctx, *execution, options)
+	if err != nil {
+		return nil, err
+	}
+
+	shards := []shard{{execution: *execution, options: options}}
+	if options.Sharding.IsSharded() {
+		if shards, err = newShards(*execution, options); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &DryRunResult{}
+	for _, s := range shards {
+		pvcSpec, jobSpec, err := c.renderJob(s.execution, s.options)
+		if err != nil {
+			return nil, err
+		}
+
+		if pvcSpec != nil {
+			result.Objects = append(result.Objects, pvcSpec)
+		}
+		result.Objects = append(result.Objects, jobSpec)
+	}
+
+	return result, nil
+}
+
+// prepare validates execute options against the cluster and resolves execution priority
+func (c *JobExecutor) prepare(ctx context.Context, execution testkube.Execution, options ExecuteOptions) (ExecuteOptions, error) {
+	if err := options.Validate(); err != nil {
+		return options, err
+	}
+
+	if err := ValidateNamespace(ctx, c.ClientSet, execution.TestNamespace); err != nil {
+		return options, err
+	}
+
+	if err := ValidateSecretNamespaces(execution, options); err != nil {
+		return options, err
+	}
+
+	if err := ValidatePodReferences(ctx, c.ClientSet, execution.TestNamespace, options); err != nil {
+		return options, err
+	}
+
+	if err := c.resultParsers.Validate(options.OutputFormat); err != nil {
+		return options, err
+	}
+
+	if options.Priority != nil {
+		priority, err := c.priorityClasses.Resolve(*options.Priority)
+		if err != nil {
+			return options, err
 		}
+		options.Priority = &priority
 	}
+
+	return options, nil
 }
 
 // CreateJob creates new Kubernetes job based on execution and execute options
 func (c *JobExecutor) CreateJob(ctx context.Context, execution testkube.Execution, options ExecuteOptions) error {
-	jobs := c.ClientSet.BatchV1().Jobs(execution.TestNamespace)
-	jobOptions, err := NewJobOptions(c.Log, c.templatesClient, c.images, c.templates,
-		c.serviceAccountNames, c.registry, c.clusterID, c.apiURI, execution, options, c.natsURI, c.debug)
+	pvcSpec, jobSpec, err := c.renderJob(execution, options)
 	if err != nil {
 		return err
 	}
 
-	if jobOptions.ArtifactRequest != nil &&
-		jobOptions.ArtifactRequest.StorageClassName != "" {
-		c.Log.Debug("creating persistent volume claim with options", "options", jobOptions)
+	if pvcSpec != nil {
 		pvcsClient := c.ClientSet.CoreV1().PersistentVolumeClaims(execution.TestNamespace)
-		pvcSpec, err := NewPersistentVolumeClaimSpec(c.Log, NewPVCOptionsFromJobOptions(jobOptions))
-		if err != nil {
+		if _, err = pvcsClient.Create(ctx, pvcSpec, metav1.CreateOptions{}); err != nil {
 			return err
 		}
+	}
 
-		_, err = pvcsClient.Create(ctx, pvcSpec, metav1.CreateOptions{})
-		if err != nil {
-			return err
+	_, err = c.ClientSet.BatchV1().Jobs(execution.TestNamespace).Create(ctx, jobSpec, metav1.CreateOptions{})
+	return err
+}
+
+// renderJob renders job spec and persistent volume claim spec when artifacts need one
+func (c *JobExecutor) renderJob(execution testkube.Execution, options ExecuteOptions) (*corev1.PersistentVolumeClaim, *batchv1.Job, error) {
+	jobOptions, err := NewJobOptions(c.Log, c.templatesClient, c.images, c.templates,
+		c.serviceAccountNames, c.registry, c.clusterID, c.apiURI, execution, options, c.natsURI, c.debug)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pvcSpec *corev1.PersistentVolumeClaim
+	if jobOptions.ArtifactRequest != nil &&
+		jobOptions.ArtifactRequest.StorageClassName != "" {
+		c.Log.Debug("creating persistent volume claim with options", "options", jobOptions)
+		if pvcSpec, err = NewPersistentVolumeClaimSpec(c.Log, NewPVCOptionsFromJobOptions(jobOptions)); err != nil {
+			return nil, nil, err
 		}
 	}
 
 	c.Log.Debug("creating job with options", "options", jobOptions)
 	jobSpec, err := NewJobSpec(c.Log, jobOptions)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	jobSpec.OwnerReferences = append(jobSpec.OwnerReferences, TestOwnerReferences(c.testsClient, execution)...)
 
-	_, err = jobs.Create(ctx, jobSpec, metav1.CreateOptions{})
-	return err
+	return pvcSpec, jobSpec, nil
 }
 
 func (c *JobExecutor) cleanPVCVolume(ctx context.Context, execution *testkube.Execution) error {
@@ -377,8 +523,9 @@ func (c *JobExecutor) cleanPVCVolume(ctx context.Context, execution *testkube.Ex
 }
 
 // updateResultsFromPod watches logs and stores results if execution is finished
-func (c *JobExecutor) updateResultsFromPod(ctx context.Context, pod corev1.Pod, l *zap.SugaredLogger, execution *testkube.Execution, isNegativeTest bool) (*testkube.ExecutionResult, error) {
+func (c *JobExecutor) updateResultsFromPod(ctx context.Context, pod corev1.Pod, l *zap.SugaredLogger, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
 	var err error
+	isNegativeTest := options.Request.NegativeTest
 
 	// save stop time and final state
 	defer func() {
@@ -393,14 +540,14 @@ func (c *JobExecutor) updateResultsFromPod(ctx context.Context, pod corev1.Pod,
 	}()
 
 	// wait for pod to be loggable
-	if err = wait.PollUntilContextTimeout(ctx, pollInterval, c.podStartTimeout, true, executor.IsPodLoggable(c.ClientSet, pod.Name, execution.TestNamespace)); err != nil {
+	if err = c.podWatcher().PollUntil(ctx, c.podStartTimeout, executor.IsPodLoggable(c.ClientSet, pod.Name, execution.TestNamespace)); err != nil {
 		c.streamLog(ctx, execution.Id, events.NewErrorLog(errors.Wrap(err, "can't start test job pod")))
 		l.Errorw("waiting for pod started error", "error", err)
 	}
 
 	l.Debug("poll immediate waiting for pod")
 	// wait for pod
-	if err = wait.PollUntilContextTimeout(ctx, pollInterval, pollTimeout, true, executor.IsPodReady(c.ClientSet, pod.Name, execution.TestNamespace)); err != nil {
+	if err = c.waitForPodCompleted(ctx, l, pod.Name, execution.TestNamespace); err != nil {
 		// continue on poll err and try to get logs later
 		c.streamLog(ctx, execution.Id, events.NewErrorLog(errors.Wrap(err, "can't read data from pod, pod was not completed")))
 		l.Errorw("waiting for pod complete error", "error", err)
@@ -423,14 +570,27 @@ func (c *JobExecutor) updateResultsFromPod(ctx context.Context, pod corev1.Pod,
 
 	// don't attach logs if logs v2 is enabled - they will be streamed through the logs service
 	attachLogs := !c.features.LogsV2
-	// parse job output log (JSON stream)
-	execution.ExecutionResult, err = output.ParseRunnerOutput(logs, attachLogs)
+	// parse job output log (JSON stream), or the configured output format
+	execution.ExecutionResult, err = c.parseOutput(logs, options.OutputFormat, attachLogs)
 	if err != nil {
 		l.Errorw("parse output error", "error", err)
 		c.streamLog(ctx, execution.Id, events.NewErrorLog(errors.Wrap(err, "can't get test execution job output")))
 		return execution.ExecutionResult, err
 	}
 
+	if current, err := c.ClientSet.CoreV1().Pods(execution.TestNamespace).Get(ctx, pod.Name, metav1.GetOptions{}); err == nil {
+		if err = ServiceFailure(*current); err != nil {
+			execution.ExecutionResult.Err(err)
+		}
+	}
+
+	if c.deadlineExceeded(ctx, execution.Id, execution.TestNamespace, pod.Name) {
+		execution.ExecutionResult.Timeout()
+		execution.ExecutionResult.ErrorMessage = "execution took too long, pod deadline exceeded"
+		c.streamLog(ctx, execution.Id, events.NewErrorLog(errors.New(execution.ExecutionResult.ErrorMessage)))
+		return execution.ExecutionResult, nil
+	}
+
 	if execution.ExecutionResult.IsFailed() {
 		errorMessage := execution.ExecutionResult.ErrorMessage
 		if errorMessage == "" {
@@ -448,6 +608,37 @@ func (c *JobExecutor) updateResultsFromPod(ctx context.Context, pod corev1.Pod,
 	return execution.ExecutionResult, nil
 }
 
+// parseOutput parses the execution container output according to the output format,
+// the native runner protocol is parsed by the output package
+func (c *JobExecutor) parseOutput(logs []byte, format OutputFormat, attachLogs bool) (*testkube.ExecutionResult, error) {
+	if format == OutputFormatNative {
+		return output.ParseRunnerOutput(logs, attachLogs)
+	}
+
+	parser, err := c.resultParsers.Get(format)
+	if err != nil {
+		result := testkube.NewErrorExecutionResult(err)
+		return &result, err
+	}
+
+	result, err := parser.Parse(logs)
+	if !attachLogs && err == nil {
+		result.Output = ""
+	}
+
+	return &result, err
+}
+
+// deadlineExceeded checks if the execution job or pod was terminated because of the active deadline
+func (c *JobExecutor) deadlineExceeded(ctx context.Context, jobName, namespace, podName string) bool {
+	if job, err := c.ClientSet.BatchV1().Jobs(namespace).Get(ctx, jobName, metav1.GetOptions{}); err == nil && JobDeadlineExceeded(*job) {
+		return true
+	}
+
+	pod, err := c.ClientSet.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	return err == nil && PodDeadlineExceeded(*pod)
+}
+
 func (c *JobExecutor) stopExecution(ctx context.Context, l *zap.SugaredLogger, execution *testkube.Execution, result *testkube.ExecutionResult, isNegativeTest bool, passedErr error) error {
 	savedExecution, err := c.Repository.Get(ctx, execution.Id)
 	if err != nil {
@@ -467,6 +658,12 @@ func (c *JobExecutor) stopExecution(ctx context.Context, l *zap.SugaredLogger, e
 		return nil
 	}
 
+	// pods of suspended job are deleted, it's not a failure, the paused execution is finished only by abort
+	if savedExecution.IsPaused() && !result.IsAborted() {
+		c.streamLog(ctx, execution.Id, logEvent.WithContent("execution is paused"))
+		return nil
+	}
+
 	execution.Stop()
 	if isNegativeTest {
 		if result.IsFailed() {
@@ -589,15 +786,6 @@ func (c *JobExecutor) stopExecution(ctx context.Context, l *zap.SugaredLogger, e
 
 // NewJobOptionsFromExecutionOptions compose JobOptions based on ExecuteOptions
 func NewJobOptionsFromExecutionOptions(options ExecuteOptions) JobOptions {
-	labels := map[string]string{
-		testkube.TestLabelTestType: utils.SanitizeName(options.TestSpec.Type_),
-		testkube.TestLabelExecutor: options.ExecutorName,
-		testkube.TestLabelTestName: options.TestName,
-	}
-	for key, value := range options.Labels {
-		labels[key] = value
-	}
-
 	contextType := ""
 	contextData := ""
 	if options.Request.RunningContext != nil {
@@ -633,16 +821,25 @@ func NewJobOptionsFromExecutionOptions(options ExecuteOptions) JobOptions {
 		TokenSecret:           options.TokenSecret,
 		RunnerCustomCASecret:  options.RunnerCustomCASecret,
 		CertificateSecret:     options.CertificateSecret,
-		ActiveDeadlineSeconds: options.Request.ActiveDeadlineSeconds,
+		ActiveDeadlineSeconds: options.ActiveDeadlineSeconds(),
 		JobTemplateExtensions: options.Request.JobTemplate,
 		EnvConfigMaps:         options.Request.EnvConfigMaps,
 		EnvSecrets:            options.Request.EnvSecrets,
-		Labels:                labels,
+		Labels:                ExecutionLabels(options),
+		Annotations:           options.Annotations,
 		ExecutionNumber:       options.Request.Number,
 		ContextType:           contextType,
 		ContextData:           contextData,
 		Features:              options.Features,
 		PvcTemplateExtensions: options.Request.PvcTemplate,
+		Resources:             options.Resources,
+		NodeSelector:          options.NodeSelector,
+		Tolerations:           options.Tolerations,
+		Affinity:              options.Affinity,
+		Services:              options.Services,
+		ExecutionEnvs:         options.Envs,
+		SecretRefs:            options.SecretEnvs,
+		ConfigMapRefs:         options.ConfigMapEnvs,
 	}
 }
 
@@ -675,7 +872,7 @@ func (c *JobExecutor) TailJobLogs(ctx context.Context, id, namespace string, log
 
 			default:
 				l.Debugw("tailing job logs: waiting for pod to be ready")
-				if err = wait.PollUntilContextTimeout(ctx, pollInterval, c.podStartTimeout, true, executor.IsPodLoggable(c.ClientSet, pod.Name, namespace)); err != nil {
+				if err = c.podWatcher().PollUntil(ctx, c.podStartTimeout, executor.IsPodLoggable(c.ClientSet, pod.Name, namespace)); err != nil {
 					l.Errorw("poll immediate error when tailing logs", "error", err)
 					return err
 				}
@@ -694,6 +891,9 @@ func (c *JobExecutor) TailPodLogs(ctx context.Context, pod corev1.Pod, logs chan
 
 	var containers []string
 	for _, container := range pod.Spec.InitContainers {
+		if executor.IsServiceContainer(container.Name) {
+			continue
+		}
 		containers = append(containers, container.Name)
 	}
 
@@ -743,6 +943,218 @@ func (c *JobExecutor) TailPodLogs(ctx context.Context, pod corev1.Pod, logs chan
 	return
 }
 
+// FollowLogs streams log lines of all execution pod containers, each container is followed concurrently,
+// so the lines of service containers running alongside the test are not held back until the test finishes;
+// lines of service containers have the service name set, so they can be collected separately,
+// restarted containers are followed again, lines are dropped when consumer can't keep up
+func (c *JobExecutor) FollowLogs(ctx context.Context, id, namespace string) (<-chan LogLine, error) {
+	pods, err := executor.GetJobPods(ctx, c.ClientSet.CoreV1().Pods(namespace), id, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := NewLogLineBuffer(DefaultLogLineBufferSize)
+	var wg sync.WaitGroup
+	for _, pod := range pods.Items {
+		if pod.Labels["job-name"] != id {
+			continue
+		}
+
+		wg.Add(1)
+		go func(pod corev1.Pod) {
+			defer wg.Done()
+
+			l := c.Log.With("podNamespace", pod.Namespace, "podName", pod.Name)
+			if err := c.podWatcher().PollUntil(ctx, c.podStartTimeout, executor.IsPodLoggable(c.ClientSet, pod.Name, namespace)); err != nil {
+				l.Errorw("poll immediate error when following logs", "error", err)
+				return
+			}
+
+			for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+				for _, container := range containers {
+					wg.Add(1)
+					go func(container string) {
+						defer wg.Done()
+						c.followContainerLogs(ctx, l, pod, container, buffer)
+					}(container.Name)
+				}
+			}
+		}(pod)
+	}
+
+	go func() {
+		wg.Wait()
+		buffer.Close()
+
+		if dropped := buffer.Dropped(); dropped > 0 {
+			c.Log.Warnw("log lines dropped, consumer too slow", "executionID", id, "dropped", dropped)
+		}
+	}()
+
+	return buffer.Lines(), nil
+}
+
+// followContainerLogs streams container logs until the container finishes without being restarted,
+// the interrupted stream is resumed from the last seen line, so the lines are not repeated
+func (c *JobExecutor) followContainerLogs(ctx context.Context, l *zap.SugaredLogger, pod corev1.Pod, container string, buffer *LogLineBuffer) {
+	source := pod.Name + "/" + container
+	service := ""
+	if executor.IsServiceContainer(container) {
+		service = strings.TrimPrefix(container, executor.ServiceContainerPrefix)
+	}
+	if err := c.waitForContainerStart(ctx, pod, container); err != nil {
+		l.Errorw("waiting for container to start error", "container", container, "error", err)
+		return
+	}
+	restarts := containerRestartCount(pod, container)
+	resumer := &logResumer{}
+	for {
+		stream, err := c.ClientSet.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, resumer.options(container)).Stream(ctx)
+		if err != nil {
+			l.Errorw("stream error", "container", container, "error", err)
+			return
+		}
+
+		reader := bufio.NewReader(stream)
+		for {
+			b, err := utils.ReadLongLine(reader)
+			if err != nil {
+				if err != io.EOF {
+					l.Errorw("scanner error", "container", container, "error", err)
+				}
+				break
+			}
+			line := ParseTimestampedLogLine(b, LogStreamCombined, source)
+			if !resumer.accept(line) {
+				continue
+			}
+			line.Service = service
+			buffer.Send(line)
+		}
+		stream.Close()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		resume, restarted, err := c.waitForContainerLogs(ctx, pod, container, &restarts)
+		if err != nil || !resume {
+			return
+		}
+
+		if restarted {
+			l.Debugw("container restarted, following logs again", "container", container, "restarts", restarts)
+		} else {
+			l.Debugw("log stream interrupted, resuming from the last line", "container", container, "since", resumer.last)
+		}
+	}
+}
+
+// waitForContainerStart waits until the container is started, so its logs can be streamed, the containers start
+// only once all the init containers before them have finished; the logs of finished pod are streamed right away
+func (c *JobExecutor) waitForContainerStart(ctx context.Context, pod corev1.Pod, container string) error {
+	return c.podWatcher().PollUntil(ctx, c.podStartTimeout, func(ctx context.Context) (bool, error) {
+		current, err := c.ClientSet.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		if current.Status.Phase == corev1.PodSucceeded || current.Status.Phase == corev1.PodFailed {
+			return true, nil
+		}
+
+		status := containerStatus(*current, container)
+		return status != nil && (status.State.Running != nil || status.State.Terminated != nil), nil
+	})
+}
+
+// waitForContainerLogs checks if there are more logs to follow once the stream ends, it's when the stream was interrupted
+// while the container is still running, or when the container was restarted
+func (c *JobExecutor) waitForContainerLogs(ctx context.Context, pod corev1.Pod, container string, restarts *int32) (resume, restarted bool, err error) {
+	err = c.podWatcher().PollUntil(ctx, c.podStartTimeout, func(ctx context.Context) (bool, error) {
+		current, err := c.ClientSet.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		if current.Status.Phase == corev1.PodSucceeded || current.Status.Phase == corev1.PodFailed {
+			return true, nil
+		}
+
+		status := containerStatus(*current, container)
+		if status == nil {
+			return false, nil
+		}
+
+		if status.State.Running != nil {
+			resume = true
+			if status.RestartCount > *restarts {
+				*restarts = status.RestartCount
+				restarted = true
+			}
+			return true, nil
+		}
+
+		// the container finished, it's started again only by the restart policy
+		return status.State.Terminated != nil && pod.Spec.RestartPolicy == corev1.RestartPolicyNever, nil
+	})
+	return resume, restarted, err
+}
+
+// logResumer follows the last seen log line, so the interrupted stream is requested again since its time
+// and the lines already seen are skipped
+type logResumer struct {
+	last time.Time
+}
+
+func (r *logResumer) options(container string) *corev1.PodLogOptions {
+	options := &corev1.PodLogOptions{
+		Follow:     true,
+		Timestamps: true,
+		Container:  container,
+	}
+	if !r.last.IsZero() {
+		// Kubernetes handles the since time with a second precision, so the already seen lines are returned too
+		since := metav1.NewTime(r.last)
+		options.SinceTime = &since
+	}
+	return options
+}
+
+// accept checks if the line is after the last seen one, and marks it as seen
+func (r *logResumer) accept(line LogLine) bool {
+	if !r.last.IsZero() && !line.Time.After(r.last) {
+		return false
+	}
+	r.last = line.Time
+	return true
+}
+
+func containerStatus(pod corev1.Pod, container string) *corev1.ContainerStatus {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for i := range statuses {
+			if statuses[i].Name == container {
+				return &statuses[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+func containerRestartCount(pod corev1.Pod, container string) int32 {
+	if status := containerStatus(pod, container); status != nil {
+		return status.RestartCount
+	}
+
+	return 0
+}
+
+func isContainerRunning(pod corev1.Pod, container string) bool {
+	status := containerStatus(pod, container)
+	return status != nil && status.State.Running != nil
+}
+
 // GetPodLogError returns last line as error
 func (c *JobExecutor) GetPodLogError(ctx context.Context, pod corev1.Pod) (logsBytes []byte, err error) {
 	// error line should be last one
@@ -769,20 +1181,58 @@ func (c *JobExecutor) GetLastLogLineError(ctx context.Context, pod corev1.Pod) e
 	return errors.Errorf("error from last log entry: %s", entry.String())
 }
 
-// Abort aborts K8S by job name
+// Abort deletes execution job and marks execution as aborted,
+// already finished execution is left untouched, its terminal result is returned with AlreadyFinishedError
 func (c *JobExecutor) Abort(ctx context.Context, execution *testkube.Execution) (result *testkube.ExecutionResult, err error) {
 	l := c.Log.With("execution", execution.Id)
-	result, err = executor.AbortJob(ctx, c.ClientSet, execution.TestNamespace, execution.Id)
-	if err != nil {
-		l.Errorw("error aborting job", "execution", execution.Id, "error", err)
+	if result, ok := c.finishedResult(ctx, *execution); ok {
+		l.Debugw("execution already finished, nothing to abort", "status", result.Status)
+		return result, &AlreadyFinishedError{ExecutionID: execution.Id, Result: result}
 	}
-	l.Debugw("job aborted", "execution", execution.Id, "result", result)
+
+	c.aborts.Cancel(execution.Id)
+	if shardsResult, ok := c.abortShardJobs(ctx, l, *execution); ok {
+		result = shardsResult
+		l.Debugw("shard jobs aborted", "execution", execution.Id, "result", result)
+	} else {
+		result, err = executor.AbortJob(ctx, c.ClientSet, execution.TestNamespace, execution.Id)
+		if err != nil {
+			l.Errorw("error aborting job", "execution", execution.Id, "error", err)
+		}
+		l.Debugw("job aborted", "execution", execution.Id, "result", result)
+	}
+
+	// execution could finish naturally while the job was being deleted
+	if finished, ok := c.finishedResult(ctx, *execution); ok {
+		l.Debugw("execution finished before abort", "status", finished.Status)
+		return finished, &AlreadyFinishedError{ExecutionID: execution.Id, Result: finished}
+	}
+
+	if result.IsAborted() {
+		result = NewAbortedExecutionResult(ctx, time.Now())
+	}
+
+	execution.ExecutionResult = result
 	if err := c.stopExecution(ctx, l, execution, result, false, nil); err != nil {
 		l.Errorw("error stopping execution on job executor abort", "error", err)
 	}
 	return result, nil
 }
 
+// finishedResult returns terminal result of the execution, stored execution is checked as it can be more recent
+func (c *JobExecutor) finishedResult(ctx context.Context, execution testkube.Execution) (*testkube.ExecutionResult, bool) {
+	if result, ok := FinishedResult(execution); ok {
+		return result, true
+	}
+
+	saved, err := c.Repository.Get(ctx, execution.Id)
+	if err != nil {
+		return nil, false
+	}
+
+	return FinishedResult(saved)
+}
+
 func (c *JobExecutor) Timeout(ctx context.Context, jobName string) (result *testkube.ExecutionResult) {
 	l := c.Log.With("jobName", jobName)
 	l.Infow("job timeout")
@@ -853,19 +1303,8 @@ func NewJobSpec(log *zap.SugaredLogger, options JobOptions) (*batchv1.Job, error
 		return nil, errors.Errorf("decoding job spec error: %v", err)
 	}
 
-	for key, value := range options.Labels {
-		if job.Labels == nil {
-			job.Labels = make(map[string]string)
-		}
-
-		job.Labels[key] = value
-
-		if job.Spec.Template.Labels == nil {
-			job.Spec.Template.Labels = make(map[string]string)
-		}
-
-		job.Spec.Template.Labels[key] = value
-	}
+	ApplyMetadata(&job.ObjectMeta, options.Labels, options.Annotations)
+	ApplyMetadata(&job.Spec.Template.ObjectMeta, options.Labels, options.Annotations)
 
 	envs := append(executor.RunnerEnvVars, corev1.EnvVar{Name: "RUNNER_CLUSTERID", Value: options.ClusterID})
 	if options.ArtifactRequest != nil && options.ArtifactRequest.StorageBucket != "" {
@@ -891,6 +1330,14 @@ func NewJobSpec(log *zap.SugaredLogger, options JobOptions) (*batchv1.Job, error
 	envs = append(envs, corev1.EnvVar{Name: "RUNNER_CONTEXTTYPE", Value: options.ContextType})
 	envs = append(envs, corev1.EnvVar{Name: "RUNNER_CONTEXTDATA", Value: options.ContextData})
 	envs = append(envs, corev1.EnvVar{Name: "RUNNER_APIURI", Value: options.APIURI})
+	if options.ArtifactCollection != nil {
+		collection, err := json.Marshal(options.ArtifactCollection)
+		if err != nil {
+			return nil, errors.Errorf("encoding artifact collection error: %v", err)
+		}
+
+		envs = append(envs, corev1.EnvVar{Name: "RUNNER_ARTIFACT_COLLECTION", Value: string(collection)})
+	}
 
 	for i := range job.Spec.Template.Spec.InitContainers {
 		job.Spec.Template.Spec.InitContainers[i].Env = append(job.Spec.Template.Spec.InitContainers[i].Env, envs...)
@@ -900,12 +1347,32 @@ func NewJobSpec(log *zap.SugaredLogger, options JobOptions) (*batchv1.Job, error
 		job.Spec.Template.Spec.Containers[i].Env = append(job.Spec.Template.Spec.Containers[i].Env, envs...)
 	}
 
+	if options.Resources != nil {
+		for i := range job.Spec.Template.Spec.Containers {
+			if job.Spec.Template.Spec.Containers[i].Name != options.Name {
+				continue
+			}
+
+			if err := options.Resources.ApplyTo(&job.Spec.Template.Spec.Containers[i]); err != nil {
+				return nil, errors.Errorf("preparing execution container resources: %v", err)
+			}
+		}
+	}
+
+	ApplyActiveDeadline(&job, options.ActiveDeadlineSeconds)
+	ApplyEnvs(&job.Spec.Template.Spec, options.ExecutionEnvs, options.SecretRefs, options.ConfigMapRefs)
+	ApplyScheduling(&job.Spec.Template.Spec, options.NodeSelector, options.Tolerations, options.Affinity)
+	ApplyPriority(&job.Spec.Template.Spec, options.Priority)
+	ApplyServices(&job.Spec.Template.Spec, options.Services)
+
 	return &job, nil
 }
 
 func NewJobOptions(log *zap.SugaredLogger, templatesClient templatesv1.Interface, images executor.Images,
 	templates executor.Templates, serviceAccountNames map[string]string, registry, clusterID, apiURI string,
 	execution testkube.Execution, options ExecuteOptions, natsURI string, debug bool) (jobOptions JobOptions, err error) {
+	// runner builds the test command from the execution, so overrides are applied there
+	execution.Command, execution.Args = options.CommandAndArgs(execution.Command, execution.Args)
 	jsn, err := json.Marshal(execution)
 	if err != nil {
 		return jobOptions, err
@@ -957,6 +1424,19 @@ func NewJobOptions(log *zap.SugaredLogger, templatesClient templatesv1.Interface
 		}
 	}
 
+	if options.Resources != nil {
+		resources, err := options.Resources.Resolve(NewExecutionMachine(execution))
+		if err != nil {
+			return jobOptions, err
+		}
+
+		if err = resources.Validate(); err != nil {
+			return jobOptions, err
+		}
+
+		jobOptions.Resources = &resources
+	}
+
 	jobOptions.Variables = execution.Variables
 	serviceAccountName, ok := serviceAccountNames[execution.TestNamespace]
 	if !ok {
@@ -964,6 +1444,10 @@ func NewJobOptions(log *zap.SugaredLogger, templatesClient templatesv1.Interface
 	}
 
 	jobOptions.ServiceAccountName = serviceAccountName
+	if options.ServiceAccountName != "" {
+		jobOptions.ServiceAccountName = options.ServiceAccountName
+	}
+
 	jobOptions.Registry = registry
 	jobOptions.ClusterID = clusterID
 
@@ -979,12 +1463,15 @@ func NewJobOptions(log *zap.SugaredLogger, templatesClient templatesv1.Interface
 		jobOptions.ArtifactRequest = execution.ArtifactRequest
 	}
 
+	jobOptions.ArtifactCollection = options.ArtifactRequest
+	jobOptions.Priority = options.Priority
+
 	workingDir := agent.GetDefaultWorkingDir(executor.VolumeDir, execution)
 	if execution.Content != nil && execution.Content.Repository != nil && execution.Content.Repository.WorkingDir != "" {
 		workingDir = filepath.Join(executor.VolumeDir, "repo", execution.Content.Repository.WorkingDir)
 	}
 
-	jobOptions.WorkingDir = workingDir
+	jobOptions.WorkingDir = options.ResolveWorkingDir(workingDir, filepath.Join(executor.VolumeDir, "repo"))
 	jobOptions.APIURI = apiURI
 
 	jobOptions.SlavePodTemplate = templates.Slave
@@ -1056,5 +1543,7 @@ func NewPVCOptionsFromJobOptions(options JobOptions) PVCOptions {
 		PvcTemplate:           options.PvcTemplate,
 		PvcTemplateExtensions: options.PvcTemplateExtensions,
 		ArtifactRequest:       options.ArtifactRequest,
+		Labels:                options.Labels,
+		Annotations:           options.Annotations,
 	}
 }
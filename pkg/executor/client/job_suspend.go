@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// minJobSuspendVersion is the first kubernetes version with job suspend enabled by default
+var minJobSuspendVersion = version.MustParseGeneric("1.21.0")
+
+// Suspend suspends the execution job, its pods are deleted and the execution is recorded as paused,
+// job keeps its spec, so the execution can be resumed later
+func (c *JobExecutor) Suspend(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
+	l := c.Log.With("execution", execution.Id)
+	if result, ok := c.finishedResult(ctx, *execution); ok {
+		return result, &AlreadyFinishedError{ExecutionID: execution.Id, Result: result}
+	}
+
+	if err := c.setJobSuspended(ctx, execution, true); err != nil {
+		return nil, err
+	}
+
+	// pods deleted by suspend would be recorded as failure by the result watcher
+	c.aborts.Cancel(execution.Id)
+	result := c.saveSuspendedStatus(ctx, execution, testkube.PAUSED_ExecutionStatus)
+	l.Debugw("job suspended")
+
+	return result, nil
+}
+
+// Resume resumes the suspended execution job and watches the pod created for it like on asynchronous execute
+func (c *JobExecutor) Resume(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	l := c.Log.With("execution", execution.Id)
+	if result, ok := c.finishedResult(ctx, *execution); ok {
+		return result, &AlreadyFinishedError{ExecutionID: execution.Id, Result: result}
+	}
+
+	if execution.ExecutionResult == nil || execution.ExecutionResult.Status == nil || !execution.ExecutionResult.IsPaused() {
+		return nil, fmt.Errorf("resuming execution %s: %w", execution.Id, ErrNotPaused)
+	}
+
+	if err := c.setJobSuspended(ctx, execution, false); err != nil {
+		return nil, err
+	}
+
+	result := c.saveSuspendedStatus(ctx, execution, testkube.RUNNING_ExecutionStatus)
+	l.Debugw("job resumed")
+
+	// resumed execution outlives the resume request
+	go c.watchResumedJob(context.WithoutCancel(ctx), l, execution, options)
+
+	return result, nil
+}
+
+// setJobSuspended patches the execution job suspend flag, the server version is checked first,
+// as older servers ignore the flag and the job would keep running
+func (c *JobExecutor) setJobSuspended(ctx context.Context, execution *testkube.Execution, suspend bool) error {
+	info, err := c.ClientSet.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Errorf("getting kubernetes server version: %w", err)
+	}
+
+	serverVersion, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return fmt.Errorf("parsing kubernetes server version %s: %w", info.GitVersion, err)
+	}
+
+	if serverVersion.LessThan(minJobSuspendVersion) {
+		return fmt.Errorf("%w: version %s, required %s", ErrSuspendNotSupported, serverVersion, minJobSuspendVersion)
+	}
+
+	patch := fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend)
+	_, err = c.ClientSet.BatchV1().Jobs(execution.TestNamespace).
+		Patch(ctx, execution.Id, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("patching job %s suspend to %t: %w", execution.Id, suspend, err)
+	}
+
+	return nil
+}
+
+// saveSuspendedStatus records the status of suspended or resumed execution, the job is already patched,
+// so the failure to save is only logged
+func (c *JobExecutor) saveSuspendedStatus(ctx context.Context, execution *testkube.Execution, status testkube.ExecutionStatus) *testkube.ExecutionResult {
+	if execution.ExecutionResult == nil {
+		execution.ExecutionResult = testkube.NewRunningExecutionResult()
+	}
+
+	execution.ExecutionResult.Status = testkube.StatusPtr(status)
+	if err := c.Repository.UpdateResult(ctx, execution.Id, *execution); err != nil {
+		c.Log.Errorw("error saving execution status", "execution", execution.Id, "status", status, "error", err)
+	}
+
+	return execution.ExecutionResult
+}
+
+// watchResumedJob waits for the pod created by resumed job and watches it like on asynchronous execute,
+// pods of the suspended job can be still terminating
+func (c *JobExecutor) watchResumedJob(ctx context.Context, l *zap.SugaredLogger, execution *testkube.Execution, options ExecuteOptions) {
+	watchCtx, release := c.aborts.Watch(ctx, execution.Id)
+	defer release()
+
+	var pod corev1.Pod
+	podsClient := c.ClientSet.CoreV1().Pods(execution.TestNamespace)
+	err := c.podWatcher().PollUntil(watchCtx, c.podStartTimeout, func(ctx context.Context) (bool, error) {
+		pods, err := podsClient.List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + execution.Id})
+		if err != nil {
+			return false, err
+		}
+
+		for _, p := range pods.Items {
+			if p.DeletionTimestamp == nil && p.Status.Phase != corev1.PodFailed {
+				pod = p
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		l.Errorw("waiting for resumed job pod error", "error", err)
+		return
+	}
+
+	if _, err := c.updateResultsFromPod(watchCtx, pod, l, execution, options); err != nil {
+		l.Errorw("update results from resumed jobs pod error", "error", err)
+	}
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/repository/result"
+)
+
+func newSuspendTestJobExecutor(repository result.Repository, serverVersion string) *JobExecutor {
+	clientSet := fake.NewSimpleClientset(&batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "exec-1", Namespace: "default"},
+	})
+	clientSet.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: serverVersion}
+
+	return &JobExecutor{
+		Repository:      repository,
+		Log:             zap.NewNop().Sugar(),
+		ClientSet:       clientSet,
+		aborts:          NewAbortRegistry(),
+		podStartTimeout: 10 * time.Millisecond,
+	}
+}
+
+func jobSuspended(t *testing.T, c *JobExecutor) bool {
+	job, err := c.ClientSet.BatchV1().Jobs("default").Get(context.Background(), "exec-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	return job.Spec.Suspend != nil && *job.Spec.Suspend
+}
+
+func TestJobExecutor_SuspendResume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := result.NewMockRepository(ctrl)
+	execution := &testkube.Execution{Id: "exec-1", TestNamespace: "default", ExecutionResult: testkube.NewRunningExecutionResult()}
+	repository.EXPECT().Get(gomock.Any(), "exec-1").Return(*execution, nil)
+	repository.EXPECT().UpdateResult(gomock.Any(), "exec-1", gomock.Any()).
+		Do(func(_ context.Context, _ string, saved testkube.Execution) {
+			assert.True(t, saved.IsPaused())
+		})
+	c := newSuspendTestJobExecutor(repository, "v1.28.4")
+	watchCtx, release := c.aborts.Watch(context.Background(), "exec-1")
+	defer release()
+
+	res, err := c.Suspend(context.Background(), execution)
+
+	assert.NoError(t, err)
+	assert.True(t, res.IsPaused())
+	assert.True(t, jobSuspended(t, c))
+	assert.ErrorIs(t, watchCtx.Err(), context.Canceled)
+
+	repository.EXPECT().Get(gomock.Any(), "exec-1").Return(*execution, nil)
+	repository.EXPECT().UpdateResult(gomock.Any(), "exec-1", gomock.Any()).
+		Do(func(_ context.Context, _ string, saved testkube.Execution) {
+			assert.True(t, saved.IsRunning())
+		})
+
+	res, err = c.Resume(context.Background(), execution, ExecuteOptions{})
+
+	assert.NoError(t, err)
+	assert.True(t, res.IsRunning())
+	assert.False(t, jobSuspended(t, c))
+}
+
+func TestJobExecutor_SuspendNotSupported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := result.NewMockRepository(ctrl)
+	execution := &testkube.Execution{Id: "exec-1", TestNamespace: "default", ExecutionResult: testkube.NewRunningExecutionResult()}
+	repository.EXPECT().Get(gomock.Any(), "exec-1").Return(*execution, nil)
+	c := newSuspendTestJobExecutor(repository, "v1.20.15")
+
+	_, err := c.Suspend(context.Background(), execution)
+
+	assert.ErrorIs(t, err, ErrSuspendNotSupported)
+	assert.False(t, jobSuspended(t, c))
+}
+
+func TestJobExecutor_SuspendFinishedExecution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := newSuspendTestJobExecutor(result.NewMockRepository(ctrl), "v1.28.4")
+	finished := &testkube.ExecutionResult{Status: testkube.ExecutionStatusPassed}
+	execution := &testkube.Execution{Id: "exec-1", TestNamespace: "default", ExecutionResult: finished}
+
+	res, err := c.Suspend(context.Background(), execution)
+	assert.True(t, IsAlreadyFinished(err))
+	assert.Same(t, finished, res)
+
+	res, err = c.Resume(context.Background(), execution, ExecuteOptions{})
+	assert.True(t, IsAlreadyFinished(err))
+	assert.Same(t, finished, res)
+	assert.False(t, jobSuspended(t, c))
+}
+
+func TestJobExecutor_ResumeNotPaused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := result.NewMockRepository(ctrl)
+	execution := &testkube.Execution{Id: "exec-1", TestNamespace: "default", ExecutionResult: testkube.NewRunningExecutionResult()}
+	repository.EXPECT().Get(gomock.Any(), "exec-1").Return(*execution, nil)
+	c := newSuspendTestJobExecutor(repository, "v1.28.4")
+
+	_, err := c.Resume(context.Background(), execution, ExecuteOptions{})
+
+	assert.ErrorIs(t, err, ErrNotPaused)
+}
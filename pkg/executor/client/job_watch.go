@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/kubeshop/testkube/pkg/executor"
+)
+
+// watchObjects evaluates the condition on the objects changed by the watch events until it's met, it reports false
+// when the watch breaks, so the caller can fall back to polling; deleted object is reported to the condition too
+func watchObjects(ctx context.Context, w watch.Interface, condition func(obj runtime.Object, deleted bool) (bool, error)) (bool, error) {
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return false, nil
+			}
+
+			switch event.Type {
+			case watch.Added, watch.Modified, watch.Deleted:
+				if done, err := condition(event.Object, event.Type == watch.Deleted); done || err != nil {
+					return true, err
+				}
+			case watch.Error:
+				return false, nil
+			}
+		}
+	}
+}
+
+// watchJobForTimeout watches the job until it finishes, timing it out when it exceeds its deadline,
+// see MonitorJobForTimeout; it reports false when the watch breaks before the job finishes
+func (c *JobExecutor) watchJobForTimeout(ctx context.Context, l *zap.SugaredLogger, jobName, namespace string) bool {
+	jobsClient := c.ClientSet.BatchV1().Jobs(namespace)
+	options := metav1.ListOptions{LabelSelector: "job-name=" + jobName}
+	jobs, err := jobsClient.List(ctx, options)
+	if err != nil {
+		l.Errorw("could not get jobs", "error", err)
+		return true
+	}
+	if len(jobs.Items) == 0 || c.checkJobForTimeout(ctx, l, jobs.Items[0]) {
+		return true
+	}
+
+	// follow the changes since the listed state, so no change is missed in between
+	options.ResourceVersion = jobs.ResourceVersion
+	w, err := jobsClient.Watch(ctx, options)
+	if err != nil {
+		l.Errorw("could not watch jobs", "error", err)
+		return false
+	}
+
+	done, err := watchObjects(ctx, w, func(obj runtime.Object, deleted bool) (bool, error) {
+		job, ok := obj.(*batchv1.Job)
+		if !ok {
+			return false, nil
+		}
+		return deleted || c.checkJobForTimeout(ctx, l, *job), nil
+	})
+	if err != nil {
+		l.Infow("context done, stopping job timeout monitor")
+	}
+	return done
+}
+
+// waitForPodCompleted waits until the pod succeeds or fails, the pod is watched when enabled by watch options,
+// and polled otherwise or when the watch breaks
+func (c *JobExecutor) waitForPodCompleted(ctx context.Context, l *zap.SugaredLogger, podName, namespace string) error {
+	if c.watchOptions.UseWatch {
+		done, err := c.watchPodCompleted(ctx, podName, namespace)
+		if done {
+			return err
+		}
+		l.Infow("pod watch broken, polling pod state", "error", err)
+	}
+
+	return c.podWatcher().PollUntil(ctx, pollTimeout, executor.IsPodReady(c.ClientSet, podName, namespace))
+}
+
+// watchPodCompleted watches the pod until it succeeds or fails, it reports false when the watch breaks before
+func (c *JobExecutor) watchPodCompleted(ctx context.Context, podName, namespace string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, pollTimeout)
+	defer cancel()
+
+	podsClient := c.ClientSet.CoreV1().Pods(namespace)
+	pod, err := podsClient.Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return true, err
+	}
+	if completed, err := isPodCompleted(pod); completed {
+		return true, err
+	}
+
+	// follow the changes since the read state, so no change is missed in between
+	w, err := podsClient.Watch(ctx, metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", podName).String(),
+		ResourceVersion: pod.ResourceVersion,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return watchObjects(ctx, w, func(obj runtime.Object, deleted bool) (bool, error) {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok {
+			return false, nil
+		}
+		if deleted {
+			return true, fmt.Errorf("pod %s was deleted before completion", podName)
+		}
+		return isPodCompleted(pod)
+	})
+}
+
+// isPodCompleted checks if the pod succeeded, or failed with the error, like executor.IsPodReady
+func isPodCompleted(pod *corev1.Pod) (bool, error) {
+	if pod.Status.Phase == corev1.PodSucceeded {
+		return true, nil
+	}
+
+	if err := executor.IsPodFailed(pod); err != nil {
+		return true, err
+	}
+
+	return false, nil
+}
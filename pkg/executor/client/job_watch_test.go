@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// watchingJobExecutor polls rarely, so the test finishes in time only when the state is watched
+func watchingJobExecutor(clientSet *fake.Clientset) *JobExecutor {
+	return &JobExecutor{
+		Log:          zap.NewNop().Sugar(),
+		ClientSet:    clientSet,
+		watchOptions: WatchOptions{Interval: time.Hour, MaxInterval: time.Hour, Multiplier: 1, UseWatch: true},
+	}
+}
+
+func countActions(clientSet *fake.Clientset, verb, resource string) (count int) {
+	for _, action := range clientSet.Actions() {
+		if action.GetVerb() == verb && action.GetResource().Resource == resource {
+			count++
+		}
+	}
+	return count
+}
+
+func TestJobExecutor_MonitorJobForTimeoutWatch(t *testing.T) {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "exec-1", Namespace: "default", Labels: map[string]string{"job-name": "exec-1"}}}
+	clientSet := fake.NewSimpleClientset(job)
+	watcher := watch.NewFake()
+	clientSet.PrependWatchReactor("jobs", k8stesting.DefaultWatchReactor(watcher, nil))
+	c := watchingJobExecutor(clientSet)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.MonitorJobForTimeout(context.Background(), "exec-1", "default")
+	}()
+
+	completed := job.DeepCopy()
+	completed.Status.Succeeded = 1
+	watcher.Modify(completed)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job completion not detected by the watch")
+	}
+	assert.Equal(t, 1, countActions(clientSet, "list", "jobs"))
+	assert.Equal(t, 1, countActions(clientSet, "watch", "jobs"))
+}
+
+func TestJobExecutor_waitForPodCompletedWatch(t *testing.T) {
+	newPod := func(phase corev1.PodPhase, message string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "exec-1-abcde", Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: phase, Message: message},
+		}
+	}
+
+	t.Run("succeeded", func(t *testing.T) {
+		clientSet := fake.NewSimpleClientset(newPod(corev1.PodRunning, ""))
+		watcher := watch.NewFake()
+		clientSet.PrependWatchReactor("pods", k8stesting.DefaultWatchReactor(watcher, nil))
+		c := watchingJobExecutor(clientSet)
+
+		go watcher.Modify(newPod(corev1.PodSucceeded, ""))
+
+		assert.NoError(t, c.waitForPodCompleted(context.Background(), c.Log, "exec-1-abcde", "default"))
+		assert.Equal(t, 1, countActions(clientSet, "get", "pods"))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		clientSet := fake.NewSimpleClientset(newPod(corev1.PodRunning, ""))
+		watcher := watch.NewFake()
+		clientSet.PrependWatchReactor("pods", k8stesting.DefaultWatchReactor(watcher, nil))
+		c := watchingJobExecutor(clientSet)
+
+		go watcher.Modify(newPod(corev1.PodFailed, "out of memory"))
+
+		assert.EqualError(t, c.waitForPodCompleted(context.Background(), c.Log, "exec-1-abcde", "default"), "out of memory")
+	})
+
+	t.Run("broken watch falls back to polling", func(t *testing.T) {
+		clientSet := fake.NewSimpleClientset()
+		gets := 0
+		clientSet.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			gets++
+			if gets == 1 {
+				return true, newPod(corev1.PodRunning, ""), nil
+			}
+			return true, newPod(corev1.PodSucceeded, ""), nil
+		})
+		watcher := watch.NewFake()
+		clientSet.PrependWatchReactor("pods", k8stesting.DefaultWatchReactor(watcher, nil))
+		c := watchingJobExecutor(clientSet)
+		watcher.Stop()
+
+		assert.NoError(t, c.waitForPodCompleted(context.Background(), c.Log, "exec-1-abcde", "default"))
+		assert.Equal(t, 2, gets)
+	})
+}
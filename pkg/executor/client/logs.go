@@ -0,0 +1,106 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLogLineBufferSize is a number of log lines buffered for slow consumers before lines are dropped
+const DefaultLogLineBufferSize = 1000
+
+// LogStream is an output stream log line was written to
+type LogStream string
+
+const (
+	LogStreamStdout LogStream = "stdout"
+	LogStreamStderr LogStream = "stderr"
+	// LogStreamCombined is used when source doesn't separate streams, e.g. Kubernetes pod logs
+	LogStreamCombined LogStream = "combined"
+)
+
+// LogLine is a single line of execution log
+type LogLine struct {
+	// Time is when the line was written, lines from different sources can be interleaved by time
+	Time time.Time
+	// Stream is an output stream line was written to
+	Stream LogStream
+	// Source identifies where the line comes from, e.g. pod/container for job executors
+	Source string
+	// Service is a name of the execution service the line comes from, it's empty for the test container lines
+	Service string
+	// Content is the line without trailing new line
+	Content string
+}
+
+// LogFollower streams execution logs while execution is running
+type LogFollower interface {
+	// FollowLogs returns channel with execution log lines, channel is closed when execution ends or context is done
+	FollowLogs(ctx context.Context, id, namespace string) (<-chan LogLine, error)
+}
+
+// LogLineBuffer delivers log lines to a consumer without blocking the producer,
+// up to size lines are buffered and when consumer falls further behind new lines are dropped and counted
+type LogLineBuffer struct {
+	lines   chan LogLine
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// NewLogLineBuffer creates new log line buffer, non-positive size uses DefaultLogLineBufferSize
+func NewLogLineBuffer(size int) *LogLineBuffer {
+	if size <= 0 {
+		size = DefaultLogLineBufferSize
+	}
+
+	return &LogLineBuffer{lines: make(chan LogLine, size)}
+}
+
+// Send buffers log line, returns false when line was dropped because buffer is full
+func (b *LogLineBuffer) Send(line LogLine) bool {
+	select {
+	case b.lines <- line:
+		return true
+	default:
+		b.dropped.Add(1)
+		return false
+	}
+}
+
+// Lines returns channel consumer reads log lines from
+func (b *LogLineBuffer) Lines() <-chan LogLine {
+	return b.lines
+}
+
+// Dropped returns number of log lines dropped because consumer was too slow
+func (b *LogLineBuffer) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Close closes lines channel, it has to be called by the producer once there are no more lines
+func (b *LogLineBuffer) Close() {
+	b.once.Do(func() {
+		close(b.lines)
+	})
+}
+
+// ParseTimestampedLogLine parses log line prefixed with RFC3339 timestamp, as returned by Kubernetes with timestamps enabled,
+// current time is used when line has no timestamp
+func ParseTimestampedLogLine(b []byte, stream LogStream, source string) LogLine {
+	line := LogLine{Time: time.Now(), Stream: stream, Source: source, Content: string(b)}
+	timestamp, content, found := bytes.Cut(b, []byte(" "))
+	if !found {
+		return line
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, string(timestamp))
+	if err != nil {
+		return line
+	}
+
+	line.Time = t
+	line.Content = string(content)
+	return line
+}
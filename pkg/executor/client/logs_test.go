@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLogLineBuffer_DropsWhenFull(t *testing.T) {
+	buffer := NewLogLineBuffer(2)
+
+	// producer never blocks, lines above the bound are dropped and counted
+	assert.True(t, buffer.Send(LogLine{Content: "first"}))
+	assert.True(t, buffer.Send(LogLine{Content: "second"}))
+	assert.False(t, buffer.Send(LogLine{Content: "third"}))
+	assert.False(t, buffer.Send(LogLine{Content: "fourth"}))
+	buffer.Close()
+	buffer.Close()
+
+	var lines []string
+	for line := range buffer.Lines() {
+		lines = append(lines, line.Content)
+	}
+
+	assert.Equal(t, []string{"first", "second"}, lines)
+	assert.Equal(t, int64(2), buffer.Dropped())
+}
+
+func TestLogLineBuffer_DefaultSize(t *testing.T) {
+	assert.Equal(t, DefaultLogLineBufferSize, cap(NewLogLineBuffer(0).lines))
+}
+
+func TestParseTimestampedLogLine(t *testing.T) {
+	line := ParseTimestampedLogLine([]byte("2024-01-02T03:04:05.123456789Z running test 1"), LogStreamCombined, "pod/main")
+
+	assert.Equal(t, LogLine{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC),
+		Stream:  LogStreamCombined,
+		Source:  "pod/main",
+		Content: "running test 1",
+	}, line)
+
+	line = ParseTimestampedLogLine([]byte("no timestamp here"), LogStreamStdout, "runner")
+	assert.Equal(t, "no timestamp here", line.Content)
+	assert.False(t, line.Time.IsZero())
+}
+
+func TestJobExecutor_FollowLogs(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "exec-1-abcde", Namespace: "default", Labels: map[string]string{"job-name": "exec-1"}},
+		Spec: corev1.PodSpec{
+			RestartPolicy:  corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{{Name: "init"}},
+			Containers:     []corev1.Container{{Name: "main"}, {Name: "scraper"}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+	executor := &JobExecutor{
+		Log:             zap.NewNop().Sugar(),
+		ClientSet:       fake.NewSimpleClientset(pod),
+		podStartTimeout: time.Second,
+	}
+
+	logs, err := executor.FollowLogs(context.Background(), "exec-1", "default")
+	require.NoError(t, err)
+
+	var sources []string
+	for line := range logs {
+		assert.Equal(t, LogStreamCombined, line.Stream)
+		assert.Equal(t, "fake logs", line.Content)
+		sources = append(sources, line.Source)
+	}
+
+	// containers are followed concurrently, so the lines are not ordered by the container
+	assert.ElementsMatch(t, []string{"exec-1-abcde/init", "exec-1-abcde/main", "exec-1-abcde/scraper"}, sources)
+}
+
+func TestJobExecutor_FollowLogsServices(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "exec-1-abcde", Namespace: "default", Labels: map[string]string{"job-name": "exec-1"}},
+		Spec: corev1.PodSpec{
+			RestartPolicy:  corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{{Name: "exec-1-init"}, {Name: ServiceContainerName("db")}},
+			Containers:     []corev1.Container{{Name: "exec-1"}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+	executor := &JobExecutor{
+		Log:             zap.NewNop().Sugar(),
+		ClientSet:       fake.NewSimpleClientset(pod),
+		podStartTimeout: time.Second,
+	}
+
+	logs, err := executor.FollowLogs(context.Background(), "exec-1", "default")
+	require.NoError(t, err)
+
+	services := map[string]string{}
+	for line := range logs {
+		services[line.Source] = line.Service
+	}
+
+	// service lines are labeled, so they can be collected separately from the test output
+	assert.Equal(t, map[string]string{
+		"exec-1-abcde/exec-1-init": "",
+		"exec-1-abcde/service-db":  "db",
+		"exec-1-abcde/exec-1":      "",
+	}, services)
+}
+
+func TestLogResumer(t *testing.T) {
+	resumer := &logResumer{}
+	options := resumer.options("main")
+	assert.True(t, options.Follow)
+	assert.True(t, options.Timestamps)
+	assert.Equal(t, "main", options.Container)
+	assert.Nil(t, options.SinceTime)
+
+	first := ParseTimestampedLogLine([]byte("2024-01-01T10:00:00.100000000Z first"), LogStreamCombined, "pod/main")
+	second := ParseTimestampedLogLine([]byte("2024-01-01T10:00:00.200000000Z second"), LogStreamCombined, "pod/main")
+	assert.True(t, resumer.accept(first))
+	assert.True(t, resumer.accept(second))
+
+	// resumed stream returns the lines since the last second, the ones already seen are skipped
+	options = resumer.options("main")
+	require.NotNil(t, options.SinceTime)
+	assert.True(t, options.SinceTime.Time.Equal(second.Time))
+	assert.False(t, resumer.accept(first))
+	assert.False(t, resumer.accept(second))
+	assert.True(t, resumer.accept(ParseTimestampedLogLine([]byte("2024-01-01T10:00:00.300000000Z third"), LogStreamCombined, "pod/main")))
+}
+
+func TestJobExecutor_waitForContainerLogs(t *testing.T) {
+	newPod := func(policy corev1.RestartPolicy, restarts int32, state corev1.ContainerState) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "exec-1-abcde", Namespace: "default", Labels: map[string]string{"job-name": "exec-1"}},
+			Spec: corev1.PodSpec{
+				RestartPolicy: policy,
+				Containers:    []corev1.Container{{Name: "main"}, {Name: "scraper"}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "main", RestartCount: restarts, State: state},
+					{Name: "scraper", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}},
+				},
+			},
+		}
+	}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	terminated := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}
+
+	tests := map[string]struct {
+		pod       *corev1.Pod
+		resume    bool
+		restarted bool
+		restarts  int32
+	}{
+		"interrupted stream of running container": {pod: newPod(corev1.RestartPolicyNever, 0, running), resume: true},
+		"finished container":                      {pod: newPod(corev1.RestartPolicyNever, 0, terminated)},
+		"restarted container":                     {pod: newPod(corev1.RestartPolicyOnFailure, 1, running), resume: true, restarted: true, restarts: 1},
+		"finished pod": {pod: func() *corev1.Pod {
+			pod := newPod(corev1.RestartPolicyOnFailure, 0, terminated)
+			pod.Status.Phase = corev1.PodSucceeded
+			return pod
+		}()},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			executor := &JobExecutor{
+				Log:             zap.NewNop().Sugar(),
+				ClientSet:       fake.NewSimpleClientset(tt.pod),
+				podStartTimeout: time.Second,
+			}
+
+			var restarts int32
+			resume, restarted, err := executor.waitForContainerLogs(context.Background(), *tt.pod, "main", &restarts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.resume, resume)
+			assert.Equal(t, tt.restarted, restarted)
+			assert.Equal(t, tt.restarts, restarts)
+		})
+	}
+}
+
+func TestJobExecutor_waitForContainerStart(t *testing.T) {
+	newPod := func(phase corev1.PodPhase, state corev1.ContainerState) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "exec-1-abcde", Namespace: "default", Labels: map[string]string{"job-name": "exec-1"}},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init"}},
+				Containers:     []corev1.Container{{Name: "main"}},
+			},
+			Status: corev1.PodStatus{
+				Phase:                 phase,
+				InitContainerStatuses: []corev1.ContainerStatus{{Name: "init", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+				ContainerStatuses:     []corev1.ContainerStatus{{Name: "main", State: state}},
+			},
+		}
+	}
+	waiting := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}
+
+	tests := map[string]struct {
+		pod       *corev1.Pod
+		container string
+		started   bool
+	}{
+		"running init container":           {pod: newPod(corev1.PodPending, waiting), container: "init", started: true},
+		"container waiting for init":       {pod: newPod(corev1.PodPending, waiting), container: "main"},
+		"running container":                {pod: newPod(corev1.PodRunning, corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}), container: "main", started: true},
+		"terminated container":             {pod: newPod(corev1.PodRunning, corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}), container: "main", started: true},
+		"finished pod without the started": {pod: newPod(corev1.PodFailed, waiting), container: "main", started: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			executor := &JobExecutor{
+				Log:             zap.NewNop().Sugar(),
+				ClientSet:       fake.NewSimpleClientset(tt.pod),
+				podStartTimeout: 100 * time.Millisecond,
+			}
+
+			err := executor.waitForContainerStart(context.Background(), *tt.pod, tt.container)
+			assert.Equal(t, tt.started, err == nil, err)
+		})
+	}
+}
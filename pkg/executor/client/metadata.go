@@ -0,0 +1,118 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
+	testsclientv3 "github.com/kubeshop/testkube-operator/pkg/client/tests/v3"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/utils"
+)
+
+const (
+	// ExecutionIDLabel is set on the objects created for the execution to the execution id
+	ExecutionIDLabel = "testkube.io/execution-id"
+	// TestNameLabel is set on the objects created for the execution to the test name
+	TestNameLabel = "testkube.io/test-name"
+	// ExecutorLabel is set on the objects created for the execution to the executor name
+	ExecutorLabel = "testkube.io/executor"
+)
+
+// ReservedLabels are set by testkube on the objects created for the execution, execute options can't set them
+var ReservedLabels = []string{ExecutionIDLabel, TestNameLabel, ExecutorLabel, ShardOfLabel, ShardIndexLabel}
+
+var invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// SanitizeLabelValue converts the value to a valid label value, characters not allowed are replaced with dash,
+// the value is truncated to validation.LabelValueMaxLength and it starts and ends with alphanumeric character
+func SanitizeLabelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(value, "-")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+
+	return strings.Trim(value, "-_.")
+}
+
+// ExecutionLabels returns labels of the objects created for the execution: the test labels,
+// execute options labels with sanitized values, and the reserved labels identifying the execution
+func ExecutionLabels(options ExecuteOptions) map[string]string {
+	labels := map[string]string{
+		testkube.TestLabelTestType: utils.SanitizeName(options.TestSpec.Type_),
+		testkube.TestLabelExecutor: options.ExecutorName,
+		testkube.TestLabelTestName: options.TestName,
+	}
+	for key, value := range options.Labels {
+		labels[key] = SanitizeLabelValue(value)
+	}
+
+	labels[ExecutionIDLabel] = SanitizeLabelValue(options.ID)
+	labels[TestNameLabel] = SanitizeLabelValue(options.TestName)
+	labels[ExecutorLabel] = SanitizeLabelValue(options.ExecutorName)
+
+	return labels
+}
+
+// ApplyMetadata merges labels and annotations into the object metadata rendered from the template,
+// execution values win for the same key
+func ApplyMetadata(meta *metav1.ObjectMeta, labels, annotations map[string]string) {
+	for key, value := range labels {
+		if meta.Labels == nil {
+			meta.Labels = make(map[string]string)
+		}
+		meta.Labels[key] = value
+	}
+
+	for key, value := range annotations {
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string)
+		}
+		meta.Annotations[key] = value
+	}
+}
+
+// TestOwnerReferences returns owner reference to the test custom resource of the execution,
+// so deleting the test garbage-collects the execution job; none is returned when the test doesn't exist
+// or it's in other namespace, as owner references can't cross namespaces
+func TestOwnerReferences(testsClient testsclientv3.Interface, execution testkube.Execution) []metav1.OwnerReference {
+	if testsClient == nil || execution.TestName == "" {
+		return nil
+	}
+
+	test, err := testsClient.Get(execution.TestName)
+	if err != nil || test == nil || test.UID == "" || test.Namespace != execution.TestNamespace {
+		return nil
+	}
+
+	return []metav1.OwnerReference{{
+		APIVersion: testsv3.GroupVersion.String(),
+		Kind:       testsv3.Resource,
+		Name:       test.Name,
+		UID:        test.UID,
+	}}
+}
+
+func (o ExecuteOptions) validateMetadata() (errs []error) {
+	for _, key := range sortedKeys(o.Labels) {
+		if slices.Contains(ReservedLabels, key) {
+			errs = append(errs, fmt.Errorf("label %q is reserved", key))
+		}
+		if problems := validation.IsQualifiedName(key); len(problems) != 0 {
+			errs = append(errs, fmt.Errorf("label %q name is invalid: %s", key, problems[0]))
+		}
+	}
+
+	for _, key := range sortedKeys(o.Annotations) {
+		if problems := validation.IsQualifiedName(key); len(problems) != 0 {
+			errs = append(errs, fmt.Errorf("annotation %q name is invalid: %s", key, problems[0]))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
+	testsclientv3 "github.com/kubeshop/testkube-operator/pkg/client/tests/v3"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func TestSanitizeLabelValue(t *testing.T) {
+	tests := map[string]string{
+		"":                             "",
+		"valid-Value_1.2":              "valid-Value_1.2",
+		"team/qa a":                    "team-qa-a",
+		"-starts.and.ends_":            "starts.and.ends",
+		"https://testkube.io/x?y":      "https---testkube.io-x-y",
+		strings.Repeat("a", 62) + "-b": strings.Repeat("a", 62),
+	}
+	for value, expected := range tests {
+		sanitized := SanitizeLabelValue(value)
+		assert.Equal(t, expected, sanitized, value)
+		assert.Empty(t, validation.IsValidLabelValue(sanitized), value)
+	}
+}
+
+func TestExecutionLabels(t *testing.T) {
+	options := NewExecuteOptions()
+	options.ID = "exec-1"
+	options.TestName = "k6-test"
+	options.ExecutorName = "k6-executor"
+	options.Labels["team"] = "qa/perf"
+	options.Labels[BatchIDLabel] = "batch-1"
+
+	assert.Equal(t, map[string]string{
+		testkube.TestLabelTestType: "",
+		testkube.TestLabelExecutor: "k6-executor",
+		testkube.TestLabelTestName: "k6-test",
+		"team":                     "qa-perf",
+		BatchIDLabel:               "batch-1",
+		ExecutionIDLabel:           "exec-1",
+		TestNameLabel:              "k6-test",
+		ExecutorLabel:              "k6-executor",
+	}, ExecutionLabels(options))
+}
+
+func TestExecuteOptions_ValidateMetadata(t *testing.T) {
+	options := ExecuteOptions{
+		Labels:      map[string]string{"team": "qa", TestNameLabel: "other", "in valid": "value"},
+		Annotations: map[string]string{"example.com/owner": "qa", "in valid": "value"},
+	}
+
+	errs := options.validateMetadata()
+	require.Len(t, errs, 3)
+	assert.ErrorContains(t, errs[0], `label "in valid" name is invalid`)
+	assert.EqualError(t, errs[1], `label "testkube.io/test-name" is reserved`)
+	assert.ErrorContains(t, errs[2], `annotation "in valid" name is invalid`)
+
+	assert.Empty(t, NewExecuteOptions().validateMetadata())
+	assert.Empty(t, ExecuteOptions{Labels: map[string]string{ChainIDLabel: "chain-1"}}.validateMetadata())
+}
+
+func TestNewJobSpec_Metadata(t *testing.T) {
+	options := NewExecuteOptions()
+	options.ID = "exec"
+	options.TestName = "test"
+	options.Labels["team"] = "qa"
+	options.Annotations["example.com/cost-center"] = "1234"
+
+	jobOptions := NewJobOptionsFromExecutionOptions(options)
+	jobOptions.Name = "exec"
+	jobOptions.JobTemplate = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: "{{ .Name }}"
+  labels:
+    team: template
+  annotations:
+    example.com/template: "true"
+spec:
+  template:
+    spec:
+      containers:
+        - name: "{{ .Name }}"
+          image: executor
+`
+	job, err := NewJobSpec(zap.NewNop().Sugar(), jobOptions)
+	require.NoError(t, err)
+
+	for _, meta := range []metav1.ObjectMeta{job.ObjectMeta, job.Spec.Template.ObjectMeta} {
+		assert.Equal(t, "qa", meta.Labels["team"])
+		assert.Equal(t, "exec", meta.Labels[ExecutionIDLabel])
+		assert.Equal(t, "test", meta.Labels[TestNameLabel])
+		assert.Equal(t, "1234", meta.Annotations["example.com/cost-center"])
+	}
+	assert.Equal(t, "true", job.Annotations["example.com/template"])
+
+	jobOptions.PvcTemplate = `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: "{{ .Name }}-pvc"
+`
+	pvc, err := NewPersistentVolumeClaimSpec(zap.NewNop().Sugar(), NewPVCOptionsFromJobOptions(jobOptions))
+	require.NoError(t, err)
+	assert.Equal(t, "exec", pvc.Labels[ExecutionIDLabel])
+	assert.Equal(t, "1234", pvc.Annotations["example.com/cost-center"])
+}
+
+func TestTestOwnerReferences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testsClient := testsclientv3.NewMockInterface(ctrl)
+	testsClient.EXPECT().Get("test").Return(&testsv3.Test{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "testkube", UID: "uid-1"},
+	}, nil).Times(2)
+	testsClient.EXPECT().Get("missing").Return(nil, errors.New("not found"))
+
+	assert.Equal(t, []metav1.OwnerReference{{APIVersion: "tests.testkube.io/v3", Kind: "Test", Name: "test", UID: "uid-1"}},
+		TestOwnerReferences(testsClient, testkube.Execution{TestName: "test", TestNamespace: "testkube"}))
+	assert.Empty(t, TestOwnerReferences(testsClient, testkube.Execution{TestName: "test", TestNamespace: "other"}))
+	assert.Empty(t, TestOwnerReferences(testsClient, testkube.Execution{TestName: "missing", TestNamespace: "testkube"}))
+	assert.Empty(t, TestOwnerReferences(nil, testkube.Execution{TestName: "test", TestNamespace: "testkube"}))
+}
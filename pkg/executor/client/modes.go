@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// ExecutionGetter reads stored execution state
+type ExecutionGetter interface {
+	Get(ctx context.Context, id string) (testkube.Execution, error)
+}
+
+// ExecutionDetachedError is returned by ExecuteSync when context is done before execution finished,
+// the execution keeps running and can be looked up by ID
+type ExecutionDetachedError struct {
+	ID  string
+	Err error
+}
+
+func (e *ExecutionDetachedError) Error() string {
+	return fmt.Sprintf("stopped waiting for execution %s, it keeps running: %v", e.ID, e.Err)
+}
+
+func (e *ExecutionDetachedError) Unwrap() error {
+	return e.Err
+}
+
+// ExecutionRunner starts executions either asynchronously or waiting for their terminal state
+type ExecutionRunner struct {
+	executor        Executor
+	executions      ExecutionGetter
+	watcher         *Watcher
+	priorityClasses PriorityClasses
+	concurrency     *batchConcurrency
+	cache           *ResultsCache
+	history         ExecutionHistory
+	defaultDuration time.Duration
+}
+
+// NewExecutionRunner creates new execution runner, watcher defines how often execution state is polled in sync mode
+func NewExecutionRunner(executor Executor, executions ExecutionGetter, watcher *Watcher) *ExecutionRunner {
+	return &ExecutionRunner{
+		executor:    executor,
+		executions:  executions,
+		watcher:     watcher,
+		concurrency: newBatchConcurrency(),
+	}
+}
+
+// WithPriorityClasses sets priority class values used to order queued executions
+func (r *ExecutionRunner) WithPriorityClasses(classes PriorityClasses) *ExecutionRunner {
+	r.priorityClasses = classes
+	return r
+}
+
+// WithResultsCache sets the cache used by GetExecution, the cache is invalidated on status changes seen by watching,
+// and on abort of batch executions; executions are always watched without the cache
+func (r *ExecutionRunner) WithResultsCache(cache *ResultsCache) *ExecutionRunner {
+	r.cache = cache
+	return r
+}
+
+// GetExecution returns current state of the execution, through the results cache when it's set
+func (r *ExecutionRunner) GetExecution(ctx context.Context, id string) (testkube.Execution, error) {
+	if r.cache != nil {
+		return r.cache.Get(ctx, id)
+	}
+
+	return r.executions.Get(ctx, id)
+}
+
+// ExecuteAsync starts execution and returns its ID without waiting for the execution to finish,
+// expressions in the execution request are resolved before the execution is submitted
+func (r *ExecutionRunner) ExecuteAsync(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (string, error) {
+	options = options.DeepCopy().Normalize()
+	options.Sync = false
+	if err := ResolveRequest(execution, &options); err != nil {
+		execution.ExecutionResult = testkube.NewRunningExecutionResult().Err(err)
+		return "", err
+	}
+
+	if _, err := r.executor.Execute(ctx, execution, options); err != nil {
+		return "", err
+	}
+
+	return execution.Id, nil
+}
+
+// ExecuteSync starts execution and blocks until it reaches terminal state, returned result carries
+// passed or failed status, when context is done first ExecutionDetachedError is returned
+func (r *ExecutionRunner) ExecuteSync(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	id, err := r.ExecuteAsync(ctx, execution, options)
+	if err != nil {
+		return execution.ExecutionResult, err
+	}
+
+	return r.wait(ctx, execution, id)
+}
+
+// wait watches execution events until execution reaches terminal state
+func (r *ExecutionRunner) wait(ctx context.Context, execution *testkube.Execution, id string) (*testkube.ExecutionResult, error) {
+	events, err := r.WatchExecution(ctx, id)
+	if err != nil {
+		return execution.ExecutionResult, err
+	}
+
+	for event := range events {
+		if event.Err != nil {
+			return execution.ExecutionResult, event.Err
+		}
+
+		if event.IsTerminal() {
+			execution.ExecutionResult = event.Result
+			return event.Result, nil
+		}
+	}
+
+	return execution.ExecutionResult, &ExecutionDetachedError{ID: id, Err: ctx.Err()}
+}
+
+func currentStatus(execution testkube.Execution) testkube.ExecutionStatus {
+	if execution.ExecutionResult == nil || execution.ExecutionResult.Status == nil {
+		return ""
+	}
+
+	return *execution.ExecutionResult.Status
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// completingExecutions reports running execution until it's polled given number of times
+type completingExecutions struct {
+	polls   int
+	after   int
+	running *testkube.ExecutionResult
+	final   *testkube.ExecutionResult
+}
+
+func (e *completingExecutions) Get(ctx context.Context, id string) (testkube.Execution, error) {
+	e.polls++
+	if e.polls < e.after {
+		return testkube.Execution{Id: id, ExecutionResult: e.running}, nil
+	}
+
+	return testkube.Execution{Id: id, ExecutionResult: e.final}, nil
+}
+
+func newTestExecutionRunner(executor Executor, executions ExecutionGetter, clock Clock) *ExecutionRunner {
+	return NewExecutionRunner(executor, executions, NewWatcher(zap.NewNop().Sugar(), WatchOptions{
+		Interval:    100 * time.Millisecond,
+		MaxInterval: time.Second,
+		Multiplier:  2,
+	}, clock))
+}
+
+func expectAsyncExecute(mockExecutor *MockExecutor) {
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+			if options.Sync {
+				return nil, errors.New("expected async execution")
+			}
+			execution.ExecutionResult = testkube.NewRunningExecutionResult()
+			return execution.ExecutionResult, nil
+		})
+}
+
+func TestExecutionRunner_ExecuteAsync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	expectAsyncExecute(mockExecutor)
+	executions := &completingExecutions{after: 3}
+
+	id, err := newTestExecutionRunner(mockExecutor, executions, &recordingClock{}).
+		ExecuteAsync(context.Background(), &testkube.Execution{Id: "exec-1"}, ExecuteOptions{Sync: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "exec-1", id)
+	assert.Equal(t, 0, executions.polls)
+}
+
+func TestExecutionRunner_ExecuteSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	expectAsyncExecute(mockExecutor)
+	clock := &recordingClock{}
+	final := &testkube.ExecutionResult{Status: testkube.ExecutionStatusFailed, ErrorMessage: "2 checks failed"}
+	executions := &completingExecutions{after: 4, running: testkube.NewRunningExecutionResult(), final: final}
+	execution := &testkube.Execution{Id: "exec-1"}
+
+	result, err := newTestExecutionRunner(mockExecutor, executions, clock).ExecuteSync(context.Background(), execution, ExecuteOptions{})
+
+	assert.NoError(t, err)
+	assert.Same(t, final, result)
+	assert.Same(t, final, execution.ExecutionResult)
+	assert.Equal(t, 4, executions.polls)
+	// first running poll is a state change, next polls back off
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}, clock.delays)
+}
+
+func TestExecutionRunner_ExecuteSyncContextDone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	expectAsyncExecute(mockExecutor)
+	executions := &completingExecutions{after: 1000, running: testkube.NewRunningExecutionResult()}
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := newFakeClock(time.Now())
+
+	done := make(chan error)
+	go func() {
+		_, err := newTestExecutionRunner(mockExecutor, executions, clock).ExecuteSync(ctx, &testkube.Execution{Id: "exec-1"}, ExecuteOptions{})
+		done <- err
+	}()
+
+	<-clock.added
+	cancel()
+	err := <-done
+
+	var detached *ExecutionDetachedError
+	assert.ErrorAs(t, err, &detached)
+	assert.Equal(t, "exec-1", detached.ID)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestExecutionRunner_ExecuteSyncStartError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	startErr := errors.New("job creation failed")
+	mockExecutor := NewMockExecutor(ctrl)
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, startErr)
+	executions := &completingExecutions{after: 1}
+
+	_, err := newTestExecutionRunner(mockExecutor, executions, &recordingClock{}).
+		ExecuteSync(context.Background(), &testkube.Execution{Id: "exec-1"}, ExecuteOptions{})
+
+	assert.ErrorIs(t, err, startErr)
+	assert.Equal(t, 0, executions.polls)
+}
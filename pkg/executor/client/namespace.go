@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// ValidateNamespace checks that namespace exists and jobs can be created there,
+// empty namespace falls back to the executor default and is not checked
+func ValidateNamespace(ctx context.Context, clientSet kubernetes.Interface, namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+
+	if _, err := clientSet.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{}); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fmt.Errorf("execution namespace %s does not exist", namespace)
+		}
+
+		return fmt.Errorf("getting execution namespace %s: %w", namespace, err)
+	}
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "create",
+				Group:     "batch",
+				Resource:  "jobs",
+			},
+		},
+	}
+
+	review, err := clientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("checking permissions in execution namespace %s: %w", namespace, err)
+	}
+
+	if !review.Status.Allowed {
+		return fmt.Errorf("not allowed to create jobs in execution namespace %s: %s", namespace, review.Status.Reason)
+	}
+
+	return nil
+}
+
+// ValidateSecretNamespaces rejects secrets referenced from other namespace than the execution one,
+// as pods can't mount secrets across namespaces
+func ValidateSecretNamespaces(execution testkube.Execution, options ExecuteOptions) error {
+	namespace := execution.TestNamespace
+	if namespace == "" {
+		return nil
+	}
+
+	for env, secretName := range options.Request.SecretEnvs {
+		if secretNamespace, _, found := strings.Cut(secretName, "/"); found && secretNamespace != namespace {
+			return fmt.Errorf("secret env %s references secret %s from namespace %s, only secrets from execution namespace %s can be used",
+				env, secretName, secretNamespace, namespace)
+		}
+	}
+
+	for name, variable := range execution.Variables {
+		if variable.SecretRef != nil && variable.SecretRef.Namespace != "" && variable.SecretRef.Namespace != namespace {
+			return fmt.Errorf("variable %s references secret %s from namespace %s, only secrets from execution namespace %s can be used",
+				name, variable.SecretRef.Name, variable.SecretRef.Namespace, namespace)
+		}
+	}
+
+	for _, secretRef := range []*testkube.SecretRef{options.UsernameSecret, options.TokenSecret} {
+		if secretRef != nil && secretRef.Namespace != "" && secretRef.Namespace != namespace {
+			return fmt.Errorf("git credentials reference secret %s from namespace %s, only secrets from execution namespace %s can be used",
+				secretRef.Name, secretRef.Namespace, namespace)
+		}
+	}
+
+	return nil
+}
+
+// ValidatePodReferences checks that service account and image pull secrets used by execution pod exist
+// in execution namespace, so missing references fail the execution right away instead of leaving pod pending,
+// executor default service account is not checked as it's managed together with the executor
+func ValidatePodReferences(ctx context.Context, clientSet kubernetes.Interface, namespace string, options ExecuteOptions) error {
+	if namespace == "" {
+		return nil
+	}
+
+	var errs []error
+	if options.ServiceAccountName != "" {
+		if _, err := clientSet.CoreV1().ServiceAccounts(namespace).Get(ctx, options.ServiceAccountName, metav1.GetOptions{}); err != nil {
+			if k8serrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("service account %s does not exist in execution namespace %s", options.ServiceAccountName, namespace))
+			} else {
+				errs = append(errs, fmt.Errorf("getting service account %s: %w", options.ServiceAccountName, err))
+			}
+		}
+	}
+
+	for _, name := range options.ImagePullSecretNames {
+		if _, err := clientSet.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+			if k8serrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("image pull secret %s does not exist in execution namespace %s", name, namespace))
+			} else {
+				errs = append(errs, fmt.Errorf("getting image pull secret %s: %w", name, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
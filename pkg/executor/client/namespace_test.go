@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func fakeNamespaceClient(allowed bool) *fake.Clientset {
+	clientSet := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tests"}})
+	clientSet.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = allowed
+		if !allowed {
+			review.Status.Reason = "forbidden by RBAC"
+		}
+		return true, review, nil
+	})
+	return clientSet
+}
+
+func TestValidateNamespace(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("default namespace fallback", func(t *testing.T) {
+		assert.NoError(t, ValidateNamespace(ctx, fake.NewSimpleClientset(), ""))
+	})
+
+	t.Run("explicit namespace", func(t *testing.T) {
+		assert.NoError(t, ValidateNamespace(ctx, fakeNamespaceClient(true), "tests"))
+	})
+
+	t.Run("missing namespace", func(t *testing.T) {
+		err := ValidateNamespace(ctx, fakeNamespaceClient(true), "other")
+		assert.EqualError(t, err, "execution namespace other does not exist")
+	})
+
+	t.Run("denied namespace", func(t *testing.T) {
+		err := ValidateNamespace(ctx, fakeNamespaceClient(false), "tests")
+		assert.EqualError(t, err, "not allowed to create jobs in execution namespace tests: forbidden by RBAC")
+	})
+}
+
+func TestValidateSecretNamespaces(t *testing.T) {
+	execution := testkube.Execution{TestNamespace: "tests"}
+
+	t.Run("same namespace", func(t *testing.T) {
+		execution := execution
+		execution.Variables = map[string]testkube.Variable{
+			"token": {Name: "token", SecretRef: &testkube.SecretRef{Namespace: "tests", Name: "secret", Key: "token"}},
+		}
+		options := ExecuteOptions{Request: testkube.ExecutionRequest{SecretEnvs: map[string]string{"TOKEN": "secret"}}}
+
+		assert.NoError(t, ValidateSecretNamespaces(execution, options))
+	})
+
+	t.Run("cross namespace secret env", func(t *testing.T) {
+		options := ExecuteOptions{Request: testkube.ExecutionRequest{SecretEnvs: map[string]string{"TOKEN": "other/secret"}}}
+
+		assert.ErrorContains(t, ValidateSecretNamespaces(execution, options), "secret env TOKEN references secret other/secret from namespace other")
+	})
+
+	t.Run("cross namespace variable", func(t *testing.T) {
+		execution := execution
+		execution.Variables = map[string]testkube.Variable{
+			"token": {Name: "token", SecretRef: &testkube.SecretRef{Namespace: "other", Name: "secret", Key: "token"}},
+		}
+
+		assert.ErrorContains(t, ValidateSecretNamespaces(execution, ExecuteOptions{}), "variable token references secret secret from namespace other")
+	})
+
+	t.Run("cross namespace git credentials", func(t *testing.T) {
+		options := ExecuteOptions{TokenSecret: &testkube.SecretRef{Namespace: "other", Name: "git", Key: "token"}}
+
+		assert.ErrorContains(t, ValidateSecretNamespaces(execution, options), "git credentials reference secret git from namespace other")
+	})
+}
+
+func TestValidatePodReferences(t *testing.T) {
+	ctx := context.Background()
+	clientSet := fake.NewSimpleClientset(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "gcs-writer", Namespace: "tests"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "tests"}},
+	)
+
+	t.Run("executor defaults", func(t *testing.T) {
+		assert.NoError(t, ValidatePodReferences(ctx, clientSet, "tests", ExecuteOptions{}))
+	})
+
+	t.Run("existing references", func(t *testing.T) {
+		options := ExecuteOptions{ServiceAccountName: "gcs-writer", ImagePullSecretNames: []string{"registry"}}
+
+		assert.NoError(t, ValidatePodReferences(ctx, clientSet, "tests", options))
+	})
+
+	t.Run("missing references", func(t *testing.T) {
+		options := ExecuteOptions{ServiceAccountName: "s3-writer", ImagePullSecretNames: []string{"registry", "private-registry"}}
+
+		err := ValidatePodReferences(ctx, clientSet, "tests", options)
+		assert.EqualError(t, err, "service account s3-writer does not exist in execution namespace tests\n"+
+			"image pull secret private-registry does not exist in execution namespace tests")
+	})
+
+	t.Run("references from other namespace", func(t *testing.T) {
+		options := ExecuteOptions{ServiceAccountName: "gcs-writer"}
+
+		assert.ErrorContains(t, ValidatePodReferences(ctx, clientSet, "other", options), "service account gcs-writer does not exist in execution namespace other")
+	})
+}
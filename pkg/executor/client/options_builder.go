@@ -0,0 +1,269 @@
+package client
+
+import (
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
+	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/scraper"
+	"github.com/kubeshop/testkube/pkg/featureflags"
+)
+
+// ExecuteOptionsBuilder assembles execute options and validates them on build
+type ExecuteOptionsBuilder struct {
+	options      ExecuteOptions
+	capabilities *Capabilities
+	strictness   CapabilitiesStrictness
+	warnings     error
+}
+
+// NewExecuteOptionsBuilder creates new execute options builder
+func NewExecuteOptionsBuilder() *ExecuteOptionsBuilder {
+	return &ExecuteOptionsBuilder{options: NewExecuteOptions()}
+}
+
+// WithID sets execution id
+func (b *ExecuteOptionsBuilder) WithID(id string) *ExecuteOptionsBuilder {
+	b.options.ID = id
+	return b
+}
+
+// WithTest sets test name and spec
+func (b *ExecuteOptionsBuilder) WithTest(name string, spec testsv3.TestSpec) *ExecuteOptionsBuilder {
+	b.options.TestName = name
+	b.options.TestSpec = spec
+	return b
+}
+
+// WithExecutor sets executor name and spec
+func (b *ExecuteOptionsBuilder) WithExecutor(name string, spec executorv1.ExecutorSpec) *ExecuteOptionsBuilder {
+	b.options.ExecutorName = name
+	b.options.ExecutorSpec = spec
+	return b
+}
+
+// WithNamespace sets execution namespace
+func (b *ExecuteOptionsBuilder) WithNamespace(namespace string) *ExecuteOptionsBuilder {
+	b.options.Namespace = namespace
+	return b
+}
+
+// WithRequest sets execution request
+func (b *ExecuteOptionsBuilder) WithRequest(request testkube.ExecutionRequest) *ExecuteOptionsBuilder {
+	b.options.Request = request
+	return b
+}
+
+// WithSync sets if execution should wait for the result
+func (b *ExecuteOptionsBuilder) WithSync(sync bool) *ExecuteOptionsBuilder {
+	b.options.Sync = sync
+	return b
+}
+
+// WithLabels sets execution labels
+func (b *ExecuteOptionsBuilder) WithLabels(labels map[string]string) *ExecuteOptionsBuilder {
+	b.options.Labels = labels
+	return b
+}
+
+// WithAnnotations sets annotations of the objects created for the execution
+func (b *ExecuteOptionsBuilder) WithAnnotations(annotations map[string]string) *ExecuteOptionsBuilder {
+	b.options.Annotations = annotations
+	return b
+}
+
+// WithOutputFormat sets the format of the execution container output
+func (b *ExecuteOptionsBuilder) WithOutputFormat(format OutputFormat) *ExecuteOptionsBuilder {
+	b.options.OutputFormat = format
+	return b
+}
+
+// WithSharding splits the execution into parallel jobs
+func (b *ExecuteOptionsBuilder) WithSharding(sharding *Sharding) *ExecuteOptionsBuilder {
+	b.options.Sharding = sharding
+	return b
+}
+
+// WithGitCredentials sets git username and token secrets
+func (b *ExecuteOptionsBuilder) WithGitCredentials(usernameSecret, tokenSecret *testkube.SecretRef) *ExecuteOptionsBuilder {
+	b.options.UsernameSecret = usernameSecret
+	b.options.TokenSecret = tokenSecret
+	return b
+}
+
+// WithImagePullSecrets sets image pull secret names
+func (b *ExecuteOptionsBuilder) WithImagePullSecrets(names ...string) *ExecuteOptionsBuilder {
+	b.options.ImagePullSecretNames = names
+	return b
+}
+
+// WithFeatures sets feature flags
+func (b *ExecuteOptionsBuilder) WithFeatures(features featureflags.FeatureFlags) *ExecuteOptionsBuilder {
+	b.options.Features = features
+	return b
+}
+
+// WithTimeout sets execution timeout
+func (b *ExecuteOptionsBuilder) WithTimeout(timeout time.Duration) *ExecuteOptionsBuilder {
+	b.options.Timeout = timeout
+	return b
+}
+
+// WithResources sets execution container resources
+func (b *ExecuteOptionsBuilder) WithResources(resources Resources) *ExecuteOptionsBuilder {
+	b.options.Resources = &resources
+	return b
+}
+
+// WithRetryPolicy sets execution retry policy
+func (b *ExecuteOptionsBuilder) WithRetryPolicy(policy RetryPolicy) *ExecuteOptionsBuilder {
+	b.options.RetryPolicy = &policy
+	return b
+}
+
+// WithNodeSelector sets execution pod node selector
+func (b *ExecuteOptionsBuilder) WithNodeSelector(nodeSelector map[string]string) *ExecuteOptionsBuilder {
+	b.options.NodeSelector = nodeSelector
+	return b
+}
+
+// WithTolerations sets execution pod tolerations
+func (b *ExecuteOptionsBuilder) WithTolerations(tolerations ...corev1.Toleration) *ExecuteOptionsBuilder {
+	b.options.Tolerations = tolerations
+	return b
+}
+
+// WithAffinity sets execution pod affinity
+func (b *ExecuteOptionsBuilder) WithAffinity(affinity corev1.Affinity) *ExecuteOptionsBuilder {
+	b.options.Affinity = &affinity
+	return b
+}
+
+// WithServiceAccountName sets execution pod service account
+func (b *ExecuteOptionsBuilder) WithServiceAccountName(name string) *ExecuteOptionsBuilder {
+	b.options.ServiceAccountName = name
+	return b
+}
+
+// WithCommand sets execution container command
+func (b *ExecuteOptionsBuilder) WithCommand(command ...string) *ExecuteOptionsBuilder {
+	b.options.Command = command
+	return b
+}
+
+// WithArgs sets execution container args and how they are combined with the defaults
+func (b *ExecuteOptionsBuilder) WithArgs(mode testkube.ArgsModeType, args ...string) *ExecuteOptionsBuilder {
+	b.options.ArgsMode = mode
+	b.options.Args = args
+	return b
+}
+
+// WithWorkingDir sets execution container working directory
+func (b *ExecuteOptionsBuilder) WithWorkingDir(workingDir string) *ExecuteOptionsBuilder {
+	b.options.WorkingDir = workingDir
+	return b
+}
+
+// WithArtifactRequest sets files collected as execution artifacts
+func (b *ExecuteOptionsBuilder) WithArtifactRequest(request scraper.ArtifactRequest) *ExecuteOptionsBuilder {
+	b.options.ArtifactRequest = &request
+	return b
+}
+
+// WithPriority sets execution priority value used to order queued executions
+func (b *ExecuteOptionsBuilder) WithPriority(value int32) *ExecuteOptionsBuilder {
+	if b.options.Priority == nil {
+		b.options.Priority = &Priority{}
+	}
+	b.options.Priority.Value = value
+	return b
+}
+
+// WithPriorityClass sets execution pod priority class, which has to be allowed by the executor
+func (b *ExecuteOptionsBuilder) WithPriorityClass(className string) *ExecuteOptionsBuilder {
+	if b.options.Priority == nil {
+		b.options.Priority = &Priority{}
+	}
+	b.options.Priority.ClassName = className
+	return b
+}
+
+// WithRunAfter sets the earliest execution start time
+func (b *ExecuteOptionsBuilder) WithRunAfter(at time.Time) *ExecuteOptionsBuilder {
+	b.options.RunAfter = at
+	return b
+}
+
+// WithDelay postpones execution start by the duration
+func (b *ExecuteOptionsBuilder) WithDelay(delay time.Duration) *ExecuteOptionsBuilder {
+	b.options.Delay = delay
+	return b
+}
+
+// WithIdempotencyKey sets key deduplicating repeated submissions of the same execution
+func (b *ExecuteOptionsBuilder) WithIdempotencyKey(key string) *ExecuteOptionsBuilder {
+	b.options.IdempotencyKey = key
+	return b
+}
+
+// WithServices adds service containers started alongside the test container
+func (b *ExecuteOptionsBuilder) WithServices(services ...Service) *ExecuteOptionsBuilder {
+	b.options.Services = append(b.options.Services, services...)
+	return b
+}
+
+// WithEnv sets execution container environment variable
+func (b *ExecuteOptionsBuilder) WithEnv(name, value string) *ExecuteOptionsBuilder {
+	b.options.Envs[name] = value
+	return b
+}
+
+// WithSecretEnvs adds secret keys, or whole secrets, exposed as execution container environment variables
+func (b *ExecuteOptionsBuilder) WithSecretEnvs(refs ...SecretRef) *ExecuteOptionsBuilder {
+	b.options.SecretEnvs = append(b.options.SecretEnvs, refs...)
+	return b
+}
+
+// WithConfigMapEnvs adds config map keys, or whole config maps, exposed as execution container environment variables
+func (b *ExecuteOptionsBuilder) WithConfigMapEnvs(refs ...ConfigMapRef) *ExecuteOptionsBuilder {
+	b.options.ConfigMapEnvs = append(b.options.ConfigMapEnvs, refs...)
+	return b
+}
+
+// WithCapabilities checks options against capabilities of the selected executor on build,
+// unsupported options fail the build in strict mode and are reported as warnings otherwise
+func (b *ExecuteOptionsBuilder) WithCapabilities(capabilities Capabilities, strictness CapabilitiesStrictness) *ExecuteOptionsBuilder {
+	b.capabilities = &capabilities
+	b.strictness = strictness
+	return b
+}
+
+// Build returns execute options, or all validation problems joined together
+func (b *ExecuteOptionsBuilder) Build() (ExecuteOptions, error) {
+	b.warnings = nil
+	err := b.options.Validate()
+	if b.capabilities != nil {
+		if capabilitiesErr := b.options.ValidateCapabilities(*b.capabilities); capabilitiesErr != nil {
+			if b.strictness == CapabilitiesStrict {
+				err = errors.Join(err, capabilitiesErr)
+			} else {
+				b.warnings = capabilitiesErr
+			}
+		}
+	}
+
+	if err != nil {
+		return ExecuteOptions{}, err
+	}
+
+	return b.options, nil
+}
+
+// Warnings returns options unsupported by the executor found by the last build in warn mode
+func (b *ExecuteOptionsBuilder) Warnings() error {
+	return b.warnings
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
+	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/scraper"
+)
+
+func validBuilder() *ExecuteOptionsBuilder {
+	return NewExecuteOptionsBuilder().
+		WithID("exec").
+		WithTest("test", testsv3.TestSpec{
+			Type_:   "k6/script",
+			Content: &testsv3.TestContent{Type_: "string", Data: "script"},
+		}).
+		WithExecutor("k6", executorv1.ExecutorSpec{
+			ExecutorType: executorv1.ExecutorTypeJob,
+			Types:        []string{"k6/script"},
+			ContentTypes: []executorv1.ScriptContentType{"string", "git"},
+			Image:        "kubeshop/testkube-k6-executor",
+		})
+}
+
+func TestExecuteOptionsBuilder_Build(t *testing.T) {
+	options, err := validBuilder().
+		WithNamespace("tests").
+		WithSync(true).
+		WithTimeout(time.Minute).
+		WithLabels(map[string]string{"team": "qa"}).
+		Build()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "exec", options.ID)
+	assert.Equal(t, "test", options.TestName)
+	assert.Equal(t, "k6", options.ExecutorName)
+	assert.Equal(t, "tests", options.Namespace)
+	assert.True(t, options.Sync)
+	assert.Equal(t, time.Minute, options.Timeout)
+	assert.Equal(t, map[string]string{"team": "qa"}, options.Labels)
+}
+
+func TestExecuteOptionsBuilder_Rules(t *testing.T) {
+	tests := map[string]struct {
+		builder *ExecuteOptionsBuilder
+		err     string
+	}{
+		"missing id": {
+			builder: validBuilder().WithID(""),
+			err:     "execution id is required",
+		},
+		"missing test name": {
+			builder: validBuilder().WithTest("", testsv3.TestSpec{}),
+			err:     "test name is required",
+		},
+		"negative timeout": {
+			builder: validBuilder().WithTimeout(-time.Second),
+			err:     ErrNegativeTimeout.Error(),
+		},
+		"unknown executor type": {
+			builder: validBuilder().WithExecutor("rest", executorv1.ExecutorSpec{ExecutorType: "rest"}),
+			err:     "unknown executor type rest",
+		},
+		"container executor without image": {
+			builder: validBuilder().WithExecutor("curl", executorv1.ExecutorSpec{ExecutorType: executorv1.ExecutorTypeContainer}),
+			err:     "container executor curl requires image",
+		},
+		"grpc executor without uri": {
+			builder: validBuilder().WithExecutor("farm", executorv1.ExecutorSpec{ExecutorType: ExecutorTypeGRPC}),
+			err:     "grpc executor farm requires uri",
+		},
+		"unsupported test type": {
+			builder: validBuilder().WithExecutor("curl", executorv1.ExecutorSpec{Types: []string{"curl/test"}}),
+			err:     "executor curl doesn't support test type k6/script",
+		},
+		"unsupported content type": {
+			builder: validBuilder().WithExecutor("k6", executorv1.ExecutorSpec{ContentTypes: []executorv1.ScriptContentType{"git"}}),
+			err:     "executor k6 doesn't support content type string",
+		},
+		"repository content request without git content": {
+			builder: validBuilder().WithRequest(testkube.ExecutionRequest{
+				ContentRequest: &testkube.TestContentRequest{Repository: &testkube.RepositoryParameters{Branch: "main"}},
+			}),
+			err: "repository content request requires test with git content",
+		},
+		"mutually exclusive content sources": {
+			builder: validBuilder().WithTest("test", testsv3.TestSpec{
+				Content: &testsv3.TestContent{Data: "script", Uri: "https://example.com/script.js"},
+			}),
+			err: "test content data, uri and repository are mutually exclusive",
+		},
+		"invalid resources": {
+			builder: validBuilder().WithResources(Resources{Requests: ResourceList{CPU: "lots"}}),
+			err:     "invalid cpu request quantity lots",
+		},
+		"invalid retry policy": {
+			builder: validBuilder().WithRetryPolicy(RetryPolicy{}),
+			err:     "retry policy max attempts should be at least 1",
+		},
+		"invalid args mode": {
+			builder: validBuilder().WithArgs("prepend", "--grep", "smoke"),
+			err:     "unknown args mode prepend",
+		},
+		"invalid toleration operator": {
+			builder: validBuilder().WithTolerations(corev1.Toleration{Key: "nvidia.com/gpu", Operator: "In"}),
+			err:     "toleration 0 has unknown operator In",
+		},
+		"system priority class": {
+			builder: validBuilder().WithPriorityClass("system-cluster-critical"),
+			err:     "system priority class system-cluster-critical can't be used for executions",
+		},
+		"negative delay": {
+			builder: validBuilder().WithDelay(-time.Minute),
+			err:     "execution delay can't be negative",
+		},
+		"artifact request without patterns": {
+			builder: validBuilder().WithArtifactRequest(scraper.ArtifactRequest{MaxSize: 1 << 20}),
+			err:     "artifact request should have at least one including pattern",
+		},
+		"secret env without name": {
+			builder: validBuilder().WithSecretEnvs(SecretRef{Key: "TOKEN"}),
+			err:     "secret env 0 name is required",
+		},
+		"invalid env name": {
+			builder: validBuilder().WithEnv("1=2", "value"),
+			err:     `env "1=2" name is invalid`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestExecuteOptionsBuilder_CollectsAllProblems(t *testing.T) {
+	_, err := NewExecuteOptionsBuilder().
+		WithTimeout(-time.Second).
+		WithExecutor("rest", executorv1.ExecutorSpec{ExecutorType: "rest"}).
+		Build()
+
+	assert.EqualError(t, err, "execution id is required\n"+
+		"test name is required\n"+
+		"execution timeout can't be negative\n"+
+		"unknown executor type rest")
+}
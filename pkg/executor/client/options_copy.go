@@ -0,0 +1,216 @@
+package client
+
+import (
+	"encoding/json"
+	"maps"
+	"regexp"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	commonv1 "github.com/kubeshop/testkube-operator/api/common/v1"
+	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
+	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/scraper"
+	"github.com/kubeshop/testkube/pkg/featureflags"
+)
+
+// RedactedValue replaces sensitive values in redacted execute options
+const RedactedValue = "********"
+
+// DefaultSensitiveKeyPattern matches env, variable and label names which values are masked by Redacted
+var DefaultSensitiveKeyPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api[_-]?key|private[_-]?key|credential|auth)`)
+
+// DeepCopy returns execute options which don't share maps, slices and pointers with the original,
+// so the copy can be modified or passed to another goroutine
+func (o ExecuteOptions) DeepCopy() ExecuteOptions {
+	result := o
+	result.TestSpec = *o.TestSpec.DeepCopy()
+	result.ExecutorSpec = *o.ExecutorSpec.DeepCopy()
+	result.Request = *o.Request.GetDeepCopy()
+	result.Labels = maps.Clone(o.Labels)
+	result.ImagePullSecretNames = slices.Clone(o.ImagePullSecretNames)
+	result.NodeSelector = maps.Clone(o.NodeSelector)
+	result.Command = slices.Clone(o.Command)
+	result.Args = slices.Clone(o.Args)
+	result.Envs = maps.Clone(o.Envs)
+	result.SecretEnvs = slices.Clone(o.SecretEnvs)
+	result.ConfigMapEnvs = slices.Clone(o.ConfigMapEnvs)
+	result.Annotations = maps.Clone(o.Annotations)
+	result.FieldOrigins = slices.Clone(o.FieldOrigins)
+
+	if o.UsernameSecret != nil {
+		usernameSecret := *o.UsernameSecret
+		result.UsernameSecret = &usernameSecret
+	}
+
+	if o.TokenSecret != nil {
+		tokenSecret := *o.TokenSecret
+		result.TokenSecret = &tokenSecret
+	}
+
+	if o.Resources != nil {
+		resources := *o.Resources
+		result.Resources = &resources
+	}
+
+	if o.RetryPolicy != nil {
+		retryPolicy := *o.RetryPolicy
+		result.RetryPolicy = &retryPolicy
+	}
+
+	if o.Tolerations != nil {
+		result.Tolerations = make([]corev1.Toleration, len(o.Tolerations))
+		for i := range o.Tolerations {
+			result.Tolerations[i] = *o.Tolerations[i].DeepCopy()
+		}
+	}
+
+	if o.Affinity != nil {
+		result.Affinity = o.Affinity.DeepCopy()
+	}
+
+	if o.ArtifactRequest != nil {
+		artifactRequest := *o.ArtifactRequest
+		artifactRequest.Patterns = slices.Clone(o.ArtifactRequest.Patterns)
+		result.ArtifactRequest = &artifactRequest
+	}
+
+	if o.Priority != nil {
+		priority := *o.Priority
+		result.Priority = &priority
+	}
+
+	if o.Sharding != nil {
+		sharding := *o.Sharding
+		sharding.Envs = maps.Clone(o.Sharding.Envs)
+		result.Sharding = &sharding
+	}
+
+	if o.Services != nil {
+		result.Services = make([]Service, len(o.Services))
+		for i, service := range o.Services {
+			service.Env = maps.Clone(service.Env)
+			service.Ports = slices.Clone(service.Ports)
+			service.ReadinessCommand = slices.Clone(service.ReadinessCommand)
+			result.Services[i] = service
+		}
+	}
+
+	return result
+}
+
+// Redacted returns a copy of execute options safe for logging, with secret env values,
+// secret variable values and values of keys matching DefaultSensitiveKeyPattern masked
+func (o ExecuteOptions) Redacted() ExecuteOptions {
+	return o.RedactedWith(DefaultSensitiveKeyPattern)
+}
+
+// RedactedWith returns a copy of execute options safe for logging, with secret env values,
+// secret variable values and values of keys matching the sensitive key pattern masked
+func (o ExecuteOptions) RedactedWith(sensitiveKey *regexp.Regexp) ExecuteOptions {
+	result := o.DeepCopy()
+	redactMap(result.Labels, sensitiveKey)
+	redactMap(result.Envs, sensitiveKey)
+	redactMap(result.Annotations, sensitiveKey)
+
+	redactMap(result.Request.ExecutionLabels, sensitiveKey)
+	redactMap(result.Request.Envs, sensitiveKey)
+	redactMap(result.Request.SecretEnvs, nil)
+	for name, variable := range result.Request.Variables {
+		if variable.Value != "" && ((variable.Type_ != nil && variable.IsSecret()) || isSensitiveKey(name, sensitiveKey)) {
+			variable.Value = RedactedValue
+			result.Request.Variables[name] = variable
+		}
+	}
+
+	if request := result.TestSpec.ExecutionRequest; request != nil {
+		redactMap(request.ExecutionLabels, sensitiveKey)
+		redactMap(request.Envs, sensitiveKey)
+		redactMap(request.SecretEnvs, nil)
+		for name, variable := range request.Variables {
+			if variable.Value != "" && (variable.Type_ == commonv1.VariableTypeSecret || isSensitiveKey(name, sensitiveKey)) {
+				variable.Value = RedactedValue
+				request.Variables[name] = variable
+			}
+		}
+	}
+
+	return result
+}
+
+// redactMap masks values of sensitive keys, all values are masked when the pattern is nil
+func redactMap(values map[string]string, sensitiveKey *regexp.Regexp) {
+	for key, value := range values {
+		if value != "" && (sensitiveKey == nil || isSensitiveKey(key, sensitiveKey)) {
+			values[key] = RedactedValue
+		}
+	}
+}
+
+func isSensitiveKey(key string, sensitiveKey *regexp.Regexp) bool {
+	return sensitiveKey != nil && sensitiveKey.MatchString(key)
+}
+
+// executeOptionsJSON defines stable JSON representation of execute options,
+// it has to keep the same fields as ExecuteOptions to be convertible
+type executeOptionsJSON struct {
+	ID                   string                    `json:"id,omitempty"`
+	TestName             string                    `json:"testName,omitempty"`
+	Namespace            string                    `json:"namespace,omitempty"`
+	TestSpec             testsv3.TestSpec          `json:"testSpec"`
+	ExecutorName         string                    `json:"executorName,omitempty"`
+	ExecutorSpec         executorv1.ExecutorSpec   `json:"executorSpec"`
+	Request              testkube.ExecutionRequest `json:"request"`
+	Sync                 bool                      `json:"sync,omitempty"`
+	Labels               map[string]string         `json:"labels,omitempty"`
+	UsernameSecret       *testkube.SecretRef       `json:"usernameSecret,omitempty"`
+	TokenSecret          *testkube.SecretRef       `json:"tokenSecret,omitempty"`
+	RunnerCustomCASecret string                    `json:"runnerCustomCASecret,omitempty"`
+	CertificateSecret    string                    `json:"certificateSecret,omitempty"`
+	AgentAPITLSSecret    string                    `json:"agentAPITLSSecret,omitempty"`
+	ImagePullSecretNames []string                  `json:"imagePullSecretNames,omitempty"`
+	Features             featureflags.FeatureFlags `json:"features"`
+	Timeout              time.Duration             `json:"timeout,omitempty"`
+	Resources            *Resources                `json:"resources,omitempty"`
+	RetryPolicy          *RetryPolicy              `json:"retryPolicy,omitempty"`
+	NodeSelector         map[string]string         `json:"nodeSelector,omitempty"`
+	Tolerations          []corev1.Toleration       `json:"tolerations,omitempty"`
+	Affinity             *corev1.Affinity          `json:"affinity,omitempty"`
+	ServiceAccountName   string                    `json:"serviceAccountName,omitempty"`
+	Command              []string                  `json:"command,omitempty"`
+	Args                 []string                  `json:"args,omitempty"`
+	ArgsMode             testkube.ArgsModeType     `json:"argsMode,omitempty"`
+	WorkingDir           string                    `json:"workingDir,omitempty"`
+	ArtifactRequest      *scraper.ArtifactRequest  `json:"artifactRequest,omitempty"`
+	Priority             *Priority                 `json:"priority,omitempty"`
+	RunAfter             time.Time                 `json:"runAfter,omitempty"`
+	Delay                time.Duration             `json:"delay,omitempty"`
+	IdempotencyKey       string                    `json:"idempotencyKey,omitempty"`
+	Services             []Service                 `json:"services,omitempty"`
+	Envs                 map[string]string         `json:"envs,omitempty"`
+	SecretEnvs           []SecretRef               `json:"secretEnvs,omitempty"`
+	ConfigMapEnvs        []ConfigMapRef            `json:"configMapEnvs,omitempty"`
+	Annotations          map[string]string         `json:"annotations,omitempty"`
+	OutputFormat         OutputFormat              `json:"outputFormat,omitempty"`
+	Sharding             *Sharding                 `json:"sharding,omitempty"`
+	FieldOrigins         []FieldOrigin             `json:"fieldOrigins,omitempty"`
+}
+
+// MarshalJSON encodes execute options with stable field names, secrets are included, use Redacted for logging
+func (o ExecuteOptions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(executeOptionsJSON(o))
+}
+
+// UnmarshalJSON decodes execute options encoded by MarshalJSON
+func (o *ExecuteOptions) UnmarshalJSON(data []byte) error {
+	var options executeOptionsJSON
+	if err := json.Unmarshal(data, &options); err != nil {
+		return err
+	}
+
+	*o = ExecuteOptions(options)
+	return nil
+}
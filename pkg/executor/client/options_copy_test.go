@@ -0,0 +1,299 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	commonv1 "github.com/kubeshop/testkube-operator/api/common/v1"
+	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
+	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/scraper"
+	"github.com/kubeshop/testkube/pkg/featureflags"
+)
+
+const propertyIterations = 50
+
+// randomExecuteOptions fills every execute options field, so copying and encoding is checked for all of them
+func randomExecuteOptions(r *rand.Rand) ExecuteOptions {
+	word := func(prefix string) string {
+		return fmt.Sprintf("%s-%d", prefix, r.Intn(1000))
+	}
+	words := func(prefix string) []string {
+		values := make([]string, 1+r.Intn(3))
+		for i := range values {
+			values[i] = word(prefix)
+		}
+		return values
+	}
+	labels := func(prefix string) map[string]string {
+		values := map[string]string{}
+		for i := 0; i <= r.Intn(3); i++ {
+			values[word(prefix)] = word("value")
+		}
+		return values
+	}
+	seconds := int64(r.Intn(600))
+	secretType := testkube.SECRET_VariableType
+
+	return ExecuteOptions{
+		ID:        word("exec"),
+		TestName:  word("test"),
+		Namespace: word("namespace"),
+		TestSpec: testsv3.TestSpec{
+			Type_: "k6/script",
+			Name:  word("test"),
+			ExecutionRequest: &testsv3.ExecutionRequest{
+				Image:           word("image"),
+				ExecutionLabels: labels("label"),
+				Envs:            labels("ENV"),
+				SecretEnvs:      labels("SECRET"),
+				Variables: map[string]testsv3.Variable{
+					"PASSWORD": {Type_: commonv1.VariableTypeSecret, Name: "PASSWORD", Value: word("password")},
+				},
+			},
+		},
+		ExecutorName: word("executor"),
+		ExecutorSpec: executorv1.ExecutorSpec{
+			Types:        words("type"),
+			ExecutorType: executorv1.ExecutorTypeJob,
+			Image:        word("image"),
+			Args:         words("--arg"),
+			Command:      words("cmd"),
+		},
+		Request: testkube.ExecutionRequest{
+			Name:            word("execution"),
+			ExecutionLabels: labels("label"),
+			Variables: map[string]testkube.Variable{
+				"TOKEN": {Name: "TOKEN", Value: word("token"), Type_: &secretType, SecretRef: &testkube.SecretRef{Name: word("secret"), Key: "token"}},
+			},
+			Command:          words("cmd"),
+			Args:             words("--arg"),
+			ImagePullSecrets: []testkube.LocalObjectReference{{Name: word("pull-secret")}},
+			Envs:             labels("ENV"),
+			SecretEnvs:       labels("SECRET"),
+			ArtifactRequest:  &testkube.ArtifactRequest{Dirs: words("/data"), Masks: words(".*")},
+			ContentRequest:   &testkube.TestContentRequest{Repository: &testkube.RepositoryParameters{Branch: word("branch")}},
+			EnvConfigMaps:    []testkube.EnvReference{{Reference: &testkube.LocalObjectReference{Name: word("config")}}},
+			RunningContext:   &testkube.RunningContext{Type_: "scheduler", Context: word("context")},
+			SlavePodRequest: &testkube.PodRequest{Resources: &testkube.PodResourcesRequest{
+				Requests: &testkube.ResourceRequest{Cpu: "100m"},
+				Limits:   &testkube.ResourceRequest{Memory: "1Gi"},
+			}},
+			NodeSelector: labels("node"),
+			Sharding:     &testkube.ExecutionSharding{Count: int32(2 + r.Intn(10)), Envs: labels("SHARD"), Merge: string(ShardMergeNone)},
+		},
+		Sync:                 true,
+		Labels:               labels("label"),
+		UsernameSecret:       &testkube.SecretRef{Name: word("secret"), Key: "username"},
+		TokenSecret:          &testkube.SecretRef{Name: word("secret"), Key: "token"},
+		RunnerCustomCASecret: word("ca"),
+		CertificateSecret:    word("cert"),
+		AgentAPITLSSecret:    word("tls"),
+		ImagePullSecretNames: words("pull-secret"),
+		Features:             featureflags.FeatureFlags{LogsV2: true},
+		Timeout:              time.Duration(1+r.Intn(600)) * time.Second,
+		Resources:            &Resources{Requests: ResourceList{CPU: "250m"}, Limits: ResourceList{Memory: "512Mi"}},
+		RetryPolicy:          &RetryPolicy{MaxAttempts: 1 + r.Intn(5), Backoff: BackoffExponential, Delay: time.Second, RetryOn: RetryOnAnyFailure},
+		NodeSelector:         labels("node"),
+		Tolerations: []corev1.Toleration{{
+			Key: word("taint"), Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute, TolerationSeconds: &seconds,
+		}},
+		Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: words("zone")}},
+			}}},
+		}},
+		ServiceAccountName: word("sa"),
+		Command:            words("cmd"),
+		Args:               words("--arg"),
+		ArgsMode:           testkube.ArgsModeTypeReplace,
+		WorkingDir:         word("/data/repo"),
+		ArtifactRequest:    &scraper.ArtifactRequest{Patterns: words("**/*.xml"), StoragePrefix: word("prefix"), MaxSize: 1024},
+		Priority:           &Priority{ClassName: word("priority"), Value: int32(r.Intn(1000))},
+		RunAfter:           time.Unix(1700000000+r.Int63n(1000000), 0).UTC(),
+		Delay:              time.Duration(1+r.Intn(60)) * time.Minute,
+		IdempotencyKey:     word("delivery"),
+		Services: []Service{{
+			Name:             word("db"),
+			Image:            word("image"),
+			Env:              labels("ENV"),
+			Ports:            []int32{int32(1 + r.Intn(65535))},
+			ReadinessCommand: words("check"),
+			StartupTimeout:   time.Duration(1+r.Intn(60)) * time.Second,
+		}},
+		Envs:          labels("ENV"),
+		SecretEnvs:    []SecretRef{{Name: word("secret"), Key: "TOKEN"}, {Name: word("secret")}},
+		ConfigMapEnvs: []ConfigMapRef{{Name: word("config"), Key: "REGION"}},
+		Annotations:   labels("annotation"),
+		OutputFormat:  OutputFormatJUnit,
+		Sharding:      &Sharding{Count: 2 + r.Intn(10), Envs: map[string]string{"PLAYWRIGHT_SHARD": "{{ .Number }}/{{ .Total }}"}, Merge: ShardMergeNone},
+		FieldOrigins:  []FieldOrigin{{Field: "args", Source: FieldSourceRequest}, {Field: "envs", Source: FieldSourceMerged}},
+	}
+}
+
+// mutateExecuteOptions changes values behind every map, slice and pointer of execute options
+func mutateExecuteOptions(o *ExecuteOptions) {
+	o.TestSpec.ExecutionRequest.Image = "mutated"
+	o.TestSpec.ExecutionRequest.Envs["MUTATED"] = "mutated"
+	o.TestSpec.ExecutionRequest.Variables["PASSWORD"] = testsv3.Variable{Value: "mutated"}
+	o.ExecutorSpec.Args[0] = "mutated"
+	o.ExecutorSpec.Types[0] = "mutated"
+	o.Request.ExecutionLabels["mutated"] = "mutated"
+	o.Request.Variables["TOKEN"].SecretRef.Name = "mutated"
+	*o.Request.Variables["TOKEN"].Type_ = testkube.BASIC_VariableType
+	o.Request.Command[0] = "mutated"
+	o.Request.ImagePullSecrets[0].Name = "mutated"
+	o.Request.SecretEnvs["MUTATED"] = "mutated"
+	o.Request.ArtifactRequest.Dirs[0] = "mutated"
+	o.Request.ContentRequest.Repository.Branch = "mutated"
+	o.Request.EnvConfigMaps[0].Reference.Name = "mutated"
+	o.Request.RunningContext.Context = "mutated"
+	o.Request.SlavePodRequest.Resources.Limits.Memory = "mutated"
+	o.Request.NodeSelector["mutated"] = "mutated"
+	o.Request.Sharding.Count = 100
+	o.Request.Sharding.Envs["MUTATED"] = "mutated"
+	o.Labels["mutated"] = "mutated"
+	o.UsernameSecret.Name = "mutated"
+	o.TokenSecret.Name = "mutated"
+	o.ImagePullSecretNames[0] = "mutated"
+	o.Resources.Limits.Memory = "mutated"
+	o.RetryPolicy.MaxAttempts = 100
+	o.NodeSelector["mutated"] = "mutated"
+	*o.Tolerations[0].TolerationSeconds = -1
+	o.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0].Values[0] = "mutated"
+	o.Command[0] = "mutated"
+	o.Args[0] = "mutated"
+	o.ArtifactRequest.Patterns[0] = "mutated"
+	o.Priority.ClassName = "mutated"
+	o.Services[0].Env["MUTATED"] = "mutated"
+	o.Services[0].Ports[0] = 0
+	o.Services[0].ReadinessCommand[0] = "mutated"
+	o.Envs["MUTATED"] = "mutated"
+	o.SecretEnvs[0].Name = "mutated"
+	o.ConfigMapEnvs[0].Key = "MUTATED"
+	o.Annotations["mutated"] = "mutated"
+	o.Sharding.Envs["MUTATED"] = "mutated"
+	o.FieldOrigins[0].Source = FieldSourceTest
+}
+
+func TestRandomExecuteOptions_SetsAllFields(t *testing.T) {
+	options := reflect.ValueOf(randomExecuteOptions(rand.New(rand.NewSource(1))))
+	for i := 0; i < options.NumField(); i++ {
+		assert.False(t, options.Field(i).IsZero(), "field %s isn't set by randomExecuteOptions", options.Type().Field(i).Name)
+	}
+}
+
+func TestExecuteOptions_DeepCopy(t *testing.T) {
+	for seed := int64(0); seed < propertyIterations; seed++ {
+		original := randomExecuteOptions(rand.New(rand.NewSource(seed)))
+		expected := randomExecuteOptions(rand.New(rand.NewSource(seed)))
+
+		copied := original.DeepCopy()
+		require.Equal(t, original, copied)
+
+		mutateExecuteOptions(&copied)
+		require.Equal(t, expected, original, "seed %d", seed)
+	}
+}
+
+func TestExecuteOptions_DeepCopyNilFields(t *testing.T) {
+	assert.Equal(t, ExecuteOptions{}, ExecuteOptions{}.DeepCopy())
+}
+
+func TestExecuteOptions_JSONRoundTrip(t *testing.T) {
+	for seed := int64(0); seed < propertyIterations; seed++ {
+		original := randomExecuteOptions(rand.New(rand.NewSource(seed)))
+
+		data, err := json.Marshal(original)
+		require.NoError(t, err)
+		var decoded ExecuteOptions
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Equal(t, original, decoded, "seed %d", seed)
+
+		again, err := json.Marshal(decoded)
+		require.NoError(t, err)
+		require.Equal(t, string(data), string(again), "seed %d", seed)
+	}
+}
+
+func TestExecuteOptions_JSONFieldNames(t *testing.T) {
+	data, err := json.Marshal(ExecuteOptions{ID: "exec", TestName: "test", Timeout: time.Second, WorkingDir: "/data"})
+
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, "exec", fields["id"])
+	assert.Equal(t, "test", fields["testName"])
+	assert.Equal(t, float64(time.Second), fields["timeout"])
+	assert.Equal(t, "/data", fields["workingDir"])
+}
+
+func TestExecuteOptions_Redacted(t *testing.T) {
+	secretType := testkube.SECRET_VariableType
+	options := ExecuteOptions{
+		ID:     "exec",
+		Labels: map[string]string{"team": "qa", "auth-token": "abc"},
+		Envs:   map[string]string{"DB_PASSWORD": "pass", "REGION": "eu"},
+		TestSpec: testsv3.TestSpec{ExecutionRequest: &testsv3.ExecutionRequest{
+			Envs:       map[string]string{"DB_PASSWORD": "pass", "REGION": "eu"},
+			SecretEnvs: map[string]string{"secret-name": "secret-key"},
+			Variables: map[string]testsv3.Variable{
+				"USER":  {Type_: commonv1.VariableTypeSecret, Value: "admin"},
+				"DEBUG": {Type_: commonv1.VariableTypeBasic, Value: "true"},
+			},
+		}},
+		Request: testkube.ExecutionRequest{
+			Envs:       map[string]string{"API_KEY": "key", "URL": "https://testkube.io"},
+			SecretEnvs: map[string]string{"secret-name": "secret-key"},
+			Variables: map[string]testkube.Variable{
+				"USER":         {Name: "USER", Value: "admin", Type_: &secretType},
+				"GITHUB_TOKEN": {Name: "GITHUB_TOKEN", Value: "ghp"},
+				"FROM_SECRET":  {Name: "FROM_SECRET", Type_: &secretType, SecretRef: &testkube.SecretRef{Name: "s", Key: "k"}},
+				"HOST":         {Name: "HOST", Value: "localhost"},
+			},
+		},
+	}
+
+	redacted := options.Redacted()
+
+	assert.Equal(t, map[string]string{"team": "qa", "auth-token": RedactedValue}, redacted.Labels)
+	assert.Equal(t, map[string]string{"DB_PASSWORD": RedactedValue, "REGION": "eu"}, redacted.Envs)
+	assert.Equal(t, map[string]string{"DB_PASSWORD": RedactedValue, "REGION": "eu"}, redacted.TestSpec.ExecutionRequest.Envs)
+	assert.Equal(t, map[string]string{"secret-name": RedactedValue}, redacted.TestSpec.ExecutionRequest.SecretEnvs)
+	assert.Equal(t, RedactedValue, redacted.TestSpec.ExecutionRequest.Variables["USER"].Value)
+	assert.Equal(t, "true", redacted.TestSpec.ExecutionRequest.Variables["DEBUG"].Value)
+	assert.Equal(t, map[string]string{"API_KEY": RedactedValue, "URL": "https://testkube.io"}, redacted.Request.Envs)
+	assert.Equal(t, map[string]string{"secret-name": RedactedValue}, redacted.Request.SecretEnvs)
+	assert.Equal(t, RedactedValue, redacted.Request.Variables["USER"].Value)
+	assert.Equal(t, RedactedValue, redacted.Request.Variables["GITHUB_TOKEN"].Value)
+	assert.Equal(t, "", redacted.Request.Variables["FROM_SECRET"].Value)
+	assert.Equal(t, "s", redacted.Request.Variables["FROM_SECRET"].SecretRef.Name)
+	assert.Equal(t, "localhost", redacted.Request.Variables["HOST"].Value)
+
+	// original options are untouched
+	assert.Equal(t, "pass", options.TestSpec.ExecutionRequest.Envs["DB_PASSWORD"])
+	assert.Equal(t, "key", options.Request.Envs["API_KEY"])
+	assert.Equal(t, "ghp", options.Request.Variables["GITHUB_TOKEN"].Value)
+}
+
+func TestExecuteOptions_RedactedWith(t *testing.T) {
+	options := ExecuteOptions{Request: testkube.ExecutionRequest{
+		Envs:       map[string]string{"API_KEY": "key", "SESSION_ID": "abc"},
+		SecretEnvs: map[string]string{"secret-name": "secret-key"},
+	}}
+
+	redacted := options.RedactedWith(regexp.MustCompile(`(?i)session`))
+
+	assert.Equal(t, map[string]string{"API_KEY": "key", "SESSION_ID": RedactedValue}, redacted.Request.Envs)
+	assert.Equal(t, map[string]string{"secret-name": RedactedValue}, redacted.Request.SecretEnvs)
+}
@@ -0,0 +1,117 @@
+package client
+
+import "reflect"
+
+// ExecuteOptionsDefaults documents the behavior of zero value of each execute options field,
+// so callers constructing execute options with struct literals keep the old behavior for the fields they don't know;
+// every new field has to be added here, with its defaulting in Normalize when the zero value needs one
+var ExecuteOptionsDefaults = map[string]string{
+	"ID":                   "required, set by the caller",
+	"TestName":             "required, set by the caller",
+	"Namespace":            "execution namespace of the test",
+	"TestSpec":             "empty test spec",
+	"ExecutorName":         "no executor name",
+	"ExecutorSpec":         "job executor",
+	"Request":              "empty execution request",
+	"Sync":                 "asynchronous execution",
+	"Labels":               "no labels",
+	"UsernameSecret":       "no username secret",
+	"TokenSecret":          "no token secret",
+	"RunnerCustomCASecret": "no custom CA secret",
+	"CertificateSecret":    "no certificate secret",
+	"AgentAPITLSSecret":    "no Agent API TLS secret",
+	"ImagePullSecretNames": "no image pull secrets",
+	"Features":             "all feature flags disabled",
+	"Timeout":              "unlimited timeout",
+	"Resources":            "executor default resources",
+	"RetryPolicy":          "no retries",
+	"NodeSelector":         "executor job template node selector",
+	"Tolerations":          "executor job template tolerations",
+	"Affinity":             "executor job template affinity",
+	"ServiceAccountName":   "executor service account",
+	"Command":              "resolved container command",
+	"Args":                 "resolved container args",
+	"ArgsMode":             "append args, same as testkube.ArgsModeTypeAppend",
+	"WorkingDir":           "container working directory",
+	"ArtifactRequest":      "no artifacts",
+	"Priority":             "no priority class",
+	"RunAfter":             "start immediately",
+	"Delay":                "no delay",
+	"IdempotencyKey":       "no deduplication",
+	"Services":             "no services",
+	"Envs":                 "no execution environment variables",
+	"SecretEnvs":           "no secret environment variables",
+	"ConfigMapEnvs":        "no config map environment variables",
+	"Annotations":          "no annotations",
+	"OutputFormat":         "native runner output",
+	"Sharding":             "single job",
+	"FieldOrigins":         "no provenance of the request fields",
+}
+
+// Normalize returns execute options with the zero and empty values replaced by their canonical form,
+// see ExecuteOptionsDefaults, so the empty but set values behave the same as unset ones;
+// the invalid values, like negative timeout, are kept for Validate
+func (o ExecuteOptions) Normalize() ExecuteOptions {
+	if o.Resources != nil && *o.Resources == (Resources{}) {
+		o.Resources = nil
+	}
+	if o.Sharding != nil && o.Sharding.Count == 1 {
+		o.Sharding = nil
+	}
+	if r := o.ArtifactRequest; r != nil && len(r.Patterns) == 0 && r.StoragePrefix == "" && r.MaxSize == 0 && !r.OnlyOnFailure {
+		o.ArtifactRequest = nil
+	}
+
+	if len(o.Labels) == 0 {
+		o.Labels = nil
+	}
+	if len(o.ImagePullSecretNames) == 0 {
+		o.ImagePullSecretNames = nil
+	}
+	if len(o.NodeSelector) == 0 {
+		o.NodeSelector = nil
+	}
+	if len(o.Tolerations) == 0 {
+		o.Tolerations = nil
+	}
+	if len(o.Command) == 0 {
+		o.Command = nil
+	}
+	if len(o.Args) == 0 {
+		o.Args = nil
+	}
+	if len(o.Services) == 0 {
+		o.Services = nil
+	}
+	if len(o.Envs) == 0 {
+		o.Envs = nil
+	}
+	if len(o.SecretEnvs) == 0 {
+		o.SecretEnvs = nil
+	}
+	if len(o.ConfigMapEnvs) == 0 {
+		o.ConfigMapEnvs = nil
+	}
+	if len(o.Annotations) == 0 {
+		o.Annotations = nil
+	}
+	if len(o.FieldOrigins) == 0 {
+		o.FieldOrigins = nil
+	}
+
+	return o
+}
+
+// Diff returns names of execute options fields which differ from the other options, in the fields order,
+// i.e. to log effective options compared to the ones provided by the caller
+func (o ExecuteOptions) Diff(other ExecuteOptions) []string {
+	var fields []string
+	left, right := reflect.ValueOf(o), reflect.ValueOf(other)
+	for i := 0; i < left.NumField(); i++ {
+		if !reflect.DeepEqual(left.Field(i).Interface(), right.Field(i).Interface()) {
+			fields = append(fields, left.Type().Field(i).Name)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubeshop/testkube/pkg/executor/scraper"
+)
+
+// normalizeGolden pins the defaulting of every execute options field, when the new field is added
+// its case has to be added here, see TestExecuteOptionsDefaults_CoverAllFields
+var normalizeGolden = map[string]struct {
+	input    ExecuteOptions
+	expected ExecuteOptions
+}{
+	"zero value": {
+		input:    ExecuteOptions{},
+		expected: ExecuteOptions{},
+	},
+	"unlimited timeout": {
+		input:    ExecuteOptions{Timeout: 0},
+		expected: ExecuteOptions{},
+	},
+	"negative timeout kept for validation": {
+		input:    ExecuteOptions{Timeout: -time.Second},
+		expected: ExecuteOptions{Timeout: -time.Second},
+	},
+	"executor default resources": {
+		input:    ExecuteOptions{Resources: &Resources{}},
+		expected: ExecuteOptions{},
+	},
+	"partial resources kept": {
+		input:    ExecuteOptions{Resources: &Resources{Limits: ResourceList{CPU: "1"}}},
+		expected: ExecuteOptions{Resources: &Resources{Limits: ResourceList{CPU: "1"}}},
+	},
+	"no artifacts": {
+		input:    ExecuteOptions{ArtifactRequest: &scraper.ArtifactRequest{Patterns: []string{}}},
+		expected: ExecuteOptions{},
+	},
+	"artifacts without patterns kept for validation": {
+		input:    ExecuteOptions{ArtifactRequest: &scraper.ArtifactRequest{MaxSize: 10}},
+		expected: ExecuteOptions{ArtifactRequest: &scraper.ArtifactRequest{MaxSize: 10}},
+	},
+	"empty collections": {
+		input: ExecuteOptions{
+			Labels:               map[string]string{},
+			ImagePullSecretNames: []string{},
+			NodeSelector:         map[string]string{},
+			Tolerations:          []corev1.Toleration{},
+			Command:              []string{},
+			Args:                 []string{},
+			Services:             []Service{},
+			Envs:                 map[string]string{},
+			SecretEnvs:           []SecretRef{},
+			ConfigMapEnvs:        []ConfigMapRef{},
+			Annotations:          map[string]string{},
+			FieldOrigins:         []FieldOrigin{},
+		},
+		expected: ExecuteOptions{},
+	},
+	"single shard is a single job": {
+		input:    ExecuteOptions{Sharding: &Sharding{Count: 1}},
+		expected: ExecuteOptions{},
+	},
+	"invalid shard count kept for validation": {
+		input:    ExecuteOptions{Sharding: &Sharding{Count: 0}},
+		expected: ExecuteOptions{Sharding: &Sharding{Count: 0}},
+	},
+	"append args mode kept": {
+		input:    ExecuteOptions{ArgsMode: ""},
+		expected: ExecuteOptions{ArgsMode: ""},
+	},
+}
+
+func TestExecuteOptions_NormalizeGolden(t *testing.T) {
+	for name, tc := range normalizeGolden {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.input.Normalize())
+		})
+	}
+}
+
+func TestExecuteOptions_NormalizeKeepsSetValues(t *testing.T) {
+	for seed := int64(0); seed < propertyIterations; seed++ {
+		original := randomExecuteOptions(rand.New(rand.NewSource(seed)))
+		require.Equal(t, original, original.Normalize(), "seed %d", seed)
+	}
+}
+
+func TestExecuteOptions_NormalizeIsIdempotent(t *testing.T) {
+	for name, tc := range normalizeGolden {
+		normalized := tc.input.Normalize()
+		assert.Equal(t, normalized, normalized.Normalize(), name)
+	}
+}
+
+func TestExecuteOptionsDefaults_CoverAllFields(t *testing.T) {
+	var fields []string
+	options := reflect.TypeOf(ExecuteOptions{})
+	for i := 0; i < options.NumField(); i++ {
+		if options.Field(i).IsExported() {
+			fields = append(fields, options.Field(i).Name)
+		}
+	}
+
+	var documented []string
+	for name := range ExecuteOptionsDefaults {
+		documented = append(documented, name)
+	}
+	sort.Strings(fields)
+	sort.Strings(documented)
+	assert.Equal(t, fields, documented, "every execute options field needs its default in ExecuteOptionsDefaults and in normalizeGolden")
+}
+
+func TestExecuteOptions_Diff(t *testing.T) {
+	provided := ExecuteOptions{TestName: "k6-test", Resources: &Resources{}, Labels: map[string]string{}}
+
+	assert.Equal(t, []string{"Labels", "Resources"}, provided.Normalize().Diff(provided))
+	assert.Empty(t, provided.Diff(provided))
+	assert.Equal(t, []string{"TestName", "Timeout"}, provided.Diff(ExecuteOptions{Resources: &Resources{}, Labels: map[string]string{}, Timeout: time.Minute}))
+}
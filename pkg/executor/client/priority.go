@@ -0,0 +1,120 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// MaxUserPriority is the highest priority value Kubernetes allows for user defined priority classes
+	MaxUserPriority = 1000000000
+	// systemPriorityClassPrefix is reserved for Kubernetes system priority classes
+	systemPriorityClassPrefix = "system-"
+)
+
+// ErrPriorityClassesNotConfigured is returned when execution requests priority class but none is allowed
+var ErrPriorityClassesNotConfigured = errors.New("execution priority classes are not configured")
+
+// Priority defines execution priority, either as a value or as a named priority class
+type Priority struct {
+	// ClassName is a PriorityClass name set on the execution pod, it has to be one of the allowed priority classes
+	ClassName string `json:"className,omitempty"`
+	// Value orders queued executions, higher values start first, defaults to the priority class value
+	Value int32 `json:"value,omitempty"`
+}
+
+// Validate checks priority value range and rejects system priority classes
+func (p Priority) Validate() error {
+	if p.Value > MaxUserPriority {
+		return fmt.Errorf("priority value can't be greater than %d", MaxUserPriority)
+	}
+
+	if strings.HasPrefix(p.ClassName, systemPriorityClassPrefix) {
+		return fmt.Errorf("system priority class %s can't be used for executions", p.ClassName)
+	}
+
+	return nil
+}
+
+// PriorityClasses maps priority class names allowed for executions to their priority values
+type PriorityClasses map[string]int32
+
+// ParsePriorityClasses parses allowed priority classes from comma separated name=value pairs, e.g. smoke=1000,nightly=10
+func ParsePriorityClasses(s string) (PriorityClasses, error) {
+	classes := PriorityClasses{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(item, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("priority class %s should be defined as name=value", item)
+		}
+
+		priority, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("priority class %s has invalid value: %w", name, err)
+		}
+
+		if err = (Priority{ClassName: name, Value: int32(priority)}).Validate(); err != nil {
+			return nil, err
+		}
+
+		classes[name] = int32(priority)
+	}
+
+	return classes, nil
+}
+
+// Resolve checks the priority class is allowed and fills the priority value from the class when not set
+func (c PriorityClasses) Resolve(priority Priority) (Priority, error) {
+	if err := priority.Validate(); err != nil {
+		return priority, err
+	}
+
+	if priority.ClassName == "" {
+		return priority, nil
+	}
+
+	if len(c) == 0 {
+		return priority, fmt.Errorf("priority class %s: %w", priority.ClassName, ErrPriorityClassesNotConfigured)
+	}
+
+	value, ok := c[priority.ClassName]
+	if !ok {
+		return priority, fmt.Errorf("priority class %s is not allowed for executions", priority.ClassName)
+	}
+
+	if priority.Value == 0 {
+		priority.Value = value
+	}
+
+	return priority, nil
+}
+
+// QueuePriority is a priority value used to order queued executions,
+// explicit priority value wins over the priority class value
+func (c PriorityClasses) QueuePriority(options ExecuteOptions) int32 {
+	if options.Priority == nil {
+		return 0
+	}
+
+	if options.Priority.Value != 0 {
+		return options.Priority.Value
+	}
+
+	return c[options.Priority.ClassName]
+}
+
+// ApplyPriority sets execution priority class on the pod spec rendered from the executor job template
+func ApplyPriority(spec *corev1.PodSpec, priority *Priority) {
+	if priority != nil && priority.ClassName != "" {
+		spec.PriorityClassName = priority.ClassName
+	}
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParsePriorityClasses(t *testing.T) {
+	classes, err := ParsePriorityClasses("smoke=1000, nightly=10,,")
+	require.NoError(t, err)
+	assert.Equal(t, PriorityClasses{"smoke": 1000, "nightly": 10}, classes)
+
+	classes, err = ParsePriorityClasses("")
+	require.NoError(t, err)
+	assert.Empty(t, classes)
+
+	for _, invalid := range []string{"smoke", "=10", "smoke=high", "smoke=2000000000", "system-cluster-critical=100"} {
+		_, err = ParsePriorityClasses(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPriorityClasses_Resolve(t *testing.T) {
+	classes := PriorityClasses{"smoke": 1000, "nightly": 10}
+
+	priority, err := classes.Resolve(Priority{ClassName: "smoke"})
+	require.NoError(t, err)
+	assert.Equal(t, Priority{ClassName: "smoke", Value: 1000}, priority)
+
+	priority, err = classes.Resolve(Priority{ClassName: "nightly", Value: 50})
+	require.NoError(t, err)
+	assert.Equal(t, Priority{ClassName: "nightly", Value: 50}, priority)
+
+	priority, err = classes.Resolve(Priority{Value: 5})
+	require.NoError(t, err)
+	assert.Equal(t, Priority{Value: 5}, priority)
+
+	_, err = classes.Resolve(Priority{ClassName: "critical"})
+	assert.EqualError(t, err, "priority class critical is not allowed for executions")
+
+	_, err = PriorityClasses{"system-node-critical": 10}.Resolve(Priority{ClassName: "system-node-critical"})
+	assert.EqualError(t, err, "system priority class system-node-critical can't be used for executions")
+
+	_, err = PriorityClasses(nil).Resolve(Priority{ClassName: "smoke"})
+	assert.ErrorIs(t, err, ErrPriorityClassesNotConfigured)
+}
+
+func TestPriorityClasses_QueuePriority(t *testing.T) {
+	classes := PriorityClasses{"smoke": 1000}
+
+	assert.Equal(t, int32(0), classes.QueuePriority(ExecuteOptions{}))
+	assert.Equal(t, int32(1000), classes.QueuePriority(ExecuteOptions{Priority: &Priority{ClassName: "smoke"}}))
+	assert.Equal(t, int32(7), classes.QueuePriority(ExecuteOptions{Priority: &Priority{ClassName: "smoke", Value: 7}}))
+	assert.Equal(t, int32(0), classes.QueuePriority(ExecuteOptions{Priority: &Priority{ClassName: "unknown"}}))
+}
+
+func TestApplyPriority(t *testing.T) {
+	spec := corev1.PodSpec{PriorityClassName: "template-default"}
+	ApplyPriority(&spec, nil)
+	assert.Equal(t, "template-default", spec.PriorityClassName)
+
+	ApplyPriority(&spec, &Priority{Value: 100})
+	assert.Equal(t, "template-default", spec.PriorityClassName)
+
+	ApplyPriority(&spec, &Priority{ClassName: "smoke", Value: 1000})
+	assert.Equal(t, "smoke", spec.PriorityClassName)
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// QueuedExecution is an execution waiting in the execution queue
+type QueuedExecution struct {
+	// Sequence is a position in which the execution was pushed to the queue
+	Sequence int
+	// Priority is a resolved queue priority
+	Priority int32
+	// Options are execute options of the execution
+	Options ExecuteOptions
+}
+
+// ExecutionQueue orders pending executions by priority, higher priority executions are popped first
+// and executions with the same priority keep the order in which they were pushed, it's safe for concurrent use
+type ExecutionQueue struct {
+	mutex    sync.Mutex
+	classes  PriorityClasses
+	items    queuedExecutions
+	sequence int
+}
+
+// NewExecutionQueue creates new execution queue, priority classes provide values for executions with priority class only
+func NewExecutionQueue(classes PriorityClasses) *ExecutionQueue {
+	return &ExecutionQueue{classes: classes}
+}
+
+// Push adds execution to the queue
+func (q *ExecutionQueue) Push(options ExecuteOptions) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	heap.Push(&q.items, QueuedExecution{
+		Sequence: q.sequence,
+		Priority: q.classes.QueuePriority(options),
+		Options:  options,
+	})
+	q.sequence++
+}
+
+// Pop removes and returns the execution which should start next
+func (q *ExecutionQueue) Pop() (QueuedExecution, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.items) == 0 {
+		return QueuedExecution{}, false
+	}
+
+	return heap.Pop(&q.items).(QueuedExecution), true
+}
+
+// Len returns number of queued executions
+func (q *ExecutionQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.items)
+}
+
+// queuedExecutions implements heap.Interface
+type queuedExecutions []QueuedExecution
+
+func (q queuedExecutions) Len() int { return len(q) }
+
+func (q queuedExecutions) Less(i, j int) bool {
+	if q[i].Priority != q[j].Priority {
+		return q[i].Priority > q[j].Priority
+	}
+
+	return q[i].Sequence < q[j].Sequence
+}
+
+func (q queuedExecutions) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *queuedExecutions) Push(x any) { *q = append(*q, x.(QueuedExecution)) }
+
+func (q *queuedExecutions) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
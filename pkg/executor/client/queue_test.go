@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func TestExecutionQueue_Order(t *testing.T) {
+	queue := NewExecutionQueue(PriorityClasses{"smoke": 1000, "nightly": 10})
+	queue.Push(ExecuteOptions{TestName: "nightly-1", Priority: &Priority{ClassName: "nightly"}})
+	queue.Push(ExecuteOptions{TestName: "default-1"})
+	queue.Push(ExecuteOptions{TestName: "smoke-1", Priority: &Priority{ClassName: "smoke"}})
+	queue.Push(ExecuteOptions{TestName: "nightly-2", Priority: &Priority{ClassName: "nightly"}})
+	queue.Push(ExecuteOptions{TestName: "urgent", Priority: &Priority{Value: 5000}})
+	queue.Push(ExecuteOptions{TestName: "smoke-2", Priority: &Priority{ClassName: "smoke"}})
+	queue.Push(ExecuteOptions{TestName: "default-2"})
+	require.Equal(t, 7, queue.Len())
+
+	var names []string
+	var sequences []int
+	for item, ok := queue.Pop(); ok; item, ok = queue.Pop() {
+		names = append(names, item.Options.TestName)
+		sequences = append(sequences, item.Sequence)
+	}
+
+	assert.Equal(t, []string{"urgent", "smoke-1", "smoke-2", "nightly-1", "nightly-2", "default-1", "default-2"}, names)
+	assert.Equal(t, []int{4, 2, 5, 0, 3, 1, 6}, sequences)
+	assert.Equal(t, 0, queue.Len())
+}
+
+func TestExecutionQueue_PushAfterPop(t *testing.T) {
+	queue := NewExecutionQueue(nil)
+	queue.Push(ExecuteOptions{TestName: "low", Priority: &Priority{Value: 1}})
+	queue.Push(ExecuteOptions{TestName: "lower"})
+
+	item, ok := queue.Pop()
+	require.True(t, ok)
+	assert.Equal(t, "low", item.Options.TestName)
+
+	queue.Push(ExecuteOptions{TestName: "high", Priority: &Priority{Value: 10}})
+	item, _ = queue.Pop()
+	assert.Equal(t, "high", item.Options.TestName)
+	item, _ = queue.Pop()
+	assert.Equal(t, "lower", item.Options.TestName)
+
+	_, ok = queue.Pop()
+	assert.False(t, ok)
+}
+
+// startOrderExecutor records order in which executions were started
+type startOrderExecutor struct {
+	*FakeExecutor
+	mu      sync.Mutex
+	started []ExecuteOptions
+}
+
+func (e *startOrderExecutor) Execute(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	e.mu.Lock()
+	e.started = append(e.started, options)
+	e.mu.Unlock()
+	return e.FakeExecutor.Execute(ctx, execution, options)
+}
+
+func TestExecutionRunner_ExecuteBatchPriorityOrder(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	for _, name := range []string{"nightly", "smoke", "regression"} {
+		fake.ExpectExecution(name)
+	}
+	executor := &startOrderExecutor{FakeExecutor: fake}
+
+	batch, err := newTestExecutionRunner(executor, fake, &recordingClock{}).
+		WithPriorityClasses(PriorityClasses{"incident": 1000}).
+		ExecuteBatch(context.Background(), []ExecuteOptions{
+			{TestName: "nightly"},
+			{TestName: "smoke", Priority: &Priority{ClassName: "incident"}},
+			{TestName: "regression", Priority: &Priority{Value: 10}},
+		}, BatchOptions{Parallelism: 1})
+
+	require.NoError(t, err)
+	assert.True(t, batch.Passed)
+	var started, indexes []string
+	for _, options := range executor.started {
+		started = append(started, options.TestName)
+		indexes = append(indexes, options.Labels[BatchIndexLabel])
+	}
+	assert.Equal(t, []string{"smoke", "regression", "nightly"}, started)
+	// batch indexes and results keep the order of passed items
+	assert.Equal(t, []string{"1", "2", "0"}, indexes)
+	for i, item := range batch.Items {
+		assert.Equal(t, i, item.Index)
+	}
+}
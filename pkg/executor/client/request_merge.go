@@ -0,0 +1,400 @@
+package client
+
+import (
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// FieldSource tells where the effective value of the execution request field came from
+type FieldSource string
+
+const (
+	// FieldSourceRequest is the value provided by the execution request
+	FieldSourceRequest FieldSource = "request"
+	// FieldSourceTest is the default from the test spec
+	FieldSourceTest FieldSource = "test"
+	// FieldSourceMerged is the value combined from both the execution request and the test spec
+	FieldSourceMerged FieldSource = "merged"
+)
+
+// FieldOrigin is the provenance of the effective value of the execution request field,
+// the field is named by its JSON name in the execution request
+type FieldOrigin struct {
+	Field  string      `json:"field"`
+	Source FieldSource `json:"source"`
+}
+
+// ListMergePolicy defines how the list from the execution request is combined with the list from the test spec
+type ListMergePolicy string
+
+const (
+	// ListReplace uses the request list when it's not empty, and the test list otherwise
+	ListReplace ListMergePolicy = "replace"
+	// ListAppend uses the items of both lists, the request items first
+	ListAppend ListMergePolicy = "append"
+)
+
+// ListMergePolicies are the policies of the list fields of the execution request used by MergeExecutionRequest
+var ListMergePolicies = map[string]ListMergePolicy{
+	"command":               ListReplace,
+	"args":                  ListReplace,
+	"artifactRequest.dirs":  ListAppend,
+	"artifactRequest.masks": ListAppend,
+}
+
+// MergeExecutionRequest combines the execution request with the defaults of the execution request from the test spec,
+// and returns provenance of every field with the effective value. The precedence is:
+//   - scalar fields: the request value wins when it's set, the test value is used otherwise
+//   - boolean flags: enabled when enabled by either of them, negativeTest is taken from the test
+//     unless the request changes it explicitly
+//   - maps (variables, envs, secretEnvs): merged by key, the request values win for the same key
+//   - env references (envConfigMaps, envSecrets): merged by reference name, the request reference wins
+//     and the test reference fills its unset fields
+//   - lists: replaced or appended, see ListMergePolicies
+//   - artifactRequest and slavePodRequest: merged field by field, the request values win
+//
+// The fields of the request which have no defaults in the test spec, like the execution name, are kept as they are.
+// Neither the request nor the test spec is modified, and the result never shares maps, slices or pointers
+// with the test spec.
+func MergeExecutionRequest(test testkube.Test, request testkube.ExecutionRequest) (testkube.ExecutionRequest, []FieldOrigin) {
+	spec := test.ExecutionRequest
+	if spec == nil {
+		spec = &testkube.ExecutionRequest{}
+	}
+
+	m := requestMerger{}
+	request.Variables = mergeMap(&m, "variables", request.Variables, spec.Variables)
+	request.Envs = mergeMap(&m, "envs", request.Envs, spec.Envs)
+	request.SecretEnvs = mergeMap(&m, "secretEnvs", request.SecretEnvs, spec.SecretEnvs)
+	request.EnvConfigMaps = m.envReferences("envConfigMaps", request.EnvConfigMaps, spec.EnvConfigMaps)
+	request.EnvSecrets = m.envReferences("envSecrets", request.EnvSecrets, spec.EnvSecrets)
+
+	m.track("variablesFile", request.VariablesFile != "", spec.VariablesFile != "", false)
+	if request.VariablesFile == "" && spec.VariablesFile != "" {
+		request.VariablesFile = spec.VariablesFile
+		request.IsVariablesFileUploaded = spec.IsVariablesFileUploaded
+	}
+
+	var fields = []struct {
+		name        string
+		source      string
+		destination *string
+	}{
+		{"httpProxy", spec.HttpProxy, &request.HttpProxy},
+		{"httpsProxy", spec.HttpsProxy, &request.HttpsProxy},
+		{"jobTemplate", spec.JobTemplate, &request.JobTemplate},
+		{"jobTemplateReference", spec.JobTemplateReference, &request.JobTemplateReference},
+		{"preRunScript", spec.PreRunScript, &request.PreRunScript},
+		{"postRunScript", spec.PostRunScript, &request.PostRunScript},
+		{"scraperTemplate", spec.ScraperTemplate, &request.ScraperTemplate},
+		{"scraperTemplateReference", spec.ScraperTemplateReference, &request.ScraperTemplateReference},
+		{"pvcTemplate", spec.PvcTemplate, &request.PvcTemplate},
+		{"pvcTemplateReference", spec.PvcTemplateReference, &request.PvcTemplateReference},
+		{"args_mode", spec.ArgsMode, &request.ArgsMode},
+	}
+
+	for _, field := range fields {
+		m.track(field.name, *field.destination != "", field.source != "", false)
+		if *field.destination == "" && field.source != "" {
+			*field.destination = field.source
+		}
+	}
+
+	request.Command = mergeList(&m, "command", request.Command, spec.Command)
+	request.Args = mergeList(&m, "args", request.Args, spec.Args)
+
+	m.track("activeDeadlineSeconds", request.ActiveDeadlineSeconds != 0, spec.ActiveDeadlineSeconds != 0, false)
+	if request.ActiveDeadlineSeconds == 0 {
+		request.ActiveDeadlineSeconds = spec.ActiveDeadlineSeconds
+	}
+
+	m.track("executePostRunScriptBeforeScraping", request.ExecutePostRunScriptBeforeScraping, spec.ExecutePostRunScriptBeforeScraping, false)
+	request.ExecutePostRunScriptBeforeScraping = request.ExecutePostRunScriptBeforeScraping || spec.ExecutePostRunScriptBeforeScraping
+
+	m.track("sourceScripts", request.SourceScripts, spec.SourceScripts, false)
+	request.SourceScripts = request.SourceScripts || spec.SourceScripts
+
+	m.track("negativeTest", request.IsNegativeTestChangedOnRun, spec.NegativeTest, false)
+	if !request.IsNegativeTestChangedOnRun {
+		request.NegativeTest = spec.NegativeTest
+	}
+
+	m.track("artifactRequest", request.ArtifactRequest != nil, spec.ArtifactRequest != nil, true)
+	request.ArtifactRequest = mergeArtifacts(&m, request.ArtifactRequest, spec.ArtifactRequest)
+
+	m.track("slavePodRequest", request.SlavePodRequest != nil, spec.SlavePodRequest != nil, true)
+	request.SlavePodRequest = mergeSlavePodRequests(request.SlavePodRequest, spec.SlavePodRequest)
+
+	return request, m.origins
+}
+
+// MapFieldOriginsToAPI maps the provenance of the execution request fields to the execution field origins
+func MapFieldOriginsToAPI(origins []FieldOrigin) map[string]string {
+	if len(origins) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(origins))
+	for _, origin := range origins {
+		result[origin.Field] = string(origin.Source)
+	}
+
+	return result
+}
+
+// requestMerger collects the provenance of the merged fields
+type requestMerger struct {
+	origins []FieldOrigin
+}
+
+// track records the origin of the field, the fields set by neither the request nor the test have no origin;
+// the values set by both are merged only when combinable, otherwise the request value wins
+func (m *requestMerger) track(field string, inRequest, inTest, combinable bool) {
+	var source FieldSource
+	switch {
+	case inRequest && inTest && combinable:
+		source = FieldSourceMerged
+	case inRequest:
+		source = FieldSourceRequest
+	case inTest:
+		source = FieldSourceTest
+	default:
+		return
+	}
+
+	m.origins = append(m.origins, FieldOrigin{Field: field, Source: source})
+}
+
+// mergeMap merges the maps by key, the request values win; the test map is copied,
+// so the result never shares the map with the test spec
+func mergeMap[T any](m *requestMerger, field string, request, test map[string]T) map[string]T {
+	m.track(field, len(request) != 0, len(test) != 0, true)
+	switch {
+	case len(test) == 0:
+		return request
+	case len(request) == 0:
+		return maps.Clone(test)
+	}
+
+	result := maps.Clone(test)
+	maps.Copy(result, request)
+	return result
+}
+
+// mergeList combines the lists according to the policy of the field from ListMergePolicies, replace by default;
+// the items are copied into a new list, so the result never shares the backing array with the test spec
+// and appending doesn't write into the request list
+func mergeList[T any](m *requestMerger, field string, request, test []T) []T {
+	appendItems := ListMergePolicies[field] == ListAppend
+	m.track(field, len(request) != 0, len(test) != 0, appendItems)
+	switch {
+	case len(test) == 0:
+		return request
+	case len(request) == 0:
+		return slices.Clone(test)
+	case appendItems:
+		result := make([]T, 0, len(request)+len(test))
+		result = append(result, request...)
+		return append(result, test...)
+	}
+
+	return request
+}
+
+// envReferences merges the env references by the reference name, the request reference wins
+// and its unset fields are taken from the test reference; the result is sorted by the reference name
+func (m *requestMerger) envReferences(field string, request, test []testkube.EnvReference) []testkube.EnvReference {
+	m.track(field, len(request) != 0, len(test) != 0, true)
+	if len(request) == 0 && len(test) == 0 {
+		return request
+	}
+
+	envs := make(map[string]testkube.EnvReference, 0)
+	for i := range request {
+		if request[i].Reference == nil {
+			continue
+		}
+
+		envs[request[i].Reference.Name] = request[i]
+	}
+
+	for i := range test {
+		if test[i].Reference == nil {
+			continue
+		}
+
+		if value, ok := envs[test[i].Reference.Name]; !ok {
+			envs[test[i].Reference.Name] = test[i]
+		} else {
+			if !value.Mount {
+				value.Mount = test[i].Mount
+			}
+
+			if value.MountPath == "" {
+				value.MountPath = test[i].MountPath
+			}
+
+			if !value.MapToVariables {
+				value.MapToVariables = test[i].MapToVariables
+			}
+
+			envs[test[i].Reference.Name] = value
+		}
+	}
+
+	res := make([]testkube.EnvReference, 0, len(envs))
+	for key := range envs {
+		res = append(res, envs[key])
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Reference.Name < res[j].Reference.Name
+	})
+
+	return res
+}
+
+// mergeArtifacts merges the artifact requests into a new artifact request, the request values win
+// and the directories and masks are combined according to ListMergePolicies
+func mergeArtifacts(m *requestMerger, artifactBase *testkube.ArtifactRequest, artifactAdjust *testkube.ArtifactRequest) *testkube.ArtifactRequest {
+	switch {
+	case artifactBase == nil && artifactAdjust == nil:
+		return nil
+	case artifactBase == nil && artifactAdjust != nil:
+		result := *artifactAdjust
+		result.Dirs = slices.Clone(artifactAdjust.Dirs)
+		result.Masks = slices.Clone(artifactAdjust.Masks)
+		return &result
+	case artifactBase != nil && artifactAdjust == nil:
+		return artifactBase
+	default:
+		result := *artifactBase
+		artifactBase = &result
+		artifactBase.Dirs = mergeList(m, "artifactRequest.dirs", artifactBase.Dirs, artifactAdjust.Dirs)
+		artifactBase.Masks = mergeList(m, "artifactRequest.masks", artifactBase.Masks, artifactAdjust.Masks)
+
+		if !artifactBase.OmitFolderPerExecution && artifactAdjust.OmitFolderPerExecution {
+			artifactBase.OmitFolderPerExecution = artifactAdjust.OmitFolderPerExecution
+		}
+
+		if !artifactBase.SharedBetweenPods && artifactAdjust.SharedBetweenPods {
+			artifactBase.SharedBetweenPods = artifactAdjust.SharedBetweenPods
+		}
+
+		var fields = []struct {
+			source      string
+			destination *string
+		}{
+			{
+				artifactAdjust.StorageClassName,
+				&artifactBase.StorageClassName,
+			},
+			{
+				artifactAdjust.VolumeMountPath,
+				&artifactBase.VolumeMountPath,
+			},
+			{
+				artifactAdjust.StorageBucket,
+				&artifactBase.StorageBucket,
+			},
+		}
+
+		for _, field := range fields {
+			if *field.destination == "" && field.source != "" {
+				*field.destination = field.source
+			}
+		}
+	}
+
+	return artifactBase
+}
+
+// mergeSlavePodRequests merges the slave pod requests into a new slave pod request, the request values win
+func mergeSlavePodRequests(podBase *testkube.PodRequest, podAdjust *testkube.PodRequest) *testkube.PodRequest {
+	switch {
+	case podBase == nil && podAdjust == nil:
+		return nil
+	case podBase == nil && podAdjust != nil:
+		return copyPodRequest(podAdjust)
+	case podBase != nil && podAdjust == nil:
+		return podBase
+	default:
+		podBase = copyPodRequest(podBase)
+		podAdjust = copyPodRequest(podAdjust)
+		var fields = []struct {
+			source      string
+			destination *string
+		}{
+			{
+				podAdjust.PodTemplate,
+				&podBase.PodTemplate,
+			},
+			{
+				podAdjust.PodTemplateReference,
+				&podBase.PodTemplateReference,
+			},
+		}
+
+		for _, field := range fields {
+			if *field.destination == "" && field.source != "" {
+				*field.destination = field.source
+			}
+		}
+
+		if podBase.Resources == nil && podAdjust.Resources != nil {
+			podBase.Resources = podAdjust.Resources
+			return podBase
+		}
+
+		if podBase.Resources != nil && podAdjust.Resources != nil {
+			if podBase.Resources.Requests == nil && podAdjust.Resources.Requests != nil {
+				podBase.Resources.Requests = podAdjust.Resources.Requests
+			} else if podBase.Resources.Requests != nil && podAdjust.Resources.Requests != nil {
+				if podBase.Resources.Requests.Cpu == "" && podAdjust.Resources.Requests.Cpu != "" {
+					podBase.Resources.Requests.Cpu = podAdjust.Resources.Requests.Cpu
+				}
+
+				if podBase.Resources.Requests.Memory == "" && podAdjust.Resources.Requests.Memory != "" {
+					podBase.Resources.Requests.Memory = podAdjust.Resources.Requests.Memory
+				}
+			}
+
+			if podBase.Resources.Limits == nil && podAdjust.Resources.Limits != nil {
+				podBase.Resources.Limits = podAdjust.Resources.Limits
+			} else if podBase.Resources.Limits != nil && podAdjust.Resources.Limits != nil {
+				if podBase.Resources.Limits.Cpu == "" && podAdjust.Resources.Limits.Cpu != "" {
+					podBase.Resources.Limits.Cpu = podAdjust.Resources.Limits.Cpu
+				}
+
+				if podBase.Resources.Limits.Memory == "" && podAdjust.Resources.Limits.Memory != "" {
+					podBase.Resources.Limits.Memory = podAdjust.Resources.Limits.Memory
+				}
+			}
+		}
+
+	}
+
+	return podBase
+}
+
+// copyPodRequest copies the pod request with its resources
+func copyPodRequest(pod *testkube.PodRequest) *testkube.PodRequest {
+	result := *pod
+	if pod.Resources != nil {
+		resources := *pod.Resources
+		if resources.Requests != nil {
+			requests := *resources.Requests
+			resources.Requests = &requests
+		}
+		if resources.Limits != nil {
+			limits := *resources.Limits
+			resources.Limits = &limits
+		}
+		result.Resources = &resources
+	}
+
+	return &result
+}
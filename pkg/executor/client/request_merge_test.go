@@ -0,0 +1,248 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func envReference(name, mountPath string, mapToVariables bool) testkube.EnvReference {
+	return testkube.EnvReference{
+		Reference:      &testkube.LocalObjectReference{Name: name},
+		Mount:          mountPath != "",
+		MountPath:      mountPath,
+		MapToVariables: mapToVariables,
+	}
+}
+
+func TestMergeExecutionRequest(t *testing.T) {
+	tests := map[string]struct {
+		spec     *testkube.ExecutionRequest
+		request  testkube.ExecutionRequest
+		expected testkube.ExecutionRequest
+		origins  []FieldOrigin
+	}{
+		"no test defaults": {
+			spec:     nil,
+			request:  testkube.ExecutionRequest{Name: "exec", Args: []string{"--verbose"}},
+			expected: testkube.ExecutionRequest{Name: "exec", Args: []string{"--verbose"}},
+			origins:  []FieldOrigin{{Field: "args", Source: FieldSourceRequest}},
+		},
+		"nothing set": {
+			spec:     &testkube.ExecutionRequest{},
+			request:  testkube.ExecutionRequest{},
+			expected: testkube.ExecutionRequest{},
+		},
+		"scalar from test": {
+			spec:     &testkube.ExecutionRequest{HttpProxy: "http://proxy", ActiveDeadlineSeconds: 60},
+			request:  testkube.ExecutionRequest{},
+			expected: testkube.ExecutionRequest{HttpProxy: "http://proxy", ActiveDeadlineSeconds: 60},
+			origins:  []FieldOrigin{{Field: "httpProxy", Source: FieldSourceTest}, {Field: "activeDeadlineSeconds", Source: FieldSourceTest}},
+		},
+		"scalar request overrides test": {
+			spec:     &testkube.ExecutionRequest{JobTemplate: "test", ActiveDeadlineSeconds: 60},
+			request:  testkube.ExecutionRequest{JobTemplate: "request", ActiveDeadlineSeconds: 10},
+			expected: testkube.ExecutionRequest{JobTemplate: "request", ActiveDeadlineSeconds: 10},
+			origins:  []FieldOrigin{{Field: "jobTemplate", Source: FieldSourceRequest}, {Field: "activeDeadlineSeconds", Source: FieldSourceRequest}},
+		},
+		"variables file with its upload flag": {
+			spec:     &testkube.ExecutionRequest{VariablesFile: "vars.json", IsVariablesFileUploaded: true},
+			request:  testkube.ExecutionRequest{},
+			expected: testkube.ExecutionRequest{VariablesFile: "vars.json", IsVariablesFileUploaded: true},
+			origins:  []FieldOrigin{{Field: "variablesFile", Source: FieldSourceTest}},
+		},
+		"variables file from request keeps its upload flag": {
+			spec:     &testkube.ExecutionRequest{VariablesFile: "vars.json", IsVariablesFileUploaded: true},
+			request:  testkube.ExecutionRequest{VariablesFile: "{}"},
+			expected: testkube.ExecutionRequest{VariablesFile: "{}"},
+			origins:  []FieldOrigin{{Field: "variablesFile", Source: FieldSourceRequest}},
+		},
+		"envs merged by key with request winning": {
+			spec:     &testkube.ExecutionRequest{Envs: map[string]string{"A": "test", "B": "test"}},
+			request:  testkube.ExecutionRequest{Envs: map[string]string{"A": "request"}},
+			expected: testkube.ExecutionRequest{Envs: map[string]string{"A": "request", "B": "test"}},
+			origins:  []FieldOrigin{{Field: "envs", Source: FieldSourceMerged}},
+		},
+		"secret envs from test": {
+			spec:     &testkube.ExecutionRequest{SecretEnvs: map[string]string{"TOKEN": "secret"}},
+			request:  testkube.ExecutionRequest{},
+			expected: testkube.ExecutionRequest{SecretEnvs: map[string]string{"TOKEN": "secret"}},
+			origins:  []FieldOrigin{{Field: "secretEnvs", Source: FieldSourceTest}},
+		},
+		"variables merged by name with request winning": {
+			spec: &testkube.ExecutionRequest{Variables: map[string]testkube.Variable{
+				"a": testkube.NewBasicVariable("a", "test"),
+				"b": testkube.NewBasicVariable("b", "test"),
+			}},
+			request: testkube.ExecutionRequest{Variables: map[string]testkube.Variable{"a": testkube.NewBasicVariable("a", "request")}},
+			expected: testkube.ExecutionRequest{Variables: map[string]testkube.Variable{
+				"a": testkube.NewBasicVariable("a", "request"),
+				"b": testkube.NewBasicVariable("b", "test"),
+			}},
+			origins: []FieldOrigin{{Field: "variables", Source: FieldSourceMerged}},
+		},
+		"env references merged by name": {
+			spec: &testkube.ExecutionRequest{EnvConfigMaps: []testkube.EnvReference{
+				envReference("settings", "/test", true),
+				envReference("extra", "", false),
+			}},
+			request: testkube.ExecutionRequest{EnvConfigMaps: []testkube.EnvReference{
+				envReference("settings", "", false),
+			}},
+			expected: testkube.ExecutionRequest{EnvConfigMaps: []testkube.EnvReference{
+				envReference("extra", "", false),
+				envReference("settings", "/test", true),
+			}},
+			origins: []FieldOrigin{{Field: "envConfigMaps", Source: FieldSourceMerged}},
+		},
+		"env references request fields win": {
+			spec:     &testkube.ExecutionRequest{EnvSecrets: []testkube.EnvReference{envReference("credentials", "/test", false)}},
+			request:  testkube.ExecutionRequest{EnvSecrets: []testkube.EnvReference{envReference("credentials", "/request", true)}},
+			expected: testkube.ExecutionRequest{EnvSecrets: []testkube.EnvReference{envReference("credentials", "/request", true)}},
+			origins:  []FieldOrigin{{Field: "envSecrets", Source: FieldSourceMerged}},
+		},
+		"command and args replaced by request": {
+			spec:     &testkube.ExecutionRequest{Command: []string{"test"}, Args: []string{"--test"}},
+			request:  testkube.ExecutionRequest{Command: []string{"request"}, Args: []string{"--request"}},
+			expected: testkube.ExecutionRequest{Command: []string{"request"}, Args: []string{"--request"}},
+			origins:  []FieldOrigin{{Field: "command", Source: FieldSourceRequest}, {Field: "args", Source: FieldSourceRequest}},
+		},
+		"command and args from test": {
+			spec:     &testkube.ExecutionRequest{Command: []string{"test"}, Args: []string{"--test"}, ArgsMode: "replace"},
+			request:  testkube.ExecutionRequest{Args: []string{}},
+			expected: testkube.ExecutionRequest{Command: []string{"test"}, Args: []string{"--test"}, ArgsMode: "replace"},
+			origins: []FieldOrigin{
+				{Field: "args_mode", Source: FieldSourceTest},
+				{Field: "command", Source: FieldSourceTest},
+				{Field: "args", Source: FieldSourceTest},
+			},
+		},
+		"flags enabled by test": {
+			spec:     &testkube.ExecutionRequest{SourceScripts: true, ExecutePostRunScriptBeforeScraping: true},
+			request:  testkube.ExecutionRequest{},
+			expected: testkube.ExecutionRequest{SourceScripts: true, ExecutePostRunScriptBeforeScraping: true},
+			origins: []FieldOrigin{
+				{Field: "executePostRunScriptBeforeScraping", Source: FieldSourceTest},
+				{Field: "sourceScripts", Source: FieldSourceTest},
+			},
+		},
+		"negative test from test": {
+			spec:     &testkube.ExecutionRequest{NegativeTest: true},
+			request:  testkube.ExecutionRequest{NegativeTest: false},
+			expected: testkube.ExecutionRequest{NegativeTest: true},
+			origins:  []FieldOrigin{{Field: "negativeTest", Source: FieldSourceTest}},
+		},
+		"negative test changed on run": {
+			spec:     &testkube.ExecutionRequest{NegativeTest: true},
+			request:  testkube.ExecutionRequest{NegativeTest: false, IsNegativeTestChangedOnRun: true},
+			expected: testkube.ExecutionRequest{NegativeTest: false, IsNegativeTestChangedOnRun: true},
+			origins:  []FieldOrigin{{Field: "negativeTest", Source: FieldSourceRequest}},
+		},
+		"artifact request merged with dirs appended": {
+			spec: &testkube.ExecutionRequest{ArtifactRequest: &testkube.ArtifactRequest{
+				Dirs: []string{"test"}, StorageClassName: "standard", VolumeMountPath: "/test",
+			}},
+			request: testkube.ExecutionRequest{ArtifactRequest: &testkube.ArtifactRequest{
+				Dirs: []string{"request"}, Masks: []string{".*\\.xml"}, VolumeMountPath: "/request",
+			}},
+			expected: testkube.ExecutionRequest{ArtifactRequest: &testkube.ArtifactRequest{
+				Dirs: []string{"request", "test"}, Masks: []string{".*\\.xml"}, StorageClassName: "standard", VolumeMountPath: "/request",
+			}},
+			origins: []FieldOrigin{
+				{Field: "artifactRequest", Source: FieldSourceMerged},
+				{Field: "artifactRequest.dirs", Source: FieldSourceMerged},
+				{Field: "artifactRequest.masks", Source: FieldSourceRequest},
+			},
+		},
+		"slave pod request merged": {
+			spec: &testkube.ExecutionRequest{SlavePodRequest: &testkube.PodRequest{
+				PodTemplate: "test", Resources: &testkube.PodResourcesRequest{Limits: &testkube.ResourceRequest{Cpu: "2", Memory: "1Gi"}},
+			}},
+			request: testkube.ExecutionRequest{SlavePodRequest: &testkube.PodRequest{
+				Resources: &testkube.PodResourcesRequest{Limits: &testkube.ResourceRequest{Cpu: "1"}},
+			}},
+			expected: testkube.ExecutionRequest{SlavePodRequest: &testkube.PodRequest{
+				PodTemplate: "test", Resources: &testkube.PodResourcesRequest{Limits: &testkube.ResourceRequest{Cpu: "1", Memory: "1Gi"}},
+			}},
+			origins: []FieldOrigin{{Field: "slavePodRequest", Source: FieldSourceMerged}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, origins := MergeExecutionRequest(testkube.Test{Name: "test", ExecutionRequest: tt.spec}, tt.request)
+
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.origins, origins)
+		})
+	}
+}
+
+func TestMergeExecutionRequest_DoesNotShareTestMaps(t *testing.T) {
+	spec := &testkube.ExecutionRequest{Envs: map[string]string{"A": "test"}}
+
+	result, _ := MergeExecutionRequest(testkube.Test{ExecutionRequest: spec}, testkube.ExecutionRequest{})
+	result.Envs["A"] = "mutated"
+
+	assert.Equal(t, "test", spec.Envs["A"])
+}
+
+func TestMergeExecutionRequest_DoesNotShareTestValues(t *testing.T) {
+	spec := &testkube.ExecutionRequest{
+		Args:            []string{"--test"},
+		ArtifactRequest: &testkube.ArtifactRequest{Dirs: []string{"/test"}, StorageClassName: "standard"},
+		SlavePodRequest: &testkube.PodRequest{Resources: &testkube.PodResourcesRequest{Limits: &testkube.ResourceRequest{Cpu: "1"}}},
+	}
+
+	result, _ := MergeExecutionRequest(testkube.Test{ExecutionRequest: spec}, testkube.ExecutionRequest{})
+	result.Args[0] = "mutated"
+	result.ArtifactRequest.Dirs[0] = "mutated"
+	result.ArtifactRequest.StorageClassName = "mutated"
+	result.SlavePodRequest.Resources.Limits.Cpu = "mutated"
+
+	assert.Equal(t, []string{"--test"}, spec.Args)
+	assert.Equal(t, &testkube.ArtifactRequest{Dirs: []string{"/test"}, StorageClassName: "standard"}, spec.ArtifactRequest)
+	assert.Equal(t, "1", spec.SlavePodRequest.Resources.Limits.Cpu)
+}
+
+func TestMergeExecutionRequest_DoesNotModifyRequest(t *testing.T) {
+	spec := &testkube.ExecutionRequest{
+		ArtifactRequest: &testkube.ArtifactRequest{Dirs: []string{"/test"}, StorageClassName: "standard"},
+		SlavePodRequest: &testkube.PodRequest{Resources: &testkube.PodResourcesRequest{Limits: &testkube.ResourceRequest{Cpu: "1"}}},
+	}
+	dirs := make([]string, 1, 2)
+	dirs[0] = "/request"
+	request := testkube.ExecutionRequest{
+		ArtifactRequest: &testkube.ArtifactRequest{Dirs: dirs},
+		SlavePodRequest: &testkube.PodRequest{Resources: &testkube.PodResourcesRequest{Limits: &testkube.ResourceRequest{Memory: "1Gi"}}},
+	}
+
+	result, _ := MergeExecutionRequest(testkube.Test{ExecutionRequest: spec}, request)
+	assert.Equal(t, []string{"/request", "/test"}, result.ArtifactRequest.Dirs)
+	result.ArtifactRequest.Dirs[0] = "mutated"
+
+	assert.Equal(t, []string{"/request", ""}, dirs[:2])
+	assert.Equal(t, &testkube.ArtifactRequest{Dirs: []string{"/request"}}, request.ArtifactRequest)
+	assert.Equal(t, &testkube.ResourceRequest{Memory: "1Gi"}, request.SlavePodRequest.Resources.Limits)
+	assert.Equal(t, "standard", result.ArtifactRequest.StorageClassName)
+	assert.Equal(t, &testkube.ResourceRequest{Cpu: "1", Memory: "1Gi"}, result.SlavePodRequest.Resources.Limits)
+}
+
+func TestListMergePolicies(t *testing.T) {
+	assert.Equal(t, map[string]ListMergePolicy{
+		"command":               ListReplace,
+		"args":                  ListReplace,
+		"artifactRequest.dirs":  ListAppend,
+		"artifactRequest.masks": ListAppend,
+	}, ListMergePolicies)
+}
+
+func TestMapFieldOriginsToAPI(t *testing.T) {
+	assert.Nil(t, MapFieldOriginsToAPI(nil))
+	assert.Equal(t, map[string]string{"args": "request", "envs": "merged"}, MapFieldOriginsToAPI([]FieldOrigin{
+		{Field: "args", Source: FieldSourceRequest},
+		{Field: "envs", Source: FieldSourceMerged},
+	}))
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+// Resources defines requests and limits of the execution container,
+// values are quantity strings which can contain expressions resolved right before submission
+type Resources struct {
+	Requests ResourceList `json:"requests,omitempty"`
+	Limits   ResourceList `json:"limits,omitempty"`
+}
+
+// ResourceList defines cpu and memory quantities
+type ResourceList struct {
+	CPU    string `json:"cpu,omitempty"`
+	Memory string `json:"memory,omitempty"`
+}
+
+// NewExecutionMachine creates expressions machine exposing execution metadata
+func NewExecutionMachine(execution testkube.Execution) expressionstcl.Machine {
+	return expressionstcl.NewMachine().
+		Register("execution.id", execution.Id).
+		Register("execution.name", execution.Name).
+		Register("execution.number", execution.Number).
+		Register("test.name", execution.TestName).
+		Register("test.namespace", execution.TestNamespace).
+		Register("test.type", execution.TestType).
+		RegisterStringMap("labels", execution.Labels)
+}
+
+// Resolve evaluates expressions in resource values
+func (r Resources) Resolve(machines ...expressionstcl.Machine) (Resources, error) {
+	values := []*string{&r.Requests.CPU, &r.Requests.Memory, &r.Limits.CPU, &r.Limits.Memory}
+	for _, value := range values {
+		if !isExpression(*value) {
+			continue
+		}
+
+		resolved, err := expressionstcl.EvalTemplate(*value, machines...)
+		if err != nil {
+			return r, fmt.Errorf("resolving resource value %s: %w", *value, err)
+		}
+
+		*value = resolved
+	}
+
+	return r, nil
+}
+
+// Validate checks that resolved values are valid quantities and limits are not lower than requests,
+// values still containing expressions are validated after resolution
+func (r Resources) Validate() error {
+	if err := compareQuantities("cpu", r.Requests.CPU, r.Limits.CPU); err != nil {
+		return err
+	}
+
+	return compareQuantities("memory", r.Requests.Memory, r.Limits.Memory)
+}
+
+// ResourceRequirements converts resources to Kubernetes container resource requirements
+func (r Resources) ResourceRequirements() (requirements corev1.ResourceRequirements, err error) {
+	if err = r.Validate(); err != nil {
+		return requirements, err
+	}
+
+	if requirements.Requests, err = r.Requests.resourceList(); err != nil {
+		return requirements, err
+	}
+
+	requirements.Limits, err = r.Limits.resourceList()
+	return requirements, err
+}
+
+func (l ResourceList) resourceList() (corev1.ResourceList, error) {
+	list := corev1.ResourceList{}
+	for name, value := range map[corev1.ResourceName]string{corev1.ResourceCPU: l.CPU, corev1.ResourceMemory: l.Memory} {
+		if value == "" {
+			continue
+		}
+
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s quantity %s: %w", name, value, err)
+		}
+
+		list[name] = quantity
+	}
+
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	return list, nil
+}
+
+func compareQuantities(name, request, limit string) error {
+	hasExpression := false
+	for _, value := range []string{request, limit} {
+		if !isExpression(value) {
+			continue
+		}
+
+		if _, err := expressionstcl.CompileTemplate(value); err != nil {
+			return fmt.Errorf("invalid %s expression %s: %w", name, value, err)
+		}
+
+		hasExpression = true
+	}
+
+	if hasExpression {
+		return nil
+	}
+
+	var requestQuantity, limitQuantity resource.Quantity
+	var err error
+	if request != "" {
+		if requestQuantity, err = resource.ParseQuantity(request); err != nil {
+			return fmt.Errorf("invalid %s request quantity %s: %w", name, request, err)
+		}
+	}
+
+	if limit != "" {
+		if limitQuantity, err = resource.ParseQuantity(limit); err != nil {
+			return fmt.Errorf("invalid %s limit quantity %s: %w", name, limit, err)
+		}
+	}
+
+	if request != "" && limit != "" && limitQuantity.Cmp(requestQuantity) < 0 {
+		return fmt.Errorf("%s limit %s is lower than request %s", name, limit, request)
+	}
+
+	return nil
+}
+
+func isExpression(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// ApplyTo sets resources on the container, keeping other container requests and limits
+func (r Resources) ApplyTo(container *corev1.Container) error {
+	requirements, err := r.ResourceRequirements()
+	if err != nil {
+		return err
+	}
+
+	for name, quantity := range requirements.Requests {
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = corev1.ResourceList{}
+		}
+		container.Resources.Requests[name] = quantity
+	}
+
+	for name, quantity := range requirements.Limits {
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = corev1.ResourceList{}
+		}
+		container.Resources.Limits[name] = quantity
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func TestResourcesValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		resources := Resources{
+			Requests: ResourceList{CPU: "500m", Memory: "512Mi"},
+			Limits:   ResourceList{CPU: "1", Memory: "1Gi"},
+		}
+
+		assert.NoError(t, resources.Validate())
+	})
+
+	t.Run("invalid quantity", func(t *testing.T) {
+		resources := Resources{Requests: ResourceList{Memory: "lots"}}
+
+		assert.ErrorContains(t, resources.Validate(), "invalid memory request quantity lots")
+	})
+
+	t.Run("limit lower than request", func(t *testing.T) {
+		resources := Resources{
+			Requests: ResourceList{CPU: "2"},
+			Limits:   ResourceList{CPU: "500m"},
+		}
+
+		assert.EqualError(t, resources.Validate(), "cpu limit 500m is lower than request 2")
+	})
+
+	t.Run("expression postponed", func(t *testing.T) {
+		resources := Resources{
+			Requests: ResourceList{Memory: `{{ test.type == "cypress/project" ? "2Gi" : "256Mi" }}`},
+			Limits:   ResourceList{Memory: "128Mi"},
+		}
+
+		assert.NoError(t, resources.Validate())
+	})
+
+	t.Run("invalid expression", func(t *testing.T) {
+		resources := Resources{Limits: ResourceList{CPU: "{{ 1 + }}"}}
+
+		assert.ErrorContains(t, resources.Validate(), "invalid cpu expression")
+	})
+}
+
+func TestResourcesResolve(t *testing.T) {
+	execution := testkube.Execution{
+		Id:       "exec-1",
+		TestName: "browser",
+		TestType: "cypress/project",
+		Labels:   map[string]string{"size": "large"},
+	}
+	resources := Resources{
+		Requests: ResourceList{
+			CPU:    "250m",
+			Memory: `{{ test.type == "cypress/project" ? "2Gi" : "256Mi" }}`,
+		},
+		Limits: ResourceList{
+			Memory: `{{ labels.size == "large" ? "4Gi" : "512Mi" }}`,
+		},
+	}
+
+	resolved, err := resources.Resolve(NewExecutionMachine(execution))
+
+	assert.NoError(t, err)
+	assert.Equal(t, Resources{
+		Requests: ResourceList{CPU: "250m", Memory: "2Gi"},
+		Limits:   ResourceList{Memory: "4Gi"},
+	}, resolved)
+	assert.NoError(t, resolved.Validate())
+}
+
+func TestResourcesResourceRequirements(t *testing.T) {
+	resources := Resources{
+		Requests: ResourceList{Memory: "512Mi"},
+		Limits:   ResourceList{CPU: "1", Memory: "1Gi"},
+	}
+
+	requirements, err := resources.ResourceRequirements()
+
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}, requirements)
+}
+
+func TestExecuteOptionsValidateResources(t *testing.T) {
+	options := ExecuteOptions{ID: "exec", TestName: "test", Resources: &Resources{
+		Requests: ResourceList{Memory: "1Gi"},
+		Limits:   ResourceList{Memory: "512Mi"},
+	}}
+
+	assert.EqualError(t, options.Validate(), "memory limit 512Mi is lower than request 1Gi")
+}
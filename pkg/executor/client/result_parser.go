@@ -0,0 +1,295 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// OutputFormat is a format of the execution container output, empty format is the native testkube runner protocol
+type OutputFormat string
+
+const (
+	// OutputFormatNative is the testkube runner JSON output, it's parsed by the output package
+	OutputFormatNative OutputFormat = ""
+	// OutputFormatJUnit is JUnit XML report printed by the container
+	OutputFormatJUnit OutputFormat = "junit"
+	// OutputFormatTAP is Test Anything Protocol output printed by the container
+	OutputFormatTAP OutputFormat = "tap"
+)
+
+// ErrUnknownOutputFormat is returned for output format without registered parser
+var ErrUnknownOutputFormat = errors.New("unknown output format")
+
+// ResultParser converts execution container output to execution result, containers not speaking
+// the native protocol are supported this way; on error the returned result keeps the raw output
+type ResultParser interface {
+	Parse(output []byte) (testkube.ExecutionResult, error)
+}
+
+// ResultParserFunc is a function implementing ResultParser
+type ResultParserFunc func(output []byte) (testkube.ExecutionResult, error)
+
+// Parse calls the function
+func (f ResultParserFunc) Parse(output []byte) (testkube.ExecutionResult, error) {
+	return f(output)
+}
+
+// ResultParsers are result parsers by output format
+type ResultParsers map[OutputFormat]ResultParser
+
+// DefaultResultParsers returns built-in result parsers
+func DefaultResultParsers() ResultParsers {
+	return ResultParsers{
+		OutputFormatJUnit: ResultParserFunc(ParseJUnitOutput),
+		OutputFormatTAP:   ResultParserFunc(ParseTAPOutput),
+	}
+}
+
+// Get returns parser for the output format, native format has no parser
+func (p ResultParsers) Get(format OutputFormat) (ResultParser, error) {
+	parser, ok := p[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownOutputFormat, format)
+	}
+
+	return parser, nil
+}
+
+// Validate checks that the output format is native or has registered parser
+func (p ResultParsers) Validate(format OutputFormat) error {
+	if format == OutputFormatNative {
+		return nil
+	}
+
+	_, err := p.Get(format)
+	return err
+}
+
+// newParsedResult creates result with the raw output attached, status is set by the parser
+func newParsedResult(output []byte) testkube.ExecutionResult {
+	return testkube.ExecutionResult{
+		Output:     string(output),
+		OutputType: "text/plain",
+	}
+}
+
+// failParsedResult marks the result failed because the output can't be parsed, the raw output is kept
+func failParsedResult(result testkube.ExecutionResult, err error) (testkube.ExecutionResult, error) {
+	result.Status = testkube.ExecutionStatusFailed
+	result.ErrorMessage = err.Error()
+	return result, err
+}
+
+// finishParsedResult sets the result status from the steps, skipped steps don't fail the result,
+// the error message counts failed steps
+func finishParsedResult(result testkube.ExecutionResult) testkube.ExecutionResult {
+	failed := 0
+	for _, step := range result.Steps {
+		if step.Status == string(testkube.FAILED_ExecutionStatus) {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		result.Status = testkube.ExecutionStatusFailed
+		result.ErrorMessage = fmt.Sprintf("%d of %d tests failed", failed, len(result.Steps))
+		return result
+	}
+
+	result.Status = testkube.ExecutionStatusPassed
+	return result
+}
+
+// ParseJUnitOutput parses JUnit XML report, text printed before the report is ignored;
+// every test case is a step named after its class name and name, nested suites are included
+func ParseJUnitOutput(output []byte) (testkube.ExecutionResult, error) {
+	result := newParsedResult(output)
+
+	report := output
+	for _, prefix := range []string{"<?xml", "<testsuite"} {
+		if i := bytes.Index(output, []byte(prefix)); i >= 0 {
+			report = output[i:]
+			break
+		}
+	}
+
+	suites, err := junit.Ingest(report)
+	if err != nil {
+		return failParsedResult(result, fmt.Errorf("parsing junit report: %w", err))
+	}
+	if len(suites) == 0 {
+		return failParsedResult(result, errors.New("parsing junit report: no test suites found"))
+	}
+
+	result.Steps = junitSteps(suites)
+	return finishParsedResult(result), nil
+}
+
+func junitSteps(suites []junit.Suite) (steps []testkube.ExecutionStepResult) {
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			name := test.Name
+			if test.Classname != "" {
+				name = test.Classname + "." + test.Name
+			}
+
+			step := testkube.ExecutionStepResult{
+				Name:     name,
+				Duration: test.Duration.String(),
+				Status:   junitStepStatus(test.Status),
+			}
+			if test.Error != nil {
+				step.AssertionResults = []testkube.AssertionResult{{
+					Name:         name,
+					Status:       step.Status,
+					ErrorMessage: junitErrorMessage(test.Error),
+				}}
+			}
+			steps = append(steps, step)
+		}
+
+		steps = append(steps, junitSteps(suite.Suites)...)
+	}
+
+	return steps
+}
+
+// junitErrorMessage prefers the failure message over the body, which is usually a stack trace
+func junitErrorMessage(err error) string {
+	if junitErr, ok := err.(junit.Error); ok && strings.TrimSpace(junitErr.Message) != "" {
+		return junitErr.Message
+	}
+
+	return err.Error()
+}
+
+func junitStepStatus(status junit.Status) string {
+	switch status {
+	case junit.StatusPassed:
+		return string(testkube.PASSED_ExecutionStatus)
+	case junit.StatusSkipped:
+		return string(testkube.SKIPPED_ExecutionStatus)
+	default:
+		return string(testkube.FAILED_ExecutionStatus)
+	}
+}
+
+var (
+	tapPlan   = regexp.MustCompile(`^1\.\.(\d+)`)
+	tapResult = regexp.MustCompile(`^(not ok|ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(.*))?$`)
+)
+
+// ParseTAPOutput parses Test Anything Protocol output, every test point is a step; failure messages
+// and durations are read from the YAML diagnostic block following the test point (message and duration_ms keys),
+// skipped and todo tests don't fail the result, missing tests according to the plan and bail out do
+func ParseTAPOutput(output []byte) (testkube.ExecutionResult, error) {
+	result := newParsedResult(output)
+
+	planned := -1
+	bailedOut, bailOutReason := false, ""
+	inDiagnostics := false
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if inDiagnostics {
+			if trimmed == "..." {
+				inDiagnostics = false
+			} else if len(result.Steps) != 0 {
+				tapDiagnostic(&result.Steps[len(result.Steps)-1], trimmed)
+			}
+			continue
+		}
+
+		switch {
+		case trimmed == "---" && len(result.Steps) != 0:
+			inDiagnostics = true
+		case strings.HasPrefix(trimmed, "Bail out!"):
+			bailedOut, bailOutReason = true, strings.TrimSpace(strings.TrimPrefix(trimmed, "Bail out!"))
+		case tapPlan.MatchString(trimmed):
+			planned, _ = strconv.Atoi(tapPlan.FindStringSubmatch(trimmed)[1])
+		case strings.HasPrefix(line, "ok") || strings.HasPrefix(line, "not ok"):
+			if match := tapResult.FindStringSubmatch(trimmed); match != nil {
+				result.Steps = append(result.Steps, tapStep(match, len(result.Steps)+1))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return failParsedResult(result, fmt.Errorf("reading tap output: %w", err))
+	}
+
+	if len(result.Steps) == 0 && planned != 0 && !bailedOut {
+		return failParsedResult(result, errors.New("parsing tap output: no test results found"))
+	}
+
+	result = finishParsedResult(result)
+	switch {
+	case bailedOut:
+		result.Status = testkube.ExecutionStatusFailed
+		result.ErrorMessage = "bail out"
+		if bailOutReason != "" {
+			result.ErrorMessage += ": " + bailOutReason
+		}
+	case planned >= 0 && planned != len(result.Steps):
+		result.Status = testkube.ExecutionStatusFailed
+		result.ErrorMessage = fmt.Sprintf("planned %d tests, but %d ran", planned, len(result.Steps))
+	}
+
+	return result, nil
+}
+
+func tapStep(match []string, number int) testkube.ExecutionStepResult {
+	name := match[3]
+	if name == "" {
+		name = match[2]
+	}
+	if name == "" {
+		name = strconv.Itoa(number)
+	}
+
+	status := testkube.PASSED_ExecutionStatus
+	if match[1] == "not ok" {
+		status = testkube.FAILED_ExecutionStatus
+	}
+
+	directive := strings.ToUpper(match[4])
+	if strings.HasPrefix(directive, "SKIP") || strings.HasPrefix(directive, "TODO") {
+		status = testkube.SKIPPED_ExecutionStatus
+	}
+
+	return testkube.ExecutionStepResult{Name: name, Status: string(status)}
+}
+
+// tapDiagnostic reads failure message and duration from the line of the YAML diagnostic block
+func tapDiagnostic(step *testkube.ExecutionStepResult, line string) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return
+	}
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+	switch strings.TrimSpace(key) {
+	case "message":
+		step.AssertionResults = append(step.AssertionResults, testkube.AssertionResult{
+			Name:         step.Name,
+			Status:       step.Status,
+			ErrorMessage: value,
+		})
+	case "duration_ms":
+		if ms, err := strconv.ParseFloat(value, 64); err == nil {
+			step.Duration = time.Duration(ms * float64(time.Millisecond)).String()
+		}
+	}
+}
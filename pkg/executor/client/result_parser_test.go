@@ -0,0 +1,152 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return data
+}
+
+func stepStatuses(result testkube.ExecutionResult) map[string]string {
+	statuses := make(map[string]string)
+	for _, step := range result.Steps {
+		statuses[step.Name] = step.Status
+	}
+	return statuses
+}
+
+func TestParseJUnitOutput(t *testing.T) {
+	output := readFixture(t, "junit.xml")
+
+	result, err := ParseJUnitOutput(output)
+
+	require.NoError(t, err)
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, "2 of 5 tests failed", result.ErrorMessage)
+	assert.Equal(t, string(output), result.Output)
+	assert.Equal(t, map[string]string{
+		"api.users.creates user":   "passed",
+		"api.users.deletes user":   "failed",
+		"api.orders.lists orders":  "failed",
+		"api.orders.cancels order": "skipped",
+		"nested case":              "passed",
+	}, stepStatuses(result))
+
+	assert.Equal(t, "500ms", result.Steps[0].Duration)
+	require.Len(t, result.Steps[1].AssertionResults, 1)
+	assert.Equal(t, "expected 204, got 500", result.Steps[1].AssertionResults[0].ErrorMessage)
+	require.Len(t, result.Steps[2].AssertionResults, 1)
+	assert.Equal(t, "connection refused", result.Steps[2].AssertionResults[0].ErrorMessage)
+	assert.Empty(t, result.Steps[3].AssertionResults)
+}
+
+func TestParseJUnitOutput_Passed(t *testing.T) {
+	result, err := ParseJUnitOutput([]byte(`<testsuite name="unit"><testcase name="adds" time="0.1"/></testsuite>`))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsPassed())
+	assert.Empty(t, result.ErrorMessage)
+	assert.Equal(t, []testkube.ExecutionStepResult{{Name: "adds", Duration: "100ms", Status: "passed"}}, result.Steps)
+}
+
+func TestParseJUnitOutput_Malformed(t *testing.T) {
+	output := readFixture(t, "junit_malformed.xml")
+
+	result, err := ParseJUnitOutput(output)
+
+	assert.ErrorContains(t, err, "parsing junit report")
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, err.Error(), result.ErrorMessage)
+	assert.Equal(t, string(output), result.Output)
+	assert.Empty(t, result.Steps)
+
+	result, err = ParseJUnitOutput([]byte("no report printed"))
+	assert.Error(t, err)
+	assert.Equal(t, "no report printed", result.Output)
+}
+
+func TestParseTAPOutput(t *testing.T) {
+	output := readFixture(t, "tap.txt")
+
+	result, err := ParseTAPOutput(output)
+
+	require.NoError(t, err)
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, "1 of 5 tests failed", result.ErrorMessage)
+	assert.Equal(t, string(output), result.Output)
+	assert.Equal(t, map[string]string{
+		"creates user":  "passed",
+		"deletes user":  "failed",
+		"lists orders":  "skipped",
+		"cancels order": "skipped",
+		"5":             "passed",
+	}, stepStatuses(result))
+
+	assert.Equal(t, "250ms", result.Steps[1].Duration)
+	require.Len(t, result.Steps[1].AssertionResults, 1)
+	assert.Equal(t, "expected 204, got 500", result.Steps[1].AssertionResults[0].ErrorMessage)
+}
+
+func TestParseTAPOutput_Plan(t *testing.T) {
+	result, err := ParseTAPOutput([]byte("1..2\nok 1 - first\nok 2 - second\n"))
+	require.NoError(t, err)
+	assert.True(t, result.IsPassed())
+
+	result, err = ParseTAPOutput([]byte("1..3\nok 1 - first\nok 2 - second\n"))
+	require.NoError(t, err)
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, "planned 3 tests, but 2 ran", result.ErrorMessage)
+
+	result, err = ParseTAPOutput(readFixture(t, "tap_bail_out.txt"))
+	require.NoError(t, err)
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, "bail out: database unavailable", result.ErrorMessage)
+
+	result, err = ParseTAPOutput([]byte("exit code 1\n"))
+	assert.Error(t, err)
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, "exit code 1\n", result.Output)
+}
+
+func TestResultParsers(t *testing.T) {
+	parsers := DefaultResultParsers()
+
+	assert.NoError(t, parsers.Validate(OutputFormatNative))
+	assert.NoError(t, parsers.Validate(OutputFormatJUnit))
+	assert.NoError(t, parsers.Validate(OutputFormatTAP))
+	assert.ErrorIs(t, parsers.Validate("xunit"), ErrUnknownOutputFormat)
+
+	parser, err := parsers.Get(OutputFormatTAP)
+	require.NoError(t, err)
+	result, err := parser.Parse([]byte("ok 1 - first\n"))
+	require.NoError(t, err)
+	assert.True(t, result.IsPassed())
+}
+
+func TestJobExecutor_parseOutput(t *testing.T) {
+	c := (&JobExecutor{}).WithResultParsers(DefaultResultParsers())
+
+	result, err := c.parseOutput([]byte("ok 1 - first\n"), OutputFormatTAP, false)
+	require.NoError(t, err)
+	assert.True(t, result.IsPassed())
+	assert.Empty(t, result.Output)
+
+	result, err = c.parseOutput([]byte("<testsuite"), OutputFormatJUnit, false)
+	assert.Error(t, err)
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, "<testsuite", result.Output)
+
+	result, err = c.parseOutput([]byte("ok 1 - first\n"), "xunit", true)
+	assert.ErrorIs(t, err, ErrUnknownOutputFormat)
+	assert.True(t, result.IsFailed())
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+	"github.com/kubeshop/testkube/pkg/utils"
+)
+
+const (
+	// ReportFormatJUnit is a format of report parsed from JUnit XML
+	ReportFormatJUnit = "junit"
+	// ReportFormatJSON is a format of report parsed from JSON output
+	ReportFormatJSON = "json"
+)
+
+// NewResultMachine creates expressions machine for finished execution, it exposes result status, duration,
+// output, steps and artifacts under result, report parsed from JUnit or JSON output is available as result.report;
+// the duration is available both as result.durationSeconds number and result.duration formatted string,
+// start and end times as result.startedAtUnix and result.finishedAtUnix seconds, 0 when unknown
+func NewResultMachine(execution testkube.Execution) expressionstcl.Machine {
+	return expressionstcl.NewVarsMachine(map[string]interface{}{"result": resultVars(execution)})
+}
+
+// EvaluateOnResult evaluates expression over the execution result, e.g. to decide about notifications
+func EvaluateOnResult(expr string, execution testkube.Execution) (interface{}, error) {
+	return expressionstcl.EvalString(expr, nil, expressionstcl.WithMachines(
+		NewResultMachine(execution),
+		NewExecutionMachine(execution),
+		NewArtifactsMachine(execution.ExecutionResult),
+	))
+}
+
+func resultVars(execution testkube.Execution) map[string]interface{} {
+	duration := resultDuration(execution)
+	vars := map[string]interface{}{
+		"status":          "",
+		"output":          "",
+		"outputType":      "",
+		"errorMessage":    "",
+		"durationSeconds": duration.Seconds(),
+		"duration":        formatDuration(duration),
+		"startedAtUnix":   unixSeconds(execution.StartTime),
+		"finishedAtUnix":  unixSeconds(execution.EndTime),
+		"steps":           []interface{}{},
+		"artifacts":       []interface{}{},
+	}
+
+	result := execution.ExecutionResult
+	if result == nil {
+		return vars
+	}
+
+	if result.Status != nil {
+		vars["status"] = string(*result.Status)
+	}
+
+	vars["output"] = result.Output
+	vars["outputType"] = result.OutputType
+	vars["errorMessage"] = result.ErrorMessage
+
+	steps := make([]interface{}, len(result.Steps))
+	for i, step := range result.Steps {
+		steps[i] = map[string]interface{}{
+			"name":     step.Name,
+			"status":   step.Status,
+			"duration": step.Duration,
+		}
+	}
+	vars["steps"] = steps
+
+	artifacts := make([]interface{}, len(result.Artifacts))
+	for i, artifact := range result.Artifacts {
+		artifacts[i] = map[string]interface{}{
+			"name":     artifact.Name,
+			"size":     artifact.Size,
+			"status":   artifact.Status,
+			"checksum": artifact.Checksum,
+		}
+	}
+	vars["artifacts"] = artifacts
+
+	if report := parseReport(*result); report != nil {
+		vars["report"] = report
+	}
+
+	return vars
+}
+
+// resultDuration returns the execution duration with the millisecond precision of execution.DurationMs,
+// both the numeric and formatted durations are computed from it, so they are always consistent
+func resultDuration(execution testkube.Execution) time.Duration {
+	if execution.DurationMs > 0 {
+		return time.Duration(execution.DurationMs) * time.Millisecond
+	}
+
+	if !execution.StartTime.IsZero() && execution.EndTime.After(execution.StartTime) {
+		return utils.RoundDuration(execution.EndTime.Sub(execution.StartTime), time.Millisecond)
+	}
+
+	return 0
+}
+
+// formatDuration formats the duration for display, i.e. "1m32.4s"
+func formatDuration(duration time.Duration) string {
+	return utils.RoundDuration(duration, time.Millisecond).String()
+}
+
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+// parseReport parses JUnit report or output, or JSON output, it returns nil when the format is not recognized
+func parseReport(result testkube.ExecutionResult) map[string]interface{} {
+	source := result.Output
+	if result.Reports != nil && result.Reports.Junit != "" {
+		source = result.Reports.Junit
+	}
+
+	source = strings.TrimSpace(source)
+	if strings.HasPrefix(source, "<") {
+		return parseJUnitReport(source)
+	}
+
+	if strings.HasPrefix(source, "{") || strings.HasPrefix(source, "[") {
+		var data interface{}
+		if err := json.Unmarshal([]byte(source), &data); err == nil {
+			return map[string]interface{}{"format": ReportFormatJSON, "data": data}
+		}
+	}
+
+	return nil
+}
+
+func parseJUnitReport(source string) map[string]interface{} {
+	suites, err := junit.Ingest([]byte(source))
+	if err != nil || len(suites) == 0 {
+		return nil
+	}
+
+	var totals junit.Totals
+	for _, suite := range suites {
+		totals.Tests += suite.Totals.Tests
+		totals.Passed += suite.Totals.Passed
+		totals.Failed += suite.Totals.Failed
+		totals.Error += suite.Totals.Error
+		totals.Skipped += suite.Totals.Skipped
+		totals.Duration += suite.Totals.Duration
+	}
+
+	passRate := 0.0
+	if totals.Tests > 0 {
+		passRate = float64(totals.Passed) / float64(totals.Tests)
+	}
+
+	return map[string]interface{}{
+		"format":          ReportFormatJUnit,
+		"suites":          len(suites),
+		"tests":           totals.Tests,
+		"passed":          totals.Passed,
+		"failed":          totals.Failed,
+		"errors":          totals.Error,
+		"skipped":         totals.Skipped,
+		"passRate":        passRate,
+		"durationSeconds": totals.Duration.Seconds(),
+	}
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+const (
+	// DefaultResultsCacheSize is a maximum number of cached executions
+	DefaultResultsCacheSize = 1000
+	// DefaultResultsCacheTTL is how long result of unfinished execution is cached
+	DefaultResultsCacheTTL = 2 * time.Second
+)
+
+// ResultsCacheMetrics observes results cache lookups
+type ResultsCacheMetrics interface {
+	IncResultsCacheHit()
+	IncResultsCacheMiss()
+}
+
+// ResultsCacheStats are numbers of results cache lookups
+type ResultsCacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+type cachedExecution struct {
+	id        string
+	execution testkube.Execution
+	// expiresAt is zero for finished executions, as their results don't change anymore
+	expiresAt time.Time
+}
+
+// ResultsCache is read-through LRU cache of executions, finished executions are cached until they're evicted,
+// while unfinished ones only for the TTL; cached executions are shared, so they mustn't be modified
+type ResultsCache struct {
+	executions ExecutionGetter
+	clock      Clock
+	size       int
+	ttl        time.Duration
+	metrics    ResultsCacheMetrics
+
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List
+	generation uint64
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+}
+
+// NewResultsCache creates new results cache reading through to the executions
+func NewResultsCache(executions ExecutionGetter, clock Clock) *ResultsCache {
+	if clock == nil {
+		clock = NewRealClock()
+	}
+
+	return &ResultsCache{
+		executions: executions,
+		clock:      clock,
+		size:       DefaultResultsCacheSize,
+		ttl:        DefaultResultsCacheTTL,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// WithSize sets maximum number of cached executions, least recently used ones are evicted first
+func (c *ResultsCache) WithSize(size int) *ResultsCache {
+	c.size = size
+	return c
+}
+
+// WithTTL sets how long result of unfinished execution is cached
+func (c *ResultsCache) WithTTL(ttl time.Duration) *ResultsCache {
+	c.ttl = ttl
+	return c
+}
+
+// WithMetrics sets metrics observing cache hits and misses
+func (c *ResultsCache) WithMetrics(metrics ResultsCacheMetrics) *ResultsCache {
+	c.metrics = metrics
+	return c
+}
+
+// Get returns cached execution, or gets it from the executions when it's not cached or expired
+func (c *ResultsCache) Get(ctx context.Context, id string) (testkube.Execution, error) {
+	c.mu.Lock()
+	if element, ok := c.items[id]; ok {
+		cached := element.Value.(*cachedExecution)
+		if cached.expiresAt.IsZero() || c.clock.Now().Before(cached.expiresAt) {
+			c.order.MoveToFront(element)
+			c.mu.Unlock()
+			c.hit()
+			return cached.execution, nil
+		}
+		c.remove(element)
+	}
+	generation := c.generation
+	c.mu.Unlock()
+	c.miss()
+
+	execution, err := c.executions.Get(ctx, id)
+	if err != nil {
+		return execution, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the execution may be outdated already when it was invalidated while it was fetched
+	if generation == c.generation {
+		c.add(id, execution)
+	}
+
+	return execution, nil
+}
+
+// Invalidate removes the execution from the cache, e.g. when its status changed
+func (c *ResultsCache) Invalidate(id string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if element, ok := c.items[id]; ok {
+		c.remove(element)
+	}
+}
+
+// Stats returns numbers of cache hits and misses
+func (c *ResultsCache) Stats() ResultsCacheStats {
+	return ResultsCacheStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+func (c *ResultsCache) add(id string, execution testkube.Execution) {
+	if c.size <= 0 {
+		return
+	}
+
+	cached := &cachedExecution{id: id, execution: execution}
+	if _, ok := FinishedResult(execution); !ok {
+		cached.expiresAt = c.clock.Now().Add(c.ttl)
+	}
+
+	if element, ok := c.items[id]; ok {
+		element.Value = cached
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[id] = c.order.PushFront(cached)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *ResultsCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*cachedExecution).id)
+}
+
+func (c *ResultsCache) hit() {
+	c.hits.Add(1)
+	if c.metrics != nil {
+		c.metrics.IncResultsCacheHit()
+	}
+}
+
+func (c *ResultsCache) miss() {
+	c.misses.Add(1)
+	if c.metrics != nil {
+		c.metrics.IncResultsCacheMiss()
+	}
+}
+
+// ResultsCachingExecutor invalidates cached execution when it's aborted
+type ResultsCachingExecutor struct {
+	Executor
+	cache *ResultsCache
+}
+
+// NewResultsCachingExecutor creates new executor invalidating the cache on abort
+func NewResultsCachingExecutor(executor Executor, cache *ResultsCache) *ResultsCachingExecutor {
+	return &ResultsCachingExecutor{Executor: executor, cache: cache}
+}
+
+// Abort aborts the execution with the wrapped executor, the cached execution is invalidated even when abort fails,
+// as it's not known whether the execution changed
+func (e *ResultsCachingExecutor) Abort(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
+	defer e.cache.Invalidate(execution.Id)
+	return e.Executor.Abort(ctx, execution)
+}
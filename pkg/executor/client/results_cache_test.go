@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// countingExecutions returns stored executions and counts the lookups
+type countingExecutions struct {
+	mu         sync.Mutex
+	executions map[string]testkube.ExecutionStatus
+	gets       int
+}
+
+func newCountingExecutions() *countingExecutions {
+	return &countingExecutions{executions: make(map[string]testkube.ExecutionStatus)}
+}
+
+func (e *countingExecutions) Set(id string, status testkube.ExecutionStatus) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.executions[id] = status
+}
+
+func (e *countingExecutions) Get(ctx context.Context, id string) (testkube.Execution, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.gets++
+	status, ok := e.executions[id]
+	if !ok {
+		return testkube.Execution{}, fmt.Errorf("execution %s not found", id)
+	}
+	return testkube.Execution{Id: id, ExecutionResult: &testkube.ExecutionResult{Status: testkube.StatusPtr(status)}}, nil
+}
+
+func (e *countingExecutions) Gets() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.gets
+}
+
+type countingCacheMetrics struct {
+	mu           sync.Mutex
+	hits, misses int
+}
+
+func (m *countingCacheMetrics) IncResultsCacheHit() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hits++
+}
+
+func (m *countingCacheMetrics) IncResultsCacheMiss() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.misses++
+}
+
+func TestResultsCache_RunningExpires(t *testing.T) {
+	executions := newCountingExecutions()
+	executions.Set("exec", testkube.RUNNING_ExecutionStatus)
+	clock := newFakeClock(time.Now())
+	metrics := &countingCacheMetrics{}
+	cache := NewResultsCache(executions, clock).WithTTL(5 * time.Second).WithMetrics(metrics)
+
+	for i := 0; i < 3; i++ {
+		execution, err := cache.Get(context.Background(), "exec")
+		require.NoError(t, err)
+		assert.Equal(t, testkube.RUNNING_ExecutionStatus, *execution.ExecutionResult.Status)
+	}
+	assert.Equal(t, 1, executions.Gets())
+
+	executions.Set("exec", testkube.PASSED_ExecutionStatus)
+	clock.Advance(4 * time.Second)
+	execution, err := cache.Get(context.Background(), "exec")
+	require.NoError(t, err)
+	assert.Equal(t, testkube.RUNNING_ExecutionStatus, *execution.ExecutionResult.Status)
+
+	clock.Advance(time.Second)
+	execution, err = cache.Get(context.Background(), "exec")
+	require.NoError(t, err)
+	assert.Equal(t, testkube.PASSED_ExecutionStatus, *execution.ExecutionResult.Status)
+	assert.Equal(t, 2, executions.Gets())
+
+	assert.Equal(t, ResultsCacheStats{Hits: 3, Misses: 2}, cache.Stats())
+	assert.Equal(t, 3, metrics.hits)
+	assert.Equal(t, 2, metrics.misses)
+}
+
+func TestResultsCache_FinishedDoesNotExpire(t *testing.T) {
+	executions := newCountingExecutions()
+	executions.Set("exec", testkube.FAILED_ExecutionStatus)
+	clock := newFakeClock(time.Now())
+	cache := NewResultsCache(executions, clock)
+
+	_, err := cache.Get(context.Background(), "exec")
+	require.NoError(t, err)
+	clock.Advance(24 * time.Hour)
+	execution, err := cache.Get(context.Background(), "exec")
+	require.NoError(t, err)
+	assert.Equal(t, testkube.FAILED_ExecutionStatus, *execution.ExecutionResult.Status)
+	assert.Equal(t, 1, executions.Gets())
+}
+
+func TestResultsCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	executions := newCountingExecutions()
+	for _, id := range []string{"a", "b", "c"} {
+		executions.Set(id, testkube.PASSED_ExecutionStatus)
+	}
+	cache := NewResultsCache(executions, newFakeClock(time.Now())).WithSize(2)
+
+	for _, id := range []string{"a", "b", "a", "c"} {
+		_, err := cache.Get(context.Background(), id)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, executions.Gets())
+
+	// "b" was used least recently, so it was evicted by "c"
+	_, err := cache.Get(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, 3, executions.Gets())
+	_, err = cache.Get(context.Background(), "b")
+	require.NoError(t, err)
+	assert.Equal(t, 4, executions.Gets())
+}
+
+func TestResultsCache_ErrorIsNotCached(t *testing.T) {
+	executions := newCountingExecutions()
+	cache := NewResultsCache(executions, newFakeClock(time.Now()))
+
+	_, err := cache.Get(context.Background(), "missing")
+	assert.Error(t, err)
+	_, err = cache.Get(context.Background(), "missing")
+	assert.Error(t, err)
+	assert.Equal(t, 2, executions.Gets())
+}
+
+func TestResultsCachingExecutor_InvalidatesOnAbort(t *testing.T) {
+	executions := newCountingExecutions()
+	executions.Set("exec", testkube.RUNNING_ExecutionStatus)
+	cache := NewResultsCache(executions, newFakeClock(time.Now()))
+	executor := NewResultsCachingExecutor(NewFakeExecutor(t), cache)
+
+	_, err := cache.Get(context.Background(), "exec")
+	require.NoError(t, err)
+
+	executions.Set("exec", testkube.ABORTED_ExecutionStatus)
+	_, err = executor.Abort(context.Background(), &testkube.Execution{Id: "exec"})
+	require.NoError(t, err)
+
+	execution, err := cache.Get(context.Background(), "exec")
+	require.NoError(t, err)
+	assert.Equal(t, testkube.ABORTED_ExecutionStatus, *execution.ExecutionResult.Status)
+	assert.Equal(t, 2, executions.Gets())
+}
+
+func TestExecutionRunner_ResultsCacheInvalidatedOnTransition(t *testing.T) {
+	fake := NewFakeExecutor(t)
+	fake.ExpectExecution("test").WithStatuses(testkube.RUNNING_ExecutionStatus, testkube.PASSED_ExecutionStatus)
+	cache := NewResultsCache(fake, newFakeClock(time.Now()))
+	runner := newTestExecutionRunner(fake, fake, &recordingClock{}).WithResultsCache(cache)
+
+	id, err := runner.ExecuteAsync(context.Background(), testkube.NewExecutionWithID("exec", "", "test"), ExecuteOptions{TestName: "test"})
+	require.NoError(t, err)
+
+	execution, err := runner.GetExecution(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, testkube.RUNNING_ExecutionStatus, *execution.ExecutionResult.Status)
+
+	events, err := runner.WatchExecution(context.Background(), id)
+	require.NoError(t, err)
+	for event := range events {
+		require.NoError(t, event.Err)
+	}
+
+	// the cached running result hasn't expired, but the watch has seen it finished
+	execution, err = runner.GetExecution(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, testkube.PASSED_ExecutionStatus, *execution.ExecutionResult.Status)
+}
+
+func TestResultsCache_Concurrent(t *testing.T) {
+	executions := newCountingExecutions()
+	for i := 0; i < 10; i++ {
+		executions.Set(fmt.Sprintf("exec-%d", i), testkube.RUNNING_ExecutionStatus)
+	}
+	clock := newFakeClock(time.Now())
+	cache := NewResultsCache(executions, clock).WithSize(5)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				id := fmt.Sprintf("exec-%d", (i+j)%10)
+				_, err := cache.Get(context.Background(), id)
+				assert.NoError(t, err)
+				if j%10 == 0 {
+					cache.Invalidate(id)
+					clock.Advance(time.Second)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2000), stats.Hits+stats.Misses)
+	assert.Equal(t, int(stats.Misses), executions.Gets())
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+const resultsJUnitReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="api" tests="3">
+    <testcase name="get" time="0.5"/>
+    <testcase name="post" time="1"><failure message="expected 201"/></testcase>
+    <testcase name="delete" time="0.5"/>
+  </testsuite>
+  <testsuite name="ui" tests="1">
+    <testcase name="login" time="2"/>
+  </testsuite>
+</testsuites>`
+
+func resultExecution(status testkube.ExecutionStatus, output string) testkube.Execution {
+	return testkube.Execution{
+		Id:         "execution-1",
+		TestName:   "api-tests",
+		DurationMs: 12500,
+		ExecutionResult: &testkube.ExecutionResult{
+			Status: &status,
+			Output: output,
+			Steps: []testkube.ExecutionStepResult{
+				{Name: "get", Status: "success"},
+				{Name: "post", Status: "error"},
+			},
+			Artifacts: []testkube.Artifact{
+				{Name: "report.html", Size: 2048, Status: "ready"},
+				{Name: "video.mp4", Size: 1 << 20, Status: "ready"},
+			},
+		},
+	}
+}
+
+func TestEvaluateOnResult(t *testing.T) {
+	execution := resultExecution(testkube.FAILED_ExecutionStatus, "2 passed\n1 failed")
+
+	tests := map[string]interface{}{
+		`result.status`:               "failed",
+		`result.status == "passed"`:   false,
+		`result.durationSeconds`:      12.5,
+		`result.durationSeconds > 10`: true,
+		`result.output`:               "2 passed\n1 failed",
+		`len(result.steps)`:           int64(2),
+		`result.steps.1.status`:       "error",
+		`result.artifacts.0.name`:     "report.html",
+		`len(filter(result.artifacts, "_.value.name == \"video.mp4\"")) > 0`: true,
+		`len(filter(result.artifacts, "_.value.name == \"trace.zip\"")) > 0`: false,
+		`test.name + ": " + result.status`:                                   "api-tests: failed",
+	}
+	for expr, expected := range tests {
+		v, err := EvaluateOnResult(expr, execution)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+}
+
+func TestEvaluateOnResultJUnit(t *testing.T) {
+	t.Run("report", func(t *testing.T) {
+		execution := resultExecution(testkube.FAILED_ExecutionStatus, "")
+		execution.ExecutionResult.Reports = &testkube.ExecutionResultReports{Junit: resultsJUnitReport}
+
+		tests := map[string]interface{}{
+			`result.report.format`:          ReportFormatJUnit,
+			`result.report.suites`:          2,
+			`result.report.tests`:           4,
+			`result.report.passed`:          3,
+			`result.report.failed`:          1,
+			`result.report.passRate`:        0.75,
+			`result.report.passRate >= 0.9`: false,
+			`result.report.passRate >= 0.7`: true,
+			`result.report.durationSeconds`: float64(4),
+		}
+		for expr, expected := range tests {
+			v, err := EvaluateOnResult(expr, execution)
+			assert.NoError(t, err, expr)
+			assert.Equal(t, expected, v, expr)
+		}
+	})
+
+	t.Run("output", func(t *testing.T) {
+		execution := resultExecution(testkube.PASSED_ExecutionStatus, "\n"+resultsJUnitReport)
+
+		v, err := EvaluateOnResult(`result.report.tests - result.report.failed`, execution)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(3), v)
+	})
+}
+
+func TestEvaluateOnResultJSON(t *testing.T) {
+	execution := resultExecution(testkube.PASSED_ExecutionStatus, `{"stats": {"passes": 9, "failures": 1}}`)
+
+	v, err := EvaluateOnResult(`result.report.format == "json" && result.report.data.stats.failures == 0`, execution)
+	assert.NoError(t, err)
+	assert.Equal(t, false, v)
+
+	v, err = EvaluateOnResult(`result.report.data.stats.passes / (result.report.data.stats.passes + result.report.data.stats.failures)`, execution)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.9, v)
+}
+
+func TestEvaluateOnResultMissing(t *testing.T) {
+	t.Run("plain output has no report", func(t *testing.T) {
+		_, err := EvaluateOnResult(`result.report.tests`, resultExecution(testkube.PASSED_ExecutionStatus, "ok"))
+		assert.ErrorContains(t, err, "result.report.tests")
+	})
+
+	t.Run("no result", func(t *testing.T) {
+		execution := testkube.Execution{
+			StartTime: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2024, 1, 1, 10, 1, 30, 0, time.UTC),
+		}
+
+		v, err := EvaluateOnResult(`result.status == "" && result.durationSeconds == 90 && len(result.artifacts) == 0`, execution)
+		assert.NoError(t, err)
+		assert.Equal(t, true, v)
+	})
+}
+
+func TestEvaluateOnResultTiming(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	executions := map[string]struct {
+		execution testkube.Execution
+		duration  string
+		seconds   float64
+	}{
+		"sub-second": {
+			execution: testkube.Execution{DurationMs: 345, StartTime: start, EndTime: start.Add(345 * time.Millisecond)},
+			duration:  "345ms",
+			seconds:   0.345,
+		},
+		"multi-hour": {
+			execution: testkube.Execution{StartTime: start, EndTime: start.Add(3*time.Hour + 25*time.Minute + 7400*time.Millisecond)},
+			duration:  "3h25m7.4s",
+			seconds:   12307.4,
+		},
+		"sub-millisecond start and end": {
+			execution: testkube.Execution{StartTime: start.Add(300 * time.Microsecond), EndTime: start.Add(92*time.Second + 400900*time.Microsecond)},
+			duration:  "1m32.401s",
+			seconds:   92.401,
+		},
+	}
+
+	for name, tc := range executions {
+		t.Run(name, func(t *testing.T) {
+			vars := resultVars(tc.execution)
+			assert.Equal(t, tc.duration, vars["duration"])
+			assert.Equal(t, tc.seconds, vars["durationSeconds"])
+
+			parsed, err := time.ParseDuration(vars["duration"].(string))
+			assert.NoError(t, err)
+			assert.Equal(t, vars["durationSeconds"], parsed.Seconds())
+
+			assert.Equal(t, tc.execution.StartTime.Unix(), vars["startedAtUnix"])
+			assert.Equal(t, tc.execution.EndTime.Unix(), vars["finishedAtUnix"])
+			v, err := EvaluateOnResult(`result.finishedAtUnix - result.startedAtUnix`, tc.execution)
+			assert.NoError(t, err)
+			assert.Equal(t, float64(tc.execution.EndTime.Unix()-tc.execution.StartTime.Unix()), v)
+		})
+	}
+
+	v, err := EvaluateOnResult(`result.duration + " " + string(result.startedAtUnix) + " " + string(result.finishedAtUnix)`, testkube.Execution{})
+	assert.NoError(t, err)
+	assert.Equal(t, "0s 0 0", v)
+}
@@ -0,0 +1,381 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/output"
+)
+
+const (
+	// RetryOfLabel links retry attempt execution with the original execution
+	RetryOfLabel = "testkube.io/retry-of"
+	// RetryAttemptLabel is a number of execution attempt
+	RetryAttemptLabel = "testkube.io/retry-attempt"
+)
+
+// RetryOn selects failures which should be retried
+type RetryOn string
+
+const (
+	// RetryOnAnyFailure retries all failed executions
+	RetryOnAnyFailure RetryOn = "any"
+	// RetryOnInfrastructureError retries only executions failed because of infrastructure, like pod eviction or image pull
+	RetryOnInfrastructureError RetryOn = "infrastructure"
+)
+
+// BackoffStrategy defines how delay between attempts grows
+type BackoffStrategy string
+
+const (
+	// BackoffFixed waits the same delay between attempts
+	BackoffFixed BackoffStrategy = "fixed"
+	// BackoffExponential doubles delay after each attempt up to max delay
+	BackoffExponential BackoffStrategy = "exponential"
+)
+
+// infrastructureErrorIndicators are messages reported when pod failed because of infrastructure
+var infrastructureErrorIndicators = []string{
+	"ImagePullBackOff",
+	"ErrImagePull",
+	"InvalidImageName",
+	"Evicted",
+	"NodeLost",
+	"CreateContainerError",
+	"CreateContainerConfigError",
+}
+
+// RetryPolicy defines how failed executions are retried
+type RetryPolicy struct {
+	// MaxAttempts is a maximum number of attempts including the first one
+	MaxAttempts int
+	// Backoff is a strategy of delay between attempts
+	Backoff BackoffStrategy
+	// Delay is a delay before the second attempt
+	Delay time.Duration
+	// MaxDelay caps exponential backoff, zero means no cap
+	MaxDelay time.Duration
+	// RetryOn selects which failures are retried
+	RetryOn RetryOn
+}
+
+// Validate checks if retry policy is valid
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return errors.New("retry policy max attempts should be at least 1")
+	}
+
+	if p.Delay < 0 || p.MaxDelay < 0 {
+		return errors.New("retry policy delays can't be negative")
+	}
+
+	switch p.Backoff {
+	case "", BackoffFixed, BackoffExponential:
+	default:
+		return fmt.Errorf("unknown retry policy backoff %s", p.Backoff)
+	}
+
+	switch p.RetryOn {
+	case "", RetryOnAnyFailure, RetryOnInfrastructureError:
+	default:
+		return fmt.Errorf("unknown retry policy retry on %s", p.RetryOn)
+	}
+
+	return nil
+}
+
+// BackoffDelay returns delay after the attempt with passed number, without MaxDelay the exponential
+// backoff stops doubling at the longest duration instead of overflowing
+func (p RetryPolicy) BackoffDelay(attempt int) time.Duration {
+	delay := p.Delay
+	if p.Backoff == BackoffExponential {
+		for i := 1; i < attempt && delay > 0; i++ {
+			if delay > math.MaxInt64/2 {
+				delay = math.MaxInt64
+				break
+			}
+			delay *= 2
+			if p.MaxDelay > 0 && delay >= p.MaxDelay {
+				break
+			}
+		}
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+
+	return delay
+}
+
+// ShouldRetry checks if the attempt outcome should be retried
+func (p RetryPolicy) ShouldRetry(attempt int, result *testkube.ExecutionResult, err error) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if err == nil && (result == nil || result.Status == nil || !(result.IsFailed() || result.IsTimeout())) {
+		return false
+	}
+
+	if p.RetryOn == RetryOnInfrastructureError {
+		return IsInfrastructureFailure(result, err)
+	}
+
+	return true
+}
+
+// IsInfrastructureFailure checks if execution failed because of infrastructure and not the test itself,
+// the execution with failed assertions is never an infrastructure failure, as the test did run
+func IsInfrastructureFailure(result *testkube.ExecutionResult, err error) bool {
+	if hasFailedAssertions(result) {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	if result == nil || result.Status == nil || !result.IsFailed() {
+		return false
+	}
+
+	for _, indicator := range infrastructureErrorIndicators {
+		if strings.Contains(result.ErrorMessage, indicator) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasFailedAssertions(result *testkube.ExecutionResult) bool {
+	if result == nil {
+		return false
+	}
+
+	for _, step := range result.Steps {
+		if step.Status == string(testkube.FAILED_ExecutionStatus) {
+			return true
+		}
+
+		for _, assertion := range step.AssertionResults {
+			if assertion.Status == string(testkube.FAILED_ExecutionStatus) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ExecutionRecorder stores retry attempts of executions
+type ExecutionRecorder interface {
+	// Insert inserts new execution result
+	Insert(ctx context.Context, result testkube.Execution) error
+	// UpdateResult updates result in execution
+	UpdateResult(ctx context.Context, id string, execution testkube.Execution) error
+}
+
+// RetryingExecutor retries failed executions according to the execute options retry policy,
+// the original execution stays running until the last attempt finishes and reports its result,
+// every attempt is stored as separate execution linked with RetryOfLabel, and listed in the final result attempts;
+// abort and logs of the original execution follow its newest attempt
+type RetryingExecutor struct {
+	Executor
+	recorder ExecutionRecorder
+	clock    Clock
+
+	mu sync.Mutex
+	// current maps the original execution id to the id of its newest attempt
+	current map[string]string
+}
+
+// NewRetryingExecutor creates new retrying executor
+func NewRetryingExecutor(executor Executor, recorder ExecutionRecorder, clock Clock) *RetryingExecutor {
+	if clock == nil {
+		clock = NewRealClock()
+	}
+
+	return &RetryingExecutor{
+		Executor: executor,
+		recorder: recorder,
+		clock:    clock,
+		current:  make(map[string]string),
+	}
+}
+
+// Execute starts execution and retries failed attempts
+func (e *RetryingExecutor) Execute(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	if options.RetryPolicy == nil || options.RetryPolicy.MaxAttempts <= 1 {
+		return e.Executor.Execute(ctx, execution, options)
+	}
+
+	if err := options.RetryPolicy.Validate(); err != nil {
+		return testkube.NewRunningExecutionResult().Err(err), err
+	}
+
+	if options.Sync {
+		return e.executeWithRetries(ctx, execution, options)
+	}
+
+	options.Sync = true
+	result := testkube.NewRunningExecutionResult()
+	execution.ExecutionResult = result
+	original := *execution
+	// retries outlive the request which started the execution
+	go func() {
+		_, _ = e.executeWithRetries(context.WithoutCancel(ctx), &original, options)
+	}()
+
+	return result, nil
+}
+
+// Abort aborts the newest attempt of the execution
+func (e *RetryingExecutor) Abort(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
+	if id, ok := e.attempt(execution.Id); ok {
+		attempt := *execution
+		attempt.Id = id
+		return e.Executor.Abort(ctx, &attempt)
+	}
+
+	return e.Executor.Abort(ctx, execution)
+}
+
+// Logs returns logs of the newest attempt of the execution
+func (e *RetryingExecutor) Logs(ctx context.Context, id, namespace string) (chan output.Output, error) {
+	if attemptID, ok := e.attempt(id); ok {
+		id = attemptID
+	}
+
+	return e.Executor.Logs(ctx, id, namespace)
+}
+
+func (e *RetryingExecutor) attempt(id string) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	attemptID, ok := e.current[id]
+	return attemptID, ok
+}
+
+func (e *RetryingExecutor) setAttempt(id, attemptID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if attemptID == "" {
+		delete(e.current, id)
+	} else {
+		e.current[id] = attemptID
+	}
+}
+
+func (e *RetryingExecutor) executeWithRetries(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	defer e.setAttempt(execution.Id, "")
+
+	policy := *options.RetryPolicy
+	attempt := execution
+	var attempts []testkube.ExecutionAttempt
+	for number := 1; ; number++ {
+		result, err := e.Executor.Execute(ctx, attempt, options)
+		if !policy.ShouldRetry(number, result, err) {
+			if attempt != execution {
+				if result != nil {
+					result.Attempts = append(attempts, newExecutionAttempt(attempt.Id, number, result, err))
+				}
+				execution.ExecutionResult = result
+				execution.Labels = withRetryLabels(execution.Labels, "", number)
+				if rErr := e.recorder.UpdateResult(ctx, execution.Id, *execution); rErr != nil {
+					return result, fmt.Errorf("updating execution %s with last attempt result: %w", execution.Id, rErr)
+				}
+			}
+
+			return result, err
+		}
+
+		attemptID := attempt.Id
+		if attempt == execution {
+			attemptID = newRetryAttempt(*execution, number).Id
+		}
+		attempts = append(attempts, newExecutionAttempt(attemptID, number, result, err))
+
+		if attempt == execution {
+			// preserve the first attempt, as the original execution is reused for the final result
+			first := newRetryAttempt(*execution, number)
+			first.ExecutionResult = result
+			if err := e.recorder.Insert(ctx, first); err != nil {
+				return result, fmt.Errorf("recording execution %s attempt %d: %w", execution.Id, number, err)
+			}
+
+			// keep the original execution running, so it occupies a single slot for all attempts
+			execution.ExecutionResult = testkube.NewRunningExecutionResult()
+			if err := e.recorder.UpdateResult(ctx, execution.Id, *execution); err != nil {
+				return result, fmt.Errorf("updating execution %s for retry: %w", execution.Id, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-e.clock.After(policy.BackoffDelay(number)):
+		}
+
+		next := newRetryAttempt(*execution, number+1)
+		next.StartTime = e.clock.Now()
+		if err := e.recorder.Insert(ctx, next); err != nil {
+			return result, fmt.Errorf("recording execution %s attempt %d: %w", execution.Id, number+1, err)
+		}
+
+		e.setAttempt(execution.Id, next.Id)
+		attempt = &next
+	}
+}
+
+// newExecutionAttempt summarizes the attempt outcome for the attempts history of the final result
+func newExecutionAttempt(id string, number int, result *testkube.ExecutionResult, err error) testkube.ExecutionAttempt {
+	attempt := testkube.ExecutionAttempt{
+		Number:      int32(number),
+		ExecutionId: id,
+		Status:      testkube.ExecutionStatusFailed,
+	}
+
+	if result != nil && result.Status != nil {
+		status := *result.Status
+		attempt.Status = &status
+		attempt.ErrorMessage = result.ErrorMessage
+	}
+
+	if err != nil {
+		attempt.ErrorMessage = err.Error()
+	}
+
+	return attempt
+}
+
+func newRetryAttempt(execution testkube.Execution, number int) testkube.Execution {
+	attempt := execution
+	attempt.Id = fmt.Sprintf("%s-%d", execution.Id, number)
+	attempt.Name = fmt.Sprintf("%s-%d", execution.Name, number)
+	attempt.Labels = withRetryLabels(execution.Labels, execution.Id, number)
+	attempt.ExecutionResult = testkube.NewRunningExecutionResult()
+	return attempt
+}
+
+func withRetryLabels(labels map[string]string, retryOf string, number int) map[string]string {
+	result := make(map[string]string, len(labels)+2)
+	for key, value := range labels {
+		result[key] = value
+	}
+
+	if retryOf != "" {
+		result[RetryOfLabel] = retryOf
+	}
+
+	result[RetryAttemptLabel] = strconv.Itoa(number)
+	return result
+}
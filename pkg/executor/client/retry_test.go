@@ -0,0 +1,276 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+type fakeRecorder struct {
+	executions map[string]testkube.Execution
+}
+
+func newFakeRecorder() *fakeRecorder {
+	return &fakeRecorder{executions: map[string]testkube.Execution{}}
+}
+
+func (r *fakeRecorder) Insert(ctx context.Context, execution testkube.Execution) error {
+	r.executions[execution.Id] = execution
+	return nil
+}
+
+func (r *fakeRecorder) UpdateResult(ctx context.Context, id string, execution testkube.Execution) error {
+	r.executions[id] = execution
+	return nil
+}
+
+func podFailure() *testkube.ExecutionResult {
+	result := testkube.NewRunningExecutionResult()
+	result.Error()
+	result.ErrorMessage = "pod failed: Evicted: The node was low on resource: memory"
+	return result
+}
+
+func testFailure() *testkube.ExecutionResult {
+	result := testkube.NewRunningExecutionResult()
+	result.Error()
+	result.ErrorMessage = "expected 200, got 500"
+	return result
+}
+
+func passed() *testkube.ExecutionResult {
+	result := testkube.NewRunningExecutionResult()
+	result.Success()
+	return result
+}
+
+func TestRetryPolicyBackoffDelay(t *testing.T) {
+	fixed := RetryPolicy{Backoff: BackoffFixed, Delay: time.Second}
+	assert.Equal(t, time.Second, fixed.BackoffDelay(1))
+	assert.Equal(t, time.Second, fixed.BackoffDelay(5))
+
+	exponential := RetryPolicy{Backoff: BackoffExponential, Delay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, exponential.BackoffDelay(1))
+	assert.Equal(t, 2*time.Second, exponential.BackoffDelay(2))
+	assert.Equal(t, 4*time.Second, exponential.BackoffDelay(3))
+	assert.Equal(t, 5*time.Second, exponential.BackoffDelay(4))
+	assert.Equal(t, 5*time.Second, exponential.BackoffDelay(100))
+
+	uncapped := RetryPolicy{Backoff: BackoffExponential, Delay: time.Second}
+	assert.Equal(t, 8*time.Second, uncapped.BackoffDelay(4))
+	assert.Equal(t, time.Duration(math.MaxInt64), uncapped.BackoffDelay(100))
+	assert.Equal(t, time.Duration(math.MaxInt64), uncapped.BackoffDelay(math.MaxInt32))
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	anyFailure := RetryPolicy{MaxAttempts: 3, RetryOn: RetryOnAnyFailure}
+	infrastructure := RetryPolicy{MaxAttempts: 3, RetryOn: RetryOnInfrastructureError}
+
+	assert.True(t, anyFailure.ShouldRetry(1, testFailure(), nil))
+	assert.True(t, anyFailure.ShouldRetry(2, podFailure(), nil))
+	assert.False(t, anyFailure.ShouldRetry(3, podFailure(), nil))
+	assert.False(t, anyFailure.ShouldRetry(1, passed(), nil))
+
+	assert.True(t, infrastructure.ShouldRetry(1, podFailure(), nil))
+	assert.True(t, infrastructure.ShouldRetry(1, testkube.NewRunningExecutionResult(), errors.New("creating job")))
+	assert.False(t, infrastructure.ShouldRetry(1, testFailure(), nil))
+
+	// assertion failure is never retried as infrastructure failure, even when the message looks like one
+	assertionFailure := podFailure()
+	assertionFailure.Steps = []testkube.ExecutionStepResult{{Name: "evict", Status: "passed", AssertionResults: []testkube.AssertionResult{
+		{Name: "pod is Evicted", Status: "failed"},
+	}}}
+	assert.False(t, infrastructure.ShouldRetry(1, assertionFailure, nil))
+	assert.False(t, infrastructure.ShouldRetry(1, assertionFailure, errors.New("reading results")))
+	assert.True(t, anyFailure.ShouldRetry(1, assertionFailure, nil))
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	assert.NoError(t, RetryPolicy{MaxAttempts: 2, Backoff: BackoffExponential}.Validate())
+	assert.Error(t, RetryPolicy{}.Validate())
+	assert.Error(t, RetryPolicy{MaxAttempts: 2, Delay: -time.Second}.Validate())
+	assert.Error(t, RetryPolicy{MaxAttempts: 2, Backoff: "linear"}.Validate())
+	assert.Error(t, RetryPolicy{MaxAttempts: 2, RetryOn: "always"}.Validate())
+}
+
+func TestRetryingExecutor_RetriesPodFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	recorder := newFakeRecorder()
+	mockExecutor := NewMockExecutor(ctrl)
+	gomock.InOrder(
+		mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+				assert.Equal(t, "exec", execution.Id)
+				assert.True(t, options.Sync)
+				return podFailure(), nil
+			}),
+		mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+				assert.Equal(t, "exec-2", execution.Id)
+				assert.Equal(t, "exec", execution.Labels[RetryOfLabel])
+				assert.Equal(t, "2", execution.Labels[RetryAttemptLabel])
+				assert.Contains(t, recorder.executions, "exec-2")
+				assert.True(t, recorder.executions["exec"].ExecutionResult.IsRunning())
+				return passed(), nil
+			}),
+	)
+
+	execution := &testkube.Execution{Id: "exec", Name: "test-1"}
+	options := ExecuteOptions{
+		Sync:        true,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3, RetryOn: RetryOnInfrastructureError},
+	}
+	result, err := NewRetryingExecutor(mockExecutor, recorder, newFakeClock(time.Now())).Execute(context.Background(), execution, options)
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsPassed())
+	assert.True(t, recorder.executions["exec"].ExecutionResult.IsPassed())
+	assert.Equal(t, "2", recorder.executions["exec"].Labels[RetryAttemptLabel])
+	assert.True(t, recorder.executions["exec-1"].ExecutionResult.IsFailed())
+	assert.Equal(t, "exec", recorder.executions["exec-1"].Labels[RetryOfLabel])
+}
+
+func TestRetryingExecutor_AsyncRetriesOutliveRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	retried := make(chan struct{})
+	mockExecutor := NewMockExecutor(ctrl)
+	gomock.InOrder(
+		mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+				cancel()
+				return podFailure(), nil
+			}),
+		mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+				assert.NoError(t, ctx.Err())
+				close(retried)
+				return passed(), nil
+			}),
+	)
+
+	execution := &testkube.Execution{Id: "exec", Name: "test-1"}
+	options := ExecuteOptions{RetryPolicy: &RetryPolicy{MaxAttempts: 2, RetryOn: RetryOnInfrastructureError}}
+	result, err := NewRetryingExecutor(mockExecutor, newFakeRecorder(), newFakeClock(time.Now())).Execute(ctx, execution, options)
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsRunning())
+	select {
+	case <-retried:
+	case <-time.After(time.Second):
+		t.Fatal("retry wasn't started after the request was cancelled")
+	}
+}
+
+func TestRetryingExecutor_DoesNotRetryTestFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	recorder := newFakeRecorder()
+	mockExecutor := NewMockExecutor(ctrl)
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(testFailure(), nil)
+
+	execution := &testkube.Execution{Id: "exec"}
+	options := ExecuteOptions{
+		Sync:        true,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3, RetryOn: RetryOnInfrastructureError},
+	}
+	result, err := NewRetryingExecutor(mockExecutor, recorder, newFakeClock(time.Now())).Execute(context.Background(), execution, options)
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsFailed())
+	assert.Empty(t, recorder.executions)
+}
+
+func TestRetryingExecutor_ReportsLastAttempt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	recorder := newFakeRecorder()
+	mockExecutor := NewMockExecutor(ctrl)
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(podFailure(), nil).Times(2)
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(testFailure(), nil)
+
+	execution := &testkube.Execution{Id: "exec"}
+	options := ExecuteOptions{
+		Sync:        true,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3},
+	}
+	result, err := NewRetryingExecutor(mockExecutor, recorder, newFakeClock(time.Now())).Execute(context.Background(), execution, options)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "expected 200, got 500", result.ErrorMessage)
+	assert.Equal(t, "expected 200, got 500", recorder.executions["exec"].ExecutionResult.ErrorMessage)
+	assert.Equal(t, "3", recorder.executions["exec"].Labels[RetryAttemptLabel])
+	assert.Len(t, recorder.executions, 4)
+}
+
+func TestRetryingExecutor_AttemptsHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	recorder := newFakeRecorder()
+	mockExecutor := NewMockExecutor(ctrl)
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(podFailure(), nil)
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("creating job"))
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(passed(), nil)
+
+	execution := &testkube.Execution{Id: "exec"}
+	options := ExecuteOptions{
+		Sync:        true,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3, RetryOn: RetryOnInfrastructureError},
+	}
+	result, err := NewRetryingExecutor(mockExecutor, recorder, newFakeClock(time.Now())).Execute(context.Background(), execution, options)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []testkube.ExecutionAttempt{
+		{Number: 1, ExecutionId: "exec-1", Status: testkube.ExecutionStatusFailed, ErrorMessage: "pod failed: Evicted: The node was low on resource: memory"},
+		{Number: 2, ExecutionId: "exec-2", Status: testkube.ExecutionStatusFailed, ErrorMessage: "creating job"},
+		{Number: 3, ExecutionId: "exec-3", Status: testkube.ExecutionStatusPassed},
+	}, result.Attempts)
+	assert.Equal(t, result.Attempts, recorder.executions["exec"].ExecutionResult.Attempts)
+}
+
+func TestRetryingExecutor_FollowsNewestAttempt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	recorder := newFakeRecorder()
+	mockExecutor := NewMockExecutor(ctrl)
+	retrying := NewRetryingExecutor(mockExecutor, recorder, newFakeClock(time.Now()))
+	gomock.InOrder(
+		mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(podFailure(), nil),
+		mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+				// the second attempt is running, abort and logs of the original execution reach it
+				_, _ = retrying.Abort(ctx, &testkube.Execution{Id: "exec"})
+				_, _ = retrying.Logs(ctx, "exec", "tests")
+				return passed(), nil
+			}),
+		mockExecutor.EXPECT().Abort(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
+				assert.Equal(t, "exec-2", execution.Id)
+				return testkube.NewRunningExecutionResult(), nil
+			}),
+		mockExecutor.EXPECT().Logs(gomock.Any(), "exec-2", "tests").Return(nil, nil),
+		mockExecutor.EXPECT().Logs(gomock.Any(), "exec", "tests").Return(nil, nil),
+	)
+
+	options := ExecuteOptions{Sync: true, RetryPolicy: &RetryPolicy{MaxAttempts: 2}}
+	_, err := retrying.Execute(context.Background(), &testkube.Execution{Id: "exec"}, options)
+	assert.NoError(t, err)
+
+	// finished execution isn't redirected anymore
+	_, _ = retrying.Logs(context.Background(), "exec", "tests")
+}
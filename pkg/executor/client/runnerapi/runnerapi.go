@@ -0,0 +1,283 @@
+// Package runnerapi defines gRPC service exposed by long-lived runner daemons.
+// Messages are encoded as JSON, so runners can be implemented without generated protobuf code.
+package runnerapi
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// CodecName is a name of the content subtype used by runner service
+	CodecName = "json"
+
+	ServiceName                   = "testkube.runner.Runner"
+	Runner_Execute_FullMethodName = "/" + ServiceName + "/Execute"
+	Runner_Watch_FullMethodName   = "/" + ServiceName + "/Watch"
+	Runner_Abort_FullMethodName   = "/" + ServiceName + "/Abort"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return CodecName
+}
+
+// ExecuteRequest starts execution on the runner
+type ExecuteRequest struct {
+	ExecutionID    string            `json:"executionId"`
+	ExecutionName  string            `json:"executionName,omitempty"`
+	Number         int32             `json:"number,omitempty"`
+	TestName       string            `json:"testName"`
+	TestNamespace  string            `json:"testNamespace,omitempty"`
+	TestType       string            `json:"testType,omitempty"`
+	Image          string            `json:"image,omitempty"`
+	Command        []string          `json:"command,omitempty"`
+	Args           []string          `json:"args,omitempty"`
+	WorkingDir     string            `json:"workingDir,omitempty"`
+	Envs           map[string]string `json:"envs,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	TimeoutSeconds int64             `json:"timeoutSeconds,omitempty"`
+	Content        *Content          `json:"content,omitempty"`
+	IdempotencyKey string            `json:"idempotencyKey,omitempty"`
+}
+
+// Content is a test content passed to the runner
+type Content struct {
+	Type       string `json:"type,omitempty"`
+	Data       string `json:"data,omitempty"`
+	URI        string `json:"uri,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Branch     string `json:"branch,omitempty"`
+	Commit     string `json:"commit,omitempty"`
+	Path       string `json:"path,omitempty"`
+}
+
+// ExecuteResponse confirms execution was accepted by the runner
+type ExecuteResponse struct {
+	Accepted bool   `json:"accepted"`
+	Message  string `json:"message,omitempty"`
+}
+
+// WatchRequest subscribes to execution status updates
+type WatchRequest struct {
+	ExecutionID string `json:"executionId"`
+}
+
+// StatusUpdate is a single execution status update, output contains only lines produced since the previous update
+type StatusUpdate struct {
+	ExecutionID  string `json:"executionId"`
+	Status       string `json:"status"`
+	Output       string `json:"output,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	Done         bool   `json:"done,omitempty"`
+	// Stream is an output stream of the output, stdout or stderr
+	Stream string `json:"stream,omitempty"`
+	// Timestamp is when the output was written
+	Timestamp time.Time `json:"timestamp,omitempty"`
+}
+
+// AbortRequest aborts running execution
+type AbortRequest struct {
+	ExecutionID string `json:"executionId"`
+}
+
+// AbortResponse confirms execution was aborted
+type AbortResponse struct {
+	Status string `json:"status,omitempty"`
+}
+
+// RunnerClient is the client API for runner service
+type RunnerClient interface {
+	Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Runner_WatchClient, error)
+	Abort(ctx context.Context, in *AbortRequest, opts ...grpc.CallOption) (*AbortResponse, error)
+}
+
+type runnerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewRunnerClient creates runner client, calls are encoded with the runner JSON codec
+func NewRunnerClient(cc grpc.ClientConnInterface) RunnerClient {
+	return &runnerClient{cc}
+}
+
+func (c *runnerClient) Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error) {
+	out := new(ExecuteResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(CodecName)}, opts...)
+	if err := c.cc.Invoke(ctx, Runner_Execute_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runnerClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Runner_WatchClient, error) {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(CodecName)}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Runner_ServiceDesc.Streams[0], Runner_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &runnerWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (c *runnerClient) Abort(ctx context.Context, in *AbortRequest, opts ...grpc.CallOption) (*AbortResponse, error) {
+	out := new(AbortResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(CodecName)}, opts...)
+	if err := c.cc.Invoke(ctx, Runner_Abort_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Runner_WatchClient receives status updates
+type Runner_WatchClient interface {
+	Recv() (*StatusUpdate, error)
+	grpc.ClientStream
+}
+
+type runnerWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *runnerWatchClient) Recv() (*StatusUpdate, error) {
+	m := new(StatusUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RunnerServer is the server API for runner service
+type RunnerServer interface {
+	Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error)
+	Watch(*WatchRequest, Runner_WatchServer) error
+	Abort(context.Context, *AbortRequest) (*AbortResponse, error)
+}
+
+// UnimplementedRunnerServer can be embedded to have forward compatible implementations
+type UnimplementedRunnerServer struct{}
+
+func (UnimplementedRunnerServer) Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Execute not implemented")
+}
+
+func (UnimplementedRunnerServer) Watch(*WatchRequest, Runner_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+
+func (UnimplementedRunnerServer) Abort(context.Context, *AbortRequest) (*AbortResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Abort not implemented")
+}
+
+// RegisterRunnerServer registers runner service implementation
+func RegisterRunnerServer(s grpc.ServiceRegistrar, srv RunnerServer) {
+	s.RegisterService(&Runner_ServiceDesc, srv)
+}
+
+func _Runner_Execute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunnerServer).Execute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Runner_Execute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunnerServer).Execute(ctx, req.(*ExecuteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runner_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RunnerServer).Watch(m, &runnerWatchServer{stream})
+}
+
+// Runner_WatchServer sends status updates
+type Runner_WatchServer interface {
+	Send(*StatusUpdate) error
+	grpc.ServerStream
+}
+
+type runnerWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *runnerWatchServer) Send(m *StatusUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Runner_Abort_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AbortRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunnerServer).Abort(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Runner_Abort_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunnerServer).Abort(ctx, req.(*AbortRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Runner_ServiceDesc is the grpc.ServiceDesc for runner service
+var Runner_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*RunnerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Execute",
+			Handler:    _Runner_Execute_Handler,
+		},
+		{
+			MethodName: "Abort",
+			Handler:    _Runner_Abort_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Runner_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "runnerapi",
+}
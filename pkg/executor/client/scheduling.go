@@ -0,0 +1,65 @@
+package client
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ValidateTolerations checks toleration operators and effects, all found problems are returned
+func ValidateTolerations(tolerations []corev1.Toleration) (errs []error) {
+	for i, toleration := range tolerations {
+		switch toleration.Operator {
+		case "", corev1.TolerationOpEqual:
+			if toleration.Key == "" {
+				errs = append(errs, fmt.Errorf("toleration %d with empty key requires operator %s", i, corev1.TolerationOpExists))
+			}
+		case corev1.TolerationOpExists:
+			if toleration.Value != "" {
+				errs = append(errs, fmt.Errorf("toleration %d with operator %s can't have value", i, corev1.TolerationOpExists))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("toleration %d has unknown operator %s", i, toleration.Operator))
+		}
+
+		switch toleration.Effect {
+		case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			errs = append(errs, fmt.Errorf("toleration %d has unknown effect %s", i, toleration.Effect))
+		}
+	}
+
+	return errs
+}
+
+// ApplyScheduling merges execution scheduling into the pod spec rendered from the executor job template,
+// so the template defines executor defaults and execution values take precedence:
+// node selector values replace template values with the same key, tolerations replace template
+// tolerations with the same key and effect, and affinity replaces template affinity as a whole
+func ApplyScheduling(spec *corev1.PodSpec, nodeSelector map[string]string, tolerations []corev1.Toleration, affinity *corev1.Affinity) {
+	for key, value := range nodeSelector {
+		if spec.NodeSelector == nil {
+			spec.NodeSelector = map[string]string{}
+		}
+		spec.NodeSelector[key] = value
+	}
+
+	for _, toleration := range tolerations {
+		replaced := false
+		for i := range spec.Tolerations {
+			if spec.Tolerations[i].Key == toleration.Key && spec.Tolerations[i].Effect == toleration.Effect {
+				spec.Tolerations[i] = toleration
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			spec.Tolerations = append(spec.Tolerations, toleration)
+		}
+	}
+
+	if affinity != nil {
+		spec.Affinity = affinity.DeepCopy()
+	}
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestValidateTolerations(t *testing.T) {
+	assert.Empty(t, ValidateTolerations([]corev1.Toleration{
+		{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: "os", Value: "windows"},
+		{Operator: corev1.TolerationOpExists},
+	}))
+
+	errs := ValidateTolerations([]corev1.Toleration{
+		{Key: "nvidia.com/gpu", Operator: "In"},
+		{Key: "os", Operator: corev1.TolerationOpExists, Value: "windows"},
+		{Value: "windows"},
+		{Key: "os", Effect: "NoRun"},
+	})
+
+	assert.Len(t, errs, 4)
+	assert.ErrorContains(t, errs[0], "toleration 0 has unknown operator In")
+	assert.ErrorContains(t, errs[1], "toleration 1 with operator Exists can't have value")
+	assert.ErrorContains(t, errs[2], "toleration 2 with empty key requires operator Exists")
+	assert.ErrorContains(t, errs[3], "toleration 3 has unknown effect NoRun")
+}
+
+func TestApplyScheduling(t *testing.T) {
+	templateAffinity := &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+	spec := corev1.PodSpec{
+		NodeSelector: map[string]string{"kubernetes.io/os": "linux", "pool": "default"},
+		Tolerations: []corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "tests", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+		},
+		Affinity: templateAffinity,
+	}
+	affinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "gpu", Operator: corev1.NodeSelectorOpIn, Values: []string{"a100"}}},
+		}}},
+	}}
+
+	ApplyScheduling(&spec,
+		map[string]string{"kubernetes.io/os": "windows", "gpu": "true"},
+		[]corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu-tests", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		},
+		affinity)
+
+	// execution values win over executor template defaults for the same key
+	assert.Equal(t, map[string]string{"kubernetes.io/os": "windows", "pool": "default", "gpu": "true"}, spec.NodeSelector)
+	assert.Equal(t, []corev1.Toleration{
+		{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu-tests", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+		{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	}, spec.Tolerations)
+	assert.Equal(t, affinity, spec.Affinity)
+	assert.NotSame(t, affinity, spec.Affinity)
+}
+
+func TestApplySchedulingKeepsTemplateDefaults(t *testing.T) {
+	affinity := &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+	spec := corev1.PodSpec{NodeSelector: map[string]string{"pool": "default"}, Affinity: affinity}
+
+	ApplyScheduling(&spec, nil, nil, nil)
+
+	assert.Equal(t, map[string]string{"pool": "default"}, spec.NodeSelector)
+	assert.Empty(t, spec.Tolerations)
+	assert.Same(t, affinity, spec.Affinity)
+}
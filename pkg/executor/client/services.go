@@ -0,0 +1,156 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kubeshop/testkube/pkg/executor"
+)
+
+const (
+	// DefaultServiceStartupTimeout is how long the test container waits for a service to become ready
+	DefaultServiceStartupTimeout = 2 * time.Minute
+	// serviceProbePeriod is how often service readiness is checked
+	serviceProbePeriod = 2 * time.Second
+)
+
+// ErrServiceFailed is reported when a service container fails after it was started
+var ErrServiceFailed = errors.New("service failed")
+
+// Service is an auxiliary container running alongside the test container, e.g. database or mock server,
+// it's started before the test container and stopped when the test container finishes
+type Service struct {
+	// Name identifies the service, it's part of the service container name, see ServiceContainerName
+	Name string `json:"name"`
+	// Image is the service container image
+	Image string `json:"image"`
+	// Env are service container environment variables
+	Env map[string]string `json:"env,omitempty"`
+	// Ports are container ports the service listens on
+	Ports []int32 `json:"ports,omitempty"`
+	// ReadinessCommand is run in the service container until it succeeds, when it's empty,
+	// the service is ready once the first port accepts connections, or once the container is running without ports
+	ReadinessCommand []string `json:"readinessCommand,omitempty"`
+	// StartupTimeout is how long the service can take to become ready, defaults to DefaultServiceStartupTimeout
+	StartupTimeout time.Duration `json:"startupTimeout,omitempty"`
+}
+
+// ServiceContainerName returns the name of service container in execution pod
+func ServiceContainerName(name string) string {
+	return executor.ServiceContainerPrefix + name
+}
+
+// Container renders the service container, it's run as a sidecar init container, so Kubernetes starts
+// the test container only after the startup probe succeeded, and stops the service once the test container finished
+func (s Service) Container() corev1.Container {
+	always := corev1.ContainerRestartPolicyAlways
+	container := corev1.Container{
+		Name:          ServiceContainerName(s.Name),
+		Image:         s.Image,
+		RestartPolicy: &always,
+	}
+
+	names := make([]string, 0, len(s.Env))
+	for name := range s.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: s.Env[name]})
+	}
+
+	for _, port := range s.Ports {
+		container.Ports = append(container.Ports, corev1.ContainerPort{ContainerPort: port, Protocol: corev1.ProtocolTCP})
+	}
+
+	var handler corev1.ProbeHandler
+	switch {
+	case len(s.ReadinessCommand) != 0:
+		handler.Exec = &corev1.ExecAction{Command: append([]string(nil), s.ReadinessCommand...)}
+	case len(s.Ports) != 0:
+		handler.TCPSocket = &corev1.TCPSocketAction{Port: intstr.FromInt32(s.Ports[0])}
+	default:
+		return container
+	}
+
+	timeout := s.StartupTimeout
+	if timeout == 0 {
+		timeout = DefaultServiceStartupTimeout
+	}
+	period := int32(serviceProbePeriod.Seconds())
+	container.StartupProbe = &corev1.Probe{
+		ProbeHandler:     handler,
+		PeriodSeconds:    period,
+		FailureThreshold: max(int32(timeout/serviceProbePeriod), 1),
+	}
+
+	return container
+}
+
+// ApplyServices adds service containers to the pod spec rendered from the executor job template,
+// they are added after template init containers, so services are started right before the test container
+func ApplyServices(spec *corev1.PodSpec, services []Service) {
+	for _, service := range services {
+		spec.InitContainers = append(spec.InitContainers, service.Container())
+	}
+}
+
+// ServiceFailure checks if any service container of the execution pod failed after it was started,
+// the returned error wraps ErrServiceFailed and names the service
+func ServiceFailure(pod corev1.Pod) error {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if !executor.IsServiceContainer(status.Name) {
+			continue
+		}
+
+		name := status.Name[len(executor.ServiceContainerPrefix):]
+		if terminated := status.LastTerminationState.Terminated; terminated != nil && status.RestartCount > 0 {
+			return fmt.Errorf("service %s exited with code %d (%s) and was restarted %d times: %w",
+				name, terminated.ExitCode, terminated.Reason, status.RestartCount, ErrServiceFailed)
+		}
+
+		if status.RestartCount > 0 {
+			return fmt.Errorf("service %s was restarted %d times: %w", name, status.RestartCount, ErrServiceFailed)
+		}
+	}
+
+	return nil
+}
+
+func (o ExecuteOptions) validateServices() (errs []error) {
+	names := make(map[string]struct{}, len(o.Services))
+	for i, service := range o.Services {
+		if service.Name == "" {
+			errs = append(errs, fmt.Errorf("service %d name is required", i))
+		} else if problems := validation.IsDNS1123Label(ServiceContainerName(service.Name)); len(problems) != 0 {
+			errs = append(errs, fmt.Errorf("service %s name is invalid: %s", service.Name, problems[0]))
+		}
+
+		if _, ok := names[service.Name]; ok && service.Name != "" {
+			errs = append(errs, fmt.Errorf("service %s is defined more than once", service.Name))
+		}
+		names[service.Name] = struct{}{}
+
+		if service.Image == "" {
+			errs = append(errs, fmt.Errorf("service %d image is required", i))
+		}
+
+		for _, port := range service.Ports {
+			if port < 1 || port > 65535 {
+				errs = append(errs, fmt.Errorf("service %d port %d is out of range", i, port))
+			}
+		}
+
+		if service.StartupTimeout < 0 {
+			errs = append(errs, fmt.Errorf("service %d startup timeout can't be negative", i))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestApplyServices(t *testing.T) {
+	spec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "exec-init"}},
+		Containers:     []corev1.Container{{Name: "exec"}},
+	}
+
+	ApplyServices(&spec, []Service{
+		{Name: "db", Image: "postgres:16", Env: map[string]string{"POSTGRES_USER": "test", "POSTGRES_DB": "app"}, Ports: []int32{5432}, ReadinessCommand: []string{"pg_isready", "-U", "test"}},
+		{Name: "mock", Image: "wiremock/wiremock", Ports: []int32{8080, 8443}, StartupTimeout: 30 * time.Second},
+		{Name: "cache", Image: "redis"},
+	})
+
+	// sidecar init containers are started in order after template init containers, and the test container is started
+	// only after all of them passed the startup probe, they are stopped once the test container finished
+	always := corev1.ContainerRestartPolicyAlways
+	assert.Equal(t, []corev1.Container{
+		{Name: "exec-init"},
+		{
+			Name:          "service-db",
+			Image:         "postgres:16",
+			RestartPolicy: &always,
+			Env:           []corev1.EnvVar{{Name: "POSTGRES_DB", Value: "app"}, {Name: "POSTGRES_USER", Value: "test"}},
+			Ports:         []corev1.ContainerPort{{ContainerPort: 5432, Protocol: corev1.ProtocolTCP}},
+			StartupProbe: &corev1.Probe{
+				ProbeHandler:     corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"pg_isready", "-U", "test"}}},
+				PeriodSeconds:    2,
+				FailureThreshold: 60,
+			},
+		},
+		{
+			Name:          "service-mock",
+			Image:         "wiremock/wiremock",
+			RestartPolicy: &always,
+			Ports: []corev1.ContainerPort{
+				{ContainerPort: 8080, Protocol: corev1.ProtocolTCP},
+				{ContainerPort: 8443, Protocol: corev1.ProtocolTCP},
+			},
+			StartupProbe: &corev1.Probe{
+				ProbeHandler:     corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(8080)}},
+				PeriodSeconds:    2,
+				FailureThreshold: 15,
+			},
+		},
+		{Name: "service-cache", Image: "redis", RestartPolicy: &always},
+	}, spec.InitContainers)
+	assert.Equal(t, []corev1.Container{{Name: "exec"}}, spec.Containers)
+}
+
+func TestServiceFailure(t *testing.T) {
+	pod := corev1.Pod{Status: corev1.PodStatus{
+		InitContainerStatuses: []corev1.ContainerStatus{
+			{Name: "exec-init", RestartCount: 1},
+			{Name: "service-db", Ready: true},
+		},
+	}}
+	assert.NoError(t, ServiceFailure(pod))
+
+	pod.Status.InitContainerStatuses[1].RestartCount = 2
+	pod.Status.InitContainerStatuses[1].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}
+	err := ServiceFailure(pod)
+	assert.True(t, errors.Is(err, ErrServiceFailed))
+	assert.EqualError(t, err, "service db exited with code 137 (OOMKilled) and was restarted 2 times: service failed")
+
+	pod.Status.InitContainerStatuses[1].LastTerminationState.Terminated = nil
+	assert.EqualError(t, ServiceFailure(pod), "service db was restarted 2 times: service failed")
+}
+
+func TestExecuteOptions_ValidateServices(t *testing.T) {
+	options := ExecuteOptions{ID: "exec", TestName: "test", Services: []Service{
+		{Name: "db", Image: "postgres", Ports: []int32{5432}},
+		{Name: "db", Image: "mysql"},
+		{Name: "Mock_Server", Image: "wiremock/wiremock"},
+		{Name: "mock", Ports: []int32{0}, StartupTimeout: -time.Second},
+		{Image: "redis"},
+	}}
+
+	err := options.Validate()
+	assert.ErrorContains(t, err, "service db is defined more than once")
+	assert.ErrorContains(t, err, "service Mock_Server name is invalid")
+	assert.ErrorContains(t, err, "service 3 image is required")
+	assert.ErrorContains(t, err, "service 3 port 0 is out of range")
+	assert.ErrorContains(t, err, "service 3 startup timeout can't be negative")
+	assert.ErrorContains(t, err, "service 4 name is required")
+
+	options.Services = options.Services[:1]
+	assert.NoError(t, options.Validate())
+	assert.EqualError(t, options.ValidateCapabilities(Capabilities{}), "services: option is not supported by the executor")
+}
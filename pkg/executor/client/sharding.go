@@ -0,0 +1,394 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor"
+	"github.com/kubeshop/testkube/pkg/logs/events"
+	"github.com/kubeshop/testkube/pkg/utils"
+)
+
+const (
+	// ShardOfLabel links shard job with the execution split into shards
+	ShardOfLabel = "testkube.io/shard-of"
+	// ShardIndexLabel is an index of the shard, starting from 0
+	ShardIndexLabel = "testkube.io/shard-index"
+	// ShardIndexEnvVarName is shard index environment var name, the index starts from 0
+	ShardIndexEnvVarName = "SHARD_INDEX"
+	// ShardTotalEnvVarName is a number of shards environment var name
+	ShardTotalEnvVarName = "SHARD_TOTAL"
+	// MaxShards is a maximum number of parallel jobs the execution can be split into
+	MaxShards = 100
+)
+
+// ShardMerge selects how results of the shards are merged into the execution result
+type ShardMerge string
+
+const (
+	// ShardMergeSteps lists steps of all shards in the execution result, step names are prefixed with the shard index
+	ShardMergeSteps ShardMerge = "steps"
+	// ShardMergeNone keeps only the shard results, execution result has no steps
+	ShardMergeNone ShardMerge = "none"
+)
+
+// Sharding splits the execution into parallel jobs, each job runs the same test with its own environment,
+// so the runner can select its part of the suite, i.e. playwright --shard=$((SHARD_INDEX+1))/$SHARD_TOTAL
+type Sharding struct {
+	// Count is a number of parallel jobs, execution with a single shard isn't split
+	Count int `json:"count"`
+	// Envs are additional environment variables of the shard jobs, the values are templates rendered with ShardTemplateData,
+	// i.e. "{{ .Number }}/{{ .Total }}"; SHARD_INDEX and SHARD_TOTAL are always set
+	Envs map[string]string `json:"envs,omitempty"`
+	// Merge selects how shard results are merged, defaults to ShardMergeSteps
+	Merge ShardMerge `json:"merge,omitempty"`
+}
+
+// ShardTemplateData is passed to the templates of the shard environment variables
+type ShardTemplateData struct {
+	// Index is the shard index, starting from 0
+	Index int
+	// Number is the shard number, starting from 1
+	Number int
+	// Total is the number of shards
+	Total int
+}
+
+// Validate checks shard count, merge strategy and environment variable templates
+func (s Sharding) Validate() error {
+	var errs []error
+	if s.Count < 1 || s.Count > MaxShards {
+		errs = append(errs, fmt.Errorf("sharding count should be between 1 and %d, %d provided", MaxShards, s.Count))
+	}
+
+	switch s.Merge {
+	case "", ShardMergeSteps, ShardMergeNone:
+	default:
+		errs = append(errs, fmt.Errorf("unknown sharding merge strategy %s", s.Merge))
+	}
+
+	for _, name := range sortedKeys(s.Envs) {
+		if problems := validation.IsEnvVarName(name); len(problems) != 0 {
+			errs = append(errs, fmt.Errorf("sharding env %q name is invalid: %s", name, problems[0]))
+		}
+		if _, err := utils.NewTemplate(name).Parse(s.Envs[name]); err != nil {
+			errs = append(errs, fmt.Errorf("sharding env %q template is invalid: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsSharded checks if the execution is split into more than one job
+func (s *Sharding) IsSharded() bool {
+	return s != nil && s.Count > 1
+}
+
+// ShardEnvs renders environment variables of the shard with the index, starting from 0
+func (s Sharding) ShardEnvs(index int) (map[string]string, error) {
+	data := ShardTemplateData{Index: index, Number: index + 1, Total: s.Count}
+	envs := make(map[string]string, len(s.Envs)+2)
+	for name, value := range s.Envs {
+		tmpl, err := utils.NewTemplate(name).Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("sharding env %q template is invalid: %w", name, err)
+		}
+
+		var buffer bytes.Buffer
+		if err = tmpl.Execute(&buffer, data); err != nil {
+			return nil, fmt.Errorf("rendering sharding env %q: %w", name, err)
+		}
+		envs[name] = buffer.String()
+	}
+
+	envs[ShardIndexEnvVarName] = strconv.Itoa(index)
+	envs[ShardTotalEnvVarName] = strconv.Itoa(s.Count)
+	return envs, nil
+}
+
+// ShardExecutionID returns id of the shard job of the execution
+func ShardExecutionID(id string, index int) string {
+	return fmt.Sprintf("%s-shard-%d", id, index)
+}
+
+// shard is a single job of the execution split into shards
+type shard struct {
+	index     int
+	execution testkube.Execution
+	options   ExecuteOptions
+}
+
+// newShards prepares executions and execute options of the shard jobs, shard jobs are labeled
+// with the execution id and shard index, and they get shard environment variables
+func newShards(execution testkube.Execution, options ExecuteOptions) ([]shard, error) {
+	shards := make([]shard, options.Sharding.Count)
+	for i := range shards {
+		envs, err := options.Sharding.ShardEnvs(i)
+		if err != nil {
+			return nil, err
+		}
+
+		shardOptions := options.DeepCopy()
+		shardOptions.Envs = maps.Clone(options.Envs)
+		if shardOptions.Envs == nil {
+			shardOptions.Envs = make(map[string]string, len(envs))
+		}
+		maps.Copy(shardOptions.Envs, envs)
+		if shardOptions.Labels == nil {
+			shardOptions.Labels = make(map[string]string, 2)
+		}
+		shardOptions.Labels[ShardOfLabel] = execution.Id
+		shardOptions.Labels[ShardIndexLabel] = strconv.Itoa(i)
+
+		shardExecution := execution
+		shardExecution.Id = ShardExecutionID(execution.Id, i)
+		shardExecution.ExecutionResult = testkube.NewRunningExecutionResult()
+		shards[i] = shard{index: i, execution: shardExecution, options: shardOptions}
+	}
+
+	return shards, nil
+}
+
+// shardOutcome is a result of the shard job
+type shardOutcome struct {
+	shard    shard
+	result   *testkube.ExecutionResult
+	duration time.Duration
+}
+
+// executeSharded creates the shard jobs and watches them, the execution result aggregates results of all shards
+func (c *JobExecutor) executeSharded(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+	result := execution.ExecutionResult
+	shards, err := newShards(*execution, options)
+	if err != nil {
+		return result.Err(err), err
+	}
+
+	l := c.Log.With("executionID", execution.Id, "shards", len(shards))
+	for i := range shards {
+		if err = c.CreateJob(ctx, shards[i].execution, shards[i].options); err != nil {
+			// shards are started all or none, so the created ones are stopped
+			c.abortShards(context.WithoutCancel(ctx), l, execution.TestNamespace, shards[:i])
+			return result.Err(err), err
+		}
+	}
+
+	c.streamLog(ctx, execution.Id, events.NewLog(fmt.Sprintf("created %d kubernetes jobs", len(shards))).WithSource(events.SourceJobExecutor))
+
+	if options.Sync {
+		return c.watchShards(ctx, l, execution, options, shards)
+	}
+
+	// abort stops watching the shards, see Abort
+	watchCtx, release := c.aborts.Watch(ctx, execution.Id)
+	go func() {
+		defer release()
+		if _, err := c.watchShards(watchCtx, l, execution, options, shards); err != nil {
+			l.Errorw("watching shards error", "error", err)
+		}
+	}()
+
+	return result, nil
+}
+
+// watchShards waits for all the shards and stores the merged result, when the context is cancelled
+// the shard jobs still running are stopped, and the result is left for the one cancelling the execution
+func (c *JobExecutor) watchShards(ctx context.Context, l *zap.SugaredLogger, execution *testkube.Execution, options ExecuteOptions, shards []shard) (*testkube.ExecutionResult, error) {
+	outcomes := make([]shardOutcome, len(shards))
+	var wg sync.WaitGroup
+	for i := range shards {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := time.Now()
+			result := c.shardResult(ctx, l.With("shard", shards[i].index), shards[i])
+			outcomes[i] = shardOutcome{shard: shards[i], result: result, duration: time.Since(start)}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		c.abortShards(context.WithoutCancel(ctx), l, execution.TestNamespace, shards)
+		return execution.ExecutionResult, err
+	}
+
+	execution.ExecutionResult = mergeShardResults(options.Sharding.Merge, outcomes)
+	if execution.ExecutionResult.IsFailed() {
+		c.streamLog(ctx, execution.Id, events.NewErrorLog(errors.New(execution.ExecutionResult.ErrorMessage)))
+	} else {
+		c.streamLog(ctx, execution.Id, events.NewLog("test execution finshed").WithMetadataEntry("status", string(*execution.ExecutionResult.Status)))
+	}
+
+	if err := c.stopExecution(ctx, l, execution, execution.ExecutionResult, options.Request.NegativeTest, nil); err != nil {
+		c.streamLog(ctx, execution.Id, events.NewErrorLog(err))
+		l.Errorw("error stopping execution after watching shards", "error", err)
+	}
+
+	return execution.ExecutionResult, nil
+}
+
+// shardResult waits for the shard pod and parses its output, the shard which pod doesn't start
+// within the pod start timeout fails and its job is stopped, so it doesn't block the other shards
+func (c *JobExecutor) shardResult(ctx context.Context, l *zap.SugaredLogger, s shard) *testkube.ExecutionResult {
+	failed := func(err error) *testkube.ExecutionResult {
+		result := testkube.NewErrorExecutionResult(err)
+		return &result
+	}
+
+	namespace := s.execution.TestNamespace
+	defer func() {
+		if err := c.cleanPVCVolume(context.WithoutCancel(ctx), &s.execution); err != nil {
+			l.Errorw("error cleaning shard pvc volume", "error", err)
+		}
+	}()
+
+	pods, err := executor.GetJobPods(ctx, c.ClientSet.CoreV1().Pods(namespace), s.execution.Id, 1, 10)
+	if err != nil {
+		return failed(err)
+	}
+	pod := pods.Items[0]
+
+	if err = c.podWatcher().PollUntil(ctx, c.podStartTimeout, executor.IsPodLoggable(c.ClientSet, pod.Name, namespace)); err != nil {
+		l.Errorw("waiting for shard pod started error", "error", err)
+		if ctx.Err() == nil {
+			c.abortShards(ctx, l, namespace, []shard{s})
+		}
+		return failed(fmt.Errorf("shard %d pod was not started: %w", s.index, err))
+	}
+
+	if err = c.waitForPodCompleted(ctx, l, pod.Name, namespace); err != nil {
+		// continue on poll err and try to get logs later
+		l.Errorw("waiting for shard pod complete error", "error", err)
+	}
+
+	logs, err := executor.GetPodLogs(ctx, c.ClientSet, namespace, pod)
+	if err != nil {
+		return failed(err)
+	}
+
+	result, err := c.parseOutput(logs, s.options.OutputFormat, !c.features.LogsV2)
+	if err != nil {
+		l.Errorw("parse shard output error", "error", err)
+		return result
+	}
+
+	if current, err := c.ClientSet.CoreV1().Pods(namespace).Get(ctx, pod.Name, metav1.GetOptions{}); err == nil {
+		if err = ServiceFailure(*current); err != nil {
+			result.Err(err)
+		}
+	}
+
+	if c.deadlineExceeded(ctx, s.execution.Id, namespace, pod.Name) {
+		result.Timeout()
+		result.ErrorMessage = "execution took too long, pod deadline exceeded"
+	} else if result.IsFailed() && result.ErrorMessage == "" {
+		result.ErrorMessage = executor.GetPodErrorMessage(ctx, c.ClientSet, &pod)
+	}
+
+	return result
+}
+
+// abortShards deletes jobs of the shards, errors are only logged, as the other shards should be stopped anyway
+func (c *JobExecutor) abortShards(ctx context.Context, l *zap.SugaredLogger, namespace string, shards []shard) {
+	for _, s := range shards {
+		if result, _ := executor.AbortJob(ctx, c.ClientSet, namespace, s.execution.Id); !result.IsAborted() {
+			l.Errorw("error aborting shard job", "shard", s.index, "error", result.Output)
+		}
+	}
+}
+
+// abortShardJobs deletes jobs of the execution split into shards, it reports false when the execution has no shard jobs
+func (c *JobExecutor) abortShardJobs(ctx context.Context, l *zap.SugaredLogger, execution testkube.Execution) (*testkube.ExecutionResult, bool) {
+	jobs, err := c.ClientSet.BatchV1().Jobs(execution.TestNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: ShardOfLabel + "=" + SanitizeLabelValue(execution.Id),
+	})
+	if err != nil || len(jobs.Items) == 0 {
+		return nil, false
+	}
+
+	result := &testkube.ExecutionResult{Status: testkube.ExecutionStatusAborted}
+	for _, job := range jobs.Items {
+		shardResult, _ := executor.AbortJob(ctx, c.ClientSet, execution.TestNamespace, job.Name)
+		if !shardResult.IsAborted() {
+			l.Errorw("error aborting shard job", "job", job.Name, "error", shardResult.Output)
+			result = shardResult
+		}
+	}
+
+	return result, true
+}
+
+// mergeShardResults aggregates the shard results into the execution result, the execution is aborted when any shard
+// was aborted, failed when any shard failed or timed out, and passed otherwise; the output summarizes the shards
+func mergeShardResults(merge ShardMerge, outcomes []shardOutcome) *testkube.ExecutionResult {
+	result := &testkube.ExecutionResult{Status: testkube.ExecutionStatusPassed, OutputType: "text/plain"}
+	sort.Slice(outcomes, func(i, j int) bool {
+		return outcomes[i].shard.index < outcomes[j].shard.index
+	})
+
+	var failed, aborted int
+	var summary strings.Builder
+	for _, outcome := range outcomes {
+		shardResult := outcome.result
+		if shardResult == nil || shardResult.Status == nil {
+			missing := testkube.NewErrorExecutionResult(errors.New("shard result is missing"))
+			shardResult = &missing
+		}
+
+		switch {
+		case shardResult.IsAborted():
+			aborted++
+		case shardResult.IsFailed() || shardResult.IsTimeout():
+			failed++
+		}
+
+		status := *shardResult.Status
+		duration := outcome.duration.Round(time.Millisecond).String()
+		result.Shards = append(result.Shards, testkube.ExecutionShardResult{
+			Index:        int32(outcome.shard.index),
+			ExecutionId:  outcome.shard.execution.Id,
+			Status:       &status,
+			ErrorMessage: shardResult.ErrorMessage,
+			Duration:     duration,
+			Output:       shardResult.Output,
+		})
+
+		fmt.Fprintf(&summary, "shard %d/%d: %s in %s", outcome.shard.index+1, len(outcomes), status, duration)
+		if shardResult.ErrorMessage != "" {
+			fmt.Fprintf(&summary, ": %s", shardResult.ErrorMessage)
+		}
+		summary.WriteString("\n")
+
+		if merge != ShardMergeNone {
+			for _, step := range shardResult.Steps {
+				step.Name = fmt.Sprintf("shard %d: %s", outcome.shard.index, step.Name)
+				result.Steps = append(result.Steps, step)
+			}
+		}
+	}
+
+	result.Output = summary.String()
+	switch {
+	case aborted > 0:
+		result.Status = testkube.ExecutionStatusAborted
+		result.ErrorMessage = fmt.Sprintf("%d of %d shards aborted", aborted, len(outcomes))
+	case failed > 0:
+		result.Status = testkube.ExecutionStatusFailed
+		result.ErrorMessage = fmt.Sprintf("%d of %d shards failed", failed, len(outcomes))
+	}
+
+	return result
+}
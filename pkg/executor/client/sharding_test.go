@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func TestSharding_Validate(t *testing.T) {
+	assert.NoError(t, Sharding{Count: 4, Envs: map[string]string{"PLAYWRIGHT_SHARD": "{{ .Number }}/{{ .Total }}"}}.Validate())
+	assert.NoError(t, Sharding{Count: 1, Merge: ShardMergeNone}.Validate())
+
+	err := Sharding{Count: MaxShards + 1, Merge: "zip", Envs: map[string]string{"1BAD": "x", "SHARD": "{{ .Number "}}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sharding count should be between 1 and 100, 101 provided")
+	assert.Contains(t, err.Error(), "unknown sharding merge strategy zip")
+	assert.Contains(t, err.Error(), `sharding env "1BAD" name is invalid`)
+	assert.Contains(t, err.Error(), `sharding env "SHARD" template is invalid`)
+}
+
+func TestSharding_IsSharded(t *testing.T) {
+	var sharding *Sharding
+	assert.False(t, sharding.IsSharded())
+	assert.False(t, (&Sharding{Count: 1}).IsSharded())
+	assert.True(t, (&Sharding{Count: 2}).IsSharded())
+}
+
+func TestSharding_ShardEnvs(t *testing.T) {
+	sharding := Sharding{Count: 3, Envs: map[string]string{"PLAYWRIGHT_SHARD": "{{ .Number }}/{{ .Total }}", "SUITE": "part-{{ .Index }}"}}
+
+	envs, err := sharding.ShardEnvs(1)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"PLAYWRIGHT_SHARD": "2/3",
+		"SUITE":            "part-1",
+		"SHARD_INDEX":      "1",
+		"SHARD_TOTAL":      "3",
+	}, envs)
+}
+
+func TestNewShards(t *testing.T) {
+	execution := testkube.Execution{Id: "exec-1", TestNamespace: "default"}
+	options := ExecuteOptions{
+		Envs:     map[string]string{"BASE": "1"},
+		Labels:   map[string]string{"team": "qa"},
+		Sharding: &Sharding{Count: 2, Envs: map[string]string{"PART": "{{ .Number }}"}},
+	}
+
+	shards, err := newShards(execution, options)
+
+	require.NoError(t, err)
+	require.Len(t, shards, 2)
+	for i, s := range shards {
+		assert.Equal(t, i, s.index)
+		assert.Equal(t, ShardExecutionID("exec-1", i), s.execution.Id)
+		assert.True(t, s.execution.ExecutionResult.IsRunning())
+		assert.Equal(t, "1", s.options.Envs["BASE"])
+		assert.Equal(t, "qa", s.options.Labels["team"])
+		assert.Equal(t, "exec-1", s.options.Labels[ShardOfLabel])
+	}
+	assert.Equal(t, "exec-1-shard-1", shards[1].execution.Id)
+	assert.Equal(t, "2", shards[1].options.Envs["PART"])
+	assert.Equal(t, "1", shards[1].options.Labels[ShardIndexLabel])
+	// options of the execution are not changed
+	assert.Equal(t, map[string]string{"BASE": "1"}, options.Envs)
+	assert.Equal(t, map[string]string{"team": "qa"}, options.Labels)
+}
+
+func newShardOutcome(index int, result *testkube.ExecutionResult) shardOutcome {
+	return shardOutcome{
+		shard:    shard{index: index, execution: testkube.Execution{Id: ShardExecutionID("exec-1", index)}},
+		result:   result,
+		duration: 1500 * time.Millisecond,
+	}
+}
+
+func TestMergeShardResults(t *testing.T) {
+	failed := testkube.NewErrorExecutionResult(errors.New("2 tests failed"))
+	failed.Steps = []testkube.ExecutionStepResult{{Name: "checkout", Status: "failed"}}
+	outcomes := []shardOutcome{
+		newShardOutcome(1, &failed),
+		newShardOutcome(0, &testkube.ExecutionResult{
+			Status: testkube.ExecutionStatusPassed,
+			Output: "ok",
+			Steps:  []testkube.ExecutionStepResult{{Name: "login", Status: "passed"}},
+		}),
+	}
+
+	result := mergeShardResults(ShardMergeSteps, outcomes)
+
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, "1 of 2 shards failed", result.ErrorMessage)
+	assert.Equal(t, "shard 1/2: passed in 1.5s\nshard 2/2: failed in 1.5s: 2 tests failed\n", result.Output)
+	assert.Equal(t, []testkube.ExecutionStepResult{
+		{Name: "shard 0: login", Status: "passed"},
+		{Name: "shard 1: checkout", Status: "failed"},
+	}, result.Steps)
+	require.Len(t, result.Shards, 2)
+	assert.Equal(t, "exec-1-shard-0", result.Shards[0].ExecutionId)
+	assert.Equal(t, "ok", result.Shards[0].Output)
+	assert.Equal(t, "1.5s", result.Shards[1].Duration)
+	assert.Equal(t, testkube.FAILED_ExecutionStatus, *result.Shards[1].Status)
+
+	result = mergeShardResults(ShardMergeNone, outcomes)
+	assert.Empty(t, result.Steps)
+}
+
+func TestMergeShardResultsStatus(t *testing.T) {
+	passed := &testkube.ExecutionResult{Status: testkube.ExecutionStatusPassed}
+	aborted := &testkube.ExecutionResult{Status: testkube.ExecutionStatusAborted}
+	timeout := &testkube.ExecutionResult{Status: testkube.ExecutionStatusTimeout}
+
+	result := mergeShardResults(ShardMergeSteps, []shardOutcome{newShardOutcome(0, passed), newShardOutcome(1, passed)})
+	assert.True(t, result.IsPassed())
+	assert.Empty(t, result.ErrorMessage)
+
+	result = mergeShardResults(ShardMergeSteps, []shardOutcome{newShardOutcome(0, timeout), newShardOutcome(1, passed)})
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, "1 of 2 shards failed", result.ErrorMessage)
+
+	result = mergeShardResults(ShardMergeSteps, []shardOutcome{newShardOutcome(0, timeout), newShardOutcome(1, aborted)})
+	assert.True(t, result.IsAborted())
+	assert.Equal(t, "1 of 2 shards aborted", result.ErrorMessage)
+
+	result = mergeShardResults(ShardMergeSteps, []shardOutcome{newShardOutcome(0, nil), newShardOutcome(1, passed)})
+	assert.True(t, result.IsFailed())
+	assert.Equal(t, "shard result is missing", result.Shards[0].ErrorMessage)
+}
+
+func TestJobExecutor_AbortShardJobs(t *testing.T) {
+	shardJob := func(name string, index int) *batchv1.Job {
+		return &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default",
+			Labels: map[string]string{ShardOfLabel: "exec-1", ShardIndexLabel: strconv.Itoa(index)}}}
+	}
+	executor := &JobExecutor{
+		Log:       zap.NewNop().Sugar(),
+		ClientSet: fake.NewSimpleClientset(shardJob("exec-1-shard-0", 0), shardJob("exec-1-shard-1", 1)),
+	}
+
+	_, found := executor.abortShardJobs(context.Background(), executor.Log, testkube.Execution{Id: "exec-2", TestNamespace: "default"})
+	assert.False(t, found)
+
+	result, found := executor.abortShardJobs(context.Background(), executor.Log, testkube.Execution{Id: "exec-1", TestNamespace: "default"})
+	assert.True(t, found)
+	assert.True(t, result.IsAborted())
+	jobs, err := executor.ClientSet.BatchV1().Jobs("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, jobs.Items)
+}
+
+func TestExecuteOptions_ValidateCapabilitiesSharding(t *testing.T) {
+	options := ExecuteOptions{Sharding: &Sharding{Count: 3}}
+
+	assert.NoError(t, options.ValidateCapabilities(FullCapabilities))
+	assert.EqualError(t, options.ValidateCapabilities(Capabilities{}), "sharding: option is not supported by the executor")
+	assert.NoError(t, ExecuteOptions{Sharding: &Sharding{Count: 1}}.ValidateCapabilities(Capabilities{}))
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+var (
+	// ErrAlreadyFinished is matched by AlreadyFinishedError, i.e. with errors.Is
+	ErrAlreadyFinished = errors.New("execution already finished")
+	// ErrNotPaused is returned when resumed execution isn't paused
+	ErrNotPaused = errors.New("execution is not paused")
+	// ErrSuspendNotSupported is returned when kubernetes server is too old to suspend jobs
+	ErrSuspendNotSupported = errors.New("suspending jobs is not supported by the kubernetes server")
+)
+
+// AlreadyFinishedError is returned when aborted, suspended or resumed execution already reached terminal state
+type AlreadyFinishedError struct {
+	ExecutionID string
+	// Result is the terminal execution result, it's returned together with the error too
+	Result *testkube.ExecutionResult
+}
+
+func (e *AlreadyFinishedError) Error() string {
+	if e.Result == nil || e.Result.Status == nil {
+		return fmt.Sprintf("execution %s already finished", e.ExecutionID)
+	}
+	return fmt.Sprintf("execution %s already finished with status %s", e.ExecutionID, *e.Result.Status)
+}
+
+func (e *AlreadyFinishedError) Is(target error) bool {
+	return target == ErrAlreadyFinished
+}
+
+// IsAlreadyFinished checks if the error reports already finished execution
+func IsAlreadyFinished(err error) bool {
+	return errors.Is(err, ErrAlreadyFinished)
+}
+
+// Suspender is implemented by executors able to pause running execution and continue it later
+type Suspender interface {
+	// Suspend pauses running execution and records it as paused
+	Suspend(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error)
+	// Resume continues paused execution, options are the ones the execution was started with,
+	// i.e. so the negative test result is evaluated as on execute
+	Resume(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error)
+}
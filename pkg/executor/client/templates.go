@@ -0,0 +1,179 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+// NewRequestMachine creates expressions machine for execution request templates, it exposes execution metadata,
+// trigger context, batch index and request environment variables as env, secret variables are not exposed
+func NewRequestMachine(execution testkube.Execution, request testkube.ExecutionRequest) expressionstcl.Machine {
+	env := make(map[string]string, len(request.Envs)+len(request.Variables))
+	for name, value := range request.Envs {
+		env[name] = value
+	}
+
+	for name, variable := range request.Variables {
+		if !variable.IsSecret() && variable.ConfigMapRef == nil {
+			env[name] = variable.Value
+		}
+	}
+
+	machine := expressionstcl.NewMachine().RegisterStringMap("env", env)
+	if execution.RunningContext != nil {
+		machine.
+			Register("trigger.type", execution.RunningContext.Type_).
+			Register("trigger.context", execution.RunningContext.Context)
+	}
+
+	// index is available only for batch executions, so templates using it fail for single ones
+	if index, err := strconv.Atoi(execution.Labels[BatchIndexLabel]); err == nil {
+		machine.Register("index", index)
+	}
+
+	return machine
+}
+
+// ResolveRequest evaluates expressions in execution request name, labels, command, args and environment variables
+// in place, resolved values are applied to the execution and recorded in its resolved expressions,
+// all fields which can't be resolved are reported together
+func ResolveRequest(execution *testkube.Execution, options *ExecuteOptions) error {
+	request := &options.Request
+	machines := []expressionstcl.Machine{NewRequestMachine(*execution, *request), NewExecutionMachine(*execution)}
+	resolved := make(map[string]string)
+	var errs []error
+	resolve := func(field string, value *string) {
+		if !isExpression(*value) {
+			return
+		}
+
+		result, err := expressionstcl.EvalTemplate(*value, machines...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resolving request field %s expression %s: %w", field, *value, err))
+			return
+		}
+
+		*value = result
+		resolved[field] = result
+	}
+
+	resolve("name", &request.Name)
+	for _, key := range sortedKeys(request.ExecutionLabels) {
+		value := request.ExecutionLabels[key]
+		resolve("executionLabels."+key, &value)
+		request.ExecutionLabels[key] = value
+	}
+
+	for i := range request.Command {
+		resolve(fmt.Sprintf("command[%d]", i), &request.Command[i])
+	}
+
+	for i := range request.Args {
+		resolve(fmt.Sprintf("args[%d]", i), &request.Args[i])
+	}
+
+	for _, key := range sortedKeys(request.Envs) {
+		value := request.Envs[key]
+		resolve("envs."+key, &value)
+		request.Envs[key] = value
+	}
+
+	names := make([]string, 0, len(request.Variables))
+	for name := range request.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		variable := request.Variables[name]
+		resolve("variables."+name, &variable.Value)
+		request.Variables[name] = variable
+	}
+
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+
+	if len(resolved) != 0 {
+		applyResolvedRequest(execution, *request, resolved)
+	}
+
+	return nil
+}
+
+// applyResolvedRequest copies resolved request values to the execution built from the request
+func applyResolvedRequest(execution *testkube.Execution, request testkube.ExecutionRequest, resolved map[string]string) {
+	if _, ok := resolved["name"]; ok {
+		execution.Name = request.Name
+	}
+
+	for key, value := range request.ExecutionLabels {
+		if _, ok := resolved["executionLabels."+key]; !ok {
+			continue
+		}
+
+		if execution.Labels == nil {
+			execution.Labels = make(map[string]string)
+		}
+		execution.Labels[key] = value
+	}
+
+	if hasResolvedItem(resolved, "command", len(request.Command)) {
+		execution.Command = request.Command
+	}
+
+	if hasResolvedItem(resolved, "args", len(request.Args)) {
+		execution.Args = request.Args
+	}
+
+	for key, value := range request.Envs {
+		if _, ok := resolved["envs."+key]; !ok {
+			continue
+		}
+
+		if execution.Envs == nil {
+			execution.Envs = make(map[string]string)
+		}
+		execution.Envs[key] = value
+	}
+
+	for name, variable := range request.Variables {
+		if _, ok := resolved["variables."+name]; !ok {
+			continue
+		}
+
+		if execution.Variables == nil {
+			execution.Variables = make(map[string]testkube.Variable)
+		}
+		execution.Variables[name] = variable
+	}
+
+	if execution.ResolvedExpressions == nil {
+		execution.ResolvedExpressions = make(map[string]string, len(resolved))
+	}
+	for field, value := range resolved {
+		execution.ResolvedExpressions[field] = value
+	}
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hasResolvedItem(resolved map[string]string, field string, length int) bool {
+	for i := 0; i < length; i++ {
+		if _, ok := resolved[fmt.Sprintf("%s[%d]", field, i)]; ok {
+			return true
+		}
+	}
+	return false
+}
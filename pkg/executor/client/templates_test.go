@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func TestResolveRequest(t *testing.T) {
+	t.Run("plain strings", func(t *testing.T) {
+		execution := &testkube.Execution{Id: "exec-1", Name: "smoke-1", Args: []string{"--env", "staging"}}
+		options := ExecuteOptions{Request: testkube.ExecutionRequest{
+			Name: "smoke-1",
+			Args: []string{"--env", "staging"},
+			Envs: map[string]string{"BRANCH": "main"},
+		}}
+
+		require.NoError(t, ResolveRequest(execution, &options))
+		assert.Equal(t, testkube.ExecutionRequest{
+			Name: "smoke-1",
+			Args: []string{"--env", "staging"},
+			Envs: map[string]string{"BRANCH": "main"},
+		}, options.Request)
+		assert.Equal(t, &testkube.Execution{Id: "exec-1", Name: "smoke-1", Args: []string{"--env", "staging"}}, execution)
+	})
+
+	t.Run("templates", func(t *testing.T) {
+		execution := &testkube.Execution{
+			Id:             "exec-1",
+			TestName:       "e2e",
+			Labels:         map[string]string{BatchIndexLabel: "2"},
+			RunningContext: &testkube.RunningContext{Type_: "testtrigger", Context: "deploy-trigger"},
+		}
+		options := ExecuteOptions{Request: testkube.ExecutionRequest{
+			Name:            "{{ test.name }}-shard-{{ index }}",
+			ExecutionLabels: map[string]string{"trigger": "{{ trigger.context }}", "team": "qa"},
+			Args:            []string{"--shard", "{{ index + 1 }}", "--branch={{ env.BRANCH }}"},
+			Envs:            map[string]string{"BRANCH": "main"},
+			Variables: map[string]testkube.Variable{
+				"TRIGGER": testkube.NewBasicVariable("TRIGGER", "{{ trigger.type }}"),
+			},
+		}}
+
+		require.NoError(t, ResolveRequest(execution, &options))
+		assert.Equal(t, "e2e-shard-2", options.Request.Name)
+		assert.Equal(t, map[string]string{"trigger": "deploy-trigger", "team": "qa"}, options.Request.ExecutionLabels)
+		assert.Equal(t, []string{"--shard", "3", "--branch=main"}, options.Request.Args)
+		assert.Equal(t, "testtrigger", options.Request.Variables["TRIGGER"].Value)
+
+		assert.Equal(t, "e2e-shard-2", execution.Name)
+		assert.Equal(t, map[string]string{BatchIndexLabel: "2", "trigger": "deploy-trigger"}, execution.Labels)
+		assert.Equal(t, []string{"--shard", "3", "--branch=main"}, execution.Args)
+		assert.Equal(t, "testtrigger", execution.Variables["TRIGGER"].Value)
+		assert.Equal(t, map[string]string{
+			"name":                    "e2e-shard-2",
+			"executionLabels.trigger": "deploy-trigger",
+			"args[1]":                 "3",
+			"args[2]":                 "--branch=main",
+			"variables.TRIGGER":       "testtrigger",
+		}, execution.ResolvedExpressions)
+	})
+
+	t.Run("missing variable", func(t *testing.T) {
+		execution := &testkube.Execution{Id: "exec-1"}
+		options := ExecuteOptions{Request: testkube.ExecutionRequest{
+			Args: []string{"--shard", "{{ index }}"},
+			Envs: map[string]string{"TOKEN": "{{ env.MISSING }}"},
+		}}
+
+		err := ResolveRequest(execution, &options)
+
+		assert.ErrorContains(t, err, "resolving request field args[1] expression {{ index }}")
+		assert.ErrorContains(t, err, "resolving request field envs.TOKEN expression {{ env.MISSING }}")
+		assert.Empty(t, execution.ResolvedExpressions)
+	})
+}
+
+func TestExecutionRunner_ExecuteAsyncResolvesRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutor := NewMockExecutor(ctrl)
+	mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, execution *testkube.Execution, options ExecuteOptions) (*testkube.ExecutionResult, error) {
+			assert.Equal(t, []string{"--suite", "k6-smoke"}, options.Request.Args)
+			return testkube.NewRunningExecutionResult(), nil
+		})
+	runner := newTestExecutionRunner(mockExecutor, &completingExecutions{}, &recordingClock{})
+	request := testkube.ExecutionRequest{Args: []string{"--suite", "{{ test.name }}"}}
+
+	_, err := runner.ExecuteAsync(context.Background(), &testkube.Execution{Id: "exec-1", TestName: "k6-smoke"}, ExecuteOptions{Request: request})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--suite", "{{ test.name }}"}, request.Args)
+
+	failed := &testkube.Execution{Id: "exec-2"}
+	_, err = runner.ExecuteAsync(context.Background(), failed, ExecuteOptions{Request: testkube.ExecutionRequest{Name: "{{ index }}"}})
+	assert.ErrorContains(t, err, "resolving request field name")
+	assert.True(t, failed.ExecutionResult.IsFailed())
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+// ErrConditionNotMet is matched by ConditionNotMetError, i.e. with errors.Is
+var ErrConditionNotMet = errors.New("execution finished without meeting the condition")
+
+// ConditionNotMetError is returned by WaitForCondition when the execution reached terminal state
+// and the condition didn't become true
+type ConditionNotMetError struct {
+	ExecutionID string
+	Condition   string
+	// Result is the final execution result the condition was evaluated on
+	Result *testkube.ExecutionResult
+	// Err is the last evaluation error, it's nil when the condition resolved to false
+	Err error
+}
+
+func (e *ConditionNotMetError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("execution %s finished without meeting condition %q: %v", e.ExecutionID, e.Condition, e.Err)
+	}
+	return fmt.Sprintf("execution %s finished without meeting condition %q", e.ExecutionID, e.Condition)
+}
+
+func (e *ConditionNotMetError) Is(target error) bool {
+	return target == ErrConditionNotMet
+}
+
+func (e *ConditionNotMetError) Unwrap() error {
+	return e.Err
+}
+
+// WaitOptions configure WaitForCondition
+type WaitOptions struct {
+	// TickInterval is how often the condition is evaluated between status transitions,
+	// i.e. for conditions over the output of running execution, zero evaluates it only on transitions
+	TickInterval time.Duration
+}
+
+// DefaultWaitOptions returns options evaluating the condition also every WatchInterval
+func DefaultWaitOptions() WaitOptions {
+	return WaitOptions{TickInterval: WatchInterval}
+}
+
+// Validate checks if wait options are valid
+func (o WaitOptions) Validate() error {
+	if o.TickInterval < 0 {
+		return errors.New("wait tick interval can't be negative")
+	}
+
+	return nil
+}
+
+// WaitForCondition watches the execution, see WatchExecution, and evaluates the condition over its state,
+// see EvaluateOnResult, on every status transition and tick; it returns the execution once the condition is true,
+// ConditionNotMetError when the execution finished without it, or the context error when it's done before.
+// The condition which doesn't compile is rejected before waiting, while evaluation errors of not finished execution
+// are not final, as the condition may refer to the values which are not there yet
+func (r *ExecutionRunner) WaitForCondition(ctx context.Context, id, condition string, options WaitOptions) (*testkube.Execution, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := expressionstcl.Compile(condition); err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", condition, err)
+	}
+
+	// Stop watching as soon as waiting is over
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, err := r.WatchExecution(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var tick <-chan time.Time
+	if options.TickInterval > 0 {
+		tick = r.watcher.clock.After(options.TickInterval)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				return nil, ctx.Err()
+			}
+
+			if event.Err != nil {
+				return nil, fmt.Errorf("watching execution %s: %w", id, event.Err)
+			}
+
+			execution := event.execution
+			met, err := evaluateCondition(condition, execution)
+			if met {
+				return &execution, nil
+			}
+
+			if event.IsTerminal() {
+				return nil, &ConditionNotMetError{ExecutionID: id, Condition: condition, Result: execution.ExecutionResult, Err: err}
+			}
+		case <-tick:
+			// Reading errors are not final, the watch reports them when they persist
+			if execution, err := r.executions.Get(ctx, id); err == nil {
+				if met, _ := evaluateCondition(condition, execution); met {
+					return &execution, nil
+				}
+			}
+
+			tick = r.watcher.clock.After(options.TickInterval)
+		}
+	}
+}
+
+// evaluateCondition tells if the condition resolved to true over the execution, non-boolean value is an error
+func evaluateCondition(condition string, execution testkube.Execution) (bool, error) {
+	value, err := EvaluateOnResult(condition, execution)
+	if err != nil {
+		return false, err
+	}
+
+	met, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("condition should resolve to boolean, %v provided", value)
+	}
+
+	return met, nil
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// scriptedExecutions returns scripted execution states, the last one is repeated when they run out
+type scriptedExecutions struct {
+	mu     sync.Mutex
+	states []testkube.ExecutionResult
+	polls  int
+}
+
+func (s *scriptedExecutions) Get(ctx context.Context, id string) (testkube.Execution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.states[min(s.polls, len(s.states)-1)]
+	s.polls++
+	return testkube.Execution{Id: id, ExecutionResult: &state}, nil
+}
+
+func (s *scriptedExecutions) Polls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.polls
+}
+
+func executionState(status testkube.ExecutionStatus, output string) testkube.ExecutionResult {
+	return testkube.ExecutionResult{Status: &status, Output: output}
+}
+
+func TestExecutionRunner_WaitForConditionMetOnTransition(t *testing.T) {
+	executions := &scriptedExecutions{states: []testkube.ExecutionResult{
+		executionState("queued", ""),
+		executionState("running", ""),
+		executionState("passed", "done"),
+	}}
+
+	execution, err := newTestExecutionRunner(nil, executions, &recordingClock{}).
+		WaitForCondition(context.Background(), "exec-1", `result.status == "passed"`, WaitOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "exec-1", execution.Id)
+	assert.Equal(t, "done", execution.ExecutionResult.Output)
+}
+
+func TestExecutionRunner_WaitForConditionMetOnTick(t *testing.T) {
+	executions := &scriptedExecutions{states: []testkube.ExecutionResult{
+		executionState("running", "starting"),
+		executionState("running", "starting"),
+		executionState("running", "server ready"),
+	}}
+
+	// The status doesn't change anymore, so only the tick observes the output
+	execution, err := newTestExecutionRunner(nil, executions, &recordingClock{}).
+		WaitForCondition(context.Background(), "exec-1", `contains(result.output, "ready")`, WaitOptions{TickInterval: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, testkube.RUNNING_ExecutionStatus, *execution.ExecutionResult.Status)
+	assert.Equal(t, "server ready", execution.ExecutionResult.Output)
+}
+
+func TestExecutionRunner_WaitForConditionNotMet(t *testing.T) {
+	executions := &scriptedExecutions{states: []testkube.ExecutionResult{
+		executionState("running", ""),
+		executionState("failed", "assertion failed"),
+	}}
+
+	execution, err := newTestExecutionRunner(nil, executions, &recordingClock{}).
+		WaitForCondition(context.Background(), "exec-1", `result.status == "passed"`, WaitOptions{})
+	assert.Nil(t, execution)
+	assert.ErrorIs(t, err, ErrConditionNotMet)
+
+	var notMetErr *ConditionNotMetError
+	require.True(t, errors.As(err, &notMetErr))
+	assert.Equal(t, "exec-1", notMetErr.ExecutionID)
+	assert.Equal(t, testkube.FAILED_ExecutionStatus, *notMetErr.Result.Status)
+	assert.Equal(t, "assertion failed", notMetErr.Result.Output)
+	assert.NoError(t, notMetErr.Err)
+}
+
+func TestExecutionRunner_WaitForConditionNotBoolean(t *testing.T) {
+	executions := &scriptedExecutions{states: []testkube.ExecutionResult{executionState("passed", "")}}
+
+	_, err := newTestExecutionRunner(nil, executions, &recordingClock{}).
+		WaitForCondition(context.Background(), "exec-1", `result.status`, WaitOptions{})
+	assert.ErrorIs(t, err, ErrConditionNotMet)
+	assert.ErrorContains(t, err, "condition should resolve to boolean")
+}
+
+func TestExecutionRunner_WaitForConditionContextDone(t *testing.T) {
+	executions := &scriptedExecutions{states: []testkube.ExecutionResult{executionState("running", "")}}
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := newFakeClock(time.Now())
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := newTestExecutionRunner(nil, executions, clock).
+			WaitForCondition(ctx, "exec-1", `result.status == "passed"`, WaitOptions{TickInterval: time.Second})
+		result <- err
+	}()
+
+	// Let the execution be observed as running, and the condition be evaluated on the tick too
+	<-clock.added
+	<-clock.added
+	clock.Advance(time.Second)
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+}
+
+func TestExecutionRunner_WaitForConditionInvalid(t *testing.T) {
+	executions := &scriptedExecutions{states: []testkube.ExecutionResult{executionState("passed", "")}}
+	runner := newTestExecutionRunner(nil, executions, &recordingClock{})
+
+	_, err := runner.WaitForCondition(context.Background(), "exec-1", `result.status ==`, WaitOptions{})
+	assert.ErrorContains(t, err, "invalid condition")
+	_, err = runner.WaitForCondition(context.Background(), "exec-1", `true`, WaitOptions{TickInterval: -time.Second})
+	assert.Error(t, err)
+	assert.Zero(t, executions.Polls())
+}
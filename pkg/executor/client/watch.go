@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// WatchOptions configure how often execution state is polled,
+// the interval grows after polls which didn't observe any state change and resets when state changes
+type WatchOptions struct {
+	// Interval is a delay before the first poll and after each state change
+	Interval time.Duration
+	// MaxInterval caps the interval growth
+	MaxInterval time.Duration
+	// Multiplier is applied to the interval after each poll without state change, values <= 1 disable backoff
+	Multiplier float64
+	// Jitter is a fraction of the interval randomly added or subtracted, e.g. 0.2 means +-20%
+	Jitter float64
+	// UseWatch makes the job executor watch jobs and pods with Kubernetes API instead of polling them,
+	// polling is used when the watch can't be started or breaks
+	UseWatch bool
+}
+
+// DefaultWatchOptions returns options polling every WatchInterval without backoff
+func DefaultWatchOptions() WatchOptions {
+	return WatchOptions{
+		Interval:    WatchInterval,
+		MaxInterval: WatchInterval,
+		Multiplier:  1,
+	}
+}
+
+// Validate checks if watch options are valid
+func (o WatchOptions) Validate() error {
+	if o.Interval <= 0 {
+		return errors.New("watch interval should be positive")
+	}
+
+	if o.MaxInterval < o.Interval {
+		return errors.New("watch max interval can't be lower than interval")
+	}
+
+	if o.Jitter < 0 || o.Jitter >= 1 {
+		return errors.New("watch jitter should be in range [0, 1)")
+	}
+
+	return nil
+}
+
+// WatchBackoff computes delays between consecutive polls
+type WatchBackoff struct {
+	options  WatchOptions
+	interval time.Duration
+	random   func() float64
+}
+
+// NewWatchBackoff creates new watch backoff, random returns numbers in range [0, 1) and defaults to math/rand
+func NewWatchBackoff(options WatchOptions, random func() float64) *WatchBackoff {
+	if random == nil {
+		random = rand.Float64
+	}
+
+	return &WatchBackoff{
+		options:  options,
+		interval: options.Interval,
+		random:   random,
+	}
+}
+
+// Next returns delay before the next poll, changed resets the interval to the initial value
+func (b *WatchBackoff) Next(changed bool) time.Duration {
+	if changed {
+		b.interval = b.options.Interval
+	}
+
+	delay := b.interval
+	if b.options.Jitter > 0 {
+		delay = time.Duration(float64(delay) * (1 + b.options.Jitter*(2*b.random()-1)))
+	}
+
+	if delay > b.options.MaxInterval {
+		delay = b.options.MaxInterval
+	}
+
+	if b.options.Multiplier > 1 {
+		b.interval = time.Duration(float64(b.interval) * b.options.Multiplier)
+		if b.interval > b.options.MaxInterval {
+			b.interval = b.options.MaxInterval
+		}
+	}
+
+	return delay
+}
+
+// PollFunc checks execution state, changed reports if state differs from the previous poll
+type PollFunc func(ctx context.Context) (done, changed bool, err error)
+
+// Watcher polls execution state with backoff between polls which didn't observe state change
+type Watcher struct {
+	log     *zap.SugaredLogger
+	options WatchOptions
+	clock   Clock
+	random  func() float64
+}
+
+// NewWatcher creates new watcher, nil clock uses system time
+func NewWatcher(log *zap.SugaredLogger, options WatchOptions, clock Clock) *Watcher {
+	if clock == nil {
+		clock = NewRealClock()
+	}
+
+	return &Watcher{
+		log:     log,
+		options: options,
+		clock:   clock,
+	}
+}
+
+// Poll calls poll function until it reports done, returns error or context is done
+func (w *Watcher) Poll(ctx context.Context, poll PollFunc) error {
+	backoff := NewWatchBackoff(w.options, w.random)
+	w.log.Debugw("watch schedule", "interval", w.options.Interval, "maxInterval", w.options.MaxInterval,
+		"multiplier", w.options.Multiplier, "jitter", w.options.Jitter)
+
+	changed := true
+	for {
+		delay := backoff.Next(changed)
+		w.log.Debugw("next watch poll", "delay", delay, "stateChanged", changed)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.clock.After(delay):
+		}
+
+		var done bool
+		var err error
+		if done, changed, err = poll(ctx); err != nil || done {
+			return err
+		}
+	}
+}
+
+// PollUntil checks condition immediately and then with watch backoff until it's done, returns error or timeout passes,
+// it replaces wait.PollUntilContextTimeout with fixed interval for waiting on pods
+func (w *Watcher) PollUntil(ctx context.Context, timeout time.Duration, condition wait.ConditionWithContextFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if done, err := condition(ctx); err != nil || done {
+		return err
+	}
+
+	return w.Poll(ctx, func(ctx context.Context) (done, changed bool, err error) {
+		done, err = condition(ctx)
+		return done, false, err
+	})
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type recordingClock struct {
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (c *recordingClock) Now() time.Time {
+	return time.Time{}
+}
+
+func (c *recordingClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.delays = append(c.delays, d)
+	c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- time.Time{}
+	return ch
+}
+
+func TestWatcher_BackoffSequence(t *testing.T) {
+	clock := &recordingClock{}
+	watcher := NewWatcher(zap.NewNop().Sugar(), WatchOptions{
+		Interval:    100 * time.Millisecond,
+		MaxInterval: time.Second,
+		Multiplier:  2,
+	}, clock)
+
+	// state changes on the 6th poll and execution ends on the 8th
+	polls := 0
+	err := watcher.Poll(context.Background(), func(ctx context.Context) (done, changed bool, err error) {
+		polls++
+		return polls == 8, polls == 6, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+	}, clock.delays)
+}
+
+func TestWatcher_Jitter(t *testing.T) {
+	clock := &recordingClock{}
+	watcher := NewWatcher(zap.NewNop().Sugar(), WatchOptions{
+		Interval:    100 * time.Millisecond,
+		MaxInterval: 300 * time.Millisecond,
+		Multiplier:  2,
+		Jitter:      0.5,
+	}, clock)
+	randoms := []float64{0, 0.75, 0.999}
+	watcher.random = func() float64 {
+		r := randoms[0]
+		randoms = randoms[1:]
+		return r
+	}
+
+	polls := 0
+	err := watcher.Poll(context.Background(), func(ctx context.Context) (done, changed bool, err error) {
+		polls++
+		return polls == 3, false, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{50 * time.Millisecond, 250 * time.Millisecond, 300 * time.Millisecond}, clock.delays)
+}
+
+func TestWatcher_DefaultOptions(t *testing.T) {
+	clock := &recordingClock{}
+	watcher := NewWatcher(zap.NewNop().Sugar(), DefaultWatchOptions(), clock)
+
+	polls := 0
+	err := watcher.Poll(context.Background(), func(ctx context.Context) (done, changed bool, err error) {
+		polls++
+		return polls == 3, false, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{WatchInterval, WatchInterval, WatchInterval}, clock.delays)
+}
+
+func TestWatcher_PollError(t *testing.T) {
+	pollErr := errors.New("api unavailable")
+	watcher := NewWatcher(zap.NewNop().Sugar(), DefaultWatchOptions(), &recordingClock{})
+
+	err := watcher.Poll(context.Background(), func(ctx context.Context) (done, changed bool, err error) {
+		return false, false, pollErr
+	})
+
+	assert.ErrorIs(t, err, pollErr)
+}
+
+func TestWatcher_PollUntil(t *testing.T) {
+	clock := &recordingClock{}
+	watcher := NewWatcher(zap.NewNop().Sugar(), WatchOptions{
+		Interval:    100 * time.Millisecond,
+		MaxInterval: time.Second,
+		Multiplier:  2,
+	}, clock)
+
+	checks := 0
+	err := watcher.PollUntil(context.Background(), time.Minute, func(ctx context.Context) (bool, error) {
+		checks++
+		return checks == 3, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, clock.delays)
+
+	// condition is checked before the first delay
+	clock.delays = nil
+	err = watcher.PollUntil(context.Background(), time.Minute, func(ctx context.Context) (bool, error) {
+		return true, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, clock.delays)
+}
+
+func TestWatcher_PollUntilTimeout(t *testing.T) {
+	watcher := NewWatcher(zap.NewNop().Sugar(), WatchOptions{Interval: time.Hour, MaxInterval: time.Hour}, nil)
+
+	err := watcher.PollUntil(context.Background(), 10*time.Millisecond, func(ctx context.Context) (bool, error) {
+		return false, nil
+	})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWatchOptionsValidate(t *testing.T) {
+	assert.NoError(t, DefaultWatchOptions().Validate())
+	assert.EqualError(t, WatchOptions{}.Validate(), "watch interval should be positive")
+	assert.EqualError(t, WatchOptions{Interval: time.Second, MaxInterval: time.Millisecond}.Validate(),
+		"watch max interval can't be lower than interval")
+	assert.EqualError(t, WatchOptions{Interval: time.Second, MaxInterval: time.Second, Jitter: 1}.Validate(),
+		"watch jitter should be in range [0, 1)")
+}
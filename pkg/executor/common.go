@@ -38,6 +38,8 @@ const (
 	GitTokenSecretName = "git-token"
 	// SlavesConfigsEnv is slave configs for creating slaves in executor
 	SlavesConfigsEnv = "RUNNER_SLAVES_CONFIGS"
+	// ServiceContainerPrefix is a name prefix of execution service containers, their logs are not part of the test output
+	ServiceContainerPrefix = "service-"
 
 	SidecarImage = "kubeshop/testkube-logs-sidecar:v0-3" // TODO - change it to valid image name after deployment will be ready
 )
@@ -352,7 +354,12 @@ func GetJobPods(ctx context.Context, podsClient tcorev1.PodInterface, jobName st
 	return pods, nil
 }
 
-// GetPodLogs returns pod logs bytes
+// IsServiceContainer checks if the container runs an execution service
+func IsServiceContainer(name string) bool {
+	return strings.HasPrefix(name, ServiceContainerPrefix)
+}
+
+// GetPodLogs returns pod logs bytes, service containers are skipped
 func GetPodLogs(ctx context.Context, c kubernetes.Interface, namespace string, pod corev1.Pod, logLinesCount ...int64) (logs []byte, err error) {
 	var count int64 = defaultLogLinesCount
 	if len(logLinesCount) > 0 {
@@ -361,6 +368,9 @@ func GetPodLogs(ctx context.Context, c kubernetes.Interface, namespace string, p
 
 	var containers []string
 	for _, container := range pod.Spec.InitContainers {
+		if IsServiceContainer(container.Name) {
+			continue
+		}
 		containers = append(containers, container.Name)
 	}
 
@@ -416,11 +426,12 @@ func GetContainerLogs(ctx context.Context, c kubernetes.Interface, pod *corev1.P
 // AbortJob - aborts Kubernetes Job with no grace period
 func AbortJob(ctx context.Context, c kubernetes.Interface, namespace string, jobName string) (*testkube.ExecutionResult, error) {
 	var zero int64 = 0
-	bg := metav1.DeletePropagationBackground
+	// foreground propagation keeps the job until its pods are gone, so nothing keeps running after abort
+	fg := metav1.DeletePropagationForeground
 	jobs := c.BatchV1().Jobs(namespace)
 	err := jobs.Delete(ctx, jobName, metav1.DeleteOptions{
 		GracePeriodSeconds: &zero,
-		PropagationPolicy:  &bg,
+		PropagationPolicy:  &fg,
 	})
 	if err != nil {
 		log.DefaultLogger.Errorf("Error while aborting job %s: %s", jobName, err.Error())
@@ -12,16 +12,15 @@ import (
 	"github.com/kubeshop/testkube/pkg/imageinspector"
 	"github.com/kubeshop/testkube/pkg/repository/config"
 	"github.com/kubeshop/testkube/pkg/secret"
-	"github.com/kubeshop/testkube/pkg/utils"
 
 	"github.com/kubeshop/testkube/pkg/repository/result"
 
 	"github.com/kubeshop/testkube/pkg/version"
 
 	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 
 	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
@@ -43,7 +42,6 @@ import (
 
 const (
 	pollTimeout             = 24 * time.Hour
-	pollInterval            = 200 * time.Millisecond
 	jobDefaultDelaySeconds  = 180
 	jobArtifactDelaySeconds = 90
 	repoPath                = "/data/repo"
@@ -78,10 +76,10 @@ func NewContainerExecutor(
 	debug bool,
 	logsStream logsclient.Stream,
 	features featureflags.FeatureFlags,
-) (client *ContainerExecutor, err error) {
+) (*ContainerExecutor, error) {
 	clientSet, err := k8sclient.ConnectToK8s()
 	if err != nil {
-		return client, err
+		return nil, err
 	}
 
 	if serviceAccountNames == nil {
@@ -112,6 +110,8 @@ func NewContainerExecutor(
 		debug:                debug,
 		logsStream:           logsStream,
 		features:             features,
+		aborts:               client.NewAbortRegistry(),
+		watchOptions:         client.DefaultWatchOptions(),
 	}, nil
 }
 
@@ -144,6 +144,9 @@ type ContainerExecutor struct {
 	debug                bool
 	logsStream           logsclient.Stream
 	features             featureflags.FeatureFlags
+	aborts               *client.AbortRegistry
+	watchOptions         client.WatchOptions
+	clock                client.Clock
 }
 
 type JobOptions struct {
@@ -181,6 +184,7 @@ type JobOptions struct {
 	EnvConfigMaps             []testkube.EnvReference
 	EnvSecrets                []testkube.EnvReference
 	Labels                    map[string]string
+	Annotations               map[string]string
 	Registry                  string
 	ClusterID                 string
 	ExecutionNumber           int32
@@ -191,6 +195,38 @@ type JobOptions struct {
 	NatsUri                   string
 	APIURI                    string
 	Features                  featureflags.FeatureFlags
+	Resources                 *client.Resources
+	NodeSelector              map[string]string
+	Tolerations               []corev1.Toleration
+	Affinity                  *corev1.Affinity
+	Services                  []client.Service
+}
+
+// WithWatchOptions sets how often pods are polled while waiting for them
+func (c *ContainerExecutor) WithWatchOptions(options client.WatchOptions) *ContainerExecutor {
+	c.watchOptions = options
+	return c
+}
+
+// podWatcher waits for pods with the watch options intervals, unset options fall back to the default ones
+func (c *ContainerExecutor) podWatcher() *client.Watcher {
+	options := c.watchOptions
+	if options.Interval <= 0 {
+		options = client.DefaultWatchOptions()
+	}
+	return client.NewWatcher(c.log, options, nil)
+}
+
+// Capabilities returns execute options supported by container executor, artifacts are collected
+// according to the execution artifact request, and logs can't be followed line by line
+func (c *ContainerExecutor) Capabilities() client.Capabilities {
+	return client.Capabilities{
+		SupportsAbort:             true,
+		SupportsResourceOverrides: true,
+		SupportsScheduling:        true,
+		SupportsCommandOverride:   true,
+		SupportsServices:          true,
+	}
 }
 
 // Logs returns job logs stream channel using kubernetes api
@@ -253,6 +289,10 @@ func (c *ContainerExecutor) Execute(ctx context.Context, execution *testkube.Exe
 	executionResult := testkube.NewRunningExecutionResult()
 	execution.ExecutionResult = executionResult
 
+	if err := c.validate(ctx, *execution, options); err != nil {
+		return executionResult.Err(err), err
+	}
+
 	jobOptions, err := c.createJob(ctx, *execution, options)
 	if err != nil {
 		executionResult.Err(err)
@@ -279,12 +319,23 @@ func (c *ContainerExecutor) Execute(ctx context.Context, execution *testkube.Exe
 	for _, pod := range pods.Items {
 		if pod.Status.Phase != corev1.PodRunning && pod.Labels["job-name"] == execution.Id {
 			if options.Sync {
-				return c.updateResultsFromPod(ctx, pod, l, execution, jobOptions, options.Request.NegativeTest)
+				stopDeadline := c.watchDeadline(ctx, execution, options.Timeout)
+				result, err := c.updateResultsFromPod(ctx, pod, l, execution, jobOptions, options.Request.NegativeTest)
+				if stopDeadline() {
+					return execution.ExecutionResult, nil
+				}
+				return result, err
 			}
 
-			// async wait for complete status or error
+			// async wait for complete status or error, abort stops watching the pod
+			watchCtx, release := c.aborts.Watch(ctx, execution.Id)
+			stopDeadline := c.watchDeadline(ctx, execution, options.Timeout)
 			go func(pod corev1.Pod) {
-				_, err := c.updateResultsFromPod(ctx, pod, l, execution, jobOptions, options.Request.NegativeTest)
+				defer release()
+				_, err := c.updateResultsFromPod(watchCtx, pod, l, execution, jobOptions, options.Request.NegativeTest)
+				if stopDeadline() {
+					return
+				}
 				if err != nil {
 					l.Errorw("update results from jobs pod error", "error", err)
 				}
@@ -299,16 +350,101 @@ func (c *ContainerExecutor) Execute(ctx context.Context, execution *testkube.Exe
 	return execution.ExecutionResult, nil
 }
 
+// watchDeadline aborts the execution when its timeout passes, as the job active deadline isn't enforced
+// when the executor job template doesn't set it; returned function stops watching the deadline when the execution
+// is done, and records the final result as timed out when the execution was aborted because of the deadline
+func (c *ContainerExecutor) watchDeadline(ctx context.Context, execution *testkube.Execution, timeout time.Duration) func() bool {
+	ctx = context.WithoutCancel(ctx)
+	watchCtx, cancel := context.WithCancel(ctx)
+	timedOut := make(chan *testkube.ExecutionResult, 1)
+	watched := *execution
+	go func() {
+		result, err := client.NewDeadlineWatcher(c, c.clock).Watch(watchCtx, &watched, timeout)
+		if err != nil {
+			c.log.Errorw("aborting timed out execution error", "executionID", execution.Id, "error", err)
+		}
+		timedOut <- result
+	}()
+
+	return func() bool {
+		cancel()
+		deadline := <-timedOut
+		if deadline == nil || !deadline.IsTimeout() {
+			return false
+		}
+
+		execution.ExecutionResult.Timeout()
+		execution.ExecutionResult.ErrorMessage = deadline.ErrorMessage
+		if err := c.repository.UpdateResult(ctx, execution.Id, *execution); err != nil {
+			c.log.Errorw("Update execution result error", "error", err)
+		}
+		return true
+	}
+}
+
 // createJob creates new Kubernetes job based on execution and execute options
+// DryRun validates execution and renders job and persistent volume claim it would create, nothing is created
+func (c *ContainerExecutor) DryRun(ctx context.Context, execution *testkube.Execution, options client.ExecuteOptions) (*client.DryRunResult, error) {
+	if err := c.validate(ctx, *execution, options); err != nil {
+		return nil, err
+	}
+
+	_, pvcSpec, jobSpec, err := c.renderJob(*execution, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &client.DryRunResult{}
+	if pvcSpec != nil {
+		result.Objects = append(result.Objects, pvcSpec)
+	}
+	result.Objects = append(result.Objects, jobSpec)
+
+	return result, nil
+}
+
+// validate checks execute options against the cluster
+func (c *ContainerExecutor) validate(ctx context.Context, execution testkube.Execution, options client.ExecuteOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	if err := client.ValidateNamespace(ctx, c.clientSet, execution.TestNamespace); err != nil {
+		return err
+	}
+
+	if err := client.ValidateSecretNamespaces(execution, options); err != nil {
+		return err
+	}
+
+	return client.ValidatePodReferences(ctx, c.clientSet, execution.TestNamespace, options)
+}
+
 func (c *ContainerExecutor) createJob(ctx context.Context, execution testkube.Execution, options client.ExecuteOptions) (*JobOptions, error) {
-	jobsClient := c.clientSet.BatchV1().Jobs(execution.TestNamespace)
+	jobOptions, pvcSpec, jobSpec, err := c.renderJob(execution, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if pvcSpec != nil {
+		pvcsClient := c.clientSet.CoreV1().PersistentVolumeClaims(execution.TestNamespace)
+		if _, err = pvcsClient.Create(ctx, pvcSpec, metav1.CreateOptions{}); err != nil {
+			return nil, err
+		}
+	}
 
+	_, err = c.clientSet.BatchV1().Jobs(execution.TestNamespace).Create(ctx, jobSpec, metav1.CreateOptions{})
+	return jobOptions, err
+}
+
+// renderJob renders executor job spec and persistent volume claim spec when artifacts need one
+func (c *ContainerExecutor) renderJob(execution testkube.Execution, options client.ExecuteOptions) (*JobOptions, *corev1.PersistentVolumeClaim, *batchv1.Job, error) {
 	// Fallback to one-time inspector when non-default namespace is needed
 	inspector := c.imageInspector
 	if len(options.ImagePullSecretNames) > 0 && options.Namespace != "" && execution.TestNamespace != options.Namespace {
 		secretClient, err := secret.NewClient(options.Namespace)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to build secrets client")
+			return nil, nil, nil, errors.Wrap(err, "failed to build secrets client")
 		}
 		inspector = imageinspector.NewInspector(c.registry, imageinspector.NewSkopeoFetcher(), imageinspector.NewSecretFetcher(secretClient))
 	}
@@ -316,32 +452,26 @@ func (c *ContainerExecutor) createJob(ctx context.Context, execution testkube.Ex
 	jobOptions, err := NewJobOptions(c.log, c.templatesClient, c.images, c.templates, inspector,
 		c.serviceAccountNames, c.registry, c.clusterID, c.apiURI, execution, options, c.natsURI, c.debug)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
+	var pvcSpec *corev1.PersistentVolumeClaim
 	if jobOptions.ArtifactRequest != nil &&
 		jobOptions.ArtifactRequest.StorageClassName != "" {
 		c.log.Debug("creating persistent volume claim with options", "options", jobOptions)
-		pvcsClient := c.clientSet.CoreV1().PersistentVolumeClaims(execution.TestNamespace)
-		pvcSpec, err := client.NewPersistentVolumeClaimSpec(c.log, NewPVCOptionsFromJobOptions(*jobOptions))
-		if err != nil {
-			return nil, err
-		}
-
-		_, err = pvcsClient.Create(ctx, pvcSpec, metav1.CreateOptions{})
-		if err != nil {
-			return nil, err
+		if pvcSpec, err = client.NewPersistentVolumeClaimSpec(c.log, NewPVCOptionsFromJobOptions(*jobOptions)); err != nil {
+			return nil, nil, nil, err
 		}
 	}
 
 	c.log.Debug("creating executor job with options", "options", jobOptions)
 	jobSpec, err := NewExecutorJobSpec(c.log, jobOptions)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
+	jobSpec.OwnerReferences = append(jobSpec.OwnerReferences, client.TestOwnerReferences(c.testsClient, execution)...)
 
-	_, err = jobsClient.Create(ctx, jobSpec, metav1.CreateOptions{})
-	return jobOptions, err
+	return jobOptions, pvcSpec, jobSpec, nil
 }
 
 func (c *ContainerExecutor) cleanPVCVolume(ctx context.Context, execution *testkube.Execution) error {
@@ -378,9 +508,9 @@ func (c *ContainerExecutor) updateResultsFromPod(
 
 	// wait for pod
 	l.Debug("poll immediate waiting for executor pod")
-	if err = wait.PollUntilContextTimeout(ctx, pollInterval, c.podStartTimeout, true, executor.IsPodLoggable(c.clientSet, executorPod.Name, execution.TestNamespace)); err != nil {
+	if err = c.podWatcher().PollUntil(ctx, c.podStartTimeout, executor.IsPodLoggable(c.clientSet, executorPod.Name, execution.TestNamespace)); err != nil {
 		l.Errorw("waiting for executor pod started error", "error", err)
-	} else if err = wait.PollUntilContextTimeout(ctx, pollInterval, pollTimeout, true, executor.IsPodReady(c.clientSet, executorPod.Name, execution.TestNamespace)); err != nil {
+	} else if err = c.podWatcher().PollUntil(ctx, pollTimeout, executor.IsPodReady(c.clientSet, executorPod.Name, execution.TestNamespace)); err != nil {
 		// continue on poll err and try to get logs later
 		l.Errorw("waiting for executor pod complete error", "error", err)
 	}
@@ -421,9 +551,9 @@ func (c *ContainerExecutor) updateResultsFromPod(
 		for _, scraperPod := range scraperPods.Items {
 			if scraperPod.Status.Phase != corev1.PodRunning && scraperPod.Labels["job-name"] == scraperPodName {
 				l.Debug("poll immediate waiting for scraper pod to succeed")
-				if err = wait.PollUntilContextTimeout(ctx, pollInterval, c.podStartTimeout, true, executor.IsPodLoggable(c.clientSet, scraperPod.Name, execution.TestNamespace)); err != nil {
+				if err = c.podWatcher().PollUntil(ctx, c.podStartTimeout, executor.IsPodLoggable(c.clientSet, scraperPod.Name, execution.TestNamespace)); err != nil {
 					l.Errorw("waiting for scraper pod started error", "error", err)
-				} else if err = wait.PollUntilContextTimeout(ctx, pollInterval, pollTimeout, true, executor.IsPodReady(c.clientSet, scraperPod.Name, execution.TestNamespace)); err != nil {
+				} else if err = c.podWatcher().PollUntil(ctx, pollTimeout, executor.IsPodReady(c.clientSet, scraperPod.Name, execution.TestNamespace)); err != nil {
 					// continue on poll err and try to get logs later
 					l.Errorw("waiting for scraper pod complete error", "error", err)
 				}
@@ -497,6 +627,10 @@ func (c *ContainerExecutor) updateResultsFromPod(
 		execution.ExecutionResult.Output = output
 	}
 
+	if err = client.ServiceFailure(*latestExecutorPod); err != nil {
+		execution.ExecutionResult.Err(err)
+	}
+
 	if execution.ExecutionResult.IsFailed() {
 		errorMessage := execution.ExecutionResult.ErrorMessage
 		if errorMessage == "" {
@@ -659,6 +793,9 @@ func NewJobOptionsFromExecutionOptions(options client.ExecuteOptions) *JobOption
 		}
 	}
 
+	workingDir = options.ResolveWorkingDir(workingDir, repoPath)
+	command, args := options.CommandAndArgs(options.Request.Command, options.Request.Args)
+
 	supportArtifacts := false
 	for _, feature := range options.ExecutorSpec.Features {
 		if feature == executorv1.FeatureArtifacts {
@@ -674,15 +811,6 @@ func NewJobOptionsFromExecutionOptions(options client.ExecuteOptions) *JobOption
 		jobDelaySeconds = jobArtifactDelaySeconds
 	}
 
-	labels := map[string]string{
-		testkube.TestLabelTestType: utils.SanitizeName(options.TestSpec.Type_),
-		testkube.TestLabelExecutor: options.ExecutorName,
-		testkube.TestLabelTestName: options.TestName,
-	}
-	for key, value := range options.Labels {
-		labels[key] = value
-	}
-
 	contextType := ""
 	contextData := ""
 	if options.Request.RunningContext != nil {
@@ -693,8 +821,8 @@ func NewJobOptionsFromExecutionOptions(options client.ExecuteOptions) *JobOption
 	return &JobOptions{
 		Image:                     image,
 		ImagePullSecrets:          options.ImagePullSecretNames,
-		Args:                      options.Request.Args,
-		Command:                   options.Request.Command,
+		Args:                      args,
+		Command:                   command,
 		WorkingDir:                workingDir,
 		TestName:                  options.TestName,
 		Namespace:                 options.Namespace,
@@ -707,7 +835,7 @@ func NewJobOptionsFromExecutionOptions(options client.ExecuteOptions) *JobOption
 		RunnerCustomCASecret:      options.RunnerCustomCASecret,
 		CertificateSecret:         options.CertificateSecret,
 		AgentAPITLSSecret:         options.AgentAPITLSSecret,
-		ActiveDeadlineSeconds:     options.Request.ActiveDeadlineSeconds,
+		ActiveDeadlineSeconds:     options.ActiveDeadlineSeconds(),
 		ArtifactRequest:           artifactRequest,
 		DelaySeconds:              jobDelaySeconds,
 		JobTemplate:               options.ExecutorSpec.JobTemplate,
@@ -716,17 +844,65 @@ func NewJobOptionsFromExecutionOptions(options client.ExecuteOptions) *JobOption
 		PvcTemplateExtensions:     options.Request.PvcTemplate,
 		EnvConfigMaps:             options.Request.EnvConfigMaps,
 		EnvSecrets:                options.Request.EnvSecrets,
-		Labels:                    labels,
+		Labels:                    client.ExecutionLabels(options),
+		Annotations:               options.Annotations,
 		ExecutionNumber:           options.Request.Number,
 		ContextType:               contextType,
 		ContextData:               contextData,
 		Features:                  options.Features,
+		Resources:                 options.Resources,
+		NodeSelector:              options.NodeSelector,
+		Tolerations:               options.Tolerations,
+		Affinity:                  options.Affinity,
+		Services:                  options.Services,
 	}
 }
 
-// Abort K8sJob aborts K8S by job name
+// Abort deletes execution job and marks execution as aborted,
+// already finished execution is left untouched, its terminal result is returned with AlreadyFinishedError
 func (c *ContainerExecutor) Abort(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
-	return executor.AbortJob(ctx, c.clientSet, execution.TestNamespace, execution.Id)
+	if result, ok := c.finishedResult(ctx, *execution); ok {
+		c.log.Debugw("execution already finished, nothing to abort", "executionID", execution.Id, "status", result.Status)
+		return result, &client.AlreadyFinishedError{ExecutionID: execution.Id, Result: result}
+	}
+
+	c.aborts.Cancel(execution.Id)
+	result, err := executor.AbortJob(ctx, c.clientSet, execution.TestNamespace, execution.Id)
+	if err != nil {
+		return result, err
+	}
+
+	// execution could finish naturally while the job was being deleted
+	if finished, ok := c.finishedResult(ctx, *execution); ok {
+		c.log.Debugw("execution finished before abort", "executionID", execution.Id, "status", finished.Status)
+		return finished, &client.AlreadyFinishedError{ExecutionID: execution.Id, Result: finished}
+	}
+
+	if result.IsAborted() {
+		result = client.NewAbortedExecutionResult(ctx, time.Now())
+	}
+
+	execution.ExecutionResult = result
+	c.stopExecution(ctx, execution, result, false)
+	if err = c.repository.UpdateResult(ctx, execution.Id, *execution); err != nil {
+		c.log.Errorw("Update execution result error", "error", err)
+	}
+
+	return result, nil
+}
+
+// finishedResult returns terminal result of the execution, stored execution is checked as it can be more recent
+func (c *ContainerExecutor) finishedResult(ctx context.Context, execution testkube.Execution) (*testkube.ExecutionResult, bool) {
+	if result, ok := client.FinishedResult(execution); ok {
+		return result, true
+	}
+
+	saved, err := c.repository.Get(ctx, execution.Id)
+	if err != nil {
+		return nil, false
+	}
+
+	return client.FinishedResult(saved)
 }
 
 func NewPVCOptionsFromJobOptions(options JobOptions) client.PVCOptions {
@@ -736,5 +912,7 @@ func NewPVCOptionsFromJobOptions(options JobOptions) client.PVCOptions {
 		PvcTemplate:           options.PvcTemplate,
 		PvcTemplateExtensions: options.PvcTemplateExtensions,
 		ArtifactRequest:       options.ArtifactRequest,
+		Labels:                options.Labels,
+		Annotations:           options.Annotations,
 	}
 }
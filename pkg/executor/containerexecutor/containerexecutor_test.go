@@ -2,16 +2,23 @@ package containerexecutor
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
 	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
 	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
@@ -40,10 +47,11 @@ func TestExecuteAsync(t *testing.T) {
 		testsClient:         FakeTestsClient{},
 		executorsClient:     FakeExecutorsClient{},
 		serviceAccountNames: map[string]string{"": ""},
+		aborts:              client.NewAbortRegistry(),
 	}
 
 	execution := &testkube.Execution{Id: "1"}
-	options := client.ExecuteOptions{}
+	options := client.ExecuteOptions{ID: "1", TestName: "test"}
 	res, err := ce.Execute(ctx, execution, options)
 	assert.NoError(t, err)
 
@@ -66,10 +74,13 @@ func TestExecuteSync(t *testing.T) {
 		testsClient:         FakeTestsClient{},
 		executorsClient:     FakeExecutorsClient{},
 		serviceAccountNames: map[string]string{"default": ""},
+		aborts:              client.NewAbortRegistry(),
 	}
 
 	execution := &testkube.Execution{Id: "1", TestNamespace: "default"}
 	options := client.ExecuteOptions{
+		ID:                   "1",
+		TestName:             "test",
 		ImagePullSecretNames: []string{"secret-name1"},
 		Sync:                 true,
 	}
@@ -78,6 +89,229 @@ func TestExecuteSync(t *testing.T) {
 	assert.Equal(t, testkube.PASSED_ExecutionStatus, *res.Status)
 }
 
+func TestExecuteMissingPodReferences(t *testing.T) {
+	t.Parallel()
+
+	ce := ContainerExecutor{
+		clientSet:           getFakeClient("1"),
+		log:                 logger(),
+		repository:          FakeResultRepository{},
+		metrics:             FakeExecutionMetric{},
+		emitter:             FakeEmitter{},
+		configMap:           FakeConfigRepository{},
+		testsClient:         FakeTestsClient{},
+		executorsClient:     FakeExecutorsClient{},
+		serviceAccountNames: map[string]string{"default": ""},
+		aborts:              client.NewAbortRegistry(),
+	}
+
+	execution := &testkube.Execution{Id: "1", TestNamespace: "default"}
+	options := client.ExecuteOptions{
+		ID:                   "1",
+		TestName:             "test",
+		ServiceAccountName:   "gcs-writer",
+		ImagePullSecretNames: []string{"secret-name1", "private-registry"},
+		Sync:                 true,
+	}
+	res, err := ce.Execute(ctx, execution, options)
+	assert.EqualError(t, err, "service account gcs-writer does not exist in execution namespace default\n"+
+		"image pull secret private-registry does not exist in execution namespace default")
+	assert.Equal(t, testkube.FAILED_ExecutionStatus, *res.Status)
+
+	jobs, err := ce.clientSet.BatchV1().Jobs("default").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, jobs.Items)
+}
+
+func TestDryRunMatchesCreatedJob(t *testing.T) {
+	t.Parallel()
+
+	ce := ContainerExecutor{
+		clientSet:           getFakeClient("1"),
+		log:                 logger(),
+		repository:          FakeResultRepository{},
+		metrics:             FakeExecutionMetric{},
+		emitter:             FakeEmitter{},
+		configMap:           FakeConfigRepository{},
+		testsClient:         FakeTestsClient{},
+		executorsClient:     FakeExecutorsClient{},
+		serviceAccountNames: map[string]string{"default": ""},
+		aborts:              client.NewAbortRegistry(),
+	}
+
+	options := client.ExecuteOptions{
+		ID:                   "1",
+		TestName:             "test",
+		ImagePullSecretNames: []string{"secret-name1"},
+		Command:              []string{"run"},
+		Args:                 []string{"--smoke"},
+		Sync:                 true,
+	}
+	dryRun, err := ce.DryRun(ctx, &testkube.Execution{Id: "1", TestNamespace: "default"}, options)
+	assert.NoError(t, err)
+
+	jobs, err := ce.clientSet.BatchV1().Jobs("default").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, jobs.Items)
+
+	_, err = ce.Execute(ctx, &testkube.Execution{Id: "1", TestNamespace: "default"}, options)
+	assert.NoError(t, err)
+
+	created, err := ce.clientSet.BatchV1().Jobs("default").Get(ctx, "1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []runtime.Object{created}, dryRun.Objects)
+
+	manifest, err := dryRun.YAML()
+	assert.NoError(t, err)
+	assert.Contains(t, manifest, "name: \"1\"")
+	assert.Contains(t, manifest, "- --smoke")
+}
+
+func TestDryRunValidates(t *testing.T) {
+	t.Parallel()
+
+	ce := ContainerExecutor{
+		clientSet:           getFakeClient("1"),
+		log:                 logger(),
+		serviceAccountNames: map[string]string{"default": ""},
+	}
+
+	_, err := ce.DryRun(ctx, &testkube.Execution{Id: "1", TestNamespace: "default"}, client.ExecuteOptions{
+		ID:                   "1",
+		TestName:             "test",
+		ImagePullSecretNames: []string{"private-registry"},
+	})
+	assert.EqualError(t, err, "image pull secret private-registry does not exist in execution namespace default")
+}
+
+func TestDryRunValidationMatchesExecute(t *testing.T) {
+	t.Parallel()
+
+	invalid := map[string]client.ExecuteOptions{
+		"resource quantity": {ID: "1", TestName: "test", Resources: &client.Resources{Requests: client.ResourceList{CPU: "lots"}}},
+		"label name":        {ID: "1", TestName: "test", Labels: map[string]string{strings.Repeat("team", 20): "qa"}},
+	}
+	for name, options := range invalid {
+		ce := ContainerExecutor{
+			clientSet:           getFakeClient("1"),
+			log:                 logger(),
+			repository:          FakeResultRepository{},
+			metrics:             FakeExecutionMetric{},
+			emitter:             FakeEmitter{},
+			configMap:           FakeConfigRepository{},
+			testsClient:         FakeTestsClient{},
+			executorsClient:     FakeExecutorsClient{},
+			serviceAccountNames: map[string]string{"default": ""},
+			aborts:              client.NewAbortRegistry(),
+		}
+
+		_, dryRunErr := ce.DryRun(ctx, &testkube.Execution{Id: "1", TestNamespace: "default"}, options)
+		assert.Error(t, dryRunErr, name)
+
+		_, err := ce.Execute(ctx, &testkube.Execution{Id: "1", TestNamespace: "default"}, options)
+		if assert.Error(t, err, name) && dryRunErr != nil {
+			assert.Equal(t, err.Error(), dryRunErr.Error(), name)
+		}
+
+		jobs, err := ce.clientSet.BatchV1().Jobs("default").List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, jobs.Items, name)
+	}
+}
+
+func newAbortTestExecutor(repository result.Repository) ContainerExecutor {
+	clientSet := getFakeClient("1")
+	_ = clientSet.Tracker().Add(&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "1", Namespace: "default"}})
+
+	return ContainerExecutor{
+		clientSet:   clientSet,
+		log:         logger(),
+		repository:  repository,
+		metrics:     FakeExecutionMetric{},
+		emitter:     FakeEmitter{},
+		configMap:   FakeConfigRepository{},
+		testsClient: FakeTestsClient{},
+		aborts:      client.NewAbortRegistry(),
+	}
+}
+
+func TestAbort(t *testing.T) {
+	t.Parallel()
+
+	ce := newAbortTestExecutor(FakeResultRepository{})
+	execution := &testkube.Execution{Id: "1", TestNamespace: "default", ExecutionResult: testkube.NewRunningExecutionResult()}
+
+	res, err := ce.Abort(client.WithAbortedBy(ctx, "api"), execution)
+	assert.NoError(t, err)
+	assert.True(t, res.IsAborted())
+	assert.False(t, res.IsFailed())
+	assert.Contains(t, res.ErrorMessage, "execution aborted by api at ")
+	assert.Equal(t, res, execution.ExecutionResult)
+	assert.False(t, execution.EndTime.IsZero())
+
+	_, err = ce.clientSet.BatchV1().Jobs("default").Get(ctx, "1", metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestAbortTimedOut(t *testing.T) {
+	t.Parallel()
+
+	ce := newAbortTestExecutor(FakeResultRepository{})
+	execution := &testkube.Execution{Id: "1", TestNamespace: "default", ExecutionResult: testkube.NewRunningExecutionResult()}
+
+	res, err := ce.Abort(client.WithTimedOut(ctx, time.Minute), execution)
+	assert.NoError(t, err)
+	assert.True(t, res.IsTimeout())
+	assert.Equal(t, "execution timed out after 1m0s", res.ErrorMessage)
+	assert.Equal(t, res, execution.ExecutionResult)
+
+	_, err = ce.clientSet.BatchV1().Jobs("default").Get(ctx, "1", metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestAbortFinishedExecution(t *testing.T) {
+	t.Parallel()
+
+	ce := newAbortTestExecutor(FakeResultRepository{})
+	finished := &testkube.ExecutionResult{Status: testkube.ExecutionStatusPassed, Output: "ok"}
+	execution := &testkube.Execution{Id: "1", TestNamespace: "default", ExecutionResult: finished}
+
+	res, err := ce.Abort(ctx, execution)
+	assert.ErrorIs(t, err, client.ErrAlreadyFinished)
+	assert.Same(t, finished, res)
+
+	_, err = ce.clientSet.BatchV1().Jobs("default").Get(ctx, "1", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestAbortRaceWithCompletion(t *testing.T) {
+	t.Parallel()
+
+	// execution finishes naturally while its job is being deleted
+	repository := &completingResultRepository{}
+	ce := newAbortTestExecutor(repository)
+	execution := &testkube.Execution{Id: "1", TestNamespace: "default", ExecutionResult: testkube.NewRunningExecutionResult()}
+
+	res, err := ce.Abort(ctx, execution)
+	assert.ErrorIs(t, err, client.ErrAlreadyFinished)
+	assert.True(t, res.IsPassed())
+	assert.Equal(t, 2, repository.gets)
+}
+
+type completingResultRepository struct {
+	FakeResultRepository
+	gets int
+}
+
+func (r *completingResultRepository) Get(ctx context.Context, id string) (testkube.Execution, error) {
+	r.gets++
+	if r.gets == 1 {
+		return testkube.Execution{Id: id, ExecutionResult: testkube.NewRunningExecutionResult()}, nil
+	}
+
+	return testkube.Execution{Id: id, ExecutionResult: &testkube.ExecutionResult{Status: testkube.ExecutionStatusPassed}}, nil
+}
+
 func TestNewExecutorJobSpecEmptyArgs(t *testing.T) {
 	t.Parallel()
 
@@ -177,6 +411,131 @@ func TestNewExecutorJobSpecWithArgs(t *testing.T) {
 	assert.ElementsMatch(t, wantEnvs, spec.Spec.Template.Spec.Containers[0].Env)
 }
 
+func TestNewExecutorJobSpecWithResources(t *testing.T) {
+	t.Parallel()
+
+	jobOptions := &JobOptions{
+		Name:        "name",
+		Namespace:   "namespace",
+		InitImage:   "kubeshop/testkube-init-executor:0.7.10",
+		Image:       "curl",
+		JobTemplate: defaultJobTemplate,
+		Command:     []string{"/bin/curl"},
+		Args:        []string{"-v", "https://testkube.kubeshop.io"},
+		Resources: &client.Resources{
+			Requests: client.ResourceList{CPU: "100m", Memory: "64Mi"},
+			Limits:   client.ResourceList{Memory: "128Mi"},
+		},
+		Features: featureflags.FeatureFlags{},
+	}
+	spec, err := NewExecutorJobSpec(logger(), jobOptions)
+	assert.NoError(t, err)
+
+	wantResources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+	}
+	for _, container := range spec.Spec.Template.Spec.Containers {
+		if container.Name == "name" {
+			assert.Equal(t, wantResources, container.Resources)
+		}
+	}
+}
+
+func TestNewExecutorJobSpecWithScheduling(t *testing.T) {
+	t.Parallel()
+
+	affinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "gpu", Operator: corev1.NodeSelectorOpIn, Values: []string{"a100"}}},
+		}}},
+	}}
+	jobOptions := &JobOptions{
+		Name:        "name",
+		Namespace:   "namespace",
+		InitImage:   "kubeshop/testkube-init-executor:0.7.10",
+		Image:       "curl",
+		JobTemplate: defaultJobTemplate,
+		// executor defaults rendered into the job template
+		JobTemplateExtensions: `spec:
+  template:
+    spec:
+      nodeSelector:
+        kubernetes.io/os: linux
+        pool: tests
+      tolerations:
+      - key: dedicated
+        operator: Equal
+        value: tests
+        effect: NoSchedule
+`,
+		Command:      []string{"/bin/curl"},
+		Args:         []string{"-v", "https://testkube.kubeshop.io"},
+		NodeSelector: map[string]string{"kubernetes.io/os": "windows"},
+		Tolerations: []corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists},
+		},
+		Affinity: affinity,
+		Features: featureflags.FeatureFlags{},
+	}
+	spec, err := NewExecutorJobSpec(logger(), jobOptions)
+	assert.NoError(t, err)
+
+	podSpec := spec.Spec.Template.Spec
+	assert.Equal(t, map[string]string{"kubernetes.io/os": "windows", "pool": "tests"}, podSpec.NodeSelector)
+	assert.Equal(t, []corev1.Toleration{
+		{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists},
+	}, podSpec.Tolerations)
+	assert.Equal(t, affinity, podSpec.Affinity)
+}
+
+func TestNewExecutorJobSpecWithServices(t *testing.T) {
+	t.Parallel()
+
+	jobOptions := &JobOptions{
+		Name:        "name",
+		Namespace:   "namespace",
+		InitImage:   "kubeshop/testkube-init-executor:0.7.10",
+		Image:       "curl",
+		JobTemplate: defaultJobTemplate,
+		Command:     []string{"/bin/curl"},
+		Args:        []string{"-v", "http://localhost:8080"},
+		Services: []client.Service{
+			{Name: "db", Image: "postgres:16", Env: map[string]string{"POSTGRES_PASSWORD": "test"}, Ports: []int32{5432}, ReadinessCommand: []string{"pg_isready"}},
+			{Name: "mock", Image: "wiremock/wiremock", Ports: []int32{8080}},
+		},
+		Features: featureflags.FeatureFlags{},
+	}
+	spec, err := NewExecutorJobSpec(logger(), jobOptions)
+	assert.NoError(t, err)
+
+	// services are sidecar init containers started after the template init containers, before the test container
+	podSpec := spec.Spec.Template.Spec
+	names := make([]string, len(podSpec.InitContainers))
+	for i, container := range podSpec.InitContainers {
+		names[i] = container.Name
+	}
+	assert.Equal(t, []string{"name-init", "service-db", "service-mock"}, names)
+	assert.Equal(t, "name", podSpec.Containers[0].Name)
+
+	always := corev1.ContainerRestartPolicyAlways
+	db := podSpec.InitContainers[1]
+	assert.Equal(t, "postgres:16", db.Image)
+	assert.Equal(t, &always, db.RestartPolicy)
+	// runner variables are not passed to services
+	assert.Equal(t, []corev1.EnvVar{{Name: "POSTGRES_PASSWORD", Value: "test"}}, db.Env)
+	assert.Equal(t, []corev1.ContainerPort{{ContainerPort: 5432, Protocol: corev1.ProtocolTCP}}, db.Ports)
+	assert.Equal(t, []string{"pg_isready"}, db.StartupProbe.Exec.Command)
+	assert.Equal(t, intstr.FromInt32(8080), podSpec.InitContainers[2].StartupProbe.TCPSocket.Port)
+}
+
 func TestNewExecutorJobSpecWithoutInitImage(t *testing.T) {
 	t.Parallel()
 
@@ -338,6 +697,91 @@ func TestNewExecutorJobSpecWithoutWorkingDir(t *testing.T) {
 	assert.Empty(t, spec.Spec.Template.Spec.Containers[0].WorkingDir)
 }
 
+func TestNewExecutorJobSpecWithCommandOverrides(t *testing.T) {
+	t.Parallel()
+
+	options := client.ExecuteOptions{
+		Request: testkube.ExecutionRequest{
+			Command: []string{"npx", "cypress", "run"},
+			Args:    []string{"--browser", "chrome"},
+		},
+		Args:       []string{"--grep", "smoke test", `--env=name="O'Brien"`, "; rm -rf / #"},
+		WorkingDir: "e2e",
+	}
+	jobOptions := NewJobOptionsFromExecutionOptions(options)
+	jobOptions.Name = "name"
+	jobOptions.Namespace = "namespace"
+	jobOptions.JobTemplate = defaultJobTemplate
+
+	spec, err := NewExecutorJobSpec(logger(), jobOptions)
+	assert.NoError(t, err)
+
+	container := spec.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"npx", "cypress", "run"}, container.Command)
+	assert.Equal(t, []string{"--browser", "chrome", "--grep", "smoke test", `--env=name="O'Brien"`, "; rm -rf / #"}, container.Args)
+	assert.Equal(t, repoPath+"/e2e", container.WorkingDir)
+	// defaults passed in the request are not modified by quoting
+	assert.Equal(t, []string{"--browser", "chrome"}, options.Request.Args)
+
+	options.Command = []string{"/bin/sh"}
+	options.ArgsMode = testkube.ArgsModeTypeOverride
+	options.Args = []string{"-c", `echo "it's fine"`}
+	jobOptions = NewJobOptionsFromExecutionOptions(options)
+	jobOptions.Name = "name"
+	jobOptions.Namespace = "namespace"
+	jobOptions.JobTemplate = defaultJobTemplate
+
+	spec, err = NewExecutorJobSpec(logger(), jobOptions)
+	assert.NoError(t, err)
+
+	container = spec.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"/bin/sh"}, container.Command)
+	assert.Equal(t, []string{"-c", `echo "it's fine"`}, container.Args)
+}
+
+func TestNewExecutorJobSpecWithServiceAccountOverride(t *testing.T) {
+	t.Parallel()
+
+	newSpec := func(options client.ExecuteOptions) *batchv1.Job {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		options.TestSpec = testsv3.TestSpec{ExecutionRequest: &testsv3.ExecutionRequest{Image: "ubuntu"}}
+		jobOptions, err := NewJobOptions(
+			logger(),
+			templatesclientv1.NewMockInterface(mockCtrl),
+			executor.Images{},
+			executor.Templates{},
+			imageinspector.NewMockInspector(mockCtrl),
+			map[string]string{"namespace": "testkube-api-server-tests-job"},
+			"",
+			"",
+			"",
+			testkube.Execution{
+				Id:            "name",
+				TestName:      "name-test-1",
+				TestNamespace: "namespace",
+			},
+			options,
+			"",
+			false,
+		)
+		assert.NoError(t, err)
+
+		spec, err := NewExecutorJobSpec(logger(), jobOptions)
+		assert.NoError(t, err)
+		return spec
+	}
+
+	spec := newSpec(client.ExecuteOptions{})
+	assert.Equal(t, "testkube-api-server-tests-job", spec.Spec.Template.Spec.ServiceAccountName)
+	assert.Empty(t, spec.Spec.Template.Spec.ImagePullSecrets)
+
+	spec = newSpec(client.ExecuteOptions{ServiceAccountName: "gcs-writer", ImagePullSecretNames: []string{"private-registry"}})
+	assert.Equal(t, "gcs-writer", spec.Spec.Template.Spec.ServiceAccountName)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "private-registry"}}, spec.Spec.Template.Spec.ImagePullSecrets)
+}
+
 func logger() *zap.SugaredLogger {
 	atomicLevel := zap.NewAtomicLevel()
 	atomicLevel.SetLevel(zap.DebugLevel)
@@ -354,6 +798,11 @@ func logger() *zap.SugaredLogger {
 
 func getFakeClient(executionID string) *fake.Clientset {
 	initObjects := []runtime.Object{
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+		},
 		&corev1.Pod{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      executionID,
@@ -366,11 +815,24 @@ func getFakeClient(executionID string) *fake.Clientset {
 				Phase: corev1.PodSucceeded,
 			},
 		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "secret-name1",
+				Namespace: "default",
+			},
+		},
 	}
 	fakeClient := fake.NewSimpleClientset(initObjects...)
+	fakeClient.PrependReactor("create", "selfsubjectaccessreviews", allowAccessReview)
 	return fakeClient
 }
 
+func allowAccessReview(action k8stesting.Action) (bool, runtime.Object, error) {
+	review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+	review.Status.Allowed = true
+	return true, review, nil
+}
+
 type FakeExecutionMetric struct {
 }
 
@@ -48,13 +48,13 @@ func NewExecutorJobSpec(log *zap.SugaredLogger, options *JobOptions) (*batchv1.J
 	options.Jsn = strings.ReplaceAll(options.Jsn, "'", "''")
 	for i := range options.Command {
 		if options.Command[i] != "" {
-			options.Command[i] = fmt.Sprintf("%q", options.Command[i])
+			options.Command[i] = quoteTemplateValue(options.Command[i])
 		}
 	}
 
 	for i := range options.Args {
 		if options.Args[i] != "" {
-			options.Args[i] = fmt.Sprintf("%q", options.Args[i])
+			options.Args[i] = quoteTemplateValue(options.Args[i])
 		}
 	}
 
@@ -86,19 +86,8 @@ func NewExecutorJobSpec(log *zap.SugaredLogger, options *JobOptions) (*batchv1.J
 		return nil, fmt.Errorf("decoding executor job spec error: %w", err)
 	}
 
-	for key, value := range options.Labels {
-		if job.Labels == nil {
-			job.Labels = make(map[string]string)
-		}
-
-		job.Labels[key] = value
-
-		if job.Spec.Template.Labels == nil {
-			job.Spec.Template.Labels = make(map[string]string)
-		}
-
-		job.Spec.Template.Labels[key] = value
-	}
+	client.ApplyMetadata(&job.ObjectMeta, options.Labels, options.Annotations)
+	client.ApplyMetadata(&job.Spec.Template.ObjectMeta, options.Labels, options.Annotations)
 
 	envs := append(executor.RunnerEnvVars, corev1.EnvVar{Name: "RUNNER_CLUSTERID", Value: options.ClusterID})
 	if options.ArtifactRequest != nil && options.ArtifactRequest.StorageBucket != "" {
@@ -140,6 +129,21 @@ func NewExecutorJobSpec(log *zap.SugaredLogger, options *JobOptions) (*batchv1.J
 		job.Spec.Template.Spec.Containers[i].Env = append(job.Spec.Template.Spec.Containers[i].Env, envs...)
 	}
 
+	if options.Resources != nil {
+		for i := range job.Spec.Template.Spec.Containers {
+			if job.Spec.Template.Spec.Containers[i].Name != options.Name {
+				continue
+			}
+
+			if err := options.Resources.ApplyTo(&job.Spec.Template.Spec.Containers[i]); err != nil {
+				return nil, fmt.Errorf("preparing execution container resources: %w", err)
+			}
+		}
+	}
+
+	client.ApplyScheduling(&job.Spec.Template.Spec, options.NodeSelector, options.Tolerations, options.Affinity)
+	client.ApplyServices(&job.Spec.Template.Spec, options.Services)
+
 	return &job, nil
 }
 
@@ -180,6 +184,9 @@ func NewScraperJobSpec(log *zap.SugaredLogger, options *JobOptions) (*batchv1.Jo
 		return nil, fmt.Errorf("decoding scraper job spec error: %w", err)
 	}
 
+	client.ApplyMetadata(&job.ObjectMeta, options.Labels, options.Annotations)
+	client.ApplyMetadata(&job.Spec.Template.ObjectMeta, options.Labels, options.Annotations)
+
 	envs := append(executor.RunnerEnvVars, corev1.EnvVar{Name: "RUNNER_CLUSTERID", Value: options.ClusterID})
 	if options.ArtifactRequest != nil && options.ArtifactRequest.StorageBucket != "" {
 		envs = append(envs, corev1.EnvVar{Name: "RUNNER_BUCKET", Value: options.ArtifactRequest.StorageBucket})
@@ -229,6 +236,19 @@ func NewJobOptions(log *zap.SugaredLogger, templatesClient templatesv1.Interface
 		return nil, err
 	}
 
+	if options.Resources != nil {
+		resources, err := options.Resources.Resolve(client.NewExecutionMachine(execution))
+		if err != nil {
+			return nil, err
+		}
+
+		if err = resources.Validate(); err != nil {
+			return nil, err
+		}
+
+		jobOptions.Resources = &resources
+	}
+
 	jobOptions.Name = execution.Id
 	jobOptions.Namespace = execution.TestNamespace
 	jobOptions.TestName = execution.TestName
@@ -312,8 +332,19 @@ func NewJobOptions(log *zap.SugaredLogger, templatesClient templatesv1.Interface
 	}
 
 	jobOptions.ServiceAccountName = serviceAccountName
+	if options.ServiceAccountName != "" {
+		jobOptions.ServiceAccountName = options.ServiceAccountName
+	}
+
 	jobOptions.Registry = registry
 	jobOptions.ClusterID = clusterID
 	jobOptions.APIURI = apiURI
 	return jobOptions, nil
 }
+
+// quoteTemplateValue quotes value as JSON string, which is a valid YAML double quoted scalar,
+// so spaces, quotes and special characters are passed to the container unchanged
+func quoteTemplateValue(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
@@ -0,0 +1,236 @@
+package scraper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/pkg/errors"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+// ArtifactRequest defines which files are collected as execution artifacts
+type ArtifactRequest struct {
+	// Patterns are doublestar glob patterns, same as in expression glob() function:
+	// relative patterns are resolved against working directory and patterns prefixed with ! exclude files
+	Patterns []string `json:"patterns"`
+	// StoragePrefix is prepended to artifact names in the storage
+	StoragePrefix string `json:"storagePrefix,omitempty"`
+	// MaxSize is a maximum total size of collected artifacts in bytes, zero means no limit
+	MaxSize int64 `json:"maxSize,omitempty"`
+	// OnlyOnFailure collects artifacts only when execution didn't pass
+	OnlyOnFailure bool `json:"onlyOnFailure,omitempty"`
+}
+
+// Validate checks artifact patterns and size limit, all found problems are returned
+func (r ArtifactRequest) Validate() (errs []error) {
+	included := 0
+	for _, pattern := range r.Patterns {
+		if !strings.HasPrefix(pattern, "!") {
+			included++
+		}
+
+		if !doublestar.ValidatePattern(strings.TrimPrefix(pattern, "!")) {
+			errs = append(errs, fmt.Errorf("invalid artifact pattern %s", pattern))
+		}
+	}
+
+	if included == 0 {
+		errs = append(errs, errors.New("artifact request should have at least one including pattern"))
+	}
+
+	if r.MaxSize < 0 {
+		errs = append(errs, errors.New("artifact size limit can't be negative"))
+	}
+
+	return errs
+}
+
+// ShouldCollect checks if artifacts should be collected for the execution result
+func (r ArtifactRequest) ShouldCollect(result *testkube.ExecutionResult) bool {
+	if !r.OnlyOnFailure {
+		return true
+	}
+
+	return result == nil || result.Status == nil || !result.IsPassed()
+}
+
+// ArtifactCollection is an outcome of artifacts collection
+type ArtifactCollection struct {
+	// Artifacts are stored artifacts with their storage names, sizes and checksums
+	Artifacts []testkube.Artifact
+	// Warnings are reported for patterns matching nothing and files skipped due to size limit
+	Warnings []string
+}
+
+// ArtifactProcessFn stores a collected artifact
+type ArtifactProcessFn func(ctx context.Context, object *Object) error
+
+// CollectArtifacts walks the filesystem for files matching artifact patterns and passes them to process function,
+// files are collected in path order until size limit is reached, files not fitting the limit are skipped with a warning
+func CollectArtifacts(ctx context.Context, fsys fs.FS, workingDir string, request ArtifactRequest, process ArtifactProcessFn) (*ArtifactCollection, error) {
+	if errs := request.Validate(); len(errs) != 0 {
+		return nil, errors.Errorf("invalid artifact request: %v", errs)
+	}
+
+	if workingDir == "" {
+		workingDir = "/"
+	}
+
+	files, warnings, err := matchArtifacts(fsys, workingDir, request.Patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &ArtifactCollection{Warnings: warnings}
+	var total int64
+	for _, file := range files {
+		info, err := fs.Stat(fsys, strings.TrimLeft(file, "/"))
+		if err != nil {
+			return collection, errors.Wrapf(err, "reading artifact %s", file)
+		}
+
+		if request.MaxSize != 0 && total+info.Size() > request.MaxSize {
+			collection.Warnings = append(collection.Warnings,
+				fmt.Sprintf("artifact %s of %d bytes skipped, size limit of %d bytes reached", file, info.Size(), request.MaxSize))
+			continue
+		}
+
+		artifact, err := collectArtifact(ctx, fsys, file, artifactName(request.StoragePrefix, file, workingDir), info.Size(), process)
+		if err != nil {
+			return collection, err
+		}
+
+		total += info.Size()
+		collection.Artifacts = append(collection.Artifacts, artifact)
+	}
+
+	return collection, nil
+}
+
+func collectArtifact(ctx context.Context, fsys fs.FS, file, name string, size int64, process ArtifactProcessFn) (testkube.Artifact, error) {
+	checksum, err := fileChecksum(fsys, file)
+	if err != nil {
+		return testkube.Artifact{}, err
+	}
+
+	reader, err := fsys.Open(strings.TrimLeft(file, "/"))
+	if err != nil {
+		return testkube.Artifact{}, errors.Wrapf(err, "opening artifact %s", file)
+	}
+	defer reader.Close()
+
+	if err = process(ctx, &Object{Name: name, Size: size, Data: reader, DataType: DataTypeRaw}); err != nil {
+		return testkube.Artifact{}, errors.Wrapf(err, "storing artifact %s", file)
+	}
+
+	return testkube.Artifact{Name: name, Size: int32(size), Checksum: checksum}, nil
+}
+
+func fileChecksum(fsys fs.FS, file string) (string, error) {
+	reader, err := fsys.Open(strings.TrimLeft(file, "/"))
+	if err != nil {
+		return "", errors.Wrapf(err, "opening artifact %s", file)
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, reader); err != nil {
+		return "", errors.Wrapf(err, "reading artifact %s", file)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// matchArtifacts returns sorted absolute paths of files matching the patterns,
+// patterns matching no file are reported as warnings
+func matchArtifacts(fsys fs.FS, workingDir string, patterns []string) ([]string, []string, error) {
+	var excluded []string
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			excluded = append(excluded, artifactPath(pattern[1:], workingDir))
+		}
+	}
+
+	matched := make(map[string]struct{})
+	var warnings []string
+	for _, original := range patterns {
+		if strings.HasPrefix(original, "!") {
+			continue
+		}
+
+		pattern := artifactPath(original, workingDir)
+		root, _ := doublestar.SplitPattern(pattern)
+		root = strings.TrimLeft(root, "/")
+		if root == "" {
+			root = "."
+		}
+
+		found := false
+		err := fs.WalkDir(fsys, root, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+
+			filePath = "/" + filePath
+			if ok, _ := doublestar.PathMatch(pattern, filePath); !ok || matchesAny(excluded, filePath) {
+				return nil
+			}
+
+			found = true
+			matched[filePath] = struct{}{}
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, errors.Wrapf(err, "matching artifact pattern %s", pattern)
+		}
+
+		if !found {
+			warnings = append(warnings, fmt.Sprintf("artifact pattern %s matched no files", original))
+		}
+	}
+
+	files := make([]string, 0, len(matched))
+	for file := range matched {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	return files, warnings, nil
+}
+
+func matchesAny(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := doublestar.PathMatch(pattern, filePath); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func artifactPath(pattern, workingDir string) string {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(workingDir, pattern)
+	}
+
+	return filepath.ToSlash(filepath.Clean(pattern))
+}
+
+func artifactName(prefix, file, workingDir string) string {
+	name := strings.TrimLeft(file, "/")
+	if rel, err := filepath.Rel(workingDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+		name = filepath.ToSlash(rel)
+	}
+
+	return path.Join(prefix, name)
+}
@@ -0,0 +1,157 @@
+package scraper_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/scraper"
+)
+
+func artifactsFs() fstest.MapFS {
+	return fstest.MapFS{
+		"data/repo/reports/junit.xml":          {Data: []byte("<testsuites/>")},
+		"data/repo/reports/nested/junit.xml":   {Data: []byte("<testsuite/>")},
+		"data/repo/reports/skip/junit.xml":     {Data: []byte("<skipped/>")},
+		"data/repo/videos/login.mp4":           {Data: []byte("0123456789")},
+		"data/repo/videos/logout.mp4":          {Data: []byte("01234")},
+		"data/repo/node_modules/lib/junit.xml": {Data: []byte("<vendor/>")},
+		"tmp/screenshot.png":                   {Data: []byte("png")},
+	}
+}
+
+type storedArtifacts map[string]string
+
+func (s storedArtifacts) process(_ context.Context, object *scraper.Object) error {
+	data, err := io.ReadAll(object.Data)
+	s[object.Name] = string(data)
+	return err
+}
+
+func artifactNames(artifacts []testkube.Artifact) (names []string) {
+	for _, artifact := range artifacts {
+		names = append(names, artifact.Name)
+	}
+	return names
+}
+
+func TestCollectArtifacts_Patterns(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		patterns []string
+		names    []string
+	}{
+		"relative recursive pattern": {
+			patterns: []string{"reports/**/*.xml"},
+			names:    []string{"reports/junit.xml", "reports/nested/junit.xml", "reports/skip/junit.xml"},
+		},
+		"ignore pattern": {
+			patterns: []string{"**/junit.xml", "!reports/skip/**", "!node_modules/**"},
+			names:    []string{"reports/junit.xml", "reports/nested/junit.xml"},
+		},
+		"absolute pattern outside working directory": {
+			patterns: []string{"/tmp/*.png", "videos/log{in,out}.mp4"},
+			names:    []string{"videos/login.mp4", "videos/logout.mp4", "tmp/screenshot.png"},
+		},
+		"overlapping patterns": {
+			patterns: []string{"videos/*", "videos/login.*"},
+			names:    []string{"videos/login.mp4", "videos/logout.mp4"},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stored := storedArtifacts{}
+			collection, err := scraper.CollectArtifacts(context.Background(), artifactsFs(), "/data/repo",
+				scraper.ArtifactRequest{Patterns: tc.patterns}, stored.process)
+
+			require.NoError(t, err)
+			assert.Empty(t, collection.Warnings)
+			assert.Equal(t, tc.names, artifactNames(collection.Artifacts))
+			assert.Len(t, stored, len(tc.names))
+		})
+	}
+}
+
+func TestCollectArtifacts_Metadata(t *testing.T) {
+	t.Parallel()
+
+	stored := storedArtifacts{}
+	collection, err := scraper.CollectArtifacts(context.Background(), artifactsFs(), "/data/repo",
+		scraper.ArtifactRequest{Patterns: []string{"videos/login.mp4"}, StoragePrefix: "e2e/run-1"}, stored.process)
+
+	require.NoError(t, err)
+	assert.Equal(t, []testkube.Artifact{{
+		Name:     "e2e/run-1/videos/login.mp4",
+		Size:     10,
+		Checksum: "84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882",
+	}}, collection.Artifacts)
+	assert.Equal(t, storedArtifacts{"e2e/run-1/videos/login.mp4": "0123456789"}, stored)
+}
+
+func TestCollectArtifacts_NoMatchIsWarning(t *testing.T) {
+	t.Parallel()
+
+	collection, err := scraper.CollectArtifacts(context.Background(), artifactsFs(), "/data/repo",
+		scraper.ArtifactRequest{Patterns: []string{"reports/junit.xml", "coverage/**", "/missing/*.log"}}, storedArtifacts{}.process)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reports/junit.xml"}, artifactNames(collection.Artifacts))
+	assert.Equal(t, []string{
+		"artifact pattern coverage/** matched no files",
+		"artifact pattern /missing/*.log matched no files",
+	}, collection.Warnings)
+}
+
+func TestCollectArtifacts_SizeLimit(t *testing.T) {
+	t.Parallel()
+
+	stored := storedArtifacts{}
+	collection, err := scraper.CollectArtifacts(context.Background(), artifactsFs(), "/data/repo",
+		scraper.ArtifactRequest{Patterns: []string{"videos/*.mp4", "/tmp/*.png"}, MaxSize: 9}, stored.process)
+
+	require.NoError(t, err)
+	// files are taken in absolute path order, login.mp4 doesn't fit and smaller files still do
+	assert.Equal(t, []string{"videos/logout.mp4", "tmp/screenshot.png"}, artifactNames(collection.Artifacts))
+	assert.Equal(t, []string{"artifact /data/repo/videos/login.mp4 of 10 bytes skipped, size limit of 9 bytes reached"}, collection.Warnings)
+	assert.Len(t, stored, 2)
+
+	var total int32
+	for _, artifact := range collection.Artifacts {
+		total += artifact.Size
+	}
+	assert.LessOrEqual(t, total, int32(9))
+}
+
+func TestArtifactRequest_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, scraper.ArtifactRequest{Patterns: []string{"**/*.xml", "!vendor/**"}, MaxSize: 1024}.Validate())
+	assert.Len(t, scraper.ArtifactRequest{}.Validate(), 1)
+	assert.Len(t, scraper.ArtifactRequest{Patterns: []string{"!vendor/**"}}.Validate(), 1)
+	assert.Len(t, scraper.ArtifactRequest{Patterns: []string{"reports/[a-"}, MaxSize: -1}.Validate(), 2)
+
+	_, err := scraper.CollectArtifacts(context.Background(), artifactsFs(), "/", scraper.ArtifactRequest{}, storedArtifacts{}.process)
+	assert.Error(t, err)
+}
+
+func TestArtifactRequest_ShouldCollect(t *testing.T) {
+	t.Parallel()
+
+	passed := &testkube.ExecutionResult{Status: testkube.ExecutionStatusPassed}
+	failed := &testkube.ExecutionResult{Status: testkube.ExecutionStatusFailed}
+
+	assert.True(t, scraper.ArtifactRequest{}.ShouldCollect(passed))
+	assert.True(t, scraper.ArtifactRequest{}.ShouldCollect(failed))
+	assert.False(t, scraper.ArtifactRequest{OnlyOnFailure: true}.ShouldCollect(passed))
+	assert.True(t, scraper.ArtifactRequest{OnlyOnFailure: true}.ShouldCollect(failed))
+}
@@ -66,32 +66,40 @@ func GetScraper(ctx context.Context, params envs.Params, extractorType Extractor
 		return nil, errors.Errorf("unknown extractor type: %s", extractorType)
 	}
 
-	var err error
-	var loader scraper.Uploader
+	loader, err := GetUploader(ctx, params, uploaderType)
+	if err != nil {
+		return nil, err
+	}
+
+	var cdeventsClient cloudevents.Client
+	if params.CDEventsTarget != "" {
+		cdeventsClient, err = cloudevents.NewClientHTTP(cloudevents.WithTarget(params.CDEventsTarget))
+		if err != nil {
+			log.DefaultLogger.Warnf("failed to create cloud event client: %v", err)
+		}
+	}
+
+	return scraper.NewExtractLoadScraper(extractor, loader, cdeventsClient, params.ClusterID, params.DashboardURI), nil
+}
+
+// GetUploader creates artifact uploader of the provided type
+func GetUploader(ctx context.Context, params envs.Params, uploaderType UploaderType) (scraper.Uploader, error) {
 	switch uploaderType {
 	case MinIOUploader:
-		loader, err = getMinIOUploader(params)
+		loader, err := getMinIOUploader(params)
 		if err != nil {
 			return nil, errors.Wrap(err, "error creating minio uploader")
 		}
+		return loader, nil
 	case CloudUploader:
-		loader, err = getRemoteStorageUploader(ctx, params)
+		loader, err := getRemoteStorageUploader(ctx, params)
 		if err != nil {
 			return nil, errors.Wrap(err, "error creating remote storage uploader")
 		}
+		return loader, nil
 	default:
 		return nil, errors.Errorf("unknown uploader type: %s", uploaderType)
 	}
-
-	var cdeventsClient cloudevents.Client
-	if params.CDEventsTarget != "" {
-		cdeventsClient, err = cloudevents.NewClientHTTP(cloudevents.WithTarget(params.CDEventsTarget))
-		if err != nil {
-			log.DefaultLogger.Warnf("failed to create cloud event client: %v", err)
-		}
-	}
-
-	return scraper.NewExtractLoadScraper(extractor, loader, cdeventsClient, params.ClusterID, params.DashboardURI), nil
 }
 
 func getRemoteStorageUploader(ctx context.Context, params envs.Params) (uploader *cloudscraper.CloudUploader, err error) {
@@ -6,6 +6,7 @@ import (
 	"github.com/kubeshop/testkube/pkg/event/bus"
 	"github.com/kubeshop/testkube/pkg/repository/config"
 
+	executorv1 "github.com/kubeshop/testkube-operator/api/executor/v1"
 	executorsv1 "github.com/kubeshop/testkube-operator/pkg/client/executors/v1"
 	testsv3 "github.com/kubeshop/testkube-operator/pkg/client/tests/v3"
 	testsourcesv1 "github.com/kubeshop/testkube-operator/pkg/client/testsources/v1"
@@ -26,7 +27,7 @@ import (
 type Scheduler struct {
 	metrics                   v1.Metrics
 	executor                  client.Executor
-	containerExecutor         client.Executor
+	executors                 *client.ExecutorFactory
 	testResults               result.Repository
 	testsuiteResults          testresult.Repository
 	executorsClient           executorsv1.Interface
@@ -76,7 +77,7 @@ func NewScheduler(
 	return &Scheduler{
 		metrics:                   metrics,
 		executor:                  executor,
-		containerExecutor:         containerExecutor,
+		executors:                 client.NewExecutorFactory(executor).Register(executorv1.ExecutorTypeContainer, containerExecutor),
 		secretClient:              secretClient,
 		testResults:               executionResults,
 		testsuiteResults:          testExecutionResults,
@@ -99,6 +100,12 @@ func NewScheduler(
 	}
 }
 
+// WithExecutor registers executor client used for executors of given type
+func (s *Scheduler) WithExecutor(executorType executorv1.ExecutorType, executor client.Executor) *Scheduler {
+	s.executors.Register(executorType, executor)
+	return s
+}
+
 // WithSubscriptionChecker sets subscription checker for the Scheduler
 // This is used to check if Pro/Enterprise subscription is valid
 func (s *Scheduler) WithSubscriptionChecker(subscriptionChecker checktcl.SubscriptionChecker) *Scheduler {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
@@ -87,18 +88,28 @@ func (s *Scheduler) executeTest(ctx context.Context, test testkube.Test, request
 
 	options.ID = execution.Id
 
+	// expressions in the request are resolved with the execution metadata, before the execution is stored
+	if err = client.ResolveRequest(&execution, &options); err != nil {
+		return s.handleExecutionError(ctx, execution, "can't resolve execution request expressions: %w", err)
+	}
+
 	s.events.Notify(testkube.NewEventStartTest(&execution))
 
 	if err := s.createSecretsReferences(&execution, &options); err != nil {
 		return s.handleExecutionError(ctx, execution, "can't create secret variables `Secret` references: %w", err)
 	}
 
-	err = s.testResults.Insert(ctx, execution)
+	// the execution queued by the test trigger is replaced by the started one
+	if request.Id != "" && s.isQueuedExecution(ctx, request.Id) {
+		err = s.testResults.Update(ctx, execution)
+	} else {
+		err = s.testResults.Insert(ctx, execution)
+	}
 	if err != nil {
 		return s.handleExecutionError(ctx, execution, "can't create new test execution, can't insert into storage: %w", err)
 	}
 
-	s.logger.Infow("calling executor with options", "executionId", execution.Id, "options", options.Request)
+	s.logger.Infow("calling executor with options", "executionId", execution.Id, "options", options.Redacted().Request)
 
 	execution.Start()
 
@@ -130,6 +141,49 @@ func (s *Scheduler) executeTest(ctx context.Context, test testkube.Test, request
 	return execution, nil
 }
 
+// DryRunTest renders the test execution with the same execute options as a real run, and returns what the executor
+// would create or send; nothing is stored, and the secret holding the secret variables isn't created either,
+// so the rendered job references the secret the real execution would create
+func (s *Scheduler) DryRunTest(ctx context.Context, test testkube.Test, request testkube.ExecutionRequest) (*client.DryRunResult, error) {
+	if request.Name == "" && test.ExecutionRequest != nil && test.ExecutionRequest.Name != "" {
+		request.Name = test.ExecutionRequest.Name
+	}
+
+	// the execution number isn't reserved, so the dry run doesn't consume it
+	if request.Name == "" {
+		request.Name = fmt.Sprintf("%s-dry-run", test.Name)
+	}
+
+	secretUUID, err := s.testsClient.GetCurrentSecretUUID(test.Name)
+	if err != nil {
+		return nil, fmt.Errorf("can't get current secret uuid: %w", err)
+	}
+
+	request.TestSecretUUID = secretUUID
+	options, err := s.getExecuteOptions(test.Namespace, test.Name, request)
+	if err != nil {
+		return nil, fmt.Errorf("can't get execute options: %w", err)
+	}
+
+	execution, err := newExecutionFromExecutionOptions(s.subscriptionChecker, options)
+	if err != nil {
+		return nil, fmt.Errorf("can't get new execution: %w", err)
+	}
+
+	options.ID = execution.Id
+	if _, err = s.secretsReferences(&execution, &options); err != nil {
+		return nil, fmt.Errorf("can't get secret variables `Secret` references: %w", err)
+	}
+
+	return client.DryRun(ctx, s.getExecutor(options.TestName), &execution, options)
+}
+
+// isQueuedExecution checks if the execution with the requested id is stored already, waiting to be started
+func (s *Scheduler) isQueuedExecution(ctx context.Context, id string) bool {
+	execution, err := s.testResults.Get(ctx, id)
+	return err == nil && execution.Id == id && execution.IsQueued()
+}
+
 func (s *Scheduler) handleExecutionStart(ctx context.Context, execution testkube.Execution) {
 	// pass here all needed execution data to the log
 	if s.featureFlags.LogsV2 {
@@ -168,6 +222,10 @@ func (s *Scheduler) handleExecutionError(ctx context.Context, execution testkube
 
 func (s *Scheduler) startTestExecution(ctx context.Context, options client.ExecuteOptions, execution *testkube.Execution) (result *testkube.ExecutionResult, err error) {
 	executor := s.getExecutor(options.TestName)
+	if options.RetryPolicy != nil {
+		executor = client.NewRetryingExecutor(executor, s.testResults, nil)
+	}
+
 	return executor.Execute(ctx, execution, options)
 }
 
@@ -184,12 +242,7 @@ func (s *Scheduler) getExecutor(testName string) client.Executor {
 		return s.executor
 	}
 
-	switch executorCR.Spec.ExecutorType {
-	case containerType:
-		return s.containerExecutor
-	default:
-		return s.executor
-	}
+	return s.executors.Get(executorCR.Spec)
 }
 
 func (s *Scheduler) getNextExecutionNumber(testName string) int32 {
@@ -204,6 +257,28 @@ func (s *Scheduler) getNextExecutionNumber(testName string) int32 {
 
 // createSecretsReferences strips secrets from text and store it inside model as reference to secret
 func (s *Scheduler) createSecretsReferences(execution *testkube.Execution, options *client.ExecuteOptions) (err error) {
+	secrets, err := s.secretsReferences(execution, options)
+	if err != nil {
+		return err
+	}
+
+	labels := map[string]string{"executionID": execution.Id, "testName": execution.TestName}
+
+	if len(secrets) > 0 {
+		return s.secretClient.Create(
+			execution.Id+"-vars",
+			labels,
+			secrets,
+			execution.TestNamespace,
+		)
+	}
+
+	return nil
+}
+
+// secretsReferences replaces secrets in the execution and options with references to the execution secret,
+// it returns the secret values to be stored in it
+func (s *Scheduler) secretsReferences(execution *testkube.Execution, options *client.ExecuteOptions) (map[string]string, error) {
 	secrets := map[string]string{}
 	secretName := execution.Id + "-vars"
 
@@ -244,31 +319,21 @@ func (s *Scheduler) createSecretsReferences(execution *testkube.Execution, optio
 
 		data, err := s.secretClient.Get(secretRef.Name)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		value, ok := data[secretRef.Key]
 		if !ok {
-			return fmt.Errorf("secret key %s not found for secret %s", secretRef.Key, secretRef.Name)
+			return nil, fmt.Errorf("secret key %s not found for secret %s", secretRef.Key, secretRef.Name)
 		}
 
 		secrets[gitCredentialPrefix+secretRef.Key] = value
+		secretRef.Namespace = execution.TestNamespace
 		secretRef.Name = secretName
 		secretRef.Key = gitCredentialPrefix + secretRef.Key
 	}
 
-	labels := map[string]string{"executionID": execution.Id, "testName": execution.TestName}
-
-	if len(secrets) > 0 {
-		return s.secretClient.Create(
-			secretName,
-			labels,
-			secrets,
-			execution.TestNamespace,
-		)
-	}
-
-	return nil
+	return secrets, nil
 }
 
 func newExecutionFromExecutionOptions(subscriptionChecker checktcl.SubscriptionChecker, options client.ExecuteOptions) (testkube.Execution, error) {
@@ -305,6 +370,7 @@ func newExecutionFromExecutionOptions(subscriptionChecker checktcl.SubscriptionC
 	execution.DownloadArtifactExecutionIDs = options.Request.DownloadArtifactExecutionIDs
 	execution.DownloadArtifactTestNames = options.Request.DownloadArtifactTestNames
 	execution.SlavePodRequest = options.Request.SlavePodRequest
+	execution.FieldOrigins = client.MapFieldOriginsToAPI(options.FieldOrigins)
 
 	// Pro edition only (tcl protected code)
 	if schedulertcl.HasExecutionNamespace(&options.Request) {
@@ -345,109 +411,14 @@ func (s *Scheduler) getExecuteOptions(namespace, id string, request testkube.Exe
 	test := testsmapper.MapTestCRToAPI(*testCR)
 
 	request.Namespace = namespace
-	if test.ExecutionRequest != nil {
-		// Test variables lowest priority, then test suite, then test suite execution / test execution
-		request.Variables = mergeVariables(test.ExecutionRequest.Variables, request.Variables)
-
-		request.Envs = mergeEnvs(request.Envs, test.ExecutionRequest.Envs)
-		request.SecretEnvs = mergeEnvs(request.SecretEnvs, test.ExecutionRequest.SecretEnvs)
-		request.EnvConfigMaps = mergeEnvReferences(request.EnvConfigMaps, test.ExecutionRequest.EnvConfigMaps)
-		request.EnvSecrets = mergeEnvReferences(request.EnvSecrets, test.ExecutionRequest.EnvSecrets)
-
-		if request.VariablesFile == "" && test.ExecutionRequest.VariablesFile != "" {
-			request.VariablesFile = test.ExecutionRequest.VariablesFile
-			request.IsVariablesFileUploaded = test.ExecutionRequest.IsVariablesFileUploaded
-		}
-
-		var fields = []struct {
-			source      string
-			destination *string
-		}{
-			{
-				test.ExecutionRequest.HttpProxy,
-				&request.HttpProxy,
-			},
-			{
-				test.ExecutionRequest.HttpsProxy,
-				&request.HttpsProxy,
-			},
-			{
-				test.ExecutionRequest.JobTemplate,
-				&request.JobTemplate,
-			},
-			{
-				test.ExecutionRequest.JobTemplateReference,
-				&request.JobTemplateReference,
-			},
-			{
-				test.ExecutionRequest.PreRunScript,
-				&request.PreRunScript,
-			},
-			{
-				test.ExecutionRequest.PostRunScript,
-				&request.PostRunScript,
-			},
-			{
-				test.ExecutionRequest.ScraperTemplate,
-				&request.ScraperTemplate,
-			},
-			{
-				test.ExecutionRequest.ScraperTemplateReference,
-				&request.ScraperTemplateReference,
-			},
-			{
-				test.ExecutionRequest.PvcTemplate,
-				&request.PvcTemplate,
-			},
-			{
-				test.ExecutionRequest.PvcTemplateReference,
-				&request.PvcTemplateReference,
-			},
-			{
-				test.ExecutionRequest.ArgsMode,
-				&request.ArgsMode,
-			},
-		}
-
-		for _, field := range fields {
-			if *field.destination == "" && field.source != "" {
-				*field.destination = field.source
-			}
-		}
-
-		// Combine test executor args with execution args
-		if len(request.Command) == 0 {
-			request.Command = test.ExecutionRequest.Command
-		}
-
-		if len(request.Args) == 0 {
-			request.Args = test.ExecutionRequest.Args
-		}
-
-		if request.ActiveDeadlineSeconds == 0 && test.ExecutionRequest.ActiveDeadlineSeconds != 0 {
-			request.ActiveDeadlineSeconds = test.ExecutionRequest.ActiveDeadlineSeconds
-		}
-
-		if !request.ExecutePostRunScriptBeforeScraping && test.ExecutionRequest.ExecutePostRunScriptBeforeScraping {
-			request.ExecutePostRunScriptBeforeScraping = test.ExecutionRequest.ExecutePostRunScriptBeforeScraping
-		}
-
-		if !request.SourceScripts && test.ExecutionRequest.SourceScripts {
-			request.SourceScripts = test.ExecutionRequest.SourceScripts
-		}
-
-		request.ArtifactRequest = mergeArtifacts(request.ArtifactRequest, test.ExecutionRequest.ArtifactRequest)
-		if request.ArtifactRequest != nil && request.ArtifactRequest.VolumeMountPath == "" {
-			request.ArtifactRequest.VolumeMountPath = filepath.Join(executor.VolumeDir, "artifacts")
-		}
-
-		request.SlavePodRequest = mergeSlavePodRequests(request.SlavePodRequest, test.ExecutionRequest.SlavePodRequest)
-		s.logger.Infow("checking for negative test change", "test", test.Name, "negativeTest", request.NegativeTest, "isNegativeTestChangedOnRun", request.IsNegativeTestChangedOnRun)
-		if !request.IsNegativeTestChangedOnRun {
-			s.logger.Infow("setting negative test from test definition", "test", test.Name, "negativeTest", test.ExecutionRequest.NegativeTest)
-			request.NegativeTest = test.ExecutionRequest.NegativeTest
-		}
+	// Test defaults have the lowest priority, then test suite, then test suite execution / test execution
+	request, fieldOrigins := client.MergeExecutionRequest(test, request)
+	s.logger.Debugw("merged execution request with test defaults", "test", test.Name, "fieldOrigins", fieldOrigins)
+	if request.ArtifactRequest != nil && request.ArtifactRequest.VolumeMountPath == "" {
+		request.ArtifactRequest.VolumeMountPath = filepath.Join(executor.VolumeDir, "artifacts")
+	}
 
+	if test.ExecutionRequest != nil {
 		// Pro edition only (tcl protected code)
 		if schedulertcl.HasExecutionNamespace(test.ExecutionRequest) {
 			if err = s.subscriptionChecker.IsActiveOrgPlanEnterpriseForFeature("execution namespace"); err != nil {
@@ -545,6 +516,27 @@ func (s *Scheduler) getExecuteOptions(namespace, id string, request testkube.Exe
 		}
 	}
 
+	var priority *client.Priority
+	if request.Priority != 0 || request.PriorityClassName != "" {
+		priority = &client.Priority{ClassName: request.PriorityClassName, Value: request.Priority}
+	}
+
+	var sharding *client.Sharding
+	if request.Sharding != nil {
+		sharding = &client.Sharding{
+			Count: int(request.Sharding.Count),
+			Envs:  request.Sharding.Envs,
+			Merge: client.ShardMerge(request.Sharding.Merge),
+		}
+	}
+
+	var delay time.Duration
+	if request.Delay != "" {
+		if delay, err = time.ParseDuration(request.Delay); err != nil {
+			return options, errors.Errorf("invalid execution delay %q: %v", request.Delay, err)
+		}
+	}
+
 	return client.ExecuteOptions{
 		TestName:             id,
 		Namespace:            request.Namespace,
@@ -561,6 +553,15 @@ func (s *Scheduler) getExecuteOptions(namespace, id string, request testkube.Exe
 		AgentAPITLSSecret:    s.agentAPITLSSecret,
 		ImagePullSecretNames: imagePullSecrets,
 		Features:             s.featureFlags,
+		NodeSelector:         request.NodeSelector,
+		ServiceAccountName:   request.ServiceAccountName,
+		WorkingDir:           request.WorkingDir,
+		Priority:             priority,
+		RunAfter:             request.RunAfter,
+		Delay:                delay,
+		OutputFormat:         client.OutputFormat(request.OutputFormat),
+		Sharding:             sharding,
+		FieldOrigins:         fieldOrigins,
 	}, nil
 }
 
@@ -577,19 +578,6 @@ func mergeVariables(vars1 map[string]testkube.Variable, vars2 map[string]testkub
 	return variables
 }
 
-func mergeEnvs(envs1 map[string]string, envs2 map[string]string) map[string]string {
-	envs := map[string]string{}
-	for k, v := range envs1 {
-		envs[k] = v
-	}
-
-	for k, v := range envs2 {
-		envs[k] = v
-	}
-
-	return envs
-}
-
 func mergeContents(test testsv3.TestSpec, testSource testsourcev1.TestSourceSpec) testsv3.TestSpec {
 	if test.Content == nil {
 		test.Content = &testsv3.TestContent{}
@@ -693,54 +681,6 @@ func mapK8sImagePullSecrets(secrets []v1.LocalObjectReference) []string {
 	return res
 }
 
-func mergeArtifacts(artifactBase *testkube.ArtifactRequest, artifactAdjust *testkube.ArtifactRequest) *testkube.ArtifactRequest {
-	switch {
-	case artifactBase == nil && artifactAdjust == nil:
-		return nil
-	case artifactBase == nil && artifactAdjust != nil:
-		return artifactAdjust
-	case artifactBase != nil && artifactAdjust == nil:
-		return artifactBase
-	default:
-		artifactBase.Dirs = append(artifactBase.Dirs, artifactAdjust.Dirs...)
-		artifactBase.Masks = append(artifactBase.Masks, artifactAdjust.Masks...)
-
-		if !artifactBase.OmitFolderPerExecution && artifactAdjust.OmitFolderPerExecution {
-			artifactBase.OmitFolderPerExecution = artifactAdjust.OmitFolderPerExecution
-		}
-
-		if !artifactBase.SharedBetweenPods && artifactAdjust.SharedBetweenPods {
-			artifactBase.SharedBetweenPods = artifactAdjust.SharedBetweenPods
-		}
-
-		var fields = []struct {
-			source      string
-			destination *string
-		}{
-			{
-				artifactAdjust.StorageClassName,
-				&artifactBase.StorageClassName,
-			},
-			{
-				artifactAdjust.VolumeMountPath,
-				&artifactBase.VolumeMountPath,
-			},
-			{
-				artifactAdjust.StorageBucket,
-				&artifactBase.StorageBucket,
-			},
-		}
-
-		for _, field := range fields {
-			if *field.destination == "" && field.source != "" {
-				*field.destination = field.source
-			}
-		}
-	}
-
-	return artifactBase
-}
-
 func adjustContent(test testsv3.TestSpec, content *testkube.TestContentRequest) testsv3.TestSpec {
 	if test.Content == nil {
 		return test
@@ -785,110 +725,3 @@ func adjustContent(test testsv3.TestSpec, content *testkube.TestContentRequest)
 
 	return test
 }
-
-func mergeEnvReferences(envs1 []testkube.EnvReference, envs2 []testkube.EnvReference) []testkube.EnvReference {
-	envs := make(map[string]testkube.EnvReference, 0)
-	for i := range envs1 {
-		if envs1[i].Reference == nil {
-			continue
-		}
-
-		envs[envs1[i].Reference.Name] = envs1[i]
-	}
-
-	for i := range envs2 {
-		if envs2[i].Reference == nil {
-			continue
-		}
-
-		if value, ok := envs[envs2[i].Reference.Name]; !ok {
-			envs[envs2[i].Reference.Name] = envs2[i]
-		} else {
-			if !value.Mount {
-				value.Mount = envs2[i].Mount
-			}
-
-			if value.MountPath == "" {
-				value.MountPath = envs2[i].MountPath
-			}
-
-			if !value.MapToVariables {
-				value.MapToVariables = envs2[i].MapToVariables
-			}
-
-			envs[envs2[i].Reference.Name] = value
-		}
-	}
-
-	res := make([]testkube.EnvReference, 0)
-	for key := range envs {
-		res = append(res, envs[key])
-	}
-
-	return res
-}
-
-func mergeSlavePodRequests(podBase *testkube.PodRequest, podAdjust *testkube.PodRequest) *testkube.PodRequest {
-	switch {
-	case podBase == nil && podAdjust == nil:
-		return nil
-	case podBase == nil && podAdjust != nil:
-		return podAdjust
-	case podBase != nil && podAdjust == nil:
-		return podBase
-	default:
-		var fields = []struct {
-			source      string
-			destination *string
-		}{
-			{
-				podAdjust.PodTemplate,
-				&podBase.PodTemplate,
-			},
-			{
-				podAdjust.PodTemplateReference,
-				&podBase.PodTemplateReference,
-			},
-		}
-
-		for _, field := range fields {
-			if *field.destination == "" && field.source != "" {
-				*field.destination = field.source
-			}
-		}
-
-		if podBase.Resources == nil && podAdjust.Resources != nil {
-			podBase.Resources = podAdjust.Resources
-			return podBase
-		}
-
-		if podBase.Resources != nil && podAdjust.Resources != nil {
-			if podBase.Resources.Requests == nil && podAdjust.Resources.Requests != nil {
-				podBase.Resources.Requests = podAdjust.Resources.Requests
-			} else if podBase.Resources.Requests != nil && podAdjust.Resources.Requests != nil {
-				if podBase.Resources.Requests.Cpu == "" && podAdjust.Resources.Requests.Cpu != "" {
-					podBase.Resources.Requests.Cpu = podAdjust.Resources.Requests.Cpu
-				}
-
-				if podBase.Resources.Requests.Memory == "" && podAdjust.Resources.Requests.Memory != "" {
-					podBase.Resources.Requests.Memory = podAdjust.Resources.Requests.Memory
-				}
-			}
-
-			if podBase.Resources.Limits == nil && podAdjust.Resources.Limits != nil {
-				podBase.Resources.Limits = podAdjust.Resources.Limits
-			} else if podBase.Resources.Limits != nil && podAdjust.Resources.Limits != nil {
-				if podBase.Resources.Limits.Cpu == "" && podAdjust.Resources.Limits.Cpu != "" {
-					podBase.Resources.Limits.Cpu = podAdjust.Resources.Limits.Cpu
-				}
-
-				if podBase.Resources.Limits.Memory == "" && podAdjust.Resources.Limits.Memory != "" {
-					podBase.Resources.Limits.Memory = podAdjust.Resources.Limits.Memory
-				}
-			}
-		}
-
-	}
-
-	return podBase
-}
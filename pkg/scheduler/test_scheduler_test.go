@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -173,8 +174,17 @@ func TestGetExecuteOptions(t *testing.T) {
 		RunningContext: &testkube.RunningContext{
 			Type_: string(testkube.RunningContextTypeUserCLI),
 		},
-		TestExecutionName: "",
-		SlavePodRequest:   &testkube.PodRequest{},
+		TestExecutionName:  "",
+		SlavePodRequest:    &testkube.PodRequest{},
+		RunAfter:           time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC),
+		Delay:              "1h30m",
+		Priority:           100,
+		PriorityClassName:  "smoke",
+		NodeSelector:       map[string]string{"kubernetes.io/os": "windows"},
+		ServiceAccountName: "cloud-iam",
+		WorkingDir:         "e2e",
+		OutputFormat:       "junit",
+		Sharding:           &testkube.ExecutionSharding{Count: 3, Envs: map[string]string{"SHARD": "{{ .Number }}/{{ .Total }}"}},
 	}
 
 	got, err := sc.getExecuteOptions("namespace", "id", req)
@@ -191,6 +201,27 @@ func TestGetExecuteOptions(t *testing.T) {
 		Sync:                 false,
 		Labels:               map[string]string(nil),
 		ImagePullSecretNames: []string{"secret-name1", "secret-name2"},
+		NodeSelector:         map[string]string{"kubernetes.io/os": "windows"},
+		ServiceAccountName:   "cloud-iam",
+		WorkingDir:           "e2e",
+		Priority:             &client.Priority{ClassName: "smoke", Value: 100},
+		RunAfter:             time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC),
+		Delay:                90 * time.Minute,
+		OutputFormat:         client.OutputFormatJUnit,
+		Sharding:             &client.Sharding{Count: 3, Envs: map[string]string{"SHARD": "{{ .Number }}/{{ .Total }}"}},
+		FieldOrigins: []client.FieldOrigin{
+			{Field: "variables", Source: client.FieldSourceRequest},
+			{Field: "envs", Source: client.FieldSourceRequest},
+			{Field: "secretEnvs", Source: client.FieldSourceRequest},
+			{Field: "envConfigMaps", Source: client.FieldSourceRequest},
+			{Field: "envSecrets", Source: client.FieldSourceRequest},
+			{Field: "command", Source: client.FieldSourceRequest},
+			{Field: "activeDeadlineSeconds", Source: client.FieldSourceRequest},
+			{Field: "executePostRunScriptBeforeScraping", Source: client.FieldSourceRequest},
+			{Field: "sourceScripts", Source: client.FieldSourceRequest},
+			{Field: "artifactRequest", Source: client.FieldSourceRequest},
+			{Field: "slavePodRequest", Source: client.FieldSourceRequest},
+		},
 	}
 
 	assert.Equal(t, want, got)
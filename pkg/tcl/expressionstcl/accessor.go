@@ -9,6 +9,7 @@
 package expressionstcl
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
@@ -66,24 +67,28 @@ func (s *accessor) SafeResolve(m ...Machine) (v Expression, changed bool, err er
 	for i := range m {
 		result, ok, err := m[i].Get(s.name)
 		if ok && err == nil {
-			return result, true, nil
+			return withSource(result, s.name), true, nil
 		}
 		if s.fallback != nil {
 			var err2 error
 			result, ok, err2 = (*s.fallback).SafeResolve(m...)
 			if ok && err2 == nil {
-				return result, true, nil
+				return withSource(result, s.name), true, nil
 			}
 		}
 		if err != nil {
-			return nil, false, fmt.Errorf("error while accessing %s: %s", s.String(), err.Error())
+			return nil, false, newKindError(ErrorKindAccessor, fmt.Errorf("error while accessing %s: %s", s.String(), err.Error()))
 		}
 	}
 	return s, false, nil
 }
 
 func (s *accessor) Resolve(m ...Machine) (v Expression, err error) {
-	return deepResolve(s, m...)
+	return s.ResolveContext(context.Background(), m...)
+}
+
+func (s *accessor) ResolveContext(ctx context.Context, m ...Machine) (v Expression, err error) {
+	return deepResolve(s, withContext(ctx, m)...)
 }
 
 func (s *accessor) Static() StaticValue {
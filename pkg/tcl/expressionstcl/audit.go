@@ -0,0 +1,292 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AuditRecord describes the single top-level resolution, i.e. of the expression deciding if the execution is created
+type AuditRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	// Accessed are the values provided by the machines for the accessors used in the resolution,
+	// the ones provided by the SecretMachine are redacted
+	Accessed map[string]interface{} `json:"accessed"`
+	// Resolved tells if the expression was fully resolved, otherwise the Value is the partially resolved expression
+	Resolved bool        `json:"resolved"`
+	Value    interface{} `json:"value,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// AuditSink receives the audit records before the resolution result is returned,
+// the resolution fails when the record couldn't be written;
+// the implementation should be safe for concurrent use, as resolutions may run in parallel
+type AuditSink interface {
+	WriteAudit(record AuditRecord) error
+}
+
+type auditSinkHolder struct {
+	sink AuditSink
+}
+
+var globalAuditSink atomic.Pointer[auditSinkHolder]
+
+// SetAuditSink sets the sink used for all top-level resolutions without own sink, nil disables the audit
+func SetAuditSink(sink AuditSink) {
+	if sink == nil {
+		globalAuditSink.Store(nil)
+		return
+	}
+	globalAuditSink.Store(&auditSinkHolder{sink: sink})
+}
+
+// WithAudit writes the audit record of the resolution to the sink, instead of the global one
+func WithAudit(sink AuditSink) ResolveOption {
+	return func(o *resolveOptions) {
+		o.audit = sink
+	}
+}
+
+type auditMachine struct {
+	sink AuditSink
+}
+
+// NewAuditMachine passes the audit sink to the top-level resolution, i.e. EvalExpression(expr, m, NewAuditMachine(sink)),
+// it takes precedence over the global sink
+func NewAuditMachine(sink AuditSink) Machine {
+	return &auditMachine{sink: sink}
+}
+
+func (a *auditMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (a *auditMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// resolveAuditSink finds the audit sink for the resolution, it's nil when the audit is disabled
+func resolveAuditSink(m []Machine) AuditSink {
+	for i := range m {
+		if a, ok := m[i].(*auditMachine); ok {
+			return a.sink
+		}
+	}
+	if holder := globalAuditSink.Load(); holder != nil {
+		return holder.sink
+	}
+	return nil
+}
+
+// audit collects the accessed values of the single top-level resolution
+type audit struct {
+	sink    AuditSink
+	record  AuditRecord
+	mu      sync.Mutex
+	secrets *redactions
+}
+
+// auditRecorder replaces the machine for the audited resolution, to record the values it provides
+type auditRecorder struct {
+	machine Machine
+	audit   *audit
+	secret  bool
+}
+
+func (a *auditRecorder) Get(name string) (Expression, bool, error) {
+	expr, ok, err := a.machine.Get(name)
+	if ok && err == nil && expr != nil && expr.Static() != nil {
+		a.audit.access(name, expr.Static().Value(), a.secret)
+	}
+	return expr, ok, err
+}
+
+func (a *auditRecorder) Call(name string, args ...StaticValue) (Expression, bool, error) {
+	return a.machine.Call(name, args...)
+}
+
+func (a *auditRecorder) CallContext(ctx context.Context, name string, args ...StaticValue) (Expression, bool, error) {
+	return AsContextCaller(a.machine).CallContext(ctx, name, args...)
+}
+
+// isMarkerMachine checks if the machine only passes the options to the resolution, so it can't be wrapped
+func isMarkerMachine(m Machine) bool {
+	switch m.(type) {
+	case *featuresMachine, noneAsEmptyStringMachine, *clockMachine, *hooksMachine, *operatorsMachine,
+		*contextMachine, *combinatorMachine, *auditMachine, *finalizer, *limitsMachine, resolveSecretsMachine, *randomMachine,
+		*FunctionCache:
+		return true
+	}
+	return false
+}
+
+// withAudit starts recording the accessed values for the top-level resolution, the audit is nil when it's disabled,
+// or when it's a nested resolution, as the outer one is recorded already
+func withAudit(source string, m []Machine) ([]Machine, *audit) {
+	sink := resolveAuditSink(m)
+	if sink == nil {
+		return m, nil
+	}
+	for i := range m {
+		if _, ok := m[i].(*auditRecorder); ok {
+			return m, nil
+		}
+	}
+	a := &audit{
+		sink:    sink,
+		record:  AuditRecord{Timestamp: time.Now(), Source: source, Accessed: make(map[string]interface{})},
+		secrets: &redactions{values: make(map[string]struct{})},
+	}
+	result := make([]Machine, len(m))
+	for i := range m {
+		switch {
+		case isMarkerMachine(m[i]):
+			result[i] = m[i]
+		case isSecretMachine(m[i]):
+			// Keep the secret machine outside, so the resolution still redacts its values
+			result[i] = NewSecretMachine(&auditRecorder{machine: m[i].(*secretMachine).machine, audit: a, secret: true})
+		default:
+			result[i] = &auditRecorder{machine: m[i], audit: a}
+		}
+	}
+	return result, a
+}
+
+func isSecretMachine(m Machine) bool {
+	_, ok := m.(*secretMachine)
+	return ok
+}
+
+func (a *audit) access(name string, value interface{}, secret bool) {
+	if secret {
+		a.secrets.add(value)
+		value = redactedValue
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.record.Accessed[name] = value
+}
+
+// finish writes the audit record with the resolution result, the error is returned when the record couldn't be written
+func (a *audit) finish(expr Expression, err error) error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	record := a.record
+	a.mu.Unlock()
+	if err != nil {
+		record.Error = a.secrets.redact(err.Error())
+	} else if expr != nil && expr.Static() != nil {
+		record.Resolved = true
+		record.Value = redactValue(expr.Static().Value(), a.secrets)
+	} else if expr != nil {
+		record.Value = a.secrets.redact(expr.String())
+	}
+	if writeErr := a.sink.WriteAudit(record); writeErr != nil {
+		return errors.Wrap(writeErr, "writing audit record")
+	}
+	return nil
+}
+
+// redactValue hides the secrets in the strings of the value
+func redactValue(value interface{}, r *redactions) interface{} {
+	if len(r.values) == 0 || value == nil {
+		return value
+	}
+	if isMap(value) || isStruct(value) {
+		v, _ := toMap(value)
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[r.redact(key)] = redactValue(item, r)
+		}
+		return result
+	}
+	if isSlice(value) {
+		v, _ := toSlice(value)
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = redactValue(item, r)
+		}
+		return result
+	}
+	if str, ok := value.(string); ok {
+		return r.redact(str)
+	}
+	if str, err := toString(value); err == nil && r.redact(str) != str {
+		return redactedValue
+	}
+	return value
+}
+
+// MemoryAuditSink keeps the audit records in memory, i.e. for tests
+type MemoryAuditSink struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func NewMemoryAuditSink() *MemoryAuditSink {
+	return &MemoryAuditSink{}
+}
+
+func (s *MemoryAuditSink) WriteAudit(record AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+// Records returns the copy of the records written so far
+func (s *MemoryAuditSink) Records() []AuditRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]AuditRecord(nil), s.records...)
+}
+
+// FileAuditSink appends the audit records to the file as JSON lines, each record is synced before the resolution returns
+type FileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileAuditSink opens the file for appending the audit records, it's created when it doesn't exist
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening audit file")
+	}
+	return &FileAuditSink{file: file}, nil
+}
+
+func (s *FileAuditSink) WriteAudit(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err = s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
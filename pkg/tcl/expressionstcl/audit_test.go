@@ -0,0 +1,152 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var auditValuesMachine = NewMachine().
+	Register("status", "passed").
+	Register("items", []int{1, 2}).
+	Register("offset", 10).
+	Register("unused", "value")
+
+func TestAuditAccessedOnly(t *testing.T) {
+	sink := NewMemoryAuditSink()
+	v, err := EvalExpression(`status == "passed" ? offset : unused`, auditValuesMachine, NewAuditMachine(sink))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), must(v.IntValue()))
+
+	records := sink.Records()
+	assert.Len(t, records, 1)
+	assert.Equal(t, `status == "passed" ? offset : unused`, records[0].Source)
+	assert.Equal(t, map[string]interface{}{"status": "passed", "offset": 10}, records[0].Accessed)
+	assert.True(t, records[0].Resolved)
+	assert.Equal(t, 10, records[0].Value)
+	assert.Empty(t, records[0].Error)
+	assert.False(t, records[0].Timestamp.IsZero())
+}
+
+func TestAuditRecordPerTopLevelResolution(t *testing.T) {
+	sink := NewMemoryAuditSink()
+	v, err := EvalExpression(`map(items, "_.value + offset")`, auditValuesMachine, NewAuditMachine(sink))
+	assert.NoError(t, err)
+	assert.Equal(t, `[11,12]`, v.String())
+
+	_, err = EvalTemplate(`{{status}}-{{len(items)}}`, auditValuesMachine, NewAuditMachine(sink))
+	assert.NoError(t, err)
+
+	records := sink.Records()
+	assert.Len(t, records, 2)
+	assert.Equal(t, map[string]interface{}{"items": []int{1, 2}, "offset": 10}, records[0].Accessed)
+	assert.Equal(t, map[string]interface{}{"status": "passed", "items": []int{1, 2}}, records[1].Accessed)
+	assert.Equal(t, "passed-2", records[1].Value)
+}
+
+func TestAuditPartialAndError(t *testing.T) {
+	sink := NewMemoryAuditSink()
+	expr, err := EvalExpressionPartial(`status + other`, auditValuesMachine, NewAuditMachine(sink))
+	assert.NoError(t, err)
+	assert.Equal(t, `"passed"+other`, expr.String())
+
+	_, err = EvalExpression(`status * 2`, auditValuesMachine, NewAuditMachine(sink))
+	assert.Error(t, err)
+
+	records := sink.Records()
+	assert.Len(t, records, 2)
+	assert.False(t, records[0].Resolved)
+	assert.Equal(t, `"passed"+other`, records[0].Value)
+	assert.False(t, records[1].Resolved)
+	assert.NotEmpty(t, records[1].Error)
+}
+
+func TestAuditSecretRedaction(t *testing.T) {
+	sink := NewMemoryAuditSink()
+	secrets := NewSecretMachine(NewMachine().Register("secret.token", "abc123"))
+	v, err := EvalString(`"Bearer " + secret.token`, map[string]interface{}{"unused": 1}, WithMachines(secrets), WithAudit(sink))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer abc123", v)
+
+	records := sink.Records()
+	assert.Len(t, records, 1)
+	assert.Equal(t, map[string]interface{}{"secret.token": "***"}, records[0].Accessed)
+	assert.Equal(t, "Bearer ***", records[0].Value)
+
+	_, err = EvalExpression(`secret.token + missing`, secrets, NewAuditMachine(sink), FinalizerFail)
+	assert.Error(t, err)
+	records = sink.Records()
+	assert.Len(t, records, 2)
+	assert.NotContains(t, records[1].Error, "abc123")
+}
+
+func TestAuditGlobalSink(t *testing.T) {
+	sink := NewMemoryAuditSink()
+	SetAuditSink(sink)
+	defer SetAuditSink(nil)
+
+	_, err := EvalExpression(`status`, auditValuesMachine)
+	assert.NoError(t, err)
+	_, err = Compile(`len([1, 2])`)
+	assert.NoError(t, err)
+	assert.Len(t, sink.Records(), 1)
+
+	// The sink of the resolution takes precedence
+	own := NewMemoryAuditSink()
+	_, err = EvalExpression(`status`, auditValuesMachine, NewAuditMachine(own))
+	assert.NoError(t, err)
+	assert.Len(t, sink.Records(), 1)
+	assert.Len(t, own.Records(), 1)
+}
+
+type failingAuditSink struct{}
+
+func (failingAuditSink) WriteAudit(_ AuditRecord) error {
+	return errors.New("disk full")
+}
+
+func TestAuditSinkError(t *testing.T) {
+	_, err := EvalExpression(`status`, auditValuesMachine, NewAuditMachine(failingAuditSink{}))
+	assert.ErrorContains(t, err, "writing audit record: disk full")
+}
+
+func TestFileAuditSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileAuditSink(path)
+	assert.NoError(t, err)
+
+	_, err = EvalExpression(`status`, auditValuesMachine, NewAuditMachine(sink))
+	assert.NoError(t, err)
+	_, err = EvalExpression(`offset + 1`, auditValuesMachine, NewAuditMachine(sink))
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	records := make([]AuditRecord, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record AuditRecord
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	assert.Len(t, records, 2)
+	assert.Equal(t, map[string]interface{}{"status": "passed"}, records[0].Accessed)
+	assert.Equal(t, "passed", records[0].Value)
+	assert.Equal(t, map[string]interface{}{"offset": 10.0}, records[1].Accessed)
+	assert.Equal(t, 11.0, records[1].Value)
+}
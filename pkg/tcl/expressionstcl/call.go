@@ -9,9 +9,12 @@
 package expressionstcl
 
 import (
+	"context"
 	"fmt"
 	"maps"
+	"slices"
 	"strings"
+	"time"
 )
 
 type call struct {
@@ -90,6 +93,9 @@ func (s *call) resolvedArgs() ([]StaticValue, error) {
 			staticItems := make([]StaticValue, len(items))
 			for i := range items {
 				staticItems[i] = NewValue(items[i])
+				if isSecretValue(value) {
+					staticItems[i] = taintSecret(staticItems[i])
+				}
 			}
 			v = append(v, staticItems...)
 		} else {
@@ -100,30 +106,55 @@ func (s *call) resolvedArgs() ([]StaticValue, error) {
 }
 
 func (s *call) SafeResolve(m ...Machine) (v Expression, changed bool, err error) {
-	var ch bool
+	// The source is reported on failure, before the arguments are resolved
+	source := s.String()
+	// The arguments are replaced in the copy, the compiled call stays intact
+	s = &call{name: s.name, args: slices.Clone(s.args)}
+	// Resolve all the arguments, to report all the independent failures at once
+	var errs []error
 	for i := range s.args {
-		s.args[i].expr, ch, err = s.args[i].expr.SafeResolve(m...)
+		expr, ch, err := s.args[i].expr.SafeResolve(m...)
 		changed = changed || ch
 		if err != nil {
-			return nil, changed, err
+			errs = append(errs, err)
+			continue
 		}
+		s.args[i].expr = expr
+	}
+	if len(errs) > 0 {
+		return nil, changed, joinErrors(errs...)
 	}
 	if s.isResolved() {
 		args, err := s.resolvedArgs()
 		if err != nil {
 			return nil, true, err
 		}
-		result, ok, err := StdLibMachine.Call(s.name, args...)
+		if err = countNode(m); err != nil {
+			return nil, true, newKindError(ErrorKindCallStack, err)
+		}
+		hooks := resolveHooks(m)
+		var start time.Time
+		if hooks != nil {
+			start = time.Now()
+		}
+		result, ok, err := callStdFunction(resolveFeatures(m), resolveClock(m), m, s.name, args...)
 		if ok {
+			if hooks != nil {
+				hooks.OnFunctionCall(s.name, time.Since(start))
+			}
 			if err != nil {
-				return nil, true, fmt.Errorf("error while calling %s: %s", s.String(), err.Error())
+				return nil, true, newKindError(ErrorKindFunction, newFunctionError(s.name, source, err))
 			}
 			return result, true, nil
 		}
 		for i := range m {
-			result, ok, err = m[i].Call(s.name, args...)
+			result, ok, err = AsContextCaller(m[i]).CallContext(resolveContext(m), s.name, args...)
+			result, err = taintResult(args, result, err)
+			if (ok || err != nil) && hooks != nil {
+				hooks.OnFunctionCall(s.name, time.Since(start))
+			}
 			if err != nil {
-				return nil, true, fmt.Errorf("error while calling %s: %s", s.String(), err.Error())
+				return nil, true, newKindError(ErrorKindFunction, newFunctionError(s.name, source, err))
 			}
 			if ok {
 				return result, true, nil
@@ -134,7 +165,11 @@ func (s *call) SafeResolve(m ...Machine) (v Expression, changed bool, err error)
 }
 
 func (s *call) Resolve(m ...Machine) (v Expression, err error) {
-	return deepResolve(s, m...)
+	return s.ResolveContext(context.Background(), m...)
+}
+
+func (s *call) ResolveContext(ctx context.Context, m ...Machine) (v Expression, err error) {
+	return deepResolve(s, withContext(ctx, m)...)
 }
 
 func (s *call) Static() StaticValue {
@@ -0,0 +1,91 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+type checksumAlgorithm struct {
+	name    string
+	newHash func() hash.Hash
+	// hexLength is a length of hex encoded digest
+	hexLength int
+}
+
+var checksumAlgorithms = []checksumAlgorithm{
+	{name: "md5", newHash: md5.New, hexLength: 32},
+	{name: "sha1", newHash: sha1.New, hexLength: 40},
+	{name: "sha256", newHash: sha256.New, hexLength: 64},
+	{name: "sha512", newHash: sha512.New, hexLength: 128},
+}
+
+func findChecksumAlgorithm(match func(checksumAlgorithm) bool) (checksumAlgorithm, bool) {
+	for _, algorithm := range checksumAlgorithms {
+		if match(algorithm) {
+			return algorithm, true
+		}
+	}
+	return checksumAlgorithm{}, false
+}
+
+// checksumVerify compares content digest with the expected hex digest in constant time,
+// the algorithm is taken from the "algorithm:" digest prefix, forced algorithm or the digest length
+func checksumVerify(content, expected, forced string) (bool, error) {
+	algorithmName := strings.ToLower(forced)
+	if prefix, digest, ok := strings.Cut(expected, ":"); ok {
+		prefix = strings.ToLower(prefix)
+		if algorithmName != "" && algorithmName != prefix {
+			return false, fmt.Errorf("digest algorithm %s doesn't match forced algorithm %s", prefix, algorithmName)
+		}
+		algorithmName, expected = prefix, digest
+	}
+
+	var algorithm checksumAlgorithm
+	var ok bool
+	if algorithmName != "" {
+		algorithm, ok = findChecksumAlgorithm(func(a checksumAlgorithm) bool { return a.name == algorithmName })
+		if !ok {
+			return false, fmt.Errorf("unknown checksum algorithm %q, supported: md5, sha1, sha256, sha512", algorithmName)
+		}
+		if len(expected) != algorithm.hexLength {
+			return false, fmt.Errorf("%s digest should have %d hex characters, %d provided", algorithm.name, algorithm.hexLength, len(expected))
+		}
+	} else {
+		algorithm, ok = findChecksumAlgorithm(func(a checksumAlgorithm) bool { return a.hexLength == len(expected) })
+		if !ok {
+			return false, fmt.Errorf("can't detect checksum algorithm from digest with %d characters, expected 32 (md5), 40 (sha1), 64 (sha256) or 128 (sha512)", len(expected))
+		}
+	}
+
+	expectedSum, err := hex.DecodeString(expected)
+	if err != nil {
+		return false, fmt.Errorf("digest is not hex encoded: %v", err)
+	}
+	h := algorithm.newHash()
+	h.Write([]byte(content))
+	return subtle.ConstantTimeCompare(h.Sum(nil), expectedSum) == 1, nil
+}
+
+// hashFunction returns hex encoded digest of the value stringified the same way as with string() function
+func hashFunction(name string, features Features, value ...StaticValue) (Expression, error) {
+	algorithm, _ := findChecksumAlgorithm(func(a checksumAlgorithm) bool { return a.name == name })
+	str, _ := toStringWith(features, value[0].Value())
+	h := algorithm.newHash()
+	h.Write([]byte(str))
+	return NewValue(hex.EncodeToString(h.Sum(nil))), nil
+}
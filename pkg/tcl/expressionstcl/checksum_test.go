@@ -0,0 +1,61 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var helloDigests = map[string]string{
+	"md5":    "5d41402abc4b2a76b9719d911017c592",
+	"sha1":   "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+	"sha256": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	"sha512": "9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca72323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043",
+}
+
+func TestChecksumVerifyDetected(t *testing.T) {
+	for algorithm, digest := range helloDigests {
+		assert.Equal(t, `true`, MustCompile(fmt.Sprintf(`checksumVerify("hello", "%s")`, digest)).String(), algorithm)
+		assert.Equal(t, `true`, MustCompile(fmt.Sprintf(`checksumVerify("hello", "%s")`, strings.ToUpper(digest))).String(), algorithm)
+		assert.Equal(t, `false`, MustCompile(fmt.Sprintf(`checksumVerify("hello!", "%s")`, digest)).String(), algorithm)
+	}
+}
+
+func TestChecksumVerifyPrefix(t *testing.T) {
+	for algorithm, digest := range helloDigests {
+		assert.Equal(t, `true`, MustCompile(fmt.Sprintf(`checksumVerify("hello", "%s:%s")`, algorithm, digest)).String(), algorithm)
+		assert.Equal(t, `true`, MustCompile(fmt.Sprintf(`checksumVerify("hello", "%s:%s")`, strings.ToUpper(algorithm), digest)).String(), algorithm)
+	}
+
+	_, err := Compile(fmt.Sprintf(`checksumVerify("hello", "sha512:%s")`, helloDigests["sha256"]))
+	assert.ErrorContains(t, err, `"checksumVerify" function: sha512 digest should have 128 hex characters, 64 provided`)
+	_, err = Compile(fmt.Sprintf(`checksumVerify("hello", "crc32:%s")`, helloDigests["md5"]))
+	assert.ErrorContains(t, err, `unknown checksum algorithm "crc32"`)
+}
+
+func TestChecksumVerifyForced(t *testing.T) {
+	assert.Equal(t, `true`, MustCompile(fmt.Sprintf(`checksumVerify("hello", "%s", "sha256")`, helloDigests["sha256"])).String())
+	assert.Equal(t, `true`, MustCompile(fmt.Sprintf(`checksumVerify("hello", "sha256:%s", "SHA256")`, helloDigests["sha256"])).String())
+
+	_, err := Compile(fmt.Sprintf(`checksumVerify("hello", "%s", "sha1")`, helloDigests["md5"]))
+	assert.ErrorContains(t, err, `sha1 digest should have 40 hex characters, 32 provided`)
+	_, err = Compile(fmt.Sprintf(`checksumVerify("hello", "md5:%s", "sha1")`, helloDigests["md5"]))
+	assert.ErrorContains(t, err, `digest algorithm md5 doesn't match forced algorithm sha1`)
+}
+
+func TestChecksumVerifyInvalid(t *testing.T) {
+	_, err := Compile(`checksumVerify("hello", "abc")`)
+	assert.ErrorContains(t, err, `can't detect checksum algorithm from digest with 3 characters`)
+	_, err = Compile(`checksumVerify("hello", "zz41402abc4b2a76b9719d911017c592")`)
+	assert.ErrorContains(t, err, `digest is not hex encoded`)
+}
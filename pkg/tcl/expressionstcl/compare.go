@@ -0,0 +1,278 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"cmp"
+	"fmt"
+	math2 "math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// isNumericString checks if the string would be converted to a number when FeatureNumericComparison is disabled
+func isNumericString(v StaticValue) bool {
+	if !v.IsString() {
+		return false
+	}
+	str, _ := v.StringValue()
+	_, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+	return err == nil
+}
+
+// checkNumericOperands rejects comparing a number with a numeric string, as it's ambiguous
+// whether it should be compared as numbers or as strings
+func checkNumericOperands(v1, v2 StaticValue) error {
+	if (v1.IsNumber() && isNumericString(v2)) || (v2.IsNumber() && isNumericString(v1)) {
+		return fmt.Errorf("cannot compare number with string: %s and %s, convert one of them with int(), float() or string()", v1.String(), v2.String())
+	}
+	return nil
+}
+
+// numberValue normalizes Go numeric types to int64 or float64, big uint64 values are converted to float64
+func numberValue(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int64, float64:
+		return n
+	case float32:
+		return float64(n)
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value.Uint() > math2.MaxInt64 {
+			return float64(value.Uint())
+		}
+		return int64(value.Uint())
+	}
+	return v
+}
+
+// compareIntFloat compares int64 with float64 exactly, without converting the integer to float64,
+// which would lose precision above 2^53
+func compareIntFloat(i int64, f float64) int {
+	if f >= math2.MaxInt64 {
+		// float64(math.MaxInt64) is 2^63, so it's above any int64
+		return -1
+	}
+	if f < math2.MinInt64 {
+		return 1
+	}
+	truncated := math2.Trunc(f)
+	if c := cmp.Compare(i, int64(truncated)); c != 0 {
+		return c
+	}
+	if f > truncated {
+		return -1
+	} else if f < truncated {
+		return 1
+	}
+	return 0
+}
+
+// compareNumbers compares numbers by their mathematical value, it's not ok for NaN
+func compareNumbers(a, b interface{}) (result int, ok bool) {
+	a, b = numberValue(a), numberValue(b)
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, y), true
+		case float64:
+			if math2.IsNaN(y) {
+				return 0, false
+			}
+			return compareIntFloat(x, y), true
+		}
+	case float64:
+		if math2.IsNaN(x) {
+			return 0, false
+		}
+		switch y := b.(type) {
+		case int64:
+			return -compareIntFloat(y, x), true
+		case float64:
+			if math2.IsNaN(y) {
+				return 0, false
+			}
+			if x < y {
+				return -1, true
+			} else if x > y {
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+// equalValues compares numbers by their mathematical value, and other values by their string form,
+// all values are compared by string form when FeatureNumericComparison is disabled
+func equalValues(features Features, v1, v2 StaticValue) (bool, error) {
+	if features.Enabled(FeatureNumericComparison) {
+		if v1.IsNumber() && v2.IsNumber() {
+			c, ok := compareNumbers(v1.Value(), v2.Value())
+			return ok && c == 0, nil
+		}
+		if err := checkNumericOperands(v1, v2); err != nil {
+			return false, err
+		}
+	}
+	s1, err := toStringWith(features, v1.Value())
+	if err != nil {
+		return false, err
+	}
+	s2, err := toStringWith(features, v2.Value())
+	if err != nil {
+		return false, err
+	}
+	return s1 == s2, nil
+}
+
+// checkOrderable rejects the lists that can't be ordered, as they contain maps or structs, naming the first offending position
+func checkOrderable(list []interface{}, path string) error {
+	for i := range list {
+		position := fmt.Sprintf("%s%d", path, i)
+		if isMap(list[i]) || isStruct(list[i]) {
+			return fmt.Errorf("%s at position %s is not comparable", NewValue(list[i]), position)
+		}
+		if isSlice(list[i]) {
+			items, _ := toSlice(list[i])
+			if err := checkOrderable(items, position+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compareLists compares the lists lexicographically, element by element, and the shorter one is first when it's a prefix
+// of the other; the elements at the same position need to be of the same kind: numbers, strings, booleans or lists
+func compareLists(a, b []interface{}) (int, error) {
+	return compareListsAt(a, b, "")
+}
+
+func compareListsAt(a, b []interface{}, path string) (int, error) {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		position := fmt.Sprintf("%s%d", path, i)
+		var c int
+		switch x, y := a[i], b[i]; {
+		case isNumber(x) && isNumber(y):
+			var ok bool
+			if c, ok = compareNumbers(x, y); !ok {
+				return 0, fmt.Errorf("NaN at position %s is not comparable", position)
+			}
+		case isString(x) && isString(y):
+			c = strings.Compare(x.(string), y.(string))
+		case isBool(x) && isBool(y):
+			c = cmp.Compare(boolRank(x.(bool)), boolRank(y.(bool)))
+		case isSlice(x) && isSlice(y):
+			xs, _ := toSlice(x)
+			ys, _ := toSlice(y)
+			var err error
+			if c, err = compareListsAt(xs, ys, position+"."); err != nil {
+				return 0, err
+			}
+		case x == nil && y == nil:
+		default:
+			return 0, fmt.Errorf("%s and %s at position %s are not comparable", NewValue(x), NewValue(y), position)
+		}
+		if c != 0 {
+			return c, nil
+		}
+	}
+	return cmp.Compare(len(a), len(b)), nil
+}
+
+// compareOrderedLists checks and compares the lists, see compareLists
+func compareOrderedLists(v1, v2 StaticValue) (int, error) {
+	a, _ := v1.SliceValue()
+	b, _ := v2.SliceValue()
+	if err := checkOrderable(a, ""); err != nil {
+		return 0, err
+	}
+	if err := checkOrderable(b, ""); err != nil {
+		return 0, err
+	}
+	return compareLists(a, b)
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// compareValues compares values for relational operators, numbers are compared by their mathematical value,
+// lists lexicographically, other values are converted to float64; the accept function decides about the result of comparison.
+// All values are converted to float64 when FeatureNumericComparison is disabled.
+func compareValues(features Features, v1, v2 StaticValue, accept func(c int) bool) (StaticValue, error) {
+	if v1.IsSlice() && v2.IsSlice() {
+		c, err := compareOrderedLists(v1, v2)
+		if err != nil {
+			return nil, err
+		}
+		return NewValue(accept(c)), nil
+	}
+	if features.Enabled(FeatureNumericComparison) {
+		if v1.IsNumber() && v2.IsNumber() {
+			c, ok := compareNumbers(v1.Value(), v2.Value())
+			return NewValue(ok && accept(c)), nil
+		}
+		if err := checkNumericOperands(v1, v2); err != nil {
+			return nil, err
+		}
+	}
+	return runOp(v1, v2, staticFloat, func(s1, s2 float64) bool {
+		switch {
+		case s1 < s2:
+			return accept(-1)
+		case s1 > s2:
+			return accept(1)
+		case s1 == s2:
+			return accept(0)
+		}
+		// NaN is not comparable
+		return false
+	})
+}
+
+// compareInRange compares the value with the range boundaries, the numbers are compared by their mathematical value
+// and the strings lexicographically, the arguments of other or mixed kinds are not comparable
+func compareInRange(value, low, high StaticValue) (toLow int, toHigh int, err error) {
+	values := []StaticValue{value, low, high}
+	switch {
+	case value.IsNumber() && low.IsNumber() && high.IsNumber():
+		var c [3]int
+		for i, pair := range [][2]StaticValue{{low, high}, {value, low}, {value, high}} {
+			var ok bool
+			if c[i], ok = compareNumbers(pair[0].Value(), pair[1].Value()); !ok {
+				return 0, 0, fmt.Errorf("NaN is not comparable")
+			}
+		}
+		if c[0] > 0 {
+			return 0, 0, fmt.Errorf("low boundary %s is greater than high boundary %s", low.String(), high.String())
+		}
+		return c[1], c[2], nil
+	case value.IsString() && low.IsString() && high.IsString():
+		s := make([]string, len(values))
+		for i := range values {
+			s[i], _ = values[i].StringValue()
+		}
+		if s[1] > s[2] {
+			return 0, 0, fmt.Errorf("low boundary %s is greater than high boundary %s", low.String(), high.String())
+		}
+		return strings.Compare(s[0], s[1]), strings.Compare(s[0], s[2]), nil
+	}
+	return 0, 0, fmt.Errorf("arguments should be all numbers or all strings, %s, %s and %s provided", value.String(), low.String(), high.String())
+}
@@ -0,0 +1,246 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	math2 "math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumericComparison(t *testing.T) {
+	tests := map[string]bool{
+		`1 == 1.0`:             true,
+		`1 != 1.0`:             false,
+		`1 == 1.5`:             false,
+		`len([1,2]) == 2.0`:    true,
+		`len([1,2]) < 2.5`:     true,
+		`len([1,2]) >= 2.0`:    true,
+		`floor(2.7) == 2`:      true,
+		`(-1) < (-0.5)`:        true,
+		`10 > 9.99`:            true,
+		`"abc" == 1`:           false,
+		`"abc" != 1`:           true,
+		`"10" == "10.0"`:       false,
+		`"10" < "9"`:           false,
+		`true == "true"`:       true,
+		`null == ""`:           true,
+		`2 <= 2`:               true,
+		`0.1 + 0.2 == 0.3`:     false,
+		`0.5 + 0.25 == 0.75`:   true,
+		`1e300 > 1`:            true,
+		`int("3") == 3`:        true,
+		`string(3) == "3"`:     true,
+		`float("2.5") > 2`:     true,
+		`len("abc") == 3.0000`: true,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, must(MustCompile(expr).Static().BoolValue()), expr)
+	}
+}
+
+func TestNumericComparisonMachineTypes(t *testing.T) {
+	m := NewMachine().
+		Register("i", 3).
+		Register("u", uint8(3)).
+		Register("f", float32(3)).
+		Register("big", uint64(math2.MaxUint64))
+
+	tests := map[string]bool{
+		`i == 3`:                    true,
+		`i == u`:                    true,
+		`u == f`:                    true,
+		`i < 3.5`:                   true,
+		`big > 9223372036854775807`: true,
+	}
+	for expr, expected := range tests {
+		v, err := EvalExpression(expr, m)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, must(v.BoolValue()), expr)
+	}
+}
+
+func TestNumericComparisonPrecision(t *testing.T) {
+	const maxSafe = int64(1) << 53
+
+	tests := []struct {
+		a, b     interface{}
+		expected int
+	}{
+		{maxSafe, float64(maxSafe), 0},
+		{maxSafe + 1, float64(maxSafe), 1}, // float64(2^53+1) would be rounded to 2^53
+		{maxSafe - 1, float64(maxSafe), -1},
+		{float64(maxSafe), maxSafe + 1, -1},
+		{maxSafe + 1, maxSafe, 1},
+		{-maxSafe - 1, -float64(maxSafe), -1},
+		{int64(math2.MaxInt64), float64(math2.MaxInt64), -1}, // float64(MaxInt64) is 2^63
+		{int64(math2.MinInt64), float64(math2.MinInt64), 0},
+		{int64(math2.MinInt64), -1e19, 1},
+		{int64(2), 2.5, -1},
+		{int64(-2), -2.5, 1},
+		{int64(3), 2.9999999, 1},
+		{int64(0), math2.Copysign(0, -1), 0},
+		{int64(1), math2.Inf(1), -1},
+		{int64(1), math2.Inf(-1), 1},
+	}
+	for _, tt := range tests {
+		c, ok := compareNumbers(tt.a, tt.b)
+		assert.True(t, ok, "%v <=> %v", tt.a, tt.b)
+		assert.Equal(t, tt.expected, c, "%v <=> %v", tt.a, tt.b)
+	}
+
+	_, ok := compareNumbers(int64(1), math2.NaN())
+	assert.False(t, ok)
+	_, ok = compareNumbers(math2.NaN(), math2.NaN())
+	assert.False(t, ok)
+
+	// 2^53+1 is not exactly representable as float64, but integers are compared exactly
+	v, err := EvalExpression(`a == b`, NewMachine().Register("a", maxSafe+1).Register("b", maxSafe))
+	assert.NoError(t, err)
+	assert.Equal(t, false, must(v.BoolValue()))
+	v, err = EvalExpression(`a > b`, NewMachine().Register("a", maxSafe+1).Register("b", float64(maxSafe)))
+	assert.NoError(t, err)
+	assert.Equal(t, true, must(v.BoolValue()))
+}
+
+func TestNumericComparisonPrecisionLiterals(t *testing.T) {
+	tests := map[string]bool{
+		`9007199254740993 == 9007199254740992`:           false,
+		`9007199254740993 > 9007199254740992`:            true,
+		`9007199254740991 < 9007199254740992`:            true,
+		`9007199254740992 == 9007199254740992.0`:         true,
+		`(-9007199254740993) < (-9007199254740992)`:      true,
+		`9223372036854775807 > 9223372036854775806`:      true,
+		`int("9007199254740993") == 9007199254740993`:    true,
+		`int("9007199254740993") != 9007199254740992`:    true,
+		`int(" 9007199254740993 ") > 9007199254740992.0`: true,
+		`int("2.7") == 2`:                                true,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, must(MustCompile(expr).Static().BoolValue()), expr)
+	}
+
+	assert.Equal(t, int64(9007199254740993), must(MustCompile(`9007199254740993`).Static().IntValue()))
+	assert.Equal(t, int64(9007199254740993), must(MustCompile(`int("9007199254740993")`).Static().IntValue()))
+}
+
+func TestNumericStringComparisonError(t *testing.T) {
+	for _, expr := range []string{`1 == "1"`, `"1" != 1`, `2 > "1"`, `"1.5" <= 2`, `len([1]) == " 1 "`} {
+		_, err := Compile(expr)
+		assert.ErrorContains(t, err, "cannot compare number with string", expr)
+	}
+}
+
+func TestListComparison(t *testing.T) {
+	tests := map[string]bool{
+		`[1, 2] < [1, 3]`:              true,
+		`[1, 2] < [1, 2]`:              false,
+		`[1, 2] <= [1, 2]`:             true,
+		`[1, 2] < [1, 2, 0]`:           true,
+		`[] < [0]`:                     true,
+		`[2] > [1, 9]`:                 true,
+		`["b", "a"] > ["a", "z"]`:      true,
+		`[false, 1] < [true, 0]`:       true,
+		`[[1, 2], 3] < [[1, 2, 0], 1]`: true,
+		`[[1], "x"] >= [[1], "x"]`:     true,
+	}
+	for expr, expected := range tests {
+		v, err := Compile(expr)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, must(v.Static().BoolValue()), expr)
+	}
+}
+
+func TestListComparisonErrors(t *testing.T) {
+	_, err := Compile(`[1, {"a": 1}] < [2, 0]`)
+	assert.ErrorContains(t, err, `{"a":1} at position 1 is not comparable`)
+	_, err = Compile(`[1, [2, "a"]] < [1, [2, 3]]`)
+	assert.ErrorContains(t, err, `"a" and 3 at position 1.1 are not comparable`)
+	_, err = Compile(`[1, 2] < ["1", 2]`)
+	assert.ErrorContains(t, err, `1 and "1" at position 0 are not comparable`)
+}
+
+func TestSortDecorated(t *testing.T) {
+	vars := map[string]interface{}{
+		"tests": []interface{}{
+			map[string]interface{}{"name": "web", "priority": 2},
+			map[string]interface{}{"name": "db", "priority": 1},
+			map[string]interface{}{"name": "api", "priority": 2},
+		},
+	}
+	v, err := EvalString(`map(sort(tests, "list(_.value.priority, _.value.name)"), "_.value.name")`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"db", "api", "web"}, v)
+}
+
+func TestComparisonWithoutNumericComparison(t *testing.T) {
+	features := Features{FeatureNumericComparison: false}
+	for _, expr := range []string{`1 == "1"`, `2 > "1"`, `1 == 1.0`} {
+		v, err := CompileWithFeatures(expr, features)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, true, must(v.Static().BoolValue()), expr)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := map[string]bool{
+		`between(30, 30, 120)`:               true,
+		`between(120, 30, 120)`:              true,
+		`between(29.999, 30, 120)`:           false,
+		`between(120.001, 30, 120)`:          false,
+		`between(75, 30, 120)`:               true,
+		`between(5, 5, 5)`:                   true,
+		`between(5.0, 5, 5)`:                 true,
+		`between(-1, -2.5, 0)`:               true,
+		`betweenExclusive(30, 30, 120)`:      false,
+		`betweenExclusive(120, 30, 120)`:     false,
+		`betweenExclusive(30.5, 30, 120)`:    true,
+		`betweenExclusive(119.999, 30, 120)`: true,
+		`betweenExclusive(5, 5, 5)`:          false,
+		`between("b", "a", "c")`:             true,
+		`between("a", "a", "c")`:             true,
+		`between("c", "a", "c")`:             true,
+		`between("ca", "a", "c")`:            false,
+		`between("10", "1", "9")`:            true,
+		`betweenExclusive("a", "a", "c")`:    false,
+		`betweenExclusive("ab", "a", "c")`:   true,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, must(MustCompile(expr).Static().BoolValue()), expr)
+	}
+
+	v, err := EvalString(`between(duration, 30, 120)`, map[string]interface{}{"duration": int32(45)})
+	assert.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	// integers are compared exactly with the float boundaries
+	const maxSafe = int64(1) << 53
+	v, err = EvalString(`between(a, b, b)`, map[string]interface{}{"a": maxSafe + 1, "b": float64(maxSafe)})
+	assert.NoError(t, err)
+	assert.Equal(t, false, v)
+}
+
+func TestBetweenErrors(t *testing.T) {
+	tests := map[string]string{
+		`between(1, 2)`:                   `"between" expects 3 arguments, 2 provided`,
+		`between(5, 10, 1)`:               `"between" function: low boundary 10 is greater than high boundary 1`,
+		`betweenExclusive("b", "c", "a")`: `"betweenExclusive" function: low boundary "c" is greater than high boundary "a"`,
+		`between(5, "1", 10)`:             `"between" function: arguments should be all numbers or all strings, 5, "1" and 10 provided`,
+		`between(true, false, true)`:      `"between" function: arguments should be all numbers or all strings`,
+		`between(null, 1, 2)`:             `"between" function: arguments should be all numbers or all strings`,
+	}
+	for expr, message := range tests {
+		_, err := Compile(expr)
+		assert.ErrorContains(t, err, message, expr)
+	}
+
+	_, err := EvalString(`between(nan, 1, 2)`, map[string]interface{}{"nan": math2.NaN()})
+	assert.ErrorContains(t, err, `"between" function: NaN is not comparable`)
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultCompiledCacheMaxEntries is a default maximum number of cached compiled expressions
+const DefaultCompiledCacheMaxEntries = 10_000
+
+// compileGeneration changes whenever the compilation of the same source could give a different result
+var compileGeneration atomic.Uint64
+
+// BumpCompileGeneration invalidates the expressions cached by all the CompiledCache instances,
+// it should be called after changing anything the compilation depends on, i.e. the standard library functions;
+// SetDefaultFeatures calls it already
+func BumpCompileGeneration() {
+	compileGeneration.Add(1)
+}
+
+// CompiledCacheStats are numbers of the compiled cache lookups;
+// compiles are the actual compilations, so the concurrent misses of the same source are counted once
+type CompiledCacheStats struct {
+	Hits     uint64
+	Misses   uint64
+	Compiles uint64
+	Entries  int
+}
+
+type compiledCacheEntry struct {
+	source     string
+	generation uint64
+	expr       Expression
+}
+
+// CompiledCache shares the compiled expressions by their source, i.e. for the trigger specs evaluated repeatedly,
+// the cached expressions are not modified by the resolution, so they may be resolved concurrently
+type CompiledCache struct {
+	maxEntries int
+	group      singleflight.Group
+
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+	compiles atomic.Uint64
+}
+
+// NewCompiledCache creates LRU cache of the compiled expressions, non-positive maxEntries uses the default
+func NewCompiledCache(maxEntries int) *CompiledCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCompiledCacheMaxEntries
+	}
+	return &CompiledCache{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the compiled expression, it's compiled once for the concurrent calls with the same source,
+// the compilation errors are not cached
+func (c *CompiledCache) Get(source string) (Expression, error) {
+	generation := compileGeneration.Load()
+	if expr, ok := c.get(source, generation); ok {
+		return expr, nil
+	}
+
+	expr, err, _ := c.group.Do(source, func() (interface{}, error) {
+		// Another call may have compiled it in the meantime
+		if expr, ok := c.lookup(source, generation); ok {
+			return expr, nil
+		}
+		c.compiles.Add(1)
+		expr, err := Compile(source)
+		if err != nil {
+			return nil, err
+		}
+		c.add(source, generation, expr)
+		return expr, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expr.(Expression), nil
+}
+
+// Stats returns the numbers of cache lookups and the current cache size
+func (c *CompiledCache) Stats() CompiledCacheStats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+	return CompiledCacheStats{
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Compiles: c.compiles.Load(),
+		Entries:  entries,
+	}
+}
+
+func (c *CompiledCache) get(source string, generation uint64) (Expression, bool) {
+	expr, ok := c.lookup(source, generation)
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return expr, ok
+}
+
+// lookup finds the entry of the current generation, the stale one is dropped
+func (c *CompiledCache) lookup(source string, generation uint64) (Expression, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[source]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*compiledCacheEntry)
+	if entry.generation != generation {
+		c.order.Remove(element)
+		delete(c.items, source)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.expr, true
+}
+
+func (c *CompiledCache) add(source string, generation uint64, expr Expression) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[source]; ok {
+		c.order.Remove(element)
+	}
+	c.items[source] = c.order.PushFront(&compiledCacheEntry{source: source, generation: generation, expr: expr})
+	for c.order.Len() > c.maxEntries {
+		entry := c.order.Remove(c.order.Back()).(*compiledCacheEntry)
+		delete(c.items, entry.source)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompiledCache(t *testing.T) {
+	cache := NewCompiledCache(2)
+	first := must(cache.Get(`a + 1`))
+	assert.Same(t, first, must(cache.Get(`a + 1`)))
+	assert.Equal(t, `a+1`, first.String())
+	assert.Equal(t, CompiledCacheStats{Hits: 1, Misses: 1, Compiles: 1, Entries: 1}, cache.Stats())
+
+	_, err := cache.Get(`a +`)
+	assert.Error(t, err)
+	_, err = cache.Get(`a +`)
+	assert.Error(t, err)
+	assert.Equal(t, uint64(3), cache.Stats().Compiles)
+	assert.Equal(t, 1, cache.Stats().Entries)
+}
+
+func TestCompiledCacheEviction(t *testing.T) {
+	cache := NewCompiledCache(2)
+	a := must(cache.Get(`a`))
+	must(cache.Get(`b`))
+	must(cache.Get(`a`))
+	must(cache.Get(`c`))
+
+	assert.Equal(t, 2, cache.Stats().Entries)
+	assert.Same(t, a, must(cache.Get(`a`)))
+	compiles := cache.Stats().Compiles
+	must(cache.Get(`b`))
+	assert.Equal(t, compiles+1, cache.Stats().Compiles)
+}
+
+func TestCompiledCacheGeneration(t *testing.T) {
+	cache := NewCompiledCache(0)
+	first := must(cache.Get(`string([1, "a"])`))
+	assert.Equal(t, `"[1,\"a\"]"`, first.String())
+
+	assert.NoError(t, SetDefaultFeatures(Features{FeatureJSONCompositeStrings: false}))
+	defer SetDefaultFeatures(nil)
+	second := must(cache.Get(`string([1, "a"])`))
+	assert.NotSame(t, first, second)
+	assert.Equal(t, `"1,a"`, second.String())
+
+	BumpCompileGeneration()
+	must(cache.Get(`string([1, "a"])`))
+	assert.Equal(t, uint64(3), cache.Stats().Compiles)
+	assert.Equal(t, 1, cache.Stats().Entries)
+}
+
+func TestCompiledCacheConcurrency(t *testing.T) {
+	cache := NewCompiledCache(0)
+	source := `map(items, "_.value * factor") == list(factor, factor * 2) ? "ok" : string(factor)`
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(factor int) {
+			defer wg.Done()
+			expr, err := cache.Get(source)
+			if err != nil {
+				errs <- err
+				return
+			}
+			m := NewMachine().Register("items", []interface{}{1, 2}).Register("factor", factor)
+			v, err := expr.Resolve(m)
+			if err == nil && v.String() != `"ok"` {
+				err = fmt.Errorf("unexpected result for %d: %s", factor, v)
+			}
+			if err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, uint64(1), cache.Stats().Compiles)
+}
+
+func BenchmarkCompiledCache(b *testing.B) {
+	source := `execution.labels.team == "qa" && len(filter(steps, "_.value.status == \"failed\"")) > 0`
+	b.Run("cached", func(b *testing.B) {
+		cache := NewCompiledCache(0)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := cache.Get(source); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Compile(source); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
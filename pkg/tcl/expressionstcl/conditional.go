@@ -9,6 +9,7 @@
 package expressionstcl
 
 import (
+	"context"
 	"fmt"
 	"maps"
 )
@@ -42,7 +43,15 @@ func (s *conditional) Type() Type {
 }
 
 func (s *conditional) String() string {
-	return fmt.Sprintf("%s ? %s : %s", s.condition.String(), s.truthy.String(), s.falsy.String())
+	return fmt.Sprintf("%s ? %s : %s", conditionalOperand(s.condition), s.truthy.String(), conditionalOperand(s.falsy))
+}
+
+// conditionalOperand wraps the nested conditional in parentheses, so it's parsed back the same way
+func conditionalOperand(expr Expression) string {
+	if _, ok := expr.(*conditional); ok {
+		return expr.SafeString()
+	}
+	return expr.String()
 }
 
 func (s *conditional) SafeString() string {
@@ -54,6 +63,7 @@ func (s *conditional) Template() string {
 }
 
 func (s *conditional) SafeResolve(m ...Machine) (v Expression, changed bool, err error) {
+	s = &conditional{condition: s.condition, truthy: s.truthy, falsy: s.falsy}
 	var ch bool
 	s.condition, ch, err = s.condition.SafeResolve(m...)
 	changed = changed || ch
@@ -71,21 +81,23 @@ func (s *conditional) SafeResolve(m ...Machine) (v Expression, changed bool, err
 		}
 		return s.falsy, true, err
 	}
-	s.truthy, ch, err = s.truthy.SafeResolve(m...)
+	truthy, ch, truthyErr := s.truthy.SafeResolve(m...)
 	changed = changed || ch
-	if err != nil {
-		return nil, changed, err
-	}
-	s.falsy, ch, err = s.falsy.SafeResolve(m...)
+	falsy, ch, falsyErr := s.falsy.SafeResolve(m...)
 	changed = changed || ch
-	if err != nil {
-		return nil, changed, err
+	if truthyErr != nil || falsyErr != nil {
+		return nil, changed, joinErrors(truthyErr, falsyErr)
 	}
+	s.truthy, s.falsy = truthy, falsy
 	return s, changed, nil
 }
 
 func (s *conditional) Resolve(m ...Machine) (v Expression, err error) {
-	return deepResolve(s, m...)
+	return s.ResolveContext(context.Background(), m...)
+}
+
+func (s *conditional) ResolveContext(ctx context.Context, m ...Machine) (v Expression, err error) {
+	return deepResolve(s, withContext(ctx, m)...)
 }
 
 func (s *conditional) Static() StaticValue {
@@ -0,0 +1,161 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ConditionsCombinator tells how the outcomes of the named conditions are combined into the verdict
+type ConditionsCombinator string
+
+const (
+	// ConditionsAll passes when every condition passed, so also when there are no conditions
+	ConditionsAll ConditionsCombinator = "all"
+	// ConditionsAny passes when at least one condition passed
+	ConditionsAny ConditionsCombinator = "any"
+)
+
+// ConditionOutcome is the result of a single named condition
+type ConditionOutcome string
+
+const (
+	ConditionPassed ConditionOutcome = "passed"
+	ConditionFailed ConditionOutcome = "failed"
+	// ConditionError is the outcome of the condition that couldn't be compiled, resolved, or is not a boolean
+	ConditionError ConditionOutcome = "error"
+)
+
+// ConditionResult is the outcome of a single named condition
+type ConditionResult struct {
+	Name       string           `json:"name"`
+	Expression string           `json:"expression"`
+	Outcome    ConditionOutcome `json:"outcome"`
+	// Value is the resolved value, it's set also for the non-boolean result
+	Value interface{} `json:"value,omitempty"`
+	Error string      `json:"error,omitempty"`
+	// Elapsed is the time of compiling and resolving the condition
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// ConditionReport is the outcome of the named conditions, sorted by name, with the verdict under the combinator
+type ConditionReport struct {
+	Combinator ConditionsCombinator `json:"combinator"`
+	Passed     bool                 `json:"passed"`
+	Conditions []ConditionResult    `json:"conditions"`
+}
+
+// Failed returns the names of the conditions that didn't pass, including the erroneous ones
+func (r ConditionReport) Failed() []string {
+	names := make([]string, 0)
+	for _, c := range r.Conditions {
+		if c.Outcome != ConditionPassed {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+type combinatorMachine struct {
+	combinator ConditionsCombinator
+}
+
+// NewCombinatorMachine selects the combinator for EvaluateConditions, i.e. EvaluateConditions(conds, m, NewCombinatorMachine(ConditionsAny)),
+// the conditions are combined with ConditionsAll by default
+func NewCombinatorMachine(combinator ConditionsCombinator) Machine {
+	return &combinatorMachine{combinator: combinator}
+}
+
+func (c *combinatorMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (c *combinatorMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func resolveCombinator(m []Machine) ConditionsCombinator {
+	for i := range m {
+		if c, ok := m[i].(*combinatorMachine); ok {
+			return c.combinator
+		}
+	}
+	return ConditionsAll
+}
+
+// EvaluateConditions compiles and resolves each named condition to a boolean, the conditions failing to do so
+// are reported with the ConditionError outcome, so they don't hide the results of the other ones;
+// the error is returned only for the invalid combinator
+func EvaluateConditions(conds map[string]string, machines ...Machine) (ConditionReport, error) {
+	combinator := resolveCombinator(machines)
+	if combinator != ConditionsAll && combinator != ConditionsAny {
+		return ConditionReport{}, fmt.Errorf("unknown conditions combinator: %q", combinator)
+	}
+
+	names := make([]string, 0, len(conds))
+	for name := range conds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := ConditionReport{Combinator: combinator, Passed: combinator == ConditionsAll, Conditions: make([]ConditionResult, len(names))}
+	for i, name := range names {
+		result := evaluateCondition(name, conds[name], machines)
+		report.Conditions[i] = result
+		if combinator == ConditionsAll && result.Outcome != ConditionPassed {
+			report.Passed = false
+		} else if combinator == ConditionsAny && result.Outcome == ConditionPassed {
+			report.Passed = true
+		}
+	}
+	return report, nil
+}
+
+func evaluateCondition(name, source string, machines []Machine) ConditionResult {
+	result := ConditionResult{Name: name, Expression: source}
+	start := time.Now()
+
+	value, err := resolveCondition(source, machines)
+	if value != nil {
+		result.Value = value.Value()
+	}
+	switch {
+	case err != nil:
+		result.Outcome = ConditionError
+		result.Error = err.Error()
+	case !value.IsBool():
+		result.Outcome = ConditionError
+		result.Error = fmt.Sprintf("condition should resolve to boolean, %s provided", value.String())
+	case value.Value().(bool):
+		result.Outcome = ConditionPassed
+	default:
+		result.Outcome = ConditionFailed
+	}
+	result.Elapsed = time.Since(start)
+	return result
+}
+
+func resolveCondition(source string, machines []Machine) (StaticValue, error) {
+	expr, err := compile(resolveFeatures(machines), source, operatorsMachines(machines)...)
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling")
+	}
+	expr, err = expr.Resolve(machines...)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving")
+	}
+	if expr.Static() == nil {
+		return nil, fmt.Errorf("could not fully resolve: %s", expr.String())
+	}
+	return expr.Static(), nil
+}
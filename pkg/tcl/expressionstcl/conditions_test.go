@@ -0,0 +1,112 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var conditionsMachine = NewMachine().
+	Register("status", "failed").
+	Register("retries", 3)
+
+var mixedConditions = map[string]string{
+	"failed":     `status == "failed"`,
+	"retried":    `retries > 5`,
+	"notBoolean": `retries + 1`,
+	"invalid":    `status ==`,
+	"unknown":    `missing.value`,
+}
+
+func TestEvaluateConditionsAll(t *testing.T) {
+	report, err := EvaluateConditions(mixedConditions, conditionsMachine)
+	assert.NoError(t, err)
+	assert.Equal(t, ConditionsAll, report.Combinator)
+	assert.False(t, report.Passed)
+
+	outcomes := make(map[string]ConditionOutcome)
+	names := make([]string, 0)
+	for _, c := range report.Conditions {
+		outcomes[c.Name] = c.Outcome
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"failed", "invalid", "notBoolean", "retried", "unknown"}, names)
+	assert.Equal(t, map[string]ConditionOutcome{
+		"failed":     ConditionPassed,
+		"retried":    ConditionFailed,
+		"notBoolean": ConditionError,
+		"invalid":    ConditionError,
+		"unknown":    ConditionError,
+	}, outcomes)
+	assert.Equal(t, []string{"invalid", "notBoolean", "retried", "unknown"}, report.Failed())
+
+	assert.Equal(t, true, report.Conditions[0].Value)
+	assert.Empty(t, report.Conditions[0].Error)
+	assert.Contains(t, report.Conditions[1].Error, "compiling")
+	assert.Equal(t, int64(4), report.Conditions[2].Value)
+	assert.Contains(t, report.Conditions[2].Error, "condition should resolve to boolean")
+	assert.Equal(t, false, report.Conditions[3].Value)
+	assert.Contains(t, report.Conditions[4].Error, "could not fully resolve")
+
+	report, err = EvaluateConditions(map[string]string{"a": `true`, "b": `retries == 3`}, conditionsMachine)
+	assert.NoError(t, err)
+	assert.True(t, report.Passed)
+}
+
+func TestEvaluateConditionsAny(t *testing.T) {
+	report, err := EvaluateConditions(mixedConditions, conditionsMachine, NewCombinatorMachine(ConditionsAny))
+	assert.NoError(t, err)
+	assert.Equal(t, ConditionsAny, report.Combinator)
+	assert.True(t, report.Passed)
+
+	report, err = EvaluateConditions(map[string]string{"a": `false`, "b": `retries`}, conditionsMachine, NewCombinatorMachine(ConditionsAny))
+	assert.NoError(t, err)
+	assert.False(t, report.Passed)
+	assert.Equal(t, []string{"a", "b"}, report.Failed())
+}
+
+func TestEvaluateConditionsEmpty(t *testing.T) {
+	report, err := EvaluateConditions(nil)
+	assert.NoError(t, err)
+	assert.True(t, report.Passed)
+
+	report, err = EvaluateConditions(nil, NewCombinatorMachine(ConditionsAny))
+	assert.NoError(t, err)
+	assert.False(t, report.Passed)
+}
+
+func TestEvaluateConditionsUnknownCombinator(t *testing.T) {
+	_, err := EvaluateConditions(mixedConditions, NewCombinatorMachine("none"))
+	assert.ErrorContains(t, err, `unknown conditions combinator: "none"`)
+}
+
+func TestEvaluateConditionsJSON(t *testing.T) {
+	report, err := EvaluateConditions(map[string]string{"ok": `retries == 3`, "bad": `retries + 1`}, conditionsMachine)
+	assert.NoError(t, err)
+	report.Conditions[0].Elapsed = 0
+	report.Conditions[1].Elapsed = 0
+
+	b, err := json.Marshal(report)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"combinator": "all",
+		"passed": false,
+		"conditions": [
+			{"name": "bad", "expression": "retries + 1", "outcome": "error", "value": 4, "error": "condition should resolve to boolean, 4 provided", "elapsed": 0},
+			{"name": "ok", "expression": "retries == 3", "outcome": "passed", "value": true, "elapsed": 0}
+		]
+	}`, string(b))
+
+	var decoded ConditionReport
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, report.Failed(), decoded.Failed())
+}
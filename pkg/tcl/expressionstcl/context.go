@@ -0,0 +1,97 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"context"
+	"errors"
+)
+
+// WithContext passes the context to the functions doing the long-running work, i.e. "jq", so they stop when it's cancelled
+func WithContext(ctx context.Context) ResolveOption {
+	return func(o *resolveOptions) {
+		o.ctx = ctx
+	}
+}
+
+type contextMachine struct {
+	ctx context.Context
+}
+
+// NewContextMachine passes the context to the single resolution, i.e. expr.Resolve(m, NewContextMachine(ctx))
+func NewContextMachine(ctx context.Context) Machine {
+	return &contextMachine{ctx: ctx}
+}
+
+func (c *contextMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (c *contextMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// resolveContext finds the context of the resolution, it's the background context when none is passed
+func resolveContext(m []Machine) context.Context {
+	for i := range m {
+		if c, ok := m[i].(*contextMachine); ok && c.ctx != nil {
+			return c.ctx
+		}
+	}
+	return context.Background()
+}
+
+// withContext passes the context to the resolution, the background context is the default already
+func withContext(ctx context.Context, m []Machine) []Machine {
+	if ctx == nil || ctx == context.Background() {
+		return m
+	}
+	return append([]Machine{NewContextMachine(ctx)}, m...)
+}
+
+// ContextCaller is the machine which functions receive the context of the resolution,
+// so they may stop the long-running work when it's cancelled
+type ContextCaller interface {
+	Machine
+	CallContext(ctx context.Context, name string, args ...StaticValue) (Expression, bool, error)
+}
+
+type contextCallerAdapter struct {
+	Machine
+}
+
+// AsContextCaller adapts the machine to ContextCaller, the functions of the machine not supporting the context ignore it
+func AsContextCaller(m Machine) ContextCaller {
+	if c, ok := m.(ContextCaller); ok {
+		return c
+	}
+	return contextCallerAdapter{Machine: m}
+}
+
+func (c contextCallerAdapter) CallContext(_ context.Context, name string, args ...StaticValue) (Expression, bool, error) {
+	return c.Call(name, args...)
+}
+
+// cancelledError keeps the message of the function stopped by the cancelled context,
+// while it's still recognized as the cancellation, i.e. errors.Is(err, context.Canceled)
+type cancelledError struct {
+	error
+	cause error
+}
+
+func withCancellation(err, cause error) error {
+	if errors.Is(err, cause) {
+		return err
+	}
+	return &cancelledError{error: err, cause: cause}
+}
+
+func (e *cancelledError) Unwrap() []error {
+	return []error{e.error, e.cause}
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type plainMachine struct{}
+
+func (plainMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (plainMachine) Call(name string, _ ...StaticValue) (Expression, bool, error) {
+	if name == "plain" {
+		return NewValue("plain"), true, nil
+	}
+	return nil, false, nil
+}
+
+func TestResolveContextCancelsMap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	machine := NewMachine().RegisterFunction("tick", func(values ...StaticValue) (interface{}, bool, error) {
+		calls++
+		if calls == 100 {
+			cancel()
+		}
+		return values[0], true, nil
+	})
+
+	start := time.Now()
+	_, err := MustCompile(`map(range(100000), "tick(_.value)")`).ResolveContext(ctx, machine)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	assert.Equal(t, 100, calls)
+	assert.Less(t, time.Since(start), time.Second)
+
+	calls = 0
+	_, err = EvalString(`filter(range(100000), "tick(_.value) > 0")`, nil, WithMachines(machine), WithContext(ctx))
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	assert.Equal(t, 0, calls)
+
+	_, err = MustCompile(`eval(expr)`).ResolveContext(ctx, machine, NewMachine().Register("expr", "tick(1)"))
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
+
+func TestResolveContextMachines(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	machine := NewMachine().RegisterFunctionContext("hasDeadline", func(ctx context.Context, _ ...StaticValue) (interface{}, bool, error) {
+		_, ok := ctx.Deadline()
+		return ok, true, nil
+	})
+
+	v, err := MustCompile(`hasDeadline()`).ResolveContext(ctx, machine)
+	assert.NoError(t, err)
+	assert.Equal(t, "true", v.String())
+	v, err = MustCompile(`hasDeadline()`).ResolveContext(ctx, NewSecretMachine(CombinedMachines(plainMachine{}, machine)))
+	assert.NoError(t, err)
+	assert.Equal(t, "true", v.String())
+	v, err = MustCompile(`hasDeadline()`).Resolve(machine)
+	assert.NoError(t, err)
+	assert.Equal(t, "false", v.String())
+
+	// The machines without the context support keep working
+	v, err = MustCompile(`plain() + string(hasDeadline())`).ResolveContext(ctx, plainMachine{}, machine)
+	assert.NoError(t, err)
+	assert.Equal(t, `"plaintrue"`, v.String())
+	v, _, err = AsContextCaller(plainMachine{}).CallContext(ctx, "plain")
+	assert.NoError(t, err)
+	assert.Equal(t, `"plain"`, v.String())
+}
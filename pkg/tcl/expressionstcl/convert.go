@@ -9,6 +9,7 @@
 package expressionstcl
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -16,7 +17,16 @@ import (
 	"strings"
 )
 
+// toString converts value to string, it's shared by "string" and "join" functions and string casts.
+// None is an empty string, scalars are formatted as they are, while lists, maps and structs
+// are rendered as compact JSON with sorted keys.
 func toString(s interface{}) (string, error) {
+	return toStringWith(nil, s)
+}
+
+// toStringWith converts value to string like toString, lists are joined with comma
+// when FeatureJSONCompositeStrings is disabled
+func toStringWith(features Features, s interface{}) (string, error) {
 	// Fast track
 	v, ok := s.(string)
 	if ok {
@@ -29,12 +39,12 @@ func toString(s interface{}) (string, error) {
 	if isNumber(s) {
 		return fmt.Sprintf("%v", s), nil
 	}
-	if isSlice(s) {
+	if isSlice(s) && !features.Enabled(FeatureJSONCompositeStrings) {
 		var err error
 		value := reflect.ValueOf(s)
 		results := make([]string, value.Len())
 		for i := 0; i < value.Len(); i++ {
-			results[i], err = toString(value.Index(i).Interface())
+			results[i], err = toStringWith(features, value.Index(i).Interface())
 			if err != nil {
 				err = fmt.Errorf("error while converting '%v' slice item: %v", value.Index(i), err)
 				return "", err
@@ -42,14 +52,24 @@ func toString(s interface{}) (string, error) {
 		}
 		return strings.Join(results, ","), nil
 	}
-	b, err := json.Marshal(s)
-	if err != nil {
-		return "", fmt.Errorf("error while converting '%v' map to JSON: %v", s, err)
-	}
-	r := string(b)
+	return toJSONString(s)
+}
+
+// toJSONString renders value as compact JSON, without escaping HTML characters
+func toJSONString(s interface{}) (string, error) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return "", fmt.Errorf("error while converting '%v' to JSON: %v", s, err)
+	}
+	r := strings.TrimSuffix(b.String(), "\n")
 	if isMap(s) && r == "null" {
 		return "{}", nil
 	}
+	if isSlice(s) && r == "null" {
+		return "[]", nil
+	}
 	return r, nil
 }
 
@@ -84,6 +104,12 @@ func toInt(s interface{}) (int64, error) {
 	if isNone(s) {
 		return 0, nil
 	}
+	// Integer strings are parsed exactly, without the float64 rounding
+	if str, ok := s.(string); ok {
+		if v, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64); err == nil {
+			return v, nil
+		}
+	}
 	// Convert
 	v, err := toFloat(s)
 	return int64(v), err
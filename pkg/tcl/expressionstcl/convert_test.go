@@ -0,0 +1,176 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToString(t *testing.T) {
+	type item struct {
+		Name  string `json:"name"`
+		Value int    `json:"value,omitempty"`
+	}
+
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{noneValue, ""},
+		{"text", "text"},
+		{"", ""},
+		{true, "true"},
+		{false, "false"},
+		{int64(10), "10"},
+		{int(-3), "-3"},
+		{1.5, "1.5"},
+		{float64(2), "2"},
+		{[]string{"a", "b"}, `["a","b"]`},
+		{[]string(nil), `[]`},
+		{[]interface{}{}, `[]`},
+		{[]interface{}{"a", int64(10), []interface{}{"a", 4}, nil}, `["a",10,["a",4],null]`},
+		{map[string]interface{}{"z": 1, "a": map[string]interface{}{"y": true, "b": nil}}, `{"a":{"b":null,"y":true},"z":1}`},
+		{map[string]string(nil), `{}`},
+		{map[string]interface{}{"html": "<a & b>"}, `{"html":"<a & b>"}`},
+		{item{Name: "x"}, `{"name":"x"}`},
+		{[]item{{Name: "x", Value: 1}}, `[{"name":"x","value":1}]`},
+	}
+	for _, tt := range tests {
+		v, err := toString(tt.value)
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, tt.expected, v, tt.value)
+	}
+}
+
+func TestStringConversionShared(t *testing.T) {
+	assert.Equal(t, `"{\"a\":1,\"b\":[1,2]}"`, MustCompile(`string({"b": [1, 2], "a": 1})`).String())
+	assert.Equal(t, `"[\"a\",\"b\"]"`, MustCompile(`string(["a", "b"])`).String())
+	assert.Equal(t, `""`, MustCompile(`string(null)`).String())
+	assert.Equal(t, `"list: [1,2]"`, MustCompile(`"list: " + [1, 2]`).String())
+	assert.Equal(t, `"{\"a\":1}|[2]"`, MustCompile(`join([{"a": 1}, [2]], "|")`).String())
+
+	v, err := EvalString(`"items: " + items`, map[string]interface{}{"items": []string{"x", "y"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `items: ["x","y"]`, v)
+
+	expr, err := CompileTemplate(`labels={{labels}}`)
+	assert.NoError(t, err)
+	expr, err = expr.Resolve(NewMachine().Register("labels", map[string]string{"app": "api"}))
+	assert.NoError(t, err)
+	assert.Equal(t, `"labels={\"app\":\"api\"}"`, expr.String())
+}
+
+func TestStringConversionWithoutJSONCompositeStrings(t *testing.T) {
+	features := Features{FeatureJSONCompositeStrings: false}
+	compile := func(expr string) string {
+		v, err := CompileWithFeatures(expr, features)
+		assert.NoError(t, err)
+		return v.String()
+	}
+
+	assert.Equal(t, `"a,10,a,4"`, compile(`join(["a",10,["a",4]])`))
+	assert.Equal(t, `"a,b"`, compile(`string(["a","b"])`))
+	assert.Equal(t, `"x:1,2"`, compile(`"x:" + [1,2]`))
+	assert.Equal(t, `"{\"a\":1}"`, compile(`string({"a": 1})`))
+	assert.Equal(t, `"[1,2]"`, MustCompile(`string([1,2])`).String())
+
+	defer defaultFeatures.Store(nil)
+	assert.NoError(t, SetDefaultFeatures(features))
+	assert.Equal(t, "a,b", must(NewValue([]string{"a", "b"}).StringValue()))
+	assert.Equal(t, "", must(NewValue([]string{}).StringValue()))
+}
+
+func TestCastHelpers(t *testing.T) {
+	unresolved := MustCompile(`value`)
+	tests := map[string]struct {
+		cast     func(Expression) Expression
+		expr     Expression
+		expected string
+	}{
+		"string static":        {CastToString, NewValue(int64(10)), `"10"`},
+		"string none":          {CastToString, None, `""`},
+		"string nil":           {CastToString, NewValue(nil), `""`},
+		"string typed":         {CastToString, MustCompile(`string(value)`), `string(value)`},
+		"string unresolved":    {CastToString, unresolved, `string(value)`},
+		"bool static":          {CastToBool, NewValue("off"), `false`},
+		"bool typed":           {CastToBool, MustCompile(`bool(value)`), `bool(value)`},
+		"bool unresolved":      {CastToBool, unresolved, `bool(value)`},
+		"int static":           {CastToInt, NewValue("12"), `12`},
+		"int invalid static":   {CastToInt, NewValue("abc"), `int("abc")`},
+		"int typed":            {CastToInt, MustCompile(`int(value)`), `int(value)`},
+		"int unresolved":       {CastToInt, unresolved, `int(value)`},
+		"float static":         {CastToFloat, NewValue("1.5"), `1.5`},
+		"float typed":          {CastToFloat, MustCompile(`float(value)`), `float(value)`},
+		"float unresolved":     {CastToFloat, unresolved, `float(value)`},
+		"slice static":         {CastToSlice, NewValue([]interface{}{"a"}), `["a"]`},
+		"slice typed static":   {CastToSlice, NewValue([]string{"a", "b"}), `["a","b"]`},
+		"slice scalar":         {CastToSlice, NewValue("a"), `["a"]`},
+		"slice JSON string":    {CastToSlice, NewValue(` [1, "b"]`), `[1,"b"]`},
+		"slice invalid JSON":   {CastToSlice, NewValue(`[1,`), `toList("[1,")`},
+		"slice none":           {CastToSlice, None, `[]`},
+		"slice map":            {CastToSlice, NewValue(map[string]interface{}{"a": 1}), `toList({"a":1})`},
+		"slice unresolved":     {CastToSlice, unresolved, `toList(value)`},
+		"map static":           {CastToMap, NewValue(map[string]interface{}{"a": 1}), `{"a":1}`},
+		"map JSON string":      {CastToMap, NewValue(`{"a": [1]}`), `{"a":[1]}`},
+		"map list":             {CastToMap, NewValue([]interface{}{"x"}), `{"0":"x"}`},
+		"map none":             {CastToMap, None, `{}`},
+		"map scalar":           {CastToMap, NewValue("a"), `toMap("a")`},
+		"map JSON list string": {CastToMap, NewValue(`["a"]`), `toMap("[\"a\"]")`},
+		"map unresolved":       {CastToMap, unresolved, `toMap(value)`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.cast(tt.expr).String())
+		})
+	}
+
+	// The expressions of the same type, and the static lists and maps, are not wrapped
+	typed := MustCompile(`float(value)`)
+	assert.Same(t, typed, CastToFloat(typed))
+	list := NewValue([]interface{}{1})
+	assert.Same(t, list, CastToSlice(list))
+}
+
+func TestCastHelpersResolve(t *testing.T) {
+	resolve := func(cast func(Expression) Expression, value interface{}) (string, error) {
+		v, err := cast(MustCompile(`value`)).Resolve(NewMachine().Register("value", value))
+		if err != nil {
+			return "", err
+		}
+		return v.String(), nil
+	}
+
+	tests := []struct {
+		cast     func(Expression) Expression
+		value    interface{}
+		expected string
+	}{
+		{CastToFloat, "1.5", `1.5`},
+		{CastToInt, "1.5", `1`},
+		{CastToString, nil, `""`},
+		{CastToString, noneValue, `""`},
+		{CastToSlice, `["x"]`, `["x"]`},
+		{CastToSlice, int64(3), `[3]`},
+		{CastToSlice, noneValue, `[]`},
+		{CastToMap, `{"a": "b"}`, `{"a":"b"}`},
+		{CastToMap, nil, `{}`},
+	}
+	for _, tt := range tests {
+		v, err := resolve(tt.cast, tt.value)
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, tt.expected, v, tt.value)
+	}
+
+	_, err := resolve(CastToMap, "a")
+	assert.ErrorContains(t, err, `"toMap" function: value can't be converted to map: "a"`)
+	_, err = resolve(CastToSlice, `[1,`)
+	assert.ErrorContains(t, err, `"toList" function: invalid JSON`)
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const defaultCSVDelimiter = ","
+
+// csvDelimiter validates the delimiter, it has to be a single character that can't be a part of the quoted field
+func csvDelimiter(str string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(str)
+	if size == 0 || size != len(str) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("delimiter should be a single character other than quote or new line, %q provided", str)
+	}
+	return r, nil
+}
+
+// csvError points to the line where the parsing failed
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("line %d: %v", parseErr.Line, parseErr.Err)
+	}
+	return err
+}
+
+// parseCSV reads the records into the maps keyed by the header row,
+// the quoted fields may contain the delimiter, quotes doubled, and new lines
+func parseCSV(str string, delimiter rune) ([]interface{}, error) {
+	r := csv.NewReader(strings.NewReader(str))
+	r.Comma = delimiter
+	header, err := r.Read()
+	if err == io.EOF {
+		return []interface{}{}, nil
+	} else if err != nil {
+		return nil, csvError(err)
+	}
+	seen := make(map[string]struct{}, len(header))
+	for _, name := range header {
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("line 1: duplicate column %q", name)
+		}
+		seen[name] = struct{}{}
+	}
+
+	result := make([]interface{}, 0)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, csvError(err)
+		}
+		item := make(map[string]interface{}, len(header))
+		for i := range header {
+			item[header[i]] = record[i]
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// buildCSV writes the maps as the records, the columns are the sorted keys of all the maps,
+// and the missing values are empty
+func buildCSV(list []interface{}, delimiter rune) (string, error) {
+	items := make([]map[string]interface{}, len(list))
+	columns := make([]string, 0)
+	seen := make(map[string]struct{})
+	for i := range list {
+		if !isMap(list[i]) && !isStruct(list[i]) {
+			return "", fmt.Errorf("item at %d index should be a map, %s provided", i, NewValue(list[i]))
+		}
+		items[i], _ = toMap(list[i])
+		for k := range items[i] {
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				columns = append(columns, k)
+			}
+		}
+	}
+	if len(items) == 0 {
+		return "", nil
+	}
+	sort.Strings(columns)
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Comma = delimiter
+	_ = w.Write(columns)
+	record := make([]string, len(columns))
+	for i := range items {
+		for j, column := range columns {
+			v, ok := items[i][column]
+			if !ok || isNone(v) || v == nil {
+				record[j] = ""
+				continue
+			}
+			str, err := toString(v)
+			if err != nil {
+				return "", fmt.Errorf("item at %d index: column %q: %v", i, column, err)
+			}
+			record[j] = str
+		}
+		_ = w.Write(record)
+	}
+	w.Flush()
+	return b.String(), w.Error()
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	assert.Equal(t, `[{"age":"30","name":"Alice"},{"age":"25","name":"Bob"}]`, MustCompile(`csv("name,age\nAlice,30\nBob,25\n")`).String())
+	assert.Equal(t, `[{"a":"1","b":"2"}]`, MustCompile(`csv("a;b\n1;2", ";")`).String())
+	assert.Equal(t, `[{"a":"1","b":"2"}]`, MustCompile(`csv("a\tb\n1\t2", "\t")`).String())
+	assert.Equal(t, `[]`, MustCompile(`csv("")`).String())
+	assert.Equal(t, `[]`, MustCompile(`csv("a,b")`).String())
+	assert.Equal(t, `"Bob"`, MustCompile(`at(csv("name,age\nAlice,30\nBob,25"), 1).name`).String())
+}
+
+func TestParseCSVQuoted(t *testing.T) {
+	v, err := parseCSV("name,note\n\"Doe, John\",\"multi\nline \"\"quoted\"\"\"\nJane,plain\n", ',')
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "Doe, John", "note": "multi\nline \"quoted\""},
+		map[string]interface{}{"name": "Jane", "note": "plain"},
+	}, v)
+
+	v, err = parseCSV("a;b\n\"1;2\";3\n", ';')
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"a": "1;2", "b": "3"}}, v)
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	_, err := Compile(`csv("a,b\n1,2\n3\n")`)
+	assert.ErrorContains(t, err, `"csv" function: line 3: wrong number of fields`)
+	_, err = Compile(`csv("a,b\n1,\"2\n3,4\n")`)
+	assert.ErrorContains(t, err, `"csv" function: line 3: extraneous or missing " in quoted-field`)
+	_, err = Compile(`csv("a,b\n1,x\"y\n")`)
+	assert.ErrorContains(t, err, `"csv" function: line 2: bare " in non-quoted-field`)
+	_, err = Compile(`csv("a,a\n1,2")`)
+	assert.ErrorContains(t, err, `"csv" function: line 1: duplicate column "a"`)
+	_, err = Compile(`csv("a,b", ",,")`)
+	assert.ErrorContains(t, err, `"csv" function: delimiter should be a single character`)
+}
+
+func TestBuildCSV(t *testing.T) {
+	assert.Equal(t, `"age,name\n30,Alice\n,Bob\n"`, MustCompile(`tocsv([{"name": "Alice", "age": 30}, {"name": "Bob"}])`).String())
+	assert.Equal(t, `"a;b\n\"1;2\";3\n"`, MustCompile(`tocsv([{"b": 3, "a": "1;2"}], ";")`).String())
+	assert.Equal(t, `"note\n\"multi\nline \"\"quoted\"\"\"\n"`, MustCompile(`tocsv([{"note": "multi\nline \"quoted\""}])`).String())
+	assert.Equal(t, `"a,b\n,true\n"`, MustCompile(`tocsv([{"a": null, "b": true}])`).String())
+	assert.Equal(t, `""`, MustCompile(`tocsv([])`).String())
+
+	_, err := Compile(`tocsv("a,b")`)
+	assert.ErrorContains(t, err, `"tocsv" function expects a list of maps`)
+	_, err = Compile(`tocsv([{"a": 1}, 2])`)
+	assert.ErrorContains(t, err, `"tocsv" function: item at 1 index should be a map, 2 provided`)
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	str := "name,note\n\"Doe, John\",\"multi\nline \"\"quoted\"\"\"\nJane,plain\n"
+	v, err := parseCSV(str, ',')
+	assert.NoError(t, err)
+	result, err := buildCSV(v, ',')
+	assert.NoError(t, err)
+	assert.Equal(t, str, result)
+}
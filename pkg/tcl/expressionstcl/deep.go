@@ -0,0 +1,158 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// errResolutionCycle is reported by resolveDeep() when the embedded template references itself
+var errResolutionCycle = errors.New("resolution cycle")
+
+// deepResolver resolves the templates embedded in the string values of the maps and lists,
+// the stack holds the templates being resolved, so the template referencing itself is detected
+type deepResolver struct {
+	features Features
+	machines []Machine
+	stack    []string
+}
+
+// resolveDeep rebuilds the value with the embedded templates resolved with the machines of the resolution,
+// the values served by the machines to these templates are resolved deeply too; the \{{ is the literal {{,
+// and errNotResolvedYet is returned when any template can't be resolved with the current machines
+func resolveDeep(machines []Machine, value StaticValue) (Expression, error) {
+	r := &deepResolver{features: resolveFeatures(machines), machines: machines}
+	v, err := r.value(value.Value())
+	if err != nil {
+		return nil, err
+	}
+	if isSecretValue(value) {
+		return taintSecret(NewValue(v)), nil
+	}
+	return NewValue(v), nil
+}
+
+func (r *deepResolver) value(v interface{}) (interface{}, error) {
+	switch {
+	case isString(v):
+		return r.template(v.(string))
+	case isMap(v):
+		m, err := toMap(v)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[string]interface{}, len(m))
+		for k := range m {
+			if result[k], err = r.value(m[k]); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case isSlice(v):
+		s, err := toSlice(v)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]interface{}, len(s))
+		for i := range s {
+			if result[i], err = r.value(s[i]); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return v, nil
+}
+
+func (r *deepResolver) template(tpl string) (interface{}, error) {
+	if !strings.Contains(tpl, "{{") {
+		return tpl, nil
+	}
+	if slices.Contains(r.stack, tpl) {
+		return nil, fmt.Errorf("%w: %q references itself", errResolutionCycle, tpl)
+	}
+	if err := resolveContext(r.machines).Err(); err != nil {
+		return nil, err
+	}
+
+	r.stack = append(r.stack, tpl)
+	defer func() {
+		r.stack = r.stack[:len(r.stack)-1]
+	}()
+
+	expr, err := compileTemplate(r.features, tpl)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %v", tpl, err)
+	}
+	// The deep machine goes first, so the accessors are served with the values resolved deeply
+	expr, err = expr.Resolve(append([]Machine{&deepMachine{resolver: r}}, r.machines...)...)
+	if err != nil {
+		return nil, err
+	}
+	if expr.Static() == nil {
+		return nil, errNotResolvedYet
+	}
+	return expr.Static().Value(), nil
+}
+
+// deepMachine serves the accessors of the resolution machines with the embedded templates resolved,
+// the accessor not served directly is read from its parent, so only the accessed part is resolved
+type deepMachine struct {
+	resolver *deepResolver
+}
+
+func (d *deepMachine) Get(name string) (Expression, bool, error) {
+	segments := strings.Split(name, ".")
+	for i := len(segments); i > 0; i-- {
+		for _, m := range d.resolver.machines {
+			result, ok, err := m.Get(strings.Join(segments[:i], "."))
+			if err != nil {
+				return nil, false, err
+			}
+			if !ok {
+				continue
+			}
+			if result.Static() == nil {
+				return nil, false, nil
+			}
+			return d.get(result.Static(), segments[i:])
+		}
+	}
+	return nil, false, nil
+}
+
+// get reads the path from the value served by the machine and resolves it deeply
+func (d *deepMachine) get(value StaticValue, path []string) (Expression, bool, error) {
+	var current Expression = value
+	for _, key := range path {
+		next, err := CallStdFunction("at", current, key)
+		if err != nil {
+			return nil, false, err
+		}
+		current = next
+	}
+
+	v, err := d.resolver.value(current.Static().Value())
+	if errors.Is(err, errNotResolvedYet) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	if isSecretValue(value) {
+		return taintSecret(NewValue(v)), true, nil
+	}
+	return NewValue(v), true, nil
+}
+
+func (d *deepMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDeep(t *testing.T) {
+	machine := NewMachine().
+		Register("config", map[string]interface{}{
+			"cmd":  "{{ env.BIN }} --verbose",
+			"args": []interface{}{"{{ config.cmd }}", 5, "--name={{ upper(name) }}"},
+			"raw":  "\\{{ not.resolved }}",
+		}).
+		Register("name", "test")
+	env := NewEnvMachine(map[string]string{"BIN": "/usr/bin/k6"})
+
+	v, err := EvalExpression(`config.cmd`, machine, env)
+	require.NoError(t, err)
+	assert.Equal(t, "{{ env.BIN }} --verbose", v.Value())
+
+	v, err = EvalExpression(`resolveDeep(config.cmd)`, machine, env)
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/bin/k6 --verbose", v.Value())
+
+	v, err = EvalExpression(`resolveDeep(config)`, machine, env)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"cmd":  "/usr/bin/k6 --verbose",
+		"args": []interface{}{"/usr/bin/k6 --verbose", 5, "--name=TEST"},
+		"raw":  "{{ not.resolved }}",
+	}, v.Value())
+}
+
+func TestResolveDeepDeferred(t *testing.T) {
+	machine := NewMachine().Register("config", map[string]interface{}{"cmd": "{{ env.BIN }} --verbose"})
+
+	expr, err := MustCompile(`resolveDeep(config.cmd)`).Resolve(machine)
+	require.NoError(t, err)
+	assert.Equal(t, `resolveDeep("{{ env.BIN }} --verbose")`, expr.String())
+
+	expr, err = expr.Resolve(NewEnvMachine(map[string]string{"BIN": "k6"}))
+	require.NoError(t, err)
+	assert.Equal(t, `"k6 --verbose"`, expr.String())
+}
+
+func TestResolveDeepCycle(t *testing.T) {
+	machine := NewMachine().Register("config", map[string]interface{}{
+		"a": "{{ config.b }}",
+		"b": "x-{{ config.a }}",
+		"c": "{{ config.c }}",
+	})
+
+	_, err := EvalExpression(`resolveDeep(config.a)`, machine)
+	assert.ErrorContains(t, err, `resolution cycle: "{{ config.b }}" references itself`)
+
+	_, err = EvalExpression(`resolveDeep(config.c)`, machine)
+	assert.ErrorContains(t, err, `resolution cycle: "{{ config.c }}" references itself`)
+
+	_, err = EvalExpression(`resolveDeep(config)`, machine)
+	assert.ErrorContains(t, err, "resolution cycle")
+}
+
+func TestResolveDeepLiterals(t *testing.T) {
+	v, err := EvalExpression(`resolveDeep([1, true, "plain", null])`)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{float64(1), true, "plain", nil}, v.Value())
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefinitionsPrefix is the prefix of the accessors exposing the definitions, see ResolveWithDefinitions
+const DefinitionsPrefix = "def."
+
+var definitionNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z\d_]*$`)
+
+// ResolveWithDefinitions resolves the expression with the named sub-expressions available as "def.<name>" accessors,
+// both to the expression and to the other definitions; each definition is compiled once, and evaluated lazily,
+// at most once per resolution, so the unreferenced definitions are never evaluated;
+// the definitions referring to each other in the cycle fail with the error naming the chain
+func ResolveWithDefinitions(expr Expression, defs map[string]string, machines ...Machine) (Expression, error) {
+	compiled := make(map[string]Expression, len(defs))
+	for name, source := range defs {
+		if !definitionNameRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid definition name: %q", name)
+		}
+		def, err := Compile(source)
+		if err != nil {
+			return nil, fmt.Errorf("compiling definition %s%s: %v", DefinitionsPrefix, name, err)
+		}
+		compiled[name] = def
+	}
+
+	definitions := &definitionsMachine{
+		defs:     compiled,
+		values:   make(map[string]Expression, len(compiled)),
+		machines: machines,
+	}
+	return expr.Resolve(append([]Machine{definitions}, machines...)...)
+}
+
+// definitionsMachine evaluates the definitions on the first access, and keeps the results for the resolution
+type definitionsMachine struct {
+	defs     map[string]Expression
+	values   map[string]Expression
+	machines []Machine
+
+	// chain is the list of definitions being evaluated, to detect the cycles
+	chain []string
+}
+
+func (d *definitionsMachine) Get(name string) (Expression, bool, error) {
+	key, ok := strings.CutPrefix(name, DefinitionsPrefix)
+	if !ok {
+		return nil, false, nil
+	}
+	def, ok := d.defs[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if v, ok := d.values[key]; ok {
+		return v, true, nil
+	}
+
+	if i := slices.Index(d.chain, key); i != -1 {
+		chain := append(slices.Clone(d.chain[i:]), key)
+		for j := range chain {
+			chain[j] = DefinitionsPrefix + chain[j]
+		}
+		return nil, true, fmt.Errorf("definitions cycle: %s", strings.Join(chain, " -> "))
+	}
+	d.chain = append(d.chain, key)
+	v, err := def.Resolve(append([]Machine{d}, d.machines...)...)
+	d.chain = d.chain[:len(d.chain)-1]
+	if err != nil {
+		return nil, true, err
+	}
+	d.values[key] = v
+	return v, true, nil
+}
+
+func (d *definitionsMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (d *definitionsMachine) ServesAccessor(name string) (bool, bool) {
+	key, ok := strings.CutPrefix(name, DefinitionsPrefix)
+	if !ok {
+		return false, true
+	}
+	_, ok = d.defs[key]
+	return ok, true
+}
+
+func (d *definitionsMachine) ServesFunction(_ string) (bool, bool) {
+	return false, true
+}
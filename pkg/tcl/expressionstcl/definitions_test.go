@@ -0,0 +1,92 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testsMachine() Machine {
+	return NewMachineFromMap(map[string]interface{}{
+		"tests": []interface{}{
+			map[string]interface{}{"name": "login", "status": "failed"},
+			map[string]interface{}{"name": "logout", "status": "passed"},
+			map[string]interface{}{"name": "signup", "status": "failed"},
+		},
+	})
+}
+
+func TestResolveWithDefinitions(t *testing.T) {
+	defs := map[string]string{
+		"failed":      `filter(tests, "_.value.status == \"failed\"")`,
+		"failedNames": `map(def.failed, "_.value.name")`,
+		"summary":     `string(len(def.failed)) + " failed: " + join(def.failedNames, ", ")`,
+	}
+
+	v, err := ResolveWithDefinitions(MustCompile(`def.summary`), defs, testsMachine())
+	assert.NoError(t, err)
+	assert.Equal(t, `"2 failed: login, signup"`, v.String())
+
+	v, err = ResolveWithDefinitions(MustCompile(`def.failedNames.1 + " of " + string(len(tests))`), defs, testsMachine())
+	assert.NoError(t, err)
+	assert.Equal(t, `"signup of 3"`, v.String())
+
+	// The unknown definitions are left unresolved, like the other accessors
+	v, err = ResolveWithDefinitions(MustCompile(`def.other`), defs, testsMachine())
+	assert.NoError(t, err)
+	assert.Equal(t, `def.other`, v.String())
+}
+
+func TestResolveWithDefinitionsLazy(t *testing.T) {
+	calls := map[string]int{}
+	machine := NewMachine().RegisterFunction("count", func(values ...StaticValue) (interface{}, bool, error) {
+		name, _ := values[0].StringValue()
+		calls[name]++
+		return calls[name], true, nil
+	})
+	defs := map[string]string{
+		"used":   `count("used")`,
+		"unused": `count("unused")`,
+	}
+
+	v, err := ResolveWithDefinitions(MustCompile(`def.used + def.used + len(map([1, 2, 3], "def.used"))`), defs, machine)
+	assert.NoError(t, err)
+	assert.Equal(t, "5", v.String())
+	assert.Equal(t, map[string]int{"used": 1}, calls)
+
+	// Each resolution evaluates the definitions again
+	_, err = ResolveWithDefinitions(MustCompile(`def.used`), defs, machine)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"used": 2}, calls)
+}
+
+func TestResolveWithDefinitionsCycle(t *testing.T) {
+	defs := map[string]string{
+		"a":    `def.b + 1`,
+		"b":    `def.c + 1`,
+		"c":    `def.a + 1`,
+		"self": `def.self`,
+	}
+
+	_, err := ResolveWithDefinitions(MustCompile(`def.a`), defs)
+	assert.ErrorContains(t, err, "definitions cycle: def.a -> def.b -> def.c -> def.a")
+
+	_, err = ResolveWithDefinitions(MustCompile(`1 + def.self`), defs)
+	assert.ErrorContains(t, err, "definitions cycle: def.self -> def.self")
+}
+
+func TestResolveWithDefinitionsInvalid(t *testing.T) {
+	_, err := ResolveWithDefinitions(MustCompile(`1`), map[string]string{"failed.names": `1`})
+	assert.EqualError(t, err, `invalid definition name: "failed.names"`)
+
+	_, err = ResolveWithDefinitions(MustCompile(`1`), map[string]string{"broken": `1 +`})
+	assert.ErrorContains(t, err, "compiling definition def.broken")
+}
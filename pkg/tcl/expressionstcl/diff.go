@@ -0,0 +1,239 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffMaxLines is a maximum number of lines of each input compared by "diffText" and "diffStat",
+// the rest is truncated with a notice, as the comparison cost grows with a product of both lengths
+var DiffMaxLines = 1000
+
+const defaultDiffContext = 3
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// diffLines splits the text to lines, trailing new line is not producing an empty line
+func diffLines(text string) (lines []string, truncated bool) {
+	if text == "" {
+		return nil, false
+	}
+	lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if DiffMaxLines > 0 && len(lines) > DiffMaxLines {
+		return lines[:DiffMaxLines], true
+	}
+	return lines, false
+}
+
+// diffOps builds the shortest edit script from the longest common subsequence of both inputs
+func diffOps(a, b []string) []diffOp {
+	// Skip common prefix and suffix to reduce the compared area
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i*(len(mb)+1)+j] is a length of the longest common subsequence of ma[i:] and mb[j:]
+	width := len(mb) + 1
+	lcs := make([]int32, (len(ma)+1)*width)
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{kind: ' ', line: line})
+	}
+	i, j := 0, 0
+	for i < len(ma) || j < len(mb) {
+		switch {
+		case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+			ops = append(ops, diffOp{kind: ' ', line: ma[i]})
+			i++
+			j++
+		case j < len(mb) && (i == len(ma) || lcs[i*width+j+1] > lcs[(i+1)*width+j]):
+			ops = append(ops, diffOp{kind: '+', line: mb[j]})
+			j++
+		default:
+			ops = append(ops, diffOp{kind: '-', line: ma[i]})
+			i++
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{kind: ' ', line: line})
+	}
+	return ops
+}
+
+// diffRange formats the hunk range the same way as GNU diff, empty range points to the preceding line
+func diffRange(start, length int) string {
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// diffText builds unified diff of both texts, it's empty when there is no change
+func diffText(oldText, newText string, context int) string {
+	a, truncatedA := diffLines(oldText)
+	b, truncatedB := diffLines(newText)
+	ops := diffOps(a, b)
+
+	var sb strings.Builder
+	oldLine, newLine := 0, 0
+	for start := 0; start < len(ops); {
+		// Find next change
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// Extend the hunk until there are more unchanged lines than fit in both contexts
+		last := first
+		for k := first; k < len(ops) && k-last-1 <= 2*context; k++ {
+			if ops[k].kind != ' ' {
+				last = k
+			}
+		}
+		from := max(start, first-context)
+		to := min(len(ops), last+context+1)
+
+		oldLine += from - start
+		newLine += from - start
+		oldCount, newCount := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+
+		if sb.Len() == 0 {
+			sb.WriteString("--- old\n+++ new\n")
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", diffRange(oldLine, oldCount), diffRange(newLine, newCount))
+		for _, op := range ops[from:to] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			sb.WriteByte('\n')
+		}
+		oldLine += oldCount
+		newLine += newCount
+		start = to
+	}
+
+	if truncatedA || truncatedB {
+		fmt.Fprintf(&sb, "... input truncated to first %d lines\n", DiffMaxLines)
+	}
+	return sb.String()
+}
+
+// diffStat counts lines added and removed between both texts
+func diffStat(oldText, newText string) map[string]interface{} {
+	a, _ := diffLines(oldText)
+	b, _ := diffLines(newText)
+	added, removed := int64(0), int64(0)
+	for _, op := range diffOps(a, b) {
+		switch op.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return map[string]interface{}{"added": added, "removed": removed}
+}
+
+// ChangedPaths lists the dotted paths of the values that differ between both values, the maps are compared key by key,
+// and the lists item by item, while the other values are compared by their JSON form; the missing values are changed too
+func ChangedPaths(oldValue, newValue interface{}) []string {
+	paths := make([]string, 0)
+	appendChangedPaths(&paths, "", oldValue, true, newValue, true)
+	return paths
+}
+
+func appendChangedPaths(paths *[]string, path string, a interface{}, aOk bool, b interface{}, bOk bool) {
+	if aOk != bOk {
+		*paths = append(*paths, path)
+		return
+	}
+	if isMap(a) && isMap(b) {
+		am, _ := toMap(a)
+		bm, _ := toMap(b)
+		keys := make([]string, 0, len(am)+len(bm))
+		for k := range am {
+			keys = append(keys, k)
+		}
+		for k := range bm {
+			if _, ok := am[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			av, aOk := am[k]
+			bv, bOk := bm[k]
+			appendChangedPaths(paths, joinPath(path, k), av, aOk, bv, bOk)
+		}
+		return
+	}
+	if isSlice(a) && isSlice(b) {
+		as, _ := toSlice(a)
+		bs, _ := toSlice(b)
+		for i := 0; i < len(as) || i < len(bs); i++ {
+			var av, bv interface{}
+			if i < len(as) {
+				av = as[i]
+			}
+			if i < len(bs) {
+				bv = bs[i]
+			}
+			appendChangedPaths(paths, joinPath(path, strconv.Itoa(i)), av, i < len(as), bv, i < len(bs))
+		}
+		return
+	}
+	ak, _ := itemKey(a)
+	bk, _ := itemKey(b)
+	if ak != bk {
+		*paths = append(*paths, path)
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
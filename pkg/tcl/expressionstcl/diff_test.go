@@ -0,0 +1,178 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffText(t *testing.T) {
+	tests := map[string]struct {
+		old, new string
+		context  int
+		expected string
+	}{
+		"no change": {
+			old: "a\nb\nc\n", new: "a\nb\nc\n", context: 3,
+			expected: "",
+		},
+		"both empty": {
+			context:  3,
+			expected: "",
+		},
+		"changed line": {
+			old: "passed: 10\nfailed: 0\nskipped: 1\n", new: "passed: 9\nfailed: 1\nskipped: 1\n", context: 3,
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				"-passed: 10\n" +
+				"-failed: 0\n" +
+				"+passed: 9\n" +
+				"+failed: 1\n" +
+				" skipped: 1\n",
+		},
+		"completely different": {
+			old: "a\nb", new: "x\ny\nz", context: 3,
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,2 +1,3 @@\n" +
+				"-a\n" +
+				"-b\n" +
+				"+x\n" +
+				"+y\n" +
+				"+z\n",
+		},
+		"added to empty": {
+			old: "", new: "first\n", context: 3,
+			expected: "--- old\n+++ new\n" +
+				"@@ -0,0 +1 @@\n" +
+				"+first\n",
+		},
+		"removed all": {
+			old: "first\nsecond\n", new: "", context: 3,
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,2 +0,0 @@\n" +
+				"-first\n" +
+				"-second\n",
+		},
+		"separate hunks": {
+			old: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", new: "1\nTWO\n3\n4\n5\n6\n7\n8\n9\nTEN\n", context: 1,
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" 1\n" +
+				"-2\n" +
+				"+TWO\n" +
+				" 3\n" +
+				"@@ -9,2 +9,2 @@\n" +
+				" 9\n" +
+				"-10\n" +
+				"+TEN\n",
+		},
+		"merged hunks": {
+			old: "1\n2\n3\n4\n5\n", new: "1\nTWO\n3\nFOUR\n5\n", context: 1,
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,5 +1,5 @@\n" +
+				" 1\n" +
+				"-2\n" +
+				"+TWO\n" +
+				" 3\n" +
+				"-4\n" +
+				"+FOUR\n" +
+				" 5\n",
+		},
+		"no context": {
+			old: "a\nb\nc\n", new: "a\nc\n", context: 0,
+			expected: "--- old\n+++ new\n" +
+				"@@ -2 +1,0 @@\n" +
+				"-b\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, diffText(tt.old, tt.new, tt.context))
+		})
+	}
+}
+
+func TestDiffTextFunction(t *testing.T) {
+	assert.Equal(t, `"--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"`, MustCompile(`diffText("a\nb\nc", "a\nB\nc")`).String())
+	assert.Equal(t, `"--- old\n+++ new\n@@ -2 +2 @@\n-b\n+B\n"`, MustCompile(`diffText("a\nb\nc", "a\nB\nc", 0)`).String())
+	assert.Equal(t, `""`, MustCompile(`diffText("same", "same")`).String())
+
+	_, err := Compile(`diffText("a", "b", -1)`)
+	assert.ErrorContains(t, err, "expects context to be a non-negative number of lines")
+}
+
+func TestDiffStat(t *testing.T) {
+	assert.Equal(t, `{"added":2,"removed":1}`, MustCompile(`diffStat("a\nb\nc", "a\nB\nc\nd")`).String())
+	assert.Equal(t, `{"added":0,"removed":0}`, MustCompile(`diffStat("a\nb", "a\nb\n")`).String())
+	assert.Equal(t, `1`, MustCompile(`diffStat("x\ny", "x").removed`).String())
+}
+
+func TestDiffTruncated(t *testing.T) {
+	defer func(lines int) { DiffMaxLines = lines }(DiffMaxLines)
+	DiffMaxLines = 3
+
+	var oldLines, newLines []string
+	for i := 0; i < 100; i++ {
+		oldLines = append(oldLines, fmt.Sprintf("old %d", i))
+		newLines = append(newLines, fmt.Sprintf("new %d", i))
+	}
+	old, new := strings.Join(oldLines, "\n"), strings.Join(newLines, "\n")
+
+	assert.Equal(t, "--- old\n+++ new\n"+
+		"@@ -1,3 +1,3 @@\n"+
+		"-old 0\n-old 1\n-old 2\n"+
+		"+new 0\n+new 1\n+new 2\n"+
+		"... input truncated to first 3 lines\n", diffText(old, new, 3))
+	assert.Equal(t, map[string]interface{}{"added": int64(3), "removed": int64(3)}, diffStat(old, new))
+}
+
+func TestChangedPaths(t *testing.T) {
+	old := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:1.0"},
+				map[string]interface{}{"name": "sidecar", "image": "proxy:1"},
+			},
+		},
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"team": "qa", "old": "yes"}},
+	}
+	new := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 1.0,
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:1.1"},
+			},
+		},
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"team": "qa", "new": "yes"}},
+		"status":   nil,
+	}
+	assert.Equal(t, []string{
+		"metadata.labels.new",
+		"metadata.labels.old",
+		"spec.containers.0.image",
+		"spec.containers.1",
+		"status",
+	}, ChangedPaths(old, new))
+	assert.Equal(t, []string{}, ChangedPaths(old, old))
+	assert.Equal(t, []string{""}, ChangedPaths("a", "b"))
+}
+
+func TestChangedPathsFunction(t *testing.T) {
+	v, err := EvalString(`changedPaths({"a": {"b": 1, "c": 2}}, {"a": {"b": 1, "c": 3}, "d": []})`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a.c", "d"}, v)
+
+	_, err = Compile(`changedPaths({})`)
+	assert.ErrorContains(t, err, `"changedPaths" expects 2 arguments, 1 provided`)
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DNSLabelMaxLength is a maximum length of RFC 1123 label
+	DNSLabelMaxLength = 63
+	// DNSSubdomainMaxLength is a maximum length of RFC 1123 subdomain
+	DNSSubdomainMaxLength = 253
+	// dnsLabelHashLength is a number of hex characters of the hash suffix, it can't change between releases,
+	// otherwise the names generated for the same input would change
+	dnsLabelHashLength = 8
+)
+
+var (
+	dnsLabelRe     = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	dnsSubdomainRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	dnsInvalidRe   = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// isDNSLabel checks if the string is RFC 1123 label, i.e. the name of most of Kubernetes resources
+func isDNSLabel(str string) bool {
+	return len(str) <= DNSLabelMaxLength && dnsLabelRe.MatchString(str)
+}
+
+// isDNSSubdomain checks if the string is RFC 1123 subdomain, i.e. dot-separated labels, like Kubernetes does
+func isDNSSubdomain(str string) bool {
+	return len(str) <= DNSSubdomainMaxLength && dnsSubdomainRe.MatchString(str)
+}
+
+// toDNSLabel lowercases the string and replaces the runs of other characters than letters and digits with "-",
+// too long result is truncated with the hash suffix of the original string, so the names stay unique
+func toDNSLabel(str string) (string, error) {
+	if str == "" {
+		return "", errors.New("empty string can't be converted to DNS label")
+	}
+	label := strings.Trim(dnsInvalidRe.ReplaceAllString(strings.ToLower(str), "-"), "-")
+	if label != "" && len(label) <= DNSLabelMaxLength {
+		return label, nil
+	}
+
+	sum := sha256.Sum256([]byte(str))
+	hash := hex.EncodeToString(sum[:])[:dnsLabelHashLength]
+	label = strings.TrimRight(label[:min(len(label), DNSLabelMaxLength-dnsLabelHashLength-1)], "-")
+	if label == "" {
+		return hash, nil
+	}
+	return label + "-" + hash, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDNSLabel(t *testing.T) {
+	for _, str := range []string{"a", "0", "my-test-1", strings.Repeat("a", 63)} {
+		assert.True(t, isDNSLabel(str), str)
+	}
+	for _, str := range []string{"", "-a", "a-", "A", "my_test", "a.b", strings.Repeat("a", 64)} {
+		assert.False(t, isDNSLabel(str), str)
+	}
+}
+
+func TestIsDNSSubdomain(t *testing.T) {
+	long := strings.Repeat(strings.Repeat("a", 63)+".", 4)
+	for _, str := range []string{"a", "my-test.example.com", "0.a", long[:253]} {
+		assert.True(t, isDNSSubdomain(str), str)
+	}
+	for _, str := range []string{"", ".a", "a.", "a..b", "-a.b", "a-.b", "a.-b", "A.b", "a_b.c", long[:252] + "a.b"} {
+		assert.False(t, isDNSSubdomain(str), str)
+	}
+}
+
+// The hash suffix must never change between releases, otherwise generated names change
+func TestToDNSLabelGolden(t *testing.T) {
+	tests := map[string]string{
+		"My Test_1":                            "my-test-1",
+		"--Ünicode.Name--":                     "nicode-name",
+		"already-valid":                        "already-valid",
+		"___":                                  "bda25155",
+		strings.Repeat("long-name-", 10):       "long-name-long-name-long-name-long-name-long-name-long-839305fe",
+		strings.Repeat("long-name-", 10) + "x": "long-name-long-name-long-name-long-name-long-name-long-bd800e81",
+	}
+	for input, expected := range tests {
+		label, err := toDNSLabel(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, label, input)
+		assert.True(t, isDNSLabel(label), label)
+	}
+
+	_, err := toDNSLabel("")
+	assert.Error(t, err)
+}
+
+func TestDNSStdFunctions(t *testing.T) {
+	assert.Equal(t, "true", MustCompile(`isDNSLabel("my-test")`).String())
+	assert.Equal(t, "false", MustCompile(`isDNSLabel("my.test")`).String())
+	assert.Equal(t, "true", MustCompile(`isDNSSubdomain("my.test")`).String())
+	assert.Equal(t, `"my-test"`, MustCompile(`toDNSLabel("My Test")`).String())
+
+	_, err := Compile(`isDNSLabel(10)`)
+	assert.ErrorContains(t, err, `"isDNSLabel" function argument should be a string`)
+	_, err = Compile(`toDNSLabel("")`)
+	assert.ErrorContains(t, err, `"toDNSLabel" function: empty string can't be converted to DNS label`)
+}
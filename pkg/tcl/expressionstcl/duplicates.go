@@ -0,0 +1,94 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// itemKey identifies the value when looking for duplicates, the values with the same JSON form are the same,
+// so i.e. 1 and 1.0 are duplicates, while 1 and "1" are not
+func itemKey(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// itemValues resolves the key expression for the list items, that has the item available as _.value,
+// and its index as _.index and _.key; without the expression, the items themselves are the keys
+func itemValues(list []interface{}, expr Expression) ([]interface{}, error) {
+	if expr == nil {
+		return list, nil
+	}
+	values := make([]interface{}, len(list))
+	current := &iterationMachine{}
+	for i := range list {
+		ex, _ := Compile(expr.String())
+		current.value, current.index = list[i], i
+		v, err := ex.Resolve(current)
+		if err != nil {
+			return nil, fmt.Errorf("error while resolving key for %d index (%v): %v", i, list[i], err)
+		}
+		if v.Static() == nil {
+			return nil, fmt.Errorf("could not resolve key for %d index (%v): %s", i, list[i], v)
+		}
+		values[i] = v.Static().Value()
+	}
+	return values, nil
+}
+
+// itemKeys builds the keys of the list items, from the items themselves or from the key expression
+func itemKeys(list []interface{}, expr Expression) ([]string, error) {
+	values, err := itemValues(list, expr)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(list))
+	for i := range values {
+		keys[i], err = itemKey(values[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid key for %d index (%v): %v", i, list[i], err)
+		}
+	}
+	return keys, nil
+}
+
+// uniqueByKey keeps the first item for each key
+func uniqueByKey(list []interface{}, keys []string) []interface{} {
+	seen := make(map[string]struct{}, len(list))
+	result := make([]interface{}, 0, len(list))
+	for i := range list {
+		if _, ok := seen[keys[i]]; ok {
+			continue
+		}
+		seen[keys[i]] = struct{}{}
+		result = append(result, list[i])
+	}
+	return result
+}
+
+// duplicatesByKey returns the first item for each key that occurs more than once, in order of their first occurrence
+func duplicatesByKey(list []interface{}, keys []string) []interface{} {
+	counts := make(map[string]int, len(list))
+	for _, key := range keys {
+		counts[key]++
+	}
+	result := make([]interface{}, 0)
+	for i := range list {
+		if counts[keys[i]] > 1 {
+			result = append(result, list[i])
+			// List each duplicate once
+			counts[keys[i]] = 0
+		}
+	}
+	return result
+}
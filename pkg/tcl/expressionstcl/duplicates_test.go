@@ -0,0 +1,76 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplicates(t *testing.T) {
+	tests := map[string]interface{}{
+		`duplicates([])`:                                       []interface{}{},
+		`duplicates([1, 2, 3])`:                                []interface{}{},
+		`duplicates(["b", "a", "b", "c", "a", "b"])`:           []interface{}{"b", "a"},
+		`duplicates([1, "1", 1.0])`:                            []interface{}{1.0},
+		`duplicates([{"a": 1, "b": 2}, {"b": 2, "a": 1}, {}])`: []interface{}{map[string]interface{}{"a": 1.0, "b": 2.0}},
+		`duplicates([[1], [1, 2], [1]])`:                       []interface{}{[]interface{}{1.0}},
+	}
+	for expr, expected := range tests {
+		v, err := EvalString(expr, nil)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+}
+
+func TestDuplicatesByAndUniqueBy(t *testing.T) {
+	vars := map[string]interface{}{
+		"shards": []interface{}{
+			map[string]interface{}{"name": "api", "index": 0},
+			map[string]interface{}{"name": "web", "index": 1},
+			map[string]interface{}{"name": "api", "index": 2},
+			map[string]interface{}{"name": "db", "index": 3},
+			map[string]interface{}{"name": "web", "index": 4},
+		},
+	}
+
+	v, err := EvalString(`map(duplicatesBy(shards, "_.value.name"), "_.value.index")`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{0, 1}, v)
+
+	v, err = EvalString(`map(uniqueBy(shards, "_.value.name"), "_.value.index")`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{0, 1, 3}, v)
+
+	v, err = EvalString(`len(duplicatesBy(shards, "_.value.index")) == 0`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	// the key may use the index too
+	v, err = EvalString(`uniqueBy(["a", "b", "c", "d"], "_.index % 2")`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, v)
+}
+
+func TestDuplicatesErrors(t *testing.T) {
+	tests := map[string]string{
+		`duplicates()`:                          `"duplicates" expects 1 argument, 0 provided`,
+		`duplicates([1], "_.value")`:            `"duplicates" expects 1 argument, 2 provided`,
+		`duplicatesBy([1])`:                     `"duplicatesBy" expects 2 arguments, 1 provided`,
+		`uniqueBy(1, "_.value")`:                `"uniqueBy" function expects 1st argument to be a list`,
+		`uniqueBy([1], "_.value +")`:            `"uniqueBy" function expects 2nd argument to be valid expression, '"_.value +"' provided`,
+		`uniqueBy([1, 2], "_.value.name")`:      `"uniqueBy" function: could not resolve key for 0 index (1)`,
+		`duplicatesBy([1, "a"], "_.value * 2")`: `"duplicatesBy" function: error while resolving key for 1 index (a)`,
+	}
+	for expr, message := range tests {
+		_, err := EvalString(expr, nil)
+		assert.ErrorContains(t, err, message, expr)
+	}
+}
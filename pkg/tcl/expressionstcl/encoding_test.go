@@ -0,0 +1,59 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodingFunctions(t *testing.T) {
+	tests := []struct {
+		input  interface{}
+		base64 string
+		sha256 string
+		md5    string
+	}{
+		{"", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "d41d8cd98f00b204e9800998ecf8427e"},
+		{"hello", "aGVsbG8=", "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", "5d41402abc4b2a76b9719d911017c592"},
+		{"zażółć 🚀", "emHFvMOzxYLEhyDwn5qA", "e70e84e954934473e6caa7592566207dc6554616bf84eced50342e2ce2abb68f", "de0b24c6827591f89ab2e45519541c82"},
+		{int64(5), "NQ==", "ef2d127de37b942baad06145e54b0c619a1f22327b2ebbcfbec78f5564afe39d", "e4da3b7fbbce2345d7772b0674a318d5"},
+		{true, "dHJ1ZQ==", "b5bea41b6c623f7c09f1bf24dcae58ebab3c0cdd90ad966bc43a45b44867e12b", "b326b5062b2f0e69046810717534cb09"},
+	}
+	for _, tt := range tests {
+		vars := map[string]interface{}{"input": tt.input}
+		assert.Equal(t, tt.base64, must(EvalString(`base64encode(input)`, vars)), tt.input)
+		assert.Equal(t, tt.sha256, must(EvalString(`sha256(input)`, vars)), tt.input)
+		assert.Equal(t, tt.md5, must(EvalString(`md5(input)`, vars)), tt.input)
+		if s, ok := tt.input.(string); ok {
+			assert.Equal(t, s, must(EvalString(`base64decode(base64encode(input))`, vars)), tt.input)
+		}
+	}
+}
+
+func TestEncodingFunctionsStdCall(t *testing.T) {
+	assert.Equal(t, "aGVsbG8=", must(must(CallStdFunction("base64encode", "hello")).Static().StringValue()))
+	assert.Equal(t, "hello", must(must(CallStdFunction("base64decode", "aGVsbG8=")).Static().StringValue()))
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", must(must(CallStdFunction("md5", "hello")).Static().StringValue()))
+	for _, name := range []string{"base64encode", "base64decode", "sha256", "md5"} {
+		assert.Equal(t, TypeString, GetStdFunctionReturnType(name), name)
+	}
+}
+
+func TestEncodingFunctionsErrors(t *testing.T) {
+	_, err := Compile(`base64decode("not base64!")`)
+	assert.ErrorContains(t, err, `"base64decode" function: invalid base64`)
+	_, err = Compile(`base64decode("aGVsbG8")`)
+	assert.ErrorContains(t, err, `"base64decode" function: invalid base64`)
+	_, err = Compile(`sha256("a", "b")`)
+	assert.ErrorContains(t, err, `"sha256" expects 1 argument, 2 provided`)
+	_, err = Compile(`base64encode()`)
+	assert.ErrorContains(t, err, `"base64encode" expects 1 argument, 0 provided`)
+}
@@ -0,0 +1,168 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+var (
+	errEnvNotEnabled    = errors.New("environment is not available for the resolution, see NewEnvMachine")
+	errConfigNotEnabled = errors.New("configuration is not available for the resolution, see NewConfigMachine")
+)
+
+// EnvMachineOptions select process environment variables captured by ProcessEnv,
+// patterns are matched against variable names with path.Match, e.g. "CLUSTER_*"
+type EnvMachineOptions struct {
+	// Allow lists exposed variables, all variables are exposed when it's empty
+	Allow []string
+	// Deny lists hidden variables, it wins over Allow
+	Deny []string
+}
+
+type envMachine struct {
+	env map[string]string
+}
+
+// NewEnvMachine creates machine finalizing `env("NAME", default?)` calls and `env.NAME` accessors with the snapshot
+// of the environment variables. Without it the calls are left unresolved, i.e. by StdLibMachine, so the expression
+// may be resolved at scheduling time with one snapshot and at run time with another one:
+//
+//	expr, err := expr.Resolve(StdLibMachine, otherMachines...)              // env("REGION") is kept
+//	expr, err = expr.Resolve(NewEnvMachine(map[string]string{"REGION": "eu"})) // env("REGION") is "eu"
+//
+// The process environment is never read implicitly, capture it with ProcessEnv when expressions may read it.
+func NewEnvMachine(env map[string]string) Machine {
+	snapshot := make(map[string]string, len(env))
+	for name, value := range env {
+		snapshot[name] = value
+	}
+	return &envMachine{env: snapshot}
+}
+
+// ProcessEnv captures the process environment variables exposed by the options, i.e. for NewEnvMachine:
+//
+//	env := NewEnvMachine(ProcessEnv(EnvMachineOptions{Allow: []string{"REGION", "CLUSTER_*"}, Deny: []string{"*_SECRET*"}}))
+func ProcessEnv(options EnvMachineOptions) map[string]string {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if options.exposes(name) {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+func (e *envMachine) Get(name string) (Expression, bool, error) {
+	if !strings.HasPrefix(name, "env.") {
+		return nil, false, nil
+	}
+	value, ok := e.env[name[4:]]
+	if !ok {
+		return nil, false, nil
+	}
+	return NewValue(value), true, nil
+}
+
+func (e *envMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// resolveEnv finds the environment snapshot of the resolution, it's nil when env() calls should be left unresolved
+func resolveEnv(m []Machine) *envMachine {
+	for i := range m {
+		if e, ok := m[i].(*envMachine); ok {
+			return e
+		}
+	}
+	return nil
+}
+
+type configMachine struct {
+	config map[string]interface{}
+}
+
+// NewConfigMachine creates machine finalizing `config("NAME", default?)` calls with the snapshot of the configuration,
+// like NewEnvMachine does for env() calls; without it the calls are left unresolved
+func NewConfigMachine(config map[string]interface{}) Machine {
+	snapshot := make(map[string]interface{}, len(config))
+	for name, value := range config {
+		snapshot[name] = value
+	}
+	return &configMachine{config: snapshot}
+}
+
+func (c *configMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (c *configMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// resolveConfig finds the configuration snapshot of the resolution, it's nil when config() calls should be left unresolved
+func resolveConfig(m []Machine) *configMachine {
+	for i := range m {
+		if c, ok := m[i].(*configMachine); ok {
+			return c
+		}
+	}
+	return nil
+}
+
+// snapshotLookup reads the name from the snapshot for env() and config(), the 2nd argument is the default;
+// the name not defined without the default fails, so it doesn't silently become none
+func snapshotLookup(fnName, kind string, lookup func(string) (interface{}, bool), value ...StaticValue) (Expression, error) {
+	name, err := value[0].StringValue()
+	if err != nil {
+		return nil, newArgError(0, fmt.Errorf(`"%s" function expects 1st argument to be a string, %s provided: %v`, fnName, value[0], err))
+	}
+	if v, ok := lookup(name); ok {
+		return NewValue(v), nil
+	}
+	if len(value) == 2 {
+		return value[1], nil
+	}
+	return nil, fmt.Errorf(`"%s" function: %s %s is not defined`, fnName, kind, name)
+}
+
+// snapshotNotEnabledError returns the error reported when the function reading the snapshot or the files
+// is finalized without its machine, it's nil for other functions
+func snapshotNotEnabledError(name string) error {
+	switch name {
+	case "env":
+		return errEnvNotEnabled
+	case "config":
+		return errConfigNotEnabled
+	case "file", "fileBase64", "fileExists":
+		return errFileNotEnabled
+	}
+	return nil
+}
+
+func (o EnvMachineOptions) exposes(name string) bool {
+	if matchesAnyPattern(o.Deny, name) {
+		return false
+	}
+	return len(o.Allow) == 0 || matchesAnyPattern(o.Allow, name)
+}
+
+func matchesAnyPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvMachineAccessor(t *testing.T) {
+	m := NewEnvMachine(map[string]string{"TK_TEST_REGION": "eu-west-1"})
+
+	v, err := MustCompile(`env.TK_TEST_REGION`).Resolve(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `"eu-west-1"`, v.String())
+
+	v, err = MustCompile(`env.TK_TEST_UNKNOWN`).Resolve(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `env.TK_TEST_UNKNOWN`, v.String())
+}
+
+func TestEnvMachineFunction(t *testing.T) {
+	m := NewEnvMachine(map[string]string{"TK_TEST_CLUSTER": "staging"})
+
+	v, err := EvalTemplate(`{{ env("TK_TEST_CLUSTER") }}/{{ env("TK_TEST_UNKNOWN", "default") }}`, m)
+	assert.NoError(t, err)
+	assert.Equal(t, "staging/default", v)
+
+	_, err = EvalTemplate(`{{ env("TK_TEST_UNKNOWN") }}`, m)
+	assert.ErrorContains(t, err, `environment variable TK_TEST_UNKNOWN is not defined`)
+}
+
+func TestEnvMachineFiltering(t *testing.T) {
+	t.Setenv("TK_TEST_REGION", "eu-west-1")
+	t.Setenv("TK_TEST_CLUSTER_NAME", "staging")
+	t.Setenv("TK_TEST_CLUSTER_TOKEN", "secret")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	m := NewEnvMachine(ProcessEnv(EnvMachineOptions{
+		Allow: []string{"TK_TEST_REGION", "TK_TEST_CLUSTER_*", "AWS_*"},
+		Deny:  []string{"*_TOKEN", "AWS_SECRET_*"},
+	}))
+
+	v, err := EvalTemplate(`{{ env.TK_TEST_REGION }}/{{ env.TK_TEST_CLUSTER_NAME }}`, m)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-1/staging", v)
+
+	for _, name := range []string{"TK_TEST_CLUSTER_TOKEN", "AWS_SECRET_ACCESS_KEY", "HOME"} {
+		v, err = EvalTemplate(`{{ env("`+name+`", "hidden") }}`, m)
+		assert.NoError(t, err)
+		assert.Equal(t, "hidden", v, name)
+	}
+}
+
+func TestEnvMachineCapturesValues(t *testing.T) {
+	t.Setenv("TK_TEST_REGION", "eu-west-1")
+	env := ProcessEnv(EnvMachineOptions{})
+	m := NewEnvMachine(env)
+	assert.NoError(t, os.Setenv("TK_TEST_REGION", "us-east-1"))
+	env["TK_TEST_REGION"] = "eu-central-1"
+
+	v, err := EvalTemplate(`{{ env.TK_TEST_REGION }}`, m)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-1", v)
+}
+
+func TestEnvMachineDoesNotReadProcessEnv(t *testing.T) {
+	t.Setenv("TK_TEST_REGION", "eu-west-1")
+
+	_, err := EvalTemplate(`{{ env("TK_TEST_REGION") }}`, NewEnvMachine(nil))
+	assert.ErrorContains(t, err, `environment variable TK_TEST_REGION is not defined`)
+}
+
+func TestEnvTwoPassResolution(t *testing.T) {
+	expr := MustCompile(`env("REGION") + "/" + env("CLUSTER", "default") + "/" + string(config("retries", 1))`)
+
+	scheduled, err := expr.Resolve(StdLibMachine)
+	assert.NoError(t, err)
+	assert.Equal(t, `env("REGION")+"/"+env("CLUSTER","default")+"/"+string(config("retries",1))`, scheduled.String())
+
+	_, err = scheduled.Resolve(FinalizerFail)
+	assert.ErrorContains(t, err, "environment is not available for the resolution")
+
+	partial, err := scheduled.Resolve(NewEnvMachine(map[string]string{"REGION": "eu"}))
+	assert.NoError(t, err)
+	assert.Equal(t, `"eu/default/"+string(config("retries",1))`, partial.String())
+
+	final, err := partial.Resolve(NewConfigMachine(map[string]interface{}{"retries": 3}), FinalizerFail)
+	assert.NoError(t, err)
+	assert.Equal(t, `"eu/default/3"`, final.String())
+
+	runtime, err := scheduled.Resolve(NewEnvMachine(map[string]string{"REGION": "us", "CLUSTER": "prod"}), NewConfigMachine(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, `"us/prod/1"`, runtime.String())
+}
+
+func TestEnvMissingFailsOnFinalization(t *testing.T) {
+	scheduled, err := MustCompile(`env("MISSING")`).Resolve(StdLibMachine)
+	assert.NoError(t, err)
+	assert.Equal(t, `env("MISSING")`, scheduled.String())
+
+	_, err = scheduled.Resolve(NewEnvMachine(map[string]string{}))
+	assert.ErrorContains(t, err, "environment variable MISSING is not defined")
+
+	_, err = MustCompile(`config("MISSING")`).Resolve(NewConfigMachine(map[string]interface{}{}))
+	assert.ErrorContains(t, err, "configuration value MISSING is not defined")
+
+	_, err = MustCompile(`config("retries")`).Resolve(FinalizerFail)
+	assert.ErrorContains(t, err, "configuration is not available for the resolution")
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ExpressionError is the failure of the function in the expression, i.e. `at(args,5): out of bounds (length=3, index=5)`,
+// the resolvers prepend the path of the spec field it comes from, so it's `spec.steps[2].shell: at(args,5): ...`;
+// use errors.As to read it from the resolution error
+type ExpressionError struct {
+	// Function is the name of the failed function
+	Function string
+	// ArgIndex is the index of the offending argument, -1 when the failure is not about a single argument
+	ArgIndex int
+	// Source is the source text of the failed sub-expression
+	Source string
+	// Path is the path of the spec field with the expression, empty when it's not known
+	Path string
+	Err  error
+}
+
+func (e *ExpressionError) Error() string {
+	message := e.Err.Error()
+	if e.Source != "" {
+		message = e.Source + ": " + message
+	}
+	if e.Path != "" {
+		message = e.Path + ": " + message
+	}
+	return message
+}
+
+func (e *ExpressionError) Unwrap() error {
+	return e.Err
+}
+
+// newArgError marks the function failure caused by the argument at the index
+func newArgError(index int, err error) error {
+	return &ExpressionError{ArgIndex: index, Err: err}
+}
+
+// newFunctionError completes the failure of the function with its name and source,
+// the error from the handler is wrapped, unless it's ExpressionError already
+func newFunctionError(name, source string, err error) error {
+	var exprErr *ExpressionError
+	if errors.As(err, &exprErr) && exprErr.Function == "" {
+		exprErr.Function = name
+		exprErr.Source = source
+		return err
+	}
+	return &ExpressionError{Function: name, ArgIndex: -1, Source: source, Err: err}
+}
+
+// WithErrorPath prepends the path of the spec field to the expression errors, i.e. "spec" or "steps[2]",
+// and wraps the other errors with it, so the resolvers may add the location as they descend into the spec
+func WithErrorPath(err error, path string) error {
+	return wrapErrors(err, path, path)
+}
+
+// joinErrorPath joins the segment with the rest of the path, the index segments like "[2]" are joined without the dot
+func joinErrorPath(segment, path string) string {
+	if segment == "" {
+		return path
+	}
+	if path == "" || strings.HasPrefix(path, "[") {
+		return segment + path
+	}
+	return segment + "." + path
+}
+
+// errorList aggregates independent resolution errors in the order they have been found
+type errorList []error
+
+func (e errorList) Error() string {
+	messages := make([]string, len(e))
+	for i := range e {
+		messages[i] = e[i].Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e errorList) Unwrap() []error {
+	return e
+}
+
+// joinErrors flattens the errors into a single one, skipping nil and duplicated messages
+func joinErrors(errs ...error) error {
+	var result errorList
+	seen := make(map[string]struct{})
+	var add func(err error)
+	add = func(err error) {
+		if err == nil {
+			return
+		}
+		if list, ok := err.(errorList); ok {
+			for i := range list {
+				add(list[i])
+			}
+			return
+		}
+		message := err.Error()
+		if _, ok := seen[message]; ok {
+			return
+		}
+		seen[message] = struct{}{}
+		result = append(result, err)
+	}
+	for i := range errs {
+		add(errs[i])
+	}
+
+	if len(result) == 0 {
+		return nil
+	} else if len(result) == 1 {
+		return result[0]
+	}
+	return result
+}
+
+// wrapErrors prefixes each of the aggregated errors with the location,
+// the expression errors get the path segment prepended to their path instead
+func wrapErrors(err error, location, segment string) error {
+	if list, ok := err.(errorList); ok {
+		result := make(errorList, len(list))
+		for i := range list {
+			result[i] = wrapError(list[i], location, segment)
+		}
+		return result
+	}
+	return wrapError(err, location, segment)
+}
+
+func wrapError(err error, location, segment string) error {
+	var exprErr *ExpressionError
+	if errors.As(err, &exprErr) {
+		exprErr.Path = joinErrorPath(segment, exprErr.Path)
+		return err
+	}
+	return errors.Wrap(err, location)
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testErrorStep struct {
+	Name  string `json:"name"`
+	Shell string `json:"shell,omitempty" expr:"template"`
+}
+
+type testErrorSpec struct {
+	Steps []testErrorStep `json:"steps" expr:"include"`
+}
+
+func TestExpressionErrorAs(t *testing.T) {
+	m := NewMachine().Register("args", []int{1, 2, 3})
+	_, err := EvalExpression(`at(args, 5)`, m)
+
+	var exprErr *ExpressionError
+	assert.True(t, errors.As(err, &exprErr))
+	assert.Equal(t, "at", exprErr.Function)
+	assert.Equal(t, 1, exprErr.ArgIndex)
+	assert.Equal(t, "at(args,5)", exprErr.Source)
+	assert.Equal(t, "", exprErr.Path)
+	assert.Equal(t, "at(args,5): out of bounds (length=3, index=5)", exprErr.Error())
+}
+
+func TestExpressionErrorMachineFunction(t *testing.T) {
+	m := NewMachine().RegisterFunction("fail", func(values ...StaticValue) (interface{}, bool, error) {
+		return nil, true, errors.New("failed")
+	})
+	_, err := EvalExpression(`fail(1)`, m)
+
+	var exprErr *ExpressionError
+	assert.True(t, errors.As(err, &exprErr))
+	assert.Equal(t, "fail", exprErr.Function)
+	assert.Equal(t, -1, exprErr.ArgIndex)
+	assert.Equal(t, "fail(1)", exprErr.Source)
+}
+
+func TestExpressionErrorPath(t *testing.T) {
+	m := NewMachine().Register("args", []int{1, 2, 3})
+	spec := testErrorSpec{Steps: []testErrorStep{
+		{Name: "first", Shell: "echo {{at(args, 0)}}"},
+		{Name: "second"},
+		{Name: "third", Shell: "echo {{at(args, 5)}}"},
+	}}
+	err := WithErrorPath(Finalize(&spec, m), "spec")
+
+	var exprErr *ExpressionError
+	assert.True(t, errors.As(err, &exprErr))
+	assert.Equal(t, "spec.steps[2].shell", exprErr.Path)
+	assert.EqualError(t, err, "spec.steps[2].shell: at(args,5): out of bounds (length=3, index=5)")
+}
+
+func TestExpressionErrorPathOtherErrors(t *testing.T) {
+	err := WithErrorPath(errors.New("failed"), "spec")
+	assert.EqualError(t, err, "spec: failed")
+
+	var exprErr *ExpressionError
+	assert.False(t, errors.As(err, &exprErr))
+}
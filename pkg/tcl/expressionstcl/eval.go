@@ -0,0 +1,295 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type resolveOptions struct {
+	ctx               context.Context
+	machines          []Machine
+	hooks             ResolveHooks
+	noneAsEmptyString bool
+	resolveSecrets    bool
+	random            bool
+	features          Features
+	lenientAccessors  bool
+	missing           *[]string
+	interceptors      []OperatorInterceptor
+	audit             AuditSink
+}
+
+type ResolveOption func(*resolveOptions)
+
+// WithMachines adds machines consulted after the variables
+func WithMachines(machines ...Machine) ResolveOption {
+	return func(o *resolveOptions) {
+		o.machines = append(o.machines, machines...)
+	}
+}
+
+// WithResolveHooks observes the resolution with the hooks instead of the global ones
+func WithResolveHooks(hooks ResolveHooks) ResolveOption {
+	return func(o *resolveOptions) {
+		o.hooks = hooks
+	}
+}
+
+// WithFeatures enables or disables the features for the resolution, overriding the defaults
+func WithFeatures(features Features) ResolveOption {
+	return func(o *resolveOptions) {
+		if o.features == nil {
+			o.features = make(Features, len(features))
+		}
+		maps.Copy(o.features, features)
+	}
+}
+
+// WithNoneAsEmptyString treats None as an empty string in string concatenation, instead of failing
+func WithNoneAsEmptyString() ResolveOption {
+	return func(o *resolveOptions) {
+		o.noneAsEmptyString = true
+	}
+}
+
+// ResolveSecrets serializes the secret values as they are in "tojson" and "toyaml" functions, instead of "***",
+// see NewSecretValue
+func ResolveSecrets() ResolveOption {
+	return func(o *resolveOptions) {
+		o.resolveSecrets = true
+	}
+}
+
+// WithRandom enables the functions generating random values, i.e. uuid(), see NewRandomMachine
+func WithRandom() ResolveOption {
+	return func(o *resolveOptions) {
+		o.random = true
+	}
+}
+
+// WithLenientAccessors resolves the accessors that no machine knows to the marker like "<missing: execution.owner>",
+// instead of failing, the unknown functions and function errors still fail;
+// the names of missing accessors are stored sorted in the missing slice, when it's provided
+func WithLenientAccessors(missing *[]string) ResolveOption {
+	return func(o *resolveOptions) {
+		o.lenientAccessors = true
+		o.missing = missing
+	}
+}
+
+// MissingAccessorMarker returns the placeholder used for the missing accessor with WithLenientAccessors option
+func MissingAccessorMarker(name string) string {
+	return fmt.Sprintf("<missing: %s>", name)
+}
+
+func newMissingAccessorsMachine(names []string) Machine {
+	missing := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		missing[name] = struct{}{}
+	}
+	return NewMachine().RegisterAccessor(func(name string) (interface{}, bool) {
+		if _, ok := missing[name]; ok {
+			return MissingAccessorMarker(name), true
+		}
+		return nil, false
+	})
+}
+
+type noneAsEmptyStringMachine struct{}
+
+// NoneAsEmptyStringMachine passes WithNoneAsEmptyString option to the single resolution, i.e. expr.Resolve(m, NoneAsEmptyStringMachine)
+var NoneAsEmptyStringMachine Machine = noneAsEmptyStringMachine{}
+
+func (noneAsEmptyStringMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (noneAsEmptyStringMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func isNoneAsEmptyString(m []Machine) bool {
+	for i := range m {
+		if _, ok := m[i].(noneAsEmptyStringMachine); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NewVarsMachine builds a machine exposing the variables, nested maps are available as dotted accessors too
+func NewVarsMachine(vars map[string]interface{}) Machine {
+	flat := make(map[string]interface{})
+	flattenVars("", vars, flat)
+	return NewMachine().RegisterAccessor(func(name string) (interface{}, bool) {
+		v, ok := flat[name]
+		return v, ok
+	})
+}
+
+func flattenVars(prefix string, vars map[string]interface{}, result map[string]interface{}) {
+	for k, v := range vars {
+		result[prefix+k] = v
+		if nested, ok := v.(map[string]interface{}); ok {
+			flattenVars(prefix+k+".", nested, result)
+		}
+	}
+}
+
+// EvalString compiles and fully resolves the expression against the variables,
+// and returns it as a plain Go value
+func EvalString(source string, vars map[string]interface{}, opts ...ResolveOption) (interface{}, error) {
+	v, err := evalWithOptions(source, false, vars, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return v.Value(), nil
+}
+
+// EvalTemplateString compiles and fully resolves the template against the variables, i.e. for notification messages
+func EvalTemplateString(tpl string, vars map[string]interface{}, opts ...ResolveOption) (string, error) {
+	v, err := evalWithOptions(tpl, true, vars, opts...)
+	if err != nil {
+		return "", err
+	}
+	return v.StringValue()
+}
+
+func evalWithOptions(source string, template bool, vars map[string]interface{}, opts ...ResolveOption) (StaticValue, error) {
+	options := resolveOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	machines := append([]Machine{NewVarsMachine(vars)}, options.machines...)
+	if options.hooks != nil {
+		machines = append(machines, NewHooksMachine(options.hooks))
+	}
+	if options.noneAsEmptyString {
+		machines = append(machines, NoneAsEmptyStringMachine)
+	}
+	if options.resolveSecrets {
+		machines = append(machines, ResolveSecretsMachine)
+	}
+	if options.random {
+		machines = append(machines, NewRandomMachine())
+	}
+	if options.features != nil {
+		if err := validateFeatures(options.features); err != nil {
+			return nil, err
+		}
+		machines = append(machines, NewFeaturesMachine(options.features))
+	}
+	if len(options.interceptors) > 0 {
+		machines = append(machines, NewOperatorsMachine(options.interceptors...))
+	}
+	if options.ctx != nil {
+		machines = append(machines, NewContextMachine(options.ctx))
+	}
+	if options.audit != nil {
+		machines = append(machines, NewAuditMachine(options.audit))
+	}
+
+	var expr Expression
+	var err error
+	if template {
+		expr, err = compileTemplate(resolveFeatures(machines), source, operatorsMachines(machines)...)
+	} else {
+		expr, err = compile(resolveFeatures(machines), source, operatorsMachines(machines)...)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling")
+	}
+
+	// Resolve what's possible first, so only the accessors that are left are missing,
+	// and not i.e. the parent objects checked for the properties
+	machines, audit := withAudit(source, machines)
+	if options.lenientAccessors {
+		expr, err = expr.Resolve(machines...)
+		if err != nil {
+			if auditErr := audit.finish(expr, err); auditErr != nil {
+				return nil, auditErr
+			}
+			return nil, errors.Wrap(err, "resolving")
+		}
+		missing := make([]string, 0)
+		for name := range expr.Accessors() {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		if options.missing != nil {
+			*options.missing = missing
+		}
+		machines = append(machines, newMissingAccessorsMachine(missing))
+	}
+
+	expr, err = expr.Resolve(append(machines, FinalizerFail)...)
+	if auditErr := audit.finish(expr, err); auditErr != nil {
+		return nil, auditErr
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving")
+	}
+	if expr.Static() == nil {
+		if template {
+			return nil, fmt.Errorf("template should be static: %s", expr.Template())
+		}
+		return nil, fmt.Errorf("expression should be static: %s", expr.String())
+	}
+	return expr.Static(), nil
+}
+
+var letRe = regexp.MustCompile(`^let\s+([a-zA-Z_][a-zA-Z0-9_]*)\s*=\s*(.+)$`)
+
+// ReadEvalPrint evaluates each line of the input and prints the result or the error,
+// the "let name = expr" lines store the result as a variable available for the next lines
+func ReadEvalPrint(r io.Reader, w io.Writer, vars map[string]interface{}, opts ...ResolveOption) error {
+	state := maps.Clone(vars)
+	if state == nil {
+		state = make(map[string]interface{})
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		name, source := "", line
+		if match := letRe.FindStringSubmatch(line); match != nil {
+			name, source = match[1], match[2]
+		}
+
+		var output string
+		v, err := EvalString(source, state, opts...)
+		if err != nil {
+			output = "error: " + strings.ReplaceAll(err.Error(), "\n", "\n       ")
+		} else if name != "" {
+			state[name] = v
+			output = name + " = " + NewValue(v).String()
+		} else {
+			output = NewValue(v).String()
+		}
+		if _, err = fmt.Fprintln(w, output); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
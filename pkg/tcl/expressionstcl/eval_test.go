@@ -0,0 +1,148 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvalString(t *testing.T) {
+	vars := map[string]interface{}{
+		"name": "api",
+		"trigger": map[string]interface{}{
+			"labels": map[string]interface{}{"env": "prod"},
+		},
+	}
+
+	v, err := EvalString(`name + "-" + trigger.labels.env`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "api-prod", v)
+
+	v, err = EvalString(`trigger.labels`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"env": "prod"}, v)
+
+	v, err = EvalString(`split("a,b", ",")`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, v)
+}
+
+func TestEvalStringOptions(t *testing.T) {
+	hooks := newRecordingHooks()
+	extra := NewMachine().Register("name", "shadowed").Register("other", 10)
+
+	v, err := EvalString(`name + string(other)`, map[string]interface{}{"name": "api"}, WithMachines(extra), WithResolveHooks(hooks))
+	assert.NoError(t, err)
+	assert.Equal(t, "api10", v)
+	assert.Equal(t, 1, hooks.series["function_calls{function=string}"])
+}
+
+func TestEvalStringErrors(t *testing.T) {
+	_, err := EvalString(`name + missing`, map[string]interface{}{"name": "api"})
+	assert.EqualError(t, err, "resolving: error while accessing missing: unknown variable")
+
+	_, err = EvalString(`1 +`, nil)
+	assert.ErrorContains(t, err, "compiling")
+}
+
+func TestReadEvalPrint(t *testing.T) {
+	input := strings.Join([]string{
+		`let base = 10`,
+		`let doubled = base * 2`,
+		``,
+		`doubled + offset`,
+		`let base = "text"`,
+		`base`,
+	}, "\n")
+	var output bytes.Buffer
+
+	err := ReadEvalPrint(strings.NewReader(input), &output, map[string]interface{}{"offset": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		`base = 10`,
+		`doubled = 20`,
+		`21`,
+		`base = "text"`,
+		`"text"`,
+	}, "\n")+"\n", output.String())
+}
+
+func TestReadEvalPrintErrors(t *testing.T) {
+	input := strings.Join([]string{
+		`let a = b + c`,
+		`a`,
+		`let a = 1`,
+		`a`,
+	}, "\n")
+	var output bytes.Buffer
+	vars := map[string]interface{}{"x": 1}
+
+	err := ReadEvalPrint(strings.NewReader(input), &output, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		`error: resolving: error while accessing b: unknown variable`,
+		`       error while accessing c: unknown variable`,
+		`error: resolving: error while accessing a: unknown variable`,
+		`a = 1`,
+		`1`,
+	}, "\n")+"\n", output.String())
+	assert.Equal(t, map[string]interface{}{"x": 1}, vars)
+}
+
+func TestEvalStringLenientAccessors(t *testing.T) {
+	vars := map[string]interface{}{
+		"execution": map[string]interface{}{"name": "api-1"},
+	}
+
+	// the missing properties of known objects are still none
+	v, err := EvalString(`execution.owner`, vars, WithLenientAccessors(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, noneValue, v)
+
+	// the default behavior is unchanged
+	_, err = EvalString(`execution.name + " by " + trigger.owner`, vars)
+	assert.ErrorContains(t, err, "trigger.owner")
+	_, err = EvalTemplateString(`{{execution.name}} by {{trigger.owner}}`, vars)
+	assert.ErrorContains(t, err, "trigger.owner")
+
+	var missing []string
+	v, err = EvalString(`execution.name + " by " + trigger.owner`, vars, WithLenientAccessors(&missing))
+	assert.NoError(t, err)
+	assert.Equal(t, "api-1 by <missing: trigger.owner>", v)
+	assert.Equal(t, []string{"trigger.owner"}, missing)
+
+	msg, err := EvalTemplateString(`Execution {{execution.name}} of {{test.name}} by {{shellquote(trigger.owner)}}, see {{trigger.owner}}`, vars, WithLenientAccessors(&missing))
+	assert.NoError(t, err)
+	assert.Equal(t, "Execution api-1 of <missing: test.name> by '<missing: trigger.owner>', see <missing: trigger.owner>", msg)
+	assert.Equal(t, []string{"test.name", "trigger.owner"}, missing)
+
+	// nothing is missing
+	msg, err = EvalTemplateString(`Execution {{execution.name}}`, vars, WithLenientAccessors(&missing))
+	assert.NoError(t, err)
+	assert.Equal(t, "Execution api-1", msg)
+	assert.Empty(t, missing)
+
+	// the collection is optional
+	v, err = EvalString(`owner`, vars, WithLenientAccessors(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, MissingAccessorMarker("owner"), v)
+}
+
+func TestEvalStringLenientAccessorsFunctionErrors(t *testing.T) {
+	_, err := EvalString(`unknownFn(owner)`, nil, WithLenientAccessors(nil))
+	assert.ErrorContains(t, err, "unknown function")
+	_, err = EvalString(`int(owner)`, nil, WithLenientAccessors(nil))
+	assert.Error(t, err)
+	_, err = EvalTemplateString(`{{toyaml(owner, {"indent": 1})}}`, nil, WithLenientAccessors(nil))
+	assert.ErrorContains(t, err, `"toyaml" function: indent should be between 2 and 9`)
+}
@@ -0,0 +1,207 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ExplainNodeKind string
+
+const (
+	ExplainNodeStatic      ExplainNodeKind = "static"
+	ExplainNodeAccessor    ExplainNodeKind = "accessor"
+	ExplainNodeProperty    ExplainNodeKind = "property"
+	ExplainNodeCall        ExplainNodeKind = "call"
+	ExplainNodeOperator    ExplainNodeKind = "operator"
+	ExplainNodeConditional ExplainNodeKind = "conditional"
+	ExplainNodeNegation    ExplainNodeKind = "negation"
+)
+
+// ExplainableMachine is implemented by the machines that can tell which accessors and functions they serve without calling them,
+// known is false when the machine can't tell, i.e. because of the custom accessor
+type ExplainableMachine interface {
+	ServesAccessor(name string) (serves bool, known bool)
+	ServesFunction(name string) (serves bool, known bool)
+}
+
+// NamedMachine is a machine with the name used in the explanation
+type NamedMachine struct {
+	Name    string
+	Machine Machine
+}
+
+// ExplainNode is a node of the compiled expression tree, the static nodes are already folded with their values
+type ExplainNode struct {
+	Kind       ExplainNodeKind `json:"kind"`
+	Expression string          `json:"expression"`
+	// Name is the accessor, function, operator or the property path
+	Name   string      `json:"name,omitempty"`
+	Type   Type        `json:"type,omitempty"`
+	Static bool        `json:"static,omitempty"`
+	Value  interface{} `json:"value,omitempty"`
+	// Machine is the first machine that is known to serve the accessor or the function,
+	// while MaybeMachines are the ones before it, that can't tell if they serve it
+	Machine       string   `json:"machine,omitempty"`
+	MaybeMachines []string `json:"maybeMachines,omitempty"`
+	// ServedAs is the parent accessor served by the machine, when the accessor itself is not served
+	ServedAs string        `json:"servedAs,omitempty"`
+	Spread   bool          `json:"spread,omitempty"`
+	Children []ExplainNode `json:"children,omitempty"`
+}
+
+// ExplainPlan analyzes the expression statically, without calling any machine, and returns its tree,
+// with the parts that are already resolvable folded, and the machines serving the rest during the resolution
+func ExplainPlan(expr Expression, machines ...NamedMachine) ExplainNode {
+	// Compile the expression again to fold the static parts, without modifying the provided one
+	if folded, err := Compile(expr.String()); err == nil {
+		expr = folded
+	}
+	return explainNode(expr, machines)
+}
+
+// Explain renders the ExplainPlan as indented text, one node per line
+func Explain(expr Expression, machines ...NamedMachine) string {
+	var b strings.Builder
+	writeExplainNode(&b, ExplainPlan(expr, machines...), 0)
+	return b.String()
+}
+
+func explainNode(expr Expression, machines []NamedMachine) ExplainNode {
+	node := ExplainNode{Expression: expr.String(), Type: expr.Type()}
+	if v := expr.Static(); v != nil {
+		node.Kind = ExplainNodeStatic
+		node.Static = true
+		node.Value = v.Value()
+		return node
+	}
+
+	switch e := expr.(type) {
+	case *accessor:
+		node.Kind = ExplainNodeAccessor
+		node.Name = e.name
+		// The accessor is tried first, and then its parents, the same way as during the resolution
+		segments := strings.Split(e.name, ".")
+		for i := len(segments); i > 0 && node.Machine == ""; i-- {
+			name := strings.Join(segments[:i], ".")
+			node.Machine, node.MaybeMachines = servingMachine(machines, name, ExplainableMachine.ServesAccessor)
+			if node.Machine != "" && i < len(segments) {
+				node.ServedAs = name
+			}
+		}
+	case *propertyAccessor:
+		node.Kind = ExplainNodeProperty
+		node.Name = strings.Join(e.path, ".")
+		node.Children = []ExplainNode{explainNode(e.value, machines)}
+	case *call:
+		node.Kind = ExplainNodeCall
+		node.Name = e.name
+		if !IsStdFunction(e.name) {
+			node.Machine, node.MaybeMachines = servingMachine(machines, e.name, ExplainableMachine.ServesFunction)
+		}
+		for _, arg := range e.args {
+			child := explainNode(arg.expr, machines)
+			child.Spread = arg.spread
+			node.Children = append(node.Children, child)
+		}
+	case *math:
+		node.Kind = ExplainNodeOperator
+		node.Name = string(e.operator)
+		node.Children = []ExplainNode{explainNode(e.left, machines), explainNode(e.right, machines)}
+	case *conditional:
+		node.Kind = ExplainNodeConditional
+		node.Children = []ExplainNode{
+			explainNode(e.condition, machines),
+			explainNode(e.truthy, machines),
+			explainNode(e.falsy, machines),
+		}
+	case *negative:
+		node.Kind = ExplainNodeNegation
+		node.Children = []ExplainNode{explainNode(e.expr, machines)}
+	}
+	return node
+}
+
+// servingMachine finds the first machine that serves the name, with the machines before it that can't tell
+func servingMachine(machines []NamedMachine, name string, serves func(ExplainableMachine, string) (bool, bool)) (string, []string) {
+	var maybe []string
+	for _, m := range machines {
+		served, known := explainServes(m.Machine, name, serves)
+		if served {
+			return m.Name, maybe
+		}
+		if !known {
+			maybe = append(maybe, m.Name)
+		}
+	}
+	return "", maybe
+}
+
+func writeExplainNode(b *strings.Builder, node ExplainNode, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	if node.Spread {
+		b.WriteString("...")
+	}
+	b.WriteString(string(node.Kind))
+	switch node.Kind {
+	case ExplainNodeStatic:
+		b.WriteString(" " + node.Expression)
+	case ExplainNodeProperty:
+		b.WriteString(" ." + node.Name)
+	case ExplainNodeCall:
+		b.WriteString(" " + node.Name + "()")
+	case ExplainNodeAccessor, ExplainNodeOperator:
+		b.WriteString(" " + node.Name)
+	}
+	if node.Type != TypeUnknown {
+		b.WriteString(" [" + string(node.Type) + "]")
+	}
+	if !node.Static {
+		b.WriteString(" runtime")
+		if node.Kind == ExplainNodeAccessor || (node.Kind == ExplainNodeCall && !IsStdFunction(node.Name)) {
+			b.WriteString(explainServing(node))
+		}
+	}
+	b.WriteString("\n")
+	for _, child := range node.Children {
+		writeExplainNode(b, child, depth+1)
+	}
+}
+
+func explainServing(node ExplainNode) string {
+	var parts []string
+	if node.Machine != "" {
+		served := fmt.Sprintf("served by %q", node.Machine)
+		if node.ServedAs != "" {
+			served += " as " + node.ServedAs
+		}
+		parts = append(parts, served)
+	}
+	if len(node.MaybeMachines) > 0 {
+		maybe := make([]string, len(node.MaybeMachines))
+		for i := range node.MaybeMachines {
+			maybe[i] = fmt.Sprintf("%q", node.MaybeMachines[i])
+		}
+		parts = append(parts, "maybe by "+strings.Join(maybe, ", "))
+	}
+	if len(parts) == 0 {
+		return ", not served by any machine"
+	}
+	return ", " + strings.Join(parts, ", ")
+}
+
+// explainServes checks if the machine serves the name, the machine that is not explainable can't tell
+func explainServes(m Machine, name string, serves func(ExplainableMachine, string) (bool, bool)) (bool, bool) {
+	explainable, ok := m.(ExplainableMachine)
+	if !ok {
+		return false, false
+	}
+	return serves(explainable, name)
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	opaque := NewMockMachine(ctrl)
+	machines := []NamedMachine{
+		{Name: "env", Machine: PrefixMachine("env.", NewMachine().RegisterStringMap("env", map[string]string{"NAME": "value"}))},
+		{Name: "config", Machine: NewMachine().Register("config", map[string]interface{}{"retries": 3}).RegisterFunction("secret", nil)},
+		{Name: "custom", Machine: opaque},
+		{Name: "execution", Machine: NewMachine().Register("execution.status", "passed")},
+	}
+
+	expr := must(Compile(`execution.status == "pass" + "ed" && config.retries > 1 + 2 ? join(split(env.NAME, ","), secret("x")) : (!other ? now() : custom.tags)`))
+	before := expr.String()
+	assert.Equal(t, `conditional runtime
+  operator && [bool] runtime
+    operator == [bool] runtime
+      accessor execution.status runtime, served by "execution", maybe by "custom"
+      static "passed" [string]
+    operator > [bool] runtime
+      accessor config.retries runtime, served by "config" as config
+      static 3 [float64]
+  call join() [string] runtime
+    call split() runtime
+      accessor env.NAME runtime, served by "env"
+      static "," [string]
+    call secret() runtime, served by "config"
+      static "x" [string]
+  conditional runtime
+    negation [bool] runtime
+      accessor other runtime, maybe by "custom"
+    call now() [string] runtime
+    accessor custom.tags runtime, maybe by "custom"
+`, Explain(expr, machines...))
+
+	// The explained expression is not modified
+	assert.Equal(t, before, expr.String())
+}
+
+func TestExplainPlan(t *testing.T) {
+	assert.Equal(t, ExplainNode{Kind: ExplainNodeStatic, Expression: "7", Type: TypeFloat64, Static: true, Value: float64(7)},
+		ExplainPlan(must(Compile(`1 + 2 * 3`))))
+
+	assert.Equal(t, ExplainNode{
+		Kind:       ExplainNodeCall,
+		Expression: `shellquote(a,"b"...)`,
+		Name:       "shellquote",
+		Type:       TypeString,
+		Children: []ExplainNode{
+			{Kind: ExplainNodeAccessor, Expression: "a", Name: "a", Machine: "vars"},
+			{Kind: ExplainNodeStatic, Expression: `"b"`, Type: TypeString, Static: true, Value: "b", Spread: true},
+		},
+	}, ExplainPlan(must(Compile(`shellquote(a, "b"...)`)), NamedMachine{Name: "vars", Machine: NewMachine().Register("a", "x")}))
+}
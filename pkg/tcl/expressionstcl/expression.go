@@ -8,6 +8,8 @@
 
 package expressionstcl
 
+import "context"
+
 //go:generate mockgen -destination=./mock_expression.go -package=expressionstcl "github.com/kubeshop/testkube/pkg/tcl/expressionstcl" Expression
 type Expression interface {
 	String() string
@@ -16,6 +18,7 @@ type Expression interface {
 	Type() Type
 	SafeResolve(...Machine) (Expression, bool, error)
 	Resolve(...Machine) (Expression, error)
+	ResolveContext(context.Context, ...Machine) (Expression, error)
 	Static() StaticValue
 	Accessors() map[string]struct{}
 	Functions() map[string]struct{}
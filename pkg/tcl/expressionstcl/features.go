@@ -0,0 +1,135 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"sync/atomic"
+)
+
+// Feature is a name of switchable behavior, that has changed in a way breaking for stored specs
+type Feature string
+
+const (
+	// FeatureJSONCompositeStrings converts lists to string as JSON, instead of joining the items with comma
+	FeatureJSONCompositeStrings Feature = "jsonCompositeStrings"
+	// FeatureNumericComparison compares numbers by their mathematical value and rejects comparing them with numeric strings,
+	// instead of comparing string forms with "==" and converting both sides to float64 with "<", "<=", ">" and ">="
+	FeatureNumericComparison Feature = "numericComparison"
+)
+
+// FeatureInfo describes the feature
+type FeatureInfo struct {
+	Name Feature
+	// Default tells if the feature is enabled when it's not set for the resolution
+	Default     bool
+	Description string
+}
+
+var knownFeatures = map[Feature]FeatureInfo{
+	FeatureJSONCompositeStrings: {
+		Name:        FeatureJSONCompositeStrings,
+		Default:     true,
+		Description: `lists are converted to string as JSON, e.g. string([1, "a"]) is "[1,\"a\"]" instead of "1,a"`,
+	},
+	FeatureNumericComparison: {
+		Name:        FeatureNumericComparison,
+		Default:     true,
+		Description: `numbers are compared by mathematical value, and comparing number with numeric string fails, e.g. 1 == "1" is an error instead of true`,
+	},
+}
+
+// Features enable or disable the features, the features not set are using the defaults
+type Features map[Feature]bool
+
+var defaultFeatures atomic.Pointer[Features]
+
+func validateFeatures(features Features) error {
+	for name := range features {
+		if _, ok := knownFeatures[name]; !ok {
+			return fmt.Errorf("unknown expressions feature: %s", name)
+		}
+	}
+	return nil
+}
+
+// SetDefaultFeatures overrides the defaults of the features for all resolutions,
+// it's meant to be called once by the application, before the expressions are used
+func SetDefaultFeatures(features Features) error {
+	if err := validateFeatures(features); err != nil {
+		return err
+	}
+	features = maps.Clone(features)
+	defaultFeatures.Store(&features)
+	BumpCompileGeneration()
+	return nil
+}
+
+// ListFeatures returns all the features sorted by name, with the current defaults
+func ListFeatures() []FeatureInfo {
+	result := make([]FeatureInfo, 0, len(knownFeatures))
+	for name, info := range knownFeatures {
+		info.Default = Features(nil).Enabled(name)
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Enabled checks if the feature is enabled, falling back to the defaults
+func (f Features) Enabled(name Feature) bool {
+	if v, ok := f[name]; ok {
+		return v
+	}
+	if defaults := defaultFeatures.Load(); defaults != nil {
+		if v, ok := (*defaults)[name]; ok {
+			return v
+		}
+	}
+	return knownFeatures[name].Default
+}
+
+type featuresMachine struct {
+	features Features
+}
+
+// NewFeaturesMachine builds a machine that passes the features to the single resolution, i.e. expr.Resolve(m, NewFeaturesMachine(f))
+func NewFeaturesMachine(features Features) Machine {
+	return &featuresMachine{features: features}
+}
+
+func (f *featuresMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (f *featuresMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// resolveFeatures finds the features for the resolution, it's nil when only the defaults should be used
+func resolveFeatures(m []Machine) Features {
+	for i := range m {
+		if f, ok := m[i].(*featuresMachine); ok {
+			return f.features
+		}
+	}
+	return nil
+}
+
+// featuresMachines passes the features to the resolution when there are any
+func featuresMachines(features Features) []Machine {
+	if features == nil {
+		return nil
+	}
+	return []Machine{NewFeaturesMachine(features)}
+}
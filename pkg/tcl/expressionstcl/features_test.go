@@ -0,0 +1,87 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListFeatures(t *testing.T) {
+	defer defaultFeatures.Store(nil)
+
+	features := ListFeatures()
+	assert.Len(t, features, 2)
+	assert.Equal(t, FeatureJSONCompositeStrings, features[0].Name)
+	assert.Equal(t, FeatureNumericComparison, features[1].Name)
+	assert.True(t, features[0].Default)
+	assert.True(t, features[1].Default)
+	assert.NotEmpty(t, features[0].Description)
+
+	assert.NoError(t, SetDefaultFeatures(Features{FeatureNumericComparison: false}))
+	features = ListFeatures()
+	assert.True(t, features[0].Default)
+	assert.False(t, features[1].Default)
+}
+
+func TestUnknownFeature(t *testing.T) {
+	assert.ErrorContains(t, SetDefaultFeatures(Features{"unknown": true}), "unknown expressions feature: unknown")
+	assert.True(t, Features(nil).Enabled(FeatureNumericComparison))
+
+	_, err := EvalString(`1`, nil, WithFeatures(Features{"unknown": true}))
+	assert.ErrorContains(t, err, "unknown expressions feature: unknown")
+}
+
+func TestFeaturesEnabled(t *testing.T) {
+	defer defaultFeatures.Store(nil)
+
+	assert.True(t, Features(nil).Enabled(FeatureJSONCompositeStrings))
+	assert.False(t, Features{FeatureJSONCompositeStrings: false}.Enabled(FeatureJSONCompositeStrings))
+
+	assert.NoError(t, SetDefaultFeatures(Features{FeatureJSONCompositeStrings: false}))
+	assert.False(t, Features(nil).Enabled(FeatureJSONCompositeStrings))
+	assert.True(t, Features{FeatureJSONCompositeStrings: true}.Enabled(FeatureJSONCompositeStrings))
+	assert.True(t, Features(nil).Enabled(FeatureNumericComparison))
+}
+
+func TestFeaturesResolution(t *testing.T) {
+	off := Features{FeatureJSONCompositeStrings: false, FeatureNumericComparison: false}
+	vars := map[string]interface{}{"list": []interface{}{1, "a"}, "num": 1}
+
+	v, err := EvalString(`string(list)`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, `[1,"a"]`, v)
+	v, err = EvalString(`string(list)`, vars, WithFeatures(off))
+	assert.NoError(t, err)
+	assert.Equal(t, `1,a`, v)
+
+	_, err = EvalString(`num == "1"`, vars)
+	assert.ErrorContains(t, err, "cannot compare number with string")
+	v, err = EvalString(`num == "1"`, vars, WithFeatures(off))
+	assert.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	// The features are passed to the resolution of partially compiled expression too
+	expr := MustCompile(`"items: " + list`)
+	result, err := expr.Resolve(NewMachine().Register("list", []interface{}{1, "a"}), NewFeaturesMachine(off))
+	assert.NoError(t, err)
+	assert.Equal(t, `"items: 1,a"`, result.String())
+
+	str, err := EvalTemplate(`{{list}}`, NewMachine().Register("list", []interface{}{1, "a"}), NewFeaturesMachine(off))
+	assert.NoError(t, err)
+	assert.Equal(t, `1,a`, str)
+}
+
+func TestCompileTemplateWithFeatures(t *testing.T) {
+	expr, err := CompileTemplateWithFeatures(`x{{[1, 2]}}`, Features{FeatureJSONCompositeStrings: false})
+	assert.NoError(t, err)
+	assert.Equal(t, `"x1,2"`, expr.String())
+	assert.Equal(t, `"x[1,2]"`, MustCompileTemplate(`x{{[1, 2]}}`).String())
+}
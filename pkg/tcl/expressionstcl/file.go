@@ -0,0 +1,178 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxFileSize limits the size of the file read by file() and fileBase64(), so the expression can't load huge files
+const maxFileSize = 16 * 1024 * 1024
+
+var (
+	errFileNotEnabled  = errors.New("files are not available for the resolution, see NewFileMachine")
+	errFileOutsideRoot = errors.New("path is outside of the root directory")
+)
+
+type fileMachine struct {
+	root string
+}
+
+// NewFileMachine creates machine finalizing `file(path)`, `fileBase64(path)` and `fileExists(path)` calls with the files
+// of the root directory, i.e. the files staged into the execution. Without it the calls are left unresolved,
+// i.e. by StdLibMachine, so the control plane never reads its own filesystem:
+//
+//	expr, err := expr.Resolve(StdLibMachine, otherMachines...)    // file("data.json") is kept
+//	expr, err = expr.Resolve(NewFileMachine("/data/repo"))         // file("data.json") is the content of /data/repo/data.json
+//
+// The paths are relative to the root, and the paths leading outside of it, directly or through the symlinks, are rejected.
+func NewFileMachine(root string) Machine {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return &fileMachine{root: filepath.Clean(root)}
+}
+
+func (f *fileMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (f *fileMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// resolveFileMachine finds the file machine of the resolution, it's nil when file() calls should be left unresolved
+func resolveFileMachine(m []Machine) *fileMachine {
+	for i := range m {
+		if f, ok := m[i].(*fileMachine); ok {
+			return f
+		}
+	}
+	return nil
+}
+
+// within checks if the path is the base directory or inside of it
+func within(base, path string) bool {
+	rel, err := filepath.Rel(base, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// path resolves the path relative to the root, following the symlinks, so the symlinks leading outside of the root
+// are rejected too; the error for the missing file wraps fs.ErrNotExist
+func (f *fileMachine) path(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("path is empty")
+	}
+	if filepath.IsAbs(name) {
+		return "", fmt.Errorf("%s: path should be relative to the root directory", name)
+	}
+	joined := filepath.Join(f.root, name)
+	if !within(f.root, joined) {
+		return "", fmt.Errorf("%s: %w", name, errFileOutsideRoot)
+	}
+
+	root, err := filepath.EvalSymlinks(f.root)
+	if err != nil {
+		return "", fmt.Errorf("root directory: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(joined)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	} else if err != nil {
+		return "", err
+	}
+	if !within(root, resolved) {
+		return "", fmt.Errorf("%s: %w", name, errFileOutsideRoot)
+	}
+	return resolved, nil
+}
+
+// exists checks if the path is a regular file inside of the root, the directories are not files
+func (f *fileMachine) exists(name string) (bool, error) {
+	resolved, err := f.path(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return false, nil
+	}
+	return info.Mode().IsRegular(), nil
+}
+
+// read reads the content of the regular file inside of the root
+func (f *fileMachine) read(name string) ([]byte, error) {
+	resolved, err := f.path(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s: not a regular file", name)
+	}
+	if info.Size() > maxFileSize {
+		return nil, fmt.Errorf("%s: file size %d exceeds %d bytes", name, info.Size(), maxFileSize)
+	}
+	return os.ReadFile(resolved)
+}
+
+// fileCall reads the path argument and calls the handler with the file machine of the resolution,
+// the call is left unresolved without it
+func fileCall(fnName string, handler func(f *fileMachine, name string) (interface{}, error)) func([]Machine, ...StaticValue) (Expression, error) {
+	return func(machines []Machine, value ...StaticValue) (Expression, error) {
+		f := resolveFileMachine(machines)
+		if f == nil {
+			return nil, errNotResolvedYet
+		}
+		if !value[0].IsString() {
+			return nil, newArgError(0, fmt.Errorf(`"%s" function expects the path to be a string, %s provided`, fnName, value[0]))
+		}
+		name, _ := value[0].StringValue()
+		result, err := handler(f, name)
+		if err != nil {
+			return nil, fmt.Errorf(`"%s" function: %w`, fnName, err)
+		}
+		return NewValue(result), nil
+	}
+}
+
+func readFileString(f *fileMachine, name string) (interface{}, error) {
+	content, err := f.read(name)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(content) {
+		return nil, fmt.Errorf("%s: file is not a valid UTF-8 text, use fileBase64() for the binary files", name)
+	}
+	return string(content), nil
+}
+
+func readFileBase64(f *fileMachine, name string) (interface{}, error) {
+	content, err := f.read(name)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.EncodeToString(content), nil
+}
+
+func fileExists(f *fileMachine, name string) (interface{}, error) {
+	return f.exists(name)
+}
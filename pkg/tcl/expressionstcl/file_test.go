@@ -0,0 +1,116 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFileTestRoot creates the root directory with the files, and the secret file outside of it
+func newFileTestRoot(t *testing.T) string {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "fixtures"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "fixtures", "users.json"), []byte(`{"users":["a"]}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "logo.png"), []byte{0x89, 'P', 'N', 'G', 0xff, 0x00}, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o644))
+	return root
+}
+
+func TestFileMachine(t *testing.T) {
+	m := NewFileMachine(newFileTestRoot(t))
+
+	v, err := MustCompile(`file("fixtures/users.json")`).Resolve(m)
+	require.NoError(t, err)
+	assert.Equal(t, `"{\"users\":[\"a\"]}"`, v.String())
+
+	v, err = MustCompile(`jq(json(file("./fixtures/../fixtures/users.json")), ".users[0]")`).Resolve(m)
+	require.NoError(t, err)
+	assert.Equal(t, `["a"]`, v.String())
+
+	v, err = MustCompile(`sha256(file("fixtures/users.json"))`).Resolve(m)
+	require.NoError(t, err)
+	expected, err := MustCompile(`sha256("{\"users\":[\"a\"]}")`).Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, expected.String(), v.String())
+
+	v, err = MustCompile(`fileBase64("logo.png")`).Resolve(m)
+	require.NoError(t, err)
+	assert.Equal(t, `"iVBOR/8A"`, v.String())
+
+	v, err = MustCompile(`fileExists("fixtures/users.json") && !fileExists("missing.json") && !fileExists("fixtures")`).Resolve(m)
+	require.NoError(t, err)
+	assert.Equal(t, `true`, v.String())
+}
+
+func TestFileMachineErrors(t *testing.T) {
+	m := NewFileMachine(newFileTestRoot(t))
+
+	tests := map[string]string{
+		`file("missing.json")`:        `"file" function: missing.json: file does not exist`,
+		`file("fixtures")`:            `"file" function: fixtures: not a regular file`,
+		`file("logo.png")`:            `"file" function: logo.png: file is not a valid UTF-8 text, use fileBase64() for the binary files`,
+		`file("../secret.txt")`:       `"file" function: ../secret.txt: path is outside of the root directory`,
+		`fileExists("../secret.txt")`: `"fileExists" function: ../secret.txt: path is outside of the root directory`,
+		`fileBase64("/etc/passwd")`:   `"fileBase64" function: /etc/passwd: path should be relative to the root directory`,
+		`file("")`:                    `"file" function: path is empty`,
+		`file(1)`:                     `"file" function expects the path to be a string, 1 provided`,
+	}
+	for expr, expected := range tests {
+		_, err := MustCompile(expr).Resolve(m)
+		assert.ErrorContains(t, err, expected, expr)
+	}
+}
+
+func TestFileMachineSymlinks(t *testing.T) {
+	root := newFileTestRoot(t)
+	require.NoError(t, os.Symlink(filepath.Join(root, "fixtures", "users.json"), filepath.Join(root, "users.json")))
+	require.NoError(t, os.Symlink(filepath.Join(root, "..", "secret.txt"), filepath.Join(root, "escape.txt")))
+	require.NoError(t, os.Symlink(filepath.Dir(root), filepath.Join(root, "fixtures", "parent")))
+	m := NewFileMachine(root)
+
+	v, err := MustCompile(`file("users.json")`).Resolve(m)
+	require.NoError(t, err)
+	assert.Equal(t, `"{\"users\":[\"a\"]}"`, v.String())
+
+	for _, expr := range []string{`file("escape.txt")`, `fileExists("escape.txt")`, `fileBase64("fixtures/parent/secret.txt")`} {
+		_, err = MustCompile(expr).Resolve(m)
+		assert.ErrorIs(t, err, errFileOutsideRoot, expr)
+	}
+}
+
+func TestFileMachineSymlinkedRoot(t *testing.T) {
+	root := newFileTestRoot(t)
+	link := filepath.Join(t.TempDir(), "workspace")
+	require.NoError(t, os.Symlink(root, link))
+
+	v, err := MustCompile(`fileExists("fixtures/users.json")`).Resolve(NewFileMachine(link))
+	require.NoError(t, err)
+	assert.Equal(t, `true`, v.String())
+}
+
+func TestFileTwoPassResolution(t *testing.T) {
+	expr := MustCompile(`fileExists("fixtures/users.json") ? sha256(file("fixtures/users.json")) : "none"`)
+
+	scheduled, err := expr.Resolve(StdLibMachine)
+	require.NoError(t, err)
+	assert.Equal(t, `fileExists("fixtures/users.json") ? sha256(file("fixtures/users.json")) : "none"`, scheduled.String())
+
+	_, err = scheduled.Resolve(FinalizerFail)
+	assert.ErrorContains(t, err, "files are not available for the resolution")
+
+	final, err := scheduled.Resolve(NewFileMachine(t.TempDir()), FinalizerFail)
+	require.NoError(t, err)
+	assert.Equal(t, `"none"`, final.String())
+}
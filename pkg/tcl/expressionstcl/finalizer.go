@@ -53,6 +53,12 @@ func (f *finalizer) Get(name string) (Expression, bool, error) {
 func (f *finalizer) Call(name string, _ ...StaticValue) (Expression, bool, error) {
 	result := f.handler(finalizerItem{function: true, name: name})
 	if result == FinalizerResultFail {
+		if isRandomStdFunction(name) {
+			return nil, true, errRandomNotEnabled
+		}
+		if err := snapshotNotEnabledError(name); err != nil {
+			return nil, true, err
+		}
 		return nil, true, errors.New("unknown function")
 	} else if result == FinalizerResultNone {
 		return None, true, nil
@@ -0,0 +1,181 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	math2 "math"
+	"strconv"
+	"strings"
+)
+
+// maxFormatWidth limits the width and the precision of the format verbs, so the layout can't allocate huge strings
+const maxFormatWidth = 100
+
+// formatVerb is a single verb of the format layout, i.e. %05d or %.2f
+type formatVerb struct {
+	flags     string
+	width     int
+	precision int
+	verb      byte
+}
+
+func (v formatVerb) String() string {
+	s := "%" + v.flags
+	if v.width >= 0 {
+		s += strconv.Itoa(v.width)
+	}
+	if v.precision >= 0 {
+		s += "." + strconv.Itoa(v.precision)
+	}
+	return s + string(v.verb)
+}
+
+// parseFormatNumber reads the digits at the position of the layout, it returns -1 when there are none
+func parseFormatNumber(layout string, i int) (int, int, error) {
+	start := i
+	for i < len(layout) && layout[i] >= '0' && layout[i] <= '9' {
+		i++
+	}
+	if start == i {
+		return -1, i, nil
+	}
+	n, err := strconv.Atoi(layout[start:i])
+	if err != nil || n > maxFormatWidth {
+		return 0, i, fmt.Errorf("width and precision should be at most %d, %s provided", maxFormatWidth, layout[start:i])
+	}
+	return n, i, nil
+}
+
+// parseFormatVerb reads the verb starting after the % sign, only the flags, width and precision
+// meaningful for the supported verbs are accepted
+func parseFormatVerb(layout string, i int) (formatVerb, int, error) {
+	v := formatVerb{width: -1, precision: -1}
+	for i < len(layout) && (layout[i] == '0' || layout[i] == '-') && !strings.ContainsRune(v.flags, rune(layout[i])) {
+		v.flags += string(layout[i])
+		i++
+	}
+
+	var err error
+	if v.width, i, err = parseFormatNumber(layout, i); err != nil {
+		return v, i, err
+	}
+	if i < len(layout) && layout[i] == '.' {
+		if v.precision, i, err = parseFormatNumber(layout, i+1); err != nil {
+			return v, i, err
+		}
+		if v.precision < 0 {
+			v.precision = 0
+		}
+	}
+	if i >= len(layout) {
+		return v, i, fmt.Errorf("layout ends with incomplete verb %s", v)
+	}
+
+	v.verb = layout[i]
+	switch v.verb {
+	case 's', 'd', 'x', 'v':
+		if v.precision >= 0 {
+			return v, i + 1, fmt.Errorf("precision is supported only by %%f, %s provided", v)
+		}
+	case 'f':
+	default:
+		return v, i + 1, fmt.Errorf("unsupported verb %s, expected one of %%s, %%d, %%f, %%x, %%v", v)
+	}
+	return v, i + 1, nil
+}
+
+// formatInt converts the value for the integer verb, the numbers with the fraction are not truncated silently
+func formatInt(value StaticValue) (int64, error) {
+	if value.IsInt() {
+		return value.IntValue()
+	}
+	f, err := value.FloatValue()
+	if err != nil {
+		return 0, err
+	}
+	if f != math2.Trunc(f) || math2.IsInf(f, 0) {
+		return 0, fmt.Errorf("%s is not an integer", value)
+	}
+	return int64(f), nil
+}
+
+// formatValue renders the argument with the verb, the arguments are coerced the same way as by the casting functions
+func formatValue(features Features, v formatVerb, value StaticValue) (string, error) {
+	var arg interface{}
+	var err error
+	verb := v
+	switch v.verb {
+	case 's', 'v':
+		// %v renders the value the same way as string()
+		arg = castStaticToString(features, value)
+		verb.verb = 's'
+	case 'd':
+		arg, err = formatInt(value)
+	case 'f':
+		arg, err = value.FloatValue()
+	case 'x':
+		if value.IsString() {
+			arg, err = value.StringValue()
+		} else {
+			arg, err = formatInt(value)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(verb.String(), arg), nil
+}
+
+// format renders the layout with the printf-style verbs, each verb consumes the next argument,
+// and all the arguments should be consumed; %% is the literal % sign. The arguments are numbered
+// as the function arguments, so the first one formatted is the 2nd, after the layout
+func format(features Features, layout string, args []StaticValue) (string, error) {
+	var b strings.Builder
+	used := 0
+	for i := 0; i < len(layout); {
+		if layout[i] != '%' {
+			b.WriteByte(layout[i])
+			i++
+			continue
+		}
+		if i+1 < len(layout) && layout[i+1] == '%' {
+			b.WriteByte('%')
+			i += 2
+			continue
+		}
+
+		v, next, err := parseFormatVerb(layout, i+1)
+		if err != nil {
+			return "", fmt.Errorf("verb at offset %d of the layout: %v", i, err)
+		}
+		i = next
+		if used >= len(args) {
+			return "", fmt.Errorf("verb %s has no argument at position %d", v, used+2)
+		}
+		s, err := formatValue(features, v, args[used])
+		if err != nil {
+			return "", fmt.Errorf("argument %d for verb %s: %v", used+2, v, err)
+		}
+		b.WriteString(s)
+		used++
+	}
+	if used < len(args) {
+		return "", fmt.Errorf("argument %d is not used by the layout, it has %d verbs", used+2, used)
+	}
+	return b.String(), nil
+}
+
+// toFixed renders the number with the fixed number of decimal places
+func toFixed(value float64, digits int64) (string, error) {
+	if digits < 0 || digits > maxFormatWidth {
+		return "", fmt.Errorf("number of digits should be between 0 and %d, %d provided", maxFormatWidth, digits)
+	}
+	return strconv.FormatFloat(value, 'f', int(digits), 64), nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdlibFormat(t *testing.T) {
+	tests := map[string]string{
+		`format("no verbs")`:                                 `"no verbs"`,
+		`format("%s-%d", "build", 42)`:                       `"build-42"`,
+		`format("%05d", 42)`:                                 `"00042"`,
+		`format("%-4d|", 7)`:                                 `"7   |"`,
+		`format("%.2f", 0.1 + 0.2)`:                          `"0.30"`,
+		`format("%8.3f", 3.14159)`:                           `"   3.142"`,
+		`format("%x", 255)`:                                  `"ff"`,
+		`format("%x", "ab")`:                                 `"6162"`,
+		`format("%d%%", "75")`:                               `"75%"`,
+		`format("%d", 4.0)`:                                  `"4"`,
+		`format("%v/%v", 0.1 + 0.2, true)`:                   MustCompile(`string(0.1 + 0.2) + "/true"`).String(),
+		`format("%s", list(1, 2))`:                           `"[1,2]"`,
+		`toFixed(0.1 + 0.2, 2)`:                              `"0.30"`,
+		`toFixed(2, 3)`:                                      `"2.000"`,
+		`toFixed(1.005, 0)`:                                  `"1"`,
+		`toFixed(-1.25, 1)`:                                  `"-1.2"`,
+		`format("%s: %s", "passed", toFixed(99.5, 1)) + "%"`: `"passed: 99.5%"`,
+	}
+	for expr, expected := range tests {
+		v, err := Compile(expr)
+		if assert.NoError(t, err, expr) {
+			assert.Equal(t, expected, v.String(), expr)
+		}
+	}
+}
+
+func TestStdlibFormatErrors(t *testing.T) {
+	tests := map[string]string{
+		`format("%s-%d", "build")`: `"format" function: verb %d has no argument at position 3`,
+		`format("%s", "a", "b")`:   `"format" function: argument 3 is not used by the layout, it has 1 verbs`,
+		`format("%d", 1.5)`:        `"format" function: argument 2 for verb %d: 1.5 is not an integer`,
+		`format("%d", "abc")`:      `"format" function: argument 2 for verb %d:`,
+		`format("a %q", "x")`:      `"format" function: verb at offset 2 of the layout: unsupported verb %q`,
+		`format("%.2d", 1)`:        `"format" function: verb at offset 0 of the layout: precision is supported only by %f, %.2d provided`,
+		`format("%5", 1)`:          `"format" function: verb at offset 0 of the layout: layout ends with incomplete verb %5`,
+		`format("%1000s", "x")`:    `"format" function: verb at offset 0 of the layout: width and precision should be at most 100, 1000 provided`,
+		`toFixed("1.5", 2)`:        `"toFixed" function expects a number, "1.5" provided`,
+		`toFixed(1.5, -1)`:         `"toFixed" function: number of digits should be between 0 and 100, -1 provided`,
+		`toFixed(1.5, 101)`:        `"toFixed" function: number of digits should be between 0 and 100, 101 provided`,
+	}
+	for expr, expected := range tests {
+		_, err := Compile(expr)
+		if assert.Error(t, err, expr) {
+			assert.Contains(t, err.Error(), expected, expr)
+		}
+	}
+}
+
+func TestStdlibFormatReturnType(t *testing.T) {
+	assert.Equal(t, TypeString, MustCompile(`format("%d", x)`).Type())
+	assert.Equal(t, TypeString, MustCompile(`toFixed(x, 2)`).Type())
+	assert.Empty(t, CheckTypes(MustCompile(`upper(format("%s", x)) + toFixed(float(x), 2)`)))
+	assert.NotEmpty(t, CheckTypes(MustCompile(`toFixed(string(x), 2)`)))
+}
@@ -11,6 +11,7 @@ package expressionstcl
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -60,6 +61,15 @@ func clone(v reflect.Value) reflect.Value {
 	return v
 }
 
+// fieldPathName is the name of the field in the path of the expression errors, the same as in the serialized spec
+func fieldPathName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" || (name == "" && !f.Anonymous) {
+		return f.Name
+	}
+	return name
+}
+
 func resolve(v reflect.Value, t tagData, m []Machine, force bool, finalize bool) (changed bool, err error) {
 	if t.value == "force" {
 		force = true
@@ -81,6 +91,7 @@ func resolve(v reflect.Value, t tagData, m []Machine, force bool, finalize bool)
 		return
 	}
 
+	var errs []error
 	switch v.Kind() {
 	case reflect.Struct:
 		// TODO: Cache the tags for structs for better performance
@@ -89,12 +100,16 @@ func resolve(v reflect.Value, t tagData, m []Machine, force bool, finalize bool)
 			if vv.Type == intstr.String {
 				return resolve(v.FieldByName("StrVal"), t, m, force, finalize)
 			}
-		} else if t.value == "include" || force {
+		} else if t.value == "include" || t.value == "walk" || force {
 			tt := v.Type()
 			for i := 0; i < tt.NumField(); i++ {
 				f := tt.Field(i)
 				tagStr := f.Tag.Get("expr")
 				tag := parseTag(tagStr)
+				// Walk through the untagged fields too, to find the tagged ones in nested structs
+				if tagStr == "" && t.value == "walk" && f.IsExported() {
+					tag = t
+				}
 				if !f.IsExported() {
 					if tagStr != "" && tagStr != "-" {
 						return changed, errors.New(f.Name + ": private property marked with `expr` clause")
@@ -102,17 +117,16 @@ func resolve(v reflect.Value, t tagData, m []Machine, force bool, finalize bool)
 					continue
 				}
 				value := v.FieldByName(f.Name)
-				var ch bool
-				ch, err = resolve(value, tag, m, force, finalize)
+				ch, fieldErr := resolve(value, tag, m, force, finalize)
 				if ch {
 					changed = true
 				}
-				if err != nil {
-					return changed, errors.Wrap(err, f.Name)
+				if fieldErr != nil {
+					errs = append(errs, wrapErrors(fieldErr, f.Name, fieldPathName(f)))
 				}
 			}
 		}
-		return
+		return changed, joinErrors(errs...)
 	case reflect.Slice:
 		if t.value == "" && !force {
 			return changed, nil
@@ -123,38 +137,43 @@ func resolve(v reflect.Value, t tagData, m []Machine, force bool, finalize bool)
 				changed = true
 			}
 			if err != nil {
-				return changed, errors.Wrap(err, fmt.Sprintf("%d", i))
+				errs = append(errs, wrapErrors(err, fmt.Sprintf("%d", i), fmt.Sprintf("[%d]", i)))
 			}
 		}
-		return
+		return changed, joinErrors(errs...)
 	case reflect.Map:
 		if t.value == "" && t.key == "" && !force {
 			return changed, nil
 		}
-		for _, k := range v.MapKeys() {
+		// Sort the keys, so the errors are reported in deterministic order
+		keys := v.MapKeys()
+		sort.SliceStable(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		for _, k := range keys {
 			if (t.value != "" || force) && !hasUnexportedFields(v.MapIndex(k)) {
 				// It's not possible to get a pointer to map element,
 				// so we need to copy it and reassign
 				item := clone(v.MapIndex(k))
-				var ch bool
-				ch, err = resolve(item, t, m, force, finalize)
+				ch, err := resolve(item, t, m, force, finalize)
 				if ch {
 					changed = true
 				}
 				if err != nil {
-					return changed, errors.Wrap(err, k.String())
+					errs = append(errs, wrapErrors(err, k.String(), k.String()))
+				} else {
+					v.SetMapIndex(k, item)
 				}
-				v.SetMapIndex(k, item)
 			}
 			if (t.key != "" || force) && !hasUnexportedFields(k) && !hasUnexportedFields(v.MapIndex(k)) {
 				key := clone(k)
-				var ch bool
-				ch, err = resolve(key, tagData{value: t.key}, m, force, finalize)
+				ch, err := resolve(key, tagData{value: t.key}, m, force, finalize)
 				if ch {
 					changed = true
 				}
 				if err != nil {
-					return changed, errors.Wrap(err, "key("+k.String()+")")
+					errs = append(errs, wrapErrors(err, "key("+k.String()+")", "key("+k.String()+")"))
+					continue
 				}
 				if !key.Equal(k) {
 					item := clone(v.MapIndex(k))
@@ -163,8 +182,13 @@ func resolve(v reflect.Value, t tagData, m []Machine, force bool, finalize bool)
 				}
 			}
 		}
-		return
+		return changed, joinErrors(errs...)
 	case reflect.String:
+		// Fail on unknown accessors already while resolving,
+		// so they are reported together with the function errors
+		if finalize {
+			m = append(m[:len(m):len(m)], FinalizerFail)
+		}
 		if t.value == "expression" {
 			var expr Expression
 			str := v.String()
@@ -176,7 +200,7 @@ func resolve(v reflect.Value, t tagData, m []Machine, force bool, finalize bool)
 			if finalize {
 				expr2, err := expr.Resolve(FinalizerFail)
 				if err != nil {
-					return changed, errors.Wrap(err, "resolving the value")
+					return changed, wrapErrors(err, "resolving the value", "")
 				}
 				vv, _ = expr2.Static().StringValue()
 			} else {
@@ -199,7 +223,7 @@ func resolve(v reflect.Value, t tagData, m []Machine, force bool, finalize bool)
 			if finalize {
 				expr2, err := expr.Resolve(FinalizerFail)
 				if err != nil {
-					return changed, errors.Wrap(err, "resolving the value")
+					return changed, wrapErrors(err, "resolving the value", "")
 				}
 				vv, _ = expr2.Static().StringValue()
 			} else {
@@ -260,3 +284,10 @@ func Finalize(t interface{}, m ...Machine) error {
 func FinalizeForce(t interface{}, m ...Machine) error {
 	return finalize(t, tagData{value: "force"}, m...)
 }
+
+// ResolveStruct resolves the fields marked with `expr:"expression"` or `expr:"template"` tag in place,
+// it walks through all the nested structs, pointers, slices and maps, so they don't need `expr:"include"` tag;
+// the errors of all the fields are reported together, with their paths
+func ResolveStruct(t interface{}, m ...Machine) error {
+	return finalize(t, tagData{value: "walk"}, m...)
+}
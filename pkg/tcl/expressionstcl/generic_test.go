@@ -222,3 +222,103 @@ func TestGenericForceSimplifyNested(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, want, got)
 }
+
+func TestGenericFinalizeMultipleErrors(t *testing.T) {
+	got := testObj{
+		Expr:         "a + b + a",
+		Tmpl:         "{{c}}-{{dummy}}-{{shellquote(c, d)}}",
+		SliceExprStr: []string{"ten", "e"},
+		MapValTmpl:   map[string]string{"z": "{{f}}", "y": "{{g}}", "x": "{{dummy}}"},
+	}
+	err := Finalize(&got, testMachine)
+
+	assert.EqualError(t, err, "Expr: error while accessing a: unknown variable\n"+
+		"Expr: error while accessing b: unknown variable\n"+
+		"Tmpl: error while accessing c: unknown variable\n"+
+		"Tmpl: error while accessing d: unknown variable\n"+
+		"SliceExprStr: 1: error while accessing e: unknown variable\n"+
+		"MapValTmpl: y: error while accessing g: unknown variable\n"+
+		"MapValTmpl: z: error while accessing f: unknown variable")
+	assert.Equal(t, "10", got.SliceExprStr[0])
+	assert.Equal(t, "test", got.MapValTmpl["x"])
+}
+
+func TestGenericFinalizeMissingProperty(t *testing.T) {
+	got := testObj{
+		Expr: "config.missing + config.other.value",
+	}
+	err := Finalize(&got, testMachine)
+
+	assert.EqualError(t, err, "Expr: error while accessing config.missing: unknown variable\n"+
+		"Expr: error while accessing config.other.value: unknown variable")
+}
+
+func TestGenericFinalizeMultipleFunctionErrors(t *testing.T) {
+	got := testObj{
+		Expr: `int(dummy) + missing + int(dummy + "2")`,
+		Tmpl: `{{int(dummy)}}-{{shellquote(int(dummy))}}`,
+	}
+	err := Finalize(&got, testMachine)
+
+	assert.EqualError(t, err, `Expr: int(dummy): error while converting value to number: test: strconv.ParseFloat: parsing "test": invalid syntax`+"\n"+
+		`Expr: error while accessing missing: unknown variable`+"\n"+
+		`Expr: int(dummy+"2"): error while converting value to number: test2: strconv.ParseFloat: parsing "test2": invalid syntax`+"\n"+
+		`Tmpl: int(dummy): error while converting value to number: test: strconv.ParseFloat: parsing "test": invalid syntax`)
+}
+
+type testResolveStep struct {
+	Name    string            `expr:"template"`
+	Command []string          `expr:"template"`
+	Env     map[string]string `expr:"template"`
+	If      string            `expr:"expression"`
+	Note    string
+}
+
+type testResolveSpec struct {
+	Image   string `expr:"template"`
+	Steps   []testResolveStep
+	Main    *testResolveStep
+	Missing *testResolveStep
+	Named   map[string]testResolveStep
+	Skipped testResolveStep `expr:"-"`
+}
+
+func TestResolveStruct(t *testing.T) {
+	got := testResolveSpec{
+		Image: "{{image}}:{{tag}}",
+		Steps: []testResolveStep{
+			{Name: "build {{dummy}}", Command: []string{"make", "{{ten}}"}, If: "ten > 5", Note: "{{dummy}}"},
+			{Name: "static", Env: map[string]string{"VAR": "{{ten * 2}}"}},
+		},
+		Main:    &testResolveStep{If: `dummy == "test"`},
+		Named:   map[string]testResolveStep{"a": {Name: "{{dummy}}"}},
+		Skipped: testResolveStep{Name: "{{dummy}}"},
+	}
+	err := ResolveStruct(&got, testMachine, NewMachine().Register("image", "alpine").Register("tag", "3.19"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, testResolveSpec{
+		Image: "alpine:3.19",
+		Steps: []testResolveStep{
+			{Name: "build test", Command: []string{"make", "10"}, If: "true", Note: "{{dummy}}"},
+			{Name: "static", Env: map[string]string{"VAR": "20"}},
+		},
+		Main:    &testResolveStep{If: "true"},
+		Named:   map[string]testResolveStep{"a": {Name: "test"}},
+		Skipped: testResolveStep{Name: "{{dummy}}"},
+	}, got)
+}
+
+func TestResolveStructErrors(t *testing.T) {
+	got := testResolveSpec{
+		Image: "{{image}}",
+		Steps: []testResolveStep{{Name: "ok"}, {Command: []string{"{{a}}"}, If: "b"}},
+		Main:  &testResolveStep{Env: map[string]string{"X": "{{c}}"}},
+	}
+	err := ResolveStruct(&got, testMachine)
+
+	assert.EqualError(t, err, "Image: error while accessing image: unknown variable\n"+
+		"Steps: 1: Command: 0: error while accessing a: unknown variable\n"+
+		"Steps: 1: If: error while accessing b: unknown variable\n"+
+		"Main: Env: X: error while accessing c: unknown variable")
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type ErrorKind string
+
+const (
+	ErrorKindAccessor  ErrorKind = "accessor"
+	ErrorKindFunction  ErrorKind = "function"
+	ErrorKindMath      ErrorKind = "math"
+	ErrorKindCallStack ErrorKind = "call_stack"
+	ErrorKindOther     ErrorKind = "other"
+)
+
+// ResolveHooks observes the expressions resolution,
+// the implementation should be safe for concurrent use, as resolutions may run in parallel
+type ResolveHooks interface {
+	OnResolveStart()
+	OnResolveEnd(duration time.Duration, passes int)
+	OnFunctionCall(name string, duration time.Duration)
+	OnError(kind ErrorKind)
+}
+
+type resolveHooksHolder struct {
+	hooks ResolveHooks
+}
+
+var globalResolveHooks atomic.Pointer[resolveHooksHolder]
+
+// SetResolveHooks sets the hooks used for all resolutions without own hooks, nil disables them
+func SetResolveHooks(hooks ResolveHooks) {
+	if hooks == nil {
+		globalResolveHooks.Store(nil)
+		return
+	}
+	globalResolveHooks.Store(&resolveHooksHolder{hooks: hooks})
+}
+
+type hooksMachine struct {
+	hooks ResolveHooks
+}
+
+// NewHooksMachine builds a machine that passes the hooks to the single resolution, i.e. expr.Resolve(m, NewHooksMachine(h)),
+// they take precedence over the global hooks
+func NewHooksMachine(hooks ResolveHooks) Machine {
+	return &hooksMachine{hooks: hooks}
+}
+
+func (h *hooksMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (h *hooksMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// resolveHooks finds the hooks for the resolution, it's nil when there are no hooks set
+func resolveHooks(m []Machine) ResolveHooks {
+	for i := range m {
+		if h, ok := m[i].(*hooksMachine); ok {
+			return h.hooks
+		}
+	}
+	if holder := globalResolveHooks.Load(); holder != nil {
+		return holder.hooks
+	}
+	return nil
+}
+
+// kindError marks the error with its kind for the hooks, without changing the message
+type kindError struct {
+	error
+	kind ErrorKind
+}
+
+func newKindError(kind ErrorKind, err error) error {
+	return &kindError{error: err, kind: kind}
+}
+
+func (e *kindError) Unwrap() error {
+	return e.error
+}
+
+// reportErrors notifies the hooks about each of the (possibly aggregated) errors
+func reportErrors(hooks ResolveHooks, err error) {
+	switch e := err.(type) {
+	case nil:
+		return
+	case *kindError:
+		hooks.OnError(e.kind)
+	case interface{ Unwrap() []error }:
+		for _, item := range e.Unwrap() {
+			reportErrors(hooks, item)
+		}
+	case interface{ Unwrap() error }:
+		reportErrors(hooks, e.Unwrap())
+	default:
+		hooks.OnError(ErrorKindOther)
+	}
+}
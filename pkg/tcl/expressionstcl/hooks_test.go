@@ -0,0 +1,129 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingHooks collects the emitted series as "name{label}" => count
+type recordingHooks struct {
+	mu     sync.Mutex
+	series map[string]int
+	passes []int
+}
+
+func newRecordingHooks() *recordingHooks {
+	return &recordingHooks{series: make(map[string]int)}
+}
+
+func (h *recordingHooks) inc(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.series[name]++
+}
+
+func (h *recordingHooks) OnResolveStart() {
+	h.inc("resolve_start")
+}
+
+func (h *recordingHooks) OnResolveEnd(_ time.Duration, passes int) {
+	h.inc("resolve_end")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.passes = append(h.passes, passes)
+}
+
+func (h *recordingHooks) OnFunctionCall(name string, _ time.Duration) {
+	h.inc(fmt.Sprintf("function_calls{function=%s}", name))
+}
+
+func (h *recordingHooks) OnError(kind ErrorKind) {
+	h.inc(fmt.Sprintf("errors{kind=%s}", kind))
+}
+
+func TestResolveHooks(t *testing.T) {
+	hooks := newRecordingHooks()
+	machine := NewMachine().Register("a", 1).Register("b", " x ").RegisterFunction("later", func(values ...StaticValue) (interface{}, bool, error) {
+		return "trim(b)", true, nil
+	})
+	v, err := MustCompile(`shellquote(string(a), trim(b), eval(later()))`).Resolve(machine, NewHooksMachine(hooks))
+
+	assert.NoError(t, err)
+	assert.Equal(t, `"1 x x"`, v.String())
+	// The expression from "eval" is resolved as the nested resolution
+	assert.Equal(t, map[string]int{
+		"resolve_start":                       2,
+		"resolve_end":                         2,
+		"function_calls{function=shellquote}": 1,
+		"function_calls{function=string}":     1,
+		"function_calls{function=trim}":       2,
+		"function_calls{function=later}":      1,
+		"function_calls{function=eval}":       1,
+	}, hooks.series)
+	assert.Equal(t, []int{1, 1}, hooks.passes)
+}
+
+func TestResolveHooksErrors(t *testing.T) {
+	hooks := newRecordingHooks()
+	machine := NewMachine().Register("c", "x")
+	_, err := MustCompile(`a + int(c) + b / 0`).Resolve(machine, FinalizerFail, NewHooksMachine(hooks))
+
+	assert.Error(t, err)
+	assert.Equal(t, map[string]int{
+		"resolve_start":                1,
+		"resolve_end":                  1,
+		"function_calls{function=int}": 1,
+		"errors{kind=accessor}":        2,
+		"errors{kind=function}":        1,
+	}, hooks.series)
+}
+
+func TestResolveHooksGlobal(t *testing.T) {
+	global := newRecordingHooks()
+	local := newRecordingHooks()
+	SetResolveHooks(global)
+	defer SetResolveHooks(nil)
+
+	_, _ = MustCompile(`1 / a`).Resolve(NewMachine().Register("a", 0))
+	_, _ = MustCompile(`a`).Resolve(NewMachine().Register("a", 0), NewHooksMachine(local))
+
+	assert.Equal(t, 1, global.series["errors{kind=math}"])
+	assert.Equal(t, 0, local.series["errors{kind=math}"])
+	assert.Equal(t, 1, local.series["resolve_end"])
+	assert.Equal(t, global.series["resolve_start"], global.series["resolve_end"])
+}
+
+func TestResolveHooksConcurrent(t *testing.T) {
+	hooks := newRecordingHooks()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _ = MustCompile(`string(a)`).Resolve(NewMachine().Register("a", i), NewHooksMachine(hooks))
+		}(i)
+	}
+	wg.Wait()
+
+	names := make([]string, 0, len(hooks.series))
+	for name := range hooks.series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"function_calls{function=string}", "resolve_end", "resolve_start"}, names)
+	assert.Equal(t, 50, hooks.series["function_calls{function=string}"])
+	assert.Equal(t, 50, hooks.series["resolve_end"])
+}
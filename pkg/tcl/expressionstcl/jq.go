@@ -0,0 +1,68 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultJqTimeout is the deadline of the "jq" query, when it's not provided in the options
+var DefaultJqTimeout = 10 * time.Second
+
+type jqOptions struct {
+	timeout time.Duration
+	// first returns the first result instead of the list, or none when there are no results
+	first bool
+}
+
+// parseJqOptions reads the options map, the timeout is either a duration string or a number of seconds
+func parseJqOptions(value StaticValue) (jqOptions, error) {
+	options := jqOptions{timeout: DefaultJqTimeout}
+	m, err := value.MapValue()
+	if err != nil {
+		return options, err
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		v := NewValue(m[key])
+		switch key {
+		case "timeout":
+			if v.IsString() {
+				str, _ := v.StringValue()
+				options.timeout, err = time.ParseDuration(str)
+			} else if v.IsNumber() {
+				var seconds float64
+				seconds, err = v.FloatValue()
+				options.timeout = time.Duration(seconds * float64(time.Second))
+			} else {
+				err = fmt.Errorf("expected duration, %s provided", v)
+			}
+			if err == nil && options.timeout <= 0 {
+				err = fmt.Errorf("expected positive duration, %s provided", v)
+			}
+			if err != nil {
+				return options, fmt.Errorf("invalid timeout: %v", err)
+			}
+		case "first":
+			if !v.IsBool() {
+				return options, fmt.Errorf("invalid first: expected boolean, %s provided", v)
+			}
+			options.first, _ = v.BoolValue()
+		default:
+			return options, fmt.Errorf("unknown option: %s", key)
+		}
+	}
+	return options, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJqFirst(t *testing.T) {
+	assert.Equal(t, `"a"`, MustCompile(`jq({"items": [{"name": "a"}, {"name": "b"}]}, ".items[].name", {"first": true})`).String())
+	assert.Equal(t, `5`, MustCompile(`jq([1,2,3,4,5], ". | max", {"first": true})`).String())
+	assert.Equal(t, `null`, MustCompile(`jq([1,2,3], ".[] | select(. > 5)", {"first": true})`).String())
+	assert.Equal(t, `[]`, MustCompile(`jq([1,2,3], ".[] | select(. > 5)", {"first": false})`).String())
+	assert.Equal(t, `["a","b"]`, MustCompile(`jq({"items": [{"name": "a"}, {"name": "b"}]}, ".items[].name", {})`).String())
+	assert.Equal(t, `["a","b"]`, MustCompile(`jq({"items": [{"name": "a"}, {"name": "b"}]}, ".items[].name", null)`).String())
+}
+
+func TestJqOptions(t *testing.T) {
+	options, err := parseJqOptions(NewValue(map[string]interface{}{"timeout": "30s", "first": true}))
+	assert.NoError(t, err)
+	assert.Equal(t, jqOptions{timeout: 30 * time.Second, first: true}, options)
+
+	options, err = parseJqOptions(NewValue(map[string]interface{}{"timeout": 1.5}))
+	assert.NoError(t, err)
+	assert.Equal(t, jqOptions{timeout: 1500 * time.Millisecond}, options)
+
+	options, err = parseJqOptions(NewValue(map[string]interface{}{}))
+	assert.NoError(t, err)
+	assert.Equal(t, jqOptions{timeout: DefaultJqTimeout}, options)
+}
+
+func TestJqOptionsErrors(t *testing.T) {
+	_, err := Compile(`jq([1], ".[]", {"timeout": "abc"})`)
+	assert.ErrorContains(t, err, `invalid timeout`)
+	_, err = Compile(`jq([1], ".[]", {"timeout": "-1s"})`)
+	assert.ErrorContains(t, err, `expected positive duration`)
+	_, err = Compile(`jq([1], ".[]", {"first": "yes"})`)
+	assert.ErrorContains(t, err, `invalid first`)
+	_, err = Compile(`jq([1], ".[]", {"unknown": 1})`)
+	assert.ErrorContains(t, err, `unknown option: unknown`)
+	_, err = Compile(`jq([1], ".[]", "first")`)
+	assert.ErrorContains(t, err, `expects 3rd argument to be a map of options`)
+	_, err = Compile(`jq([1], ".[]", {}, {})`)
+	assert.ErrorContains(t, err, `expects between 2 and 3 arguments, 4 provided`)
+}
+
+func TestJqTimeout(t *testing.T) {
+	_, err := Compile(`jq(null, "last(range(1e12))", {"timeout": "10ms"})`)
+	assert.ErrorContains(t, err, `"jq" error: executing: last(range(1e12))`)
+	assert.ErrorContains(t, err, `context deadline exceeded`)
+}
+
+func TestJqContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := EvalString(`jq(items, ".[]")`, map[string]interface{}{"items": []int{1, 2}}, WithContext(ctx))
+	assert.ErrorContains(t, err, `"jq" error: executing: .[]`)
+	assert.ErrorContains(t, err, `context canceled`)
+
+	_, err = MustCompile(`jq(items, ".[]")`).Resolve(NewMachine().Register("items", []int{1, 2}), NewContextMachine(ctx))
+	assert.ErrorContains(t, err, `context canceled`)
+
+	v, err := EvalString(`jq(items, ".[]")`, map[string]interface{}{"items": []int{1, 2}}, WithContext(context.Background()))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1.0, 2.0}, v)
+}
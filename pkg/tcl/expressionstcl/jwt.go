@@ -0,0 +1,71 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// jwtNow is the time the token expiration is compared against, it's replaced in tests
+var jwtNow = time.Now
+
+// decodeJWTUnverified decodes header and claims of the token from its base64url encoded parts,
+// the signature is NOT verified, so the content can't be trusted for authorization
+func decodeJWTUnverified(token string) (map[string]interface{}, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token: expected 3 dot separated parts, %d found", len(parts))
+	}
+
+	header, err := decodeJWTPart("header", parts[0])
+	if err != nil {
+		return nil, err
+	}
+	claims, err := decodeJWTPart("claims", parts[1])
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"header": header, "claims": claims}, nil
+}
+
+func decodeJWTPart(name, part string) (map[string]interface{}, error) {
+	// The padding is not allowed by the specification, but some encoders add it anyway
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return nil, fmt.Errorf("malformed token %s: %v", name, err)
+	}
+	var result map[string]interface{}
+	if err = json.Unmarshal(data, &result); err != nil || result == nil {
+		return nil, fmt.Errorf("malformed token %s: expected JSON object", name)
+	}
+	return result, nil
+}
+
+// jwtExpiredUnverified checks if the "exp" claim of the token is not after the current time with skew,
+// token without "exp" claim never expires, the signature is NOT verified
+func jwtExpiredUnverified(token string, skew time.Duration) (bool, error) {
+	decoded, err := decodeJWTUnverified(token)
+	if err != nil {
+		return false, err
+	}
+	exp, ok := decoded["claims"].(map[string]interface{})["exp"]
+	if !ok || exp == nil {
+		return false, nil
+	}
+	seconds, ok := exp.(float64)
+	if !ok {
+		return false, fmt.Errorf(`"exp" claim should be a number, %v provided`, exp)
+	}
+	expiresAt := time.Unix(0, int64(seconds*float64(time.Second)))
+	return !jwtNow().Before(expiresAt.Add(skew)), nil
+}
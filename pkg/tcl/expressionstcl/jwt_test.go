@@ -0,0 +1,89 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func jwtPart(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func jwtToken(claims string) string {
+	return jwtPart(`{"alg":"HS256","typ":"JWT"}`) + "." + jwtPart(claims) + ".c2lnbmF0dXJl"
+}
+
+func TestJWTDecodeUnverified(t *testing.T) {
+	// "ünï" encodes to non-padded base64url containing "-" and "_" characters
+	token := jwtToken(`{"sub":"ünï~~?","name":"Zażółć 🚀","n":1}`)
+
+	v, err := EvalString(fmt.Sprintf(`jwtDecodeUnverified("%s")`, token), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"header": map[string]interface{}{"alg": "HS256", "typ": "JWT"},
+		"claims": map[string]interface{}{"sub": "ünï~~?", "name": "Zażółć 🚀", "n": float64(1)},
+	}, v)
+
+	v, err = EvalString(`token.claims.name`, map[string]interface{}{"token": v})
+	assert.NoError(t, err)
+	assert.Equal(t, "Zażółć 🚀", v)
+}
+
+func TestJWTDecodeUnverifiedPadded(t *testing.T) {
+	header := base64.URLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	claims := base64.URLEncoding.EncodeToString([]byte(`{"a":"b"}`))
+
+	decoded, err := decodeJWTUnverified(header + "." + claims + ".")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "b"}, decoded["claims"])
+}
+
+func TestJWTDecodeUnverifiedMalformed(t *testing.T) {
+	tests := map[string]string{
+		"not-a-token":                "expected 3 dot separated parts, 1 found",
+		"a.b.c.d":                    "expected 3 dot separated parts, 4 found",
+		"%%%." + jwtPart(`{}`) + ".": "malformed token header",
+		jwtPart(`{}`) + "." + jwtPart(`[1]`) + ".":  "malformed token claims: expected JSON object",
+		jwtPart(`null`) + "." + jwtPart(`{}`) + ".": "malformed token header: expected JSON object",
+	}
+	for token, expected := range tests {
+		_, err := Compile(fmt.Sprintf(`jwtDecodeUnverified("%s")`, token))
+		assert.ErrorContains(t, err, expected, token)
+	}
+}
+
+func TestJWTExpiredUnverified(t *testing.T) {
+	defer func(now func() time.Time) { jwtNow = now }(jwtNow)
+	jwtNow = func() time.Time { return time.Unix(1700000000, 0) }
+
+	tests := map[string]interface{}{
+		fmt.Sprintf(`jwtExpiredUnverified("%s")`, jwtToken(`{"exp":1699999999}`)):      true,
+		fmt.Sprintf(`jwtExpiredUnverified("%s")`, jwtToken(`{"exp":1700000000}`)):      true,
+		fmt.Sprintf(`jwtExpiredUnverified("%s")`, jwtToken(`{"exp":1700000001}`)):      false,
+		fmt.Sprintf(`jwtExpiredUnverified("%s", 30)`, jwtToken(`{"exp":1699999990}`)):  false,
+		fmt.Sprintf(`jwtExpiredUnverified("%s", -30)`, jwtToken(`{"exp":1700000010}`)): true,
+		fmt.Sprintf(`jwtExpiredUnverified("%s")`, jwtToken(`{"sub":"ünï"}`)):           false,
+	}
+	for expr, expected := range tests {
+		v, err := EvalString(expr, nil)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+
+	_, err := Compile(fmt.Sprintf(`jwtExpiredUnverified("%s")`, jwtToken(`{"exp":"tomorrow"}`)))
+	assert.ErrorContains(t, err, `"exp" claim should be a number, tomorrow provided`)
+	_, err = Compile(fmt.Sprintf(`jwtExpiredUnverified("%s", "soon")`, jwtToken(`{"exp":1}`)))
+	assert.ErrorContains(t, err, "expects skew to be number of seconds")
+}
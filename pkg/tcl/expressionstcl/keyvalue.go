@@ -0,0 +1,140 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPairSeparator     = ","
+	defaultKeyValueSeparator = "="
+)
+
+// indexUnquoted finds the first occurrence of the separator outside single or double quotes,
+// backslash escapes the next character inside double quotes
+func indexUnquoted(str, sep string) (int, error) {
+	var quote byte
+	for i := 0; i < len(str); i++ {
+		switch {
+		case quote == '"' && str[i] == '\\':
+			i++
+		case quote != 0:
+			if str[i] == quote {
+				quote = 0
+			}
+		case str[i] == '"' || str[i] == '\'':
+			quote = str[i]
+		case strings.HasPrefix(str[i:], sep):
+			return i, nil
+		}
+	}
+	if quote != 0 {
+		return -1, fmt.Errorf("unterminated quote in %q", str)
+	}
+	return -1, nil
+}
+
+// unquoteKeyValue trims the whitespace and removes quotes surrounding the whole key or value
+func unquoteKeyValue(str string) (string, error) {
+	str = strings.TrimSpace(str)
+	if len(str) < 2 || str[0] != str[len(str)-1] {
+		return str, nil
+	}
+	if str[0] == '\'' {
+		return str[1 : len(str)-1], nil
+	}
+	if str[0] == '"' {
+		v, err := strconv.Unquote(str)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value %s: %v", str, err)
+		}
+		return v, nil
+	}
+	return str, nil
+}
+
+// parseKeyValue converts "k=v,k2=v2" string to map, separators inside quoted values are not splitting it
+func parseKeyValue(str, pairSep, kvSep string) (map[string]interface{}, error) {
+	if pairSep == "" || kvSep == "" || pairSep == kvSep {
+		return nil, errors.New("separators should be non-empty and different")
+	}
+	result := make(map[string]interface{})
+	for rest := str; rest != ""; {
+		end, err := indexUnquoted(rest, pairSep)
+		if err != nil {
+			return nil, err
+		}
+		segment := rest
+		if end == -1 {
+			rest = ""
+		} else {
+			segment, rest = rest[:end], rest[end+len(pairSep):]
+		}
+		if strings.TrimSpace(segment) == "" {
+			continue
+		}
+
+		index, err := indexUnquoted(segment, kvSep)
+		if err != nil {
+			return nil, err
+		}
+		if index == -1 {
+			return nil, fmt.Errorf("missing %q separator in %q", kvSep, strings.TrimSpace(segment))
+		}
+		key, err := unquoteKeyValue(segment[:index])
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			return nil, fmt.Errorf("missing key in %q", strings.TrimSpace(segment))
+		}
+		value, err := unquoteKeyValue(segment[index+len(kvSep):])
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// quoteKeyValue quotes the key or value when it wouldn't be parsed back as it is
+func quoteKeyValue(str, pairSep, kvSep string) string {
+	if str != strings.TrimSpace(str) || strings.Contains(str, pairSep) || strings.Contains(str, kvSep) ||
+		strings.ContainsAny(str, `"'\`) {
+		return strconv.Quote(str)
+	}
+	return str
+}
+
+// buildKeyValue encodes map as "k=v,k2=v2" string, keys are sorted
+func buildKeyValue(m map[string]interface{}, pairSep, kvSep string) (string, error) {
+	if pairSep == "" || kvSep == "" || pairSep == kvSep {
+		return "", errors.New("separators should be non-empty and different")
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		value, err := NewValue(m[key]).StringValue()
+		if err != nil {
+			return "", fmt.Errorf("value of %q: %v", key, err)
+		}
+		pairs[i] = quoteKeyValue(key, pairSep, kvSep) + kvSep + quoteKeyValue(value, pairSep, kvSep)
+	}
+	return strings.Join(pairs, pairSep), nil
+}
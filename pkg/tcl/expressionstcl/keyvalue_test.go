@@ -0,0 +1,75 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeyValue(t *testing.T) {
+	assert.Equal(t, `{"app":"api","tier":"backend"}`, MustCompile(`parseKeyValue("app=api,tier=backend")`).String())
+	assert.Equal(t, `{"app":"api","tier":"backend"}`, MustCompile(`parseKeyValue(" app = api ,  tier=backend , ")`).String())
+	assert.Equal(t, `{"a":"1","b":"2"}`, MustCompile(`parseKeyValue("a:1;b:2", ";", ":")`).String())
+	assert.Equal(t, `{"a":"1","b":""}`, MustCompile(`parseKeyValue("a=1,b=")`).String())
+	assert.Equal(t, `{"url":"http://x?a=b"}`, MustCompile(`parseKeyValue("url=http://x?a=b")`).String())
+	assert.Equal(t, `{}`, MustCompile(`parseKeyValue("")`).String())
+	assert.Equal(t, `"backend"`, MustCompile(`parseKeyValue("app=api,tier=backend").tier`).String())
+}
+
+func TestParseKeyValueQuoted(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		`args="-a,-b=1", env=prod`:   {"args": "-a,-b=1", "env": "prod"},
+		`msg='say "hi", bye',x=1`:    {"msg": `say "hi", bye`, "x": "1"},
+		`"key,with=sep"=" padded "`:  {"key,with=sep": " padded "},
+		`q="escaped \" quote, here"`: {"q": `escaped " quote, here`},
+	}
+	for str, expected := range tests {
+		v, err := parseKeyValue(str, defaultPairSeparator, defaultKeyValueSeparator)
+		assert.NoError(t, err, str)
+		assert.Equal(t, expected, v, str)
+	}
+}
+
+func TestParseKeyValueErrors(t *testing.T) {
+	_, err := Compile(`parseKeyValue("app=api,tier,env=prod")`)
+	assert.ErrorContains(t, err, `missing "=" separator in "tier"`)
+	_, err = Compile(`parseKeyValue("app=api; =prod", ";")`)
+	assert.ErrorContains(t, err, `missing key in "=prod"`)
+	_, err = Compile(`parseKeyValue("a=1,b=2", ",", ",")`)
+	assert.ErrorContains(t, err, "separators should be non-empty and different")
+
+	_, err = parseKeyValue(`a="unterminated,b=2`, defaultPairSeparator, defaultKeyValueSeparator)
+	assert.ErrorContains(t, err, "unterminated quote")
+}
+
+func TestToKeyValue(t *testing.T) {
+	assert.Equal(t, `"app=api,tier=backend,zone=1"`, MustCompile(`toKeyValue({"zone": 1, "tier": "backend", "app": "api"})`).String())
+	assert.Equal(t, `"a:1;b:2"`, MustCompile(`toKeyValue({"b": "2", "a": "1"}, ";", ":")`).String())
+	assert.Equal(t, `""`, MustCompile(`toKeyValue({})`).String())
+
+	_, err := Compile(`toKeyValue("app=api")`)
+	assert.ErrorContains(t, err, `"toKeyValue" function expects a map`)
+}
+
+func TestKeyValueRoundTrip(t *testing.T) {
+	values := []map[string]interface{}{
+		{"app": "api", "tier": "backend"},
+		{"args": "-a,-b=1", "msg": `say "hi"`, "path": `C:\tmp`, "pad": " x ", "key,=": "'"},
+		{"empty": ""},
+	}
+	for _, m := range values {
+		str, err := buildKeyValue(m, defaultPairSeparator, defaultKeyValueSeparator)
+		assert.NoError(t, err)
+		v, err := parseKeyValue(str, defaultPairSeparator, defaultKeyValueSeparator)
+		assert.NoError(t, err, str)
+		assert.Equal(t, m, v, str)
+	}
+}
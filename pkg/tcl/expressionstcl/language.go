@@ -0,0 +1,254 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LanguageVersion is increased whenever the LanguageDescription changes in a way
+// that may be incompatible for the external tools, i.e. the syntax or a function is removed
+const LanguageVersion = 1
+
+// LanguageDescription is a machine-readable description of the expressions language, for external tools like editors
+type LanguageDescription struct {
+	Version   int                   `json:"version"`
+	Functions []FunctionDescription `json:"functions"`
+	Operators []OperatorDescription `json:"operators"`
+	Literals  []LiteralDescription  `json:"literals"`
+	Features  []FeatureDescription  `json:"features"`
+}
+
+// FunctionDescription describes the function from the standard library
+type FunctionDescription struct {
+	Name       string `json:"name"`
+	ReturnType Type   `json:"returnType,omitempty"`
+	// Pure functions depend only on their arguments
+	Pure bool `json:"pure,omitempty"`
+	// Features tells if the function behavior depends on the features
+	Features bool `json:"features,omitempty"`
+	// Clock tells if the function result depends on the current time
+	Clock bool `json:"clock,omitempty"`
+	// Random tells if the function generates random values, see NewRandomMachine
+	Random bool `json:"random,omitempty"`
+}
+
+// StdFunctionDoc describes the function from the standard library for the CLI and documentation generators
+type StdFunctionDoc struct {
+	Name string `json:"name"`
+	// Namespace is the part of the dot-separated name before the last dot, empty for the top-level functions
+	Namespace   string `json:"namespace,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Signature is a human-readable form of the call, i.e. join(any, string?) string
+	Signature string `json:"signature"`
+	MinArgs   int    `json:"minArgs"`
+	// MaxArgs is VariadicArgs for the functions accepting any number of arguments
+	MaxArgs int `json:"maxArgs"`
+	// ArgTypes are the expected types of the arguments, for variadic functions the last one applies to the rest
+	ArgTypes   []Type `json:"argTypes,omitempty"`
+	ReturnType Type   `json:"returnType,omitempty"`
+	Pure       bool   `json:"pure,omitempty"`
+}
+
+// OperatorDescription describes the operator, the binary operators with higher precedence are bound first
+type OperatorDescription struct {
+	Symbol     string `json:"symbol"`
+	Precedence int    `json:"precedence"`
+	Unary      bool   `json:"unary,omitempty"`
+	// Alias is the canonical symbol of the operator, when this one is an alternative for it
+	Alias       string `json:"alias,omitempty"`
+	Description string `json:"description"`
+}
+
+// LiteralDescription describes the literal syntax with an example
+type LiteralDescription struct {
+	Name        string `json:"name"`
+	Example     string `json:"example"`
+	Description string `json:"description"`
+}
+
+// FeatureDescription describes the feature with its state in effect
+type FeatureDescription struct {
+	Name        Feature `json:"name"`
+	Enabled     bool    `json:"enabled"`
+	Description string  `json:"description"`
+}
+
+// LintIssue is the problem found in the expression source
+type LintIssue struct {
+	// Index is the offset in the source where the issue is found, or -1 when it's not known
+	Index   int    `json:"index"`
+	Message string `json:"message"`
+}
+
+func (l LintIssue) String() string {
+	if l.Index < 0 {
+		return l.Message
+	}
+	return fmt.Sprintf("%d: %s", l.Index, l.Message)
+}
+
+var binaryOperators = []struct {
+	op          operator
+	alias       operator
+	description string
+}{
+	{op: operatorOr, description: "logical or, returns the first truthy operand"},
+	{op: operatorAnd, description: "logical and, returns the first falsy operand"},
+	{op: operatorEquals, description: "equality"},
+	{op: operatorEqualsAlias, alias: operatorEquals, description: "equality"},
+	{op: operatorNotEquals, description: "inequality"},
+	{op: operatorNotEqualsAlias, alias: operatorNotEquals, description: "inequality"},
+	{op: operatorGt, description: "greater than"},
+	{op: operatorGte, description: "greater than or equal"},
+	{op: operatorLt, description: "less than"},
+	{op: operatorLte, description: "less than or equal"},
+	{op: operatorAdd, description: "addition, or concatenation of strings"},
+	{op: operatorSubtract, description: "subtraction"},
+	{op: operatorMultiply, description: "multiplication"},
+	{op: operatorDivide, description: "division"},
+	{op: operatorModulo, description: "modulo"},
+	{op: operatorPower, description: "exponentiation"},
+}
+
+var languageLiterals = []LiteralDescription{
+	{Name: "null", Example: `null`, Description: "none value"},
+	{Name: "bool", Example: `true`, Description: "true or false"},
+	{Name: "number", Example: `-1.5e3`, Description: "JSON number"},
+	{Name: "string", Example: `"a\"b"`, Description: "JSON string, may contain literal new lines and tabs"},
+	{Name: "list", Example: `[1, "a"]`, Description: "JSON array"},
+	{Name: "map", Example: `{"a": 1}`, Description: "JSON object"},
+	{Name: "accessor", Example: `env.NAME`, Description: "value provided by the machines, with dot-separated properties, * is a wildcard"},
+	{Name: "call", Example: `join(list, ",")`, Description: "function call, the argument followed by ... is spread into multiple arguments"},
+	{Name: "template", Example: `prefix-{{ expression }}`, Description: "string with expressions embedded in double curly braces"},
+}
+
+// DescribeLanguage returns the description of the expressions language,
+// with the features in effect for the resolutions using the provided ones
+func DescribeLanguage(features Features) LanguageDescription {
+	names := stdFunctionNames()
+	functions := make([]FunctionDescription, len(names))
+	for i, name := range names {
+		fn, _ := getStdFunction(name)
+		functions[i] = FunctionDescription{
+			Name:       name,
+			ReturnType: fn.ReturnType,
+			Pure:       fn.Pure,
+			Features:   fn.FeaturesHandler != nil,
+			Clock:      fn.ClockHandler != nil,
+			Random:     fn.RandomHandler != nil,
+		}
+	}
+
+	operators := make([]OperatorDescription, 0, len(binaryOperators)+3)
+	for _, o := range binaryOperators {
+		operators = append(operators, OperatorDescription{
+			Symbol:      string(o.op),
+			Precedence:  getOperatorPriority(o.op),
+			Alias:       string(o.alias),
+			Description: o.description,
+		})
+	}
+	operators = append(operators,
+		OperatorDescription{Symbol: "?:", Precedence: -1, Description: "ternary conditional, a ? b : c"},
+		OperatorDescription{Symbol: "!", Unary: true, Description: "logical negation"},
+		OperatorDescription{Symbol: "-", Unary: true, Description: "numeric negation"},
+	)
+
+	known := ListFeatures()
+	featureList := make([]FeatureDescription, len(known))
+	for i, info := range known {
+		featureList[i] = FeatureDescription{Name: info.Name, Enabled: features.Enabled(info.Name), Description: info.Description}
+	}
+
+	return LanguageDescription{
+		Version:   LanguageVersion,
+		Functions: functions,
+		Operators: operators,
+		Literals:  languageLiterals,
+		Features:  featureList,
+	}
+}
+
+// ListStdFunctions returns the catalog of the built-in and registered functions, sorted by name
+func ListStdFunctions() []StdFunctionDoc {
+	names := stdFunctionNames()
+	docs := make([]StdFunctionDoc, 0, len(names))
+	for _, name := range names {
+		fn, ok := getStdFunction(name)
+		if !ok {
+			// Unregistered in the meantime
+			continue
+		}
+		count := fn.MaxArgs
+		if count == VariadicArgs {
+			count = fn.MinArgs + 1
+		}
+		argTypes := make([]Type, count)
+		for i := range argTypes {
+			argTypes[i] = fn.argType(i)
+		}
+		namespace := ""
+		if i := strings.LastIndex(name, "."); i != -1 {
+			namespace = name[:i]
+		}
+		docs = append(docs, StdFunctionDoc{
+			Name:        name,
+			Namespace:   namespace,
+			Description: fn.Description,
+			Signature:   stdFunctionSignature(name, fn.MinArgs, fn.MaxArgs, argTypes, fn.ReturnType),
+			MinArgs:     fn.MinArgs,
+			MaxArgs:     fn.MaxArgs,
+			ArgTypes:    argTypes,
+			ReturnType:  fn.ReturnType,
+			Pure:        fn.Pure,
+		})
+	}
+	return docs
+}
+
+// stdFunctionSignature renders the call with the argument types, the optional arguments are suffixed with "?",
+// and the rest of variadic ones with "..."
+func stdFunctionSignature(name string, minArgs, maxArgs int, argTypes []Type, returnType Type) string {
+	args := make([]string, len(argTypes))
+	for i, t := range argTypes {
+		args[i] = typeName(t)
+		if maxArgs == VariadicArgs && i == len(argTypes)-1 {
+			args[i] += "..."
+		} else if i >= minArgs {
+			args[i] += "?"
+		}
+	}
+	signature := name + "(" + strings.Join(args, ", ") + ")"
+	if returnType != TypeUnknown {
+		signature += " " + typeName(returnType)
+	}
+	return signature
+}
+
+func typeName(t Type) string {
+	if t == TypeUnknown {
+		return "any"
+	}
+	return string(t)
+}
+
+// ValidateSyntax checks only the grammar of the expression, without knowing the machines nor the types,
+// so i.e. the unknown functions and invalid arguments are not reported
+func ValidateSyntax(source string) []LintIssue {
+	t, i, err := tokenize(source, 0)
+	if err != nil {
+		return []LintIssue{{Index: i, Message: fmt.Sprintf("tokenizer error: %v", err)}}
+	}
+	if _, err = parse(t); err != nil {
+		return []LintIssue{{Index: -1, Message: fmt.Sprintf("parser error: %v", err)}}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeLanguageFunctions(t *testing.T) {
+	desc := DescribeLanguage(nil)
+	assert.Equal(t, LanguageVersion, desc.Version)
+	names := make(map[string]FunctionDescription)
+	for _, fn := range desc.Functions {
+		names[fn.Name] = fn
+	}
+	assert.Len(t, names, len(stdFunctions))
+	for name := range stdFunctions {
+		assert.Contains(t, names, name)
+	}
+	assert.Equal(t, FunctionDescription{Name: "floor", ReturnType: TypeInt64}, names["floor"])
+	assert.True(t, names["now"].Clock)
+	assert.True(t, names["string"].Features)
+}
+
+// zeroArgsStdFunctions are the only built-in functions accepting no arguments, so the others can't keep the zero arity
+var zeroArgsStdFunctions = map[string]struct{}{"now": {}, "uuid": {}}
+
+func TestStdFunctionsDeclareArity(t *testing.T) {
+	for name, fn := range stdFunctions {
+		assert.NotEmpty(t, fn.Description, name)
+		assert.GreaterOrEqual(t, fn.MinArgs, 0, name)
+		if _, ok := zeroArgsStdFunctions[name]; !ok {
+			assert.NotZero(t, fn.MaxArgs, name)
+		}
+		count := fn.MinArgs + 1
+		if fn.MaxArgs != VariadicArgs {
+			assert.GreaterOrEqual(t, fn.MaxArgs, fn.MinArgs, name)
+			assert.LessOrEqual(t, len(fn.ArgTypes), fn.MaxArgs, name)
+			count = fn.MaxArgs
+		}
+		// The strict arguments are checked against the declared types by CheckTypes
+		for _, i := range fn.StrictArgs {
+			assert.Less(t, i, len(fn.ArgTypes), name)
+		}
+
+		// The handler may rely on the declared arity
+		for _, n := range []int{fn.MinArgs, count} {
+			assert.NotPanics(t, func() { _, _ = CallStdFunction(name, make([]interface{}, n)...) }, name)
+		}
+		if fn.MinArgs > 0 {
+			_, err := CallStdFunction(name, make([]interface{}, fn.MinArgs-1)...)
+			assert.ErrorContains(t, err, fmt.Sprintf(`"%s" expects`, name))
+		}
+		if fn.MaxArgs != VariadicArgs {
+			_, err := CallStdFunction(name, make([]interface{}, fn.MaxArgs+1)...)
+			assert.ErrorContains(t, err, fmt.Sprintf(`"%s" expects`, name))
+		}
+	}
+}
+
+func TestStdFunctionArityErrors(t *testing.T) {
+	tests := map[string]string{
+		`join([1], ",", 1, 2)`: `"join" expects between 1 and 2 arguments, 4 provided`,
+		`default(1)`:           `"default" expects 2 arguments, 1 provided`,
+		`len()`:                `"len" expects 1 argument, 0 provided`,
+		`pick()`:               `"pick" expects at least 1 argument, 0 provided`,
+	}
+	for expr, expected := range tests {
+		_, err := Compile(expr)
+		assert.ErrorContains(t, err, expected, expr)
+	}
+
+	_, ok, err := StdLibMachine.Call("len", NewValue("a"), NewValue("b"))
+	assert.True(t, ok)
+	assert.EqualError(t, err, `"len" expects 1 argument, 2 provided`)
+	_, err = CallStdFunction("splitFirst", "a=b", "=")
+	assert.EqualError(t, err, `"splitFirst" expects 3 arguments, 2 provided`)
+	_, err = CallStdFunction("now", 1)
+	assert.EqualError(t, err, `"now" expects 0 arguments, 1 provided`)
+}
+
+func TestListStdFunctions(t *testing.T) {
+	assert.NoError(t, RegisterStdFunction("ci.hostname", hostnameFunction))
+	defer UnregisterStdFunction("ci.hostname")
+
+	docs := ListStdFunctions()
+	assert.Len(t, docs, len(stdFunctions)+1)
+	byName := make(map[string]StdFunctionDoc)
+	for i := range docs {
+		if i > 0 {
+			assert.Less(t, docs[i-1].Name, docs[i].Name)
+		}
+		byName[docs[i].Name] = docs[i]
+	}
+
+	assert.Equal(t, StdFunctionDoc{
+		Name:        "join",
+		Description: `joins the list items converted to strings with the separator, "," by default`,
+		Signature:   "join(any, string?) string",
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeUnknown, TypeString},
+		ReturnType:  TypeString,
+	}, byName["join"])
+	assert.Equal(t, "pick(any, string...)", byName["pick"].Signature)
+	assert.Equal(t, "coalesce(any...)", byName["coalesce"].Signature)
+	assert.Equal(t, "min(float64, float64...)", byName["min"].Signature)
+	assert.Equal(t, "now() string", byName["now"].Signature)
+	assert.Equal(t, "ci", byName["ci.hostname"].Namespace)
+	assert.Equal(t, "ci.hostname() string", byName["ci.hostname"].Signature)
+	assert.Empty(t, byName["len"].Namespace)
+}
+
+func TestDescribeLanguageOperators(t *testing.T) {
+	precedence := make(map[string]int)
+	for _, op := range DescribeLanguage(nil).Operators {
+		if !op.Unary {
+			precedence[op.Symbol] = op.Precedence
+		}
+	}
+	assert.Greater(t, precedence["*"], precedence["+"])
+	assert.Greater(t, precedence["**"], precedence["*"])
+	assert.Greater(t, precedence["=="], precedence["&&"])
+	assert.Equal(t, precedence["=="], precedence["="])
+}
+
+func TestDescribeLanguageFeatures(t *testing.T) {
+	desc := DescribeLanguage(Features{FeatureNumericComparison: false})
+	for _, f := range desc.Features {
+		assert.Equal(t, f.Name != FeatureNumericComparison, f.Enabled, f.Name)
+	}
+}
+
+func TestDescribeLanguageJSON(t *testing.T) {
+	desc := DescribeLanguage(nil)
+	b, err := json.Marshal(desc)
+	assert.NoError(t, err)
+	var result LanguageDescription
+	assert.NoError(t, json.Unmarshal(b, &result))
+	assert.Equal(t, desc, result)
+}
+
+func TestValidateSyntax(t *testing.T) {
+	assert.Empty(t, ValidateSyntax(`a.b + unknownFn(1, "x", args...)`))
+	assert.Empty(t, ValidateSyntax(`x ? int("a") : 2`))
+	assert.Empty(t, ValidateSyntax(``))
+
+	issues := ValidateSyntax(`1 + $`)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, 4, issues[0].Index)
+	assert.Contains(t, issues[0].Message, "tokenizer error")
+
+	issues = ValidateSyntax(`join(a, b`)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, -1, issues[0].Index)
+	assert.Contains(t, issues[0].Message, "missing call close")
+
+	issues = ValidateSyntax(`a ? b`)
+	assert.Len(t, issues, 1)
+	assert.Contains(t, issues[0].Message, "expected ternary separator")
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package libs
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+// PrometheusResolveHooks exposes the expressions resolution metrics as Prometheus collectors,
+// the functions are reported only when they are handled, so the cardinality is bound by the registered functions
+type PrometheusResolveHooks struct {
+	inProgress       prometheus.Gauge
+	resolveDuration  prometheus.Histogram
+	resolvePasses    prometheus.Histogram
+	functionCalls    *prometheus.CounterVec
+	functionDuration *prometheus.HistogramVec
+	errors           *prometheus.CounterVec
+}
+
+func NewPrometheusResolveHooks(registerer prometheus.Registerer) (*PrometheusResolveHooks, error) {
+	h := &PrometheusResolveHooks{
+		inProgress: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "testkube_expressions_resolutions_in_progress",
+			Help: "The number of expression resolutions in progress",
+		}),
+		resolveDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "testkube_expressions_resolve_duration_seconds",
+			Help:    "The duration of expression resolutions",
+			Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}),
+		resolvePasses: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "testkube_expressions_resolve_passes",
+			Help:    "The number of passes needed to resolve the expressions",
+			Buckets: []float64{1, 2, 3, 5, 10, 100, 1000},
+		}),
+		functionCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "testkube_expressions_function_calls_count",
+			Help: "The total number of expression function calls",
+		}, []string{"function"}),
+		functionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "testkube_expressions_function_duration_seconds",
+			Help:    "The duration of expression function calls",
+			Buckets: prometheus.ExponentialBuckets(0.000001, 4, 10),
+		}, []string{"function"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "testkube_expressions_errors_count",
+			Help: "The total number of expression resolution errors",
+		}, []string{"kind"}),
+	}
+	for _, c := range []prometheus.Collector{h.inProgress, h.resolveDuration, h.resolvePasses, h.functionCalls, h.functionDuration, h.errors} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func (h *PrometheusResolveHooks) OnResolveStart() {
+	h.inProgress.Inc()
+}
+
+func (h *PrometheusResolveHooks) OnResolveEnd(duration time.Duration, passes int) {
+	h.inProgress.Dec()
+	h.resolveDuration.Observe(duration.Seconds())
+	h.resolvePasses.Observe(float64(passes))
+}
+
+func (h *PrometheusResolveHooks) OnFunctionCall(name string, duration time.Duration) {
+	h.functionCalls.WithLabelValues(name).Inc()
+	h.functionDuration.WithLabelValues(name).Observe(duration.Seconds())
+}
+
+func (h *PrometheusResolveHooks) OnError(kind expressionstcl.ErrorKind) {
+	h.errors.WithLabelValues(string(kind)).Inc()
+}
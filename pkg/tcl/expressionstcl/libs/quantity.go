@@ -0,0 +1,71 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package libs
+
+import (
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+// exponentRe matches the decimal exponent, which is not the unit, as "1e3" is a plain number too
+var exponentRe = regexp.MustCompile(`^[eE][+-]?\d+$`)
+
+// QuantityInterceptor makes the arithmetic and comparison of Kubernetes resource quantities work,
+// i.e. "512Mi" + "1Gi" is "1536Mi" and "500m" < "1" is true, when both operands are quantity strings
+// and at least one of them has the unit suffix, so the plain numeric strings keep the default semantics
+func QuantityInterceptor(op string, left, right expressionstcl.StaticValue) (expressionstcl.Expression, bool, error) {
+	l, lUnit, ok := parseQuantity(left)
+	if !ok {
+		return nil, false, nil
+	}
+	r, rUnit, ok := parseQuantity(right)
+	if !ok || (!lUnit && !rUnit) {
+		return nil, false, nil
+	}
+
+	switch op {
+	case "+":
+		l.Add(r)
+		return expressionstcl.NewValue(l.String()), true, nil
+	case "-":
+		l.Sub(r)
+		return expressionstcl.NewValue(l.String()), true, nil
+	case "=", "==":
+		return expressionstcl.NewValue(l.Cmp(r) == 0), true, nil
+	case "!=", "<>":
+		return expressionstcl.NewValue(l.Cmp(r) != 0), true, nil
+	case "<":
+		return expressionstcl.NewValue(l.Cmp(r) < 0), true, nil
+	case "<=":
+		return expressionstcl.NewValue(l.Cmp(r) <= 0), true, nil
+	case ">":
+		return expressionstcl.NewValue(l.Cmp(r) > 0), true, nil
+	case ">=":
+		return expressionstcl.NewValue(l.Cmp(r) >= 0), true, nil
+	}
+	return nil, false, nil
+}
+
+// parseQuantity reads the quantity from the string value, unit tells if it has the suffix
+func parseQuantity(v expressionstcl.StaticValue) (q resource.Quantity, unit bool, ok bool) {
+	if !v.IsString() {
+		return q, false, false
+	}
+	str, _ := v.StringValue()
+	q, err := resource.ParseQuantity(str)
+	if err != nil {
+		return q, false, false
+	}
+	suffix := strings.TrimLeft(str, "+-0123456789.")
+	return q, suffix != "" && !exponentRe.MatchString(suffix), true
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package libs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+func evalQuantity(t *testing.T, expr string, vars map[string]interface{}) interface{} {
+	v, err := expressionstcl.EvalString(expr, vars, expressionstcl.WithOperatorInterceptors(QuantityInterceptor))
+	assert.NoError(t, err, expr)
+	return v
+}
+
+func TestQuantityInterceptorArithmetic(t *testing.T) {
+	assert.Equal(t, "1536Mi", evalQuantity(t, `"512Mi" + "1Gi"`, nil))
+	assert.Equal(t, "512Mi", evalQuantity(t, `"1Gi" - "512Mi"`, nil))
+	assert.Equal(t, "1500m", evalQuantity(t, `"500m" + "1"`, nil))
+	assert.Equal(t, "2Gi", evalQuantity(t, `limit + "1Gi"`, map[string]interface{}{"limit": "1Gi"}))
+}
+
+func TestQuantityInterceptorComparison(t *testing.T) {
+	assert.Equal(t, true, evalQuantity(t, `"500m" < "1"`, nil))
+	assert.Equal(t, true, evalQuantity(t, `"1Gi" == "1024Mi"`, nil))
+	assert.Equal(t, false, evalQuantity(t, `"1Gi" != "1024Mi"`, nil))
+	assert.Equal(t, true, evalQuantity(t, `"2Gi" >= "2048Mi"`, nil))
+	assert.Equal(t, false, evalQuantity(t, `"1G" > "1Gi"`, nil))
+}
+
+func TestQuantityInterceptorFallThrough(t *testing.T) {
+	assert.Equal(t, "12", evalQuantity(t, `"1" + "2"`, nil))
+	assert.Equal(t, "1e32", evalQuantity(t, `"1e3" + "2"`, nil))
+	assert.Equal(t, "abc1Gi", evalQuantity(t, `"abc" + "1Gi"`, nil))
+	assert.Equal(t, 3.0, evalQuantity(t, `1 + 2`, nil))
+	assert.Equal(t, true, evalQuantity(t, `"1Gi" == "1Gi" && true`, nil))
+
+	_, err := expressionstcl.EvalString(`"1Gi" * "2"`, nil, expressionstcl.WithOperatorInterceptors(QuantityInterceptor))
+	assert.ErrorContains(t, err, "error while converting value to number")
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import "fmt"
+
+// ResolveMaxDepth is the maximum number of nested resolutions, i.e. "eval", "map" and "filter" resolving the expression
+// inside of the resolved expression, so the self-referencing expressions fail instead of hanging; 0 disables the limit
+var ResolveMaxDepth = 128
+
+// ResolveMaxNodes is the maximum number of function calls and nested resolutions in the single resolution,
+// so the nested "map(range(...), ...)" calls can't exhaust the memory; 0 disables the limit
+var ResolveMaxNodes = 1_000_000
+
+// WithLimits overrides ResolveMaxDepth and ResolveMaxNodes for the resolution, 0 disables the limit
+func WithLimits(maxDepth, maxNodes int) ResolveOption {
+	return WithMachines(NewLimitsMachine(maxDepth, maxNodes))
+}
+
+type limitsMachine struct {
+	maxDepth int
+	maxNodes int
+	depth    int
+	nodes    *int
+}
+
+// NewLimitsMachine passes the limits to the single resolution, i.e. expr.Resolve(m, NewLimitsMachine(16, 10_000)),
+// otherwise ResolveMaxDepth and ResolveMaxNodes are used
+func NewLimitsMachine(maxDepth, maxNodes int) Machine {
+	return &limitsMachine{maxDepth: maxDepth, maxNodes: maxNodes}
+}
+
+func (l *limitsMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (l *limitsMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// count adds the resolved node, and fails when there are too many of them
+func (l *limitsMachine) count() error {
+	*l.nodes++
+	if l.maxNodes > 0 && *l.nodes > l.maxNodes {
+		return fmt.Errorf("maximum resolved nodes exceeded: %d", l.maxNodes)
+	}
+	return nil
+}
+
+// withLimits enters the resolution, it starts counting for the top-level one,
+// and goes one level deeper for the nested one, sharing the nodes count with the outer resolutions
+func withLimits(m []Machine) ([]Machine, error) {
+	for i := range m {
+		l, ok := m[i].(*limitsMachine)
+		if !ok {
+			continue
+		}
+		// The machine passed by the caller only configures the limits, each resolution counts from the start
+		if l.nodes == nil {
+			l = &limitsMachine{maxDepth: l.maxDepth, maxNodes: l.maxNodes, nodes: new(int)}
+		}
+		nested := &limitsMachine{maxDepth: l.maxDepth, maxNodes: l.maxNodes, depth: l.depth + 1, nodes: l.nodes}
+		if nested.maxDepth > 0 && nested.depth > nested.maxDepth {
+			return m, fmt.Errorf("maximum expression depth exceeded: %d", nested.maxDepth)
+		}
+		if err := nested.count(); err != nil {
+			return m, err
+		}
+		result := make([]Machine, len(m))
+		copy(result, m)
+		result[i] = nested
+		return result, nil
+	}
+	return append(m[:len(m):len(m)], &limitsMachine{maxDepth: ResolveMaxDepth, maxNodes: ResolveMaxNodes, depth: 1, nodes: new(int)}), nil
+}
+
+// countNode adds the function call to the nodes of the resolution
+func countNode(m []Machine) error {
+	for i := range m {
+		if l, ok := m[i].(*limitsMachine); ok && l.nodes != nil {
+			return l.count()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveDepthSelfReferencingEval(t *testing.T) {
+	_, err := EvalExpression(`eval(self)`, NewMachine().Register("self", "eval(self)"))
+	assert.ErrorContains(t, err, "maximum expression depth exceeded: 128")
+
+	_, err = EvalExpression(`map([1], self)`, NewMachine().Register("self", "map([1], self)"))
+	assert.ErrorContains(t, err, "maximum expression depth exceeded: 128")
+
+	_, err = EvalExpression(`filter([1], even)`, NewMachine().Register("even", "!filter([1], odd)").Register("odd", "!filter([1], even)"))
+	assert.ErrorContains(t, err, "maximum expression depth exceeded: 128")
+}
+
+func TestResolveDepthConfigurable(t *testing.T) {
+	vars := map[string]interface{}{"outer": "eval(inner)", "inner": "one + 2", "one": 1}
+
+	v, err := EvalString(`eval(outer)`, vars, WithLimits(3, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), v)
+
+	_, err = EvalString(`eval(outer)`, vars, WithLimits(2, 0))
+	assert.ErrorContains(t, err, "maximum expression depth exceeded: 2")
+
+	// The limits apply to each resolution separately
+	machine := NewMachine().Register("outer", "eval(inner)").Register("inner", "one + 2").Register("one", 1)
+	limits := NewLimitsMachine(3, 10)
+	for i := 0; i < 3; i++ {
+		expr, err := MustCompile(`eval(outer)`).Resolve(machine, limits)
+		assert.NoError(t, err)
+		assert.Equal(t, "3", expr.String())
+	}
+}
+
+func TestResolveMaxNodes(t *testing.T) {
+	vars := map[string]interface{}{"n": 100, "inner": `map(range(n), "_.value")`, "middle": "map(range(n), inner)"}
+
+	v, err := EvalString(`len(map(range(n), inner))`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), v)
+
+	_, err = EvalString(`len(map(range(n), inner))`, vars, WithLimits(0, 1000))
+	assert.ErrorContains(t, err, "maximum resolved nodes exceeded: 1000")
+
+	vars["n"] = 1000
+	_, err = EvalString(`map(range(n), middle)`, vars)
+	assert.ErrorContains(t, err, "maximum resolved nodes exceeded: 1000000")
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sortKey is a number, when the key is a number or a numeric string, a list, or its string form otherwise
+type sortKey struct {
+	number  interface{}
+	text    string
+	numeric bool
+	list    []interface{}
+	isList  bool
+}
+
+func newSortKey(v interface{}) (sortKey, error) {
+	// None is sorted as an empty string
+	if v == nil || isNone(v) {
+		return sortKey{}, nil
+	}
+	if isMap(v) || isStruct(v) {
+		return sortKey{}, fmt.Errorf("%s is not sortable", NewValue(v))
+	}
+	if isSlice(v) {
+		list, _ := toSlice(v)
+		if err := checkOrderable(list, ""); err != nil {
+			return sortKey{}, err
+		}
+		return sortKey{list: list, isList: true, text: NewValue(v).String()}, nil
+	}
+	text, err := toString(v)
+	if err != nil {
+		return sortKey{}, err
+	}
+	if isNumber(v) {
+		return sortKey{number: numberValue(v), text: text, numeric: true}, nil
+	}
+	if str, ok := v.(string); ok {
+		trimmed := strings.TrimSpace(str)
+		if i, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return sortKey{number: i, text: text, numeric: true}, nil
+		}
+		if f, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return sortKey{number: f, text: text, numeric: true}, nil
+		}
+	}
+	return sortKey{text: text}, nil
+}
+
+// sortByKeys sorts the list stably by the keys, numerically when all the keys are numbers,
+// lexicographically by their elements when all the keys are lists, and lexicographically by their string form otherwise
+func sortByKeys(list []interface{}, keys []interface{}) ([]interface{}, error) {
+	sortKeys := make([]sortKey, len(keys))
+	numeric := true
+	lists := 0
+	for i := range keys {
+		var err error
+		sortKeys[i], err = newSortKey(keys[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid key for %d index (%v): %v", i, list[i], err)
+		}
+		numeric = numeric && sortKeys[i].numeric
+		if sortKeys[i].isList {
+			lists++
+		}
+	}
+	if lists > 0 && lists < len(keys) {
+		for i := range sortKeys {
+			if !sortKeys[i].isList {
+				return nil, fmt.Errorf("invalid key for %d index (%v): %s can't be compared with lists", i, list[i], NewValue(keys[i]))
+			}
+		}
+	}
+
+	order := make([]int, len(list))
+	for i := range order {
+		order[i] = i
+	}
+	var err error
+	sort.SliceStable(order, func(a, b int) bool {
+		ka, kb := sortKeys[order[a]], sortKeys[order[b]]
+		if lists > 0 {
+			c, listErr := compareLists(ka.list, kb.list)
+			if listErr != nil && err == nil {
+				err = listErr
+			}
+			return c < 0
+		}
+		if !numeric {
+			return ka.text < kb.text
+		}
+		c, ok := compareNumbers(ka.number, kb.number)
+		if !ok && err == nil {
+			err = fmt.Errorf("can't compare %s with %s", ka.text, kb.text)
+		}
+		return c < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, len(list))
+	for i := range order {
+		result[i] = list[order[i]]
+	}
+	return result, nil
+}
+
+// flattenList flattens a single level of the nested lists, the other items are kept as they are
+func flattenList(list []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		if isSlice(item) {
+			items, _ := toSlice(item)
+			result = append(result, items...)
+		} else {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// elementIndex converts the index to the position in the list of the length, the negative index counts from the end
+func elementIndex(length int, index int64) (int, bool) {
+	if index < 0 {
+		index += int64(length)
+	}
+	if index < 0 || index >= int64(length) {
+		return 0, false
+	}
+	return int(index), true
+}
+
+// sliceBounds converts the bounds to the positions in the list of the length, the negative bounds count from the end,
+// and the bounds out of range are clamped, so the slice is empty when the start is after the end
+func sliceBounds(length int, start, end int64) (int, int) {
+	clamp := func(bound int64) int {
+		if bound < 0 {
+			bound += int64(length)
+		}
+		if bound < 0 {
+			return 0
+		}
+		if bound > int64(length) {
+			return length
+		}
+		return int(bound)
+	}
+	from, to := clamp(start), clamp(end)
+	if from > to {
+		from = to
+	}
+	return from, to
+}
@@ -0,0 +1,250 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListFunctions(t *testing.T) {
+	tests := map[string]interface{}{
+		`sort([])`:                                  []interface{}{},
+		`sort([3, 1, 2])`:                           []interface{}{1.0, 2.0, 3.0},
+		`sort(["10", 9, "1.5", -2])`:                []interface{}{-2.0, "1.5", 9.0, "10"},
+		`sort(["b", "a", "C", 10, 9])`:              []interface{}{10.0, 9.0, "C", "a", "b"},
+		`sort([true, "abc", null])`:                 []interface{}{nil, "abc", true},
+		`sort(["b", "a"], "_.index * -1")`:          []interface{}{"a", "b"},
+		`sort([[2, "b"], [1, "z"], [2, "a"], [1]])`: []interface{}{[]interface{}{1.0}, []interface{}{1.0, "z"}, []interface{}{2.0, "a"}, []interface{}{2.0, "b"}},
+		`sort([[[1, 2], 1], [[1], 2], [[1, 1]]])`:   []interface{}{[]interface{}{[]interface{}{1.0}, 2.0}, []interface{}{[]interface{}{1.0, 1.0}}, []interface{}{[]interface{}{1.0, 2.0}, 1.0}},
+		`reverse([])`:                               []interface{}{},
+		`reverse([1, "a", [2]])`:                    []interface{}{[]interface{}{2.0}, "a", 1.0},
+		`uniq(["b", "a", "b", "c", "a"])`:           []interface{}{"b", "a", "c"},
+		`uniq([1, "1", 1.0, {"a": 1}, {"a": 1}])`:   []interface{}{1.0, "1", map[string]interface{}{"a": 1.0}},
+		`flatten([])`:                               []interface{}{},
+		`flatten([1, [2, 3], [], [[4]]])`:           []interface{}{1.0, 2.0, 3.0, []interface{}{4.0}},
+	}
+	for expr, expected := range tests {
+		v, err := EvalString(expr, nil)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+}
+
+func TestSortByExpression(t *testing.T) {
+	vars := map[string]interface{}{
+		"tests": []interface{}{
+			map[string]interface{}{"name": "api", "duration": "1.5"},
+			map[string]interface{}{"name": "web", "duration": 12},
+			map[string]interface{}{"name": "db", "duration": 3},
+			map[string]interface{}{"name": "ui", "duration": 3},
+		},
+	}
+	v, err := EvalString(`map(sort(tests, "_.value.duration"), "_.value.name")`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"api", "db", "ui", "web"}, v)
+
+	v, err = EvalString(`map(reverse(sort(tests, "_.value.name")), "_.value.name")`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"web", "ui", "db", "api"}, v)
+}
+
+func TestListFunctionsErrors(t *testing.T) {
+	_, err := Compile(`sort([1, {"a": 1}])`)
+	assert.ErrorContains(t, err, `"sort" function: invalid key for 1 index (map[a:1]): {"a":1} is not sortable`)
+	_, err = Compile(`sort([[1, "a"], [1, 2]])`)
+	assert.ErrorContains(t, err, `at position 1 are not comparable`)
+	_, err = Compile(`sort([[1, [2, {"a": 1}]], [0]])`)
+	assert.ErrorContains(t, err, `"sort" function: invalid key for 0 index ([1 [2 map[a:1]]]): {"a":1} at position 1.1 is not comparable`)
+	_, err = Compile(`sort([[1], 2])`)
+	assert.ErrorContains(t, err, `"sort" function: invalid key for 1 index (2): 2 can't be compared with lists`)
+	_, err = Compile(`sort("abc")`)
+	assert.ErrorContains(t, err, `"sort" function expects 1st argument to be a list`)
+	_, err = Compile(`sort([1], "_.value", 3)`)
+	assert.ErrorContains(t, err, `"sort" expects between 1 and 2 arguments, 3 provided`)
+	_, err = Compile(`reverse()`)
+	assert.ErrorContains(t, err, `"reverse" expects 1 argument, 0 provided`)
+	_, err = Compile(`uniq([1], [2])`)
+	assert.ErrorContains(t, err, `"uniq" expects 1 argument, 2 provided`)
+	_, err = Compile(`flatten(5)`)
+	assert.ErrorContains(t, err, `"flatten" function expects 1st argument to be a list`)
+}
+
+func TestReduce(t *testing.T) {
+	tests := map[string]interface{}{
+		`reduce([1, 2, 3, 4], "_.accumulator + _.value", 0)`:                     10.0,
+		`reduce([1, 2, 3, 4], "_.accumulator + _.value")`:                        10.0,
+		`reduce([1, 2, 3], "_.accumulator + _.index", 10)`:                       int64(13),
+		`reduce(["a", "b", "c"], "_.value + _.accumulator")`:                     "cba",
+		`reduce([3, 7, 5], "_.value > _.accumulator ? _.value : _.accumulator")`: 7.0,
+		`reduce([], "_.accumulator + _.value", 5)`:                               5.0,
+		`reduce([2], "_.accumulator * _.value")`:                                 2.0,
+	}
+	for expr, expected := range tests {
+		v, err := EvalString(expr, nil)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+
+	v, err := EvalExpression(`reduce([], "_.accumulator + _.value")`)
+	assert.NoError(t, err)
+	assert.True(t, v.IsNone())
+
+	vars := map[string]interface{}{"steps": []interface{}{
+		map[string]interface{}{"name": "build", "duration": 12},
+		map[string]interface{}{"name": "test", "duration": 30},
+	}}
+	result, err := EvalString(`reduce(map(steps, "_.value.duration"), "_.accumulator + _.value", 0)`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), result)
+}
+
+func TestReduceErrors(t *testing.T) {
+	_, err := Compile(`reduce([1, 2], "_.accumulator + unknown", 0)`)
+	assert.ErrorContains(t, err, `"reduce" function: could not resolve reducer for 0 index (1): 0+unknown`)
+	_, err = Compile(`reduce([1])`)
+	assert.ErrorContains(t, err, `"reduce" expects between 2 and 3 arguments, 1 provided`)
+	_, err = Compile(`reduce("abc", "_.value")`)
+	assert.ErrorContains(t, err, `"reduce" function expects 1st argument to be a list`)
+}
+
+func TestRange(t *testing.T) {
+	tests := map[string]string{
+		`range(5)`:            `[0,1,2,3,4]`,
+		`range(0)`:            `[]`,
+		`range(-3)`:           `[]`,
+		`range(2, 5)`:         `[2,3,4]`,
+		`range(5, 2)`:         `[]`,
+		`range(0, 10, 3)`:     `[0,3,6,9]`,
+		`range(5, 0, -2)`:     `[5,3,1]`,
+		`range(0, 5, -1)`:     `[]`,
+		`len(range(1000000))`: `1000000`,
+		`map(range(3), "\"shard-\" + string(_.index)")`: `["shard-0","shard-1","shard-2"]`,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, MustCompile(expr).String(), expr)
+	}
+	assert.Equal(t, []interface{}{int64(0), int64(1)}, MustCompile(`range(2)`).Static().Value())
+}
+
+func TestRangeErrors(t *testing.T) {
+	_, err := Compile(`range(0, 5, 0)`)
+	assert.ErrorContains(t, err, `"range" function: step can't be zero`)
+	_, err = Compile(`range(1000001)`)
+	assert.ErrorContains(t, err, `"range" function: result exceeds 1000000 items`)
+	_, err = Compile(`range(-1000000000000, 1000000000000, 7)`)
+	assert.ErrorContains(t, err, `"range" function: result exceeds 1000000 items`)
+	_, err = Compile(`range(1.5)`)
+	assert.ErrorContains(t, err, `"range" function expects integer arguments, 1.5 provided as argument 1`)
+	_, err = Compile(`range()`)
+	assert.ErrorContains(t, err, `"range" expects between 1 and 3 arguments, 0 provided`)
+}
+
+func TestAtNegativeIndex(t *testing.T) {
+	tests := map[string]string{
+		`at([1,2,3], -1)`:   `3`,
+		`at([1,2,3], -3)`:   `1`,
+		`at("żółw", 1)`:     `"ó"`,
+		`at("żółw", -1)`:    `"w"`,
+		`at("a🚀b", 1)`:      `"🚀"`,
+		`len("żółw")`:       `4`,
+		`len("a🚀b")`:        `3`,
+		`bytelen("żółw")`:   `7`,
+		`bytelen("abc")`:    `3`,
+		`len(["ż", "ó"])`:   `2`,
+		`at(["ż", "ó"], 0)`: `"ż"`,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, MustCompile(expr).String(), expr)
+	}
+
+	_, err := Compile(`at([1,2,3], -4)`)
+	assert.ErrorContains(t, err, `out of bounds (length=3, index=-4)`)
+	_, err = Compile(`at("żółw", 4)`)
+	assert.ErrorContains(t, err, `at("żółw",4): out of bounds (length=4, index=4)`)
+	_, err = Compile(`bytelen([1])`)
+	assert.ErrorContains(t, err, `"bytelen" function expects string, [1] provided`)
+}
+
+func TestSlice(t *testing.T) {
+	tests := map[string]string{
+		`slice([1,2,3,4,5], 1, 3)`:    `[2,3]`,
+		`slice([1,2,3,4,5], 2)`:       `[3,4,5]`,
+		`slice([1,2,3,4,5], -2)`:      `[4,5]`,
+		`slice([1,2,3,4,5], 0, -1)`:   `[1,2,3,4]`,
+		`slice([1,2,3,4,5], -10, 2)`:  `[1,2]`,
+		`slice([1,2,3,4,5], 3, 100)`:  `[4,5]`,
+		`slice([1,2,3,4,5], 4, 1)`:    `[]`,
+		`slice([1,2,3,4,5], 10)`:      `[]`,
+		`slice([1,2,3,4,5], 1, null)`: `[2,3,4,5]`,
+		`slice([], 0, 1)`:             `[]`,
+		`slice("żółw", 1, 3)`:         `"ół"`,
+		`slice("żółw", -2)`:           `"łw"`,
+		`slice("a🚀b🚀c", 1, -1)`:       `"🚀b🚀"`,
+		`slice("abc", 5)`:             `""`,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, MustCompile(expr).String(), expr)
+	}
+
+	_, err := Compile(`slice({"a": 1}, 0)`)
+	assert.ErrorContains(t, err, `"slice" function can be performed only on lists and strings`)
+	_, err = Compile(`slice([1], "x")`)
+	assert.ErrorContains(t, err, `"slice" function expects 2nd argument to be number`)
+	_, err = Compile(`slice([1])`)
+	assert.ErrorContains(t, err, `"slice" expects between 2 and 3 arguments, 1 provided`)
+}
+
+func TestMapSpecialCharacters(t *testing.T) {
+	vars := map[string]interface{}{"items": []interface{}{"a\"b", "c,d", "e\nf", `g\h`, "{{i}}"}}
+	v, err := EvalString(`map(items, "_.value")`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, vars["items"], v)
+	v, err = EvalString(`map(items, "\"<\" + _.value + \">\"")`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"<a\"b>", "<c,d>", "<e\nf>", `<g\h>`, "<{{i}}>"}, v)
+	v, err = EvalString(`filter(items, "_.value != \"c,d\"")`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a\"b", "e\nf", `g\h`, "{{i}}"}, v)
+}
+
+func TestMapPartiallyResolved(t *testing.T) {
+	expr := MustCompile(`map([1, 2], "_.value + offset")`)
+	assert.Equal(t, `map([1,2],"_.value + offset")`, expr.String())
+	v, err := expr.Resolve(NewMachine().Register("offset", 10))
+	assert.NoError(t, err)
+	assert.Equal(t, `[11,12]`, v.String())
+}
+
+func TestMapTwoPassResolution(t *testing.T) {
+	scheduling := NewMachine().Register("items", []int{1, 2})
+	execution := NewMachine().Register("env.OFFSET", 10)
+
+	partial, err := MustCompile(`map(items, "_.value + env.OFFSET")`).Resolve(scheduling)
+	assert.NoError(t, err)
+	assert.Equal(t, `map([1,2],"_.value + env.OFFSET")`, partial.String())
+
+	v, err := partial.Resolve(execution)
+	assert.NoError(t, err)
+	assert.Equal(t, `[11,12]`, v.String())
+}
+
+func TestFilterTwoPassResolution(t *testing.T) {
+	scheduling := NewMachine().Register("items", []int{1, 2, 3})
+	execution := NewMachine().Register("env.SKIP", 2)
+
+	partial, err := MustCompile(`filter(items, "_.value != env.SKIP")`).Resolve(scheduling)
+	assert.NoError(t, err)
+	assert.Equal(t, `filter([1,2,3],"_.value != env.SKIP")`, partial.String())
+
+	v, err := partial.Resolve(execution)
+	assert.NoError(t, err)
+	assert.Equal(t, `[1,3]`, v.String())
+}
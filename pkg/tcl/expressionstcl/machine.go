@@ -8,7 +8,10 @@
 
 package expressionstcl
 
-import "strings"
+import (
+	"context"
+	"strings"
+)
 
 //go:generate mockgen -destination=./mock_machine.go -package=expressionstcl "github.com/kubeshop/testkube/pkg/tcl/expressionstcl" Machine
 type Machine interface {
@@ -19,21 +22,29 @@ type Machine interface {
 type MachineAccessorExt = func(name string) (interface{}, bool, error)
 type MachineAccessor = func(name string) (interface{}, bool)
 type MachineFn = func(values ...StaticValue) (interface{}, bool, error)
+type MachineContextFn = func(ctx context.Context, values ...StaticValue) (interface{}, bool, error)
 
 type machine struct {
 	accessors []MachineAccessorExt
-	functions map[string]MachineFn
+	functions map[string]MachineContextFn
+
+	// names and prefixes are the known accessors, for the static analysis, see ExplainableMachine
+	names    map[string]struct{}
+	prefixes []string
+	opaque   bool
 }
 
 func NewMachine() *machine {
 	return &machine{
 		accessors: make([]MachineAccessorExt, 0),
-		functions: make(map[string]MachineFn),
+		functions: make(map[string]MachineContextFn),
+		names:     make(map[string]struct{}),
 	}
 }
 
 func (m *machine) Register(name string, value interface{}) *machine {
-	return m.RegisterAccessor(func(n string) (interface{}, bool) {
+	m.names[name] = struct{}{}
+	return m.registerAccessor(func(n string) (interface{}, bool) {
 		if n == name {
 			return value, true
 		}
@@ -41,11 +52,23 @@ func (m *machine) Register(name string, value interface{}) *machine {
 	})
 }
 
+// RegisterAll registers all the values, the nested maps are available as dotted accessors, see NewMachineFromMap
+func (m *machine) RegisterAll(values map[string]interface{}) *machine {
+	for name := range values {
+		m.names[name] = struct{}{}
+		m.prefixes = append(m.prefixes, name+".")
+	}
+	return m.registerAccessor(func(n string) (interface{}, bool) {
+		return lookupMap(values, n)
+	})
+}
+
 func (m *machine) RegisterStringMap(prefix string, value map[string]string) *machine {
 	if len(prefix) > 0 {
 		prefix += "."
 	}
-	return m.RegisterAccessor(func(n string) (interface{}, bool) {
+	m.prefixes = append(m.prefixes, prefix)
+	return m.registerAccessor(func(n string) (interface{}, bool) {
 		if !strings.HasPrefix(n, prefix) {
 			return nil, false
 		}
@@ -55,22 +78,54 @@ func (m *machine) RegisterStringMap(prefix string, value map[string]string) *mac
 }
 
 func (m *machine) RegisterAccessorExt(fn MachineAccessorExt) *machine {
+	m.opaque = true
 	m.accessors = append(m.accessors, fn)
 	return m
 }
 
 func (m *machine) RegisterAccessor(fn MachineAccessor) *machine {
-	return m.RegisterAccessorExt(func(name string) (interface{}, bool, error) {
+	m.opaque = true
+	return m.registerAccessor(fn)
+}
+
+func (m *machine) registerAccessor(fn MachineAccessor) *machine {
+	m.accessors = append(m.accessors, func(name string) (interface{}, bool, error) {
 		v, ok := fn(name)
 		return v, ok, nil
 	})
+	return m
 }
 
 func (m *machine) RegisterFunction(name string, fn MachineFn) *machine {
+	return m.RegisterFunctionContext(name, func(_ context.Context, values ...StaticValue) (interface{}, bool, error) {
+		return fn(values...)
+	})
+}
+
+// RegisterFunctionContext registers the function receiving the context of the resolution, see WithContext,
+// so it may stop the long-running work when the context is cancelled
+func (m *machine) RegisterFunctionContext(name string, fn MachineContextFn) *machine {
 	m.functions[name] = fn
 	return m
 }
 
+func (m *machine) ServesAccessor(name string) (bool, bool) {
+	if _, ok := m.names[name]; ok {
+		return true, true
+	}
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true, true
+		}
+	}
+	return false, !m.opaque
+}
+
+func (m *machine) ServesFunction(name string) (bool, bool) {
+	_, ok := m.functions[name]
+	return ok, true
+}
+
 func (m *machine) Get(name string) (Expression, bool, error) {
 	for i := range m.accessors {
 		r, ok, err := m.accessors[i](name)
@@ -88,11 +143,15 @@ func (m *machine) Get(name string) (Expression, bool, error) {
 }
 
 func (m *machine) Call(name string, args ...StaticValue) (Expression, bool, error) {
+	return m.CallContext(context.Background(), name, args...)
+}
+
+func (m *machine) CallContext(ctx context.Context, name string, args ...StaticValue) (Expression, bool, error) {
 	fn, ok := m.functions[name]
 	if !ok {
 		return nil, false, nil
 	}
-	r, ok, err := fn(args...)
+	r, ok, err := fn(ctx, args...)
 	if !ok || err != nil {
 		return nil, ok, err
 	}
@@ -0,0 +1,96 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var nestedMachineValues = map[string]interface{}{
+	"execution": map[string]interface{}{
+		"id":     "abc",
+		"labels": map[string]string{"team": "qa"},
+		"steps":  []interface{}{"build", "test"},
+	},
+	"dotted.key": map[string]interface{}{"value": 5},
+	"flat":       true,
+}
+
+func TestNewMachineFromMap(t *testing.T) {
+	m := NewMachineFromMap(nestedMachineValues)
+	assert.Equal(t, "abc", must(EvalExpression(`execution.id`, m)).Value())
+	assert.Equal(t, "qa", must(EvalExpression(`execution.labels.team`, m)).Value())
+	assert.Equal(t, "test", must(EvalExpression(`execution.steps.1`, m)).Value())
+	assert.Equal(t, 5, must(EvalExpression(`dotted.key.value`, m)).Value())
+	assert.Equal(t, true, must(EvalExpression(`flat`, m)).Value())
+	assert.Equal(t, map[string]string{"team": "qa"}, must(EvalExpression(`execution.labels`, m)).Value())
+
+	_, ok, err := m.Get("execution.missing")
+	assert.False(t, ok)
+	assert.NoError(t, err)
+	_, ok, _ = m.Get("flat.value")
+	assert.False(t, ok)
+}
+
+func TestMachineRegisterAll(t *testing.T) {
+	m := NewMachine().Register("other", "x").RegisterAll(nestedMachineValues)
+	assert.Equal(t, "qa", must(EvalExpression(`execution.labels.team`, m)).Value())
+	assert.Equal(t, 5, must(EvalExpression(`dotted.key.value`, m)).Value())
+	assert.Equal(t, "x", must(EvalExpression(`other`, m)).Value())
+
+	served, known := m.ServesAccessor("execution.id")
+	assert.True(t, served)
+	assert.True(t, known)
+	served, known = m.ServesAccessor("unknown")
+	assert.False(t, served)
+	assert.True(t, known)
+}
+
+func TestIterationMachine(t *testing.T) {
+	vars := map[string]interface{}{"list": []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b"}}}
+	assert.Equal(t, []interface{}{"0:a", "1:b"}, must(EvalExpression(`map(list, "string(_.index, \":\", _.value.name)")`, NewMachineFromMap(vars))).Value())
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "b"}}, must(EvalExpression(`filter(list, "_.key == 1")`, NewMachineFromMap(vars))).Value())
+}
+
+func BenchmarkFilter(b *testing.B) {
+	list := make([]interface{}, 10000)
+	for i := range list {
+		list[i] = map[string]interface{}{"id": i, "passed": i%2 == 0}
+	}
+	m := NewMachineFromMap(map[string]interface{}{"list": list})
+	expr := MustCompile(`filter(list, "_.value.passed")`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := expr.Resolve(m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMap(b *testing.B) {
+	list := make([]interface{}, 10000)
+	for i := range list {
+		list[i] = map[string]interface{}{"id": i, "name": fmt.Sprintf("test-%d", i)}
+	}
+	m := NewMachineFromMap(map[string]interface{}{"list": list})
+	expr := MustCompile(`map(list, "_.value.name + \"-\" + string(_.index)")`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := expr.Resolve(m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
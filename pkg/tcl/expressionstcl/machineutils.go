@@ -8,7 +8,10 @@
 
 package expressionstcl
 
-import "strings"
+import (
+	"context"
+	"strings"
+)
 
 type limitedMachine struct {
 	prefix  string
@@ -30,12 +33,30 @@ func (m *limitedMachine) Get(name string) (Expression, bool, error) {
 }
 
 func (m *limitedMachine) Call(name string, args ...StaticValue) (Expression, bool, error) {
+	return m.CallContext(context.Background(), name, args...)
+}
+
+func (m *limitedMachine) CallContext(ctx context.Context, name string, args ...StaticValue) (Expression, bool, error) {
 	if strings.HasPrefix(name, m.prefix) {
-		return m.machine.Call(name, args...)
+		return AsContextCaller(m.machine).CallContext(ctx, name, args...)
 	}
 	return nil, false, nil
 }
 
+func (m *limitedMachine) ServesAccessor(name string) (bool, bool) {
+	if !strings.HasPrefix(name, m.prefix) {
+		return false, true
+	}
+	return explainServes(m.machine, name, ExplainableMachine.ServesAccessor)
+}
+
+func (m *limitedMachine) ServesFunction(name string) (bool, bool) {
+	if !strings.HasPrefix(name, m.prefix) {
+		return false, true
+	}
+	return explainServes(m.machine, name, ExplainableMachine.ServesFunction)
+}
+
 type combinedMachine struct {
 	machines []Machine
 }
@@ -55,8 +76,12 @@ func (m *combinedMachine) Get(name string) (Expression, bool, error) {
 }
 
 func (m *combinedMachine) Call(name string, args ...StaticValue) (Expression, bool, error) {
+	return m.CallContext(context.Background(), name, args...)
+}
+
+func (m *combinedMachine) CallContext(ctx context.Context, name string, args ...StaticValue) (Expression, bool, error) {
 	for i := range m.machines {
-		v, ok, err := m.machines[i].Call(name, args...)
+		v, ok, err := AsContextCaller(m.machines[i]).CallContext(ctx, name, args...)
 		if err != nil || ok {
 			return v, ok, err
 		}
@@ -64,6 +89,110 @@ func (m *combinedMachine) Call(name string, args ...StaticValue) (Expression, bo
 	return nil, false, nil
 }
 
+func (m *combinedMachine) ServesAccessor(name string) (bool, bool) {
+	return m.serves(name, ExplainableMachine.ServesAccessor)
+}
+
+func (m *combinedMachine) ServesFunction(name string) (bool, bool) {
+	return m.serves(name, ExplainableMachine.ServesFunction)
+}
+
+func (m *combinedMachine) serves(name string, serves func(ExplainableMachine, string) (bool, bool)) (bool, bool) {
+	known := true
+	for i := range m.machines {
+		served, ok := explainServes(m.machines[i], name, serves)
+		if served {
+			return true, true
+		}
+		known = known && ok
+	}
+	return false, known
+}
+
+type mapMachine struct {
+	values map[string]interface{}
+}
+
+// NewMachineFromMap exposes the values, with the nested maps available as dotted accessors,
+// the nested values are looked up on access, so the huge structures are not flattened up front
+func NewMachineFromMap(values map[string]interface{}) Machine {
+	return &mapMachine{values: values}
+}
+
+func (m *mapMachine) Get(name string) (Expression, bool, error) {
+	v, ok := lookupMap(m.values, name)
+	if !ok {
+		return nil, false, nil
+	}
+	if expr, ok := v.(Expression); ok {
+		return expr, true, nil
+	}
+	return NewValue(v), true, nil
+}
+
+func (m *mapMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (m *mapMachine) ServesAccessor(name string) (bool, bool) {
+	_, ok := lookupMap(m.values, name)
+	return ok, true
+}
+
+func (m *mapMachine) ServesFunction(_ string) (bool, bool) {
+	return false, true
+}
+
+// lookupMap finds the value by its dotted name, the keys may contain dots too
+func lookupMap(values map[string]interface{}, name string) (interface{}, bool) {
+	if v, ok := values[name]; ok {
+		return v, true
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] != '.' {
+			continue
+		}
+		switch nested := values[name[:i]].(type) {
+		case map[string]interface{}:
+			if v, ok := lookupMap(nested, name[i+1:]); ok {
+				return v, true
+			}
+		case map[string]string:
+			if v, ok := nested[name[i+1:]]; ok {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// iterationMachine exposes the current element to the expression in the iterating functions,
+// it's updated for each element, instead of building a new machine
+type iterationMachine struct {
+	value interface{}
+	index int
+	// accumulator is available as _.accumulator only while reducing
+	accumulator StaticValue
+}
+
+func (m *iterationMachine) Get(name string) (Expression, bool, error) {
+	switch name {
+	case "_.value":
+		return NewValue(m.value), true, nil
+	case "_.index", "_.key":
+		return NewValue(m.index), true, nil
+	case "_.accumulator":
+		if m.accumulator != nil {
+			return m.accumulator, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (m *iterationMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
 func ReplacePrefixMachine(from string, to string) Machine {
 	return NewMachine().RegisterAccessor(func(name string) (interface{}, bool) {
 		if strings.HasPrefix(name, from) {
@@ -0,0 +1,68 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"sort"
+)
+
+// mapArg reads the map argument, unlike MapValue it doesn't accept lists
+func mapArg(name string, value StaticValue, index int) (map[string]interface{}, error) {
+	if !value.IsMap() {
+		return nil, fmt.Errorf(`"%s" function expects maps, %s provided as argument %d`, name, value, index+1)
+	}
+	m, err := value.MapValue()
+	if err != nil {
+		return nil, fmt.Errorf(`"%s" function expects maps, %s provided as argument %d: %v`, name, value, index+1, err)
+	}
+	return m, nil
+}
+
+// sortedKeys returns the map keys in order, so the results are deterministic
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// filterMapStdFunction builds "pick" or "omit" function, that copies the map with or without the provided keys
+func filterMapStdFunction(name, description string, keep bool) StdFunction {
+	return StdFunction{
+		Description: description,
+		MinArgs:     1,
+		MaxArgs:     VariadicArgs,
+		ArgTypes:    []Type{TypeUnknown, TypeString},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			m, err := mapArg(name, value[0], 0)
+			if err != nil {
+				return nil, err
+			}
+			selected := make(map[string]struct{}, len(value)-1)
+			for i := 1; i < len(value); i++ {
+				if value[i].IsMap() || value[i].IsSlice() {
+					return nil, fmt.Errorf(`"%s" function expects keys to be strings, %s provided as argument %d`, name, value[i], i+1)
+				}
+				key, _ := value[i].StringValue()
+				selected[key] = struct{}{}
+			}
+			result := make(map[string]interface{}, len(m))
+			for k, v := range m {
+				if _, ok := selected[k]; ok == keep {
+					result[k] = v
+				}
+			}
+			return NewValue(result), nil
+		},
+	}
+}
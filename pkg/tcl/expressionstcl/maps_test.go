@@ -0,0 +1,61 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapFunctions(t *testing.T) {
+	tests := map[string]interface{}{
+		`keys({})`:                           []interface{}{},
+		`keys({"b": 1, "a": 2, "c": 3})`:     []interface{}{"a", "b", "c"},
+		`values({})`:                         []interface{}{},
+		`values({"b": 1, "a": 2, "c": [3]})`: []interface{}{2.0, 1.0, []interface{}{3.0}},
+		`merge()`:                            map[string]interface{}{},
+		`merge({"a": 1, "b": {"x": 1}}, {"b": {"y": 2}})`: map[string]interface{}{"a": 1.0, "b": map[string]interface{}{"y": 2.0}},
+		`merge({"a": 1}, {}, {"a": 2, "c": 3})`:           map[string]interface{}{"a": 2.0, "c": 3.0},
+		`pick({"a": 1, "b": 2, "c": 3}, "a", "c", "d")`:   map[string]interface{}{"a": 1.0, "c": 3.0},
+		`pick({"a": 1})`: map[string]interface{}{},
+		`omit({"a": 1, "b": 2, "c": 3}, "a", "d")`: map[string]interface{}{"b": 2.0, "c": 3.0},
+		`omit({"a": 1}, ["a"]...)`:                 map[string]interface{}{},
+	}
+	for expr, expected := range tests {
+		v, err := EvalString(expr, nil)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+}
+
+func TestMapFunctionsEnv(t *testing.T) {
+	vars := map[string]interface{}{
+		"defaults": map[string]interface{}{"LOG_LEVEL": "info", "REGION": "eu"},
+		"config":   map[string]string{"LOG_LEVEL": "debug", "TOKEN": "secret"},
+	}
+	v, err := EvalString(`merge(defaults, omit(config, "TOKEN"))`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"LOG_LEVEL": "debug", "REGION": "eu"}, v)
+}
+
+func TestMapFunctionsErrors(t *testing.T) {
+	_, err := Compile(`merge({"a": 1}, [1, 2])`)
+	assert.ErrorContains(t, err, `"merge" function expects maps, [1,2] provided as argument 2`)
+	_, err = Compile(`merge({"a": 1}, null)`)
+	assert.ErrorContains(t, err, `"merge" function expects maps, null provided as argument 2`)
+	_, err = Compile(`keys("abc")`)
+	assert.ErrorContains(t, err, `"keys" function expects maps, "abc" provided as argument 1`)
+	_, err = Compile(`values({}, {})`)
+	assert.ErrorContains(t, err, `"values" expects 1 argument, 2 provided`)
+	_, err = Compile(`pick()`)
+	assert.ErrorContains(t, err, `"pick" expects at least 1 argument, 0 provided`)
+	_, err = Compile(`omit({"a": 1}, {"a": 1})`)
+	assert.ErrorContains(t, err, `"omit" function expects keys to be strings`)
+}
@@ -9,10 +9,12 @@
 package expressionstcl
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"maps"
 	math2 "math"
+	"strings"
 )
 
 type operator string
@@ -81,10 +83,6 @@ func runOp[T interface{}, U interface{}](v1 StaticValue, v2 StaticValue, mapper
 	return NewValue(op(s1, s2)), nil
 }
 
-func staticString(v StaticValue) (string, error) {
-	return v.StringValue()
-}
-
 func staticFloat(v StaticValue) (float64, error) {
 	return v.FloatValue()
 }
@@ -93,31 +91,82 @@ func staticBool(v StaticValue) (bool, error) {
 	return v.BoolValue()
 }
 
-func (s *math) performMath(v1 StaticValue, v2 StaticValue) (StaticValue, error) {
+// intOperands returns both operands as int64 when one of them is an integer and the other one is an integer
+// or a float64 holding an exactly representable integer (i.e. a literal), so the integer math keeps the precision
+// above 2^53, where float64 can't represent every integer
+func intOperands(v1, v2 StaticValue) (int64, int64, bool) {
+	a, ok1 := intOperand(v1.Value())
+	b, ok2 := intOperand(v2.Value())
+	_, int1 := numberValue(v1.Value()).(int64)
+	_, int2 := numberValue(v2.Value()).(int64)
+	return a, b, ok1 && ok2 && (int1 || int2)
+}
+
+func intOperand(v interface{}) (int64, bool) {
+	switch n := numberValue(v).(type) {
+	case int64:
+		return n, true
+	case float64:
+		if n == math2.Trunc(n) && n <= maxExactFloatInt && n >= -maxExactFloatInt {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
+
+// intMath performs the integer operation, it's not ok when the result overflows int64
+func intMath(operator operator, a, b int64) (result int64, ok bool) {
+	switch operator {
+	case operatorAdd:
+		result = a + b
+		return result, (result > a) == (b > 0)
+	case operatorSubtract:
+		result = a - b
+		return result, (result < a) == (b > 0)
+	case operatorMultiply:
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		result = a * b
+		return result, result/b == a && !(a == -1 && b == math2.MinInt64) && !(b == -1 && a == math2.MinInt64)
+	case operatorModulo:
+		if b == 0 {
+			return 0, false
+		}
+		return a % b, true
+	}
+	return 0, false
+}
+
+func (s *math) performMath(features Features, v1 StaticValue, v2 StaticValue) (StaticValue, error) {
 	switch s.operator {
 	case operatorEquals, operatorEqualsAlias:
-		return runOp(v1, v2, staticString, func(s1, s2 string) bool {
-			return s1 == s2
-		})
+		equal, err := equalValues(features, v1, v2)
+		if err != nil {
+			return nil, err
+		}
+		return NewValue(equal), nil
 	case operatorNotEquals, operatorNotEqualsAlias:
-		return runOp(v1, v2, staticString, func(s1, s2 string) bool {
-			return s1 != s2
-		})
+		equal, err := equalValues(features, v1, v2)
+		if err != nil {
+			return nil, err
+		}
+		return NewValue(!equal), nil
 	case operatorGt:
-		return runOp(v1, v2, staticFloat, func(s1, s2 float64) bool {
-			return s1 > s2
+		return compareValues(features, v1, v2, func(c int) bool {
+			return c > 0
 		})
 	case operatorLt:
-		return runOp(v1, v2, staticFloat, func(s1, s2 float64) bool {
-			return s1 < s2
+		return compareValues(features, v1, v2, func(c int) bool {
+			return c < 0
 		})
 	case operatorGte:
-		return runOp(v1, v2, staticFloat, func(s1, s2 float64) bool {
-			return s1 >= s2
+		return compareValues(features, v1, v2, func(c int) bool {
+			return c >= 0
 		})
 	case operatorLte:
-		return runOp(v1, v2, staticFloat, func(s1, s2 float64) bool {
-			return s1 <= s2
+		return compareValues(features, v1, v2, func(c int) bool {
+			return c <= 0
 		})
 	case operatorAnd:
 		return runOp(v1, v2, staticBool, func(s1, s2 bool) interface{} {
@@ -135,18 +184,29 @@ func (s *math) performMath(v1 StaticValue, v2 StaticValue) (StaticValue, error)
 		})
 	case operatorAdd:
 		if v1.IsString() || v2.IsString() {
-			return runOp(v1, v2, staticString, func(s1, s2 string) string {
+			return runOp(v1, v2, func(v StaticValue) (string, error) {
+				return toStringWith(features, v.Value())
+			}, func(s1, s2 string) string {
 				return s1 + s2
 			})
 		}
+		if result, ok := s.performIntMath(v1, v2); ok {
+			return result, nil
+		}
 		return runOp(v1, v2, staticFloat, func(s1, s2 float64) float64 {
 			return s1 + s2
 		})
 	case operatorSubtract:
+		if result, ok := s.performIntMath(v1, v2); ok {
+			return result, nil
+		}
 		return runOp(v1, v2, staticFloat, func(s1, s2 float64) float64 {
 			return s1 - s2
 		})
 	case operatorModulo:
+		if result, ok := s.performIntMath(v1, v2); ok {
+			return result, nil
+		}
 		divideByZero := false
 		res, err := runOp(v1, v2, staticFloat, func(s1, s2 float64) float64 {
 			if s2 == 0 {
@@ -173,6 +233,9 @@ func (s *math) performMath(v1 StaticValue, v2 StaticValue) (StaticValue, error)
 		}
 		return res, err
 	case operatorMultiply:
+		if result, ok := s.performIntMath(v1, v2); ok {
+			return result, nil
+		}
 		return runOp(v1, v2, staticFloat, func(s1, s2 float64) float64 {
 			return s1 * s2
 		})
@@ -185,6 +248,30 @@ func (s *math) performMath(v1 StaticValue, v2 StaticValue) (StaticValue, error)
 	return nil, fmt.Errorf("unknown math operator: %s", s.operator)
 }
 
+// performIntMath keeps the integer operands of +, -, * and % as int64,
+// the result falls back to float64 math when it overflows int64
+func (s *math) performIntMath(v1, v2 StaticValue) (StaticValue, bool) {
+	a, b, ok := intOperands(v1, v2)
+	if !ok {
+		return nil, false
+	}
+	result, ok := intMath(s.operator, a, b)
+	if !ok {
+		return nil, false
+	}
+	return NewValue(result), true
+}
+
+// intMathType is the type of +, -, * and % result, the integer with the float is an integer only when the float is integral
+func intMathType(l, r Type) Type {
+	if l == TypeInt64 && r == TypeInt64 {
+		return TypeInt64
+	} else if l == TypeInt64 || r == TypeInt64 {
+		return TypeUnknown
+	}
+	return TypeFloat64
+}
+
 func (s *math) Type() Type {
 	l := s.left.Type()
 	r := s.right.Type()
@@ -194,13 +281,15 @@ func (s *math) Type() Type {
 			return l
 		}
 		return TypeUnknown
-	case operatorPower, operatorModulo, operatorSubtract, operatorMultiply, operatorDivide:
+	case operatorPower, operatorDivide:
 		return TypeFloat64
+	case operatorModulo, operatorSubtract, operatorMultiply:
+		return intMathType(l, r)
 	case operatorAdd:
 		if l == TypeString || r == TypeString {
 			return TypeString
 		}
-		return TypeFloat64
+		return intMathType(l, r)
 	case operatorEquals, operatorNotEquals, operatorEqualsAlias, operatorNotEqualsAlias, operatorGt, operatorLt, operatorGte, operatorLte:
 		return TypeBool
 	default:
@@ -234,12 +323,17 @@ func (s *math) Template() string {
 }
 
 func (s *math) SafeResolve(m ...Machine) (v Expression, changed bool, err error) {
+	// Resolve the copy, so the compiled expression may be resolved again, i.e. for each item in "map"
+	s = &math{operator: s.operator, left: s.left, right: s.right}
 	var ch bool
-	s.left, ch, err = s.left.SafeResolve(m...)
+	left, ch, err := s.left.SafeResolve(m...)
 	changed = changed || ch
 	if err != nil {
-		return
+		// Resolve the other side too, to report all the independent failures at once
+		_, _, rightErr := s.right.SafeResolve(m...)
+		return nil, changed, joinErrors(err, rightErr)
 	}
+	s.left = left
 
 	// Fast track for cutting dead paths
 	if s.left.Static() != nil {
@@ -287,17 +381,105 @@ func (s *math) SafeResolve(m ...Machine) (v Expression, changed bool, err error)
 	}
 
 	if s.left.Static() != nil && s.right.Static() != nil {
-		res, err := s.performMath(s.left.Static(), s.right.Static())
+		if res, ok, err := interceptOperator(m, s.operator, s.left.Static(), s.right.Static()); ok {
+			if err != nil {
+				return nil, changed, newKindError(ErrorKindMath, fmt.Errorf("error while performing math: %s: %s", s.String(), err))
+			}
+			return res, true, nil
+		}
+		v1, v2, deferred, err := s.noneOperands(s.left.Static(), s.right.Static(), m)
+		if deferred {
+			return s, changed, nil
+		}
 		if err != nil {
-			return nil, changed, fmt.Errorf("error while performing math: %s: %s", s.String(), err)
+			return nil, changed, newKindError(ErrorKindMath, fmt.Errorf("error while performing math: %s: %s", s.String(), err))
+		}
+		res, err := s.performMath(resolveFeatures(m), v1, v2)
+		if err != nil {
+			err = fmt.Errorf("error while performing math: %s%s: %s", s.String(), operandSources(v1, v2), err)
+			return nil, changed, newKindError(ErrorKindMath, redactErr(err, secretRedactions(v1, v2)))
+		}
+		// The concatenation and the sum reveal the secret operand
+		if s.operator == operatorAdd && (isSecretValue(v1) || isSecretValue(v2)) {
+			return taintSecret(res), true, nil
 		}
 		return res, true, nil
 	}
 	return s, changed, nil
 }
 
+func isNoneOperand(v StaticValue) bool {
+	return v.IsNone() || v.Value() == nil
+}
+
+func noneOperandError(side string, v StaticValue) error {
+	if source := staticSource(v); source != "" {
+		return fmt.Errorf("%s operand is none (from accessor '%s')", side, source)
+	}
+	return fmt.Errorf("%s operand is none", side)
+}
+
+// operandSources describes the accessors the operands have been read from
+func operandSources(v1, v2 StaticValue) string {
+	var sources []string
+	if source := staticSource(v1); source != "" {
+		sources = append(sources, fmt.Sprintf("left operand from accessor '%s'", source))
+	}
+	if source := staticSource(v2); source != "" {
+		sources = append(sources, fmt.Sprintf("right operand from accessor '%s'", source))
+	}
+	if len(sources) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(sources, ", ") + ")"
+}
+
+// noneOperands applies the None propagation rules before performing the operation:
+// equality and logical operators accept None as any other value, string concatenation treats None
+// as an empty string only with NoneAsEmptyStringMachine, while other operators fail early.
+// The concatenation is deferred when resolved without machines, i.e. while compiling,
+// as the option is not known yet.
+func (s *math) noneOperands(v1, v2 StaticValue, m []Machine) (StaticValue, StaticValue, bool, error) {
+	switch s.operator {
+	case operatorEquals, operatorEqualsAlias, operatorNotEquals, operatorNotEqualsAlias, operatorAnd, operatorOr:
+		return v1, v2, false, nil
+	}
+	none1, none2 := isNoneOperand(v1), isNoneOperand(v2)
+	if !none1 && !none2 {
+		return v1, v2, false, nil
+	}
+
+	if s.operator == operatorAdd && (v1.IsString() || v2.IsString()) {
+		if compiling(m) {
+			return v1, v2, true, nil
+		}
+		if isNoneAsEmptyString(m) {
+			if none1 {
+				v1 = NewValue("")
+			}
+			if none2 {
+				v2 = NewValue("")
+			}
+			return v1, v2, false, nil
+		}
+	}
+
+	var errs []error
+	if none1 {
+		errs = append(errs, noneOperandError("left", v1))
+	}
+	if none2 {
+		errs = append(errs, noneOperandError("right", v2))
+	}
+	return v1, v2, false, errors.Join(errs...)
+}
+
 func (s *math) Resolve(m ...Machine) (v Expression, err error) {
-	return deepResolve(s, m...)
+	return s.ResolveContext(context.Background(), m...)
+}
+
+func (s *math) ResolveContext(ctx context.Context, m ...Machine) (v Expression, err error) {
+	return deepResolve(s, withContext(ctx, m)...)
 }
 
 func (s *math) Static() StaticValue {
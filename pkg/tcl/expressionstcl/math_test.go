@@ -0,0 +1,59 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	math2 "math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegerMathPrecision(t *testing.T) {
+	tests := map[string]interface{}{
+		`9007199254740993 + 0`:                     int64(9007199254740993),
+		`9007199254740993 - 1 + 2`:                 int64(9007199254740994),
+		`9007199254740994 - 1`:                     int64(9007199254740993),
+		`int("3002399751580331") * 3`:              int64(9007199254740993),
+		`9007199254740993 % 10`:                    int64(3),
+		`int("-7") % 3`:                            int64(-1),
+		`9007199254740993 + 0 == 9007199254740992`: false,
+		`int("9007199254740993") - 1`:              int64(9007199254740992),
+		`1 + 2`:                                    3.0,
+		`9007199254740993 + 0.5`:                   9007199254740992.0,
+		`7 / 2`:                                    3.5,
+		`2 ** 3`:                                   8.0,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, MustCompile(expr).Static().Value(), expr)
+	}
+
+	// integers of any Go type are kept as int64
+	v, err := EvalExpression(`a * b`, NewMachine().Register("a", int32(3)).Register("b", uint8(2)))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), v.Value())
+}
+
+func TestIntegerMathOverflow(t *testing.T) {
+	// the result above int64 range falls back to float64
+	assert.Equal(t, float64(math2.MaxInt64)+1, MustCompile(`9223372036854775807 + 1`).Static().Value())
+	assert.Equal(t, float64(math2.MinInt64)-1, MustCompile(`(-9223372036854775807) - 2`).Static().Value())
+	assert.Equal(t, 2*float64(math2.MaxInt64), MustCompile(`9223372036854775807 * 2`).Static().Value())
+
+	_, err := Compile(`int("5") % 0`)
+	assert.ErrorContains(t, err, "cannot modulo by zero")
+}
+
+func TestIntegerMathType(t *testing.T) {
+	assert.Equal(t, TypeInt64, MustCompile(`len(a) + len(b)`).Type())
+	assert.Equal(t, TypeInt64, MustCompile(`len(a) * int(b)`).Type())
+	assert.Equal(t, TypeUnknown, MustCompile(`len(a) - 2`).Type())
+	assert.Equal(t, TypeFloat64, MustCompile(`len(a) / 2`).Type())
+	assert.Equal(t, TypeFloat64, MustCompile(`a - 2.5`).Type())
+}
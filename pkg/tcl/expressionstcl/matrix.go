@@ -0,0 +1,91 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// MaxMatrixCombinations caps number of combinations generated by the matrix function
+const MaxMatrixCombinations = 10_000
+
+// matrixExclusion excludes combinations with all of its dimension values, values are compared by JSON encoding
+type matrixExclusion map[string]string
+
+func newMatrixExclusion(exclusion map[string]interface{}) (matrixExclusion, error) {
+	result := make(matrixExclusion, len(exclusion))
+	for name, value := range exclusion {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("exclusion value of %q: %v", name, err)
+		}
+		result[name] = string(encoded)
+	}
+	return result, nil
+}
+
+func (e matrixExclusion) matches(combination map[string]interface{}) bool {
+	for name, value := range e {
+		v, ok := combination[name]
+		if !ok {
+			return false
+		}
+		encoded, _ := json.Marshal(v)
+		if string(encoded) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// buildMatrix returns all combinations of dimension values, dimensions are sorted by name
+// and the last dimension changes the fastest, combinations matching any exclusion are skipped
+func buildMatrix(dimensions map[string][]interface{}, exclusions []matrixExclusion) ([]map[string]interface{}, error) {
+	names := make([]string, 0, len(dimensions))
+	total := 1
+	for name, values := range dimensions {
+		names = append(names, name)
+		if total > 0 && len(values) > MaxMatrixCombinations/total {
+			return nil, fmt.Errorf("matrix has more than %d combinations", MaxMatrixCombinations)
+		}
+		total *= len(values)
+	}
+	sort.Strings(names)
+
+	result := make([]map[string]interface{}, 0, total)
+	indexes := make([]int, len(names))
+	for i := 0; i < total; i++ {
+		combination := make(map[string]interface{}, len(names))
+		for j, name := range names {
+			combination[name] = dimensions[name][indexes[j]]
+		}
+
+		excluded := false
+		for _, exclusion := range exclusions {
+			if exclusion.matches(combination) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			result = append(result, combination)
+		}
+
+		for j := len(names) - 1; j >= 0; j-- {
+			indexes[j]++
+			if indexes[j] < len(dimensions[names[j]]) {
+				break
+			}
+			indexes[j] = 0
+		}
+	}
+	return result, nil
+}
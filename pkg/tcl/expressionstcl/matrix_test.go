@@ -0,0 +1,54 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatrix(t *testing.T) {
+	assert.Equal(t,
+		`[{"browser":"chrome","viewport":"mobile"},{"browser":"chrome","viewport":"desktop"},{"browser":"firefox","viewport":"mobile"},{"browser":"firefox","viewport":"desktop"}]`,
+		MustCompile(`matrix({"viewport": ["mobile", "desktop"], "browser": ["chrome", "firefox"]})`).String())
+	assert.Equal(t,
+		`[{"node":18,"os":"linux"},{"node":20,"os":"linux"}]`,
+		MustCompile(`matrix({"os": ["linux"], "node": [18, 20]})`).String())
+	assert.Equal(t, `[{}]`, MustCompile(`matrix({})`).String())
+	assert.Equal(t, `[]`, MustCompile(`matrix({"browser": ["chrome"], "viewport": []})`).String())
+}
+
+func TestMatrixExclusions(t *testing.T) {
+	assert.Equal(t,
+		`[{"browser":"chrome","viewport":"mobile"},{"browser":"chrome","viewport":"desktop"},{"browser":"firefox","viewport":"desktop"}]`,
+		MustCompile(`matrix({"browser": ["chrome", "firefox"], "viewport": ["mobile", "desktop"]}, [{"browser": "firefox", "viewport": "mobile"}])`).String())
+	assert.Equal(t,
+		`[{"browser":"chrome","node":18},{"browser":"chrome","node":20}]`,
+		MustCompile(`matrix({"browser": ["chrome", "safari"], "node": [18, 20]}, [{"browser": "safari"}])`).String())
+	assert.Equal(t,
+		`[{"node":18},{"node":20}]`,
+		MustCompile(`matrix({"node": [18, 20]}, [{"node": "18"}, {"os": "linux"}])`).String())
+}
+
+func TestMatrixInvalid(t *testing.T) {
+	values := make([]string, 101)
+	for i := range values {
+		values[i] = fmt.Sprint(i)
+	}
+	list := "[" + strings.Join(values, ",") + "]"
+	_, err := Compile(fmt.Sprintf(`matrix({"a": %s, "b": %s})`, list, list))
+	assert.ErrorContains(t, err, `"matrix" function: matrix has more than 10000 combinations`)
+	_, err = Compile(`matrix({"browser": "chrome"})`)
+	assert.ErrorContains(t, err, `"matrix" function expects "browser" dimension to be a list`)
+	_, err = Compile(`matrix({"browser": ["chrome"]}, ["chrome"])`)
+	assert.ErrorContains(t, err, `"matrix" function expects exclusion 0 to be a map`)
+}
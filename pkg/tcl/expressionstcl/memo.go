@@ -0,0 +1,351 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultFunctionCacheMaxEntries is a default maximum number of cached function results
+	DefaultFunctionCacheMaxEntries = 1000
+	// DefaultFunctionCacheMaxBytes is a default maximum total size of cached function results
+	DefaultFunctionCacheMaxBytes = 64 << 20
+	// DefaultFunctionCacheMaxEntryBytes is a default maximum size of a single cached function result
+	DefaultFunctionCacheMaxEntryBytes = 1 << 20
+)
+
+// FunctionCacheOptions limit the function cache, the size of entry is a size of its key and canonical form of its result
+type FunctionCacheOptions struct {
+	MaxEntries int
+	MaxBytes   int
+	// MaxEntryBytes prevents caching the results bigger than that, so a single huge document can't evict everything
+	MaxEntryBytes int
+}
+
+// FunctionCacheStats are numbers of the function cache lookups;
+// skipped are the results which were too big to be cached
+type FunctionCacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Skipped uint64
+	Entries int
+	Bytes   int
+}
+
+type functionCacheEntry struct {
+	key    string
+	result Expression
+	size   int
+}
+
+// FunctionCache memoizes the results of the pure standard library functions, i.e. the ones with Pure flag,
+// the cached results are shared between resolutions, so they mustn't be modified;
+// it may be also passed with the machines, to memoize the results for a single resolution pass, see Get
+type FunctionCache struct {
+	options FunctionCacheOptions
+
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+	bytes int
+
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	skipped atomic.Uint64
+}
+
+var functionCache atomic.Pointer[FunctionCache]
+
+// NewFunctionCache creates LRU cache of the function results, zero options are using the defaults
+func NewFunctionCache(options FunctionCacheOptions) *FunctionCache {
+	if options.MaxEntries <= 0 {
+		options.MaxEntries = DefaultFunctionCacheMaxEntries
+	}
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = DefaultFunctionCacheMaxBytes
+	}
+	if options.MaxEntryBytes <= 0 {
+		options.MaxEntryBytes = min(DefaultFunctionCacheMaxEntryBytes, options.MaxBytes)
+	}
+	return &FunctionCache{
+		options: options,
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// SetFunctionCache enables memoization of the pure functions for all resolutions, nil disables it
+func SetFunctionCache(cache *FunctionCache) {
+	functionCache.Store(cache)
+}
+
+// Stats returns the numbers of cache lookups and the current cache size
+func (c *FunctionCache) Stats() FunctionCacheStats {
+	c.mu.Lock()
+	entries, bytes := c.order.Len(), c.bytes
+	c.mu.Unlock()
+	return FunctionCacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Skipped: c.skipped.Load(),
+		Entries: entries,
+		Bytes:   bytes,
+	}
+}
+
+func (c *FunctionCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+	c.bytes = 0
+}
+
+func (c *FunctionCache) get(key string) (Expression, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	c.hits.Add(1)
+	return element.Value.(*functionCacheEntry).result, true
+}
+
+func (c *FunctionCache) add(key string, result Expression) {
+	size := len(key) + len(result.String())
+	if size > c.options.MaxEntryBytes {
+		c.skipped.Add(1)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.items[key]; ok {
+		return
+	}
+	c.items[key] = c.order.PushFront(&functionCacheEntry{key: key, result: result, size: size})
+	c.bytes += size
+	for c.order.Len() > c.options.MaxEntries || c.bytes > c.options.MaxBytes {
+		entry := c.order.Remove(c.order.Back()).(*functionCacheEntry)
+		delete(c.items, entry.key)
+		c.bytes -= entry.size
+	}
+}
+
+// callCached calls the function, the result of the pure one is memoized when the function cache is enabled,
+// and the result of any deterministic one is memoized when the function cache is passed with the machines
+func (fn StdFunction) callCached(features Features, now func() time.Time, machines []Machine, name string, args ...StaticValue) (Expression, error) {
+	// The results for the secret values are not kept, so they don't outlive the resolution
+	if secretRedactions(args...) != nil {
+		return fn.call(features, now, machines, args...)
+	}
+
+	cache, key := functionCache.Load(), ""
+	if pass := resolvePassCache(machines); pass != nil && !fn.NonDeterministic {
+		cache, key = pass, passCacheKey(fn, features, name, args, machines)
+	} else if cache != nil && fn.Pure {
+		key = functionCacheKey(features, name, args)
+	}
+	if key == "" {
+		return fn.call(features, now, machines, args...)
+	}
+
+	if result, ok := cache.get(key); ok {
+		return result, nil
+	}
+	result, err := fn.call(features, now, machines, args...)
+	if err == nil && result != nil && result.Static() != nil && !isSecretValue(result.Static()) {
+		cache.add(key, result)
+	}
+	return result, err
+}
+
+// Get is a no-op, the function cache is passed alongside the machines to memoize the results for a single resolution pass:
+//
+//	cache := NewFunctionCache(FunctionCacheOptions{})
+//	for i := range fields {
+//		fields[i], err = fields[i].Resolve(cache, machines...)
+//	}
+//
+// The machines don't change within the pass, so all the functions except the NonDeterministic ones are memoized,
+// and the functions using the machines are keyed by their identity too, i.e. the repeated jq(config, ".query")
+// across the fields of a large spec is computed once.
+func (c *FunctionCache) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (c *FunctionCache) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// resolvePassCache finds the function cache passed with the machines
+func resolvePassCache(m []Machine) *FunctionCache {
+	for i := range m {
+		if c, ok := m[i].(*FunctionCache); ok {
+			return c
+		}
+	}
+	return nil
+}
+
+// passCacheKey builds the key for the single pass, the functions using the machines are keyed by their identity too,
+// it's empty when the result can't be cached, as the machines record the accessed values or have no identity
+func passCacheKey(fn StdFunction, features Features, name string, args []StaticValue, machines []Machine) string {
+	token, ok := machinesToken(machines)
+	if !ok {
+		return ""
+	}
+	key := functionCacheKey(features, name, args)
+	if fn.MachinesHandler != nil {
+		key += "\x00" + token
+	}
+	return key
+}
+
+// machinesToken identifies the machines by their types and addresses, the machines created by each resolution
+// that don't provide the values are skipped, so the token is the same for all the resolutions with the same machines
+func machinesToken(m []Machine) (string, bool) {
+	var b strings.Builder
+	for i := range m {
+		switch x := m[i].(type) {
+		case *FunctionCache, *contextMachine, *limitsMachine, *clockMachine:
+			continue
+		case *auditRecorder, *secretMachine, *secretRecorder:
+			// The accessed values are recorded by the resolution for the audit and the redaction, so they can't be skipped
+			return "", false
+		case *deepMachine:
+			token, ok := machinesToken(x.resolver.machines)
+			if !ok {
+				return "", false
+			}
+			b.WriteString("deep(" + token + ")")
+		default:
+			id, ok := machineIdentity(x)
+			if !ok {
+				return "", false
+			}
+			b.WriteString(id)
+		}
+		b.WriteByte(';')
+	}
+	return b.String(), true
+}
+
+// machineIdentity identifies the machine by its address, the machines passed by value have no identity,
+// unless they have no state at all
+func machineIdentity(m Machine) (string, bool) {
+	v := reflect.ValueOf(m)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map:
+		return fmt.Sprintf("%T@%x", m, v.Pointer()), true
+	case reflect.Struct:
+		if v.NumField() == 0 {
+			return fmt.Sprintf("%T", m), true
+		}
+	}
+	return "", false
+}
+
+// functionCacheKey builds the key from function name and canonical form of its arguments,
+// with the features, as they may affect the conversions
+func functionCacheKey(features Features, name string, args []StaticValue) string {
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('(')
+	for i := range args {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		writeCanonical(&b, args[i].Value())
+	}
+	b.WriteByte(')')
+	for _, info := range ListFeatures() {
+		if features.Enabled(info.Name) {
+			b.WriteString("+" + string(info.Name))
+		}
+	}
+	return b.String()
+}
+
+// writeCanonical serializes the value with the types, so i.e. 1 and 1.0 or "1" have different forms,
+// and the map keys are sorted
+func writeCanonical(b *strings.Builder, v interface{}) {
+	switch x := v.(type) {
+	case nil:
+		b.WriteString("null")
+		return
+	case string:
+		b.WriteString(strconv.Quote(x))
+		return
+	case bool:
+		b.WriteString(strconv.FormatBool(x))
+		return
+	}
+
+	switch n := numberValue(v).(type) {
+	case int64:
+		b.WriteString("i" + strconv.FormatInt(n, 10))
+		return
+	case float64:
+		b.WriteString("f" + strconv.FormatFloat(n, 'g', -1, 64))
+		return
+	}
+
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		b.WriteByte('[')
+		for i := 0; i < value.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeCanonical(b, value.Index(i).Interface())
+		}
+		b.WriteByte(']')
+	case reflect.Map:
+		keys := make([]string, 0, value.Len())
+		values := make(map[string]interface{}, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			k := fmt.Sprint(iter.Key().Interface())
+			keys = append(keys, k)
+			values[k] = iter.Value().Interface()
+		}
+		sort.Strings(keys)
+		b.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Quote(k) + ":")
+			writeCanonical(b, values[k])
+		}
+		b.WriteByte('}')
+	default:
+		data, _ := json.Marshal(v)
+		b.WriteString(fmt.Sprintf("%T", v))
+		b.Write(data)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withFunctionCache(t testing.TB, options FunctionCacheOptions) *FunctionCache {
+	cache := NewFunctionCache(options)
+	SetFunctionCache(cache)
+	t.Cleanup(func() { SetFunctionCache(nil) })
+	return cache
+}
+
+func TestFunctionCacheKey(t *testing.T) {
+	key := func(args ...interface{}) string {
+		values := make([]StaticValue, len(args))
+		for i := range args {
+			values[i] = NewValue(args[i])
+		}
+		return functionCacheKey(nil, "fn", values)
+	}
+
+	assert.Equal(t, `fn(i1,"a",[true,null])+jsonCompositeStrings+numericComparison`, key(1, "a", []interface{}{true, nil}))
+	assert.NotEqual(t, key(1), key(1.0))
+	assert.NotEqual(t, key(1), key("1"))
+	assert.NotEqual(t, key([]interface{}{1, 2}), key([]interface{}{1.0, 2}))
+	assert.Equal(t, key(int32(5)), key(int64(5)))
+	assert.Equal(t, key(map[string]interface{}{"a": 1, "b": 2}), key(map[string]interface{}{"b": 2, "a": 1}))
+
+	off := Features{FeatureJSONCompositeStrings: false}
+	assert.NotEqual(t, key("a"), functionCacheKey(off, "fn", []StaticValue{NewValue("a")}))
+}
+
+func TestFunctionCachePure(t *testing.T) {
+	cache := withFunctionCache(t, FunctionCacheOptions{})
+
+	for i := 0; i < 3; i++ {
+		v, err := EvalString(`yaml(config).a`, map[string]interface{}{"config": "a: 1\nb: 2\n"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, v)
+	}
+	assert.Equal(t, FunctionCacheStats{Hits: 2, Misses: 1, Entries: 1, Bytes: cache.Stats().Bytes}, cache.Stats())
+
+	// errors are not cached
+	for i := 0; i < 2; i++ {
+		_, err := Compile(`yaml("a: [")`)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, uint64(3), cache.Stats().Misses)
+	assert.Equal(t, 1, cache.Stats().Entries)
+}
+
+func TestFunctionCacheImpure(t *testing.T) {
+	cache := withFunctionCache(t, FunctionCacheOptions{})
+
+	// functions depending on time, or evaluating expressions or queries, are never cached
+	for _, name := range []string{"jwtExpiredUnverified", "jq", "eval", "map", "filter"} {
+		assert.False(t, stdFunctions[name].Pure, name)
+	}
+	for _, expr := range []string{`eval("1 + 2")`, `map([1, 2], "_.value * 2")`, `jq({"a": 1}, ".a")`, `len([1, 2])`, `string([1])`} {
+		for i := 0; i < 2; i++ {
+			_, err := Compile(expr)
+			assert.NoError(t, err, expr)
+		}
+	}
+	assert.Equal(t, FunctionCacheStats{}, cache.Stats())
+}
+
+func TestFunctionCacheLimits(t *testing.T) {
+	cache := withFunctionCache(t, FunctionCacheOptions{MaxEntries: 2, MaxBytes: 1000, MaxEntryBytes: 200})
+
+	// a result bigger than the entry limit doesn't evict anything
+	MustCompile(`split("a,b", ",")`)
+	MustCompile(`split("` + strings.Repeat("a,", 100) + `", ",")`)
+	assert.Equal(t, uint64(1), cache.Stats().Skipped)
+	assert.Equal(t, 1, cache.Stats().Entries)
+
+	MustCompile(`split("c,d", ",")`)
+	MustCompile(`split("a,b", ",")`)
+	MustCompile(`split("e,f", ",")`)
+	assert.Equal(t, 2, cache.Stats().Entries)
+
+	// "c,d" was used least recently, so it was evicted
+	hits := cache.Stats().Hits
+	MustCompile(`split("a,b", ",")`)
+	assert.Equal(t, hits+1, cache.Stats().Hits)
+	MustCompile(`split("c,d", ",")`)
+	assert.Equal(t, hits+1, cache.Stats().Hits)
+}
+
+func TestFunctionCacheMaxBytes(t *testing.T) {
+	cache := withFunctionCache(t, FunctionCacheOptions{MaxEntries: 100, MaxBytes: 100, MaxEntryBytes: 60})
+
+	for i := 0; i < 10; i++ {
+		MustCompile(fmt.Sprintf(`split("%d,%d", ",")`, i, i))
+	}
+	stats := cache.Stats()
+	assert.LessOrEqual(t, stats.Bytes, 100)
+	assert.Less(t, stats.Entries, 10)
+}
+
+func benchmarkRepeatedEvaluation(b *testing.B) {
+	var config strings.Builder
+	config.WriteString("items:\n")
+	for i := 0; i < 200; i++ {
+		config.WriteString(fmt.Sprintf("  - name: item-%d\n    enabled: %t\n", i, i%2 == 0))
+	}
+	vars := map[string]interface{}{"config": config.String(), "name": "item-150"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := EvalString(`yaml(config).items.150.name == name`, vars); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFunctionCache(b *testing.B) {
+	b.Run("uncached", benchmarkRepeatedEvaluation)
+	b.Run("cached", func(b *testing.B) {
+		withFunctionCache(b, FunctionCacheOptions{})
+		benchmarkRepeatedEvaluation(b)
+	})
+}
+
+func TestFunctionCachePass(t *testing.T) {
+	cache := NewFunctionCache(FunctionCacheOptions{})
+	m := NewMachine().Register("config", map[string]interface{}{"items": []interface{}{"a", "b", "c"}})
+
+	for _, expr := range []string{`len(jq(config, ".items[]"))`, `len(jq(config, ".items[]")) + 1`, `len(jq(config, ".items[]"))`} {
+		v, err := MustCompile(expr).Resolve(cache, m)
+		assert.NoError(t, err)
+		expected, err := MustCompile(expr).Resolve(m)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.String(), v.String(), expr)
+	}
+	assert.Equal(t, uint64(4), cache.Stats().Hits)
+	assert.Equal(t, 2, cache.Stats().Entries)
+}
+
+func TestFunctionCachePassMachinesIdentity(t *testing.T) {
+	cache := NewFunctionCache(FunctionCacheOptions{})
+	expr := MustCompile(`eval("name")`)
+
+	v, err := expr.Resolve(cache, NewMachine().Register("name", "a"))
+	assert.NoError(t, err)
+	assert.Equal(t, `"a"`, v.String())
+	v, err = expr.Resolve(cache, NewMachine().Register("name", "b"))
+	assert.NoError(t, err)
+	assert.Equal(t, `"b"`, v.String())
+
+	v, err = MustCompile(`map([1, 2], "eval(\"_.value\")")`).Resolve(cache)
+	assert.NoError(t, err)
+	assert.Equal(t, `[1,2]`, v.String())
+}
+
+func TestFunctionCachePassNonDeterministic(t *testing.T) {
+	cache := NewFunctionCache(FunctionCacheOptions{})
+	random := NewSeededRandomMachine(1)
+	expr := MustCompile(`uuid()`)
+
+	v1, err := expr.Resolve(cache, random)
+	assert.NoError(t, err)
+	v2, err := expr.Resolve(cache, random)
+	assert.NoError(t, err)
+	assert.NotEqual(t, v1.String(), v2.String())
+	assert.Equal(t, FunctionCacheStats{}, cache.Stats())
+
+	for _, name := range []string{"now", "date", "uuid", "random", "randomString", "file", "fileBase64", "fileExists", "jwtExpiredUnverified"} {
+		assert.True(t, stdFunctions[name].NonDeterministic, name)
+	}
+	assert.False(t, stdFunctions["jq"].NonDeterministic)
+}
+
+func TestFunctionCachePassSecrets(t *testing.T) {
+	cache := NewFunctionCache(FunctionCacheOptions{})
+	secrets := NewSecretMachine(NewMachine().Register("token", "abc"))
+
+	for i := 0; i < 2; i++ {
+		_, err := MustCompile(`string(token) + x`).Resolve(cache, secrets, FinalizerFail)
+		assert.Error(t, err)
+		assert.NotContains(t, err.Error(), "abc")
+	}
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
+
+func BenchmarkFunctionCachePass(b *testing.B) {
+	items := make([]interface{}, 100)
+	for i := range items {
+		items[i] = map[string]interface{}{"name": fmt.Sprintf("item-%d", i), "labels": map[string]interface{}{"team": "qa"}}
+	}
+	m := NewMachine().Register("config", map[string]interface{}{"items": items})
+	fields := make([]Expression, 300)
+	for i := range fields {
+		fields[i] = MustCompile(fmt.Sprintf(`jq(config, ".items[%d].name") + "-" + jq(config, "[.items[].labels.team] | unique | join(\",\")")`, i%10))
+	}
+	resolve := func(b *testing.B, machines ...Machine) {
+		for i := range fields {
+			if _, err := fields[i].Resolve(machines...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			resolve(b, m)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			resolve(b, NewFunctionCache(FunctionCacheOptions{}), m)
+		}
+	})
+}
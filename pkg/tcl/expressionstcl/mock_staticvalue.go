@@ -5,6 +5,7 @@
 package expressionstcl
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -238,6 +239,26 @@ func (mr *MockStaticValueMockRecorder) Resolve(arg0 ...interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockStaticValue)(nil).Resolve), arg0...)
 }
 
+// ResolveContext mocks base method.
+func (m *MockStaticValue) ResolveContext(arg0 context.Context, arg1 ...Machine) (Expression, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ResolveContext", varargs...)
+	ret0, _ := ret[0].(Expression)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveContext indicates an expected call of ResolveContext.
+func (mr *MockStaticValueMockRecorder) ResolveContext(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveContext", reflect.TypeOf((*MockStaticValue)(nil).ResolveContext), varargs...)
+}
+
 // SafeResolve mocks base method.
 func (m *MockStaticValue) SafeResolve(arg0 ...Machine) (Expression, bool, error) {
 	m.ctrl.T.Helper()
@@ -8,7 +8,10 @@
 
 package expressionstcl
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 type negative struct {
 	expr Expression
@@ -38,6 +41,7 @@ func (s *negative) Template() string {
 }
 
 func (s *negative) SafeResolve(m ...Machine) (v Expression, changed bool, err error) {
+	s = &negative{expr: s.expr}
 	s.expr, changed, err = s.expr.SafeResolve(m...)
 	if err != nil {
 		return nil, changed, err
@@ -55,7 +59,11 @@ func (s *negative) SafeResolve(m ...Machine) (v Expression, changed bool, err er
 }
 
 func (s *negative) Resolve(m ...Machine) (v Expression, err error) {
-	return deepResolve(s, m...)
+	return s.ResolveContext(context.Background(), m...)
+}
+
+func (s *negative) ResolveContext(ctx context.Context, m ...Machine) (v Expression, err error) {
+	return deepResolve(s, withContext(ctx, m)...)
 }
 
 func (s *negative) Static() StaticValue {
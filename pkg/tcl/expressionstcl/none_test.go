@@ -0,0 +1,108 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func noneMachine() Machine {
+	return NewMachine().
+		Register("duration", None).
+		Register("missing", nil).
+		Register("count", 5).
+		Register("name", "api").
+		Register("config", map[string]interface{}{"timeout": nil})
+}
+
+func TestNoneArithmetic(t *testing.T) {
+	for _, op := range []string{"+", "-", "*", "/", "%", "**"} {
+		t.Run(op, func(t *testing.T) {
+			_, err := EvalExpression(fmt.Sprintf(`duration %s 5`, op), noneMachine())
+			assert.ErrorContains(t, err, "left operand is none (from accessor 'duration')")
+
+			_, err = EvalExpression(fmt.Sprintf(`count %s missing`, op), noneMachine())
+			assert.ErrorContains(t, err, "right operand is none (from accessor 'missing')")
+		})
+	}
+}
+
+func TestNoneComparison(t *testing.T) {
+	for _, op := range []string{"<", "<=", ">", ">="} {
+		t.Run(op, func(t *testing.T) {
+			_, err := EvalExpression(fmt.Sprintf(`config.timeout %s 5`, op), noneMachine())
+			assert.ErrorContains(t, err, "left operand is none (from accessor 'config.timeout')")
+
+			_, err = EvalExpression(fmt.Sprintf(`5 %s duration`, op), noneMachine())
+			assert.ErrorContains(t, err, "right operand is none (from accessor 'duration')")
+
+			_, err = EvalExpression(fmt.Sprintf(`duration %s missing`, op), noneMachine())
+			assert.ErrorContains(t, err, "left operand is none (from accessor 'duration')\nright operand is none (from accessor 'missing')")
+		})
+	}
+
+	_, err := Compile(`null > 1`)
+	assert.ErrorContains(t, err, "left operand is none")
+}
+
+func TestNoneEqualityAndLogical(t *testing.T) {
+	tests := map[string]bool{
+		`duration == null`:        true,
+		`null == duration`:        true,
+		`duration != null`:        false,
+		`missing == null`:         false,
+		`count == null`:           false,
+		`duration || true`:        true,
+		`!(duration && true)`:     true,
+		`config.timeout == count`: false,
+	}
+	for expr, expected := range tests {
+		v, err := EvalExpression(expr, noneMachine())
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, must(v.BoolValue()), expr)
+	}
+}
+
+func TestNoneStringConcatenation(t *testing.T) {
+	_, err := EvalExpression(`name + "-" + duration`, noneMachine())
+	assert.ErrorContains(t, err, "right operand is none (from accessor 'duration')")
+	_, err = EvalExpression(`duration + name`, noneMachine())
+	assert.ErrorContains(t, err, "left operand is none (from accessor 'duration')")
+	_, err = EvalString(`"prefix-" + null`, nil)
+	assert.ErrorContains(t, err, "right operand is none")
+
+	v, err := EvalString(`name + "-" + duration + "-" + missing`, nil, WithMachines(noneMachine()), WithNoneAsEmptyString())
+	assert.NoError(t, err)
+	assert.Equal(t, "api--", v)
+	v, err = EvalString(`null + "-suffix"`, nil, WithNoneAsEmptyString())
+	assert.NoError(t, err)
+	assert.Equal(t, "-suffix", v)
+
+	// None is not a string, so the option is not turning it into a number
+	_, err = EvalString(`duration + 1`, nil, WithMachines(noneMachine()), WithNoneAsEmptyString())
+	assert.ErrorContains(t, err, "left operand is none (from accessor 'duration')")
+
+	expr, err := MustCompile(`name + duration`).Resolve(noneMachine(), NoneAsEmptyStringMachine)
+	assert.NoError(t, err)
+	assert.Equal(t, `"api"`, expr.String())
+}
+
+func TestNoneInTemplate(t *testing.T) {
+	v, err := EvalTemplate(`value: {{duration}}, name: {{name}}`, noneMachine())
+	assert.NoError(t, err)
+	assert.Equal(t, "value: , name: api", v)
+}
+
+func TestOperandSourceInErrors(t *testing.T) {
+	_, err := EvalExpression(`name * count`, noneMachine())
+	assert.ErrorContains(t, err, `"api"*5 (left operand from accessor 'name', right operand from accessor 'count'): error while converting value to number`)
+}
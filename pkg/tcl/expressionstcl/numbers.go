@@ -0,0 +1,146 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	math2 "math"
+	"strconv"
+	"strings"
+)
+
+// compactNumberUnits are suffixes of compact numbers, each one is 1000 times the previous one
+var compactNumberUnits = []string{"", "k", "M", "B"}
+
+// formatNumber formats int exactly and float with the shortest representation
+func formatNumber(value StaticValue) string {
+	if value.IsInt() {
+		v, _ := value.IntValue()
+		return strconv.FormatInt(v, 10)
+	}
+	v, _ := value.FloatValue()
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// humanNumber groups integer digits of the number by thousands, e.g. "1,523,467.5"
+func humanNumber(value StaticValue, separator string) string {
+	str := formatNumber(value)
+	sign := ""
+	if strings.HasPrefix(str, "-") {
+		sign, str = "-", str[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(str, ".")
+
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteString(separator)
+		}
+		grouped.WriteRune(digit)
+	}
+	if hasFraction {
+		return sign + grouped.String() + "." + fraction
+	}
+	return sign + grouped.String()
+}
+
+// compactNumber shortens the number with unit suffix and one decimal of precision, e.g. "1.5M" or "12.3k",
+// value rounded up to the next unit is shown in that unit, e.g. 999950 is "1M"
+func compactNumber(value StaticValue) string {
+	v, _ := value.FloatValue()
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+
+	unit := 0
+	for unit < len(compactNumberUnits)-1 && v >= 1000 {
+		v /= 1000
+		unit++
+	}
+	rounded := math2.Round(v*10) / 10
+	if rounded >= 1000 && unit < len(compactNumberUnits)-1 {
+		rounded = math2.Round(rounded/1000*10) / 10
+		unit++
+	}
+	if rounded == 0 {
+		sign = ""
+	}
+	return sign + strconv.FormatFloat(rounded, 'f', -1, 64) + compactNumberUnits[unit]
+}
+
+// numberArgs reads the numbers passed to the aggregate function, either as a single list or as variadic arguments
+func numberArgs(name string, value []StaticValue) ([]interface{}, error) {
+	var list []interface{}
+	if len(value) == 1 && value[0].IsSlice() {
+		var err error
+		list, err = value[0].SliceValue()
+		if err != nil {
+			return nil, fmt.Errorf(`"%s" function expects a list of numbers, %s provided: %v`, name, value[0], err)
+		}
+	} else {
+		list = make([]interface{}, len(value))
+		for i := range value {
+			list[i] = value[i].Value()
+		}
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf(`"%s" function expects at least 1 number, empty list provided`, name)
+	}
+	return list, nil
+}
+
+// aggregateNumbers folds the numbers with the operation, keeping int64 precision when all of them are integers
+func aggregateNumbers(name string, list []interface{}, intOp func(a, b int64) int64, floatOp func(a, b float64) float64) (interface{}, error) {
+	allInts := true
+	floats := make([]float64, len(list))
+	for i := range list {
+		v, err := toFloat(list[i])
+		if err != nil {
+			return nil, fmt.Errorf(`"%s" function expects numbers, %v provided: %v`, name, list[i], err)
+		}
+		floats[i] = v
+		allInts = allInts && isInt(list[i])
+	}
+	if allInts {
+		result, _ := toInt(list[0])
+		for _, item := range list[1:] {
+			v, _ := toInt(item)
+			result = intOp(result, v)
+		}
+		return result, nil
+	}
+	result := floats[0]
+	for _, v := range floats[1:] {
+		result = floatOp(result, v)
+	}
+	return result, nil
+}
+
+// numberAggregateStdFunction builds the function accepting either a list of numbers or variadic numbers
+func numberAggregateStdFunction(name, description string, intOp func(a, b int64) int64, floatOp func(a, b float64) float64) StdFunction {
+	return StdFunction{
+		Description: description,
+		MinArgs:     1,
+		MaxArgs:     VariadicArgs,
+		ArgTypes:    []Type{TypeFloat64},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			list, err := numberArgs(name, value)
+			if err != nil {
+				return nil, err
+			}
+			result, err := aggregateNumbers(name, list, intOp, floatOp)
+			if err != nil {
+				return nil, err
+			}
+			return NewValue(result), nil
+		},
+	}
+}
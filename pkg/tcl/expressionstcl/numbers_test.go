@@ -0,0 +1,102 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanNumber(t *testing.T) {
+	tests := map[string]string{
+		`humanNumber(0)`:            `"0"`,
+		`humanNumber(999)`:          `"999"`,
+		`humanNumber(1000)`:         `"1,000"`,
+		`humanNumber(1523467)`:      `"1,523,467"`,
+		`humanNumber(-1523467)`:     `"-1,523,467"`,
+		`humanNumber(-100)`:         `"-100"`,
+		`humanNumber(1234567.891)`:  `"1,234,567.891"`,
+		`humanNumber(-0.5)`:         `"-0.5"`,
+		`humanNumber(123456789012)`: `"123,456,789,012"`,
+		`humanNumber(1523467, " ")`: `"1 523 467"`,
+		`humanNumber(1523467, ".")`: `"1.523.467"`,
+		`humanNumber(1523467, "")`:  `"1523467"`,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, MustCompile(expr).String(), expr)
+	}
+}
+
+func TestCompactNumber(t *testing.T) {
+	tests := map[string]string{
+		`compactNumber(0)`:          `"0"`,
+		`compactNumber(999)`:        `"999"`,
+		`compactNumber(12.34)`:      `"12.3"`,
+		`compactNumber(999.96)`:     `"1k"`,
+		`compactNumber(1000)`:       `"1k"`,
+		`compactNumber(1049)`:       `"1k"`,
+		`compactNumber(1050)`:       `"1.1k"`,
+		`compactNumber(12345)`:      `"12.3k"`,
+		`compactNumber(999949)`:     `"999.9k"`,
+		`compactNumber(999950)`:     `"1M"`,
+		`compactNumber(1000000)`:    `"1M"`,
+		`compactNumber(1523467)`:    `"1.5M"`,
+		`compactNumber(1e9)`:        `"1B"`,
+		`compactNumber(2.5e9)`:      `"2.5B"`,
+		`compactNumber(1.5e12)`:     `"1500B"`,
+		`compactNumber(-1523467)`:   `"-1.5M"`,
+		`compactNumber(-12345.6)`:   `"-12.3k"`,
+		`compactNumber(-0.04)`:      `"0"`,
+		`compactNumber(1523467.89)`: `"1.5M"`,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, MustCompile(expr).String(), expr)
+	}
+}
+
+func TestNumberFormattingInvalid(t *testing.T) {
+	_, err := Compile(`humanNumber("1000")`)
+	assert.ErrorContains(t, err, `"humanNumber" function expects a number`)
+	_, err = Compile(`compactNumber(1, 2)`)
+	assert.ErrorContains(t, err, `"compactNumber" expects 1 argument, 2 provided`)
+}
+
+func TestNumberAggregates(t *testing.T) {
+	tests := map[string]interface{}{
+		`max(list(1, 5, 3))`:  int64(5),
+		`max(1, 5, 3)`:        int64(5),
+		`max(1, 5.5, 3)`:      5.5,
+		`min(list(4, -2, 3))`: int64(-2),
+		`min(4, 2.5)`:         2.5,
+		`min(7)`:              int64(7),
+		`sum(list(1, 2, 3))`:  int64(6),
+		`sum(1, 2.5)`:         3.5,
+		`sum(list("1", "2"))`: float64(3),
+		`avg(list(1, 2))`:     1.5,
+		`avg(2, 4)`:           float64(3),
+		`abs(-3)`:             int64(3),
+		`abs(3)`:              int64(3),
+		`abs(-2.5)`:           2.5,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, MustCompile(expr).Static().Value(), expr)
+	}
+}
+
+func TestNumberAggregatesInvalid(t *testing.T) {
+	_, err := Compile(`max(list())`)
+	assert.ErrorContains(t, err, `"max" function expects at least 1 number, empty list provided`)
+	_, err = Compile(`avg()`)
+	assert.ErrorContains(t, err, `"avg" expects at least 1 argument, 0 provided`)
+	_, err = Compile(`sum(list(1, "a"))`)
+	assert.ErrorContains(t, err, `"sum" function expects numbers`)
+	_, err = Compile(`abs("a")`)
+	assert.ErrorContains(t, err, `"abs" function expects a number`)
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+// OperatorInterceptor is consulted before the default semantics of the binary operator with both operands static,
+// i.e. to make the domain values like quantities work with arithmetic, returning false falls through to the next
+// interceptor or the default semantics; the "&&" and "||" operators are not intercepted, as they short-circuit
+type OperatorInterceptor func(op string, left, right StaticValue) (Expression, bool, error)
+
+// WithOperatorInterceptors consults the interceptors for the binary operators, in registration order
+func WithOperatorInterceptors(interceptors ...OperatorInterceptor) ResolveOption {
+	return func(o *resolveOptions) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+type operatorsMachine struct {
+	interceptors []OperatorInterceptor
+}
+
+// NewOperatorsMachine passes the operator interceptors to the resolution, i.e. expr.Resolve(m, NewOperatorsMachine(fn)),
+// the interceptors of multiple machines are consulted in the machines order
+func NewOperatorsMachine(interceptors ...OperatorInterceptor) Machine {
+	return &operatorsMachine{interceptors: interceptors}
+}
+
+func (o *operatorsMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (o *operatorsMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// operatorsMachines finds the machines passing the operator interceptors, they are needed while compiling already,
+// so the static operations are not folded with the default semantics
+func operatorsMachines(m []Machine) []Machine {
+	var result []Machine
+	for i := range m {
+		if _, ok := m[i].(*operatorsMachine); ok {
+			result = append(result, m[i])
+		}
+	}
+	return result
+}
+
+// interceptOperator consults the interceptors of the resolution for the operation
+func interceptOperator(m []Machine, op operator, left, right StaticValue) (Expression, bool, error) {
+	if op == operatorAnd || op == operatorOr {
+		return nil, false, nil
+	}
+	for i := range m {
+		o, ok := m[i].(*operatorsMachine)
+		if !ok {
+			continue
+		}
+		for _, interceptor := range o.interceptors {
+			result, ok, err := interceptor(string(op), left, right)
+			if ok || err != nil {
+				return result, true, err
+			}
+		}
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// versionInterceptor compares the "v"-prefixed strings by their length, to tell it from the default semantics
+func versionInterceptor(op string, left, right StaticValue) (Expression, bool, error) {
+	l, _ := left.StringValue()
+	r, _ := right.StringValue()
+	if !left.IsString() || !right.IsString() || !strings.HasPrefix(l, "v") || !strings.HasPrefix(r, "v") {
+		return nil, false, nil
+	}
+	switch op {
+	case "<":
+		return NewValue(len(l) < len(r)), true, nil
+	case "+":
+		return nil, true, errors.New("versions can't be added")
+	}
+	return nil, false, nil
+}
+
+func TestOperatorInterceptors(t *testing.T) {
+	result, err := EvalString(`"v10" < "v9"`, nil, WithOperatorInterceptors(versionInterceptor))
+	assert.NoError(t, err)
+	assert.Equal(t, false, result)
+
+	result, err = EvalString(`a < "v10"`, map[string]interface{}{"a": "v9"}, WithOperatorInterceptors(versionInterceptor))
+	assert.NoError(t, err)
+	assert.Equal(t, true, result)
+
+	// Fall through to the default semantics
+	result, err = EvalString(`"v10" == "v9"`, nil, WithOperatorInterceptors(versionInterceptor))
+	assert.NoError(t, err)
+	assert.Equal(t, false, result)
+	result, err = EvalString(`"a" + "b"`, nil, WithOperatorInterceptors(versionInterceptor))
+	assert.NoError(t, err)
+	assert.Equal(t, "ab", result)
+
+	_, err = EvalString(`"v1" + "v2"`, nil, WithOperatorInterceptors(versionInterceptor))
+	assert.ErrorContains(t, err, "versions can't be added")
+
+	// Without the interceptors
+	_, err = EvalString(`"v10" < "v9"`, nil)
+	assert.ErrorContains(t, err, "error while converting value to number")
+}
+
+func TestOperatorInterceptorsOrder(t *testing.T) {
+	calls := make([]string, 0)
+	interceptor := func(name string, ok bool) OperatorInterceptor {
+		return func(op string, left, right StaticValue) (Expression, bool, error) {
+			calls = append(calls, name)
+			if !ok {
+				return nil, false, nil
+			}
+			return NewValue(name), true, nil
+		}
+	}
+	result, err := EvalString(`1 + 2`, nil, WithOperatorInterceptors(interceptor("first", false), interceptor("second", true)),
+		WithOperatorInterceptors(interceptor("third", true)))
+	assert.NoError(t, err)
+	assert.Equal(t, "second", result)
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
+
+func TestOperatorInterceptorsMachine(t *testing.T) {
+	expr := MustCompile(`a < "v9"`)
+	result, err := expr.Resolve(NewMachine().Register("a", "v10"), NewOperatorsMachine(versionInterceptor))
+	assert.NoError(t, err)
+	assert.Equal(t, `false`, result.String())
+
+	result, err = EvalExpressionPartial(`"v10" < "v9" && b`, NewOperatorsMachine(versionInterceptor))
+	assert.NoError(t, err)
+	assert.Equal(t, `false`, result.String())
+}
@@ -112,6 +112,12 @@ func getNextSegment(t []token) (e Expression, i int, err error) {
 		if err != nil {
 			return nil, 0, err
 		}
+		// Keep the integer literals beyond float64 precision exact
+		if v, ok := e.(*static); ok {
+			if n, ok := v.value.(int64); ok && n != math2.MinInt64 {
+				return NewValue(-n), i + 1, nil
+			}
+		}
 		return newMath(operatorSubtract, NewValue(0), e), i + 1, nil
 	}
 
@@ -175,6 +181,17 @@ func parse(t []token) (e Expression, err error) {
 }
 
 func Compile(exp string) (Expression, error) {
+	return compile(nil, exp)
+}
+
+// CompileWithFeatures compiles the expression like Compile, with features overriding the defaults
+func CompileWithFeatures(exp string, features Features) (Expression, error) {
+	return compile(features, exp)
+}
+
+// compile parses the expression and resolves its static parts, with the machines like NewOperatorsMachine,
+// that change how the static parts are resolved
+func compile(features Features, exp string, machines ...Machine) (Expression, error) {
 	t, _, e := tokenize(exp, 0)
 	if e != nil {
 		return nil, fmt.Errorf("tokenizer error: %v", e)
@@ -183,7 +200,21 @@ func Compile(exp string) (Expression, error) {
 	if e != nil {
 		return nil, fmt.Errorf("parser error: %v", e)
 	}
-	return v.Resolve()
+	return v.Resolve(append(append(featuresMachines(features), machines...), deferredClockMachine)...)
+}
+
+// compiling checks if the expression is resolved while compiling, without any machines providing the values
+func compiling(m []Machine) bool {
+	for i := range m {
+		switch m[i].(type) {
+		case *operatorsMachine, *limitsMachine, *randomMachine:
+			continue
+		}
+		if m[i] != deferredClockMachine {
+			return false
+		}
+	}
+	return true
 }
 
 func MustCompile(exp string) Expression {
@@ -196,22 +227,50 @@ func MustCompile(exp string) Expression {
 
 var endExprRe = regexp.MustCompile(`^\s*}}`)
 
+// appendTemplate concatenates the next part of the template, the first part starts the expression
+func appendTemplate(e Expression, part Expression) Expression {
+	if e == nil {
+		return part
+	}
+	return newMath(operatorAdd, e, part)
+}
+
 func CompileTemplate(tpl string) (Expression, error) {
+	return compileTemplate(nil, tpl)
+}
+
+// CompileTemplateWithFeatures compiles the template like CompileTemplate, with features overriding the defaults
+func CompileTemplateWithFeatures(tpl string, features Features) (Expression, error) {
+	return compileTemplate(features, tpl)
+}
+
+// compileTemplate concatenates the text with the {{ expressions }} converted to strings,
+// the "\{{" is the literal "{{", and the errors point to the offset of the failed expression
+func compileTemplate(features Features, tpl string, machines ...Machine) (Expression, error) {
 	var e Expression
 
 	offset := 0
+	text := ""
 	for index := strings.Index(tpl[offset:], "{{"); index != -1; index = strings.Index(tpl[offset:], "{{") {
-		if index != 0 {
-			e = newMath(operatorAdd, e, NewStringValue(tpl[offset:offset+index]))
+		start := offset + index
+		if start > offset && tpl[start-1] == '\\' {
+			text += tpl[offset:start-1] + "{{"
+			offset = start + 2
+			continue
+		}
+		text += tpl[offset:start]
+		if text != "" {
+			e = appendTemplate(e, NewStringValue(text))
+			text = ""
 		}
-		offset += index + 2
+		offset = start + 2
 		tokens, i, err := tokenize(tpl, offset)
 		offset = i
 		if err == nil {
-			return nil, errors.New("template error: expression not closed")
+			return nil, fmt.Errorf("template error: expression at offset %d not closed", start)
 		}
 		if !endExprRe.MatchString(tpl[offset:]) || !strings.Contains(err.Error(), "unknown character") {
-			return nil, fmt.Errorf("tokenizer error: %v", err)
+			return nil, fmt.Errorf("tokenizer error: expression at offset %d: %v", start, err)
 		}
 		offset += len(endExprRe.FindString(tpl[offset:]))
 		if len(tokens) == 0 {
@@ -219,21 +278,22 @@ func CompileTemplate(tpl string) (Expression, error) {
 		}
 		v, err := parse(tokens)
 		if err != nil {
-			return nil, fmt.Errorf("parser error: %v", e)
+			return nil, fmt.Errorf("parser error: expression at offset %d: %v", start, err)
 		}
-		v, err = v.Resolve()
+		v, err = v.Resolve(append(append(featuresMachines(features), machines...), deferredClockMachine)...)
 		if err != nil {
-			return nil, fmt.Errorf("expression error: %v", e)
+			return nil, fmt.Errorf("expression error: expression at offset %d: %v", start, err)
 		}
-		e = newMath(operatorAdd, e, CastToString(v))
+		e = appendTemplate(e, castToString(features, v))
 	}
-	if offset < len(tpl) {
-		e = newMath(operatorAdd, e, NewStringValue(tpl[offset:]))
+	text += tpl[offset:]
+	if text != "" {
+		e = appendTemplate(e, NewStringValue(text))
 	}
 	if e == nil {
 		return NewStringValue(""), nil
 	}
-	return e.Resolve()
+	return e.Resolve(append(featuresMachines(features), machines...)...)
 }
 
 func MustCompileTemplate(tpl string) Expression {
@@ -31,6 +31,8 @@ func TestCompileTernary(t *testing.T) {
 	assert.Equal(t, "xyz", must(MustCompile(`false ? "value" : true ? "xyz" :"another"`).Static().StringValue()))
 	assert.Equal(t, "xyz", must(MustCompile(`false ? "value" : (true ? "xyz" :"another")`).Static().StringValue()))
 	assert.Equal(t, 5.78, must(MustCompile(`false ? 3 : (true ? 5.78 : 2)`).Static().FloatValue()))
+	assert.Equal(t, `a ? b : (c ? d : e)`, MustCompile(`a ? b : (c ? d : e)`).String())
+	assert.Equal(t, `(a ? b : c) ? d : e`, MustCompile(`(a ? b : c) ? d : e`).String())
 }
 
 func TestCompileMath(t *testing.T) {
@@ -215,6 +217,34 @@ func TestCompileEscapeTemplate(t *testing.T) {
 	assert.Equal(t, `foo{{"{{"}}barbaz{{"{{"}}`, MustCompileTemplate(`foo{{"{{bar"}}baz{{"{{"}}`).Template())
 }
 
+func TestCompileEscapeTemplateBackslash(t *testing.T) {
+	assert.Equal(t, `"foo {{bar}} 3"`, MustCompileTemplate(`foo \{{bar}} {{1 + 2}}`).String())
+	assert.Equal(t, `"{{ 5"`, MustCompileTemplate(`\{{ {{5}}`).String())
+	assert.Equal(t, `"a\\3"`, MustCompileTemplate(`a{{"\\"}}{{3}}`).String())
+
+	// The template of the static value reads back as the same value
+	for _, str := range []string{`foo \{{bar}}`, `\\{{`, `{{\`, `a{{b}}c`} {
+		assert.Equal(t, str, must(MustCompileTemplate(NewValue(str).Template()).Static().StringValue()), str)
+	}
+}
+
+func TestCompileTemplateNestedBraces(t *testing.T) {
+	assert.Equal(t, `"a 1 b"`, MustCompileTemplate(`a {{ {"x": {"y": 1}}.x.y }} b`).String())
+	assert.Equal(t, `"{\"x\":\"}}\"}"`, MustCompileTemplate(`{{tojson({"x": "}}"})}}`).String())
+	assert.Equal(t, `"x}}y"`, MustCompileTemplate(`x{{"}}"}}y`).String())
+}
+
+func TestCompileTemplateErrorOffset(t *testing.T) {
+	_, err := CompileTemplate(`abc {{ 1 + 2`)
+	assert.EqualError(t, err, "template error: expression at offset 4 not closed")
+
+	_, err = CompileTemplate(`{{1}} and {{ 1 + }}`)
+	assert.ErrorContains(t, err, "parser error: expression at offset 10: ")
+
+	_, err = CompileTemplate(`a {{ 1 # 2 }}`)
+	assert.ErrorContains(t, err, "tokenizer error: expression at offset 2: ")
+}
+
 func TestCompileStandardLib(t *testing.T) {
 	assert.Equal(t, `false`, MustCompile(`bool(0)`).String())
 	assert.Equal(t, `true`, MustCompile(`bool(500)`).String())
@@ -236,15 +266,15 @@ func TestCompileStandardLib(t *testing.T) {
 	assert.Equal(t, `"abc  d"`, MustCompile(`trim("   abc  d  \n  ")`).String())
 	assert.Equal(t, `"abc"`, MustCompile(`yaml("\"abc\"")`).String())
 	assert.Equal(t, `{"foo":{"bar":"baz"}}`, MustCompile(`yaml("foo:\n  bar: 'baz'")`).String())
-	assert.Equal(t, `"foo:\n    bar: baz\n"`, MustCompile(`toyaml({"foo":{"bar":"baz"}})`).String())
+	assert.Equal(t, `"foo:\n  bar: baz\n"`, MustCompile(`toyaml({"foo":{"bar":"baz"}})`).String())
 	assert.Equal(t, `{"a":["b","v"]}`, MustCompile(`yaml("
 a:
 - b
 - v
 ")`).String())
 	assert.Equal(t, `["a",10,["a",4]]`, MustCompile(`list("a", 10, ["a", 4])`).String())
-	assert.Equal(t, `"a,10,a,4"`, MustCompile(`join(["a",10,["a",4]])`).String())
-	assert.Equal(t, `"a---10---a,4"`, MustCompile(`join(["a",10,["a",4]], "---")`).String())
+	assert.Equal(t, `"a,10,[\"a\",4]"`, MustCompile(`join(["a",10,["a",4]])`).String())
+	assert.Equal(t, `"a---10---[\"a\",4]"`, MustCompile(`join(["a",10,["a",4]], "---")`).String())
 	assert.Equal(t, `[""]`, MustCompile(`split(null)`).String())
 	assert.Equal(t, `["a","b","c"]`, MustCompile(`split("a,b,c")`).String())
 	assert.Equal(t, `["a","b","c"]`, MustCompile(`split("a---b---c", "---")`).String())
@@ -271,6 +301,89 @@ a:
 	assert.Equal(t, `"abc"`, MustCompile(`eval("\"abc\"")`).String())
 	assert.Equal(t, `50`, MustCompile(`eval("5 * 10")`).String())
 	assert.Equal(t, `50*something`, MustCompile(`eval("5 * 10 * something")`).String())
+	assert.Equal(t, `-1`, MustCompile(`semverCompare("v1.2.3", "1.10.0")`).String())
+	assert.Equal(t, `true`, MustCompile(`semverSatisfies("1.4.0", ">=1.2.0 <2.0.0")`).String())
+	assert.Equal(t, `{"build":"sha.5114f85","major":1,"minor":2,"patch":3,"prerelease":"rc.1"}`, MustCompile(`semverParse("v1.2.3-rc.1+sha.5114f85")`).String())
+	assert.Equal(t, `2`, MustCompile(`semverParse("1.2.3").minor`).String())
+}
+
+func TestCompileStandardLibCoalesceList(t *testing.T) {
+	assert.Equal(t, `["a.spec.js"]`, MustCompile(`coalesceList(["a.spec.js"], ["b.spec.js"])`).String())
+	assert.Equal(t, `["b.spec.js"]`, MustCompile(`coalesceList([], ["b.spec.js"])`).String())
+	assert.Equal(t, `["b.spec.js"]`, MustCompile(`coalesceList(null, [], ["b.spec.js"], ["c.spec.js"])`).String())
+	assert.Equal(t, `null`, MustCompile(`coalesceList([], null, [])`).String())
+	assert.Equal(t, `null`, MustCompile(`coalesceList(null)`).String())
+	assert.Equal(t, `null`, MustCompile(`coalesceList()`).String())
+	assert.Equal(t, `"none"`, MustCompile(`coalesceList([]) == null ? "none" : "empty"`).String())
+
+	_, err := Compile(`coalesceList([], "a.spec.js")`)
+	assert.ErrorContains(t, err, `"coalesceList" function expects lists or null, "a.spec.js" provided as argument 2`)
+	_, err = Compile(`coalesceList(["a.spec.js"], 5)`)
+	assert.ErrorContains(t, err, `"coalesceList" function expects lists or null, 5 provided as argument 2`)
+}
+
+func TestCompileStandardLibCoalesce(t *testing.T) {
+	assert.Equal(t, `"a"`, MustCompile(`coalesce(null, "", "a", "b")`).String())
+	assert.Equal(t, `0`, MustCompile(`coalesce(null, 0, 1)`).String())
+	assert.Equal(t, `false`, MustCompile(`coalesce("", false)`).String())
+	assert.Equal(t, `[]`, MustCompile(`coalesce(null, [])`).String())
+	assert.Equal(t, `null`, MustCompile(`coalesce(null, "", null)`).String())
+	assert.Equal(t, `null`, MustCompile(`coalesce()`).String())
+	assert.Equal(t, `"x"`, MustCompile(`default(null, "x")`).String())
+	assert.Equal(t, `"x"`, MustCompile(`default("", "x")`).String())
+	assert.Equal(t, `"a"`, MustCompile(`default("a", "x")`).String())
+	assert.Equal(t, `0`, MustCompile(`default(0, 5)`).String())
+
+	_, err := Compile(`default(null)`)
+	assert.ErrorContains(t, err, `"default" expects 2 arguments, 1 provided`)
+
+	assert.Equal(t, "a", must(CallStdFunction("coalesce", None, "", None, "a")).Static().Value())
+	assert.True(t, must(CallStdFunction("coalesce", None, None)).Static().IsNone())
+	assert.Equal(t, 5, must(CallStdFunction("default", None, 5)).Static().Value())
+	assert.Equal(t, "x", must(CallStdFunction("default", "", "x")).Static().Value())
+}
+
+func TestCompileStandardLibEmpty(t *testing.T) {
+	tests := map[string]bool{
+		`empty(null)`:     true,
+		`empty("")`:       true,
+		`empty([])`:       true,
+		`empty({})`:       true,
+		`empty(" ")`:      false,
+		`empty([null])`:   false,
+		`empty({"a": 1})`: false,
+		`empty(0)`:        false,
+		`empty(false)`:    false,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, must(must(Compile(expr)).Static().BoolValue()), expr)
+	}
+	assert.True(t, must(must(CallStdFunction("empty", None)).Static().BoolValue()))
+	assert.False(t, must(must(CallStdFunction("empty", map[string]interface{}{"a": None})).Static().BoolValue()))
+
+	_, err := Compile(`empty()`)
+	assert.ErrorContains(t, err, `"empty" expects 1 argument, 0 provided`)
+}
+
+func TestCompileStandardLibPaths(t *testing.T) {
+	assert.Equal(t, `"report.xml"`, MustCompile(`basename("/data/reports/report.xml")`).String())
+	assert.Equal(t, `"reports"`, MustCompile(`basename("/data/reports/")`).String())
+	assert.Equal(t, `"/"`, MustCompile(`basename("/")`).String())
+	assert.Equal(t, `"/data"`, MustCompile(`dirname("/data/reports/")`).String())
+	assert.Equal(t, `"/data/reports"`, MustCompile(`dirname("/data/reports/report.xml")`).String())
+	assert.Equal(t, `"."`, MustCompile(`dirname("report.xml")`).String())
+	assert.Equal(t, `"/"`, MustCompile(`dirname("//")`).String())
+	assert.Equal(t, `".gz"`, MustCompile(`ext("logs/archive.tar.gz")`).String())
+	assert.Equal(t, `".xml"`, MustCompile(`ext("/data/reports/report.xml/")`).String())
+	assert.Equal(t, `""`, MustCompile(`ext("/home/tk/.bashrc")`).String())
+	assert.Equal(t, `".json"`, MustCompile(`ext(".config.json")`).String())
+	assert.Equal(t, `""`, MustCompile(`ext("/data/Makefile")`).String())
+	assert.Equal(t, `""`, MustCompile(`ext("..")`).String())
+	assert.Equal(t, `"/data/reports/report.xml"`, MustCompile(`pathJoin("/data/", "/reports/", "report.xml")`).String())
+	assert.Equal(t, `"data/reports"`, MustCompile(`pathJoin(["data", "", "reports/"])`).String())
+	assert.Equal(t, `""`, MustCompile(`pathJoin()`).String())
+	assert.Equal(t, `"/data/report.xml"`, MustCompile(`pathClean("/data//reports/../report.xml")`).String())
+	assert.Equal(t, `"."`, MustCompile(`pathClean("")`).String())
 }
 
 func TestCompileWildcard_Unknown(t *testing.T) {
@@ -343,3 +456,16 @@ func TestCompileImmutableNone(t *testing.T) {
 	assert.Same(t, None, NewValue(noneValue))
 	assert.Same(t, NewValue(noneValue), NewValue(noneValue))
 }
+
+func TestResolveMultipleErrors(t *testing.T) {
+	_, err := MustCompile(`a + shellquote(b, c ? d : e, a) + (f || g)`).Resolve(FinalizerFail)
+	assert.EqualError(t, err, "error while accessing a: unknown variable\n"+
+		"error while accessing b: unknown variable\n"+
+		"error while accessing c: unknown variable\n"+
+		"error while accessing f: unknown variable\n"+
+		"error while accessing g: unknown variable")
+
+	_, err = MustCompileTemplate(`{{a}}-{{b}}-{{a}}`).Resolve(FinalizerFail)
+	assert.EqualError(t, err, "error while accessing a: unknown variable\n"+
+		"error while accessing b: unknown variable")
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// PathsMaxDepth is a maximum nesting of the value traversed by "paths" and "findPaths"
+	PathsMaxDepth = 64
+	// PathsMaxNodes is a maximum number of maps, lists and leaf values visited by "paths" and "findPaths"
+	PathsMaxNodes = 100_000
+)
+
+var pathSegmentEscaper = strings.NewReplacer(`\`, `\\`, `.`, `\.`)
+
+// escapePathSegment escapes the key so it can be joined with dots, e.g. "a.b" key is "a\.b" segment
+func escapePathSegment(key string) string {
+	return pathSegmentEscaper.Replace(key)
+}
+
+type pathEntry struct {
+	path string
+	key  string
+	leaf bool
+}
+
+// collectPaths walks the value and returns dotted paths to all its nested values,
+// empty maps and lists are leaves too, list items are addressed by index
+func collectPaths(value interface{}) ([]pathEntry, error) {
+	var result []pathEntry
+	nodes := 0
+	var walk func(v interface{}, path, key string, depth int) error
+	walk = func(v interface{}, path, key string, depth int) error {
+		nodes++
+		if PathsMaxNodes > 0 && nodes > PathsMaxNodes {
+			return fmt.Errorf("value has more than %d nodes", PathsMaxNodes)
+		}
+		if PathsMaxDepth > 0 && depth > PathsMaxDepth {
+			return fmt.Errorf("value is nested deeper than %d levels", PathsMaxDepth)
+		}
+
+		var children map[string]interface{}
+		if isMap(v) || isStruct(v) {
+			children, _ = toMap(v)
+		} else if isSlice(v) {
+			items, _ := toSlice(v)
+			children = make(map[string]interface{}, len(items))
+			for i := range items {
+				children[strconv.Itoa(i)] = items[i]
+			}
+		}
+		if path != "" {
+			result = append(result, pathEntry{path: path, key: key, leaf: len(children) == 0})
+		}
+
+		for k, child := range children {
+			childPath := escapePathSegment(k)
+			if path != "" {
+				childPath = path + "." + childPath
+			}
+			if err := walk(child, childPath, k, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(value, "", "", 0); err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].path < result[j].path
+	})
+	return result, nil
+}
+
+// leafPaths returns sorted paths to all leaf values
+func leafPaths(value interface{}) ([]string, error) {
+	entries, err := collectPaths(value)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.leaf {
+			result = append(result, entry.path)
+		}
+	}
+	return result, nil
+}
+
+// findPaths returns sorted paths to values stored under the key, including maps and lists
+func findPaths(value interface{}, key string) ([]string, error) {
+	entries, err := collectPaths(value)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0)
+	for _, entry := range entries {
+		if entry.key == key {
+			result = append(result, entry.path)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaths(t *testing.T) {
+	assert.Equal(t, `["a.b","a.c.0","a.c.1.d","e"]`, MustCompile(`paths({"e": 1, "a": {"c": [true, {"d": null}], "b": "x"}})`).String())
+	assert.Equal(t, `["0","1.0"]`, MustCompile(`paths([1, [2]])`).String())
+	assert.Equal(t, `["empty","list"]`, MustCompile(`paths({"empty": {}, "list": []})`).String())
+	assert.Equal(t, `[]`, MustCompile(`paths("scalar")`).String())
+	assert.Equal(t, `[]`, MustCompile(`paths({})`).String())
+}
+
+func TestPathsEscaping(t *testing.T) {
+	value := map[string]interface{}{
+		"app.kubernetes.io/name": "api",
+		`C:\tmp`:                 map[string]interface{}{"x": 1},
+	}
+	v, err := leafPaths(value)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`C:\\tmp.x`, `app\.kubernetes\.io/name`}, v)
+}
+
+func TestFindPaths(t *testing.T) {
+	value := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "api", "labels": map[string]interface{}{"name": "x"}},
+		"items": []interface{}{
+			map[string]interface{}{"name": "first"},
+			map[string]interface{}{"id": 2},
+		},
+	}
+	v, err := EvalString(`findPaths(payload, "name")`, map[string]interface{}{"payload": value})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"items.0.name", "metadata.labels.name", "metadata.name"}, v)
+
+	v, err = EvalString(`findPaths(payload, "labels")`, map[string]interface{}{"payload": value})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"metadata.labels"}, v)
+
+	assert.Equal(t, `[]`, MustCompile(`findPaths({"a": 1}, "missing")`).String())
+}
+
+func TestPathsStruct(t *testing.T) {
+	type item struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	}
+	v, err := leafPaths([]item{{Name: "a", Labels: map[string]string{"env": "prod"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0.labels.env", "0.name"}, v)
+}
+
+func TestPathsLimits(t *testing.T) {
+	defer func(depth, nodes int) { PathsMaxDepth, PathsMaxNodes = depth, nodes }(PathsMaxDepth, PathsMaxNodes)
+	PathsMaxDepth, PathsMaxNodes = 3, 10
+
+	_, err := Compile(`paths({"a": {"b": {"c": {"d": 1}}}})`)
+	assert.ErrorContains(t, err, "value is nested deeper than 3 levels")
+	_, err = Compile(`findPaths([` + strings.Repeat("1,", 20) + `1], "0")`)
+	assert.ErrorContains(t, err, "value has more than 10 nodes")
+
+	v, err := leafPaths(map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": 1}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.b.c"}, v)
+}
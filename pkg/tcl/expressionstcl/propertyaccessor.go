@@ -9,6 +9,7 @@
 package expressionstcl
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -42,9 +43,15 @@ func (s *propertyAccessor) Template() string {
 
 func (s *propertyAccessor) SafeResolve(m ...Machine) (v Expression, changed bool, err error) {
 	if s.value.Static() == nil {
-		s.value, changed, err = s.value.SafeResolve(m...)
-		if !changed || err != nil || s.value.Static() == nil {
-			return s, changed, err
+		s = &propertyAccessor{value: s.value, path: s.path}
+		var value Expression
+		value, changed, err = s.value.SafeResolve(m...)
+		if err != nil {
+			return nil, changed, err
+		}
+		s.value = value
+		if !changed || s.value.Static() == nil {
+			return s, changed, nil
 		}
 	}
 	current := s.value
@@ -58,7 +65,11 @@ func (s *propertyAccessor) SafeResolve(m ...Machine) (v Expression, changed bool
 }
 
 func (s *propertyAccessor) Resolve(m ...Machine) (v Expression, err error) {
-	return deepResolve(s, m...)
+	return s.ResolveContext(context.Background(), m...)
+}
+
+func (s *propertyAccessor) ResolveContext(ctx context.Context, m ...Machine) (v Expression, err error) {
+	return deepResolve(s, withContext(ctx, m)...)
 }
 
 func (s *propertyAccessor) Static() StaticValue {
@@ -0,0 +1,98 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const (
+	randomStringAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	// maxRandomStringLength prevents exhausting the memory with a single call
+	maxRandomStringLength = 65536
+)
+
+var errRandomNotEnabled = errors.New("function generating random values is not enabled for the resolution, see NewRandomMachine")
+
+type randomMachine struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewRandomMachine enables uuid(), random() and randomString() for the resolution, i.e. expr.Resolve(m, NewRandomMachine());
+// without it they are left unresolved, so the value is generated once, by the final resolution,
+// and not i.e. while compiling or scheduling; the values are not suitable for the secrets
+func NewRandomMachine() Machine {
+	var seed [8]byte
+	_, _ = cryptorand.Read(seed[:])
+	return NewSeededRandomMachine(int64(binary.LittleEndian.Uint64(seed[:])))
+}
+
+// NewSeededRandomMachine enables the random functions like NewRandomMachine,
+// but the sequence of the values is the same for the same seed, i.e. for tests
+func NewSeededRandomMachine(seed int64) Machine {
+	return &randomMachine{rand: rand.New(rand.NewSource(seed))}
+}
+
+func (r *randomMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (r *randomMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// generate calls the handler with the source of the machine, which is not safe for concurrent use itself
+func (r *randomMachine) generate(fn func(*rand.Rand) (Expression, error)) (Expression, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return fn(r.rand)
+}
+
+// resolveRandom finds the machine enabling the random functions, it's nil when they should not be resolved
+func resolveRandom(m []Machine) *randomMachine {
+	for i := range m {
+		if r, ok := m[i].(*randomMachine); ok {
+			return r
+		}
+	}
+	return nil
+}
+
+// isRandomStdFunction checks if the standard library function generates random values
+func isRandomStdFunction(name string) bool {
+	fn, ok := getStdFunction(name)
+	return ok && fn.RandomHandler != nil
+}
+
+func randomUUID(r *rand.Rand) (string, error) {
+	id, err := uuid.NewRandomFromReader(r)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func randomString(r *rand.Rand, length int64) (string, error) {
+	if length < 0 || length > maxRandomStringLength {
+		return "", fmt.Errorf("length should be between 0 and %d, %d provided", maxRandomStringLength, length)
+	}
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = randomStringAlphabet[r.Intn(len(randomStringAlphabet))]
+	}
+	return string(b), nil
+}
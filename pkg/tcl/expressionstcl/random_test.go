@@ -0,0 +1,79 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var uuidV4Re = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRandomDeferred(t *testing.T) {
+	assert.Equal(t, `uuid()`, MustCompile(`uuid()`).String())
+	assert.Equal(t, `"id-"+uuid()`, MustCompile(`"id-" + uuid()`).String())
+	assert.Equal(t, `random(10)`, MustCompile(`random(10)`).String())
+
+	expr, err := MustCompile(`randomString(8)`).Resolve(StdLibMachine, NewMachine().Register("a", 1))
+	assert.NoError(t, err)
+	assert.Equal(t, `randomString(8)`, expr.String())
+
+	_, err = EvalExpression(`uuid()`)
+	assert.ErrorContains(t, err, "expression should be static: uuid()")
+	_, err = MustCompile(`uuid()`).Resolve(FinalizerFail)
+	assert.ErrorContains(t, err, "function generating random values is not enabled for the resolution")
+	_, err = CallStdFunction("uuid")
+	assert.ErrorContains(t, err, "function generating random values is not enabled for the resolution")
+}
+
+func TestRandomResolved(t *testing.T) {
+	v, err := EvalString(`uuid()`, nil, WithRandom())
+	assert.NoError(t, err)
+	assert.Regexp(t, uuidV4Re, v)
+
+	v, err = EvalString(`random(10)`, nil, WithRandom())
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, v, int64(0))
+	assert.Less(t, v, int64(10))
+
+	v, err = EvalString(`randomString(24)`, nil, WithRandom())
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[A-Za-z0-9]{24}$`, v)
+
+	expr, err := MustCompile(`"id-" + uuid()`).Resolve(NewRandomMachine())
+	assert.NoError(t, err)
+	assert.Regexp(t, `^"id-[0-9a-f-]{36}"$`, expr.String())
+}
+
+func TestRandomSeeded(t *testing.T) {
+	expr := MustCompile(`uuid() + "," + string(random(1000000)) + "," + randomString(16)`)
+	first, err := expr.Resolve(NewSeededRandomMachine(42))
+	assert.NoError(t, err)
+	second, err := expr.Resolve(NewSeededRandomMachine(42))
+	assert.NoError(t, err)
+	other, err := expr.Resolve(NewSeededRandomMachine(43))
+	assert.NoError(t, err)
+
+	assert.Equal(t, first.String(), second.String())
+	assert.NotEqual(t, first.String(), other.String())
+}
+
+func TestRandomErrors(t *testing.T) {
+	m := NewSeededRandomMachine(1)
+	_, err := MustCompile(`random(0)`).Resolve(m)
+	assert.ErrorContains(t, err, `"random" function expects a positive integer, 0 provided`)
+	_, err = MustCompile(`randomString(-1)`).Resolve(m)
+	assert.ErrorContains(t, err, `"randomString" function: length should be between 0 and 65536, -1 provided`)
+
+	v, err := MustCompile(`randomString(0)`).Resolve(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `""`, v.String())
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexFunctions(t *testing.T) {
+	vars := map[string]interface{}{
+		"output": "Tests: 12 passed, 3 failed\nDuration: 1.5s",
+	}
+	tests := map[string]interface{}{
+		`regexMatch("[0-9]+ failed", output)`:                       true,
+		`regexMatch("^Tests", "")`:                                  false,
+		`regexMatch("", "")`:                                        true,
+		`regexMatch("^1[0-9]$", 12)`:                                true,
+		`regexFind("[0-9]+ passed", output)`:                        "12 passed",
+		`regexFind("[0-9]+ skipped", output)`:                       noneValue,
+		`regexFind("x*", "")`:                                       "",
+		`regexFind("[0-9]+", 3.5)`:                                  "3",
+		`regexFindAll("[0-9]+", output)`:                            []string{"12", "3", "1", "5"},
+		`regexFindAll("[0-9]+", "")`:                                []string{},
+		`regexFindAll("e", true)`:                                   []string{"e"},
+		`regexReplace("([0-9]+) (passed|failed)", "$2=$1", output)`: "Tests: passed=12, failed=3\nDuration: 1.5s",
+		`regexReplace("(?P<n>[0-9]+)s", "${n} seconds", output)`:    "Tests: 12 passed, 3 failed\nDuration: 1.5 seconds",
+		`regexReplace("a", "b", "")`:                                "",
+		`regexReplace("0", 1, 100)`:                                 "111",
+	}
+	for expr, expected := range tests {
+		v, err := EvalString(expr, vars)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+}
+
+func TestRegexFunctionsErrors(t *testing.T) {
+	tests := map[string]string{
+		`regexMatch("a")`:                  `"regexMatch" expects 2 arguments, 1 provided`,
+		`regexFind("a", "b", "c")`:         `"regexFind" expects 2 arguments, 3 provided`,
+		`regexReplace("a", "b")`:           `"regexReplace" expects 3 arguments, 2 provided`,
+		`regexMatch("[a-", "a")`:           `"regexMatch" function: invalid pattern: error parsing regexp`,
+		`regexFindAll("(a", "a")`:          `"regexFindAll" function: invalid pattern: error parsing regexp`,
+		`regexReplace("a{2,1}", "b", "a")`: `"regexReplace" function: invalid pattern: error parsing regexp`,
+	}
+	for expr, expected := range tests {
+		_, err := EvalString(expr, nil)
+		assert.ErrorContains(t, err, expected, expr)
+	}
+}
+
+func TestRegexFunctionsReturnType(t *testing.T) {
+	assert.Equal(t, TypeBool, GetStdFunctionReturnType("regexMatch"))
+	assert.Equal(t, TypeString, GetStdFunctionReturnType("regexReplace"))
+	assert.Equal(t, TypeUnknown, GetStdFunctionReturnType("regexFind"))
+	assert.Equal(t, TypeUnknown, GetStdFunctionReturnType("regexFindAll"))
+	assert.Equal(t, TypeBool, must(Compile(`regexMatch("a", b)`)).Type())
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+var stdFunctionNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z\d_]*(?:\.[a-zA-Z_][a-zA-Z\d_]*)*$`)
+
+// customStdFunctions are registered by the integrations, they take precedence over the built-in stdFunctions,
+// which are never modified, so they may be read without the lock
+var (
+	customStdFunctionsMu sync.RWMutex
+	customStdFunctions   = map[string]StdFunction{}
+)
+
+type registerOptions struct {
+	override bool
+}
+
+type RegisterOption func(*registerOptions)
+
+// WithOverride allows the registered function to replace the built-in or previously registered one with the same name
+func WithOverride() RegisterOption {
+	return func(o *registerOptions) {
+		o.override = true
+	}
+}
+
+// RegisterStdFunction adds the function to the standard library for all resolutions, i.e. at the plugin initialization;
+// the name that is already taken is rejected, unless WithOverride option is passed;
+// the function has to declare its MinArgs and MaxArgs, the zero values accept no arguments
+func RegisterStdFunction(name string, fn StdFunction, opts ...RegisterOption) error {
+	options := registerOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if !stdFunctionNameRe.MatchString(name) || name == "null" || name == "true" || name == "false" {
+		return fmt.Errorf("invalid function name: %q", name)
+	}
+	if fn.Handler == nil && fn.FeaturesHandler == nil && fn.ClockHandler == nil && fn.MachinesHandler == nil && fn.RandomHandler == nil {
+		return fmt.Errorf("function %q has no handler", name)
+	}
+	if fn.MinArgs < 0 || (fn.MaxArgs != VariadicArgs && fn.MaxArgs < fn.MinArgs) {
+		return fmt.Errorf("function %q has invalid arity: minimum %d, maximum %d", name, fn.MinArgs, fn.MaxArgs)
+	}
+
+	customStdFunctionsMu.Lock()
+	defer customStdFunctionsMu.Unlock()
+	if !options.override {
+		if _, ok := stdFunctions[name]; ok {
+			return fmt.Errorf("function %q would shadow the built-in function", name)
+		}
+		if _, ok := customStdFunctions[name]; ok {
+			return fmt.Errorf("function %q is already registered", name)
+		}
+	}
+	customStdFunctions[name] = fn
+	invalidateStdFunctions()
+	return nil
+}
+
+// UnregisterStdFunction removes the registered function, the overridden built-in function is available again;
+// the built-in functions can't be removed
+func UnregisterStdFunction(name string) {
+	customStdFunctionsMu.Lock()
+	defer customStdFunctionsMu.Unlock()
+	if _, ok := customStdFunctions[name]; ok {
+		delete(customStdFunctions, name)
+		invalidateStdFunctions()
+	}
+}
+
+// invalidateStdFunctions drops the results of the previous function with the same name,
+// both memoized and folded in the compiled expressions
+func invalidateStdFunctions() {
+	if cache := functionCache.Load(); cache != nil {
+		cache.clear()
+	}
+	BumpCompileGeneration()
+}
+
+// getStdFunction finds the function by name, the registered ones take precedence over the built-in
+func getStdFunction(name string) (StdFunction, bool) {
+	customStdFunctionsMu.RLock()
+	fn, ok := customStdFunctions[name]
+	customStdFunctionsMu.RUnlock()
+	if ok {
+		return fn, true
+	}
+	fn, ok = stdFunctions[name]
+	return fn, ok
+}
+
+// stdFunctionNames returns the names of built-in and registered functions, sorted
+func stdFunctionNames() []string {
+	customStdFunctionsMu.RLock()
+	names := make([]string, 0, len(stdFunctions)+len(customStdFunctions))
+	for name := range customStdFunctions {
+		if _, ok := stdFunctions[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	customStdFunctionsMu.RUnlock()
+	for name := range stdFunctions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
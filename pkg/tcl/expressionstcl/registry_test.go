@@ -0,0 +1,117 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var hostnameFunction = StdFunction{
+	Description: "name of the runner host",
+	ReturnType:  TypeString,
+	Handler: func(value ...StaticValue) (Expression, error) {
+		return NewValue("runner-1"), nil
+	},
+}
+
+func TestRegisterStdFunction(t *testing.T) {
+	assert.NoError(t, RegisterStdFunction("hostname", hostnameFunction))
+	defer UnregisterStdFunction("hostname")
+
+	assert.True(t, IsStdFunction("hostname"))
+	assert.Equal(t, TypeString, GetStdFunctionReturnType("hostname"))
+
+	result, ok, err := StdLibMachine.Call("hostname")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, `"runner-1"`, result.String())
+
+	v, err := EvalExpression(`"host-" + hostname()`, StdLibMachine)
+	assert.NoError(t, err)
+	assert.Equal(t, "host-runner-1", must(v.StringValue()))
+
+	_, err = EvalExpression(`hostname(1)`, StdLibMachine)
+	assert.ErrorContains(t, err, `"hostname" expects 0 arguments, 1 provided`)
+
+	assert.Contains(t, DescribeLanguage(nil).Functions, FunctionDescription{Name: "hostname", ReturnType: TypeString})
+
+	UnregisterStdFunction("hostname")
+	assert.False(t, IsStdFunction("hostname"))
+	_, ok, _ = StdLibMachine.Call("hostname")
+	assert.False(t, ok)
+	assert.Equal(t, `hostname()`, MustCompile(`hostname()`).String())
+}
+
+func TestRegisterStdFunctionErrors(t *testing.T) {
+	assert.ErrorContains(t, RegisterStdFunction("len", hostnameFunction), `function "len" would shadow the built-in function`)
+	assert.ErrorContains(t, RegisterStdFunction("host-name", hostnameFunction), `invalid function name: "host-name"`)
+	assert.ErrorContains(t, RegisterStdFunction("null", hostnameFunction), `invalid function name: "null"`)
+	assert.ErrorContains(t, RegisterStdFunction("hostname", StdFunction{}), `function "hostname" has no handler`)
+	assert.ErrorContains(t, RegisterStdFunction("hostname", StdFunction{MinArgs: 2, MaxArgs: 1, Handler: hostnameFunction.Handler}),
+		`function "hostname" has invalid arity: minimum 2, maximum 1`)
+	assert.ErrorContains(t, RegisterStdFunction("hostname", StdFunction{MinArgs: -1, MaxArgs: VariadicArgs, Handler: hostnameFunction.Handler}),
+		`function "hostname" has invalid arity: minimum -1, maximum -1`)
+
+	assert.NoError(t, RegisterStdFunction("hostname", hostnameFunction))
+	defer UnregisterStdFunction("hostname")
+	assert.ErrorContains(t, RegisterStdFunction("hostname", hostnameFunction), `function "hostname" is already registered`)
+}
+
+func TestRegisterStdFunctionOverride(t *testing.T) {
+	cache := NewCompiledCache(0)
+	expr, err := cache.Get(`len("abc")`)
+	assert.NoError(t, err)
+	assert.Equal(t, `3`, expr.String())
+
+	err = RegisterStdFunction("len", StdFunction{
+		MinArgs:    1,
+		MaxArgs:    1,
+		ReturnType: TypeInt64,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			return NewValue(42), nil
+		},
+	}, WithOverride())
+	assert.NoError(t, err)
+	assert.Equal(t, `42`, MustCompile(`len("abc")`).String())
+	expr, err = cache.Get(`len("abc")`)
+	assert.NoError(t, err)
+	assert.Equal(t, `42`, expr.String())
+
+	// The built-in function is available again
+	UnregisterStdFunction("len")
+	assert.Equal(t, `3`, MustCompile(`len("abc")`).String())
+	UnregisterStdFunction("len")
+	assert.True(t, IsStdFunction("len"))
+}
+
+func TestRegisterStdFunctionConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		name := fmt.Sprintf("custom%d", i)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, RegisterStdFunction(name, hostnameFunction))
+			UnregisterStdFunction(name)
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := EvalExpression(`len("abc") + len(hostname)`, NewMachine().Register("hostname", "x"))
+				assert.NoError(t, err)
+				IsStdFunction(name)
+			}
+		}()
+	}
+	wg.Wait()
+}
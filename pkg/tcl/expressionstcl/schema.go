@@ -0,0 +1,293 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"encoding/json"
+	"fmt"
+	math2 "math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// schemaKeywords is the supported subset of JSON Schema draft 7, the annotations don't affect the validation
+var schemaKeywords = map[string]struct{}{
+	"type": {}, "properties": {}, "required": {}, "items": {}, "enum": {}, "minimum": {}, "maximum": {}, "pattern": {},
+	"$schema": {}, "title": {}, "description": {},
+}
+
+var schemaTypes = map[string]struct{}{
+	"object": {}, "array": {}, "string": {}, "number": {}, "integer": {}, "boolean": {}, "null": {},
+}
+
+// schemaPointer appends the token to JSON pointer, escaped as in RFC 6901
+func schemaPointer(pointer, token string) string {
+	return pointer + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func schemaLocation(pointer string) string {
+	return "#" + pointer
+}
+
+// parseSchema reads the schema provided as a map, or as JSON string
+func parseSchema(value StaticValue) (map[string]interface{}, error) {
+	if value.IsString() {
+		str, _ := value.StringValue()
+		var schema map[string]interface{}
+		if err := json.Unmarshal([]byte(str), &schema); err != nil {
+			return nil, fmt.Errorf("invalid schema: %v", err)
+		}
+		return schema, nil
+	}
+	if !value.IsMap() {
+		return nil, fmt.Errorf("schema should be a map or JSON string, %s provided", value.String())
+	}
+	return value.MapValue()
+}
+
+// schemaValidator collects the violations of the value, or fails when the schema itself is invalid
+type schemaValidator struct {
+	violations []string
+}
+
+func (v *schemaValidator) violation(pointer, format string, args ...interface{}) {
+	v.violations = append(v.violations, schemaLocation(pointer)+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *schemaValidator) validate(value interface{}, schema map[string]interface{}, pointer, schemaPointerPath string) error {
+	keywords := make([]string, 0, len(schema))
+	for keyword := range schema {
+		if _, ok := schemaKeywords[keyword]; !ok {
+			return fmt.Errorf("invalid schema: unsupported keyword %q at %s", keyword, schemaLocation(schemaPointerPath))
+		}
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		rule := schema[keyword]
+		location := schemaPointer(schemaPointerPath, keyword)
+		var err error
+		switch keyword {
+		case "type":
+			err = v.validateType(value, rule, pointer, location)
+		case "properties":
+			err = v.validateProperties(value, rule, pointer, location)
+		case "required":
+			err = v.validateRequired(value, rule, pointer, location)
+		case "items":
+			err = v.validateItems(value, rule, pointer, location)
+		case "enum":
+			err = v.validateEnum(value, rule, pointer, location)
+		case "minimum", "maximum":
+			err = v.validateRange(value, rule, keyword, pointer, location)
+		case "pattern":
+			err = v.validatePattern(value, rule, pointer, location)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func schemaTypeOf(value interface{}) string {
+	switch {
+	case isNone(value) || value == nil:
+		return "null"
+	case isMap(value) || isStruct(value):
+		return "object"
+	case isSlice(value):
+		return "array"
+	case isString(value):
+		return "string"
+	case isBool(value):
+		return "boolean"
+	case isInt(value):
+		return "integer"
+	case isNumber(value):
+		f, _ := toFloat(value)
+		if f == math2.Trunc(f) && !math2.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func (v *schemaValidator) validateType(value, rule interface{}, pointer, location string) error {
+	var types []string
+	if str, ok := rule.(string); ok {
+		types = []string{str}
+	} else if list, ok := rule.([]interface{}); ok {
+		for _, item := range list {
+			str, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("invalid schema: type should be a string or list of strings at %s", schemaLocation(location))
+			}
+			types = append(types, str)
+		}
+	} else {
+		return fmt.Errorf("invalid schema: type should be a string or list of strings at %s", schemaLocation(location))
+	}
+
+	actual := schemaTypeOf(value)
+	for _, t := range types {
+		if _, ok := schemaTypes[t]; !ok {
+			return fmt.Errorf("invalid schema: unknown type %q at %s", t, schemaLocation(location))
+		}
+		if t == actual || (t == "number" && actual == "integer") {
+			return nil
+		}
+	}
+	v.violation(pointer, "expected %s, got %s", strings.Join(types, " or "), actual)
+	return nil
+}
+
+func (v *schemaValidator) validateProperties(value, rule interface{}, pointer, location string) error {
+	properties, ok := rule.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid schema: properties should be a map at %s", schemaLocation(location))
+	}
+	object, isObject := schemaObject(value)
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema, ok := properties[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid schema: property schema should be a map at %s", schemaLocation(schemaPointer(location, name)))
+		}
+		item, exists := object[name]
+		if !isObject || !exists {
+			// Still validate the schema itself, so it fails regardless of the value
+			item = nil
+		}
+		nested := &schemaValidator{}
+		if err := nested.validate(item, schema, schemaPointer(pointer, name), schemaPointer(location, name)); err != nil {
+			return err
+		}
+		if isObject && exists {
+			v.violations = append(v.violations, nested.violations...)
+		}
+	}
+	return nil
+}
+
+func (v *schemaValidator) validateRequired(value, rule interface{}, pointer, location string) error {
+	list, ok := rule.([]interface{})
+	if !ok {
+		return fmt.Errorf("invalid schema: required should be a list of strings at %s", schemaLocation(location))
+	}
+	object, isObject := schemaObject(value)
+	for _, item := range list {
+		name, ok := item.(string)
+		if !ok {
+			return fmt.Errorf("invalid schema: required should be a list of strings at %s", schemaLocation(location))
+		}
+		if _, exists := object[name]; isObject && !exists {
+			v.violation(schemaPointer(pointer, name), "required property is missing")
+		}
+	}
+	return nil
+}
+
+func (v *schemaValidator) validateItems(value, rule interface{}, pointer, location string) error {
+	schema, ok := rule.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid schema: items should be a map at %s, the list form is not supported", schemaLocation(location))
+	}
+	if !isSlice(value) {
+		// Still validate the schema itself, so it fails regardless of the value
+		return (&schemaValidator{}).validate(nil, schema, pointer, location)
+	}
+	list, _ := toSlice(value)
+	for i, item := range list {
+		if err := v.validate(item, schema, schemaPointer(pointer, fmt.Sprintf("%d", i)), location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *schemaValidator) validateEnum(value, rule interface{}, pointer, location string) error {
+	list, ok := rule.([]interface{})
+	if !ok {
+		return fmt.Errorf("invalid schema: enum should be a list at %s", schemaLocation(location))
+	}
+	actual, err := json.Marshal(value)
+	if err != nil {
+		v.violation(pointer, "value is not one of the enum values")
+		return nil
+	}
+	for _, item := range list {
+		if expected, _ := json.Marshal(item); string(expected) == string(actual) {
+			return nil
+		}
+	}
+	options, _ := json.Marshal(list)
+	v.violation(pointer, "%s is not one of %s", actual, options)
+	return nil
+}
+
+func (v *schemaValidator) validateRange(value, rule interface{}, keyword, pointer, location string) error {
+	if !isNumber(rule) {
+		return fmt.Errorf("invalid schema: %s should be a number at %s", keyword, schemaLocation(location))
+	}
+	if !isNumber(value) {
+		return nil
+	}
+	c, ok := compareNumbers(value, rule)
+	if !ok {
+		return nil
+	}
+	if keyword == "minimum" && c < 0 {
+		v.violation(pointer, "%v is less than minimum %v", value, rule)
+	} else if keyword == "maximum" && c > 0 {
+		v.violation(pointer, "%v is greater than maximum %v", value, rule)
+	}
+	return nil
+}
+
+func (v *schemaValidator) validatePattern(value, rule interface{}, pointer, location string) error {
+	pattern, ok := rule.(string)
+	if !ok {
+		return fmt.Errorf("invalid schema: pattern should be a string at %s", schemaLocation(location))
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid schema: pattern at %s: %v", schemaLocation(location), err)
+	}
+	if str, ok := value.(string); ok && !re.MatchString(str) {
+		v.violation(pointer, "%q doesn't match pattern %q", str, pattern)
+	}
+	return nil
+}
+
+func schemaObject(value interface{}) (map[string]interface{}, bool) {
+	if !isMap(value) && !isStruct(value) {
+		return nil, false
+	}
+	object, err := toMap(value)
+	return object, err == nil
+}
+
+// validateSchema returns the violations of the value sorted by location, the error is returned only for invalid schema
+func validateSchema(value interface{}, schema map[string]interface{}) ([]string, error) {
+	v := &schemaValidator{}
+	if err := v.validate(value, schema, "", ""); err != nil {
+		return nil, err
+	}
+	sort.Strings(v.violations)
+	return v.violations, nil
+}
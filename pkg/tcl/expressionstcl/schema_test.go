@@ -0,0 +1,126 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfigSchema = `{
+	"type": "object",
+	"required": ["name", "server"],
+	"properties": {
+		"name": {"type": "string", "pattern": "^[a-z][a-z0-9-]*$"},
+		"mode": {"enum": ["fast", "full"]},
+		"server": {
+			"type": "object",
+			"required": ["host", "port"],
+			"properties": {
+				"host": {"type": "string"},
+				"port": {"type": "integer", "minimum": 1, "maximum": 65535}
+			}
+		},
+		"tags": {"type": "array", "items": {"type": "string"}}
+	}
+}`
+
+func TestValidateSchema(t *testing.T) {
+	tests := map[string][]string{
+		`name: api
+server: {host: localhost, port: 8080}
+tags: [a, b]`: nil,
+		`name: api
+server: {host: localhost}`: {`#/server/port: required property is missing`},
+		`name: Api_1
+mode: slow
+server: {host: 1, port: 70000}
+tags: [a, 2]`: {
+			`#/mode: "slow" is not one of ["fast","full"]`,
+			`#/name: "Api_1" doesn't match pattern "^[a-z][a-z0-9-]*$"`,
+			`#/server/host: expected string, got integer`,
+			`#/server/port: 70000 is greater than maximum 65535`,
+			`#/tags/1: expected string, got integer`,
+		},
+		`[]`:           {`#: expected object, got array`},
+		`{server: {}}`: {`#/name: required property is missing`, `#/server/host: required property is missing`, `#/server/port: required property is missing`},
+		`{name: a, server: {host: h, port: 1.5}}`: {`#/server/port: expected integer, got number`},
+	}
+	schema, err := parseSchema(NewValue(testConfigSchema))
+	assert.NoError(t, err)
+	for config, expected := range tests {
+		value, err := EvalString(`yaml(config)`, map[string]interface{}{"config": config})
+		assert.NoError(t, err, config)
+		violations, err := validateSchema(value, schema)
+		assert.NoError(t, err, config)
+		assert.Equal(t, expected, violations, config)
+	}
+}
+
+func TestValidateSchemaPointerEscaping(t *testing.T) {
+	schema := map[string]interface{}{"properties": map[string]interface{}{"a/b~c": map[string]interface{}{"type": "string"}}}
+	violations, err := validateSchema(map[string]interface{}{"a/b~c": 1}, schema)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`#/a~1b~0c: expected string, got integer`}, violations)
+}
+
+func TestValidateSchemaInvalid(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "object", "additionalProperties": false}`: `invalid schema: unsupported keyword "additionalProperties" at #`,
+		`{"properties": {"a": {"oneOf": []}}}`:              `invalid schema: unsupported keyword "oneOf" at #/properties/a`,
+		`{"items": {"type": "strings"}}`:                    `invalid schema: unknown type "strings" at #/items/type`,
+		`{"items": [{"type": "string"}]}`:                   `the list form is not supported`,
+		`{"pattern": "("}`:                                  `invalid schema: pattern at #/pattern`,
+		`{"minimum": "1"}`:                                  `invalid schema: minimum should be a number at #/minimum`,
+		`{"required": "name"}`:                              `invalid schema: required should be a list of strings at #/required`,
+	}
+	for source, message := range tests {
+		schema, err := parseSchema(NewValue(source))
+		assert.NoError(t, err, source)
+		// the schema is invalid regardless of the value
+		for _, value := range []interface{}{1, map[string]interface{}{}} {
+			_, err = validateSchema(value, schema)
+			assert.ErrorContains(t, err, message, source)
+		}
+	}
+
+	_, err := parseSchema(NewValue(`{`))
+	assert.ErrorContains(t, err, "invalid schema")
+}
+
+func TestSchemaStdFunctions(t *testing.T) {
+	vars := map[string]interface{}{
+		"config": "name: api\nserver: {host: localhost, port: 8080}\n",
+		"broken": "name: api\nserver: {host: localhost, port: 0}\n",
+		"schema": testConfigSchema,
+	}
+
+	v, err := EvalString(`validateSchema(yaml(config), schema)`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, noneValue, v)
+	v, err = EvalString(`matchesSchema(yaml(config), schema) && !matchesSchema(yaml(broken), schema)`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	_, err = EvalString(`validateSchema(yaml(broken), schema)`, vars)
+	assert.ErrorContains(t, err, `"validateSchema" function: value doesn't match the schema: #/server/port: 0 is less than minimum 1`)
+
+	// the schema can be a map too
+	v, err = EvalString(`matchesSchema(5, {"type": "integer", "maximum": 3})`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, false, v)
+
+	_, err = EvalString(`matchesSchema(1, {"type": "integer", "format": "int32"})`, vars)
+	assert.ErrorContains(t, err, `"matchesSchema" function: invalid schema: unsupported keyword "format" at #`)
+	_, err = EvalString(`matchesSchema(1, 2)`, vars)
+	assert.ErrorContains(t, err, `"matchesSchema" function: schema should be a map or JSON string, 2 provided`)
+	_, err = EvalString(`validateSchema(1)`, vars)
+	assert.ErrorContains(t, err, `"validateSchema" expects 2 arguments, 1 provided`)
+}
@@ -0,0 +1,339 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const redactedValue = "***"
+
+type secretMachine struct {
+	machine Machine
+}
+
+// NewSecretMachine wraps the machine, so the values it provides resolve as usual,
+// but they are replaced with "***" in error messages and in String() of partially resolved expressions
+// of the same resolution, i.e. expr.Resolve(NewSecretMachine(m))
+func NewSecretMachine(machine Machine) Machine {
+	return &secretMachine{machine: machine}
+}
+
+func (s *secretMachine) Get(name string) (Expression, bool, error) {
+	return s.machine.Get(name)
+}
+
+func (s *secretMachine) Call(name string, args ...StaticValue) (Expression, bool, error) {
+	return s.machine.Call(name, args...)
+}
+
+func (s *secretMachine) CallContext(ctx context.Context, name string, args ...StaticValue) (Expression, bool, error) {
+	return AsContextCaller(s.machine).CallContext(ctx, name, args...)
+}
+
+// redactions is a set of secret values resolved within a single resolution
+type redactions struct {
+	mu       sync.Mutex
+	values   map[string]struct{}
+	replacer *strings.Replacer
+}
+
+func (r *redactions) add(value interface{}) {
+	if isMap(value) || isStruct(value) {
+		v, _ := toMap(value)
+		for _, item := range v {
+			r.add(item)
+		}
+		return
+	}
+	if isSlice(value) {
+		v, _ := toSlice(value)
+		for _, item := range v {
+			r.add(item)
+		}
+		return
+	}
+	str, err := toString(value)
+	if err != nil || str == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.values[str]; !ok {
+		r.values[str] = struct{}{}
+		r.replacer = nil
+	}
+}
+
+func (r *redactions) redact(str string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.values) == 0 {
+		return str
+	}
+	if r.replacer == nil {
+		// Replace the longest values first, so the secret containing another one is not partially revealed
+		values := make([]string, 0, len(r.values))
+		for v := range r.values {
+			values = append(values, v)
+		}
+		sort.Slice(values, func(i, j int) bool {
+			return len(values[i]) > len(values[j]) || (len(values[i]) == len(values[j]) && values[i] < values[j])
+		})
+		pairs := make([]string, 0, 2*len(values))
+		for _, v := range values {
+			pairs = append(pairs, v, redactedValue)
+		}
+		r.replacer = strings.NewReplacer(pairs...)
+	}
+	return r.replacer.Replace(str)
+}
+
+// secretRecorder replaces the secret machine for the resolution, to record the values it provides
+type secretRecorder struct {
+	machine    Machine
+	redactions *redactions
+}
+
+func (s *secretRecorder) record(expr Expression, ok bool, err error) (Expression, bool, error) {
+	if ok && err == nil && expr != nil && expr.Static() != nil {
+		s.redactions.add(expr.Static().Value())
+	}
+	return expr, ok, err
+}
+
+func (s *secretRecorder) Get(name string) (Expression, bool, error) {
+	return s.record(s.machine.Get(name))
+}
+
+func (s *secretRecorder) Call(name string, args ...StaticValue) (Expression, bool, error) {
+	return s.record(s.machine.Call(name, args...))
+}
+
+func (s *secretRecorder) CallContext(ctx context.Context, name string, args ...StaticValue) (Expression, bool, error) {
+	return s.record(AsContextCaller(s.machine).CallContext(ctx, name, args...))
+}
+
+// withRedactions starts recording the secrets for the resolution, the redactions are nil when there are no secret machines,
+// or when it's a nested resolution, as the outer one is redacting the result already
+func withRedactions(m []Machine) ([]Machine, *redactions) {
+	var r *redactions
+	for i := range m {
+		if _, ok := m[i].(*secretRecorder); ok {
+			return m, nil
+		}
+		if _, ok := m[i].(*secretMachine); ok && r == nil {
+			r = &redactions{values: make(map[string]struct{})}
+		}
+	}
+	if r == nil {
+		return m, nil
+	}
+	result := make([]Machine, len(m))
+	for i := range m {
+		if s, ok := m[i].(*secretMachine); ok {
+			result[i] = &secretRecorder{machine: s.machine, redactions: r}
+		} else {
+			result[i] = m[i]
+		}
+	}
+	return result, r
+}
+
+// redactedError hides the secrets in the error message
+type redactedError struct {
+	err        error
+	redactions *redactions
+}
+
+func (e *redactedError) Error() string {
+	return e.redactions.redact(e.err.Error())
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactedExpression hides the secrets in the partially resolved expression
+type redactedExpression struct {
+	Expression
+	redactions *redactions
+}
+
+func (e *redactedExpression) String() string {
+	return e.redactions.redact(e.Expression.String())
+}
+
+func (e *redactedExpression) SafeString() string {
+	return e.redactions.redact(e.Expression.SafeString())
+}
+
+func (e *redactedExpression) SafeResolve(m ...Machine) (Expression, bool, error) {
+	expr, changed, err := e.Expression.SafeResolve(m...)
+	return redactResult(expr, e.redactions), changed, redactErr(err, e.redactions)
+}
+
+func (e *redactedExpression) Resolve(m ...Machine) (Expression, error) {
+	expr, err := e.Expression.Resolve(m...)
+	return redactResult(expr, e.redactions), redactErr(err, e.redactions)
+}
+
+// union combines the secrets of both resolutions, e.g. when partially resolved expression is resolved with another secrets
+func (r *redactions) union(other *redactions) *redactions {
+	if r == other {
+		return r
+	}
+	result := &redactions{values: make(map[string]struct{})}
+	for _, source := range []*redactions{r, other} {
+		source.mu.Lock()
+		for v := range source.values {
+			result.values[v] = struct{}{}
+		}
+		source.mu.Unlock()
+	}
+	return result
+}
+
+func redactErr(err error, r *redactions) error {
+	if err == nil || r == nil {
+		return err
+	}
+	if e, ok := err.(*redactedError); ok {
+		return &redactedError{err: e.err, redactions: e.redactions.union(r)}
+	}
+	return &redactedError{err: err, redactions: r}
+}
+
+// redactResult wraps the partially resolved expression, while static values are kept as they are
+func redactResult(expr Expression, r *redactions) Expression {
+	if expr == nil || r == nil || expr.Static() != nil {
+		return expr
+	}
+	if e, ok := expr.(*redactedExpression); ok {
+		return &redactedExpression{Expression: e.Expression, redactions: e.redactions.union(r)}
+	}
+	return &redactedExpression{Expression: expr, redactions: r}
+}
+
+// secretValue is the static value hiding its content when stringified, see NewSecretValue
+type secretValue struct {
+	StaticValue
+}
+
+// NewSecretValue builds the sensitive value, it resolves and casts as usual, but it's "***" in String(),
+// in the error messages, and in "tojson" and "toyaml" output, unless ResolveSecrets option is passed;
+// the results of the functions called with it, and of the concatenation with it, are secret too
+func NewSecretValue(value interface{}) StaticValue {
+	if v, ok := value.(StaticValue); ok {
+		return taintSecret(v)
+	}
+	return taintSecret(NewValue(value))
+}
+
+// taintSecret marks the value as secret, none has nothing to hide, so it's kept
+func taintSecret(v StaticValue) StaticValue {
+	if v == nil || v.IsNone() || isSecretValue(v) {
+		return v
+	}
+	return &secretValue{StaticValue: v}
+}
+
+func isSecretValue(v Expression) bool {
+	_, ok := v.(*secretValue)
+	return ok
+}
+
+func (s *secretValue) String() string {
+	return `"` + redactedValue + `"`
+}
+
+func (s *secretValue) SafeString() string {
+	return s.String()
+}
+
+func (s *secretValue) SafeResolve(_ ...Machine) (Expression, bool, error) {
+	return s, false, nil
+}
+
+func (s *secretValue) Resolve(_ ...Machine) (Expression, error) {
+	return s, nil
+}
+
+func (s *secretValue) ResolveContext(_ context.Context, _ ...Machine) (Expression, error) {
+	return s, nil
+}
+
+func (s *secretValue) Static() StaticValue {
+	return s
+}
+
+// secretRedactions collects the secret values, it's nil when there are none
+func secretRedactions(values ...StaticValue) *redactions {
+	var r *redactions
+	for _, v := range values {
+		if !isSecretValue(v) {
+			continue
+		}
+		if r == nil {
+			r = &redactions{values: make(map[string]struct{})}
+		}
+		r.add(v.Value())
+	}
+	return r
+}
+
+// taintResult marks the result of the function called with the secret values as secret,
+// and hides these values in its error
+func taintResult(args []StaticValue, result Expression, err error) (Expression, error) {
+	r := secretRedactions(args...)
+	if r == nil {
+		return result, err
+	}
+	if err != nil {
+		return result, redactErr(err, r)
+	}
+	if result != nil && result.Static() != nil {
+		return taintSecret(result.Static()), nil
+	}
+	return result, nil
+}
+
+type resolveSecretsMachine struct{}
+
+// ResolveSecretsMachine passes ResolveSecrets option to the single resolution, i.e. expr.Resolve(m, ResolveSecretsMachine)
+var ResolveSecretsMachine Machine = resolveSecretsMachine{}
+
+func (resolveSecretsMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (resolveSecretsMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func isResolveSecrets(m []Machine) bool {
+	for i := range m {
+		if _, ok := m[i].(resolveSecretsMachine); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// revealValue returns the value to serialize, the secret value is "***", unless ResolveSecrets option is passed
+func revealValue(m []Machine, v StaticValue) interface{} {
+	if isSecretValue(v) && !isResolveSecrets(m) {
+		return redactedValue
+	}
+	return v.Value()
+}
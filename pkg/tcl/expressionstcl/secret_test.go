@@ -0,0 +1,177 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "s3cr3t-t0ken"
+
+func TestSecretMachineRedactsErrors(t *testing.T) {
+	secrets := NewSecretMachine(NewMachine().Register("token", testSecret))
+
+	_, err := MustCompile(`"Bearer " + urlbuild(token)`).Resolve(secrets)
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), testSecret)
+	assert.Contains(t, err.Error(), `"urlbuild" function expects a map of URL components, "***" provided`)
+
+	_, err = EvalString(`semverCompare("v" + token, "1.0.0")`, nil, WithMachines(secrets))
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), testSecret)
+}
+
+func TestSecretMachineKeepsResolvedValue(t *testing.T) {
+	secrets := NewSecretMachine(NewMachine().Register("token", testSecret))
+
+	expr, err := MustCompile(`"Bearer " + token`).Resolve(secrets)
+	assert.NoError(t, err)
+	assert.Equal(t, `"Bearer `+testSecret+`"`, expr.String())
+
+	v, err := EvalString(`"Bearer " + token`, nil, WithMachines(secrets))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer "+testSecret, v)
+}
+
+func TestSecretMachineRedactsPartialResult(t *testing.T) {
+	secrets := NewSecretMachine(NewMachine().Register("token", testSecret))
+
+	expr, err := MustCompile(`token + "@" + host`).Resolve(secrets)
+	assert.NoError(t, err)
+	assert.Equal(t, `"***@"+host`, expr.String())
+
+	expr, err = expr.Resolve(NewMachine().Register("host", "example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, `"`+testSecret+`@example.com"`, expr.String())
+
+	_, err = MustCompile(`token + "@" + host + missing`).Resolve(secrets, FinalizerFail)
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), testSecret)
+}
+
+func TestSecretMachineNestedValues(t *testing.T) {
+	secrets := NewSecretMachine(NewMachine().Register("creds", map[string]interface{}{
+		"user":     "admin-user",
+		"password": "hunter22",
+		"keys":     []interface{}{"key-1", "key-1-extended"},
+	}))
+
+	_, err := MustCompile(`urlbuild(creds.password + "/" + creds.keys.1 + "/" + creds.keys.0)`).Resolve(secrets)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `"***/***/***"`)
+}
+
+func TestSecretMachineScopedToResolution(t *testing.T) {
+	secrets := NewSecretMachine(NewMachine().Register("token", testSecret))
+	_, err := MustCompile(`urlbuild(token)`).Resolve(secrets)
+	assert.NotContains(t, err.Error(), testSecret)
+
+	_, err = MustCompile(`urlbuild(token)`).Resolve(NewMachine().Register("token", testSecret))
+	assert.Contains(t, err.Error(), testSecret)
+}
+
+func TestSecretValueString(t *testing.T) {
+	v := NewSecretValue(testSecret)
+	assert.Equal(t, `"***"`, v.String())
+	assert.Equal(t, `"***"`, v.SafeString())
+	assert.Equal(t, testSecret, v.Value())
+	assert.True(t, v.IsString())
+	assert.Equal(t, v, v.Static())
+	assert.Equal(t, None, NewSecretValue(None))
+	assert.Equal(t, v, NewSecretValue(v))
+}
+
+func TestSecretValueResolves(t *testing.T) {
+	m := NewMachine().Register("token", NewSecretValue(testSecret)).Register("port", NewSecretValue("8080"))
+
+	v, err := EvalString(`"Bearer " + token`, nil, WithMachines(m))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer "+testSecret, v)
+
+	v, err = EvalString(`int(port) + 1`, nil, WithMachines(m))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8081), v)
+
+	str, err := EvalTemplateString(`Bearer {{token}}`, nil, WithMachines(m))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer "+testSecret, str)
+}
+
+func TestSecretValueTaintsConcatenation(t *testing.T) {
+	m := NewMachine().Register("token", NewSecretValue(testSecret))
+
+	expr, err := MustCompile(`"Bearer " + token`).Resolve(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `"***"`, expr.String())
+	assert.Equal(t, "Bearer "+testSecret, expr.Static().Value())
+
+	expr, err = MustCompile(`string("Bearer ", token)`).Resolve(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `"***"`, expr.String())
+
+	expr, err = MustCompile(`"Bearer " + token + unknown`).Resolve(m)
+	assert.NoError(t, err)
+	assert.NotContains(t, expr.String(), testSecret)
+
+	expr, err = MustCompile(`len(token) > 3`).Resolve(m)
+	assert.NoError(t, err)
+	assert.Equal(t, "true", expr.String())
+}
+
+func TestSecretValueCastError(t *testing.T) {
+	m := NewMachine().Register("token", NewSecretValue(testSecret))
+
+	_, err := EvalString(`int(token)`, nil, WithMachines(m))
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), testSecret)
+	assert.Contains(t, err.Error(), `int(token): error while converting value to number: ***`)
+
+	_, err = EvalString(`token * 2`, nil, WithMachines(m))
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), testSecret)
+
+	_, err = EvalString(`fail(token)`, nil, WithMachines(m, NewMachine().RegisterFunction("fail", func(values ...StaticValue) (interface{}, bool, error) {
+		return nil, true, fmt.Errorf("invalid value: %v", values[0].Value())
+	})))
+	assert.EqualError(t, err, `resolving: fail(token): invalid value: ***`)
+}
+
+func TestSecretValueSerialization(t *testing.T) {
+	m := NewMachine().Register("token", NewSecretValue(testSecret))
+
+	v, err := EvalString(`tojson(token)`, nil, WithMachines(m))
+	assert.NoError(t, err)
+	assert.Equal(t, `"***"`, v)
+
+	v, err = EvalString(`toyaml(token)`, nil, WithMachines(m))
+	assert.NoError(t, err)
+	assert.NotContains(t, v, testSecret)
+
+	v, err = EvalString(`tojson(token)`, nil, WithMachines(m), ResolveSecrets())
+	assert.NoError(t, err)
+	assert.Equal(t, `"`+testSecret+`"`, v)
+
+	expr, err := MustCompile(`toyaml(token)`).Resolve(m, ResolveSecretsMachine)
+	assert.NoError(t, err)
+	assert.Equal(t, testSecret+"\n", expr.Static().Value())
+	assert.Equal(t, `"***"`, expr.String())
+}
+
+func TestSecretValueNotCached(t *testing.T) {
+	cache := NewFunctionCache(FunctionCacheOptions{MaxEntries: 10})
+	SetFunctionCache(cache)
+	t.Cleanup(func() { SetFunctionCache(nil) })
+
+	_, err := MustCompile(`tojson(token)`).Resolve(NewMachine().Register("token", NewSecretValue(testSecret)))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// semverRe is the SemVer 2.0.0 grammar, see https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string
+var semverRe = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// parseSemver parses semantic version strictly by the specification, tolerating the "v" prefix
+func parseSemver(str string) (*semver.Version, error) {
+	trimmed := str
+	if strings.HasPrefix(str, "v") || strings.HasPrefix(str, "V") {
+		trimmed = str[1:]
+	}
+	if !semverRe.MatchString(trimmed) {
+		return nil, fmt.Errorf("invalid version %q", str)
+	}
+	v, err := semver.StrictNewVersion(trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q: %v", str, err)
+	}
+	return v, nil
+}
+
+// parseSemverConstraint parses constraint, i.e. ">=1.2.0 <2.0.0 || ^3.0.0",
+// the comparators separated by spaces or commas must all match
+func parseSemverConstraint(str string) (*semver.Constraints, error) {
+	c, err := semver.NewConstraint(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint %q: %v", str, err)
+	}
+	return c, nil
+}
+
+func semverToMap(v *semver.Version) map[string]interface{} {
+	return map[string]interface{}{
+		"major":      v.Major(),
+		"minor":      v.Minor(),
+		"patch":      v.Patch(),
+		"prerelease": v.Prerelease(),
+		"build":      v.Metadata(),
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemverComparePrecedence(t *testing.T) {
+	// precedence examples from semver.org 2.0.0, section 11
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "2.0.0", "2.1.0", "2.1.1",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			expr := fmt.Sprintf(`semverCompare("%s", "%s")`, ordered[i], ordered[j])
+			assert.Equal(t, fmt.Sprint(expected), MustCompile(expr).String(), expr)
+		}
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	assert.Equal(t, `0`, MustCompile(`semverCompare("v1.0.0", "1.0.0")`).String())
+	assert.Equal(t, `0`, MustCompile(`semverCompare("1.0.0+build.1", "1.0.0+build.2")`).String())
+	assert.Equal(t, `1`, MustCompile(`semverCompare("1.10.0", "1.9.0")`).String())
+	assert.Equal(t, `1`, MustCompile(`semverCompare("1.0.0-rc.100", "1.0.0-rc.99")`).String())
+	assert.Equal(t, `-1`, MustCompile(`semverCompare("1.0.0-99", "1.0.0-a")`).String())
+	assert.Equal(t, `-1`, MustCompile(`semverCompare("1.0.0-Beta", "1.0.0-alpha")`).String())
+}
+
+func TestSemverSatisfies(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matching   []string
+		other      []string
+	}{
+		// prerelease versions satisfy only the comparators with prerelease
+		{">=1.2.0 <2.0.0", []string{"1.2.0", "1.9.9"}, []string{"1.1.9", "2.0.0", "2.0.0-rc.1"}},
+		{">= 1.2.0, < 2.0.0", []string{"1.2.0"}, []string{"2.0.0"}},
+		{"~1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0", "1.3.0-alpha", "1.1.9"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.10"}, []string{"1.2.2", "1.3.0"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0", "2.0.0-rc.1"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^1.2", []string{"1.2.0", "1.9.0"}, []string{"2.0.0"}},
+		{"1.2", []string{"1.2.0", "1.2.5"}, []string{"1.3.0"}},
+		{"1.2.3", []string{"1.2.3", "v1.2.3+build"}, []string{"1.2.4"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"!=1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{">=1.0.0-beta <1.0.0-rc.2", []string{"1.0.0-beta.2", "1.0.0-rc.1"}, []string{"1.0.0-alpha", "1.0.0"}},
+		{"^1.0.0 || ^3.0.0", []string{"1.5.0", "3.1.0"}, []string{"2.0.0"}},
+	}
+
+	for _, tt := range tests {
+		for _, version := range tt.matching {
+			assert.True(t, semverSatisfiesString(t, version, tt.constraint), "%s should satisfy %s", version, tt.constraint)
+		}
+		for _, version := range tt.other {
+			assert.False(t, semverSatisfiesString(t, version, tt.constraint), "%s should not satisfy %s", version, tt.constraint)
+		}
+	}
+}
+
+func semverSatisfiesString(t *testing.T, version, constraint string) bool {
+	v, err := parseSemver(version)
+	assert.NoError(t, err)
+	c, err := parseSemverConstraint(constraint)
+	assert.NoError(t, err)
+	return c.Check(v)
+}
+
+func TestSemverParse(t *testing.T) {
+	assert.Equal(t, `{"build":"","major":1,"minor":0,"patch":0,"prerelease":"alpha.1"}`, MustCompile(`semverParse("1.0.0-alpha.1")`).String())
+	assert.Equal(t, `{"build":"20130313144700","major":1,"minor":0,"patch":0,"prerelease":""}`, MustCompile(`semverParse("1.0.0+20130313144700")`).String())
+	assert.Equal(t, `{"build":"exp.sha.5114f85","major":1,"minor":0,"patch":0,"prerelease":"beta"}`, MustCompile(`semverParse("V1.0.0-beta+exp.sha.5114f85")`).String())
+}
+
+func TestSemverInvalid(t *testing.T) {
+	invalid := []string{"", "1", "1.2", "1.2.3.4", "01.2.3", "1.2.3-01", "1.2.3-", "1.2.3-alpha..1", "1.2.3+", "1.2.x", "latest", "1.2.3-beta_1"}
+	for _, version := range invalid {
+		_, err := parseSemver(version)
+		assert.ErrorContains(t, err, fmt.Sprintf("invalid version %q", version))
+	}
+
+	_, err := Compile(`semverCompare("1.2.3", "next")`)
+	assert.ErrorContains(t, err, `"semverCompare" function: invalid version "next"`)
+	_, err = Compile(`semverSatisfies("1.2.3", ">=1.2.0 <two")`)
+	assert.ErrorContains(t, err, `"semverSatisfies" function: invalid constraint ">=1.2.0 <two"`)
+	_, err = Compile(`semverParse("1.2")`)
+	assert.ErrorContains(t, err, `"semverParse" function: invalid version "1.2"`)
+}
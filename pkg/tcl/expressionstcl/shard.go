@@ -0,0 +1,72 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// shardOf assigns the key to the shard by 64-bit FNV-1a hash of the key modulo number of shards,
+// the assignment must never change, so the same key is always run by the same shard
+func shardOf(key string, total int64) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum64() % uint64(total))
+}
+
+// sampled decides whether the key is in the sample of the given rate, by mapping its hash uniformly to [0, 1),
+// so the same key always gets the same decision, and keys sampled with lower rate are sampled with higher rate too.
+// The high bits of FNV-1a are not distributed well for similar keys, so the hash is mixed with MurmurHash3 finalizer.
+func sampled(key string, rate float64) bool {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	k := h.Sum64()
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return float64(k>>11)/(1<<53) < rate
+}
+
+func validateSampleRate(rate float64) error {
+	if !(rate > 0 && rate <= 1) {
+		return fmt.Errorf("rate should be in (0, 1] range, %v provided", rate)
+	}
+	return nil
+}
+
+func validateShards(total int64) error {
+	if total < 1 {
+		return fmt.Errorf("total shards should be at least 1, %d provided", total)
+	}
+	return nil
+}
+
+// shardList returns the items assigned to the shard, items are hashed by their string representation
+func shardList(items []interface{}, total, index int64) ([]interface{}, error) {
+	if err := validateShards(total); err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= total {
+		return nil, fmt.Errorf("shard index should be between 0 and %d, %d provided", total-1, index)
+	}
+	result := make([]interface{}, 0, len(items)/int(total)+1)
+	for _, item := range items {
+		key, err := toString(item)
+		if err != nil {
+			return nil, err
+		}
+		if shardOf(key, total) == index {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
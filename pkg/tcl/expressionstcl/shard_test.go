@@ -0,0 +1,105 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The assignment must never change between releases, otherwise tests move between shards
+func TestShardGolden(t *testing.T) {
+	tests := map[string][2]int64{
+		"login.spec.ts":     {2, 1},
+		"checkout.spec.ts":  {1, 1},
+		"search.spec.ts":    {1, 2},
+		"profile.spec.ts":   {2, 2},
+		"cart.spec.ts":      {3, 0},
+		"api/users_test.go": {3, 1},
+		"ünïcode":           {3, 2},
+		"":                  {1, 2},
+	}
+	for key, expected := range tests {
+		assert.Equal(t, fmt.Sprintf("%d", expected[0]), MustCompile(fmt.Sprintf(`shard("%s", 4)`, key)).String(), key)
+		assert.Equal(t, fmt.Sprintf("%d", expected[1]), MustCompile(fmt.Sprintf(`shard("%s", 3)`, key)).String(), key)
+		assert.Equal(t, "0", MustCompile(fmt.Sprintf(`shard("%s", 1)`, key)).String(), key)
+	}
+}
+
+func TestShardList(t *testing.T) {
+	list := `["login.spec.ts", "checkout.spec.ts", "search.spec.ts", "profile.spec.ts", "cart.spec.ts", "api/users_test.go"]`
+	assert.Equal(t, `[]`, MustCompile(`shardList(`+list+`, 4, 0)`).String())
+	assert.Equal(t, `["checkout.spec.ts","search.spec.ts"]`, MustCompile(`shardList(`+list+`, 4, 1)`).String())
+	assert.Equal(t, `["login.spec.ts","profile.spec.ts"]`, MustCompile(`shardList(`+list+`, 4, 2)`).String())
+	assert.Equal(t, `["cart.spec.ts","api/users_test.go"]`, MustCompile(`shardList(`+list+`, 4, 3)`).String())
+	assert.Equal(t, `[true]`, MustCompile(`shardList([1, true, "x"], 4, shard("true", 4))`).String())
+}
+
+func TestShardValidation(t *testing.T) {
+	_, err := Compile(`shard("a", 0)`)
+	assert.ErrorContains(t, err, "total shards should be at least 1, 0 provided")
+	_, err = Compile(`shardList(["a"], -1, 0)`)
+	assert.ErrorContains(t, err, "total shards should be at least 1, -1 provided")
+	_, err = Compile(`shardList(["a"], 3, 3)`)
+	assert.ErrorContains(t, err, "shard index should be between 0 and 2, 3 provided")
+	_, err = Compile(`shardList(["a"], 3, -1)`)
+	assert.ErrorContains(t, err, "shard index should be between 0 and 2, -1 provided")
+	_, err = Compile(`shardList("a", 3, 0)`)
+	assert.ErrorContains(t, err, "expects a list as 1st argument")
+}
+
+// The sampling must never change between releases, otherwise the retried commit could get a different decision
+func TestSampleRateGolden(t *testing.T) {
+	tests := map[string][2]bool{
+		"a1b2c3d": {false, false},
+		"e4f5a6b": {false, false},
+		"9f8e7d6": {true, true},
+		"0123456": {false, false},
+		"deadbee": {false, false},
+		"cafef00": {false, true},
+		"feedbac": {false, false},
+		"1234abc": {false, true},
+		"ünïcode": {true, true},
+	}
+	for key, expected := range tests {
+		assert.Equal(t, fmt.Sprintf("%v", expected[0]), MustCompile(fmt.Sprintf(`sampleRate("%s", 0.1)`, key)).String(), key)
+		assert.Equal(t, fmt.Sprintf("%v", expected[1]), MustCompile(fmt.Sprintf(`sampleRate("%s", 0.5)`, key)).String(), key)
+		assert.Equal(t, "true", MustCompile(fmt.Sprintf(`sampleRate("%s", 1)`, key)).String(), key)
+	}
+}
+
+func TestSampleRateDistribution(t *testing.T) {
+	for _, rate := range []float64{0.01, 0.1, 0.5} {
+		count := 0
+		for i := 0; i < 20000; i++ {
+			if sampled(fmt.Sprintf("commit-%d", i), rate) {
+				count++
+			}
+		}
+		assert.InDelta(t, rate, float64(count)/20000, 0.01, rate)
+	}
+}
+
+func TestSampleRateValidation(t *testing.T) {
+	for _, rate := range []string{"0", "-0.1", "1.01", "2"} {
+		_, err := Compile(`sampleRate("a", ` + rate + `)`)
+		assert.ErrorContains(t, err, "rate should be in (0, 1] range", rate)
+	}
+}
+
+func TestBucket(t *testing.T) {
+	assert.Equal(t, "7", MustCompile(`bucket("a1b2c3d", 10)`).String())
+	assert.Equal(t, "1", MustCompile(`bucket("ünïcode", 10)`).String())
+	assert.Equal(t, MustCompile(`shard("deadbee", 10)`).String(), MustCompile(`bucket("deadbee", 10)`).String())
+
+	_, err := Compile(`bucket("a", 0)`)
+	assert.ErrorContains(t, err, "number of buckets should be at least 1, 0 provided")
+}
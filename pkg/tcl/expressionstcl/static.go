@@ -9,14 +9,20 @@
 package expressionstcl
 
 import (
+	"context"
 	"encoding/json"
-	"strings"
+	"regexp"
+	"strconv"
 )
 
 type static struct {
 	value interface{}
+	// source is the accessor the value has been read from, to point to it in the errors
+	source string
 }
 
+var templateEscapeRe = regexp.MustCompile(`\\*\{\{`)
+
 var none *static
 var None StaticValue = none
 
@@ -27,6 +33,26 @@ func NewValue(value interface{}) StaticValue {
 	return &static{value: value}
 }
 
+// withSource marks the static value with the accessor it has been read from
+func withSource(expr Expression, name string) Expression {
+	if expr == nil || expr.Static() == nil {
+		return expr
+	}
+	v, ok := expr.Static().(*static)
+	if !ok {
+		return expr
+	}
+	return &static{value: v.Value(), source: name}
+}
+
+// staticSource returns the accessor the value has been read from, it's empty for other values
+func staticSource(v StaticValue) string {
+	if s, ok := v.(*static); ok && s != nil {
+		return s.source
+	}
+	return ""
+}
+
 func NewStringValue(value interface{}) StaticValue {
 	v, _ := toString(value)
 	return NewValue(v)
@@ -77,7 +103,10 @@ func (s *static) Template() string {
 		return ""
 	}
 	v, _ := s.StringValue()
-	return strings.ReplaceAll(v, "{{", "{{\"{{\"}}")
+	// The backslashes before "{{" would escape it, so they are moved into the expression too
+	return templateEscapeRe.ReplaceAllStringFunc(v, func(str string) string {
+		return "{{" + strconv.Quote(str) + "}}"
+	})
 }
 
 func (s *static) SafeResolve(_ ...Machine) (Expression, bool, error) {
@@ -88,12 +117,16 @@ func (s *static) Resolve(_ ...Machine) (Expression, error) {
 	return s, nil
 }
 
+func (s *static) ResolveContext(_ context.Context, _ ...Machine) (Expression, error) {
+	return s, nil
+}
+
 func (s *static) Static() StaticValue {
 	return s
 }
 
 func (s *static) IsNone() bool {
-	return s == nil
+	return s == nil || isNone(s.value)
 }
 
 func (s *static) IsString() bool {
@@ -224,9 +224,9 @@ func TestStaticSlice(t *testing.T) {
 	assert.Error(t, errOnly(NewValue([]string(nil)).FloatValue()))
 	assert.Error(t, errOnly(NewValue([]string{}).FloatValue()))
 	assert.Error(t, errOnly(NewValue([]string{"a", "b"}).FloatValue()))
-	assert.Equal(t, "", must(NewValue([]string(nil)).StringValue()))
-	assert.Equal(t, "", must(NewValue([]string{}).StringValue()))
-	assert.Equal(t, `a,b`, must(NewValue([]string{"a", "b"}).StringValue()))
+	assert.Equal(t, "[]", must(NewValue([]string(nil)).StringValue()))
+	assert.Equal(t, "[]", must(NewValue([]string{}).StringValue()))
+	assert.Equal(t, `["a","b"]`, must(NewValue([]string{"a", "b"}).StringValue()))
 	assert.Equal(t, map[string]interface{}{}, must(NewValue([]string(nil)).MapValue()))
 	assert.Equal(t, map[string]interface{}{}, must(NewValue([]string{}).MapValue()))
 	assert.Equal(t, map[string]interface{}{"0": "a", "1": "b"}, must(NewValue([]string{"a", "b"}).MapValue()))
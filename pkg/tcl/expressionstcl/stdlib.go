@@ -10,21 +10,125 @@ package expressionstcl
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	math2 "math"
+	"math/rand"
+	"path"
+	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/itchyny/gojq"
 	"github.com/kballard/go-shellquote"
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v3"
 )
 
+// VariadicArgs is the MaxArgs of the functions accepting any number of arguments
+const VariadicArgs = -1
+
 type StdFunction struct {
+	// Description is a short summary of the function for the documentation
+	Description string
+	// MinArgs and MaxArgs are the number of the arguments accepted by the function, MaxArgs is VariadicArgs when unbounded;
+	// the number of arguments is validated before calling the handler, so it's not repeated there
+	MinArgs int
+	MaxArgs int
+	// ArgTypes are the expected types of the arguments for the documentation, TypeUnknown accepts any value;
+	// the last one applies to the rest of variadic arguments, and the missing ones are TypeUnknown
+	ArgTypes   []Type
 	ReturnType Type
-	Handler    func(...StaticValue) (Expression, error)
+	// StrictArgs are the indexes of the arguments checked by the handler to be of the type declared in ArgTypes,
+	// instead of being converted, so CheckTypes reports the arguments known to be of other type
+	StrictArgs []int
+	// ListArgs are the indexes of the arguments checked by the handler to be lists, so CheckTypes reports the known scalars
+	ListArgs []int
+	// Pure functions depend only on their arguments, so their results may be memoized with the FunctionCache
+	Pure bool
+	// NonDeterministic functions may return different results for the same arguments and machines,
+	// i.e. the ones depending on the current time, random source or files, so they are not memoized even within a single pass
+	NonDeterministic bool
+	Handler          func(...StaticValue) (Expression, error)
+	// FeaturesHandler is used instead of Handler by the functions depending on the features
+	FeaturesHandler func(Features, ...StaticValue) (Expression, error)
+	// ClockHandler is used instead of Handler by the functions depending on the current time, see NewClockMachine
+	ClockHandler func(time.Time, ...StaticValue) (Expression, error)
+	// MachinesHandler is used instead of Handler by the functions depending on the machines of the resolution,
+	// i.e. resolving nested expressions, it returns errNotResolvedYet to leave the call for the further resolution
+	MachinesHandler func([]Machine, ...StaticValue) (Expression, error)
+	// RandomHandler is used instead of Handler by the functions generating random values,
+	// they are resolved only with the machine enabling them, see NewRandomMachine
+	RandomHandler func(*rand.Rand, ...StaticValue) (Expression, error)
+}
+
+// errNotResolvedYet is returned by the MachinesHandler when the nested expression can't be resolved with the current machines
+var errNotResolvedYet = errors.New("nested expression could not be resolved yet")
+
+// checkArgs validates the number of arguments against the arity declared by the function
+func (fn StdFunction) checkArgs(name string, count int) error {
+	if count >= fn.MinArgs && (fn.MaxArgs == VariadicArgs || count <= fn.MaxArgs) {
+		return nil
+	}
+	return fmt.Errorf(`"%s" expects %s, %d provided`, name, fn.arityText(), count)
+}
+
+func (fn StdFunction) arityText() string {
+	switch {
+	case fn.MaxArgs == VariadicArgs:
+		return "at least " + pluralArgs(fn.MinArgs)
+	case fn.MinArgs == fn.MaxArgs:
+		return pluralArgs(fn.MinArgs)
+	default:
+		return fmt.Sprintf("between %d and %d arguments", fn.MinArgs, fn.MaxArgs)
+	}
+}
+
+func pluralArgs(count int) string {
+	if count == 1 {
+		return "1 argument"
+	}
+	return fmt.Sprintf("%d arguments", count)
+}
+
+// argType returns the expected type of the argument at the index
+func (fn StdFunction) argType(index int) Type {
+	if index < len(fn.ArgTypes) {
+		return fn.ArgTypes[index]
+	}
+	if fn.MaxArgs == VariadicArgs && len(fn.ArgTypes) > 0 {
+		return fn.ArgTypes[len(fn.ArgTypes)-1]
+	}
+	return TypeUnknown
+}
+
+func (fn StdFunction) call(features Features, now func() time.Time, machines []Machine, value ...StaticValue) (Expression, error) {
+	result, err := fn.callHandler(features, now, machines, value...)
+	return taintResult(value, result, err)
+}
+
+func (fn StdFunction) callHandler(features Features, now func() time.Time, machines []Machine, value ...StaticValue) (Expression, error) {
+	if fn.MachinesHandler != nil {
+		return fn.MachinesHandler(machines, value...)
+	}
+	if fn.RandomHandler != nil {
+		r := resolveRandom(machines)
+		if r == nil {
+			return nil, errRandomNotEnabled
+		}
+		return r.generate(func(source *rand.Rand) (Expression, error) {
+			return fn.RandomHandler(source, value...)
+		})
+	}
+	if fn.FeaturesHandler != nil {
+		return fn.FeaturesHandler(features, value...)
+	}
+	if fn.ClockHandler != nil {
+		return fn.ClockHandler(now(), value...)
+	}
+	return fn.Handler(value...)
 }
 
 type stdMachine struct{}
@@ -33,17 +137,22 @@ var StdLibMachine = &stdMachine{}
 
 var stdFunctions = map[string]StdFunction{
 	"string": {
-		ReturnType: TypeString,
-		Handler: func(value ...StaticValue) (Expression, error) {
+		Description: "concatenation of the values converted to strings, none is an empty string",
+		MinArgs:     0,
+		MaxArgs:     VariadicArgs,
+		ReturnType:  TypeString,
+		FeaturesHandler: func(features Features, value ...StaticValue) (Expression, error) {
 			str := ""
 			for i := range value {
-				next, _ := value[i].StringValue()
-				str += next
+				str += castStaticToString(features, value[i])
 			}
 			return NewValue(str), nil
 		},
 	},
 	"list": {
+		Description: "list of the arguments",
+		MinArgs:     0,
+		MaxArgs:     VariadicArgs,
 		Handler: func(value ...StaticValue) (Expression, error) {
 			v := make([]interface{}, len(value))
 			for i := range value {
@@ -52,12 +161,86 @@ var stdFunctions = map[string]StdFunction{
 			return NewValue(v), nil
 		},
 	},
-	"join": {
-		ReturnType: TypeString,
+	"coalesceList": {
+		Description: "first non-empty list of the arguments, null ones are skipped",
+		MinArgs:     0,
+		MaxArgs:     VariadicArgs,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			var result StaticValue
+			for i := range value {
+				if value[i].IsNone() {
+					continue
+				}
+				if !value[i].IsSlice() {
+					return nil, fmt.Errorf(`"coalesceList" function expects lists or null, %s provided as argument %d`, value[i], i+1)
+				}
+				if result == nil {
+					if list, _ := value[i].SliceValue(); len(list) > 0 {
+						result = value[i]
+					}
+				}
+			}
+			if result == nil {
+				return None, nil
+			}
+			return result, nil
+		},
+	},
+	"coalesce": {
+		Description: "first argument that is neither null nor empty string",
+		MinArgs:     0,
+		MaxArgs:     VariadicArgs,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			for i := range value {
+				if !isNoneOrEmptyString(value[i]) {
+					return value[i], nil
+				}
+			}
+			return None, nil
+		},
+	},
+	"default": {
+		Description: "the value, or the default when the value is null or empty string",
+		MinArgs:     2,
+		MaxArgs:     2,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if isNoneOrEmptyString(value[0]) {
+				return value[1], nil
+			}
+			return value[0], nil
+		},
+	},
+	"empty": {
+		Description: "tells if the value is null, empty string, empty list or empty map",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Pure:        true,
+		ReturnType:  TypeBool,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) == 0 || len(value) > 2 {
-				return nil, fmt.Errorf(`"join" function expects 1-2 arguments, %d provided`, len(value))
+			if isNoneOrEmptyString(value[0]) {
+				return NewValue(true), nil
+			}
+			if value[0].IsSlice() {
+				list, _ := value[0].SliceValue()
+				return NewValue(len(list) == 0), nil
 			}
+			if value[0].IsMap() {
+				v, _ := value[0].MapValue()
+				return NewValue(len(v) == 0), nil
+			}
+			return NewValue(false), nil
+		},
+	},
+	"join": {
+		Description: `joins the list items converted to strings with the separator, "," by default`,
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeUnknown, TypeString},
+		ListArgs:    []int{0},
+		ReturnType:  TypeString,
+		FeaturesHandler: func(features Features, value ...StaticValue) (Expression, error) {
 			if value[0].IsNone() {
 				return value[0], nil
 			}
@@ -70,7 +253,7 @@ var stdFunctions = map[string]StdFunction{
 			}
 			v := make([]string, len(slice))
 			for i := range slice {
-				v[i], _ = toString(slice[i])
+				v[i], _ = toStringWith(features, slice[i])
 			}
 			separator := ","
 			if len(value) == 2 {
@@ -80,24 +263,52 @@ var stdFunctions = map[string]StdFunction{
 		},
 	},
 	"split": {
+		Description: `splits the string by the separator, "," by default`,
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		Pure:        true,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) == 0 || len(value) > 2 {
-				return nil, fmt.Errorf(`"split" function expects 1-2 arguments, %d provided`, len(value))
-			}
 			str, _ := value[0].StringValue()
 			separator := ","
 			if len(value) == 2 {
 				separator, _ = value[1].StringValue()
 			}
-			return NewValue(strings.Split(str, separator)), nil
+			parts := strings.SplitN(str, separator, capLimit(SplitMaxParts))
+			if SplitMaxParts > 0 && len(parts) > SplitMaxParts {
+				return nil, fmt.Errorf(`"split" function: result exceeds %d parts`, SplitMaxParts)
+			}
+			return NewValue(parts), nil
 		},
 	},
-	"int": {
-		ReturnType: TypeInt64,
+	"splitFirst": {
+		Description: "splits the string by the separator into at most the number of parts",
+		MinArgs:     3,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeString, TypeString, TypeInt64},
+		Pure:        true,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"int" function expects 1 argument, %d provided`, len(value))
+			str, _ := value[0].StringValue()
+			separator, _ := value[1].StringValue()
+			n, err := value[2].IntValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"splitFirst" function: invalid number of parts: %v`, err)
+			}
+			if n < 1 {
+				return nil, fmt.Errorf(`"splitFirst" function: number of parts should be positive, %d provided`, n)
 			}
+			if SplitMaxParts > 0 && n > int64(SplitMaxParts) {
+				return nil, fmt.Errorf(`"splitFirst" function: result exceeds %d parts`, SplitMaxParts)
+			}
+			return NewValue(strings.SplitN(str, separator, int(n))), nil
+		},
+	},
+	"int": {
+		Description: "converts the value to integer",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ReturnType:  TypeInt64,
+		Handler: func(value ...StaticValue) (Expression, error) {
 			v, err := value[0].IntValue()
 			if err != nil {
 				return nil, err
@@ -106,11 +317,11 @@ var stdFunctions = map[string]StdFunction{
 		},
 	},
 	"bool": {
-		ReturnType: TypeBool,
+		Description: "converts the value to boolean",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ReturnType:  TypeBool,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"bool" function expects 1 argument, %d provided`, len(value))
-			}
 			v, err := value[0].BoolValue()
 			if err != nil {
 				return nil, err
@@ -119,11 +330,11 @@ var stdFunctions = map[string]StdFunction{
 		},
 	},
 	"float": {
-		ReturnType: TypeFloat64,
+		Description: "converts the value to number",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ReturnType:  TypeFloat64,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"float" function expects 1 argument, %d provided`, len(value))
-			}
 			v, err := value[0].FloatValue()
 			if err != nil {
 				return nil, err
@@ -131,13 +342,42 @@ var stdFunctions = map[string]StdFunction{
 			return NewValue(v), nil
 		},
 	},
-	"tojson": {
-		ReturnType: TypeString,
+	"toList": {
+		Description: "converts the value to list: the JSON string is parsed, the scalar is a single-element list, none is an empty list",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			v, err := toListValue(value[0])
+			if err != nil {
+				return nil, fmt.Errorf(`"toList" function: %v`, err)
+			}
+			return NewValue(v), nil
+		},
+	},
+	"toMap": {
+		Description: "converts the value to map: the JSON string is parsed, the list is indexed, none is an empty map",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Pure:        true,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"tojson" function expects 1 argument, %d provided`, len(value))
+			v, err := toMapValue(value[0])
+			if err != nil {
+				return nil, fmt.Errorf(`"toMap" function: %v`, err)
 			}
-			b, err := json.Marshal(value[0].Value())
+			return NewValue(v), nil
+		},
+	},
+	"tryInt":   tryStdFunction("tryInt", "converts the value to integer, or null when it can't be converted", false, tryParseInt),
+	"tryFloat": tryStdFunction("tryFloat", "converts the value to number, or null when it can't be converted", false, tryParseFloat),
+	"tojson": {
+		Description: "serializes the value to JSON",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Pure:        true,
+		ReturnType:  TypeString,
+		MachinesHandler: func(machines []Machine, value ...StaticValue) (Expression, error) {
+			b, err := json.Marshal(revealValue(machines, value[0]))
 			if err != nil {
 				return nil, fmt.Errorf(`"tojson" function had problem marshalling: %s`, err.Error())
 			}
@@ -145,52 +385,158 @@ var stdFunctions = map[string]StdFunction{
 		},
 	},
 	"json": {
+		Description: "parses the JSON string",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		StrictArgs:  []int{0},
+		Pure:        true,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"json" function expects 1 argument, %d provided`, len(value))
-			}
 			if !value[0].IsString() {
 				return nil, fmt.Errorf(`"json" function argument should be a string`)
 			}
-			var v interface{}
-			err := json.Unmarshal([]byte(value[0].Value().(string)), &v)
+			v, err := parseJSON(value[0].Value().(string))
 			if err != nil {
 				return nil, fmt.Errorf(`"json" function had problem unmarshalling: %s`, err.Error())
 			}
 			return NewValue(v), nil
 		},
 	},
+	"tryJson": tryStdFunction("tryJson", "parses the JSON string, or null when it's malformed", true, tryParseJSON),
 	"toyaml": {
-		ReturnType: TypeString,
-		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"toyaml" function expects 1 argument, %d provided`, len(value))
+		Description: "serializes the value to YAML, with the optional map of formatting options",
+		MinArgs:     1,
+		MaxArgs:     2,
+		Pure:        true,
+		ReturnType:  TypeString,
+		MachinesHandler: func(machines []Machine, value ...StaticValue) (Expression, error) {
+			options := DefaultYAMLOptions()
+			if len(value) == 2 {
+				var err error
+				options, err = parseYAMLOptions(value[1])
+				if err != nil {
+					return nil, fmt.Errorf(`"toyaml" function: %v`, err)
+				}
 			}
-			b, err := yaml.Marshal(value[0].Value())
+			str, err := marshalYAML(revealValue(machines, value[0]), options)
 			if err != nil {
 				return nil, fmt.Errorf(`"toyaml" function had problem marshalling: %s`, err.Error())
 			}
-			return NewValue(string(b)), nil
+			return NewValue(str), nil
 		},
 	},
 	"yaml": {
+		Description: "parses the YAML string",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		StrictArgs:  []int{0},
+		Pure:        true,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"yaml" function expects 1 argument, %d provided`, len(value))
-			}
 			if !value[0].IsString() {
 				return nil, fmt.Errorf(`"yaml" function argument should be a string`)
 			}
-			var v interface{}
-			err := yaml.Unmarshal([]byte(value[0].Value().(string)), &v)
+			v, err := parseYAML(value[0].Value().(string))
 			if err != nil {
 				return nil, fmt.Errorf(`"yaml" function had problem unmarshalling: %s`, err.Error())
 			}
 			return NewValue(v), nil
 		},
 	},
+	"tryYaml": tryStdFunction("tryYaml", "parses the YAML string, or null when it's malformed", true, tryParseYAML),
+	"csv": {
+		Description: "parses the CSV string into the list of maps keyed by the header row, with the optional delimiter",
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			str, _ := value[0].StringValue()
+			delimiter, err := csvDelimiter(defaultCSVDelimiter)
+			if len(value) == 2 {
+				sep, _ := value[1].StringValue()
+				delimiter, err = csvDelimiter(sep)
+			}
+			if err != nil {
+				return nil, fmt.Errorf(`"csv" function: %v`, err)
+			}
+			v, err := parseCSV(str, delimiter)
+			if err != nil {
+				return nil, fmt.Errorf(`"csv" function: %v`, err)
+			}
+			return NewValue(v), nil
+		},
+	},
+	"tocsv": {
+		Description: "serializes the list of maps to CSV with the sorted keys as the header row, with the optional delimiter",
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeUnknown, TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			list, err := value[0].SliceValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"tocsv" function expects a list of maps, %s provided`, value[0])
+			}
+			delimiter, err := csvDelimiter(defaultCSVDelimiter)
+			if len(value) == 2 {
+				sep, _ := value[1].StringValue()
+				delimiter, err = csvDelimiter(sep)
+			}
+			if err != nil {
+				return nil, fmt.Errorf(`"tocsv" function: %v`, err)
+			}
+			str, err := buildCSV(list, delimiter)
+			if err != nil {
+				return nil, fmt.Errorf(`"tocsv" function: %v`, err)
+			}
+			return NewValue(str), nil
+		},
+	},
+	"toml": {
+		Description: "parses the TOML string, the dates and times are strings",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		StrictArgs:  []int{0},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if !value[0].IsString() {
+				return nil, fmt.Errorf(`"toml" function argument should be a string`)
+			}
+			v, err := parseTOML(value[0].Value().(string))
+			if err != nil {
+				return nil, fmt.Errorf(`"toml" function: %v`, err)
+			}
+			return NewValue(v), nil
+		},
+	},
+	"totoml": {
+		Description: "serializes the map to TOML",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if !value[0].IsMap() {
+				return nil, fmt.Errorf(`"totoml" function expects a map, %s provided`, value[0])
+			}
+			m, _ := value[0].MapValue()
+			str, err := buildTOML(m)
+			if err != nil {
+				return nil, fmt.Errorf(`"totoml" function: %v`, err)
+			}
+			return NewValue(str), nil
+		},
+	},
 	"shellquote": {
-		ReturnType: TypeString,
+		Description: "joins the arguments into the shell-quoted command line",
+		MinArgs:     0,
+		MaxArgs:     VariadicArgs,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
 		Handler: func(value ...StaticValue) (Expression, error) {
 			args := make([]string, len(value))
 			for i := range value {
@@ -200,21 +546,25 @@ var stdFunctions = map[string]StdFunction{
 		},
 	},
 	"shellargs": {
+		Description: "splits the shell command line into the arguments",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"shellargs" function expects 1 arguments, %d provided`, len(value))
-			}
 			v, _ := value[0].StringValue()
 			words, err := shellquote.Split(v)
 			return NewValue(words), err
 		},
 	},
 	"trim": {
-		ReturnType: TypeString,
+		Description: "removes the leading and trailing whitespace of the string",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		StrictArgs:  []int{0},
+		ReturnType:  TypeString,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"trim" function expects 1 argument, %d provided`, len(value))
-			}
 			if !value[0].IsString() {
 				return nil, fmt.Errorf(`"trim" function argument should be a string`)
 			}
@@ -222,19 +572,95 @@ var stdFunctions = map[string]StdFunction{
 			return NewValue(strings.TrimSpace(str)), nil
 		},
 	},
-	"len": {
-		ReturnType: TypeInt64,
+	"collapse":            whitespaceStdFunction("collapse", "trims the string and replaces each run of whitespace with a single space", collapseWhitespace),
+	"normalizeWhitespace": whitespaceStdFunction("normalizeWhitespace", "collapses the whitespace in each line of the string, keeping the lines", normalizeWhitespace),
+
+	"lower":      stringStdFunction("lower", "lower-cases the string", strings.ToLower),
+	"upper":      stringStdFunction("upper", "upper-cases the string", strings.ToUpper),
+	"contains":   stringPredicateStdFunction("contains", "tells if the string contains the other one", strings.Contains),
+	"startsWith": stringPredicateStdFunction("startsWith", "tells if the string starts with the prefix", strings.HasPrefix),
+	"endsWith":   stringPredicateStdFunction("endsWith", "tells if the string ends with the suffix", strings.HasSuffix),
+	"replace": {
+		Description: "replaces all occurrences of the substring",
+		MinArgs:     3,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeString, TypeString, TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		FeaturesHandler: func(features Features, value ...StaticValue) (Expression, error) {
+			str, _ := toStringWith(features, value[0].Value())
+			old, _ := toStringWith(features, value[1].Value())
+			replacement, _ := toStringWith(features, value[2].Value())
+			return NewValue(strings.ReplaceAll(str, old, replacement)), nil
+		},
+	},
+	"padLeft":  padStdFunction("padLeft", "pads the string on the left to the length, with space or the provided character", true),
+	"padRight": padStdFunction("padRight", "pads the string on the right to the length, with space or the provided character", false),
+	"repeat": {
+		Description: "repeats the string the number of times",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeInt64},
+		Pure:        true,
+		ReturnType:  TypeString,
+		FeaturesHandler: func(features Features, value ...StaticValue) (Expression, error) {
+			str, _ := toStringWith(features, value[0].Value())
+			count, err := value[1].IntValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"repeat" function expects 2nd argument to be integer, %s provided: %v`, value[1], err)
+			}
+			if count < 0 {
+				return nil, fmt.Errorf(`"repeat" function expects 2nd argument to be >= 0, %s provided`, value[1])
+			}
+			if int64(len(str))*count > maxRepeatLength {
+				return nil, fmt.Errorf(`"repeat" function: result would exceed %d bytes`, maxRepeatLength)
+			}
+			return NewValue(strings.Repeat(str, int(count))), nil
+		},
+	},
+
+	"basename":  pathStdFunction("basename", "last element of the slash-separated path", path.Base),
+	"dirname":   pathStdFunction("dirname", "all but the last element of the slash-separated path", dirname),
+	"ext":       pathStdFunction("ext", "extension of the last element of the slash-separated path", ext),
+	"pathClean": pathStdFunction("pathClean", "shortest equivalent of the slash-separated path", path.Clean),
+	"pathJoin": {
+		Description: "joins the slash-separated path elements, passed as arguments or a single list",
+		MinArgs:     0,
+		MaxArgs:     VariadicArgs,
+		ArgTypes:    []Type{TypeString},
+		ReturnType:  TypeString,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"len" function expects 1 argument, %d provided`, len(value))
+			if len(value) == 1 && value[0].IsSlice() {
+				list, _ := value[0].SliceValue()
+				value = make([]StaticValue, len(list))
+				for i := range list {
+					value[i] = NewValue(list[i])
+				}
+			}
+			parts := make([]string, len(value))
+			for i := range value {
+				v, err := value[i].StringValue()
+				if err != nil {
+					return nil, fmt.Errorf(`"pathJoin" function expects string arguments, %s provided: %v`, value[i], err)
+				}
+				parts[i] = v
 			}
+			return NewValue(path.Join(parts...)), nil
+		},
+	},
+	"len": {
+		Description: "number of characters in the string, or items in the list or map",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ReturnType:  TypeInt64,
+		Handler: func(value ...StaticValue) (Expression, error) {
 			if value[0].IsSlice() {
 				v, err := value[0].SliceValue()
 				return NewValue(int64(len(v))), err
 			}
 			if value[0].IsString() {
 				v, err := value[0].StringValue()
-				return NewValue(int64(len(v))), err
+				return NewValue(int64(utf8.RuneCountInString(v))), err
 			}
 			if value[0].IsMap() {
 				v, err := value[0].MapValue()
@@ -243,12 +669,29 @@ var stdFunctions = map[string]StdFunction{
 			return nil, fmt.Errorf(`"len" function expects string, slice or map, %v provided`, value[0])
 		},
 	},
-	"floor": {
-		ReturnType: TypeInt64,
+	"bytelen": {
+		Description: "number of bytes in the string",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		StrictArgs:  []int{0},
+		ReturnType:  TypeInt64,
+		Pure:        true,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"floor" function expects 1 argument, %d provided`, len(value))
+			if !value[0].IsString() {
+				return nil, fmt.Errorf(`"bytelen" function expects string, %v provided`, value[0])
 			}
+			v, err := value[0].StringValue()
+			return NewValue(int64(len(v))), err
+		},
+	},
+	"floor": {
+		Description: "rounds the number down",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeFloat64},
+		ReturnType:  TypeInt64,
+		Handler: func(value ...StaticValue) (Expression, error) {
 			f, err := value[0].FloatValue()
 			if err != nil {
 				return nil, fmt.Errorf(`"floor" function expects a number, %s provided: %v`, value[0], err)
@@ -257,11 +700,12 @@ var stdFunctions = map[string]StdFunction{
 		},
 	},
 	"ceil": {
-		ReturnType: TypeInt64,
+		Description: "rounds the number up",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeFloat64},
+		ReturnType:  TypeInt64,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"ceil" function expects 1 argument, %d provided`, len(value))
-			}
 			f, err := value[0].FloatValue()
 			if err != nil {
 				return nil, fmt.Errorf(`"ceil" function expects a number, %s provided: %v`, value[0], err)
@@ -270,11 +714,12 @@ var stdFunctions = map[string]StdFunction{
 		},
 	},
 	"round": {
-		ReturnType: TypeInt64,
+		Description: "rounds the number to the nearest integer",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeFloat64},
+		ReturnType:  TypeInt64,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"round" function expects 1 argument, %d provided`, len(value))
-			}
 			f, err := value[0].FloatValue()
 			if err != nil {
 				return nil, fmt.Errorf(`"round" function expects a number, %s provided: %v`, value[0], err)
@@ -282,11 +727,162 @@ var stdFunctions = map[string]StdFunction{
 			return NewValue(int64(math2.Round(f))), nil
 		},
 	},
-	"chunk": {
+	"abs": {
+		Description: "absolute value of the number",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeFloat64},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			f, err := value[0].FloatValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"abs" function expects a number, %s provided: %v`, value[0], err)
+			}
+			if value[0].IsInt() {
+				v, _ := value[0].IntValue()
+				if v < 0 {
+					v = -v
+				}
+				return NewValue(v), nil
+			}
+			return NewValue(math2.Abs(f)), nil
+		},
+	},
+	"min": numberAggregateStdFunction("min", "smallest of the numbers, passed as arguments or a single list", func(a, b int64) int64 { return min(a, b) }, math2.Min),
+	"max": numberAggregateStdFunction("max", "largest of the numbers, passed as arguments or a single list", func(a, b int64) int64 { return max(a, b) }, math2.Max),
+	"sum": numberAggregateStdFunction("sum", "sum of the numbers, passed as arguments or a single list", func(a, b int64) int64 { return a + b }, func(a, b float64) float64 { return a + b }),
+	"avg": {
+		Description: "arithmetic mean of the numbers, passed as arguments or a single list",
+		MinArgs:     1,
+		MaxArgs:     VariadicArgs,
+		ArgTypes:    []Type{TypeFloat64},
+		Pure:        true,
+		ReturnType:  TypeFloat64,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			list, err := numberArgs("avg", value)
+			if err != nil {
+				return nil, err
+			}
+			sum := 0.0
+			for _, item := range list {
+				v, err := toFloat(item)
+				if err != nil {
+					return nil, fmt.Errorf(`"avg" function expects numbers, %v provided: %v`, item, err)
+				}
+				sum += v
+			}
+			return NewValue(sum / float64(len(list))), nil
+		},
+	},
+	"humanNumber": {
+		Description: `formats the number with the thousands separator, "," by default`,
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeFloat64, TypeString},
+		StrictArgs:  []int{0},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if !value[0].IsNumber() {
+				return nil, fmt.Errorf(`"humanNumber" function expects a number, %s provided`, value[0])
+			}
+			separator := ","
+			if len(value) == 2 {
+				separator, _ = value[1].StringValue()
+			}
+			return NewValue(humanNumber(value[0], separator)), nil
+		},
+	},
+	"compactNumber": {
+		Description: "formats the number in the compact form, like 1.5K",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeFloat64},
+		StrictArgs:  []int{0},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if !value[0].IsNumber() {
+				return nil, fmt.Errorf(`"compactNumber" function expects a number, %s provided`, value[0])
+			}
+			return NewValue(compactNumber(value[0])), nil
+		},
+	},
+	"format": {
+		Description: "formats the arguments with the printf-style layout, supporting %s, %d, %f, %x and %v verbs with the width and precision",
+		MinArgs:     1,
+		MaxArgs:     VariadicArgs,
+		ArgTypes:    []Type{TypeString, TypeUnknown},
+		Pure:        true,
+		ReturnType:  TypeString,
+		FeaturesHandler: func(features Features, value ...StaticValue) (Expression, error) {
+			layout, _ := toStringWith(features, value[0].Value())
+			str, err := format(features, layout, value[1:])
+			if err != nil {
+				return nil, fmt.Errorf(`"format" function: %v`, err)
+			}
+			return NewValue(str), nil
+		},
+	},
+	"toFixed": {
+		Description: "formats the number with the fixed number of decimal places",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeFloat64, TypeInt64},
+		StrictArgs:  []int{0},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if !value[0].IsNumber() {
+				return nil, fmt.Errorf(`"toFixed" function expects a number, %s provided`, value[0])
+			}
+			number, _ := value[0].FloatValue()
+			digits, err := value[1].IntValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"toFixed" function expects number of digits to be an integer, %s provided: %v`, value[1], err)
+			}
+			str, err := toFixed(number, digits)
+			if err != nil {
+				return nil, fmt.Errorf(`"toFixed" function: %v`, err)
+			}
+			return NewValue(str), nil
+		},
+	},
+	"range": {
+		Description: "list of integers to the end, or from the start to the end, with the optional step",
+		MinArgs:     1,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeInt64, TypeInt64, TypeInt64},
+		Pure:        true,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 2 {
-				return nil, fmt.Errorf(`"chunk" function expects 2 arguments, %d provided`, len(value))
+			args := make([]int64, len(value))
+			for i := range value {
+				if !value[i].IsInt() {
+					return nil, fmt.Errorf(`"range" function expects integer arguments, %s provided as argument %d`, value[i], i+1)
+				}
+				args[i], _ = value[i].IntValue()
+			}
+			start, end, step := int64(0), args[0], int64(1)
+			if len(args) > 1 {
+				start, end = args[0], args[1]
+			}
+			if len(args) > 2 {
+				step = args[2]
+			}
+			result, err := intRange(start, end, step)
+			if err != nil {
+				return nil, fmt.Errorf(`"range" function: %v`, err)
 			}
+			return NewValue(result), nil
+		},
+	},
+	"chunk": {
+		Description: "splits the list into the chunks of the size",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeUnknown, TypeInt64},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
 			list, err := value[0].SliceValue()
 			if err != nil {
 				return nil, fmt.Errorf(`"chunk" function expects 1st argument to be a list, %s provided: %v`, value[0], err)
@@ -310,157 +906,1626 @@ var stdFunctions = map[string]StdFunction{
 			return NewValue(chunks), nil
 		},
 	},
-	"at": {
+	"sort": {
+		Description: "sorts the list, by the optional key expression",
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeUnknown, TypeString},
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 2 {
-				return nil, fmt.Errorf(`"at" function expects 2 arguments, %d provided`, len(value))
+			list, err := value[0].SliceValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"sort" function expects 1st argument to be a list, %s provided: %v`, value[0], err)
 			}
-			if value[0].IsSlice() {
-				v, _ := value[0].SliceValue()
-				k, err := value[1].IntValue()
+			var expr Expression
+			if len(value) == 2 {
+				exprStr, _ := value[1].StringValue()
+				expr, err = Compile(exprStr)
 				if err != nil {
-					return nil, fmt.Errorf(`"at" function expects 2nd argument to be number for list, %s provided`, value[1])
-				}
-				if k >= 0 && k < int64(len(v)) {
-					return NewValue(v[int(k)]), nil
+					return nil, fmt.Errorf(`"sort" function expects 2nd argument to be valid expression, '%s' provided: %v`, value[1], err)
 				}
-				return nil, fmt.Errorf(`"at" function: error: out of bounds (length=%d, index=%d)`, len(v), k)
 			}
-			if value[0].IsMap() {
-				v, _ := value[0].MapValue()
-				k, _ := value[1].StringValue()
-				item, ok := v[k]
-				if ok {
-					return NewValue(item), nil
-				}
-				return None, nil
+			keys, err := itemValues(list, expr)
+			if err != nil {
+				return nil, fmt.Errorf(`"sort" function: %v`, err)
 			}
-			if value[0].IsString() {
-				v, _ := value[0].StringValue()
-				k, err := value[1].IntValue()
-				if err != nil {
-					return nil, fmt.Errorf(`"at" function expects 2nd argument to be number for string, %s provided`, value[1])
-				}
-				if k >= 0 && k < int64(len(v)) {
-					return NewValue(v[int(k)]), nil
-				}
-				return nil, fmt.Errorf(`"at" function: error: out of bounds (length=%d, index=%d)`, len(v), k)
+			result, err := sortByKeys(list, keys)
+			if err != nil {
+				return nil, fmt.Errorf(`"sort" function: %v`, err)
 			}
-			return nil, fmt.Errorf(`"at" function can be performed only on lists, maps and strings: %s provided`, value[0])
+			return NewValue(result), nil
 		},
 	},
-	"map": {
+	"reverse": {
+		Description: "list in the reversed order",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Pure:        true,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 2 {
-				return nil, fmt.Errorf(`"map" function expects 2 arguments, %d provided`, len(value))
-			}
 			list, err := value[0].SliceValue()
 			if err != nil {
-				return nil, fmt.Errorf(`"map" function expects 1st argument to be a list, %s provided: %v`, value[0], err)
-			}
-			exprStr, _ := value[1].StringValue()
-			expr, err := Compile(exprStr)
-			if err != nil {
-				return nil, fmt.Errorf(`"map" function expects 2nd argument to be valid expression, '%s' provided: %v`, value[1], err)
+				return nil, fmt.Errorf(`"reverse" function expects 1st argument to be a list, %s provided: %v`, value[0], err)
 			}
-			result := make([]string, len(list))
-			for i := 0; i < len(list); i++ {
-				ex, _ := Compile(expr.String())
-				v, err := ex.Resolve(NewMachine().Register("_.value", list[i]).Register("_.index", i).Register("_.key", i))
-				if err != nil {
-					return nil, fmt.Errorf(`"map" function: error while mapping %d index (%v): %v`, i, list[i], err)
-				}
-				result[i] = v.String()
+			result := make([]interface{}, len(list))
+			for i := range list {
+				result[len(list)-1-i] = list[i]
 			}
-			return Compile(fmt.Sprintf("list(%s)", strings.Join(result, ",")))
+			return NewValue(result), nil
 		},
 	},
-	"filter": {
+	"uniq": keyedListStdFunction("uniq", "list without the duplicated items", false, uniqueByKey),
+	"flatten": {
+		Description: "flattens the nested lists",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Pure:        true,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 2 {
-				return nil, fmt.Errorf(`"filter" function expects 2 arguments, %d provided`, len(value))
-			}
 			list, err := value[0].SliceValue()
 			if err != nil {
-				return nil, fmt.Errorf(`"filter" function expects 1st argument to be a list, %s provided: %v`, value[0], err)
+				return nil, fmt.Errorf(`"flatten" function expects 1st argument to be a list, %s provided: %v`, value[0], err)
 			}
-			exprStr, _ := value[1].StringValue()
-			expr, err := Compile(exprStr)
+			return NewValue(flattenList(list)), nil
+		},
+	},
+	"keys": {
+		Description: "sorted keys of the map",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			m, err := mapArg("keys", value[0], 0)
+			if err != nil {
+				return nil, err
+			}
+			keys := sortedKeys(m)
+			result := make([]interface{}, len(keys))
+			for i := range keys {
+				result[i] = keys[i]
+			}
+			return NewValue(result), nil
+		},
+	},
+	"values": {
+		Description: "values of the map, in order of the sorted keys",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			m, err := mapArg("values", value[0], 0)
+			if err != nil {
+				return nil, err
+			}
+			keys := sortedKeys(m)
+			result := make([]interface{}, len(keys))
+			for i := range keys {
+				result[i] = m[keys[i]]
+			}
+			return NewValue(result), nil
+		},
+	},
+	"merge": {
+		Description: "merges the maps, the keys of the later ones take precedence",
+		MinArgs:     0,
+		MaxArgs:     VariadicArgs,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			result := make(map[string]interface{})
+			for i := range value {
+				m, err := mapArg("merge", value[i], i)
+				if err != nil {
+					return nil, err
+				}
+				for k, v := range m {
+					result[k] = v
+				}
+			}
+			return NewValue(result), nil
+		},
+	},
+	"pick": filterMapStdFunction("pick", "copy of the map with only the provided keys", true),
+	"omit": filterMapStdFunction("omit", "copy of the map without the provided keys", false),
+	"matrix": {
+		Description: "combinations of the map of lists, without the ones matching the optional exclusion maps",
+		MinArgs:     1,
+		MaxArgs:     2,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			dimensionsMap, err := value[0].MapValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"matrix" function expects 1st argument to be a map of lists, %s provided: %v`, value[0], err)
+			}
+			dimensions := make(map[string][]interface{}, len(dimensionsMap))
+			for name, values := range dimensionsMap {
+				list, err := NewValue(values).SliceValue()
+				if err != nil {
+					return nil, fmt.Errorf(`"matrix" function expects "%s" dimension to be a list, %v provided: %v`, name, values, err)
+				}
+				dimensions[name] = list
+			}
+			var exclusions []matrixExclusion
+			if len(value) == 2 {
+				list, err := value[1].SliceValue()
+				if err != nil {
+					return nil, fmt.Errorf(`"matrix" function expects 2nd argument to be a list of exclusion maps, %s provided: %v`, value[1], err)
+				}
+				for i := range list {
+					exclusionMap, err := NewValue(list[i]).MapValue()
+					if err != nil {
+						return nil, fmt.Errorf(`"matrix" function expects exclusion %d to be a map, %v provided: %v`, i, list[i], err)
+					}
+					exclusion, err := newMatrixExclusion(exclusionMap)
+					if err != nil {
+						return nil, fmt.Errorf(`"matrix" function: %v`, err)
+					}
+					exclusions = append(exclusions, exclusion)
+				}
+			}
+			combinations, err := buildMatrix(dimensions, exclusions)
+			if err != nil {
+				return nil, fmt.Errorf(`"matrix" function: %v`, err)
+			}
+			return NewValue(combinations), nil
+		},
+	},
+	"at": {
+		Description: "item of the list or character of the string at the index, negative from the end, or value of the map at the key",
+		MinArgs:     2,
+		MaxArgs:     2,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if value[0].IsSlice() {
+				v, _ := value[0].SliceValue()
+				k, err := value[1].IntValue()
+				if err != nil {
+					return nil, newArgError(1, fmt.Errorf(`"at" function expects 2nd argument to be number for list, %s provided`, value[1]))
+				}
+				if i, ok := elementIndex(len(v), k); ok {
+					return NewValue(v[i]), nil
+				}
+				return nil, newArgError(1, fmt.Errorf("out of bounds (length=%d, index=%d)", len(v), k))
+			}
+			if value[0].IsMap() {
+				v, _ := value[0].MapValue()
+				k, _ := value[1].StringValue()
+				item, ok := v[k]
+				if ok {
+					return NewValue(item), nil
+				}
+				return None, nil
+			}
+			if value[0].IsString() {
+				str, _ := value[0].StringValue()
+				v := []rune(str)
+				k, err := value[1].IntValue()
+				if err != nil {
+					return nil, newArgError(1, fmt.Errorf(`"at" function expects 2nd argument to be number for string, %s provided`, value[1]))
+				}
+				if i, ok := elementIndex(len(v), k); ok {
+					return NewValue(string(v[i])), nil
+				}
+				return nil, newArgError(1, fmt.Errorf("out of bounds (length=%d, index=%d)", len(v), k))
+			}
+			return nil, newArgError(0, fmt.Errorf(`"at" function can be performed only on lists, maps and strings: %s provided`, value[0]))
+		},
+	},
+	"slice": {
+		Description: "part of the list or string between the indexes, negative from the end",
+		MinArgs:     2,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeUnknown, TypeInt64, TypeInt64},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if !value[0].IsSlice() && !value[0].IsString() {
+				return nil, fmt.Errorf(`"slice" function can be performed only on lists and strings: %s provided`, value[0])
+			}
+			start, err := value[1].IntValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"slice" function expects 2nd argument to be number, %s provided`, value[1])
+			}
+			end := int64(math2.MaxInt64)
+			if len(value) == 3 && !value[2].IsNone() {
+				end, err = value[2].IntValue()
+				if err != nil {
+					return nil, fmt.Errorf(`"slice" function expects 3rd argument to be number, %s provided`, value[2])
+				}
+			}
+			if value[0].IsString() {
+				str, _ := value[0].StringValue()
+				v := []rune(str)
+				from, to := sliceBounds(len(v), start, end)
+				return NewValue(string(v[from:to])), nil
+			}
+			v, _ := value[0].SliceValue()
+			from, to := sliceBounds(len(v), start, end)
+			return NewValue(v[from:to]), nil
+		},
+	},
+	"map": {
+		Description: "maps the list items with the expression using _.value and _.index",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeUnknown, TypeString},
+		MachinesHandler: func(machines []Machine, value ...StaticValue) (Expression, error) {
+			list, err := value[0].SliceValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"map" function expects 1st argument to be a list, %s provided: %v`, value[0], err)
+			}
+			exprStr, _ := value[1].StringValue()
+			expr, err := Compile(exprStr)
+			if err != nil {
+				return nil, fmt.Errorf(`"map" function expects 2nd argument to be valid expression, '%s' provided: %v`, value[1], err)
+			}
+			result := make([]interface{}, len(list))
+			ctx := resolveContext(machines)
+			current := &iterationMachine{}
+			m := append([]Machine{current}, machines...)
+			for i := 0; i < len(list); i++ {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				current.value, current.index = list[i], i
+				v, err := expr.Resolve(m...)
+				if err != nil {
+					return nil, fmt.Errorf(`"map" function: error while mapping %d index (%v): %v`, i, list[i], err)
+				}
+				// The whole call is left for the further resolution, when the machines can't resolve it yet
+				if v.Static() == nil {
+					return nil, errNotResolvedYet
+				}
+				result[i] = v.Static().Value()
+			}
+			return NewValue(result), nil
+		},
+	},
+	"filter": {
+		Description: "list items for which the expression using _.value and _.index is true",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeUnknown, TypeString},
+		MachinesHandler: func(machines []Machine, value ...StaticValue) (Expression, error) {
+			list, err := value[0].SliceValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"filter" function expects 1st argument to be a list, %s provided: %v`, value[0], err)
+			}
+			exprStr, _ := value[1].StringValue()
+			expr, err := Compile(exprStr)
+			if err != nil {
+				return nil, fmt.Errorf(`"filter" function expects 2nd argument to be valid expression, '%s' provided: %v`, value[1], err)
+			}
+			result := make([]interface{}, 0)
+			ctx := resolveContext(machines)
+			current := &iterationMachine{}
+			m := append([]Machine{current}, machines...)
+			for i := 0; i < len(list); i++ {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				current.value, current.index = list[i], i
+				v, err := expr.Resolve(m...)
+				if err != nil {
+					return nil, fmt.Errorf(`"filter" function: error while filtering %d index (%v): %v`, i, list[i], err)
+				}
+				// The whole call is left for the further resolution, when the machines can't resolve it yet
+				if v.Static() == nil {
+					return nil, errNotResolvedYet
+				}
+				b, err := v.Static().BoolValue()
+				if err != nil {
+					return nil, fmt.Errorf(`"filter" function: could not resolve filter for %d index (%v) as boolean: %s`, i, list[i], err)
+				}
+				if b {
+					result = append(result, list[i])
+				}
+			}
+			return NewValue(result), nil
+		},
+	},
+	"reduce": {
+		Description: "reduces the list with the expression using _.accumulator and _.value, from the optional initial value",
+		MinArgs:     2,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeUnknown, TypeString},
+		Handler: func(value ...StaticValue) (Expression, error) {
+			list, err := value[0].SliceValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"reduce" function expects 1st argument to be a list, %s provided: %v`, value[0], err)
+			}
+			exprStr, _ := value[1].StringValue()
+			expr, err := Compile(exprStr)
+			if err != nil {
+				return nil, fmt.Errorf(`"reduce" function expects 2nd argument to be valid expression, '%s' provided: %v`, value[1], err)
+			}
+			// Without the initial value, the first item is the initial accumulator
+			current := &iterationMachine{accumulator: None}
+			start := 0
+			if len(value) == 3 {
+				current.accumulator = value[2]
+			} else if len(list) > 0 {
+				current.accumulator = NewValue(list[0])
+				start = 1
+			}
+			for i := start; i < len(list); i++ {
+				current.value, current.index = list[i], i
+				v, err := expr.Resolve(current)
+				if err != nil {
+					return nil, fmt.Errorf(`"reduce" function: error while reducing %d index (%v): %v`, i, list[i], err)
+				}
+				if v.Static() == nil {
+					return nil, fmt.Errorf(`"reduce" function: could not resolve reducer for %d index (%v): %s`, i, list[i], v)
+				}
+				current.accumulator = v.Static()
+			}
+			return current.accumulator, nil
+		},
+	},
+	"eval": {
+		Description: "compiles the expression from the string",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		MachinesHandler: func(machines []Machine, value ...StaticValue) (Expression, error) {
+			if err := resolveContext(machines).Err(); err != nil {
+				return nil, err
+			}
+			exprStr, _ := value[0].StringValue()
+			expr, err := Compile(exprStr)
+			if err != nil {
+				return nil, fmt.Errorf(`"eval" function: %s: error: %v`, value[0], err)
+			}
+			// Resolve it as the nested expression, so the self-referencing expressions hit the depth limit
+			return expr.Resolve(machines...)
+		},
+	},
+	"tpl": {
+		Description: "renders the template string with {{ expressions }}, the \\{{ is the literal {{",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		ReturnType:  TypeString,
+		MachinesHandler: func(machines []Machine, value ...StaticValue) (Expression, error) {
+			if err := resolveContext(machines).Err(); err != nil {
+				return nil, err
+			}
+			tplStr, _ := value[0].StringValue()
+			expr, err := compileTemplate(resolveFeatures(machines), tplStr)
+			if err != nil {
+				return nil, fmt.Errorf(`"tpl" function: %s: error: %v`, value[0], err)
+			}
+			// Resolve it as the nested expression, the parts that can't be resolved yet are left for the next pass
+			return expr.Resolve(machines...)
+		},
+	},
+	"resolveDeep": {
+		Description: "resolves the {{ templates }} embedded in the strings of the value, its maps and lists, the \\{{ is the literal {{",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ReturnType:  TypeUnknown,
+		MachinesHandler: func(machines []Machine, value ...StaticValue) (Expression, error) {
+			result, err := resolveDeep(machines, value[0])
+			if err != nil && !errors.Is(err, errNotResolvedYet) {
+				return nil, fmt.Errorf(`"resolveDeep" function: %w`, err)
+			}
+			return result, err
+		},
+	},
+	"env": {
+		Description: "environment variable from the snapshot of NewEnvMachine, with the optional default; unresolved without it",
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeUnknown},
+		ReturnType:  TypeUnknown,
+		MachinesHandler: func(machines []Machine, value ...StaticValue) (Expression, error) {
+			env := resolveEnv(machines)
+			if env == nil {
+				return nil, errNotResolvedYet
+			}
+			return snapshotLookup("env", "environment variable", func(name string) (interface{}, bool) {
+				v, ok := env.env[name]
+				return v, ok
+			}, value...)
+		},
+	},
+	"config": {
+		Description: "configuration value from the snapshot of NewConfigMachine, with the optional default; unresolved without it",
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeUnknown},
+		ReturnType:  TypeUnknown,
+		MachinesHandler: func(machines []Machine, value ...StaticValue) (Expression, error) {
+			config := resolveConfig(machines)
+			if config == nil {
+				return nil, errNotResolvedYet
+			}
+			return snapshotLookup("config", "configuration value", func(name string) (interface{}, bool) {
+				v, ok := config.config[name]
+				return v, ok
+			}, value...)
+		},
+	},
+	"file": {
+		Description:      "content of the text file from the root of NewFileMachine; unresolved without it",
+		MinArgs:          1,
+		MaxArgs:          1,
+		ArgTypes:         []Type{TypeString},
+		StrictArgs:       []int{0},
+		ReturnType:       TypeString,
+		MachinesHandler:  fileCall("file", readFileString),
+		NonDeterministic: true,
+	},
+	"fileBase64": {
+		Description:      "base64 encoded content of the file from the root of NewFileMachine, for the binary files; unresolved without it",
+		MinArgs:          1,
+		MaxArgs:          1,
+		ArgTypes:         []Type{TypeString},
+		StrictArgs:       []int{0},
+		ReturnType:       TypeString,
+		MachinesHandler:  fileCall("fileBase64", readFileBase64),
+		NonDeterministic: true,
+	},
+	"fileExists": {
+		Description:      "tells if the regular file exists in the root of NewFileMachine; unresolved without it",
+		MinArgs:          1,
+		MaxArgs:          1,
+		ArgTypes:         []Type{TypeString},
+		StrictArgs:       []int{0},
+		ReturnType:       TypeBool,
+		MachinesHandler:  fileCall("fileExists", fileExists),
+		NonDeterministic: true,
+	},
+	"uuid": {
+		Description: "random UUID v4, resolved only with the random functions enabled",
+		MinArgs:     0,
+		MaxArgs:     0,
+		ReturnType:  TypeString,
+		RandomHandler: func(r *rand.Rand, _ ...StaticValue) (Expression, error) {
+			id, err := randomUUID(r)
+			if err != nil {
+				return nil, fmt.Errorf(`"uuid" function: %v`, err)
+			}
+			return NewValue(id), nil
+		},
+		NonDeterministic: true,
+	},
+	"random": {
+		Description: "random integer between 0 and n, excluding n, resolved only with the random functions enabled",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeInt64},
+		ReturnType:  TypeInt64,
+		RandomHandler: func(r *rand.Rand, value ...StaticValue) (Expression, error) {
+			n, err := value[0].IntValue()
+			if err != nil || n <= 0 {
+				return nil, newArgError(0, fmt.Errorf(`"random" function expects a positive integer, %s provided`, value[0]))
+			}
+			return NewValue(r.Int63n(n)), nil
+		},
+		NonDeterministic: true,
+	},
+	"randomString": {
+		Description: "random alphanumeric string of the length, resolved only with the random functions enabled",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeInt64},
+		ReturnType:  TypeString,
+		RandomHandler: func(r *rand.Rand, value ...StaticValue) (Expression, error) {
+			length, err := value[0].IntValue()
+			if err != nil {
+				return nil, newArgError(0, fmt.Errorf(`"randomString" function expects a length, %s provided`, value[0]))
+			}
+			str, err := randomString(r, length)
+			if err != nil {
+				return nil, newArgError(0, fmt.Errorf(`"randomString" function: %v`, err))
+			}
+			return NewValue(str), nil
+		},
+		NonDeterministic: true,
+	},
+	"jq": {
+		Description: "runs the jq query on the value, with the optional map of options: timeout and first",
+		MinArgs:     2,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeUnknown, TypeString},
+		MachinesHandler: func(machines []Machine, value ...StaticValue) (Expression, error) {
+			queryStr, _ := value[1].StringValue()
+			query, err := gojq.Parse(queryStr)
+			if err != nil {
+				return nil, fmt.Errorf(`"jq" error: could not parse the query: %s: %v`, queryStr, err)
+			}
+			options := jqOptions{timeout: DefaultJqTimeout}
+			if len(value) == 3 && !value[2].IsNone() {
+				options, err = parseJqOptions(value[2])
+				if err != nil {
+					return nil, fmt.Errorf(`"jq" function expects 3rd argument to be a map of options: %v`, err)
+				}
+			}
+
+			// Marshal data to basic types
+			bytes, err := json.Marshal(value[0].Value())
+			if err != nil {
+				return nil, fmt.Errorf(`"jq" error: could not marshal the value: %v: %v`, value[0].Value(), err)
+			}
+			var v interface{}
+			_ = json.Unmarshal(bytes, &v)
+
+			// Run query against the value
+			ctx, ctxCancel := context.WithTimeout(resolveContext(machines), options.timeout)
+			defer ctxCancel()
+			iter := query.RunWithContext(ctx, v)
+			result := make([]interface{}, 0)
+			for {
+				v, ok := iter.Next()
+				if !ok {
+					break
+				}
+				if err, ok := v.(error); ok {
+					return nil, errors.Wrapf(err, `"jq" error: executing: %s`, queryStr)
+				}
+				result = append(result, v)
+				if options.first {
+					break
+				}
+			}
+			if options.first {
+				if len(result) == 0 {
+					return None, nil
+				}
+				return NewValue(result[0]), nil
+			}
+			return NewValue(result), nil
+		},
+	},
+	"checksumVerify": {
+		Description: "tells if the content matches the hex encoded digest, with the optional algorithm",
+		MinArgs:     2,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeString, TypeString, TypeString},
+		Pure:        true,
+		ReturnType:  TypeBool,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			content, _ := value[0].StringValue()
+			expected, _ := value[1].StringValue()
+			algorithm := ""
+			if len(value) == 3 {
+				algorithm, _ = value[2].StringValue()
+			}
+			ok, err := checksumVerify(content, strings.TrimSpace(expected), algorithm)
+			if err != nil {
+				return nil, fmt.Errorf(`"checksumVerify" function: %v`, err)
+			}
+			return NewValue(ok), nil
+		},
+	},
+	"base64encode": {
+		Description: "encodes the string with base64",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		FeaturesHandler: func(features Features, value ...StaticValue) (Expression, error) {
+			str, _ := toStringWith(features, value[0].Value())
+			return NewValue(base64.StdEncoding.EncodeToString([]byte(str))), nil
+		},
+	},
+	"base64decode": {
+		Description: "decodes the base64 string",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			str, _ := value[0].StringValue()
+			decoded, err := base64.StdEncoding.DecodeString(str)
+			if err != nil {
+				return nil, fmt.Errorf(`"base64decode" function: invalid base64: %v`, err)
+			}
+			return NewValue(string(decoded)), nil
+		},
+	},
+	"sha256": {
+		Description: "hex encoded SHA-256 digest of the value converted to string",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		FeaturesHandler: func(features Features, value ...StaticValue) (Expression, error) {
+			return hashFunction("sha256", features, value...)
+		},
+	},
+	"md5": {
+		Description: "hex encoded MD5 digest of the value converted to string",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		FeaturesHandler: func(features Features, value ...StaticValue) (Expression, error) {
+			return hashFunction("md5", features, value...)
+		},
+	},
+	"jwtDecodeUnverified": {
+		Description: "decodes the header and claims of the JWT, without verifying its signature",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			token, _ := value[0].StringValue()
+			decoded, err := decodeJWTUnverified(token)
+			if err != nil {
+				return nil, fmt.Errorf(`"jwtDecodeUnverified" function: %v`, err)
+			}
+			return NewValue(decoded), nil
+		},
+	},
+	"jwtExpiredUnverified": {
+		Description: "tells if the JWT is expired, with the optional clock skew in seconds, without verifying its signature",
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeFloat64},
+		ReturnType:  TypeBool,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			token, _ := value[0].StringValue()
+			skew := 0.0
+			if len(value) == 2 {
+				var err error
+				skew, err = value[1].FloatValue()
+				if err != nil {
+					return nil, fmt.Errorf(`"jwtExpiredUnverified" function expects skew to be number of seconds: %v`, err)
+				}
+			}
+			expired, err := jwtExpiredUnverified(token, time.Duration(skew*float64(time.Second)))
+			if err != nil {
+				return nil, fmt.Errorf(`"jwtExpiredUnverified" function: %v`, err)
+			}
+			return NewValue(expired), nil
+		},
+		NonDeterministic: true,
+	},
+	"urlparse": {
+		Description: "parses the URL into the map of its components, the query is a map of lists",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			str, _ := value[0].StringValue()
+			components, err := parseURL(str)
+			if err != nil {
+				return nil, fmt.Errorf(`"urlparse" function: %v`, err)
+			}
+			return NewValue(components), nil
+		},
+	},
+	"urlencode": {
+		Description: "escapes the string for the URL query component, the space is %20",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			str, _ := value[0].StringValue()
+			return NewValue(encodeURLComponent(str)), nil
+		},
+	},
+	"urldecode": {
+		Description: "unescapes the URL query component",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			str, _ := value[0].StringValue()
+			decoded, err := decodeURLComponent(str)
+			if err != nil {
+				return nil, fmt.Errorf(`"urldecode" function: %q: %v`, str, err)
+			}
+			return NewValue(decoded), nil
+		},
+	},
+	"urljoin": {
+		Description: "resolves the relative reference against the base URL",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			base, _ := value[0].StringValue()
+			ref, _ := value[1].StringValue()
+			str, err := joinURL(base, ref)
+			if err != nil {
+				return nil, fmt.Errorf(`"urljoin" function: %v`, err)
+			}
+			return NewValue(str), nil
+		},
+	},
+	"urlbuild": {
+		Description: "builds the URL from the map of its components",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			components, err := value[0].MapValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"urlbuild" function expects a map of URL components, %s provided: %v`, value[0], err)
+			}
+			str, err := buildURL(components)
+			if err != nil {
+				return nil, fmt.Errorf(`"urlbuild" function: %v`, err)
+			}
+			return NewValue(str), nil
+		},
+	},
+	"parseKeyValue": {
+		Description: "parses the key-value pairs into the map, with the optional pair and key-value separators",
+		MinArgs:     1,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeString, TypeString, TypeString},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			str, _ := value[0].StringValue()
+			pairSep, kvSep := defaultPairSeparator, defaultKeyValueSeparator
+			if len(value) > 1 {
+				pairSep, _ = value[1].StringValue()
+			}
+			if len(value) > 2 {
+				kvSep, _ = value[2].StringValue()
+			}
+			result, err := parseKeyValue(str, pairSep, kvSep)
+			if err != nil {
+				return nil, fmt.Errorf(`"parseKeyValue" function: %v`, err)
+			}
+			return NewValue(result), nil
+		},
+	},
+	"toKeyValue": {
+		Description: "serializes the map into the key-value pairs, with the optional pair and key-value separators",
+		MinArgs:     1,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeUnknown, TypeString, TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			m, err := value[0].MapValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"toKeyValue" function expects a map, %s provided: %v`, value[0], err)
+			}
+			pairSep, kvSep := defaultPairSeparator, defaultKeyValueSeparator
+			if len(value) > 1 {
+				pairSep, _ = value[1].StringValue()
+			}
+			if len(value) > 2 {
+				kvSep, _ = value[2].StringValue()
+			}
+			str, err := buildKeyValue(m, pairSep, kvSep)
+			if err != nil {
+				return nil, fmt.Errorf(`"toKeyValue" function: %v`, err)
+			}
+			return NewValue(str), nil
+		},
+	},
+	"diffText": {
+		Description: "unified diff of the texts, with the optional number of context lines",
+		MinArgs:     2,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeString, TypeString, TypeInt64},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			oldText, _ := value[0].StringValue()
+			newText, _ := value[1].StringValue()
+			context := int64(defaultDiffContext)
+			if len(value) == 3 {
+				var err error
+				context, err = value[2].IntValue()
+				if err != nil || context < 0 {
+					return nil, fmt.Errorf(`"diffText" function expects context to be a non-negative number of lines, %s provided`, value[2])
+				}
+			}
+			return NewValue(diffText(oldText, newText, int(context))), nil
+		},
+	},
+	"diffStat": {
+		Description: "numbers of the added and removed lines between the texts",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			oldText, _ := value[0].StringValue()
+			newText, _ := value[1].StringValue()
+			return NewValue(diffStat(oldText, newText)), nil
+		},
+	},
+	"changedPaths": {
+		Description: "paths of the values that differ between the values",
+		MinArgs:     2,
+		MaxArgs:     2,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			paths := ChangedPaths(value[0].Value(), value[1].Value())
+			result := make([]interface{}, len(paths))
+			for i := range paths {
+				result[i] = paths[i]
+			}
+			return NewValue(result), nil
+		},
+	},
+
+	"paths": {
+		Description: "paths of all the leaf values",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			result, err := leafPaths(value[0].Value())
+			if err != nil {
+				return nil, fmt.Errorf(`"paths" function: %v`, err)
+			}
+			return NewValue(result), nil
+		},
+	},
+	"findPaths": {
+		Description: "paths of the values stored under the key",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeUnknown, TypeString},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			key, _ := value[1].StringValue()
+			result, err := findPaths(value[0].Value(), key)
+			if err != nil {
+				return nil, fmt.Errorf(`"findPaths" function: %v`, err)
+			}
+			return NewValue(result), nil
+		},
+	},
+	"shard": {
+		Description: "stable shard index of the key, for the total number of shards",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeInt64},
+		Pure:        true,
+		ReturnType:  TypeInt64,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			key, _ := value[0].StringValue()
+			total, err := value[1].IntValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"shard" function expects total shards to be an integer: %v`, err)
+			}
+			if err = validateShards(total); err != nil {
+				return nil, fmt.Errorf(`"shard" function: %v`, err)
+			}
+			return NewValue(shardOf(key, total)), nil
+		},
+	},
+	"shardList": {
+		Description: "items of the list assigned to the shard index, for the total number of shards",
+		MinArgs:     3,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeUnknown, TypeInt64, TypeInt64},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			items, err := value[0].SliceValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"shardList" function expects a list as 1st argument: %v`, err)
+			}
+			total, err := value[1].IntValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"shardList" function expects total shards to be an integer: %v`, err)
+			}
+			index, err := value[2].IntValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"shardList" function expects shard index to be an integer: %v`, err)
+			}
+			result, err := shardList(items, total, index)
+			if err != nil {
+				return nil, fmt.Errorf(`"shardList" function: %v`, err)
+			}
+			return NewValue(result), nil
+		},
+	},
+	"bucket": {
+		Description: "stable bucket of the key, for the number of buckets",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeInt64},
+		Pure:        true,
+		ReturnType:  TypeInt64,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			key, _ := value[0].StringValue()
+			total, err := value[1].IntValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"bucket" function expects number of buckets to be an integer: %v`, err)
+			}
+			if total < 1 {
+				return nil, fmt.Errorf(`"bucket" function: number of buckets should be at least 1, %d provided`, total)
+			}
+			return NewValue(shardOf(key, total)), nil
+		},
+	},
+	"sampleRate": {
+		Description: "tells if the key is sampled with the rate between 0 and 1",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeFloat64},
+		Pure:        true,
+		ReturnType:  TypeBool,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			key, _ := value[0].StringValue()
+			rate, err := value[1].FloatValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"sampleRate" function expects rate to be a number: %v`, err)
+			}
+			if err = validateSampleRate(rate); err != nil {
+				return nil, fmt.Errorf(`"sampleRate" function: %v`, err)
+			}
+			return NewValue(sampled(key, rate)), nil
+		},
+	},
+	"summarize": {
+		Description: "shortens the text to the maximum bytes, keeping the optional ratio of its beginning",
+		MinArgs:     2,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeString, TypeInt64, TypeFloat64},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			text, _ := value[0].StringValue()
+			maxBytes, err := value[1].IntValue()
+			if err != nil || maxBytes < 0 {
+				return nil, fmt.Errorf(`"summarize" function expects maximum bytes to be a non-negative integer, %s provided`, value[1])
+			}
+			headRatio := defaultSummarizeHeadRatio
+			if len(value) == 3 {
+				headRatio, err = value[2].FloatValue()
+				if err != nil || headRatio < 0 || headRatio > 1 {
+					return nil, fmt.Errorf(`"summarize" function expects ratio of the beginning to be between 0 and 1, %s provided`, value[2])
+				}
+			}
+			str, err := summarize(text, int(maxBytes), headRatio)
+			if err != nil {
+				return nil, fmt.Errorf(`"summarize" function: %v`, err)
+			}
+			return NewValue(str), nil
+		},
+	},
+	"isDNSLabel":     dnsStdFunction("isDNSLabel", "tells if the string is a valid DNS label", isDNSLabel),
+	"isDNSSubdomain": dnsStdFunction("isDNSSubdomain", "tells if the string is a valid DNS subdomain", isDNSSubdomain),
+	"toDNSLabel": {
+		Description: "converts the string into a valid DNS label",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		StrictArgs:  []int{0},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if !value[0].IsString() {
+				return nil, fmt.Errorf(`"toDNSLabel" function argument should be a string`)
+			}
+			str, _ := value[0].StringValue()
+			label, err := toDNSLabel(str)
+			if err != nil {
+				return nil, fmt.Errorf(`"toDNSLabel" function: %v`, err)
+			}
+			return NewValue(label), nil
+		},
+	},
+	"validateSchema": {
+		Description: "fails when the value doesn't match the JSON schema",
+		MinArgs:     2,
+		MaxArgs:     2,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			violations, err := schemaViolations("validateSchema", value...)
+			if err != nil {
+				return nil, err
+			}
+			if len(violations) > 0 {
+				return nil, fmt.Errorf(`"validateSchema" function: value doesn't match the schema: %s`, strings.Join(violations, "; "))
+			}
+			return None, nil
+		},
+	},
+	"matchesSchema": {
+		Description: "tells if the value matches the JSON schema",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ReturnType:  TypeBool,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			violations, err := schemaViolations("matchesSchema", value...)
+			if err != nil {
+				return nil, err
+			}
+			return NewValue(len(violations) == 0), nil
+		},
+	},
+	"uniqueBy":     keyedListStdFunction("uniqueBy", "list without the items with duplicated key built with the expression", true, uniqueByKey),
+	"duplicates":   keyedListStdFunction("duplicates", "the duplicated items of the list", false, duplicatesByKey),
+	"duplicatesBy": keyedListStdFunction("duplicatesBy", "the items of the list with duplicated key built with the expression", true, duplicatesByKey),
+
+	"between":          betweenStdFunction("between", "tells if the value is within the range, including the boundaries", true),
+	"betweenExclusive": betweenStdFunction("betweenExclusive", "tells if the value is within the range, excluding the boundaries", false),
+
+	"redactPatterns": {
+		Description: "redacts the secrets and the optional list of patterns in the text",
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeUnknown},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			text, patterns, err := redactArgs("redactPatterns", value...)
+			if err != nil {
+				return nil, err
+			}
+			text, _ = redactText(text, patterns)
+			return NewValue(text), nil
+		},
+	},
+	"redactCount": {
+		Description: "number of the secrets and the optional list of patterns redacted in the text",
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeUnknown},
+		Pure:        true,
+		ReturnType:  TypeInt64,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			text, patterns, err := redactArgs("redactCount", value...)
+			if err != nil {
+				return nil, err
+			}
+			_, count := redactText(text, patterns)
+			return NewValue(int64(count)), nil
+		},
+	},
+
+	"regexMatch": {
+		Description: "tells if the string matches the pattern",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		Pure:        true,
+		ReturnType:  TypeBool,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			re, str, err := regexArgs("regexMatch", 2, value...)
+			if err != nil {
+				return nil, err
+			}
+			return NewValue(re.MatchString(str)), nil
+		},
+	},
+	"regexFind": {
+		Description: "first match of the pattern in the string, or null",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			re, str, err := regexArgs("regexFind", 2, value...)
 			if err != nil {
-				return nil, fmt.Errorf(`"filter" function expects 2nd argument to be valid expression, '%s' provided: %v`, value[1], err)
+				return nil, err
 			}
-			result := make([]interface{}, 0)
-			for i := 0; i < len(list); i++ {
-				ex, _ := Compile(expr.String())
-				v, err := ex.Resolve(NewMachine().Register("_.value", list[i]).Register("_.index", i).Register("_.key", i))
+			loc := re.FindStringIndex(str)
+			if loc == nil {
+				return None, nil
+			}
+			return NewValue(str[loc[0]:loc[1]]), nil
+		},
+	},
+	"regexFindAll": {
+		Description: "all matches of the pattern in the string",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			re, str, err := regexArgs("regexFindAll", 2, value...)
+			if err != nil {
+				return nil, err
+			}
+			matches := re.FindAllString(str, capLimit(SplitMaxParts))
+			if SplitMaxParts > 0 && len(matches) > SplitMaxParts {
+				return nil, fmt.Errorf(`"regexFindAll" function: result exceeds %d matches`, SplitMaxParts)
+			}
+			if matches == nil {
+				matches = []string{}
+			}
+			return NewValue(matches), nil
+		},
+	},
+	"regexReplace": {
+		Description: "replaces the matches of the pattern in the string",
+		MinArgs:     3,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeString, TypeString, TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			re, str, err := regexArgs("regexReplace", 3, value...)
+			if err != nil {
+				return nil, err
+			}
+			replacement, err := value[1].StringValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"regexReplace" function: replacement: %v`, err)
+			}
+			return NewValue(re.ReplaceAllString(str, replacement)), nil
+		},
+	},
+
+	"now": {
+		Description: "current time in RFC 3339 format",
+		MinArgs:     0,
+		MaxArgs:     0,
+		ReturnType:  TypeString,
+		ClockHandler: func(now time.Time, value ...StaticValue) (Expression, error) {
+			return NewValue(now.UTC().Format(time.RFC3339)), nil
+		},
+		NonDeterministic: true,
+	},
+	"date": {
+		Description: "current time formatted with the layout",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		ReturnType:  TypeString,
+		ClockHandler: func(now time.Time, value ...StaticValue) (Expression, error) {
+			layout, _ := value[0].StringValue()
+			layout, err := dateLayout(layout)
+			if err != nil {
+				return nil, fmt.Errorf(`"date" function: %v`, err)
+			}
+			return NewValue(now.UTC().Format(layout)), nil
+		},
+		NonDeterministic: true,
+	},
+	"formatDate": {
+		Description: "formats the time with the layout",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeUnknown, TypeString},
+		ReturnType:  TypeString,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			t, err := toTime(value[0])
+			if err != nil {
+				return nil, fmt.Errorf(`"formatDate" function: %v`, err)
+			}
+			layout, _ := value[1].StringValue()
+			layout, err = dateLayout(layout)
+			if err != nil {
+				return nil, fmt.Errorf(`"formatDate" function: %v`, err)
+			}
+			return NewValue(t.UTC().Format(layout)), nil
+		},
+	},
+	"parseDate": {
+		Description: "unix time of the date parsed with the layout",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		ReturnType:  TypeInt64,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			str, _ := value[0].StringValue()
+			layout, _ := value[1].StringValue()
+			layout, err := dateLayout(layout)
+			if err != nil {
+				return nil, fmt.Errorf(`"parseDate" function: %v`, err)
+			}
+			t, err := parseDateUnix(str, layout)
+			if err != nil {
+				return nil, fmt.Errorf(`"parseDate" function: %v`, err)
+			}
+			return NewValue(t), nil
+		},
+	},
+	"tryDate": {
+		Description: "unix time of the date parsed with the layout, RFC 3339 by default, or null when it's malformed",
+		MinArgs:     1,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		StrictArgs:  []int{0},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if !value[0].IsString() {
+				return nil, fmt.Errorf(`"tryDate" function argument should be a string`)
+			}
+			str, _ := value[0].StringValue()
+			layout := time.RFC3339Nano
+			if len(value) == 2 {
+				var err error
+				layout, _ = value[1].StringValue()
+				layout, err = dateLayout(layout)
 				if err != nil {
-					return nil, fmt.Errorf(`"filter" function: error while filtering %d index (%v): %v`, i, list[i], err)
-				}
-				if v.Static() == nil {
-					// TODO: It shouldn't fail then
-					return nil, fmt.Errorf(`"filter" function: could not resolve filter for %d index (%v): %s`, i, list[i], v)
+					return nil, fmt.Errorf(`"tryDate" function: %v`, err)
 				}
-				b, err := v.Static().BoolValue()
+			}
+			t, err := parseDateUnix(str, layout)
+			if err != nil {
+				return None, nil
+			}
+			return NewValue(t), nil
+		},
+	},
+	"duration": {
+		Description: "number of seconds in the duration, like 1h30m",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		ReturnType:  TypeInt64,
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			str, _ := value[0].StringValue()
+			d, err := time.ParseDuration(str)
+			if err != nil {
+				return nil, fmt.Errorf(`"duration" function: %v`, err)
+			}
+			return NewValue(int64(d / time.Second)), nil
+		},
+	},
+
+	"semverCompare": {
+		Description: "compares the semantic versions, resolves to -1, 0 or 1",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		Pure:        true,
+		ReturnType:  TypeInt64,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			versions := make([]*semver.Version, 2)
+			for i := range value {
+				str, _ := value[i].StringValue()
+				v, err := parseSemver(str)
 				if err != nil {
-					return nil, fmt.Errorf(`"filter" function: could not resolve filter for %d index (%v) as boolean: %s`, i, list[i], err)
-				}
-				if b {
-					result = append(result, list[i])
+					return nil, fmt.Errorf(`"semverCompare" function: %v`, err)
 				}
+				versions[i] = v
 			}
-			return NewValue(result), nil
+			return NewValue(int64(versions[0].Compare(versions[1]))), nil
 		},
 	},
-	"eval": {
+	"semverSatisfies": {
+		Description: "tells if the semantic version satisfies the constraint",
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		Pure:        true,
+		ReturnType:  TypeBool,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 1 {
-				return nil, fmt.Errorf(`"eval" function expects 1 argument, %d provided`, len(value))
+			str, _ := value[0].StringValue()
+			v, err := parseSemver(str)
+			if err != nil {
+				return nil, fmt.Errorf(`"semverSatisfies" function: %v`, err)
 			}
-			exprStr, _ := value[0].StringValue()
-			expr, err := Compile(exprStr)
+			constraintStr, _ := value[1].StringValue()
+			constraint, err := parseSemverConstraint(constraintStr)
 			if err != nil {
-				return nil, fmt.Errorf(`"eval" function: %s: error: %v`, value[0], err)
+				return nil, fmt.Errorf(`"semverSatisfies" function: %v`, err)
 			}
-			return expr, nil
+			return NewValue(constraint.Check(v)), nil
 		},
 	},
-	"jq": {
+	"semverParse": {
+		Description: "parses the semantic version into the map of its parts",
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
 		Handler: func(value ...StaticValue) (Expression, error) {
-			if len(value) != 2 {
-				return nil, fmt.Errorf(`"jq" function expects 2 arguments, %d provided`, len(value))
-			}
-			queryStr, _ := value[1].StringValue()
-			query, err := gojq.Parse(queryStr)
+			str, _ := value[0].StringValue()
+			v, err := parseSemver(str)
 			if err != nil {
-				return nil, fmt.Errorf(`"jq" error: could not parse the query: %s: %v`, queryStr, err)
+				return nil, fmt.Errorf(`"semverParse" function: %v`, err)
 			}
+			return NewValue(semverToMap(v)), nil
+		},
+	},
+}
 
-			// Marshal data to basic types
-			bytes, err := json.Marshal(value[0].Value())
+// pathStdFunction creates function transforming single slash-separated path, regardless of the host OS
+func pathStdFunction(name, description string, fn func(string) string) StdFunction {
+	return StdFunction{
+		Description: description,
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			v, err := value[0].StringValue()
 			if err != nil {
-				return nil, fmt.Errorf(`"jq" error: could not marshal the value: %v: %v`, value[0].Value(), err)
+				return nil, fmt.Errorf(`"%s" function expects a string, %s provided: %v`, name, value[0], err)
 			}
-			var v interface{}
-			_ = json.Unmarshal(bytes, &v)
+			return NewValue(fn(v)), nil
+		},
+	}
+}
 
-			// Run query against the value
-			ctx, ctxCancel := context.WithTimeout(context.Background(), 10*time.Second)
-			defer ctxCancel()
-			iter := query.RunWithContext(ctx, v)
-			result := make([]interface{}, 0)
-			for {
-				v, ok := iter.Next()
-				if !ok {
-					break
+// whitespaceStdFunction builds a function cleaning up whitespace of the string, like "trim" it doesn't accept other types
+func whitespaceStdFunction(name, description string, fn func(string) string) StdFunction {
+	return StdFunction{
+		Description: description,
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		StrictArgs:  []int{0},
+		ReturnType:  TypeString,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if !value[0].IsString() {
+				return nil, fmt.Errorf(`"%s" function argument should be a string`, name)
+			}
+			str, _ := value[0].StringValue()
+			return NewValue(fn(str)), nil
+		},
+	}
+}
+
+// SplitMaxParts is a maximum number of results produced by "split", "splitFirst" and "regexFindAll",
+// exceeding it is an error rather than a silent truncation
+var SplitMaxParts = 100_000
+
+// capLimit converts the result cap to the limit accepted by strings.SplitN and regexp's FindAll,
+// one more than the cap, so the excess is detected without processing the rest of the input
+func capLimit(limit int) int {
+	if limit <= 0 {
+		return -1
+	}
+	return limit + 1
+}
+
+// RangeMaxLength is a maximum number of items produced by "range", exceeding it is an error
+var RangeMaxLength = 1_000_000
+
+// intRange builds the list from start to end, excluding the end, with the step that may be negative to count down
+func intRange(start, end, step int64) ([]interface{}, error) {
+	if step == 0 {
+		return nil, errors.New("step can't be zero")
+	}
+	// Count in float first, so the extreme bounds can't overflow
+	count := math2.Ceil((float64(end) - float64(start)) / float64(step))
+	if count <= 0 {
+		return []interface{}{}, nil
+	}
+	if count > float64(RangeMaxLength) {
+		return nil, fmt.Errorf("result exceeds %d items", RangeMaxLength)
+	}
+	result := make([]interface{}, 0, int(count))
+	for i := int64(0); i < int64(count); i++ {
+		result = append(result, start+i*step)
+	}
+	return result, nil
+}
+
+// maxRepeatLength limits the strings built by "repeat" and padding functions, so a single call can't exhaust the memory
+const maxRepeatLength = 10 << 20
+
+// stringStdFunction builds a function transforming the string, other values are converted like with "string" function
+func stringStdFunction(name, description string, fn func(string) string) StdFunction {
+	return StdFunction{
+		Description: description,
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		FeaturesHandler: func(features Features, value ...StaticValue) (Expression, error) {
+			str, _ := toStringWith(features, value[0].Value())
+			return NewValue(fn(str)), nil
+		},
+	}
+}
+
+// stringPredicateStdFunction builds a predicate checking the string against the other one,
+// both values are converted like with "string" function
+func stringPredicateStdFunction(name, description string, fn func(string, string) bool) StdFunction {
+	return StdFunction{
+		Description: description,
+		MinArgs:     2,
+		MaxArgs:     2,
+		ArgTypes:    []Type{TypeString, TypeString},
+		Pure:        true,
+		ReturnType:  TypeBool,
+		FeaturesHandler: func(features Features, value ...StaticValue) (Expression, error) {
+			str, _ := toStringWith(features, value[0].Value())
+			other, _ := toStringWith(features, value[1].Value())
+			return NewValue(fn(str, other)), nil
+		},
+	}
+}
+
+// padStdFunction builds a function padding the string to the length in characters, with space or the provided character
+func padStdFunction(name, description string, left bool) StdFunction {
+	return StdFunction{
+		Description: description,
+		MinArgs:     2,
+		MaxArgs:     3,
+		ArgTypes:    []Type{TypeString, TypeInt64, TypeString},
+		Pure:        true,
+		ReturnType:  TypeString,
+		FeaturesHandler: func(features Features, value ...StaticValue) (Expression, error) {
+			str, _ := toStringWith(features, value[0].Value())
+			length, err := value[1].IntValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"%s" function expects 2nd argument to be integer, %s provided: %v`, name, value[1], err)
+			}
+			if length > maxRepeatLength {
+				return nil, fmt.Errorf(`"%s" function: result would exceed %d bytes`, name, maxRepeatLength)
+			}
+			pad := " "
+			if len(value) == 3 {
+				pad, _ = toStringWith(features, value[2].Value())
+				if utf8.RuneCountInString(pad) != 1 {
+					return nil, fmt.Errorf(`"%s" function expects 3rd argument to be a single character, %s provided`, name, value[2])
 				}
-				if err, ok := v.(error); ok {
-					return nil, errors.Wrap(err, `"jq" error: executing: %v`)
+			}
+			missing := int(length) - utf8.RuneCountInString(str)
+			if missing <= 0 {
+				return NewValue(str), nil
+			}
+			if left {
+				return NewValue(strings.Repeat(pad, missing) + str), nil
+			}
+			return NewValue(str + strings.Repeat(pad, missing)), nil
+		},
+	}
+}
+
+// dnsStdFunction builds a predicate checking if the string is a valid DNS name
+func dnsStdFunction(name, description string, fn func(string) bool) StdFunction {
+	return StdFunction{
+		Description: description,
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{TypeString},
+		StrictArgs:  []int{0},
+		ReturnType:  TypeBool,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if !value[0].IsString() {
+				return nil, fmt.Errorf(`"%s" function argument should be a string`, name)
+			}
+			str, _ := value[0].StringValue()
+			return NewValue(fn(str)), nil
+		},
+	}
+}
+
+// betweenStdFunction builds a predicate checking if the value is within the range, including its boundaries or not
+func betweenStdFunction(name, description string, inclusive bool) StdFunction {
+	return StdFunction{
+		Description: description,
+		MinArgs:     3,
+		MaxArgs:     3,
+		Pure:        true,
+		ReturnType:  TypeBool,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			toLow, toHigh, err := compareInRange(value[0], value[1], value[2])
+			if err != nil {
+				return nil, fmt.Errorf(`"%s" function: %v`, name, err)
+			}
+			if inclusive {
+				return NewValue(toLow >= 0 && toHigh <= 0), nil
+			}
+			return NewValue(toLow > 0 && toHigh < 0), nil
+		},
+	}
+}
+
+// keyedListStdFunction builds a function processing the list items by their keys,
+// the keys are built with the key expression passed as the second argument, or from the items themselves
+func keyedListStdFunction(name, description string, withExpression bool, fn func(list []interface{}, keys []string) []interface{}) StdFunction {
+	args, argTypes := 1, []Type{TypeUnknown}
+	if withExpression {
+		args, argTypes = 2, []Type{TypeUnknown, TypeString}
+	}
+	return StdFunction{
+		Description: description,
+		MinArgs:     args,
+		MaxArgs:     args,
+		ArgTypes:    argTypes,
+		// The key expression may use any function
+		Pure: !withExpression,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			list, err := value[0].SliceValue()
+			if err != nil {
+				return nil, fmt.Errorf(`"%s" function expects 1st argument to be a list, %s provided: %v`, name, value[0], err)
+			}
+			var expr Expression
+			if withExpression {
+				exprStr, _ := value[1].StringValue()
+				expr, err = Compile(exprStr)
+				if err != nil {
+					return nil, fmt.Errorf(`"%s" function expects 2nd argument to be valid expression, '%s' provided: %v`, name, value[1], err)
 				}
-				result = append(result, v)
 			}
-			return NewValue(result), nil
+			keys, err := itemKeys(list, expr)
+			if err != nil {
+				return nil, fmt.Errorf(`"%s" function: %v`, name, err)
+			}
+			return NewValue(fn(list, keys)), nil
 		},
-	},
+	}
+}
+
+// schemaViolations validates the value against the schema passed as the second argument
+func schemaViolations(name string, value ...StaticValue) ([]string, error) {
+	schema, err := parseSchema(value[1])
+	if err != nil {
+		return nil, fmt.Errorf(`"%s" function: %v`, name, err)
+	}
+	violations, err := validateSchema(value[0].Value(), schema)
+	if err != nil {
+		return nil, fmt.Errorf(`"%s" function: %v`, name, err)
+	}
+	return violations, nil
+}
+
+// regexArgs compiles the pattern passed as the first argument, and reads the string passed as the last one
+func regexArgs(name string, args int, value ...StaticValue) (*regexp.Regexp, string, error) {
+	pattern, err := value[0].StringValue()
+	if err != nil {
+		return nil, "", fmt.Errorf(`"%s" function: pattern: %v`, name, err)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, "", fmt.Errorf(`"%s" function: invalid pattern: %v`, name, err)
+	}
+	str, err := value[args-1].StringValue()
+	if err != nil {
+		return nil, "", fmt.Errorf(`"%s" function: %v`, name, err)
+	}
+	return re, str, nil
+}
+
+// redactArgs reads the text and the optional list of additional patterns to redact
+func redactArgs(name string, value ...StaticValue) (string, []*regexp.Regexp, error) {
+	text, err := value[0].StringValue()
+	if err != nil {
+		return "", nil, fmt.Errorf(`"%s" function: %v`, name, err)
+	}
+	if len(value) == 1 || value[1].IsNone() {
+		return text, nil, nil
+	}
+	list, err := value[1].SliceValue()
+	if err != nil {
+		return "", nil, fmt.Errorf(`"%s" function expects 2nd argument to be a list, %s provided: %v`, name, value[1], err)
+	}
+	sources := make([]string, len(list))
+	for i := range list {
+		sources[i], err = toString(list[i])
+		if err != nil {
+			return "", nil, fmt.Errorf(`"%s" function: pattern at %d index: %v`, name, i, err)
+		}
+	}
+	patterns, err := compileRedactPatterns(sources)
+	if err != nil {
+		return "", nil, fmt.Errorf(`"%s" function: %v`, name, err)
+	}
+	return text, patterns, nil
+}
+
+// dirname returns all but the last path element, trailing slashes don't create an empty last element
+func dirname(p string) string {
+	trimmed := strings.TrimRight(p, "/")
+	if trimmed == "" && p != "" {
+		return "/"
+	}
+	return path.Dir(trimmed)
+}
+
+// ext returns the extension of the last path element, hidden files without another dot have no extension
+func ext(p string) string {
+	base := strings.TrimLeft(path.Base(p), ".")
+	if base == "" {
+		return ""
+	}
+	return path.Ext(base)
 }
 
 const (
@@ -468,21 +2533,45 @@ const (
 	boolCastStdFn   = "bool"
 	intCastStdFn    = "int"
 	floatCastStdFn  = "float"
+	listCastStdFn   = "toList"
+	mapCastStdFn    = "toMap"
 )
 
+// The cast helpers convert the static values right away, and wrap the other expressions with the cast function,
+// unless they are known to have the type already; the static values that can't be converted are wrapped too,
+// so the resolution fails with the conversion error
+
 func CastToString(v Expression) Expression {
+	return castToString(nil, v)
+}
+
+func castToString(features Features, v Expression) Expression {
 	if v.Static() != nil {
-		return NewStringValue(v.Static().Value())
+		return NewValue(castStaticToString(features, v.Static()))
 	} else if v.Type() == TypeString {
 		return v
 	}
 	return newCall(stringCastStdFn, []callArgument{{expr: v}})
 }
 
+// castStaticToString converts the value to string, none is an empty string instead of "null"
+func castStaticToString(features Features, v StaticValue) string {
+	if v.IsNone() || v.Value() == nil {
+		return ""
+	}
+	str, _ := toStringWith(features, v.Value())
+	return str
+}
+
 func CastToBool(v Expression) Expression {
 	if v.Type() == TypeBool {
 		return v
 	}
+	if v.Static() != nil {
+		if b, err := v.Static().BoolValue(); err == nil {
+			return NewValue(b)
+		}
+	}
 	return newCall(boolCastStdFn, []callArgument{{expr: v}})
 }
 
@@ -490,6 +2579,11 @@ func CastToInt(v Expression) Expression {
 	if v.Type() == TypeInt64 {
 		return v
 	}
+	if v.Static() != nil {
+		if i, err := v.Static().IntValue(); err == nil {
+			return NewValue(i)
+		}
+	}
 	return newCall(intCastStdFn, []callArgument{{expr: v}})
 }
 
@@ -497,20 +2591,116 @@ func CastToFloat(v Expression) Expression {
 	if v.Type() == TypeFloat64 {
 		return v
 	}
-	return newCall(intCastStdFn, []callArgument{{expr: v}})
+	if v.Static() != nil {
+		if f, err := v.Static().FloatValue(); err == nil {
+			return NewValue(f)
+		}
+	}
+	return newCall(floatCastStdFn, []callArgument{{expr: v}})
+}
+
+// CastToSlice converts the expression to list, see "toList" function
+func CastToSlice(v Expression) Expression {
+	if v.Static() != nil {
+		if _, ok := v.Static().Value().([]interface{}); ok {
+			return v
+		}
+		if list, err := toListValue(v.Static()); err == nil {
+			return NewValue(list)
+		}
+	}
+	return newCall(listCastStdFn, []callArgument{{expr: v}})
+}
+
+// CastToMap converts the expression to map, see "toMap" function
+func CastToMap(v Expression) Expression {
+	if v.Static() != nil {
+		if _, ok := v.Static().Value().(map[string]interface{}); ok {
+			return v
+		}
+		if m, err := toMapValue(v.Static()); err == nil {
+			return NewValue(m)
+		}
+	}
+	return newCall(mapCastStdFn, []callArgument{{expr: v}})
+}
+
+// parseJSONLike parses the string that looks like JSON document starting with the character
+func parseJSONLike(v StaticValue, start string) (interface{}, bool, error) {
+	if !v.IsString() {
+		return nil, false, nil
+	}
+	str, _ := v.StringValue()
+	str = strings.TrimSpace(str)
+	if !strings.HasPrefix(str, start) {
+		return nil, false, nil
+	}
+	parsed, err := parseJSON(str)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid JSON: %v", err)
+	}
+	return parsed, true, nil
+}
+
+func toListValue(v StaticValue) ([]interface{}, error) {
+	if v.IsNone() || v.Value() == nil {
+		return []interface{}{}, nil
+	}
+	if parsed, ok, err := parseJSONLike(v, "["); ok {
+		if err != nil {
+			return nil, err
+		}
+		return toSlice(parsed)
+	}
+	if v.IsSlice() {
+		return v.SliceValue()
+	}
+	if v.IsMap() {
+		return nil, fmt.Errorf("map can't be converted to list: %s", v)
+	}
+	return []interface{}{v.Value()}, nil
+}
+
+func toMapValue(v StaticValue) (map[string]interface{}, error) {
+	if v.IsNone() || v.Value() == nil {
+		return map[string]interface{}{}, nil
+	}
+	if parsed, ok, err := parseJSONLike(v, "{"); ok {
+		if err != nil {
+			return nil, err
+		}
+		return toMap(parsed)
+	}
+	if v.IsMap() || v.IsSlice() {
+		return v.MapValue()
+	}
+	return nil, fmt.Errorf("value can't be converted to map: %s", v)
+}
+
+// isNoneOrEmptyString tells if the value is missing for the coalesce and default functions
+func isNoneOrEmptyString(value StaticValue) bool {
+	if value.IsNone() {
+		return true
+	}
+	if !value.IsString() {
+		return false
+	}
+	str, _ := value.StringValue()
+	return str == ""
 }
 
 func IsStdFunction(name string) bool {
-	_, ok := stdFunctions[name]
+	_, ok := getStdFunction(name)
 	return ok
 }
 
 func GetStdFunctionReturnType(name string) Type {
-	return stdFunctions[name].ReturnType
+	fn, _ := getStdFunction(name)
+	return fn.ReturnType
 }
 
 func CallStdFunction(name string, value ...interface{}) (Expression, error) {
-	fn, ok := stdFunctions[name]
+	fn, ok := getStdFunction(name)
 	if !ok {
 		return nil, fmt.Errorf("function '%s' doesn't exists in standard library", name)
 	}
@@ -524,7 +2714,10 @@ func CallStdFunction(name string, value ...interface{}) (Expression, error) {
 			r = append(r, NewValue(value[i]))
 		}
 	}
-	return fn.Handler(r...)
+	if err := fn.checkArgs(name, len(r)); err != nil {
+		return nil, err
+	}
+	return fn.callCached(nil, timeNow, nil, name, r...)
 }
 
 func (*stdMachine) Get(name string) (Expression, bool, error) {
@@ -532,9 +2725,26 @@ func (*stdMachine) Get(name string) (Expression, bool, error) {
 }
 
 func (*stdMachine) Call(name string, args ...StaticValue) (Expression, bool, error) {
-	fn, ok := stdFunctions[name]
+	return callStdFunction(nil, timeNow, nil, name, args...)
+}
+
+// callStdFunction calls the standard library function with the features, the clock and the machines of the resolution
+func callStdFunction(features Features, now func() time.Time, machines []Machine, name string, args ...StaticValue) (Expression, bool, error) {
+	fn, ok := getStdFunction(name)
+	if ok && fn.ClockHandler != nil && now == nil {
+		return nil, false, nil
+	}
+	if ok && fn.RandomHandler != nil && resolveRandom(machines) == nil {
+		return nil, false, nil
+	}
 	if ok {
-		exp, err := fn.Handler(args...)
+		if err := fn.checkArgs(name, len(args)); err != nil {
+			return nil, true, err
+		}
+		exp, err := fn.callCached(features, now, machines, name, args...)
+		if errors.Is(err, errNotResolvedYet) {
+			return nil, false, nil
+		}
 		return exp, true, err
 	}
 	return nil, false, nil
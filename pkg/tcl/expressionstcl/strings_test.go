@@ -0,0 +1,174 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringFunctions(t *testing.T) {
+	tests := map[string]interface{}{
+		`lower("Hello ŻÓŁW")`:            "hello żółw",
+		`upper("Hello żółw")`:            "HELLO ŻÓŁW",
+		`lower(true)`:                    "true",
+		`upper("")`:                      "",
+		`contains("testkube", "kube")`:   true,
+		`contains("testkube", "Kube")`:   false,
+		`contains(5500, "55")`:           true,
+		`contains("abc", "")`:            true,
+		`contains("", "")`:               true,
+		`startsWith("testkube", "test")`: true,
+		`startsWith("testkube", "kube")`: false,
+		`startsWith(1.5, 1)`:             true,
+		`endsWith("testkube", "kube")`:   true,
+		`endsWith("testkube", "")`:       true,
+		`replace("a-b-c", "-", "_")`:     "a_b_c",
+		`replace("a-b-c", "", ".")`:      ".a.-.b.-.c.",
+		`replace("a-b-c", "x", "y")`:     "a-b-c",
+		`replace(1000, 0, 1)`:            "1111",
+		`replace("aaa", "a", "")`:        "",
+		`padLeft("7", 3, "0")`:           "007",
+		`padLeft(7, 3)`:                  "  7",
+		`padLeft("żółw", 6, "·")`:        "··żółw",
+		`padLeft("abc", 2, "0")`:         "abc",
+		`padLeft("abc", 0)`:              "abc",
+		`padLeft("", 0)`:                 "",
+		`padRight("ab", 4, "-")`:         "ab--",
+		`padRight("ab", -1)`:             "ab",
+		`repeat("ab", 3)`:                "ababab",
+		`repeat("ab", 0)`:                "",
+		`repeat("", 5)`:                  "",
+		`repeat(1, 2)`:                   "11",
+	}
+	for expr, expected := range tests {
+		v, err := EvalString(expr, nil)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+}
+
+func TestStringFunctionsReturnType(t *testing.T) {
+	for _, name := range []string{"contains", "startsWith", "endsWith"} {
+		assert.Equal(t, TypeBool, GetStdFunctionReturnType(name), name)
+	}
+	for _, name := range []string{"lower", "upper", "replace", "padLeft", "padRight", "repeat"} {
+		assert.Equal(t, TypeString, GetStdFunctionReturnType(name), name)
+	}
+}
+
+func TestStringFunctionsErrors(t *testing.T) {
+	tests := map[string]string{
+		`lower()`:                 `"lower" expects 1 argument, 0 provided`,
+		`contains("a")`:           `"contains" expects 2 arguments, 1 provided`,
+		`replace("a", "b")`:       `"replace" expects 3 arguments, 2 provided`,
+		`padLeft("a", 3, "ab")`:   `"padLeft" function expects 3rd argument to be a single character, "ab" provided`,
+		`padRight("a", 3, "")`:    `"padRight" function expects 3rd argument to be a single character, "" provided`,
+		`padLeft("a", "x")`:       `"padLeft" function expects 2nd argument to be integer`,
+		`padLeft("a", 100000000)`: `"padLeft" function: result would exceed 10485760 bytes`,
+		`repeat("a", -1)`:         `"repeat" function expects 2nd argument to be >= 0, -1 provided`,
+		`repeat("ab", 10000000)`:  `"repeat" function: result would exceed 10485760 bytes`,
+	}
+	for expr, expected := range tests {
+		_, err := Compile(expr)
+		assert.ErrorContains(t, err, expected, expr)
+	}
+}
+
+func TestSplitFunctions(t *testing.T) {
+	tests := map[string]interface{}{
+		`split("a,b,c")`:                   []string{"a", "b", "c"},
+		`splitFirst("a=b=c", "=", 2)`:      []string{"a", "b=c"},
+		`splitFirst("a=b=c", "=", 1)`:      []string{"a=b=c"},
+		`splitFirst("a=b=c", "=", 5)`:      []string{"a", "b", "c"},
+		`splitFirst("", ",", 2)`:           []string{""},
+		`splitFirst("key: a: b", ": ", 2)`: []string{"key", "a: b"},
+	}
+	for expr, expected := range tests {
+		v, err := EvalString(expr, nil)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+}
+
+func TestSplitFunctionsCap(t *testing.T) {
+	defer func(parts int) { SplitMaxParts = parts }(SplitMaxParts)
+	SplitMaxParts = 3
+
+	assert.Equal(t, []string{"a", "b", "c"}, must(EvalString(`split("a,b,c")`, nil)))
+	_, err := Compile(`split("a,b,c,d")`)
+	assert.ErrorContains(t, err, `"split" function: result exceeds 3 parts`)
+	_, err = Compile(`regexFindAll("[a-z]", "a,b,c,d")`)
+	assert.ErrorContains(t, err, `"regexFindAll" function: result exceeds 3 matches`)
+	_, err = Compile(`splitFirst("a,b", ",", 4)`)
+	assert.ErrorContains(t, err, `"splitFirst" function: result exceeds 3 parts`)
+
+	// The remainder is not scanned, so it may contain more separators than the cap allows
+	vars := map[string]interface{}{"text": "key=" + strings.Repeat("=", 1_000_000)}
+	v, err := EvalString(`splitFirst(text, "=", 2)`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key", strings.Repeat("=", 1_000_000)}, v)
+
+	SplitMaxParts = 0
+	assert.Equal(t, []string{"a", "b", "c", "d"}, must(EvalString(`split("a,b,c,d")`, nil)))
+}
+
+func TestSplitFirstAllocations(t *testing.T) {
+	text := "key=" + strings.Repeat("a=", 100_000)
+	allocs := testing.AllocsPerRun(10, func() {
+		_ = must(CallStdFunction("splitFirst", text, "=", 2))
+	})
+	// The cost doesn't depend on the number of separators in the remainder
+	assert.Less(t, allocs, 20.0)
+}
+
+func TestSplitFunctionsErrors(t *testing.T) {
+	tests := map[string]string{
+		`splitFirst("a", ",")`:      `"splitFirst" expects 3 arguments, 2 provided`,
+		`splitFirst("a", ",", 0)`:   `"splitFirst" function: number of parts should be positive, 0 provided`,
+		`splitFirst("a", ",", "x")`: `"splitFirst" function: invalid number of parts`,
+	}
+	for expr, expected := range tests {
+		_, err := Compile(expr)
+		assert.ErrorContains(t, err, expected, expr)
+	}
+}
+
+func TestTplFunction(t *testing.T) {
+	m := NewMachine().Register("host", "localhost").Register("port", 8080).Register("tls", true)
+
+	v, err := EvalString(`tpl("http{{tls ? \"s\" : \"\"}}://{{host}}:{{port}}/")`, nil, WithMachines(m))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://localhost:8080/", v)
+
+	v, err = EvalString(`tpl("\\{{host}} is {{host}}")`, nil, WithMachines(m))
+	assert.NoError(t, err)
+	assert.Equal(t, "{{host}} is localhost", v)
+
+	v, err = EvalString(`tpl(pattern)`, map[string]interface{}{"pattern": "{{ tojson({\"a\": 1}) }}"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, v)
+}
+
+func TestTplFunctionPartialResolution(t *testing.T) {
+	expr, err := MustCompile(`tpl("{{host}}:{{port}}")`).Resolve(NewMachine().Register("host", "localhost"))
+	assert.NoError(t, err)
+	assert.Equal(t, `"localhost:"+string(port)`, expr.String())
+
+	expr, err = expr.Resolve(NewMachine().Register("port", 8080))
+	assert.NoError(t, err)
+	assert.Equal(t, `"localhost:8080"`, expr.String())
+}
+
+func TestTplFunctionErrors(t *testing.T) {
+	_, err := EvalString(`tpl("abc {{ 1 + ")`, nil)
+	assert.ErrorContains(t, err, `"tpl" function: "abc {{ 1 + ": error: template error: expression at offset 4 not closed`)
+}
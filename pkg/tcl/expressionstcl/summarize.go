@@ -0,0 +1,52 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"strings"
+)
+
+const defaultSummarizeHeadRatio = 0.5
+
+func summarizeMarker(omitted int) string {
+	return "… " + humanNumber(NewValue(int64(omitted)), ",") + " bytes omitted …\n"
+}
+
+// summarize shortens the text to at most maxBytes, by keeping whole lines from its beginning and end,
+// with a marker line in place of the omitted ones; headRatio is a part of the space left for the beginning
+func summarize(text string, maxBytes int, headRatio float64) (string, error) {
+	if len(text) <= maxBytes {
+		return text, nil
+	}
+
+	// The omitted size is not known yet, but it can't have more digits than the whole text size
+	budget := maxBytes - len(summarizeMarker(len(text)))
+	if budget < 0 {
+		return "", fmt.Errorf("%d bytes is not enough to fit the omission marker", maxBytes)
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	head := 0
+	headBudget := int(float64(budget) * headRatio)
+	first := 0
+	for ; first < len(lines) && head+len(lines[first]) <= headBudget; first++ {
+		head += len(lines[first])
+	}
+
+	// The space not used by the beginning is left for the end
+	tail := 0
+	tailBudget := budget - head
+	last := len(lines)
+	for ; last > first && tail+len(lines[last-1]) <= tailBudget; last-- {
+		tail += len(lines[last-1])
+	}
+
+	return text[:head] + summarizeMarker(len(text)-head-tail) + text[len(text)-tail:], nil
+}
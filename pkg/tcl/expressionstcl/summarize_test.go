@@ -0,0 +1,107 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func summarizeLines(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %03d", i+1)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestSummarizeIdentity(t *testing.T) {
+	text := summarizeLines(3)
+	for _, maxBytes := range []int{len(text), len(text) + 1, 1000} {
+		v, err := summarize(text, maxBytes, 0.5)
+		assert.NoError(t, err)
+		assert.Equal(t, text, v)
+	}
+	assert.Equal(t, `""`, MustCompile(`summarize("", 0)`).String())
+	assert.Equal(t, `"short"`, MustCompile(`summarize("short", 5)`).String())
+}
+
+func TestSummarize(t *testing.T) {
+	// 100 lines of 9 bytes
+	text := summarizeLines(100)
+
+	v, err := summarize(text, 100, 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, "line 001\nline 002\nline 003\nline 004\n"+
+		"… 828 bytes omitted …\n"+
+		"line 097\nline 098\nline 099\nline 100\n", v)
+
+	v, err = summarize(text, 100, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "… 828 bytes omitted …\n"+
+		"line 093\nline 094\nline 095\nline 096\nline 097\nline 098\nline 099\nline 100\n", v)
+
+	v, err = summarize(text, 100, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "line 001\nline 002\nline 003\nline 004\nline 005\nline 006\nline 007\nline 008\n"+
+		"… 828 bytes omitted …\n", v)
+
+	v, err = summarize(text, 500, 0.25)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(v), 500)
+	assert.True(t, strings.HasPrefix(v, "line 001\n"))
+	assert.True(t, strings.HasSuffix(v, "line 100\n"))
+	assert.Contains(t, v, "line 013\n… 432 bytes omitted …\nline 062\n")
+}
+
+func TestSummarizeBoundary(t *testing.T) {
+	text := summarizeLines(100)
+
+	v, err := summarize(text, len(text)-1, 0.5)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(v), len(text)-1)
+	assert.Contains(t, v, "bytes omitted")
+
+	for maxBytes := 0; maxBytes <= len(text); maxBytes++ {
+		v, err := summarize(text, maxBytes, 0.3)
+		if err != nil {
+			assert.Less(t, maxBytes, len(summarizeMarker(len(text))))
+			continue
+		}
+		assert.LessOrEqual(t, len(v), maxBytes, maxBytes)
+		head, tail, _ := strings.Cut(v, "…")
+		assert.True(t, head == "" || strings.HasSuffix(head, "\n"), maxBytes)
+		assert.True(t, strings.HasPrefix(text, head), maxBytes)
+		_, tail, _ = strings.Cut(tail, "…\n")
+		assert.True(t, strings.HasSuffix(text, tail), maxBytes)
+	}
+}
+
+func TestSummarizeLongLines(t *testing.T) {
+	text := strings.Repeat("x", 100) + "\n" + strings.Repeat("y", 100)
+	v, err := summarize(text, 50, 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, "… 201 bytes omitted …\n", v)
+}
+
+func TestSummarizeFunction(t *testing.T) {
+	text := fmt.Sprintf("%q", summarizeLines(8))
+	assert.Equal(t, `"line 001\n… 54 bytes omitted …\nline 008\n"`, MustCompile(`summarize(`+text+`, 50)`).String())
+	assert.Equal(t, `"… 54 bytes omitted …\nline 007\nline 008\n"`, MustCompile(`summarize(`+text+`, 50, 0)`).String())
+
+	_, err := Compile(`summarize("a\nb\nc\nd", 5)`)
+	assert.ErrorContains(t, err, "5 bytes is not enough to fit the omission marker")
+	_, err = Compile(`summarize("text", 2, 1.5)`)
+	assert.ErrorContains(t, err, "expects ratio of the beginning to be between 0 and 1")
+	_, err = Compile(`summarize("text", -1)`)
+	assert.ErrorContains(t, err, "expects maximum bytes to be a non-negative integer")
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"errors"
+	"fmt"
+	math2 "math"
+	"strconv"
+	"time"
+)
+
+// timeNow is the clock used by resolutions without own clock, it's replaced in tests
+var timeNow = time.Now
+
+// namedDateLayouts may be used instead of the Go reference layouts in date functions
+var namedDateLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+	"Kitchen":     time.Kitchen,
+}
+
+type clockMachine struct {
+	now      time.Time
+	deferred bool
+}
+
+// deferredClockMachine leaves the functions depending on the current time unresolved,
+// it's used for compilation, so the compiled expression doesn't carry the compilation time
+var deferredClockMachine = &clockMachine{deferred: true}
+
+// NewClockMachine builds a machine that pins the current time for the single resolution, i.e. expr.Resolve(m, NewClockMachine(t)),
+// so now() and date() return the same time in all the resolution passes; the resolution without it pins the time on start
+func NewClockMachine(now time.Time) Machine {
+	return &clockMachine{now: now}
+}
+
+func (c *clockMachine) Get(_ string) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+func (c *clockMachine) Call(_ string, _ ...StaticValue) (Expression, bool, error) {
+	return nil, false, nil
+}
+
+// resolveClock finds the clock for the resolution, it's the current time when no time is pinned,
+// and nil when the functions depending on the current time should not be resolved yet
+func resolveClock(m []Machine) func() time.Time {
+	for i := range m {
+		if c, ok := m[i].(*clockMachine); ok {
+			if c.deferred {
+				return nil
+			}
+			return func() time.Time {
+				return c.now
+			}
+		}
+	}
+	return timeNow
+}
+
+// withClock pins the current time for the resolution, unless it's already pinned
+func withClock(m []Machine) []Machine {
+	for i := range m {
+		if _, ok := m[i].(*clockMachine); ok {
+			return m
+		}
+	}
+	return append(m[:len(m):len(m)], NewClockMachine(timeNow()))
+}
+
+// dateLayout resolves the named layout, and validates that the Go reference layout has any date or time element
+func dateLayout(layout string) (string, error) {
+	if named, ok := namedDateLayouts[layout]; ok {
+		return named, nil
+	}
+	if layout == "" {
+		return "", errors.New("layout can't be empty")
+	}
+	// Layout without any element is formatted as it is
+	if time.Unix(0, 0).UTC().Format(layout) == layout {
+		return "", fmt.Errorf("layout %q has no date or time elements, use Go reference time, i.e. 2006-01-02T15:04:05Z07:00", layout)
+	}
+	return layout, nil
+}
+
+// toTime reads the unix seconds, or the RFC3339 date
+func toTime(value StaticValue) (time.Time, error) {
+	if value.IsString() {
+		str, _ := value.StringValue()
+		if _, err := strconv.ParseFloat(str, 64); err != nil {
+			t, err := time.Parse(time.RFC3339Nano, str)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("expected unix seconds or RFC3339 date: %v", err)
+			}
+			return t, nil
+		}
+	}
+	seconds, err := value.FloatValue()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected unix seconds or RFC3339 date: %v", err)
+	}
+	whole, fraction := math2.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*float64(time.Second))), nil
+}
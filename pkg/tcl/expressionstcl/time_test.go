@@ -0,0 +1,94 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateFunctions(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 45, 0, time.FixedZone("CET", 3600))
+	tests := map[string]interface{}{
+		`now()`:                    "2024-03-15T09:30:45Z",
+		`date("2006-01-02")`:       "2024-03-15",
+		`date("DateTime")`:         "2024-03-15 09:30:45",
+		`formatDate(0, "RFC3339")`: "1970-01-01T00:00:00Z",
+		`formatDate(1700000000.5, "15:04:05.000")`:           "22:13:20.500",
+		`formatDate("1700000000", "DateOnly")`:               "2023-11-14",
+		`formatDate(now(), "Jan 2, 2006")`:                   "Mar 15, 2024",
+		`formatDate("2024-03-15T10:30:45+01:00", "Kitchen")`: "9:30AM",
+		`parseDate("2024-03-15", "2006-01-02")`:              int64(1710460800),
+		`parseDate("2024-03-15T10:30:45+01:00", "RFC3339")`:  int64(1710495045),
+		`parseDate(now(), "RFC3339") + duration("5m30s")`:    int64(1710495375),
+		`duration("5m30s")`:                                  int64(330),
+		`duration("1h")`:                                     int64(3600),
+		`duration("1500ms")`:                                 int64(1),
+		`duration("-2m")`:                                    int64(-120),
+	}
+	for expr, expected := range tests {
+		v, err := must(Compile(expr)).Resolve(NewClockMachine(now))
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, v.Static().Value(), expr)
+	}
+}
+
+func TestDateFunctionsErrors(t *testing.T) {
+	tests := map[string]string{
+		`now(1)`:                              `"now" expects 0 arguments, 1 provided`,
+		`date()`:                              `"date" expects 1 argument, 0 provided`,
+		`date("")`:                            `"date" function: layout can't be empty`,
+		`date("today")`:                       `"date" function: layout "today" has no date or time elements`,
+		`formatDate(0, "yyyy-MM-dd")`:         `"formatDate" function: layout "yyyy-MM-dd" has no date or time elements`,
+		`formatDate("yesterday", "DateOnly")`: `"formatDate" function: expected unix seconds or RFC3339 date`,
+		`formatDate(0)`:                       `"formatDate" expects 2 arguments, 1 provided`,
+		`parseDate("15/03/2024", "DateOnly")`: `"parseDate" function: parsing time "15/03/2024"`,
+		`parseDate("2024-03-15", "date")`:     `"parseDate" function: layout "date" has no date or time elements`,
+		`duration("5 minutes")`:               `"duration" function: time: unknown unit`,
+		`duration()`:                          `"duration" expects 1 argument, 0 provided`,
+	}
+	for expr, expected := range tests {
+		_, err := EvalString(expr, nil)
+		assert.ErrorContains(t, err, expected, expr)
+	}
+}
+
+func TestDateFunctionsClockPinnedPerResolution(t *testing.T) {
+	calls := 0
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	timeNow = func() time.Time {
+		calls++
+		return time.Unix(int64(1700000000+calls), 0)
+	}
+
+	// the accessor is resolved in the later pass, but now() still returns the time pinned on the resolution start
+	expr := must(CompileTemplate(`{{now()}} {{formatDate(later, "RFC3339")}}`))
+	machine := NewMachine().RegisterAccessorExt(func(name string) (interface{}, bool, error) {
+		if name == "later" {
+			return must(Compile("now()")), true, nil
+		}
+		return nil, false, nil
+	})
+	calls = 0
+	v, err := expr.Resolve(machine)
+	assert.NoError(t, err)
+	assert.Equal(t, "2023-11-14T22:13:21Z 2023-11-14T22:13:21Z", v.Static().Value())
+	assert.Equal(t, 1, calls)
+
+	// the next resolution has its own time
+	v, err = must(Compile("now()")).Resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, "2023-11-14T22:13:22Z", v.Static().Value())
+
+	assert.Equal(t, TypeString, GetStdFunctionReturnType("now"))
+	assert.Equal(t, TypeInt64, GetStdFunctionReturnType("parseDate"))
+	assert.Equal(t, TypeInt64, GetStdFunctionReturnType("duration"))
+}
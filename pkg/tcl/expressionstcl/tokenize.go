@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"strconv"
 )
 
 var mathOperatorRe = regexp.MustCompile(`^(?:!=|<>|==|>=|<=|&&|\*\*|\|\||[+\-*/><=%])`)
@@ -70,12 +71,13 @@ func tokenizeNext(exp string, i int) (token, int, error) {
 				}
 			}
 			decoder := json.NewDecoder(bytes.NewBuffer([]byte(exp[i:])))
+			decoder.UseNumber()
 			var val interface{}
 			err := decoder.Decode(&val)
 			if err != nil {
 				return token{}, i, fmt.Errorf("error while decoding JSON from index %d in expression: %s: %s", i, exp, err.Error())
 			}
-			return tokenJson(val), i + int(decoder.InputOffset()) - appended, nil
+			return tokenJson(jsonNumbers(val)), i + int(decoder.InputOffset()) - appended, nil
 		case accessorRe.MatchString(exp[i:]):
 			acc := accessorRe.FindString(exp[i:])
 			return tokenAccessor(acc), i + len(acc), nil
@@ -89,6 +91,31 @@ func tokenizeNext(exp string, i int) (token, int, error) {
 	return token{}, 0, io.EOF
 }
 
+// maxExactFloatInt is the largest integer which float64 represents exactly (2^53)
+const maxExactFloatInt = 1 << 53
+
+// jsonNumbers converts the numbers of the decoded literal to float64,
+// except of integers beyond float64 precision, that are kept exact as int64 (i.e. 9007199254740993)
+func jsonNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil && (i > maxExactFloatInt || i < -maxExactFloatInt) {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = jsonNumbers(v[key])
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonNumbers(v[i])
+		}
+	}
+	return value
+}
+
 func tokenize(exp string, index int) (tokens []token, i int, err error) {
 	tokens = make([]token, 0)
 	var t token
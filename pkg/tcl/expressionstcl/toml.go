@@ -0,0 +1,744 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"fmt"
+	math2 "math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The TOML support is written by hand, as there is no TOML module in the dependencies,
+// and the parser has to keep the date-times and special floats as strings anyway,
+// because the expression values are JSON-compatible.
+
+var (
+	tomlBareKeyRe   = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	tomlKeyCharRe   = regexp.MustCompile(`^[A-Za-z0-9_-]+`)
+	tomlIntRe       = regexp.MustCompile(`^(?:[+-]?(?:0|[1-9](?:_?\d)*)|0x[0-9A-Fa-f](?:_?[0-9A-Fa-f])*|0o[0-7](?:_?[0-7])*|0b[01](?:_?[01])*)$`)
+	tomlFloatRe     = regexp.MustCompile(`^[+-]?(?:0|[1-9](?:_?\d)*)(?:\.\d(?:_?\d)*)?(?:[eE][+-]?\d(?:_?\d)*)?$`)
+	tomlDateTimeRe  = regexp.MustCompile(`^(?:\d{4}-\d{2}-\d{2}(?:[Tt ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:[Zz]|[+-]\d{2}:\d{2})?)?|\d{2}:\d{2}:\d{2}(?:\.\d+)?)$`)
+	tomlValueCharRe = regexp.MustCompile(`^[0-9A-Za-z_:.+\-]+`)
+)
+
+// tomlDateTimeLayouts are the offset date-time, local date-time, local date and local time,
+// the fractional seconds are accepted by time.Parse without being in the layout
+var tomlDateTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", time.DateOnly, time.TimeOnly}
+
+// tomlParser reads the TOML document, the date-times, inf and nan are kept as strings
+type tomlParser struct {
+	src  string
+	pos  int
+	line int
+
+	root    map[string]interface{}
+	current map[string]interface{}
+	path    string
+	// defined are the paths of tables defined with the header or with the dotted keys, that can't be defined again,
+	// frozen are the paths of inline tables and static arrays, that can't be extended
+	defined map[string]struct{}
+	frozen  map[string]struct{}
+	// tableArrays are the paths of arrays defined with [[header]], to extend them with the next items
+	tableArrays map[string]struct{}
+}
+
+// parseTOML parses the TOML document into the map, the errors point to the line where the parsing failed
+func parseTOML(str string) (map[string]interface{}, error) {
+	p := &tomlParser{
+		src:         str,
+		line:        1,
+		root:        make(map[string]interface{}),
+		defined:     make(map[string]struct{}),
+		frozen:      make(map[string]struct{}),
+		tableArrays: make(map[string]struct{}),
+	}
+	p.current = p.root
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("line %d: %v", p.line, err)
+	}
+	return p.root, nil
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek(prefix string) bool {
+	return strings.HasPrefix(p.src[p.pos:], prefix)
+}
+
+// skipSpace skips the spaces and tabs
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipComment skips the comment until the end of line
+func (p *tomlParser) skipComment() {
+	if p.peek("#") {
+		for !p.eof() && p.src[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+}
+
+// skipBlank skips the whitespace, comments and new lines, i.e. between the array items
+func (p *tomlParser) skipBlank() {
+	for {
+		p.skipSpace()
+		p.skipComment()
+		if p.peek("\r\n") {
+			p.pos += 2
+		} else if p.peek("\n") {
+			p.pos++
+		} else {
+			return
+		}
+		p.line++
+	}
+}
+
+// endLine expects the end of line or document after the key-value pair or the header
+func (p *tomlParser) endLine() error {
+	p.skipSpace()
+	p.skipComment()
+	switch {
+	case p.eof():
+		return nil
+	case p.peek("\r\n"):
+		p.pos += 2
+	case p.peek("\n"):
+		p.pos++
+	default:
+		return fmt.Errorf("expected the end of line, found %q", p.src[p.pos:p.pos+1])
+	}
+	p.line++
+	return nil
+}
+
+func (p *tomlParser) parse() error {
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil
+		}
+		var err error
+		switch {
+		case p.peek("[["):
+			err = p.parseTableArrayHeader()
+		case p.peek("["):
+			err = p.parseTableHeader()
+		default:
+			err = p.parseKeyValue(p.current, p.path)
+		}
+		if err != nil {
+			return err
+		}
+		if err = p.endLine(); err != nil {
+			return err
+		}
+	}
+}
+
+// joinTOMLPath builds the unique path of the key, the quoted keys with dots are not confused with the dotted keys
+func joinTOMLPath(path, key string) string {
+	return path + "\x00" + key
+}
+
+func displayTOMLKey(keys []string) string {
+	result := make([]string, len(keys))
+	for i := range keys {
+		result[i] = formatTOMLKey(keys[i])
+	}
+	return strings.Join(result, ".")
+}
+
+// descend returns the table at the key, and creates the missing one
+func (p *tomlParser) descend(table map[string]interface{}, path, key string) (map[string]interface{}, string, error) {
+	path = joinTOMLPath(path, key)
+	switch v := table[key].(type) {
+	case nil:
+		next := make(map[string]interface{})
+		table[key] = next
+		return next, path, nil
+	case map[string]interface{}:
+		if _, ok := p.frozen[path]; ok {
+			return nil, "", fmt.Errorf("inline table %s can't be extended", formatTOMLKey(key))
+		}
+		return v, path, nil
+	case []interface{}:
+		if _, ok := p.tableArrays[path]; !ok || len(v) == 0 {
+			return nil, "", fmt.Errorf("static array %s can't be extended", formatTOMLKey(key))
+		}
+		return v[len(v)-1].(map[string]interface{}), fmt.Sprintf("%s[%d]", path, len(v)-1), nil
+	default:
+		return nil, "", fmt.Errorf("key %s is already defined as a value", formatTOMLKey(key))
+	}
+}
+
+func (p *tomlParser) descendAll(keys []string) (map[string]interface{}, string, error) {
+	table, path := p.root, ""
+	for _, key := range keys {
+		var err error
+		table, path, err = p.descend(table, path, key)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return table, path, nil
+}
+
+func (p *tomlParser) parseTableHeader() error {
+	p.pos++
+	p.skipSpace()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if !p.peek("]") {
+		return fmt.Errorf("expected ] after the table name")
+	}
+	p.pos++
+	parent, path, err := p.descendAll(keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	if _, ok := p.tableArrays[joinTOMLPath(path, keys[len(keys)-1])]; ok {
+		return fmt.Errorf("array of tables %s can't be redefined as a table", displayTOMLKey(keys))
+	}
+	table, path, err := p.descend(parent, path, keys[len(keys)-1])
+	if err != nil {
+		return err
+	}
+	if _, ok := p.defined[path]; ok {
+		return fmt.Errorf("table %s is already defined", displayTOMLKey(keys))
+	}
+	p.defined[path] = struct{}{}
+	p.current, p.path = table, path
+	return nil
+}
+
+func (p *tomlParser) parseTableArrayHeader() error {
+	p.pos += 2
+	p.skipSpace()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if !p.peek("]]") {
+		return fmt.Errorf("expected ]] after the array of tables name")
+	}
+	p.pos += 2
+	parent, path, err := p.descendAll(keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	key := keys[len(keys)-1]
+	path = joinTOMLPath(path, key)
+	var list []interface{}
+	if v, ok := parent[key]; ok {
+		list, ok = v.([]interface{})
+		if _, isTableArray := p.tableArrays[path]; !ok || !isTableArray {
+			return fmt.Errorf("key %s is already defined as a value", displayTOMLKey(keys))
+		}
+	}
+	table := make(map[string]interface{})
+	parent[key] = append(list, table)
+	p.tableArrays[path] = struct{}{}
+	p.current, p.path = table, fmt.Sprintf("%s[%d]", path, len(list))
+	return nil
+}
+
+// parseKeyValue reads the pair into the table, the dotted keys define the nested tables
+func (p *tomlParser) parseKeyValue(table map[string]interface{}, path string) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if !p.peek("=") {
+		return fmt.Errorf("expected = after the key %s", displayTOMLKey(keys))
+	}
+	p.pos++
+	p.skipSpace()
+	for _, key := range keys[:len(keys)-1] {
+		table, path, err = p.descend(table, path, key)
+		if err != nil {
+			return err
+		}
+		p.defined[path] = struct{}{}
+	}
+	key := keys[len(keys)-1]
+	if _, ok := table[key]; ok {
+		return fmt.Errorf("key %s is already defined", displayTOMLKey(keys))
+	}
+	value, err := p.parseValue(joinTOMLPath(path, key))
+	if err != nil {
+		return err
+	}
+	table[key] = value
+	return nil
+}
+
+// parseKey reads the dotted key, the parts may be bare or quoted
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		var key string
+		var err error
+		switch {
+		case p.peek(`"`):
+			key, err = p.parseBasicString(false)
+		case p.peek("'"):
+			key, err = p.parseLiteralString(false)
+		default:
+			key = tomlKeyCharRe.FindString(p.src[p.pos:])
+			if key == "" {
+				if p.eof() {
+					return nil, fmt.Errorf("expected the key, found the end of document")
+				}
+				return nil, fmt.Errorf("expected the key, found %q", p.src[p.pos:p.pos+1])
+			}
+			p.pos += len(key)
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		p.skipSpace()
+		if !p.peek(".") {
+			return keys, nil
+		}
+		p.pos++
+		p.skipSpace()
+	}
+}
+
+func (p *tomlParser) parseValue(path string) (interface{}, error) {
+	switch {
+	case p.eof():
+		return nil, fmt.Errorf("expected the value, found the end of document")
+	case p.peek(`"`):
+		return p.parseBasicString(p.peek(`"""`))
+	case p.peek("'"):
+		return p.parseLiteralString(p.peek("'''"))
+	case p.peek("["):
+		p.frozen[path] = struct{}{}
+		return p.parseArray(path)
+	case p.peek("{"):
+		p.frozen[path] = struct{}{}
+		return p.parseInlineTable(path)
+	}
+	token := tomlValueCharRe.FindString(p.src[p.pos:])
+	// The date and time may be separated by the space
+	if len(token) == 10 && tomlDateTimeRe.MatchString(token) && p.pos+len(token)+3 <= len(p.src) && p.src[p.pos+len(token)] == ' ' {
+		if rest := tomlValueCharRe.FindString(p.src[p.pos+len(token)+1:]); tomlDateTimeRe.MatchString(token + " " + rest) {
+			token += " " + rest
+		}
+	}
+	if token == "" {
+		return nil, fmt.Errorf("expected the value, found %q", p.src[p.pos:p.pos+1])
+	}
+	p.pos += len(token)
+	return parseTOMLScalar(token)
+}
+
+func parseTOMLScalar(token string) (interface{}, error) {
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		return token, nil
+	}
+	switch {
+	case tomlIntRe.MatchString(token):
+		// Base prefixes and underscores are handled by strconv, leading zeros are already rejected
+		v, err := strconv.ParseInt(token, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("integer %s out of range", token)
+		}
+		return v, nil
+	case tomlFloatRe.MatchString(token):
+		v, err := strconv.ParseFloat(strings.ReplaceAll(token, "_", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %s", token)
+		}
+		return v, nil
+	case tomlDateTimeRe.MatchString(token):
+		normalized := []byte(strings.ToUpper(token))
+		if len(normalized) > 10 && normalized[10] == ' ' {
+			normalized[10] = 'T'
+		}
+		for _, layout := range tomlDateTimeLayouts {
+			if _, err := time.Parse(layout, string(normalized)); err == nil {
+				return token, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid date-time %s", token)
+	}
+	return nil, fmt.Errorf("invalid value %s", token)
+}
+
+func (p *tomlParser) parseArray(path string) ([]interface{}, error) {
+	p.pos++
+	result := make([]interface{}, 0)
+	for {
+		p.skipBlank()
+		if p.peek("]") {
+			p.pos++
+			return result, nil
+		}
+		v, err := p.parseValue(fmt.Sprintf("%s[%d]", path, len(result)))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+		p.skipBlank()
+		if p.peek(",") {
+			p.pos++
+		} else if !p.peek("]") {
+			return nil, fmt.Errorf("expected , or ] in the array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable(path string) (map[string]interface{}, error) {
+	p.pos++
+	result := make(map[string]interface{})
+	p.skipSpace()
+	if p.peek("}") {
+		p.pos++
+		return result, nil
+	}
+	for {
+		p.skipSpace()
+		if err := p.parseKeyValue(result, path); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek("}") {
+			p.pos++
+			return result, nil
+		}
+		if !p.peek(",") {
+			return nil, fmt.Errorf("expected , or } in the inline table")
+		}
+		p.pos++
+	}
+}
+
+// openString skips the opening delimiter, and the new line immediately following the multi-line one
+func (p *tomlParser) openString(delimiter string, multiline bool) string {
+	if multiline {
+		delimiter = strings.Repeat(delimiter, 3)
+	}
+	p.pos += len(delimiter)
+	if multiline && p.peek("\r\n") {
+		p.pos += 2
+		p.line++
+	} else if multiline && p.peek("\n") {
+		p.pos++
+		p.line++
+	}
+	return delimiter
+}
+
+func (p *tomlParser) parseLiteralString(multiline bool) (string, error) {
+	delimiter := p.openString("'", multiline)
+	end := strings.Index(p.src[p.pos:], delimiter)
+	if end == -1 || (!multiline && strings.Contains(p.src[p.pos:p.pos+end], "\n")) {
+		return "", fmt.Errorf("unterminated string")
+	}
+	// Up to two quotes are allowed right before the closing delimiter of multi-line string
+	for i := 0; multiline && i < 2 && p.pos+end+3 < len(p.src) && p.src[p.pos+end+3] == '\''; i++ {
+		end++
+	}
+	str := p.src[p.pos : p.pos+end]
+	p.line += strings.Count(str, "\n")
+	p.pos += end + len(delimiter)
+	return str, nil
+}
+
+func (p *tomlParser) parseBasicString(multiline bool) (string, error) {
+	delimiter := p.openString(`"`, multiline)
+	var b strings.Builder
+	for !p.eof() {
+		switch {
+		case p.peek(delimiter):
+			p.pos += len(delimiter)
+			// Up to two quotes are allowed right before the closing delimiter of multi-line string
+			for i := 0; multiline && i < 2 && p.peek(`"`); i++ {
+				b.WriteByte('"')
+				p.pos++
+			}
+			return b.String(), nil
+		case p.peek("\\"):
+			// The backslash at the end of line trims the whitespace and new lines that follow
+			rest := strings.TrimLeft(p.src[p.pos+1:], " \t")
+			if multiline && (strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n")) {
+				p.pos = len(p.src) - len(rest)
+				p.skipBlankLines()
+				continue
+			}
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		case p.src[p.pos] == '\n' && !multiline:
+			return "", fmt.Errorf("unterminated string")
+		default:
+			if p.src[p.pos] == '\n' {
+				p.line++
+			}
+			b.WriteByte(p.src[p.pos])
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// skipBlankLines skips the whitespace including new lines, but not the comments
+func (p *tomlParser) skipBlankLines() {
+	for !p.eof() && strings.IndexByte(" \t\r\n", p.src[p.pos]) != -1 {
+		if p.src[p.pos] == '\n' {
+			p.line++
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseEscape(b *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		return fmt.Errorf("unterminated string")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return fmt.Errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return fmt.Errorf("invalid unicode escape \\%c%s", c, p.src[p.pos:p.pos+size])
+		}
+		b.WriteRune(rune(code))
+		p.pos += size
+	default:
+		return fmt.Errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
+
+// buildTOML writes the map as the TOML document, the keys are sorted, the nested maps are the tables,
+// and the lists of maps are the arrays of tables
+func buildTOML(value map[string]interface{}) (string, error) {
+	var b strings.Builder
+	if err := writeTOMLTable(&b, nil, value); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func isTOMLTable(v interface{}) bool {
+	return isMap(v) || isStruct(v)
+}
+
+func isTOMLTableArray(v interface{}) bool {
+	if !isSlice(v) {
+		return false
+	}
+	list, _ := toSlice(v)
+	for i := range list {
+		if !isTOMLTable(list[i]) {
+			return false
+		}
+	}
+	return len(list) > 0
+}
+
+func writeTOMLTable(b *strings.Builder, path []string, table map[string]interface{}) error {
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// The values go first, as the following headers start the nested tables
+	for _, k := range keys {
+		if isTOMLTable(table[k]) || isTOMLTableArray(table[k]) {
+			continue
+		}
+		str, err := formatTOMLValue(table[k])
+		if err != nil {
+			return fmt.Errorf("%s: %v", displayTOMLKey(append(path, k)), err)
+		}
+		b.WriteString(formatTOMLKey(k) + " = " + str + "\n")
+	}
+	for _, k := range keys {
+		v := table[k]
+		nested := append(path[:len(path):len(path)], k)
+		if isTOMLTable(v) {
+			m, _ := toMap(v)
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString("[" + displayTOMLKey(nested) + "]\n")
+			if err := writeTOMLTable(b, nested, m); err != nil {
+				return err
+			}
+		} else if isTOMLTableArray(v) {
+			list, _ := toSlice(v)
+			for i := range list {
+				m, _ := toMap(list[i])
+				if b.Len() > 0 {
+					b.WriteString("\n")
+				}
+				b.WriteString("[[" + displayTOMLKey(nested) + "]]\n")
+				if err := writeTOMLTable(b, nested, m); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func formatTOMLKey(key string) string {
+	if tomlBareKeyRe.MatchString(key) {
+		return key
+	}
+	return formatTOMLString(key)
+}
+
+func formatTOMLString(str string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range str {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func formatTOMLValue(v interface{}) (string, error) {
+	if v == nil || isNone(v) {
+		return "", fmt.Errorf("null value is not supported")
+	}
+	switch x := v.(type) {
+	case string:
+		return formatTOMLString(x), nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	}
+	switch n := numberValue(v).(type) {
+	case int64:
+		return strconv.FormatInt(n, 10), nil
+	case float64:
+		switch {
+		case math2.IsNaN(n):
+			return "nan", nil
+		case math2.IsInf(n, 1):
+			return "inf", nil
+		case math2.IsInf(n, -1):
+			return "-inf", nil
+		case n == math2.Trunc(n) && math2.Abs(n) < math2.MaxInt64:
+			// Integral values are integers, as i.e. JSON numbers are always decoded as floats
+			return strconv.FormatInt(int64(n), 10), nil
+		}
+		str := strconv.FormatFloat(n, 'g', -1, 64)
+		if !strings.ContainsAny(str, ".e") {
+			str += ".0"
+		}
+		return str, nil
+	}
+	if isTOMLTable(v) {
+		m, _ := toMap(v)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			str, err := formatTOMLValue(m[k])
+			if err != nil {
+				return "", fmt.Errorf("%s: %v", formatTOMLKey(k), err)
+			}
+			items[i] = formatTOMLKey(k) + " = " + str
+		}
+		if len(items) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	}
+	if isSlice(v) {
+		list, _ := toSlice(v)
+		items := make([]string, len(list))
+		for i := range list {
+			str, err := formatTOMLValue(list[i])
+			if err != nil {
+				return "", fmt.Errorf("[%d]: %v", i, err)
+			}
+			items[i] = str
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	}
+	str, err := toString(v)
+	if err != nil {
+		return "", err
+	}
+	return formatTOMLString(str), nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	math2 "math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTOML = `# Test data
+title = "TOML \"example\""
+path = 'C:\Users\test'
+ints = [1, 0x1F, 0o17, 0b101, 1_000, -3]
+floats = [1.5, -2e3, 6.626e-34, inf]
+enabled = true
+released = 1979-05-27T07:32:00Z
+local = 1979-05-27 07:32:00
+day = 1979-05-27
+site."google.com" = true
+point = { x = 1, y = 2 }
+text = """
+Roses are red
+Violets are \
+    blue"""
+raw = '''
+first
+second'''
+
+[database]
+ports = [
+  8000, # main
+  8001,
+]
+
+[database.primary]
+host = "db.local"
+
+[[products]]
+name = "Hammer"
+sku = 738594937
+
+[[products]]
+
+[[products]]
+name = "Nail"
+color = "gray"
+`
+
+func TestParseTOML(t *testing.T) {
+	v, err := parseTOML(testTOML)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"title":    `TOML "example"`,
+		"path":     `C:\Users\test`,
+		"ints":     []interface{}{int64(1), int64(31), int64(15), int64(5), int64(1000), int64(-3)},
+		"floats":   []interface{}{1.5, -2000.0, 6.626e-34, "inf"},
+		"enabled":  true,
+		"released": "1979-05-27T07:32:00Z",
+		"local":    "1979-05-27 07:32:00",
+		"day":      "1979-05-27",
+		"site":     map[string]interface{}{"google.com": true},
+		"point":    map[string]interface{}{"x": int64(1), "y": int64(2)},
+		"text":     "Roses are red\nViolets are blue",
+		"raw":      "first\nsecond",
+		"database": map[string]interface{}{
+			"ports":   []interface{}{int64(8000), int64(8001)},
+			"primary": map[string]interface{}{"host": "db.local"},
+		},
+		"products": []interface{}{
+			map[string]interface{}{"name": "Hammer", "sku": int64(738594937)},
+			map[string]interface{}{},
+			map[string]interface{}{"name": "Nail", "color": "gray"},
+		},
+	}, v)
+}
+
+func TestParseTOMLFunction(t *testing.T) {
+	assert.Equal(t, `{"a":{"b":1}}`, MustCompile(`toml("[a]\nb = 1")`).String())
+	assert.Equal(t, `"db.local"`, MustCompile(`toml("[database]\nhost = \"db.local\"").database.host`).String())
+	assert.Equal(t, `{}`, MustCompile(`toml("")`).String())
+	assert.Equal(t, `{"a":"inf","b":"-nan"}`, MustCompile(`toml("a = inf\nb = -nan")`).String())
+	assert.Equal(t, `"1979-05-27t07:32:00.999-07:00"`, MustCompile(`toml("a = 1979-05-27t07:32:00.999-07:00").a`).String())
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := map[string]string{
+		"a = 1\nb = \n":                   `line 2: expected the value, found "\n"`,
+		"a = 1\na = 2":                    "line 2: key a is already defined",
+		"[a]\nb = 1\n[a]":                 "line 3: table a is already defined",
+		"a = { b = 1 }\n[a]":              "line 2: inline table a can't be extended",
+		"a = [1]\n[[a]]":                  "line 2: key a is already defined as a value",
+		"a = \"unterminated\nb = 1":       "line 1: unterminated string",
+		"a = 1\n\n# comment\nb = 09":      "line 4: invalid value 09",
+		"a = \"\"\"\nmulti\nline":         "line 3: unterminated string",
+		"a = [\n1,\n2\n3]":                "line 4: expected , or ] in the array",
+		"a = 07:60:00":                    "line 1: invalid date-time 07:60:00",
+		"a = 0x_1F":                       "line 1: invalid value 0x_1F",
+		"a = 1979-13-27":                  "line 1: invalid date-time 1979-13-27",
+		"a = 99999999999999999999":        "line 1: integer 99999999999999999999 out of range",
+		"a = \"\\q\"":                     "line 1: invalid escape \\q",
+		"[a\nb = 1":                       "line 1: expected ] after the table name",
+		"= 1":                             "line 1: expected the key, found \"=\"",
+		"a = 1 b = 2":                     "line 1: expected the end of line, found \"b\"",
+		"a.b = 1\n[a]":                    "line 2: table a is already defined",
+		"a = 1\n[a.b]":                    "line 2: key a is already defined as a value",
+		"[[a]]\nb = 1\n[a]\nc = 1":        "line 3: array of tables a can't be redefined as a table",
+		"a = [{ b = 1 }]\n[a.c]":          "line 2: static array a can't be extended",
+		"a = { b = 1, b = 2 }":            "line 1: key b is already defined",
+		"str = 'abc\n'":                   "line 1: unterminated string",
+		"x = \"\\u00\"":                   "line 1: invalid unicode escape",
+		"nested = [[1, 2], [3, 4]]\nz = ": "line 2: expected the value, found the end of document",
+	}
+	for str, expected := range tests {
+		_, err := parseTOML(str)
+		assert.ErrorContains(t, err, expected, str)
+	}
+
+	_, err := Compile(`toml("a = 1\nb =")`)
+	assert.ErrorContains(t, err, `"toml" function: line 2: expected the value, found the end of document`)
+}
+
+func TestBuildTOML(t *testing.T) {
+	str, err := buildTOML(map[string]interface{}{
+		"title":    "example",
+		"count":    3,
+		"ratio":    2.0,
+		"scale":    2.5,
+		"limit":    math2.Inf(1),
+		"tags":     []interface{}{"a", "b"},
+		"meta":     []interface{}{map[string]interface{}{"k": "v"}, 1},
+		"key.dots": "\"quoted\"\n",
+		"database": map[string]interface{}{
+			"host":    "db.local",
+			"primary": map[string]interface{}{"port": 5432},
+		},
+		"products": []interface{}{
+			map[string]interface{}{"name": "Hammer"},
+			map[string]interface{}{"name": "Nail"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `count = 3
+"key.dots" = "\"quoted\"\n"
+limit = inf
+meta = [{ k = "v" }, 1]
+ratio = 2
+scale = 2.5
+tags = ["a", "b"]
+title = "example"
+
+[database]
+host = "db.local"
+
+[database.primary]
+port = 5432
+
+[[products]]
+name = "Hammer"
+
+[[products]]
+name = "Nail"
+`, str)
+
+	_, err = buildTOML(map[string]interface{}{"a": map[string]interface{}{"b": nil}})
+	assert.EqualError(t, err, "a.b: null value is not supported")
+
+	_, err = Compile(`totoml([1, 2])`)
+	assert.ErrorContains(t, err, `"totoml" function expects a map, [1,2] provided`)
+}
+
+func TestTOMLRoundTrip(t *testing.T) {
+	v, err := parseTOML(testTOML)
+	assert.NoError(t, err)
+	str, err := buildTOML(v)
+	assert.NoError(t, err)
+	result, err := parseTOML(str)
+	assert.NoError(t, err)
+	// Integral floats are written as integers
+	v["floats"].([]interface{})[1] = int64(-2000)
+	assert.Equal(t, v, result)
+
+	assert.Equal(t, `{"a":[1,2],"b":{"c":"x"}}`, MustCompile(`toml(totoml({"a": [1, 2], "b": {"c": "x"}}))`).String())
+	assert.Equal(t, `"count = 3\nratio = 0.5\n"`, MustCompile(`totoml(json("{\"count\": 3, \"ratio\": 0.5}"))`).String())
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The parsing is shared by the strict functions, i.e. json(), and their speculative try* counterparts,
+// so they can't diverge: the strict ones fail for the malformed input, while the try* ones return None
+
+func parseJSON(str string) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal([]byte(str), &v)
+	return v, err
+}
+
+func parseYAML(str string) (interface{}, error) {
+	var v interface{}
+	err := yaml.Unmarshal([]byte(str), &v)
+	return v, err
+}
+
+// parseDateUnix parses the date with the already resolved layout, see dateLayout, to unix seconds
+func parseDateUnix(str, layout string) (int64, error) {
+	t, err := time.Parse(layout, str)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
+
+// tryStdFunction builds the try* function from the parser, only non-string argument when the string is required
+// is an error, while the malformed input resolves to None
+func tryStdFunction(name, description string, requireString bool, parse func(value StaticValue) (interface{}, error)) StdFunction {
+	argType := TypeUnknown
+	if requireString {
+		argType = TypeString
+	}
+	return StdFunction{
+		Description: description,
+		MinArgs:     1,
+		MaxArgs:     1,
+		ArgTypes:    []Type{argType},
+		Pure:        true,
+		Handler: func(value ...StaticValue) (Expression, error) {
+			if requireString && !value[0].IsString() {
+				return nil, fmt.Errorf(`"%s" function argument should be a string`, name)
+			}
+			v, err := parse(value[0])
+			if err != nil {
+				return None, nil
+			}
+			return NewValue(v), nil
+		},
+	}
+}
+
+func tryParseJSON(value StaticValue) (interface{}, error) {
+	str, _ := value.StringValue()
+	return parseJSON(str)
+}
+
+func tryParseYAML(value StaticValue) (interface{}, error) {
+	str, _ := value.StringValue()
+	return parseYAML(str)
+}
+
+func tryParseInt(value StaticValue) (interface{}, error) {
+	return value.IntValue()
+}
+
+func tryParseFloat(value StaticValue) (interface{}, error) {
+	return value.FloatValue()
+}
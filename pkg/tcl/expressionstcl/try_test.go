@@ -0,0 +1,86 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTryParseMalformed(t *testing.T) {
+	for _, expr := range []string{
+		`tryJson("{not json")`,
+		`tryJson("")`,
+		`tryYaml("a: [b")`,
+		`tryYaml("\tkey: value")`,
+		`tryInt("abc")`,
+		`tryInt("1.5x")`,
+		`tryFloat("abc")`,
+		`tryFloat([1, 2])`,
+		`tryDate("yesterday")`,
+		`tryDate("2024-13-01", "DateOnly")`,
+	} {
+		v, err := Compile(expr)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, `null`, v.String(), expr)
+		assert.True(t, v.Static().IsNone(), expr)
+	}
+}
+
+func TestTryParseSameAsStrict(t *testing.T) {
+	tests := map[string]string{
+		`tryJson("{\"a\": [1, \"b\"]}")`:         `json("{\"a\": [1, \"b\"]}")`,
+		`tryJson("null")`:                        `json("null")`,
+		`tryYaml("a: 1\nb: [x, y]")`:             `yaml("a: 1\nb: [x, y]")`,
+		`tryInt("12")`:                           `int("12")`,
+		`tryInt(3.7)`:                            `int(3.7)`,
+		`tryFloat("1.5")`:                        `float("1.5")`,
+		`tryFloat(2)`:                            `float(2)`,
+		`tryDate("2024-01-02", "DateOnly")`:      `parseDate("2024-01-02", "DateOnly")`,
+		`tryDate("2024-01-02T03:04:05Z")`:        `parseDate("2024-01-02T03:04:05Z", "RFC3339Nano")`,
+		`tryDate("2024-01-02T03:04:05.5+02:00")`: `parseDate("2024-01-02T03:04:05.5+02:00", "RFC3339")`,
+		`tryDate("03:04PM", "Kitchen")`:          `parseDate("03:04PM", "Kitchen")`,
+	}
+	for expr, strict := range tests {
+		assert.Equal(t, MustCompile(strict).String(), MustCompile(expr).String(), expr)
+	}
+}
+
+func TestTryParseErrors(t *testing.T) {
+	tests := map[string]string{
+		`tryJson()`:                `"tryJson" expects 1 argument, 0 provided`,
+		`tryJson("1", "2")`:        `"tryJson" expects 1 argument, 2 provided`,
+		`tryJson(1)`:               `"tryJson" function argument should be a string`,
+		`tryYaml([1])`:             `"tryYaml" function argument should be a string`,
+		`tryInt()`:                 `"tryInt" expects 1 argument, 0 provided`,
+		`tryFloat(1, 2)`:           `"tryFloat" expects 1 argument, 2 provided`,
+		`tryDate(1700000000)`:      `"tryDate" function argument should be a string`,
+		`tryDate("a", "b", "c")`:   `"tryDate" expects between 1 and 2 arguments, 3 provided`,
+		`tryDate("2024", "plain")`: `"tryDate" function: layout "plain" has no date or time elements`,
+	}
+	for expr, expected := range tests {
+		_, err := Compile(expr)
+		assert.ErrorContains(t, err, expected, expr)
+	}
+}
+
+func TestTryParseWithFallback(t *testing.T) {
+	expr := `default(tryJson(output), {"status": ""}).status || (contains(output, "PASS") ? "passed" : "failed")`
+	v, err := EvalString(expr, map[string]interface{}{"output": `{"status": "skipped"}`})
+	assert.NoError(t, err)
+	assert.Equal(t, "skipped", v)
+	v, err = EvalString(expr, map[string]interface{}{"output": "PASS: 10 tests"})
+	assert.NoError(t, err)
+	assert.Equal(t, "passed", v)
+
+	v, err = EvalString(`default(tryInt(env.RETRIES), 3)`, map[string]interface{}{"env": map[string]interface{}{"RETRIES": "many"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, v)
+}
@@ -8,7 +8,11 @@
 
 package expressionstcl
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
 
 type noneType struct{}
 
@@ -60,3 +64,135 @@ func isStruct(s interface{}) bool {
 func isSlice(s interface{}) bool {
 	return reflect.ValueOf(s).Kind() == reflect.Slice
 }
+
+// TypeIssue is a type mismatch found statically in the compiled expression, it fails the resolution later
+type TypeIssue struct {
+	// Expression is the offending sub-expression
+	Expression string `json:"expression"`
+	Message    string `json:"message"`
+}
+
+func (i TypeIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Expression, i.Message)
+}
+
+// CheckTypes walks the expression tree and reports the mismatches that can be proven without resolving it,
+// i.e. wrong number of arguments of the standard library function, or the argument known to be of the type
+// the function doesn't accept; the sub-expressions of unknown type are never reported, so the issues are warnings
+// that can be shown before the expression is resolved, i.e. while validating the workflow
+func CheckTypes(expr Expression) []TypeIssue {
+	var issues []TypeIssue
+	checkTypes(expr, &issues)
+	return issues
+}
+
+func checkTypes(expr Expression, issues *[]TypeIssue) {
+	if expr == nil || expr.Static() != nil {
+		return
+	}
+
+	switch e := expr.(type) {
+	case *call:
+		for _, arg := range e.args {
+			checkTypes(arg.expr, issues)
+		}
+		checkCallTypes(e, issues)
+	case *math:
+		checkTypes(e.left, issues)
+		checkTypes(e.right, issues)
+		checkOperatorTypes(e, issues)
+	case *conditional:
+		checkTypes(e.condition, issues)
+		checkTypes(e.truthy, issues)
+		checkTypes(e.falsy, issues)
+	case *negative:
+		checkTypes(e.expr, issues)
+	case *propertyAccessor:
+		checkTypes(e.value, issues)
+	}
+}
+
+func checkCallTypes(e *call, issues *[]TypeIssue) {
+	fn, ok := getStdFunction(e.name)
+	if !ok {
+		return
+	}
+	// The number of the spread arguments is not known before the resolution
+	for _, arg := range e.args {
+		if arg.spread {
+			return
+		}
+	}
+
+	if err := fn.checkArgs(e.name, len(e.args)); err != nil {
+		*issues = append(*issues, TypeIssue{Expression: e.String(), Message: err.Error()})
+		return
+	}
+
+	for i, arg := range e.args {
+		known := knownType(arg.expr)
+		if known == TypeUnknown {
+			continue
+		}
+		if slices.Contains(fn.ListArgs, i) {
+			*issues = append(*issues, TypeIssue{
+				Expression: e.String(),
+				Message:    fmt.Sprintf(`"%s" expects a list as argument %d, %s %s provided`, e.name, i+1, known, arg.expr),
+			})
+		} else if expected := fn.argType(i); slices.Contains(fn.StrictArgs, i) && !typeAccepted(expected, known) {
+			*issues = append(*issues, TypeIssue{
+				Expression: e.String(),
+				Message:    fmt.Sprintf(`"%s" expects %s as argument %d, %s %s provided`, e.name, expected, i+1, known, arg.expr),
+			})
+		}
+	}
+}
+
+func checkOperatorTypes(e *math, issues *[]TypeIssue) {
+	switch e.operator {
+	case operatorEquals, operatorEqualsAlias, operatorNotEquals, operatorNotEqualsAlias,
+		operatorGt, operatorGte, operatorLt, operatorLte:
+	default:
+		return
+	}
+
+	l, r := knownType(e.left), knownType(e.right)
+	if (l == TypeBool && r == TypeString) || (l == TypeString && r == TypeBool) {
+		*issues = append(*issues, TypeIssue{
+			Expression: e.String(),
+			Message:    fmt.Sprintf("comparing %s %s with %s %s", l, e.left, r, e.right),
+		})
+	}
+}
+
+// knownType is the type of the expression known before the resolution, none and composite values are unknown;
+// the sum is a number only when both operands are known numbers, as the unknown one may be a string
+func knownType(expr Expression) Type {
+	if v := expr.Static(); v != nil {
+		if v.IsNone() {
+			return TypeUnknown
+		}
+		return v.Type()
+	}
+	if e, ok := expr.(*math); ok && e.operator == operatorAdd {
+		l, r := knownType(e.left), knownType(e.right)
+		if l == TypeString || r == TypeString {
+			return TypeString
+		}
+		if isNumberType(l) && isNumberType(r) {
+			return TypeFloat64
+		}
+		return TypeUnknown
+	}
+	return expr.Type()
+}
+
+// typeAccepted checks if the value of the known type is accepted as the expected one,
+// the numbers are accepted as each other, as the math results are declared as float64
+func typeAccepted(expected, known Type) bool {
+	return expected == TypeUnknown || expected == known || (isNumberType(expected) && isNumberType(known))
+}
+
+func isNumberType(t Type) bool {
+	return t == TypeInt64 || t == TypeFloat64
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTypes(t *testing.T) {
+	tests := map[string][]TypeIssue{
+		`trim(int(x))`: {{Expression: `trim(int(x))`, Message: `"trim" expects string as argument 1, int64 int(x) provided`}},
+		`trim(a, b)`:   {{Expression: `trim(a,b)`, Message: `"trim" expects 1 argument, 2 provided`}},
+		`join(string(list), ",")`: {{
+			Expression: `join(string(list),",")`,
+			Message:    `"join" expects a list as argument 1, string string(list) provided`,
+		}},
+		`bool(x) == "true"`: {{Expression: `bool(x)=="true"`, Message: `comparing bool bool(x) with string "true"`}},
+		`humanNumber(len(x) * 2) + yaml(bool(y))`: {{
+			Expression: `yaml(bool(y))`,
+			Message:    `"yaml" expects string as argument 1, bool bool(y) provided`,
+		}},
+		`upper(trim(int(x)))`: {{Expression: `trim(int(x))`, Message: `"trim" expects string as argument 1, int64 int(x) provided`}},
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, CheckTypes(MustCompile(expr)), expr)
+	}
+}
+
+func TestCheckTypesNoFalsePositives(t *testing.T) {
+	for _, expr := range []string{
+		`trim(x)`,
+		`trim(a + b)`,
+		`trim(cond ? a : b)`,
+		`trim(string(x))`,
+		`upper(int(x))`,
+		`join(list, int(x))`,
+		`join(split(x, ","))`,
+		`humanNumber(x - 1)`,
+		`x == "true"`,
+		`bool(x) == y`,
+		`bool(x) == true`,
+		`custom(int(x), a, b, c)`,
+		`trim(items...)`,
+		`env("NAME")`,
+		`"a" + trim(x)`,
+	} {
+		assert.Empty(t, CheckTypes(MustCompile(expr)), expr)
+	}
+}
+
+func TestCheckTypesTemplate(t *testing.T) {
+	issues := CheckTypes(MustCompileTemplate(`{{ trim(env("NAME")) }}-{{ trim(len(items)) }}`))
+	assert.Equal(t, []TypeIssue{{Expression: `trim(len(items))`, Message: `"trim" expects string as argument 1, int64 len(items) provided`}}, issues)
+	assert.Equal(t, `trim(len(items)): "trim" expects string as argument 1, int64 len(items) provided`, issues[0].String())
+}
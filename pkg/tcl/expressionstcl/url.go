@@ -0,0 +1,176 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var urlComponents = []string{"scheme", "user", "password", "host", "port", "path", "rawPath", "query", "fragment"}
+
+// parseQuery converts query string to map of lists, so the repeated keys have the same shape as the single ones
+func parseQuery(query string) (map[string]interface{}, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{}, len(values))
+	for key, v := range values {
+		items := make([]interface{}, len(v))
+		for i := range v {
+			items[i] = v[i]
+		}
+		result[key] = items
+	}
+	return result, nil
+}
+
+// buildQuery encodes query map, list values produce repeated keys, keys are sorted
+func buildQuery(query map[string]interface{}) (string, error) {
+	values := url.Values{}
+	for key, value := range query {
+		items := []interface{}{value}
+		if v := NewValue(value); v.IsSlice() {
+			items, _ = v.SliceValue()
+		}
+		for _, item := range items {
+			str, err := NewValue(item).StringValue()
+			if err != nil {
+				return "", fmt.Errorf("query value of %q: %v", key, err)
+			}
+			values.Add(key, str)
+		}
+	}
+	return values.Encode(), nil
+}
+
+// parseURL splits URL to decoded components, rawPath keeps the original path encoding, e.g. of "%2F"
+func parseURL(str string) (map[string]interface{}, error) {
+	u, err := url.Parse(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %v", str, errors.Unwrap(err))
+	}
+	query, err := parseQuery(u.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: query: %v", str, err)
+	}
+	password, _ := u.User.Password()
+	return map[string]interface{}{
+		"scheme":   u.Scheme,
+		"user":     u.User.Username(),
+		"password": password,
+		"host":     u.Hostname(),
+		"port":     u.Port(),
+		"path":     u.Path,
+		"rawPath":  u.EscapedPath(),
+		"query":    query,
+		"fragment": u.Fragment,
+	}, nil
+}
+
+// buildURL assembles URL from components returned by parseURL, each component is escaped
+func buildURL(components map[string]interface{}) (string, error) {
+	for key := range components {
+		if !slices.Contains(urlComponents, key) {
+			return "", fmt.Errorf("unknown URL component %q, expected one of: %s", key, strings.Join(urlComponents, ", "))
+		}
+	}
+
+	str := func(key string) (string, error) {
+		value, ok := components[key]
+		if !ok || value == nil {
+			return "", nil
+		}
+		v, err := NewValue(value).StringValue()
+		if err != nil {
+			return "", fmt.Errorf("URL component %q: %v", key, err)
+		}
+		return v, nil
+	}
+	values := make(map[string]string, len(urlComponents))
+	for _, key := range urlComponents {
+		if key == "query" {
+			continue
+		}
+		v, err := str(key)
+		if err != nil {
+			return "", err
+		}
+		values[key] = v
+	}
+
+	u := url.URL{
+		Scheme:   values["scheme"],
+		Host:     values["host"],
+		Path:     values["path"],
+		RawPath:  values["rawPath"],
+		Fragment: values["fragment"],
+	}
+	if values["port"] != "" {
+		u.Host = net.JoinHostPort(values["host"], values["port"])
+	} else if strings.Contains(u.Host, ":") {
+		u.Host = "[" + u.Host + "]"
+	}
+	if values["password"] != "" {
+		u.User = url.UserPassword(values["user"], values["password"])
+	} else if values["user"] != "" {
+		u.User = url.User(values["user"])
+	}
+	if u.Host != "" && u.Path != "" && !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+		u.RawPath = ""
+	}
+
+	if query, ok := components["query"]; ok && query != nil {
+		if v := NewValue(query); v.IsMap() {
+			q, _ := v.MapValue()
+			encoded, err := buildQuery(q)
+			if err != nil {
+				return "", err
+			}
+			u.RawQuery = encoded
+		} else {
+			encoded, err := v.StringValue()
+			if err != nil {
+				return "", fmt.Errorf(`URL component "query": %v`, err)
+			}
+			u.RawQuery = strings.TrimPrefix(encoded, "?")
+		}
+	}
+	return u.String(), nil
+}
+
+// encodeURLComponent escapes the string for the query component, the space is "%20", not "+",
+// so it's valid in the path too
+func encodeURLComponent(str string) string {
+	return strings.ReplaceAll(url.QueryEscape(str), "+", "%20")
+}
+
+// decodeURLComponent unescapes the query component, the "+" is the space too
+func decodeURLComponent(str string) (string, error) {
+	return url.QueryUnescape(str)
+}
+
+// joinURL resolves the reference, i.e. relative path, against the base URL
+func joinURL(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL %q: %v", base, errors.Unwrap(err))
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid reference %q: %v", ref, errors.Unwrap(err))
+	}
+	return b.ResolveReference(r).String(), nil
+}
@@ -10,6 +10,7 @@ package expressionstcl
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -17,24 +18,55 @@ import (
 const maxCallStack = 10_000
 
 func deepResolve(expr Expression, machines ...Machine) (Expression, error) {
+	machines, err := withLimits(machines)
+	if err != nil {
+		return expr, newKindError(ErrorKindCallStack, err)
+	}
+	machines, redactions := withRedactions(withClock(machines))
+	hooks := resolveHooks(machines)
+	if hooks == nil {
+		expr, _, err := deepResolvePasses(expr, machines...)
+		return redactResult(expr, redactions), redactErr(err, redactions)
+	}
+
+	hooks.OnResolveStart()
+	start := time.Now()
+	expr, passes, err := deepResolvePasses(expr, machines...)
+	hooks.OnResolveEnd(time.Since(start), passes)
+	reportErrors(hooks, err)
+	return redactResult(expr, redactions), redactErr(err, redactions)
+}
+
+func deepResolvePasses(expr Expression, machines ...Machine) (Expression, int, error) {
+	ctx := resolveContext(machines)
 	i := 1
 	expr, changed, err := expr.SafeResolve(machines...)
 	for changed && err == nil && expr.Static() == nil {
 		if i > maxCallStack {
-			return expr, fmt.Errorf("maximum call stack exceeded while resolving expression: %s", expr.String())
+			return expr, i, newKindError(ErrorKindCallStack, fmt.Errorf("maximum call stack exceeded while resolving expression: %s", expr.String()))
+		}
+		if err = ctx.Err(); err != nil {
+			return expr, i, err
 		}
 		expr, changed, err = expr.SafeResolve(machines...)
 		i++
 	}
-	return expr, err
+	if err != nil && ctx.Err() != nil {
+		return expr, i, withCancellation(err, ctx.Err())
+	}
+	return expr, i, err
 }
 
 func EvalTemplate(tpl string, machines ...Machine) (string, error) {
-	expr, err := CompileTemplate(tpl)
+	expr, err := compileTemplate(resolveFeatures(machines), tpl, operatorsMachines(machines)...)
 	if err != nil {
 		return "", errors.Wrap(err, "compiling")
 	}
+	machines, audit := withAudit(tpl, machines)
 	expr, err = expr.Resolve(machines...)
+	if auditErr := audit.finish(expr, err); auditErr != nil {
+		return "", auditErr
+	}
 	if err != nil {
 		return "", errors.Wrap(err, "resolving")
 	}
@@ -45,11 +77,15 @@ func EvalTemplate(tpl string, machines ...Machine) (string, error) {
 }
 
 func EvalExpressionPartial(str string, machines ...Machine) (Expression, error) {
-	expr, err := Compile(str)
+	expr, err := compile(resolveFeatures(machines), str, operatorsMachines(machines)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "compiling")
 	}
+	machines, audit := withAudit(str, machines)
 	expr, err = expr.Resolve(machines...)
+	if auditErr := audit.finish(expr, err); auditErr != nil {
+		return nil, auditErr
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "resolving")
 	}
@@ -0,0 +1,26 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import "strings"
+
+// collapseWhitespace trims the string and replaces each run of whitespace with a single space,
+// the whitespace is classified by unicode.IsSpace like in "trim", so it includes non-breaking spaces too
+func collapseWhitespace(str string) string {
+	return strings.Join(strings.Fields(str), " ")
+}
+
+// normalizeWhitespace collapses the whitespace in each line, while the lines are kept
+func normalizeWhitespace(str string) string {
+	lines := strings.Split(str, "\n")
+	for i := range lines {
+		lines[i] = collapseWhitespace(lines[i])
+	}
+	return strings.Join(lines, "\n")
+}
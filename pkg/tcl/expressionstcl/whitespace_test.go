@@ -0,0 +1,50 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollapseWhitespace(t *testing.T) {
+	assert.Equal(t, "", collapseWhitespace(""))
+	assert.Equal(t, "", collapseWhitespace(" \t\n "))
+	assert.Equal(t, "a b c", collapseWhitespace("  a \t b\n\n c \r\n"))
+	assert.Equal(t, "a b", collapseWhitespace("\u00a0a\u00a0 \u00a0b\u00a0"))
+	assert.Equal(t, "a b", collapseWhitespace(" a   b "))
+}
+
+func TestNormalizeWhitespace(t *testing.T) {
+	assert.Equal(t, "", normalizeWhitespace(""))
+	assert.Equal(t, "a b\nc d", normalizeWhitespace("  a \t b \nc\u00a0 d  "))
+	assert.Equal(t, "a\n\nb\n", normalizeWhitespace("a \r\n \t \nb\n"))
+	assert.Equal(t, "a b\nc", normalizeWhitespace("a\u00a0\u00a0b\u00a0\n\u00a0c"))
+}
+
+func TestWhitespaceStdFunctions(t *testing.T) {
+	assert.Equal(t, `"a b c"`, MustCompile(`collapse("  a \t b\n c ")`).String())
+	assert.Equal(t, `"a b\nc"`, MustCompile(`normalizeWhitespace(" a  b \n  c")`).String())
+
+	// the same output with incidental whitespace differences is equal
+	v, err := EvalString(`normalizeWhitespace(a) == normalizeWhitespace(b)`, map[string]interface{}{
+		"a": "total:  3\tpassed\n",
+		"b": "total: 3 passed \u00a0\n",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	_, err = Compile(`collapse(10)`)
+	assert.ErrorContains(t, err, `"collapse" function argument should be a string`)
+	_, err = Compile(`normalizeWhitespace(["a"])`)
+	assert.ErrorContains(t, err, `"normalizeWhitespace" function argument should be a string`)
+	_, err = Compile(`collapse("a", "b")`)
+	assert.ErrorContains(t, err, `"collapse" expects 1 argument, 2 provided`)
+}
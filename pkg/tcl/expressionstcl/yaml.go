@@ -0,0 +1,165 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultYAMLIndent is a default indentation of toyaml() output, the same as usually used for Kubernetes manifests
+	DefaultYAMLIndent = 2
+
+	minYAMLIndent = 2
+	maxYAMLIndent = 9
+)
+
+// YAMLOptions configure the toyaml() output
+type YAMLOptions struct {
+	// Indent is a number of spaces used for each nesting level
+	Indent int
+	// SortKeys orders the map keys by plain string comparison, instead of the natural order, where "a2" precedes "a10"
+	SortKeys bool
+	// ForceBlockStyle emits the non-empty collections in block style, even when the value asks for flow style
+	ForceBlockStyle bool
+}
+
+var defaultYAMLOptions atomic.Pointer[YAMLOptions]
+
+func (o YAMLOptions) validate() error {
+	if o.Indent < minYAMLIndent || o.Indent > maxYAMLIndent {
+		return fmt.Errorf("indent should be between %d and %d, %d provided", minYAMLIndent, maxYAMLIndent, o.Indent)
+	}
+	return nil
+}
+
+// SetDefaultYAMLOptions overrides the defaults of toyaml() options for all resolutions,
+// it's meant to be called once by the application, before the expressions are used
+func SetDefaultYAMLOptions(options YAMLOptions) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+	defaultYAMLOptions.Store(&options)
+
+	// The memoized toyaml() results may be using the previous defaults
+	if cache := functionCache.Load(); cache != nil {
+		cache.clear()
+	}
+	return nil
+}
+
+// DefaultYAMLOptions returns the current defaults of toyaml() options
+func DefaultYAMLOptions() YAMLOptions {
+	if options := defaultYAMLOptions.Load(); options != nil {
+		return *options
+	}
+	return YAMLOptions{Indent: DefaultYAMLIndent}
+}
+
+// parseYAMLOptions overrides the defaults with the options provided as a map,
+// i.e. {"indent": 4, "sortKeys": true, "forceBlockStyle": true}
+func parseYAMLOptions(value StaticValue) (YAMLOptions, error) {
+	options := DefaultYAMLOptions()
+	if !value.IsMap() {
+		return options, fmt.Errorf("options should be a map, %s provided", value.String())
+	}
+	m, _ := value.MapValue()
+	for key, v := range m {
+		item := NewValue(v)
+		var err error
+		switch key {
+		case "indent":
+			var indent int64
+			if indent, err = item.IntValue(); err == nil && !item.IsInt() {
+				err = fmt.Errorf("%s is not an integer", item.String())
+			}
+			options.Indent = int(indent)
+		case "sortKeys":
+			options.SortKeys, err = yamlBoolOption(item)
+		case "forceBlockStyle":
+			options.ForceBlockStyle, err = yamlBoolOption(item)
+		default:
+			return options, fmt.Errorf("unknown option %q", key)
+		}
+		if err != nil {
+			return options, fmt.Errorf("invalid %q option: %v", key, err)
+		}
+	}
+	return options, options.validate()
+}
+
+func yamlBoolOption(value StaticValue) (bool, error) {
+	if !value.IsBool() {
+		return false, fmt.Errorf("%s is not a boolean", value.String())
+	}
+	return value.BoolValue()
+}
+
+// marshalYAML serializes the value as YAML document, the multi-line strings are using literal block scalars
+// wherever it is allowed, the others are double-quoted
+func marshalYAML(value interface{}, options YAMLOptions) (string, error) {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return "", err
+	}
+	formatYAMLNode(&node, options)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(options.Indent)
+	if err := encoder.Encode(&node); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func formatYAMLNode(node *yaml.Node, options YAMLOptions) {
+	if node == nil {
+		return
+	}
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		if options.ForceBlockStyle {
+			node.Style &^= yaml.FlowStyle
+		}
+		if node.Kind == yaml.MappingNode && options.SortKeys {
+			sortYAMLMapping(node)
+		}
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!str" && strings.Contains(node.Value, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+	}
+	for _, item := range node.Content {
+		formatYAMLNode(item, options)
+	}
+}
+
+// sortYAMLMapping sorts the key/value pairs of the mapping node by the key
+func sortYAMLMapping(node *yaml.Node) {
+	pairs := make([][2]*yaml.Node, len(node.Content)/2)
+	for i := range pairs {
+		pairs[i] = [2]*yaml.Node{node.Content[2*i], node.Content[2*i+1]}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i][0].Value < pairs[j][0].Value
+	})
+	for i := range pairs {
+		node.Content[2*i], node.Content[2*i+1] = pairs[i][0], pairs[i][1]
+	}
+}
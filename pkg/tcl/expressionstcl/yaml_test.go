@@ -0,0 +1,223 @@
+// Copyright 2024 Testkube.
+//
+// Licensed as a Testkube Pro file under the Testkube Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/kubeshop/testkube/blob/main/licenses/TCL.txt
+
+package expressionstcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type yamlTestManifest struct {
+	Kind     string                 `yaml:"kind"`
+	Metadata map[string]interface{} `yaml:"metadata"`
+	Spec     yamlTestSpec           `yaml:"spec"`
+}
+
+type yamlTestSpec struct {
+	Replicas int      `yaml:"replicas"`
+	Args     []string `yaml:"args,flow"`
+	Script   string   `yaml:"script"`
+}
+
+func withDefaultYAMLOptions(t *testing.T, options YAMLOptions) {
+	previous := DefaultYAMLOptions()
+	assert.NoError(t, SetDefaultYAMLOptions(options))
+	t.Cleanup(func() { _ = SetDefaultYAMLOptions(previous) })
+}
+
+func TestMarshalYAMLGolden(t *testing.T) {
+	manifest := yamlTestManifest{
+		Kind:     "Job",
+		Metadata: map[string]interface{}{"name": "api", "labels": map[string]interface{}{"tier10": "db", "tier2": "web", "app": "api"}},
+		Spec:     yamlTestSpec{Replicas: 2, Args: []string{"--verbose", "--name=a b"}, Script: "set -e\nmake test\n"},
+	}
+	tests := []struct {
+		options  YAMLOptions
+		expected string
+	}{
+		{YAMLOptions{Indent: 2, SortKeys: false, ForceBlockStyle: false}, `kind: Job
+metadata:
+  labels:
+    app: api
+    tier2: web
+    tier10: db
+  name: api
+spec:
+  replicas: 2
+  args: [--verbose, --name=a b]
+  script: |
+    set -e
+    make test
+`},
+		{YAMLOptions{Indent: 2, SortKeys: false, ForceBlockStyle: true}, `kind: Job
+metadata:
+  labels:
+    app: api
+    tier2: web
+    tier10: db
+  name: api
+spec:
+  replicas: 2
+  args:
+    - --verbose
+    - --name=a b
+  script: |
+    set -e
+    make test
+`},
+		{YAMLOptions{Indent: 2, SortKeys: true, ForceBlockStyle: false}, `kind: Job
+metadata:
+  labels:
+    app: api
+    tier10: db
+    tier2: web
+  name: api
+spec:
+  args: [--verbose, --name=a b]
+  replicas: 2
+  script: |
+    set -e
+    make test
+`},
+		{YAMLOptions{Indent: 2, SortKeys: true, ForceBlockStyle: true}, `kind: Job
+metadata:
+  labels:
+    app: api
+    tier10: db
+    tier2: web
+  name: api
+spec:
+  args:
+    - --verbose
+    - --name=a b
+  replicas: 2
+  script: |
+    set -e
+    make test
+`},
+		{YAMLOptions{Indent: 4, SortKeys: false, ForceBlockStyle: false}, `kind: Job
+metadata:
+    labels:
+        app: api
+        tier2: web
+        tier10: db
+    name: api
+spec:
+    replicas: 2
+    args: [--verbose, --name=a b]
+    script: |
+        set -e
+        make test
+`},
+		{YAMLOptions{Indent: 4, SortKeys: false, ForceBlockStyle: true}, `kind: Job
+metadata:
+    labels:
+        app: api
+        tier2: web
+        tier10: db
+    name: api
+spec:
+    replicas: 2
+    args:
+        - --verbose
+        - --name=a b
+    script: |
+        set -e
+        make test
+`},
+		{YAMLOptions{Indent: 4, SortKeys: true, ForceBlockStyle: false}, `kind: Job
+metadata:
+    labels:
+        app: api
+        tier10: db
+        tier2: web
+    name: api
+spec:
+    args: [--verbose, --name=a b]
+    replicas: 2
+    script: |
+        set -e
+        make test
+`},
+		{YAMLOptions{Indent: 4, SortKeys: true, ForceBlockStyle: true}, `kind: Job
+metadata:
+    labels:
+        app: api
+        tier10: db
+        tier2: web
+    name: api
+spec:
+    args:
+        - --verbose
+        - --name=a b
+    replicas: 2
+    script: |
+        set -e
+        make test
+`},
+	}
+	for _, tt := range tests {
+		result, err := marshalYAML(manifest, tt.options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, result, "%+v", tt.options)
+	}
+}
+
+func TestMarshalYAMLMultilineStrings(t *testing.T) {
+	value := map[string]interface{}{
+		"script":   "echo 1\necho 2",
+		"trailing": "space \nbreak",
+		"list":     []interface{}{"a\nb"},
+	}
+	result, err := marshalYAML(value, YAMLOptions{Indent: 2})
+	assert.NoError(t, err)
+	// literal block scalar can't keep the space before line break
+	assert.Equal(t, "list:\n  - |-\n    a\n    b\nscript: |-\n  echo 1\n  echo 2\ntrailing: \"space \\nbreak\"\n", result)
+}
+
+func TestToYAMLOptions(t *testing.T) {
+	vars := map[string]interface{}{"config": map[string]interface{}{"b": map[string]interface{}{"a2": 1, "a10": 2}, "a": []interface{}{1}}}
+
+	v, err := EvalString(`toyaml(config)`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "a:\n  - 1\nb:\n  a2: 1\n  a10: 2\n", v)
+
+	v, err = EvalString(`toyaml(config, {"indent": 4, "sortKeys": true})`, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "a:\n    - 1\nb:\n    a10: 2\n    a2: 1\n", v)
+
+	tests := map[string]string{
+		`toyaml(config, {"indent": 1})`:          `"toyaml" function: indent should be between 2 and 9, 1 provided`,
+		`toyaml(config, {"indent": 2.5})`:        `"toyaml" function: invalid "indent" option: 2.5 is not an integer`,
+		`toyaml(config, {"sortKeys": "yes"})`:    `"toyaml" function: invalid "sortKeys" option: "yes" is not a boolean`,
+		`toyaml(config, {"forceBlockStyle": 1})`: `"toyaml" function: invalid "forceBlockStyle" option: 1 is not a boolean`,
+		`toyaml(config, {"width": 80})`:          `"toyaml" function: unknown option "width"`,
+		`toyaml(config, "indent=4")`:             `"toyaml" function: options should be a map, "indent=4" provided`,
+		`toyaml(config, {}, {})`:                 `"toyaml" expects between 1 and 2 arguments, 3 provided`,
+	}
+	for expr, message := range tests {
+		_, err = EvalString(expr, vars)
+		assert.ErrorContains(t, err, message, expr)
+	}
+}
+
+func TestToYAMLDefaultOptions(t *testing.T) {
+	assert.Equal(t, YAMLOptions{Indent: DefaultYAMLIndent}, DefaultYAMLOptions())
+	assert.Error(t, SetDefaultYAMLOptions(YAMLOptions{Indent: 10}))
+
+	cache := withFunctionCache(t, FunctionCacheOptions{})
+	assert.Equal(t, `"foo:\n  bar: baz\n"`, MustCompile(`toyaml({"foo":{"bar":"baz"}})`).String())
+
+	// the memoized results are dropped, as they were using the previous defaults
+	withDefaultYAMLOptions(t, YAMLOptions{Indent: 4, SortKeys: true})
+	assert.Equal(t, 0, cache.Stats().Entries)
+	assert.Equal(t, `"foo:\n    bar: baz\n"`, MustCompile(`toyaml({"foo":{"bar":"baz"}})`).String())
+	assert.Equal(t, `"b: 1\n"`, MustCompile(`toyaml({"b": 1}, {"indent": 2})`).String())
+}
@@ -0,0 +1,235 @@
+package triggers
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
+	testsuitesv3 "github.com/kubeshop/testkube-operator/api/testsuite/v3"
+	testtriggersv1 "github.com/kubeshop/testkube-operator/api/testtriggers/v1"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/repository/result"
+	"github.com/kubeshop/testkube/pkg/repository/testresult"
+)
+
+const (
+	// SkippedConcurrentExecutionMessage is the error message of execution skipped by the forbid concurrency policy
+	SkippedConcurrentExecutionMessage = "skipped: concurrent execution in progress"
+
+	runningTestExecutionStatuses = string(testkube.RUNNING_ExecutionStatus) + "," +
+		string(testkube.QUEUED_ExecutionStatus) + "," + string(testkube.PAUSED_ExecutionStatus)
+	runningTestSuiteExecutionStatuses = string(testkube.RUNNING_TestSuiteExecutionStatus) + "," +
+		string(testkube.QUEUED_TestSuiteExecutionStatus)
+)
+
+// concurrencyTargets are the tests or test suites selected by the trigger, the concurrency policy applies to them
+type concurrencyTargets struct {
+	tests      []testsv3.Test
+	testSuites []testsuitesv3.TestSuite
+}
+
+// runningExecutions are the running executions of the concurrency targets, no matter who started them
+type runningExecutions struct {
+	tests      []testkube.Execution
+	testSuites []testkube.TestSuiteExecution
+}
+
+func (r runningExecutions) empty() bool {
+	return len(r.tests) == 0 && len(r.testSuites) == 0
+}
+
+// targetLocks serializes the concurrency policy check with starting the executions per test or test suite
+type targetLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock locks the keys in sorted order, so the triggers selecting overlapping tests can't deadlock,
+// and returns the function unlocking them
+func (l *targetLocks) lock(keys ...string) func() {
+	keys = slices.Clone(keys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*sync.Mutex)
+	}
+	mutexes := make([]*sync.Mutex, len(keys))
+	for i, key := range keys {
+		if l.locks[key] == nil {
+			l.locks[key] = &sync.Mutex{}
+		}
+		mutexes[i] = l.locks[key]
+	}
+	l.mu.Unlock()
+
+	for _, m := range mutexes {
+		m.Lock()
+	}
+	return func() {
+		for i := len(mutexes) - 1; i >= 0; i-- {
+			mutexes[i].Unlock()
+		}
+	}
+}
+
+func testLockKey(name string) string {
+	return ExecutionTest + "/" + name
+}
+
+func testSuiteLockKey(name string) string {
+	return ExecutionTestSuite + "/" + name
+}
+
+func (t concurrencyTargets) lockKeys() []string {
+	keys := make([]string, 0, len(t.tests)+len(t.testSuites))
+	for _, test := range t.tests {
+		keys = append(keys, testLockKey(test.Name))
+	}
+	for _, testSuite := range t.testSuites {
+		keys = append(keys, testSuiteLockKey(testSuite.Name))
+	}
+	return keys
+}
+
+// trigger applies the trigger concurrency policy and runs the trigger executor; the policy applies to the tests or test suites
+// selected by the trigger, so the check of their running executions and the start of the new ones are serialized
+// per test or test suite, and the executions started by other triggers, the API or the CLI count as running too,
+// so triggers selecting the same test can't both start it with forbid policy, or both replace the same running executions
+// with replace policy
+func (s *Service) trigger(ctx context.Context, e *watcherEvent, t *testtriggersv1.TestTrigger, status *triggerStatus) error {
+	queue := queuesExecutions(t)
+	if queue || t.Spec.ConcurrencyPolicy != "" && t.Spec.ConcurrencyPolicy != testtriggersv1.TestTriggerConcurrencyPolicyAllow {
+		targets, err := s.getConcurrencyTargets(t)
+		if err != nil {
+			return err
+		}
+
+		unlock := s.targetLocks.lock(targets.lockKeys()...)
+		defer unlock()
+
+		running, err := s.getRunningExecutions(ctx, targets)
+		if err != nil {
+			return err
+		}
+
+		if !running.empty() {
+			if queue {
+				if t.Spec.Execution != ExecutionTest {
+					s.logger.Infof(
+						"trigger service: matcher component: skipping trigger execution for trigger %s/%s by event %s on resource %s because %s executions can't be queued",
+						t.Namespace, t.Name, e.eventType, e.resource, t.Spec.Execution,
+					)
+					return nil
+				}
+				s.logger.Infof(
+					"trigger service: matcher component: queuing trigger execution for trigger %s/%s by event %s on resource %s because its tests are currently running",
+					t.Namespace, t.Name, e.eventType, e.resource,
+				)
+				s.queueExecutions(ctx, e, t, status, targets.tests)
+				return nil
+			}
+
+			switch t.Spec.ConcurrencyPolicy {
+			case testtriggersv1.TestTriggerConcurrencyPolicyForbid:
+				s.logger.Infof(
+					"trigger service: matcher component: skipping trigger execution for trigger %s/%s by event %s on resource %s because its %ss are currently running",
+					t.Namespace, t.Name, e.eventType, e.resource, t.Spec.Execution,
+				)
+				s.recordSkippedExecutions(ctx, t, targets.tests)
+				return nil
+			case testtriggersv1.TestTriggerConcurrencyPolicyReplace:
+				s.logger.Infof(
+					"trigger service: matcher component: aborting running executions for trigger %s/%s by event %s on resource %s because its %ss are currently running",
+					t.Namespace, t.Name, e.eventType, e.resource, t.Spec.Execution,
+				)
+				s.abortExecutions(ctx, t.Name, status, running)
+			}
+		}
+	}
+
+	s.logger.Infof("trigger service: matcher component: event %s matches trigger %s/%s for resource %s", e.eventType, t.Namespace, t.Name, e.resource)
+	s.logger.Infof("trigger service: matcher component: triggering %s action for %s execution", t.Spec.Action, t.Spec.Execution)
+	return s.triggerExecutor(ctx, e, t)
+}
+
+func (s *Service) getConcurrencyTargets(t *testtriggersv1.TestTrigger) (targets concurrencyTargets, err error) {
+	switch t.Spec.Execution {
+	case ExecutionTest:
+		targets.tests, err = s.getTests(t)
+	case ExecutionTestSuite:
+		targets.testSuites, err = s.getTestSuites(t)
+	}
+	return targets, err
+}
+
+// getRunningExecutions fetches the running executions of the concurrency targets from the repositories
+func (s *Service) getRunningExecutions(ctx context.Context, targets concurrencyTargets) (runningExecutions, error) {
+	var running runningExecutions
+	for _, test := range targets.tests {
+		executions, err := s.getRunningTestExecutions(ctx, test.Name)
+		if err != nil {
+			return running, err
+		}
+		running.tests = append(running.tests, executions...)
+	}
+
+	for _, testSuite := range targets.testSuites {
+		filter := testresult.NewExecutionsFilter().WithName(testSuite.Name).WithStatus(runningTestSuiteExecutionStatuses)
+		executions, err := s.testResultRepository.GetExecutions(ctx, filter)
+		if err != nil {
+			return running, err
+		}
+		running.testSuites = append(running.testSuites, executions...)
+	}
+	return running, nil
+}
+
+// getRunningTestExecutions fetches the running executions of the test, the executions waiting in the trigger queues
+// aren't running yet
+func (s *Service) getRunningTestExecutions(ctx context.Context, testName string) ([]testkube.Execution, error) {
+	filter := result.NewExecutionsFilter().WithTestName(testName).WithStatus(runningTestExecutionStatuses)
+	executions, err := s.resultRepository.GetExecutions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(executions, func(execution testkube.Execution) bool {
+		return execution.IsQueued() && execution.Labels[QueuedByTriggerLabel] != ""
+	}), nil
+}
+
+// recordSkippedExecutions stores skipped execution for every test selected by the trigger,
+// so the skipped runs are visible in the test executions; test suite executions have no skipped status
+// and are only logged
+func (s *Service) recordSkippedExecutions(ctx context.Context, t *testtriggersv1.TestTrigger, tests []testsv3.Test) {
+	if s.resultRepository == nil {
+		return
+	}
+
+	now := time.Now()
+	for _, test := range tests {
+		execution := testkube.NewExecutionWithID(primitive.NewObjectID().Hex(), test.Spec.Type_, test.Name)
+		execution.Name = test.Name + "-skipped-" + execution.Id
+		execution.TestNamespace = test.Namespace
+		execution.StartTime = now
+		execution.EndTime = now
+		execution.RunningContext = &testkube.RunningContext{
+			Type_:   string(testkube.RunningContextTypeTestTrigger),
+			Context: t.Name,
+		}
+		execution.ExecutionResult = &testkube.ExecutionResult{
+			Status:       testkube.StatusPtr(testkube.SKIPPED_ExecutionStatus),
+			ErrorMessage: SkippedConcurrentExecutionMessage,
+		}
+
+		if err := s.resultRepository.Insert(ctx, *execution); err != nil {
+			s.logger.Errorf("trigger service: matcher component: error recording skipped execution of test %s for trigger %s/%s: %v", test.Name, t.Namespace, t.Name, err)
+		}
+	}
+}
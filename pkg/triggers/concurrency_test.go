@@ -0,0 +1,270 @@
+package triggers
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
+	testtriggersv1 "github.com/kubeshop/testkube-operator/api/testtriggers/v1"
+	testsclientv3 "github.com/kubeshop/testkube-operator/pkg/client/tests/v3"
+	"github.com/kubeshop/testkube/internal/app/api/metrics"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/client"
+	"github.com/kubeshop/testkube/pkg/log"
+	"github.com/kubeshop/testkube/pkg/repository/result"
+)
+
+func concurrencyTestTrigger(policy testtriggersv1.TestTriggerConcurrencyPolicy) *testtriggersv1.TestTrigger {
+	return &testtriggersv1.TestTrigger{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "testkube", Name: "test-trigger-1"},
+		Spec: testtriggersv1.TestTriggerSpec{
+			Resource:          "deployment",
+			Event:             "modified",
+			Action:            "run",
+			Execution:         "test",
+			ConcurrencyPolicy: policy,
+			TestSelector:      testtriggersv1.TestTriggerSelector{Name: "some-test"},
+		},
+	}
+}
+
+// startingExecutor simulates the trigger executor, which stores the running execution and registers it once it's scheduled
+func startingExecutor(status *triggerStatus, results *memoryResults, started *int32) ExecutorF {
+	return func(ctx context.Context, e *watcherEvent, t *testtriggersv1.TestTrigger) error {
+		n := atomic.AddInt32(started, 1)
+		time.Sleep(10 * time.Millisecond)
+		id := "test-execution-" + string(rune('0'+n))
+		results.put(testkube.Execution{Id: id, TestName: "some-test", TestType: "curl/test", ExecutionResult: testkube.NewRunningExecutionResult()})
+		status.addExecutionID(id)
+		return nil
+	}
+}
+
+// abortingExecutor stores the aborted executions, like the executors do
+type abortingExecutor struct {
+	*client.FakeExecutor
+	results *memoryResults
+}
+
+func (e abortingExecutor) Abort(ctx context.Context, execution *testkube.Execution) (*testkube.ExecutionResult, error) {
+	res, err := e.FakeExecutor.Abort(ctx, execution)
+	if err == nil {
+		execution.ExecutionResult = res
+		e.results.put(*execution)
+	}
+	return res, err
+}
+
+func skippedExecutions(results *memoryResults) []testkube.Execution {
+	results.mu.Lock()
+	defer results.mu.Unlock()
+	var skipped []testkube.Execution
+	for _, execution := range results.executions {
+		if *execution.ExecutionResult.Status == testkube.SKIPPED_ExecutionStatus {
+			skipped = append(skipped, execution)
+		}
+	}
+	return skipped
+}
+
+func someTestClient(mockCtrl *gomock.Controller, times int) *testsclientv3.MockInterface {
+	mockTestsClient := testsclientv3.NewMockInterface(mockCtrl)
+	mockTestsClient.EXPECT().Get("some-test").
+		Return(&testsv3.Test{ObjectMeta: metav1.ObjectMeta{Namespace: "testkube", Name: "some-test"}, Spec: testsv3.TestSpec{Type_: "curl/test"}}, nil).
+		Times(times)
+	return mockTestsClient
+}
+
+func TestService_triggerForbidConcurrent(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockResultRepository := result.NewMockRepository(mockCtrl)
+	results := newMemoryResults(mockResultRepository)
+
+	testTrigger := concurrencyTestTrigger(testtriggersv1.TestTriggerConcurrencyPolicyForbid)
+	status := newTriggerStatus(testTrigger)
+	var started int32
+	s := &Service{
+		triggerExecutor:  startingExecutor(status, results, &started),
+		triggerStatus:    map[statusKey]*triggerStatus{newStatusKey(testTrigger.Namespace, testTrigger.Name): status},
+		resultRepository: mockResultRepository,
+		testsClient:      someTestClient(mockCtrl, 10),
+		logger:           log.DefaultLogger,
+	}
+
+	e := &watcherEvent{resource: "deployment", name: "test-deployment", namespace: "testkube", eventType: "modified"}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, s.trigger(context.Background(), e, testTrigger, status))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), started)
+	assert.Equal(t, []string{"test-execution-1"}, status.getExecutionIDs())
+	skipped := skippedExecutions(results)
+	assert.Len(t, skipped, 9)
+	for _, execution := range skipped {
+		assert.Equal(t, "some-test", execution.TestName)
+		assert.Equal(t, SkippedConcurrentExecutionMessage, execution.ExecutionResult.ErrorMessage)
+		assert.Equal(t, "test-trigger-1", execution.RunningContext.Context)
+	}
+}
+
+func TestService_triggerForbidSameTest(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockResultRepository := result.NewMockRepository(mockCtrl)
+	results := newMemoryResults(mockResultRepository)
+
+	testTrigger1 := concurrencyTestTrigger(testtriggersv1.TestTriggerConcurrencyPolicyForbid)
+	testTrigger2 := concurrencyTestTrigger(testtriggersv1.TestTriggerConcurrencyPolicyForbid)
+	testTrigger2.Name = "test-trigger-2"
+	status1 := newTriggerStatus(testTrigger1)
+	status2 := newTriggerStatus(testTrigger2)
+	var started int32
+	s := &Service{
+		triggerExecutor: func(ctx context.Context, e *watcherEvent, t *testtriggersv1.TestTrigger) error {
+			if t.Name == testTrigger1.Name {
+				return startingExecutor(status1, results, &started)(ctx, e, t)
+			}
+			return startingExecutor(status2, results, &started)(ctx, e, t)
+		},
+		triggerStatus: map[statusKey]*triggerStatus{
+			newStatusKey(testTrigger1.Namespace, testTrigger1.Name): status1,
+			newStatusKey(testTrigger2.Namespace, testTrigger2.Name): status2,
+		},
+		resultRepository: mockResultRepository,
+		testsClient:      someTestClient(mockCtrl, 10),
+		logger:           log.DefaultLogger,
+	}
+
+	// the triggers selecting the same test don't start it concurrently
+	e := &watcherEvent{resource: "deployment", name: "test-deployment", namespace: "testkube", eventType: "modified"}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		testTrigger, status := testTrigger1, status1
+		if i%2 == 1 {
+			testTrigger, status = testTrigger2, status2
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, s.trigger(context.Background(), e, testTrigger, status))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), started)
+	assert.Len(t, append(status1.getExecutionIDs(), status2.getExecutionIDs()...), 1)
+	assert.Len(t, skippedExecutions(results), 9)
+}
+
+func TestService_triggerForbidManualExecution(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockResultRepository := result.NewMockRepository(mockCtrl)
+	results := newMemoryResults(mockResultRepository)
+	results.put(testkube.Execution{Id: "manual-execution", TestName: "some-test", ExecutionResult: testkube.NewRunningExecutionResult()})
+
+	testTrigger := concurrencyTestTrigger(testtriggersv1.TestTriggerConcurrencyPolicyForbid)
+	status := newTriggerStatus(testTrigger)
+	var started int32
+	s := &Service{
+		triggerExecutor:  startingExecutor(status, results, &started),
+		triggerStatus:    map[statusKey]*triggerStatus{newStatusKey(testTrigger.Namespace, testTrigger.Name): status},
+		resultRepository: mockResultRepository,
+		testsClient:      someTestClient(mockCtrl, 1),
+		logger:           log.DefaultLogger,
+	}
+
+	// the execution started by the API counts as running, even though the trigger has no running executions
+	e := &watcherEvent{resource: "deployment", name: "test-deployment", namespace: "testkube", eventType: "modified"}
+	assert.NoError(t, s.trigger(context.Background(), e, testTrigger, status))
+
+	assert.Equal(t, int32(0), started)
+	assert.Len(t, skippedExecutions(results), 1)
+}
+
+func TestService_triggerReplaceConcurrent(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockResultRepository := result.NewMockRepository(mockCtrl)
+	results := newMemoryResults(mockResultRepository)
+	fakeExecutor := client.NewFakeExecutor(t)
+
+	testTrigger := concurrencyTestTrigger(testtriggersv1.TestTriggerConcurrencyPolicyReplace)
+	status := newTriggerStatus(testTrigger)
+	var started int32
+	s := &Service{
+		triggerExecutor:  startingExecutor(status, results, &started),
+		triggerStatus:    map[statusKey]*triggerStatus{newStatusKey(testTrigger.Namespace, testTrigger.Name): status},
+		resultRepository: mockResultRepository,
+		testsClient:      someTestClient(mockCtrl, 3),
+		testExecutor:     abortingExecutor{FakeExecutor: fakeExecutor, results: results},
+		metrics:          metrics.NewMetrics(),
+		logger:           log.DefaultLogger,
+	}
+
+	e := &watcherEvent{resource: "deployment", name: "test-deployment", namespace: "testkube", eventType: "modified"}
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, s.trigger(context.Background(), e, testTrigger, status))
+		}()
+	}
+	wg.Wait()
+
+	// every trigger started new execution, and each one but the last was aborted by the next trigger
+	assert.Equal(t, int32(3), started)
+	assert.Equal(t, []string{"test-execution-1", "test-execution-2"}, fakeExecutor.Aborted())
+	assert.Equal(t, []string{"test-execution-3"}, status.getExecutionIDs())
+}
+
+func TestService_triggerAllowConcurrent(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	testTrigger := concurrencyTestTrigger(testtriggersv1.TestTriggerConcurrencyPolicyAllow)
+	status := newTriggerStatus(testTrigger)
+	var started int32
+	s := &Service{
+		triggerExecutor: startingExecutor(status, newMemoryResults(result.NewMockRepository(mockCtrl)), &started),
+		triggerStatus:   map[statusKey]*triggerStatus{newStatusKey(testTrigger.Namespace, testTrigger.Name): status},
+		logger:          log.DefaultLogger,
+	}
+
+	e := &watcherEvent{resource: "deployment", name: "test-deployment", namespace: "testkube", eventType: "modified"}
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, s.trigger(context.Background(), e, testTrigger, status))
+	}
+
+	assert.Equal(t, int32(3), started)
+	assert.Len(t, status.getExecutionIDs(), 3)
+}
@@ -28,6 +28,7 @@ type watcherEvent struct {
 	namespace        string
 	labels           map[string]string
 	object           metav1.Object
+	oldObject        metav1.Object
 	eventType        testtrigger.EventType
 	causes           []testtrigger.Cause
 	conditionsGetter conditionsGetterFn
@@ -42,6 +43,13 @@ func withCauses(causes []testtrigger.Cause) watcherOpts {
 	}
 }
 
+// withOldObject keeps the object before the update, for update events
+func withOldObject(object metav1.Object) watcherOpts {
+	return func(w *watcherEvent) {
+		w.oldObject = object
+	}
+}
+
 func withConditionsGetter(conditionsGetter conditionsGetterFn) watcherOpts {
 	return func(w *watcherEvent) {
 		w.conditionsGetter = conditionsGetter
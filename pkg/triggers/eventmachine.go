@@ -0,0 +1,53 @@
+package triggers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+// newEventMachine exposes the event that fired the trigger to the expressions: event.type, event.resource
+// with its kind, name, namespace and labels, the object as event.new and, for update events, the previous one as event.old,
+// with event.changedPaths between them; event.old is None for other events
+func newEventMachine(e *watcherEvent) (expressionstcl.Machine, error) {
+	newObject, err := objectToMap(e.object)
+	if err != nil {
+		return nil, err
+	}
+
+	var oldObject interface{} = expressionstcl.None
+	changedPaths := make([]interface{}, 0)
+	if e.oldObject != nil {
+		old, err := objectToMap(e.oldObject)
+		if err != nil {
+			return nil, err
+		}
+		oldObject = old
+		for _, path := range expressionstcl.ChangedPaths(old, newObject) {
+			changedPaths = append(changedPaths, path)
+		}
+	}
+
+	return expressionstcl.NewMachineFromMap(map[string]interface{}{
+		"event": map[string]interface{}{
+			"type": string(e.eventType),
+			"resource": map[string]interface{}{
+				"kind":      string(e.resource),
+				"name":      e.name,
+				"namespace": e.namespace,
+				"labels":    e.labels,
+			},
+			"old":          oldObject,
+			"new":          newObject,
+			"changedPaths": changedPaths,
+		},
+	}), nil
+}
+
+func objectToMap(object metav1.Object) (map[string]interface{}, error) {
+	if object == nil {
+		return nil, nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+}
@@ -0,0 +1,58 @@
+package triggers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeshop/testkube-operator/pkg/validation/tests/v1/testtrigger"
+	"github.com/kubeshop/testkube/pkg/tcl/expressionstcl"
+)
+
+func testDeployment(image string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", Labels: map[string]string{"app": "api"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "api", Image: image}},
+			}},
+		},
+	}
+}
+
+func evalEvent(t *testing.T, e *watcherEvent, expr string) interface{} {
+	machine, err := newEventMachine(e)
+	require.NoError(t, err)
+	v, err := expressionstcl.EvalExpression(expr, machine)
+	require.NoError(t, err, expr)
+	return v.Value()
+}
+
+func TestEventMachine_Update(t *testing.T) {
+	e := newWatcherEvent(testtrigger.EventModified, testDeployment("api:1.1", 2), testtrigger.ResourceDeployment,
+		withOldObject(testDeployment("api:1.0", 2)))
+
+	assert.Equal(t, "modified", evalEvent(t, e, `event.type`))
+	assert.Equal(t, "deployment", evalEvent(t, e, `event.resource.kind`))
+	assert.Equal(t, "api", evalEvent(t, e, `event.resource.name`))
+	assert.Equal(t, "default", evalEvent(t, e, `event.resource.namespace`))
+	assert.Equal(t, "api", evalEvent(t, e, `event.resource.labels.app`))
+	assert.Equal(t, "api:1.0", evalEvent(t, e, `event.old.spec.template.spec.containers.0.image`))
+	assert.Equal(t, "api:1.1", evalEvent(t, e, `event.new.spec.template.spec.containers.0.image`))
+	assert.Equal(t, []interface{}{"spec.template.spec.containers.0.image"}, evalEvent(t, e, `event.changedPaths`))
+	assert.Equal(t, true, evalEvent(t, e, `contains(string(event.changedPaths), "image")`))
+}
+
+func TestEventMachine_Create(t *testing.T) {
+	e := newWatcherEvent(testtrigger.EventCreated, testDeployment("api:1.0", 1), testtrigger.ResourceDeployment)
+
+	assert.Equal(t, "created", evalEvent(t, e, `event.type`))
+	assert.Equal(t, true, evalEvent(t, e, `event.old == null`))
+	assert.Equal(t, int64(1), evalEvent(t, e, `event.new.spec.replicas`))
+	assert.Equal(t, []interface{}{}, evalEvent(t, e, `event.changedPaths`))
+}
@@ -29,28 +29,7 @@ func (s *Service) execute(ctx context.Context, e *watcherEvent, t *testtriggersv
 	status := s.getStatusForTrigger(t)
 
 	concurrencyLevel := scheduler.DefaultConcurrencyLevel
-	variables := map[string]testkube.Variable{
-		"WATCHER_EVENT_RESOURCE": {
-			Name:  "WATCHER_EVENT_RESOURCE",
-			Value: string(e.resource),
-			Type_: testkube.VariableTypeBasic,
-		},
-		"WATCHER_EVENT_NAME": {
-			Name:  "WATCHER_EVENT_NAME",
-			Value: e.name,
-			Type_: testkube.VariableTypeBasic,
-		},
-		"WATCHER_EVENT_NAMESPACE": {
-			Name:  "WATCHER_EVENT_NAMESPACE",
-			Value: e.namespace,
-			Type_: testkube.VariableTypeBasic,
-		},
-		"WATCHER_EVENT_EVENT_TYPE": {
-			Name:  "WATCHER_EVENT_EVENT_TYPE",
-			Value: string(e.eventType),
-			Type_: testkube.VariableTypeBasic,
-		},
-	}
+	variables := watcherEventVariables(e)
 
 	switch t.Spec.Execution {
 	case ExecutionTest:
@@ -81,7 +60,11 @@ func (s *Service) execute(ctx context.Context, e *watcherEvent, t *testtriggersv
 				"trigger service: executor component: scheduling test executions for trigger %s/%s",
 				t.Namespace, t.Name,
 			)
-			go wp.SendRequests(s.scheduler.PrepareTestRequests(tests, request))
+			requests := s.scheduler.PrepareTestRequests(tests, request)
+			for i := range requests {
+				requests[i].Options.Priority, requests[i].Options.PriorityClassName = executionPriority(tests[i].Annotations, t.Annotations)
+			}
+			go wp.SendRequests(requests)
 			go wp.Run(ctx)
 		}()
 
@@ -133,6 +116,32 @@ func (s *Service) execute(ctx context.Context, e *watcherEvent, t *testtriggersv
 	return nil
 }
 
+// watcherEventVariables passes the event which fired the trigger to the executions
+func watcherEventVariables(e *watcherEvent) map[string]testkube.Variable {
+	return map[string]testkube.Variable{
+		"WATCHER_EVENT_RESOURCE": {
+			Name:  "WATCHER_EVENT_RESOURCE",
+			Value: string(e.resource),
+			Type_: testkube.VariableTypeBasic,
+		},
+		"WATCHER_EVENT_NAME": {
+			Name:  "WATCHER_EVENT_NAME",
+			Value: e.name,
+			Type_: testkube.VariableTypeBasic,
+		},
+		"WATCHER_EVENT_NAMESPACE": {
+			Name:  "WATCHER_EVENT_NAMESPACE",
+			Value: e.namespace,
+			Type_: testkube.VariableTypeBasic,
+		},
+		"WATCHER_EVENT_EVENT_TYPE": {
+			Name:  "WATCHER_EVENT_EVENT_TYPE",
+			Value: string(e.eventType),
+			Type_: testkube.VariableTypeBasic,
+		},
+	}
+}
+
 func (s *Service) getTests(t *testtriggersv1.TestTrigger) ([]testsv3.Test, error) {
 	var tests []testsv3.Test
 	if t.Spec.TestSelector.Name != "" {
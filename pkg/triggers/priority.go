@@ -0,0 +1,33 @@
+package triggers
+
+import (
+	"strconv"
+
+	"github.com/kubeshop/testkube/pkg/executor/client"
+)
+
+const (
+	// ExecutionPriorityAnnotation sets priority of the test executions, on a test trigger it applies to all executions it starts,
+	// queued executions with higher priority start first
+	ExecutionPriorityAnnotation = "testkube.io/execution-priority"
+	// ExecutionPriorityClassAnnotation sets priority class of the test execution pods, like ExecutionPriorityAnnotation
+	ExecutionPriorityClassAnnotation = "testkube.io/execution-priority-class"
+)
+
+// executionPriority reads priority from the annotations, later annotations win, invalid priority values are ignored
+func executionPriority(annotations ...map[string]string) (value int32, className string) {
+	for _, items := range annotations {
+		if v, err := strconv.ParseInt(items[ExecutionPriorityAnnotation], 10, 32); err == nil {
+			value = int32(v)
+		}
+		if class := items[ExecutionPriorityClassAnnotation]; class != "" {
+			className = class
+		}
+	}
+	return value, className
+}
+
+// queuePriority orders queued executions, explicit value wins over the priority class value
+func (s *Service) queuePriority(value int32, className string) int32 {
+	return s.priorityClasses.QueuePriority(client.ExecuteOptions{Priority: &client.Priority{ClassName: className, Value: value}})
+}
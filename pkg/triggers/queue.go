@@ -0,0 +1,225 @@
+package triggers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
+	testtriggersv1 "github.com/kubeshop/testkube-operator/api/testtriggers/v1"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/event/bus"
+	"github.com/kubeshop/testkube/pkg/repository/result"
+)
+
+const (
+	// ConcurrencyPolicyAnnotation set to ConcurrencyPolicyQueue on a test trigger queues its test executions
+	// while the previous ones are running, it takes precedence over the concurrency policy of the trigger spec
+	ConcurrencyPolicyAnnotation = "testkube.io/concurrency-policy"
+	// ConcurrencyPolicyQueue starts the executions when the running ones finish
+	ConcurrencyPolicyQueue = "queue"
+	// QueuedByTriggerLabel marks the execution waiting in the queue of the test trigger, the value is the test trigger name
+	QueuedByTriggerLabel = "testkube.io/queued-by-trigger"
+	// DroppedQueuedExecutionMessage is the error message of queued execution dropped when the trigger queue is full
+	DroppedQueuedExecutionMessage = "aborted: dropped from full test trigger queue"
+)
+
+// QueuedExecutorF starts the execution which was waiting in the trigger queue
+type QueuedExecutorF func(context.Context, testkube.Execution) (testkube.Execution, error)
+
+// queuedExecution is the execution waiting in the trigger queue, it's stored with the queued status,
+// so the queue is restored when the service restarts
+type queuedExecution struct {
+	id       string
+	testName string
+	priority int32
+}
+
+// queuesExecutions checks if the test trigger queues its executions
+func queuesExecutions(t *testtriggersv1.TestTrigger) bool {
+	return t.Annotations[ConcurrencyPolicyAnnotation] == ConcurrencyPolicyQueue
+}
+
+// queueExecutions stores queued execution for every test selected by the trigger, they are started by the execution scraper
+// when the running executions finish in priority order; the oldest queued executions with the lowest priority
+// are aborted when the queue is full
+func (s *Service) queueExecutions(ctx context.Context, e *watcherEvent, t *testtriggersv1.TestTrigger, status *triggerStatus, tests []testsv3.Test) {
+	if s.resultRepository == nil {
+		return
+	}
+
+	now := time.Now()
+	for _, test := range tests {
+		execution := newQueuedExecution(e, t, test, now)
+		if err := s.resultRepository.Insert(ctx, execution); err != nil {
+			s.logger.Errorf("trigger service: matcher component: error queuing execution of test %s for trigger %s/%s: %v", test.Name, t.Namespace, t.Name, err)
+			continue
+		}
+
+		queued := queuedExecution{id: execution.Id, testName: test.Name, priority: s.queuePriority(executionPriority(execution.Labels))}
+		for _, dropped := range status.enqueue(queued, s.queueDepth) {
+			s.dropQueuedExecution(ctx, t, dropped)
+		}
+	}
+}
+
+func newQueuedExecution(e *watcherEvent, t *testtriggersv1.TestTrigger, test testsv3.Test, now time.Time) testkube.Execution {
+	execution := testkube.NewExecutionWithID(primitive.NewObjectID().Hex(), test.Spec.Type_, test.Name)
+	execution.Name = test.Name + "-queued-" + execution.Id
+	execution.TestNamespace = test.Namespace
+	execution.StartTime = now
+	execution.Variables = watcherEventVariables(e)
+	execution.Labels[QueuedByTriggerLabel] = t.Name
+	// the priority is kept with the queued execution, so it's restored and used when the execution starts
+	value, className := executionPriority(test.Annotations, t.Annotations)
+	if value != 0 {
+		execution.Labels[ExecutionPriorityAnnotation] = strconv.Itoa(int(value))
+	}
+	if className != "" {
+		execution.Labels[ExecutionPriorityClassAnnotation] = className
+	}
+	execution.RunningContext = &testkube.RunningContext{
+		Type_:   string(testkube.RunningContextTypeTestTrigger),
+		Context: t.Name,
+	}
+	return *execution
+}
+
+// dropQueuedExecution aborts the queued execution removed from the full queue, and notifies about it
+func (s *Service) dropQueuedExecution(ctx context.Context, t *testtriggersv1.TestTrigger, dropped queuedExecution) {
+	s.logger.Infof(
+		"trigger service: matcher component: dropping queued execution %s of test %s for trigger %s/%s because the queue is full",
+		dropped.id, dropped.testName, t.Namespace, t.Name,
+	)
+
+	execution, err := s.resultRepository.Get(ctx, dropped.id)
+	if err != nil {
+		s.logger.Errorf("trigger service: matcher component: error fetching dropped queued execution %s: %v", dropped.id, err)
+		return
+	}
+	if !execution.IsQueued() {
+		return
+	}
+
+	execution.EndTime = time.Now()
+	execution.ExecutionResult = &testkube.ExecutionResult{
+		Status:       testkube.StatusPtr(testkube.ABORTED_ExecutionStatus),
+		ErrorMessage: DroppedQueuedExecutionMessage,
+	}
+	if err := s.resultRepository.UpdateResult(ctx, execution.Id, execution); err != nil {
+		s.logger.Errorf("trigger service: matcher component: error aborting dropped queued execution %s: %v", execution.Id, err)
+		return
+	}
+
+	if s.eventsBus != nil {
+		if err := s.eventsBus.PublishTopic(bus.InternalPublishTopic, testkube.NewEventEndTestAborted(&execution)); err != nil {
+			s.logger.Errorf("trigger service: matcher component: error publishing dropped queued execution %s: %v", execution.Id, err)
+		}
+	}
+}
+
+// startQueuedExecutions starts the first queued execution of every test in priority order when the test has no running executions,
+// the queued executions of the running tests wait for the next round
+func (s *Service) startQueuedExecutions(ctx context.Context, status *triggerStatus) {
+	for _, queued := range status.dequeue() {
+		s.startQueuedExecution(ctx, status, queued)
+	}
+
+	if status.hasActiveTests() {
+		status.start()
+	}
+}
+
+// startQueuedExecution starts the queued execution, the check of the running executions of the test and the start
+// are serialized with the triggers selecting the same test, see Service.trigger
+func (s *Service) startQueuedExecution(ctx context.Context, status *triggerStatus, queued queuedExecution) {
+	unlock := s.targetLocks.lock(testLockKey(queued.testName))
+	defer unlock()
+
+	running, err := s.getRunningTestExecutions(ctx, queued.testName)
+	if err != nil {
+		s.logger.Errorf("trigger service: execution scraper component: error fetching running executions of test %s: %v", queued.testName, err)
+		status.requeue(queued)
+		return
+	}
+	if len(running) > 0 {
+		status.requeue(queued)
+		return
+	}
+
+	execution, err := s.resultRepository.Get(ctx, queued.id)
+	if err == mongo.ErrNoDocuments {
+		s.logger.Warnf("trigger service: execution scraper component: no queued test execution found for id %s", queued.id)
+		return
+	} else if err != nil {
+		s.logger.Errorf("trigger service: execution scraper component: error fetching queued test execution: %v", err)
+		return
+	}
+	if !execution.IsQueued() {
+		s.logger.Debugf("trigger service: execution scraper component: test execution %s is not queued anymore", queued.id)
+		return
+	}
+
+	started, err := s.queuedExecutor(ctx, execution)
+	if err != nil {
+		s.logger.Errorf("trigger service: execution scraper component: error starting queued test execution %s: %v", queued.id, err)
+		return
+	}
+
+	s.logger.Debugf("trigger service: execution scraper component: started queued test execution %s", started.Id)
+	status.addExecutionID(started.Id)
+}
+
+// executeQueued schedules the queued execution, the scheduler replaces it with the started one under the same id
+func (s *Service) executeQueued(ctx context.Context, execution testkube.Execution) (testkube.Execution, error) {
+	test, err := s.testsClient.Get(execution.TestName)
+	if err != nil {
+		return execution, err
+	}
+
+	request := testkube.ExecutionRequest{
+		Id:             execution.Id,
+		Variables:      execution.Variables,
+		RunningContext: execution.RunningContext,
+	}
+	request.Priority, request.PriorityClassName = executionPriority(execution.Labels)
+	requests := s.scheduler.PrepareTestRequests([]testsv3.Test{*test}, request)
+	if len(requests) == 0 {
+		return execution, fmt.Errorf("no request prepared for test %s", execution.TestName)
+	}
+
+	return requests[0].ExecFn(ctx, requests[0].Object, requests[0].Options)
+}
+
+// restoreQueuedExecutions loads the executions queued by the trigger before the service restarted, in the order they were queued
+func (s *Service) restoreQueuedExecutions(ctx context.Context, status *triggerStatus) {
+	if s.resultRepository == nil {
+		return
+	}
+
+	t := status.testTrigger
+	filter := result.NewExecutionsFilter().
+		WithStatus(string(testkube.QUEUED_ExecutionStatus)).
+		WithSelector(QueuedByTriggerLabel + "=" + t.Name)
+	executions, err := s.resultRepository.GetExecutions(ctx, filter)
+	if err != nil {
+		s.logger.Errorf("trigger service: error restoring queued executions of trigger %s/%s: %v", t.Namespace, t.Name, err)
+		return
+	}
+
+	// executions are sorted from the newest
+	for i := len(executions) - 1; i >= 0; i-- {
+		queued := queuedExecution{
+			id:       executions[i].Id,
+			testName: executions[i].TestName,
+			priority: s.queuePriority(executionPriority(executions[i].Labels)),
+		}
+		for _, dropped := range status.enqueue(queued, s.queueDepth) {
+			s.dropQueuedExecution(ctx, t, dropped)
+		}
+	}
+}
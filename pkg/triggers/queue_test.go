@@ -0,0 +1,290 @@
+package triggers
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	testsv3 "github.com/kubeshop/testkube-operator/api/tests/v3"
+	testtriggersv1 "github.com/kubeshop/testkube-operator/api/testtriggers/v1"
+	testsclientv3 "github.com/kubeshop/testkube-operator/pkg/client/tests/v3"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/event/bus"
+	"github.com/kubeshop/testkube/pkg/executor/client"
+	"github.com/kubeshop/testkube/pkg/log"
+	"github.com/kubeshop/testkube/pkg/repository/result"
+)
+
+// memoryResults keeps the executions stored through the mocked result repository
+type memoryResults struct {
+	mu         sync.Mutex
+	order      []string
+	executions map[string]testkube.Execution
+}
+
+func newMemoryResults(mockResultRepository *result.MockRepository) *memoryResults {
+	r := &memoryResults{executions: make(map[string]testkube.Execution)}
+	mockResultRepository.EXPECT().Insert(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, execution testkube.Execution) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.order = append(r.order, execution.Id)
+		r.executions[execution.Id] = execution
+		return nil
+	}).AnyTimes()
+	mockResultRepository.EXPECT().UpdateResult(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id string, execution testkube.Execution) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.executions[id] = execution
+		return nil
+	}).AnyTimes()
+	mockResultRepository.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id string) (testkube.Execution, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		execution, ok := r.executions[id]
+		if !ok {
+			return testkube.Execution{}, mongo.ErrNoDocuments
+		}
+		return execution, nil
+	}).AnyTimes()
+	mockResultRepository.EXPECT().GetExecutions(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, filter result.Filter) ([]testkube.Execution, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		var executions []testkube.Execution
+		for _, id := range r.order {
+			execution := r.executions[id]
+			if execution.TestName == filter.TestName() && slices.Contains(filter.Statuses(), *execution.ExecutionResult.Status) {
+				executions = append(executions, execution)
+			}
+		}
+		return executions, nil
+	}).AnyTimes()
+	return r
+}
+
+// put stores the execution as if it was started or updated outside of the trigger service
+func (r *memoryResults) put(execution testkube.Execution) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.executions[execution.Id]; !ok {
+		r.order = append(r.order, execution.Id)
+	}
+	r.executions[execution.Id] = execution
+}
+
+func (r *memoryResults) get(i int) testkube.Execution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.executions[r.order[i]]
+}
+
+func TestTriggerStatus_queue(t *testing.T) {
+	t.Parallel()
+
+	status := newTriggerStatus(&testtriggersv1.TestTrigger{})
+
+	assert.Empty(t, status.enqueue(queuedExecution{id: "1", testName: "a"}, 3))
+	assert.Empty(t, status.enqueue(queuedExecution{id: "2", testName: "a"}, 3))
+	assert.Empty(t, status.enqueue(queuedExecution{id: "3", testName: "b"}, 3))
+	assert.Equal(t, []queuedExecution{{id: "1", testName: "a"}}, status.enqueue(queuedExecution{id: "4", testName: "b"}, 3))
+	assert.True(t, status.hasQueuedExecutions())
+
+	assert.Equal(t, []queuedExecution{{id: "2", testName: "a"}, {id: "3", testName: "b"}}, status.dequeue())
+	assert.Equal(t, []queuedExecution{{id: "4", testName: "b"}}, status.dequeue())
+	assert.False(t, status.hasQueuedExecutions())
+	assert.Empty(t, status.dequeue())
+}
+
+func TestTriggerStatus_queuePriority(t *testing.T) {
+	t.Parallel()
+
+	status := newTriggerStatus(&testtriggersv1.TestTrigger{})
+
+	assert.Empty(t, status.enqueue(queuedExecution{id: "nightly-1", testName: "a"}, 4))
+	assert.Empty(t, status.enqueue(queuedExecution{id: "smoke-1", testName: "b", priority: 100}, 4))
+	assert.Empty(t, status.enqueue(queuedExecution{id: "nightly-2", testName: "c"}, 4))
+	assert.Empty(t, status.enqueue(queuedExecution{id: "smoke-2", testName: "a", priority: 100}, 4))
+
+	// the oldest execution with the lowest priority is dropped, even when higher priority ones are older
+	assert.Equal(t, []queuedExecution{{id: "nightly-1", testName: "a"}}, status.enqueue(queuedExecution{id: "urgent", testName: "c", priority: 1000}, 4))
+
+	assert.Equal(t, []queuedExecution{
+		{id: "urgent", testName: "c", priority: 1000},
+		{id: "smoke-1", testName: "b", priority: 100},
+		{id: "smoke-2", testName: "a", priority: 100},
+	}, status.dequeue())
+	assert.Equal(t, []queuedExecution{{id: "nightly-2", testName: "c"}}, status.dequeue())
+	assert.False(t, status.hasQueuedExecutions())
+}
+
+func TestTriggerStatus_requeue(t *testing.T) {
+	t.Parallel()
+
+	status := newTriggerStatus(&testtriggersv1.TestTrigger{})
+
+	assert.Empty(t, status.enqueue(queuedExecution{id: "1", testName: "a", priority: 100}, 4))
+	assert.Empty(t, status.enqueue(queuedExecution{id: "2", testName: "a", priority: 100}, 4))
+	assert.Empty(t, status.enqueue(queuedExecution{id: "3", testName: "b"}, 4))
+
+	// the execution of the running test goes back to its place
+	dequeued := status.dequeue()
+	assert.Equal(t, []queuedExecution{{id: "1", testName: "a", priority: 100}, {id: "3", testName: "b"}}, dequeued)
+	status.requeue(dequeued[0])
+
+	assert.Equal(t, []queuedExecution{{id: "1", testName: "a", priority: 100}}, status.dequeue())
+	assert.Equal(t, []queuedExecution{{id: "2", testName: "a", priority: 100}}, status.dequeue())
+	assert.False(t, status.hasQueuedExecutions())
+}
+
+func TestExecutionPriority(t *testing.T) {
+	t.Parallel()
+
+	test := map[string]string{ExecutionPriorityAnnotation: "10", ExecutionPriorityClassAnnotation: "nightly"}
+	trigger := map[string]string{ExecutionPriorityAnnotation: "1000"}
+
+	value, className := executionPriority(test, trigger)
+	assert.Equal(t, int32(1000), value)
+	assert.Equal(t, "nightly", className)
+
+	value, className = executionPriority(map[string]string{ExecutionPriorityAnnotation: "high"}, nil)
+	assert.Equal(t, int32(0), value)
+	assert.Empty(t, className)
+
+	s := &Service{priorityClasses: client.PriorityClasses{"nightly": 10, "smoke": 500}}
+	assert.Equal(t, int32(500), s.queuePriority(0, "smoke"))
+	assert.Equal(t, int32(20), s.queuePriority(20, "smoke"))
+	assert.Equal(t, int32(0), s.queuePriority(0, "unknown"))
+}
+
+func queueTestTrigger() *testtriggersv1.TestTrigger {
+	testTrigger := concurrencyTestTrigger(testtriggersv1.TestTriggerConcurrencyPolicyAllow)
+	testTrigger.Annotations = map[string]string{ConcurrencyPolicyAnnotation: ConcurrencyPolicyQueue}
+	return testTrigger
+}
+
+func TestQueuesExecutions(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, queuesExecutions(queueTestTrigger()))
+	assert.False(t, queuesExecutions(concurrencyTestTrigger(testtriggersv1.TestTriggerConcurrencyPolicyForbid)))
+
+	testTrigger := concurrencyTestTrigger(testtriggersv1.TestTriggerConcurrencyPolicyForbid)
+	testTrigger.Annotations = map[string]string{ConcurrencyPolicyAnnotation: "replace"}
+	assert.False(t, queuesExecutions(testTrigger))
+}
+
+func TestService_triggerQueue(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockResultRepository := result.NewMockRepository(mockCtrl)
+	results := newMemoryResults(mockResultRepository)
+	mockTestsClient := testsclientv3.NewMockInterface(mockCtrl)
+	mockTestsClient.EXPECT().Get("some-test").
+		Return(&testsv3.Test{ObjectMeta: metav1.ObjectMeta{Namespace: "testkube", Name: "some-test"}, Spec: testsv3.TestSpec{Type_: "curl/test"}}, nil).
+		Times(3)
+	eventsBus := bus.NewEventBusMock()
+	events := make(chan testkube.Event, 10)
+	assert.NoError(t, eventsBus.SubscribeTopic(bus.InternalPublishTopic, "test", func(event testkube.Event) error {
+		events <- event
+		return nil
+	}))
+
+	testTrigger := queueTestTrigger()
+	status := newTriggerStatus(testTrigger)
+	running := testkube.Execution{Id: "running-execution", TestName: "some-test", ExecutionResult: testkube.NewRunningExecutionResult()}
+	results.put(running)
+	status.addExecutionID(running.Id)
+	var started []testkube.Execution
+	s := &Service{
+		triggerExecutor: func(ctx context.Context, e *watcherEvent, t *testtriggersv1.TestTrigger) error {
+			return assert.AnError
+		},
+		queuedExecutor: func(ctx context.Context, execution testkube.Execution) (testkube.Execution, error) {
+			started = append(started, execution)
+			return execution, nil
+		},
+		queueDepth:       2,
+		triggerStatus:    map[statusKey]*triggerStatus{newStatusKey(testTrigger.Namespace, testTrigger.Name): status},
+		resultRepository: mockResultRepository,
+		testsClient:      mockTestsClient,
+		eventsBus:        eventsBus,
+		logger:           log.DefaultLogger,
+	}
+
+	e := &watcherEvent{resource: "deployment", name: "test-deployment", namespace: "testkube", eventType: "modified"}
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, s.trigger(context.Background(), e, testTrigger, status))
+	}
+
+	// the oldest queued execution is dropped, when the third one doesn't fit in the queue
+	dropped := results.get(1)
+	assert.Equal(t, testkube.ABORTED_ExecutionStatus, *dropped.ExecutionResult.Status)
+	assert.Equal(t, DroppedQueuedExecutionMessage, dropped.ExecutionResult.ErrorMessage)
+	select {
+	case event := <-events:
+		assert.Equal(t, testkube.END_TEST_ABORTED_EventType, *event.Type_)
+		assert.Equal(t, dropped.Id, event.TestExecution.Id)
+	case <-time.After(time.Second):
+		t.Fatal("dropped queued execution event not published")
+	}
+
+	queued := results.get(2)
+	assert.Equal(t, testkube.QUEUED_ExecutionStatus, *queued.ExecutionResult.Status)
+	assert.Equal(t, "test-trigger-1", queued.Labels[QueuedByTriggerLabel])
+	assert.Equal(t, "test-deployment", queued.Variables["WATCHER_EVENT_NAME"].Value)
+
+	// queued executions wait for the running one
+	s.startQueuedExecutions(context.Background(), status)
+	assert.Empty(t, started)
+
+	running.ExecutionResult = &testkube.ExecutionResult{Status: testkube.StatusPtr(testkube.PASSED_ExecutionStatus)}
+	results.put(running)
+	status.removeExecutionID(running.Id)
+	s.startQueuedExecutions(context.Background(), status)
+	assert.Len(t, started, 1)
+	assert.Equal(t, queued.Id, started[0].Id)
+	assert.Equal(t, []string{queued.Id}, status.getExecutionIDs())
+	assert.True(t, status.hasQueuedExecutions())
+}
+
+func TestService_restoreQueuedExecutions(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockResultRepository := result.NewMockRepository(mockCtrl)
+	mockResultRepository.EXPECT().GetExecutions(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, filter result.Filter) ([]testkube.Execution, error) {
+		assert.Equal(t, QueuedByTriggerLabel+"=test-trigger-1", filter.Selector())
+		assert.Equal(t, testkube.ExecutionStatuses{testkube.QUEUED_ExecutionStatus}, filter.Statuses())
+		return []testkube.Execution{
+			{Id: "newer", TestName: "some-test"},
+			{Id: "older", TestName: "some-test"},
+			{Id: "smoke", TestName: "some-test", Labels: map[string]string{ExecutionPriorityAnnotation: "100"}},
+		}, nil
+	})
+
+	testTrigger := queueTestTrigger()
+	s := &Service{
+		queueDepth:       defaultQueueDepth,
+		triggerStatus:    make(map[statusKey]*triggerStatus),
+		resultRepository: mockResultRepository,
+		logger:           log.DefaultLogger,
+	}
+
+	s.addTrigger(testTrigger)
+
+	status := s.getStatusForTrigger(testTrigger)
+	assert.Equal(t, []queuedExecution{{id: "smoke", testName: "some-test", priority: 100}}, status.dequeue())
+	assert.Equal(t, []queuedExecution{{id: "older", testName: "some-test"}}, status.dequeue())
+	assert.Equal(t, []queuedExecution{{id: "newer", testName: "some-test"}}, status.dequeue())
+}
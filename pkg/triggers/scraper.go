@@ -8,6 +8,7 @@ import (
 
 	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
 	"github.com/kubeshop/testkube/pkg/event/bus"
+	"github.com/kubeshop/testkube/pkg/executor/client"
 )
 
 func (s *Service) runExecutionScraper(ctx context.Context) {
@@ -30,6 +31,9 @@ func (s *Service) runExecutionScraper(ctx context.Context) {
 						status.done()
 					}
 				}
+				if status.hasQueuedExecutions() {
+					s.startQueuedExecutions(ctx, status)
+				}
 			}
 		}
 	}
@@ -48,7 +52,7 @@ func (s *Service) checkForRunningTestExecutions(ctx context.Context, status *tri
 			s.logger.Errorf("trigger service: execution scraper component: error fetching test execution result: %v", err)
 			continue
 		}
-		if !execution.IsRunning() && !execution.IsQueued() {
+		if !execution.IsRunning() && !execution.IsQueued() && !execution.IsPaused() {
 			s.logger.Debugf("trigger service: execution scraper component: test execution %s is finished", id)
 			status.removeExecutionID(id)
 		}
@@ -75,65 +79,48 @@ func (s *Service) checkForRunningTestSuiteExecutions(ctx context.Context, status
 	}
 }
 
-func (s *Service) abortExecutions(ctx context.Context, testTriggerName string, status *triggerStatus) {
+// abortExecutions aborts the running executions of the tests or test suites selected by the trigger, no matter who started them
+func (s *Service) abortExecutions(ctx context.Context, testTriggerName string, status *triggerStatus, running runningExecutions) {
 	s.logger.Debugf("trigger service: abort executions")
-	s.abortRunningTestExecutions(ctx, status)
-	s.abortRunningTestSuiteExecutions(ctx, status)
+	ctx = client.WithAbortedBy(ctx, "testtrigger "+testTriggerName)
+	s.abortRunningTestExecutions(ctx, status, running.tests)
+	s.abortRunningTestSuiteExecutions(ctx, status, running.testSuites)
 	if !status.hasActiveTests() {
 		s.logger.Debugf("marking status as finished for testtrigger %s", testTriggerName)
 		status.done()
 	}
 }
 
-func (s *Service) abortRunningTestExecutions(ctx context.Context, status *triggerStatus) {
-	testExecutionIDs := status.getExecutionIDs()
-
-	for _, id := range testExecutionIDs {
-		execution, err := s.resultRepository.Get(ctx, id)
-		if err == mongo.ErrNoDocuments {
-			s.logger.Warnf("trigger service: execution scraper component: no test execution found for id %s", id)
-			status.removeExecutionID(id)
+func (s *Service) abortRunningTestExecutions(ctx context.Context, status *triggerStatus, executions []testkube.Execution) {
+	for i := range executions {
+		execution := &executions[i]
+		res, err := s.testExecutor.Abort(ctx, execution)
+		if client.IsAlreadyFinished(err) {
+			s.logger.Debugf("trigger service: execution scraper component: test execution %s is already finished", execution.Id)
+			status.removeExecutionID(execution.Id)
 			continue
-		} else if err != nil {
-			s.logger.Errorf("trigger service: execution scraper component: error fetching test execution result: %v", err)
+		}
+		if err != nil {
+			s.logger.Errorf("trigger service: execution scraper component: error aborting test execution: %v", err)
 			continue
 		}
-		if execution.IsRunning() || execution.IsQueued() {
-			res, err := s.testExecutor.Abort(ctx, &execution)
-			if err != nil {
-				s.logger.Errorf("trigger service: execution scraper component: error aborting test execution: %v", err)
-				continue
-			}
-			s.metrics.IncAbortTest(execution.TestType, res.IsFailed())
+		s.metrics.IncAbortTest(execution.TestType, res.IsFailed())
 
-			s.logger.Debugf("trigger service: execution scraper component: test execution %s is aborted", id)
-			status.removeExecutionID(id)
-		}
+		s.logger.Debugf("trigger service: execution scraper component: test execution %s is aborted", execution.Id)
+		status.removeExecutionID(execution.Id)
 	}
 }
 
-func (s *Service) abortRunningTestSuiteExecutions(ctx context.Context, status *triggerStatus) {
-	testSuiteExecutionIDs := status.getTestSuiteExecutionIDs()
-
-	for _, id := range testSuiteExecutionIDs {
-		execution, err := s.testResultRepository.Get(ctx, id)
-		if err == mongo.ErrNoDocuments {
-			s.logger.Warnf("trigger service: execution scraper component: no testsuite execution found for id %s", id)
-			status.removeTestSuiteExecutionID(id)
-			continue
-		} else if err != nil {
-			s.logger.Errorf("trigger service: execution scraper component: error fetching testsuite execution result: %v", err)
+func (s *Service) abortRunningTestSuiteExecutions(ctx context.Context, status *triggerStatus, executions []testkube.TestSuiteExecution) {
+	for i := range executions {
+		execution := &executions[i]
+		err := s.eventsBus.PublishTopic(bus.InternalPublishTopic, testkube.NewEventEndTestSuiteAborted(execution))
+		if err != nil {
+			s.logger.Errorf("trigger service: execution scraper component: error aborting test suite execution: %v", err)
 			continue
 		}
-		if execution.IsRunning() || execution.IsQueued() {
-			err := s.eventsBus.PublishTopic(bus.InternalPublishTopic, testkube.NewEventEndTestSuiteAborted(&execution))
-			if err != nil {
-				s.logger.Errorf("trigger service: execution scraper component: error aborting test suite execution: %v", err)
-				continue
-			}
 
-			s.logger.Debugf("trigger service: execution scraper component: testsuite execution %s is aborted", id)
-			status.removeTestSuiteExecutionID(id)
-		}
+		s.logger.Debugf("trigger service: execution scraper component: testsuite execution %s is aborted", execution.Id)
+		status.removeTestSuiteExecutionID(execution.Id)
 	}
 }
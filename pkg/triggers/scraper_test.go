@@ -9,7 +9,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"github.com/kubeshop/testkube/internal/app/api/metrics"
 	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/kubeshop/testkube/pkg/executor/client"
 	"github.com/kubeshop/testkube/pkg/log"
 	"github.com/kubeshop/testkube/pkg/repository/result"
 	"github.com/kubeshop/testkube/pkg/repository/testresult"
@@ -94,3 +96,22 @@ func TestService_runExecutionScraper(t *testing.T) {
 		}
 	})
 }
+
+func TestService_abortExecutions(t *testing.T) {
+	t.Parallel()
+
+	fakeExecutor := client.NewFakeExecutor(t)
+	runningExecution := testkube.Execution{Id: "test-execution-1", TestType: "curl/test", ExecutionResult: testkube.NewRunningExecutionResult()}
+
+	status := &triggerStatus{testExecutionIDs: []string{"test-execution-1"}}
+	s := &Service{
+		testExecutor: fakeExecutor,
+		metrics:      metrics.NewMetrics(),
+		logger:       log.DefaultLogger,
+	}
+
+	s.abortExecutions(context.Background(), "test-trigger-1", status, runningExecutions{tests: []testkube.Execution{runningExecution}})
+
+	assert.Equal(t, []string{"test-execution-1"}, fakeExecutor.Aborted())
+	assert.False(t, status.hasActiveTests())
+}
@@ -41,6 +41,7 @@ const (
 	defaultProbesCheckTimeout     = 60 * time.Second
 	defaultClusterID              = "testkube-api"
 	defaultIdentifierFormat       = "testkube-api-%s"
+	defaultQueueDepth             = 10
 )
 
 type Service struct {
@@ -49,6 +50,9 @@ type Service struct {
 	identifier                    string
 	clusterID                     string
 	triggerExecutor               ExecutorF
+	queuedExecutor                QueuedExecutorF
+	queueDepth                    int
+	priorityClasses               client.PriorityClasses
 	scraperInterval               time.Duration
 	leaseCheckInterval            time.Duration
 	maxLeaseDuration              time.Duration
@@ -58,6 +62,7 @@ type Service struct {
 	defaultProbesCheckBackoff     time.Duration
 	watchFromDate                 time.Time
 	triggerStatus                 map[statusKey]*triggerStatus
+	targetLocks                   targetLocks
 	scheduler                     *scheduler.Scheduler
 	clientset                     kubernetes.Interface
 	testKubeClientset             testkubeclientsetv1.Interface
@@ -103,6 +108,7 @@ func NewService(
 		scraperInterval:               defaultScraperInterval,
 		leaseCheckInterval:            defaultLeaseCheckInterval,
 		maxLeaseDuration:              defaultMaxLeaseDuration,
+		queueDepth:                    defaultQueueDepth,
 		defaultConditionsCheckTimeout: defaultConditionsCheckTimeout,
 		defaultConditionsCheckBackoff: defaultConditionsCheckBackoff,
 		defaultProbesCheckTimeout:     defaultProbesCheckTimeout,
@@ -128,6 +134,9 @@ func NewService(
 	if s.triggerExecutor == nil {
 		s.triggerExecutor = s.execute
 	}
+	if s.queuedExecutor == nil {
+		s.queuedExecutor = s.executeQueued
+	}
 
 	for _, opt := range opts {
 		opt(s)
@@ -183,6 +192,24 @@ func WithExecutor(triggerExecutor ExecutorF) Option {
 	}
 }
 
+// WithQueueDepth sets how many executions can wait for a trigger with the queue concurrency policy,
+// the oldest queued execution with the lowest priority is dropped when the queue is full
+func WithQueueDepth(depth int) Option {
+	return func(s *Service) {
+		if depth > 0 {
+			s.queueDepth = depth
+		}
+	}
+}
+
+// WithPriorityClasses sets priority classes allowed for executions, they provide queue priority
+// of the queued executions with priority class only
+func WithPriorityClasses(classes client.PriorityClasses) Option {
+	return func(s *Service) {
+		s.priorityClasses = classes
+	}
+}
+
 func WithTestkubeNamespace(namespace string) Option {
 	return func(s *Service) {
 		s.testkubeNamespace = namespace
@@ -219,6 +246,9 @@ func (s *Service) Run(ctx context.Context) {
 func (s *Service) addTrigger(t *testtriggersv1.TestTrigger) {
 	key := newStatusKey(t.Namespace, t.Name)
 	s.triggerStatus[key] = newTriggerStatus(t)
+	if queuesExecutions(t) {
+		s.restoreQueuedExecutions(context.Background(), s.triggerStatus[key])
+	}
 }
 
 func (s *Service) updateTrigger(target *testtriggersv1.TestTrigger) {
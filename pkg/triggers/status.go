@@ -2,6 +2,7 @@ package triggers
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -20,6 +21,7 @@ type triggerStatus struct {
 	lastExecutionFinished *time.Time
 	testExecutionIDs      []string
 	testSuiteExecutionIDs []string
+	queuedExecutions      []queuedExecution
 	sync.RWMutex
 }
 
@@ -99,6 +101,79 @@ func (s *triggerStatus) removeTestSuiteExecutionID(targetID string) {
 	}
 }
 
+// enqueue adds the execution to the queue ordered by priority, after the queued executions with the same or higher priority,
+// the oldest executions with the lowest priority over the depth are dropped and returned
+func (s *triggerStatus) enqueue(execution queuedExecution, depth int) []queuedExecution {
+	defer s.Unlock()
+
+	s.Lock()
+	position := len(s.queuedExecutions)
+	for i, queued := range s.queuedExecutions {
+		if queued.priority < execution.priority {
+			position = i
+			break
+		}
+	}
+	s.queuedExecutions = slices.Insert(s.queuedExecutions, position, execution)
+
+	var dropped []queuedExecution
+	for depth > 0 && len(s.queuedExecutions) > depth {
+		lowest := 0
+		for i, queued := range s.queuedExecutions {
+			if queued.priority < s.queuedExecutions[lowest].priority {
+				lowest = i
+			}
+		}
+		dropped = append(dropped, s.queuedExecutions[lowest])
+		s.queuedExecutions = slices.Delete(s.queuedExecutions, lowest, lowest+1)
+	}
+	return dropped
+}
+
+// requeue puts the dequeued execution back to the queue, before the queued executions with the same or lower priority,
+// so it keeps its place in the queue
+func (s *triggerStatus) requeue(execution queuedExecution) {
+	defer s.Unlock()
+
+	s.Lock()
+	position := len(s.queuedExecutions)
+	for i, queued := range s.queuedExecutions {
+		if queued.priority <= execution.priority {
+			position = i
+			break
+		}
+	}
+	s.queuedExecutions = slices.Insert(s.queuedExecutions, position, execution)
+}
+
+// dequeue removes the first queued execution of every test from the queue, the later executions of the same test wait
+// for the next round, so the executions of a test run one after another in the priority and queuing order,
+// returned executions are ordered by priority
+func (s *triggerStatus) dequeue() []queuedExecution {
+	defer s.Unlock()
+
+	s.Lock()
+	var result, rest []queuedExecution
+	tests := make(map[string]struct{})
+	for _, execution := range s.queuedExecutions {
+		if _, ok := tests[execution.testName]; ok {
+			rest = append(rest, execution)
+			continue
+		}
+		tests[execution.testName] = struct{}{}
+		result = append(result, execution)
+	}
+	s.queuedExecutions = rest
+	return result
+}
+
+func (s *triggerStatus) hasQueuedExecutions() bool {
+	defer s.RUnlock()
+
+	s.RLock()
+	return len(s.queuedExecutions) > 0
+}
+
 func (s *triggerStatus) done() {
 	defer s.Unlock()
 